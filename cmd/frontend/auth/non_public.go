@@ -96,6 +96,12 @@ func AllowAnonymousRequest(req *http.Request) bool {
 		return true
 	}
 
+	// The inbound email webhook authenticates requests itself (with the configured webhook secret),
+	// because inbound email providers can't sign in.
+	if req.URL.Path == "/.api/inbound-email" {
+		return true
+	}
+
 	apiRouteName := matchedRouteName(req, router.Router())
 	if apiRouteName == router.UI {
 		// Test against UI router. (Some of its handlers inject private data into the title or meta tags.)
@@ -111,12 +111,18 @@ func GetAndSaveUser(ctx context.Context, op GetAndSaveUserOp) (userID int32, saf
 
 	// Update user properties, if they've changed
 	if !userSaved {
-		// Update user in our DB if their profile info changed on the issuer. (Except username and
-		// email, which the user is somewhat likely to want to control separately on Sourcegraph.)
 		user, err := db.Users.GetByID(ctx, userID)
 		if err != nil {
 			return 0, "Unexpected error getting the Sourcegraph user account. Ask a site admin for help.", err
 		}
+
+		// 🚨 SECURITY: Deactivated users may not sign in.
+		if user.DeactivatedAt != nil {
+			return 0, "Your Sourcegraph user account has been deactivated. Ask a site admin for help.", fmt.Errorf("user %d is deactivated", user.ID)
+		}
+
+		// Update user in our DB if their profile info changed on the issuer. (Except username and
+		// email, which the user is somewhat likely to want to control separately on Sourcegraph.)
 		var userUpdate db.UserUpdate
 		if user.DisplayName != op.UserProps.DisplayName {
 			userUpdate.DisplayName = &op.UserProps.DisplayName
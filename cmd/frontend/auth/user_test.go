@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/sergi/go-diff/diffmatchpatch"
@@ -52,6 +53,7 @@ func TestGetAndSaveUser(t *testing.T) {
 	}
 
 	unexpectedErr := errors.New("unexpected err")
+	deactivatedAt := time.Now()
 
 	oneUser := []userInfo{{
 		user: types.User{ID: 1, Username: "u1"},
@@ -346,6 +348,20 @@ func TestGetAndSaveUser(t *testing.T) {
 				expErr:                     unexpectedErr,
 			}},
 		},
+		{
+			description: "deactivated user",
+			mock: mockParams{userInfos: []userInfo{{
+				user:     types.User{ID: 1, Username: "u1", DeactivatedAt: &deactivatedAt},
+				extAccts: []extsvc.ExternalAccountSpec{ext("st1", "s1", "c1", "s1/u1")},
+				emails:   []string{"u1@example.com"},
+			}}},
+			innerCases: []innerCase{{
+				op:                         getOneUserOp,
+				createIfNotExistIrrelevant: true,
+				expSafeErr:                 "Your Sourcegraph user account has been deactivated. Ask a site admin for help.",
+				expErr:                     errors.New("user 1 is deactivated"),
+			}},
+		},
 	}
 
 	allCases := append(append([]outerCase{}, mainCase), errorCases...)
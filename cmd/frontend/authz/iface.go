@@ -82,6 +82,12 @@ type Provider interface {
 	Validate() (problems []string)
 }
 
+// SourcegraphServiceType is the service type of the external accounts that identify users to authz
+// providers whose permissions are stored in Sourcegraph itself (such as explicit repository
+// permissions). Such accounts are computed by the authz provider's FetchAccount method whenever
+// they are needed, and they are never saved.
+const SourcegraphServiceType = "sourcegraph"
+
 type Repo struct {
 	// RepoName is the unique name of the repo on Sourcegraph.
 	RepoName api.RepoName
@@ -0,0 +1,132 @@
+package backend
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/env"
+	"github.com/sourcegraph/sourcegraph/pkg/highlight"
+	"github.com/sourcegraph/sourcegraph/pkg/vcs/git"
+	log15 "gopkg.in/inconshreveable/log15.v2"
+)
+
+var highlightPrewarmFiles, _ = strconv.Atoi(env.Get("HIGHLIGHT_CACHE_PREWARM_FILES", "0", "number of most-viewed files to highlight in the background at the latest commit of their repository's default branch (0 disables pre-warming)"))
+
+// highlightCachePrewarmInterval is how often the most-viewed files are
+// highlighted in the background.
+const highlightCachePrewarmInterval = 15 * time.Minute
+
+type fileViewKey struct {
+	repo api.RepoName
+	path string
+}
+
+type fileView struct {
+	repo  *types.Repo
+	path  string
+	count float64
+}
+
+// fileViews counts views of files (on any revision) for pre-warming the
+// highlight cache. Counts are halved after each pre-warming round, so that
+// files that are no longer popular eventually drop out.
+var fileViews = struct {
+	sync.Mutex
+	m map[fileViewKey]*fileView
+}{m: map[fileViewKey]*fileView{}}
+
+// RecordFileView records that the file at path in repo was viewed.
+func RecordFileView(repo *types.Repo, path string) {
+	if highlightPrewarmFiles <= 0 {
+		return
+	}
+	fileViews.Lock()
+	defer fileViews.Unlock()
+	k := fileViewKey{repo: repo.Name, path: path}
+	v, ok := fileViews.m[k]
+	if !ok {
+		// Bound memory usage. Files viewed only once since the last round are
+		// unlikely to be among the most viewed.
+		if len(fileViews.m) >= 100*highlightPrewarmFiles {
+			return
+		}
+		v = &fileView{repo: repo, path: path}
+		fileViews.m[k] = v
+	}
+	v.count++
+}
+
+// topFileViews returns the n most-viewed files and decays all view counts.
+func topFileViews(n int) []fileView {
+	fileViews.Lock()
+	defer fileViews.Unlock()
+	all := make([]fileView, 0, len(fileViews.m))
+	for k, v := range fileViews.m {
+		all = append(all, *v)
+		v.count /= 2
+		if v.count < 1 {
+			delete(fileViews.m, k)
+		}
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].count != all[j].count {
+			return all[i].count > all[j].count
+		}
+		if all[i].repo.Name != all[j].repo.Name {
+			return all[i].repo.Name < all[j].repo.Name
+		}
+		return all[i].path < all[j].path
+	})
+	if len(all) > n {
+		all = all[:n]
+	}
+	return all
+}
+
+// StartHighlightCachePrewarmer periodically highlights the most-viewed files
+// at the latest commit of their repository's default branch, so that the
+// highlighted HTML is already cached when users view them after their
+// repository is updated. It does nothing unless HIGHLIGHT_CACHE_PREWARM_FILES
+// is set.
+func StartHighlightCachePrewarmer() {
+	if highlightPrewarmFiles <= 0 {
+		return
+	}
+	for {
+		time.Sleep(highlightCachePrewarmInterval)
+		for _, v := range topFileViews(highlightPrewarmFiles) {
+			if err := prewarmHighlightCache(context.Background(), v.repo, v.path); err != nil {
+				log15.Debug("Failed to pre-warm highlight cache.", "repo", v.repo.Name, "path", v.path, "error", err)
+			}
+		}
+	}
+}
+
+func prewarmHighlightCache(ctx context.Context, repo *types.Repo, path string) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	cachedRepo, err := CachedGitRepo(ctx, repo)
+	if err != nil {
+		return err
+	}
+	commitID, err := git.ResolveRevision(ctx, *cachedRepo, nil, "HEAD", &git.ResolveRevisionOptions{NoEnsureRevision: true})
+	if err != nil {
+		return err
+	}
+	content, err := git.ReadFile(ctx, *cachedRepo, commitID, path)
+	if err != nil {
+		return err
+	}
+	for _, isLightTheme := range []bool{false, true} {
+		if _, _, err := highlight.CachedCode(ctx, content, path, true, isLightTheme); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package backend
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+)
+
+func TestTopFileViews(t *testing.T) {
+	defer func(orig int) { highlightPrewarmFiles = orig }(highlightPrewarmFiles)
+	highlightPrewarmFiles = 2
+
+	a := &types.Repo{Name: "a"}
+	b := &types.Repo{Name: "b"}
+	for i := 0; i < 3; i++ {
+		RecordFileView(b, "x")
+	}
+	RecordFileView(a, "y")
+	RecordFileView(a, "z")
+	RecordFileView(a, "z")
+
+	paths := func(views []fileView) (paths []string) {
+		for _, v := range views {
+			paths = append(paths, string(v.repo.Name)+"/"+v.path)
+		}
+		return paths
+	}
+
+	if got, want := paths(topFileViews(2)), []string{"b/x", "a/z"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// Counts were halved, and a/y (1 view) was dropped.
+	if got, want := paths(topFileViews(5)), []string{"b/x", "a/z"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("after decay: got %v, want %v", got, want)
+	}
+}
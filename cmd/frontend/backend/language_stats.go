@@ -0,0 +1,82 @@
+package backend
+
+import (
+	"context"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/env"
+	"github.com/sourcegraph/sourcegraph/pkg/vcs/git"
+	log15 "gopkg.in/inconshreveable/log15.v2"
+)
+
+var languageStatsInterval = env.Get("LANGUAGE_STATS_INTERVAL", "24h", "how often to record the language breakdown of each repository's default branch (0 disables recording)")
+
+// StartLanguageStatsRecorder periodically records the language breakdown of
+// the latest commit on each enabled repository's default branch, for the
+// site-wide language statistics over time. A breakdown is only recorded if
+// the default branch changed since the last recording.
+func StartLanguageStatsRecorder() {
+	interval, err := time.ParseDuration(languageStatsInterval)
+	if err != nil {
+		log15.Error("Invalid LANGUAGE_STATS_INTERVAL, not recording language statistics.", "error", err)
+		return
+	}
+	if interval <= 0 {
+		return
+	}
+	for {
+		if err := recordLanguageStats(context.Background()); err != nil {
+			log15.Error("Failed to record language statistics.", "error", err)
+		}
+		time.Sleep(interval)
+	}
+}
+
+func recordLanguageStats(ctx context.Context) error {
+	const pageSize = 500
+	for offset := 0; ; offset += pageSize {
+		repos, err := db.Repos.List(ctx, db.ReposListOptions{
+			Enabled:     true,
+			LimitOffset: &db.LimitOffset{Limit: pageSize, Offset: offset},
+		})
+		if err != nil {
+			return err
+		}
+		for _, repo := range repos {
+			if err := recordRepoLanguageStats(ctx, repo); err != nil {
+				log15.Debug("Failed to record language statistics for repository.", "repo", repo.Name, "error", err)
+			}
+		}
+		if len(repos) < pageSize {
+			return nil
+		}
+	}
+}
+
+func recordRepoLanguageStats(ctx context.Context, repo *types.Repo) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	cachedRepo, err := CachedGitRepo(ctx, repo)
+	if err != nil {
+		return err
+	}
+	commitID, err := git.ResolveRevision(ctx, *cachedRepo, nil, "HEAD", &git.ResolveRevisionOptions{NoEnsureRevision: true})
+	if err != nil {
+		return err
+	}
+	latest, err := db.RepoLanguageStats.LatestCommitID(ctx, repo.ID)
+	if err != nil {
+		return err
+	}
+	if latest == commitID {
+		return nil
+	}
+	inv, err := Repos.GetInventory(ctx, repo, commitID)
+	if err != nil {
+		return err
+	}
+	return db.RepoLanguageStats.Record(ctx, repo.ID, commitID, inv.Languages)
+}
@@ -20,3 +20,12 @@ func (symbols) ListTags(ctx context.Context, args protocol.SearchArgs) ([]protoc
 	}
 	return result.Symbols, err
 }
+
+// Outline returns the tree of symbols defined in a single file from ctags.
+func (symbols) Outline(ctx context.Context, args protocol.OutlineArgs) ([]*protocol.OutlineSymbol, error) {
+	result, err := symbolsclient.DefaultClient.Outline(ctx, args)
+	if result == nil {
+		return nil, err
+	}
+	return result.Symbols, err
+}
@@ -110,7 +110,7 @@ func (s *accessTokens) Lookup(ctx context.Context, tokenHexEncoded string, requi
 	}
 
 	if err := dbconn.Global.QueryRowContext(ctx,
-		// Ensure that subject and creator users still exist.
+		// Ensure that subject and creator users still exist and have not been deactivated.
 		`
 UPDATE access_tokens t SET last_used_at=now()
 FROM access_tokens t2
@@ -118,6 +118,7 @@ JOIN users subject_user ON t2.subject_user_id=subject_user.id
 JOIN users creator_user ON t2.creator_user_id=creator_user.id
 WHERE t.value_sha256=$1 AND t.deleted_at IS NULL AND
   subject_user.deleted_at IS NULL AND creator_user.deleted_at IS NULL AND
+  subject_user.deactivated_at IS NULL AND creator_user.deactivated_at IS NULL AND
   $2 = ANY (t.scopes)
 RETURNING t.subject_user_id
 `,
@@ -0,0 +1,92 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/keegancsmith/sqlf"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/db/dbconn"
+	"github.com/sourcegraph/sourcegraph/pkg/db/dbutil"
+)
+
+// discussionCodeHostComments provides access to the `discussion_code_host_comments` table, which
+// links discussion comments to the code host pull request comments that they were mirrored to or
+// imported from.
+//
+// For a detailed overview of the schema, see schema.md.
+type discussionCodeHostComments struct{}
+
+// Create links a discussion comment to a code host comment.
+//
+// A link without an ExternalCommentID is pending: it is created before the comment is posted to
+// the code host (so that the comment is never posted twice, even if the link can't be completed
+// afterwards), and completed with SetExternalIDs once the comment has been posted.
+func (s *discussionCodeHostComments) Create(ctx context.Context, c *types.DiscussionCodeHostComment) error {
+	return s.create(ctx, dbconn.Global, c)
+}
+
+func (*discussionCodeHostComments) create(ctx context.Context, q queryable, c *types.DiscussionCodeHostComment) error {
+	externalThreadID := sql.NullString{String: c.ExternalThreadID, Valid: c.ExternalThreadID != ""}
+	externalCommentID := sql.NullString{String: c.ExternalCommentID, Valid: c.ExternalCommentID != ""}
+	query := sqlf.Sprintf("INSERT INTO discussion_code_host_comments(thread_id, comment_id, service_type, service_id, external_repo_id, pull_request_number, external_thread_id, external_comment_id) VALUES(%s, %s, %s, %s, %s, %s, %s, %s) RETURNING id, created_at",
+		c.ThreadID, c.CommentID, c.ServiceType, c.ServiceID, c.ExternalRepoID, c.PullRequestNumber, externalThreadID, externalCommentID)
+	return q.QueryRowContext(ctx, query.Query(sqlf.PostgresBindVar), query.Args()...).Scan(&c.ID, &c.CreatedAt)
+}
+
+// CreateImported creates a discussion comment for a comment that was made on the code host, and
+// links them. Both are created in a single transaction, because an unlinked comment would be
+// imported again (and posted back to the code host).
+func (s *discussionCodeHostComments) CreateImported(ctx context.Context, newComment *types.DiscussionComment, c *types.DiscussionCodeHostComment) error {
+	if err := DiscussionComments.validateNew(ctx, newComment); err != nil {
+		return err
+	}
+	return dbutil.Transaction(ctx, dbconn.Global, func(tx *sql.Tx) error {
+		if err := DiscussionComments.insert(ctx, tx, newComment); err != nil {
+			return err
+		}
+		c.CommentID = newComment.ID
+		return s.create(ctx, tx, c)
+	})
+}
+
+// SetExternalIDs completes a pending link with the IDs of the code host thread and comment that
+// the discussion comment was posted as.
+func (*discussionCodeHostComments) SetExternalIDs(ctx context.Context, id int64, externalThreadID, externalCommentID string) error {
+	q := sqlf.Sprintf("UPDATE discussion_code_host_comments SET external_thread_id=%s, external_comment_id=%s WHERE id=%s", externalThreadID, externalCommentID, id)
+	_, err := dbconn.Global.ExecContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	return err
+}
+
+// Delete deletes a link, such as a pending link whose comment could not be posted to the code host.
+func (*discussionCodeHostComments) Delete(ctx context.Context, id int64) error {
+	q := sqlf.Sprintf("DELETE FROM discussion_code_host_comments WHERE id=%s", id)
+	_, err := dbconn.Global.ExecContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	return err
+}
+
+// ListByThread returns the links of the thread's comments, oldest first. The first link (if any)
+// is that of the thread's first comment, which is the comment that started the thread on the code
+// host.
+func (*discussionCodeHostComments) ListByThread(ctx context.Context, threadID int64) ([]*types.DiscussionCodeHostComment, error) {
+	q := sqlf.Sprintf("SELECT id, thread_id, comment_id, service_type, service_id, external_repo_id, pull_request_number, external_thread_id, external_comment_id, created_at FROM discussion_code_host_comments WHERE thread_id=%s ORDER BY id", threadID)
+	rows, err := dbconn.Global.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var links []*types.DiscussionCodeHostComment
+	for rows.Next() {
+		var (
+			c                                   types.DiscussionCodeHostComment
+			externalThreadID, externalCommentID sql.NullString
+		)
+		if err := rows.Scan(&c.ID, &c.ThreadID, &c.CommentID, &c.ServiceType, &c.ServiceID, &c.ExternalRepoID, &c.PullRequestNumber, &externalThreadID, &externalCommentID, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		c.ExternalThreadID, c.ExternalCommentID = externalThreadID.String, externalCommentID.String
+		links = append(links, &c)
+	}
+	return links, rows.Err()
+}
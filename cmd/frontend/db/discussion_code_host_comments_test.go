@@ -0,0 +1,140 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/db/dbtesting"
+)
+
+func TestDiscussionCodeHostComments(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	ctx := dbtesting.TestContext(t)
+
+	user, err := Users.Create(ctx, NewUser{Username: "u"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Repos.Upsert(ctx, api.InsertRepoOp{Name: "myrepo", Enabled: true}); err != nil {
+		t.Fatal(err)
+	}
+	repo, err := Repos.GetByName(ctx, "myrepo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	thread, err := DiscussionThreads.Create(ctx, &types.DiscussionThread{
+		AuthorUserID: user.ID,
+		Title:        "t",
+		TargetRepo:   &types.DiscussionThreadTargetRepo{RepoID: repo.ID, Path: strPtr("a.go"), Branch: strPtr("b")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var comments []*types.DiscussionComment
+	for _, contents := range []string{"c1", "c2"} {
+		comment, err := DiscussionComments.Create(ctx, &types.DiscussionComment{ThreadID: thread.ID, AuthorUserID: user.ID, Contents: contents})
+		if err != nil {
+			t.Fatal(err)
+		}
+		comments = append(comments, comment)
+	}
+
+	for i, externalCommentID := range []string{"100", "101"} {
+		link := &types.DiscussionCodeHostComment{
+			ThreadID:          thread.ID,
+			CommentID:         comments[i].ID,
+			ServiceType:       "github",
+			ServiceID:         "https://github.com/",
+			ExternalRepoID:    "r",
+			PullRequestNumber: 7,
+			ExternalThreadID:  "100",
+			ExternalCommentID: externalCommentID,
+		}
+		if err := DiscussionCodeHostComments.Create(ctx, link); err != nil {
+			t.Fatal(err)
+		}
+		if link.ID == 0 || link.CreatedAt.IsZero() {
+			t.Errorf("got link %+v, want ID and CreatedAt to be set", link)
+		}
+	}
+
+	// The same code host comment must not be linked twice.
+	if err := DiscussionCodeHostComments.Create(ctx, &types.DiscussionCodeHostComment{ThreadID: thread.ID, CommentID: comments[1].ID, ServiceType: "github", ServiceID: "https://github.com/", ExternalCommentID: "100"}); err == nil {
+		t.Error("got no error when linking a code host comment twice")
+	}
+
+	links, err := DiscussionCodeHostComments.ListByThread(ctx, thread.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(links) != 2 {
+		t.Fatalf("got %d links, want 2", len(links))
+	}
+	if l := links[0]; l.CommentID != comments[0].ID || l.ExternalCommentID != "100" || l.PullRequestNumber != 7 || l.ExternalThreadID != "100" {
+		t.Errorf("got first link %+v, want link of first comment", l)
+	}
+	if l := links[1]; l.CommentID != comments[1].ID || l.ExternalCommentID != "101" {
+		t.Errorf("got second link %+v, want link of second comment", l)
+	}
+
+	// Pending links have no external comment ID until they are completed, and
+	// don't conflict with each other.
+	var pending []*types.DiscussionCodeHostComment
+	for _, contents := range []string{"c3", "c4"} {
+		comment, err := DiscussionComments.Create(ctx, &types.DiscussionComment{ThreadID: thread.ID, AuthorUserID: user.ID, Contents: contents})
+		if err != nil {
+			t.Fatal(err)
+		}
+		link := &types.DiscussionCodeHostComment{ThreadID: thread.ID, CommentID: comment.ID, ServiceType: "github", ServiceID: "https://github.com/", ExternalThreadID: "100"}
+		if err := DiscussionCodeHostComments.Create(ctx, link); err != nil {
+			t.Fatal(err)
+		}
+		pending = append(pending, link)
+	}
+	if err := DiscussionCodeHostComments.SetExternalIDs(ctx, pending[0].ID, "100", "102"); err != nil {
+		t.Fatal(err)
+	}
+	if err := DiscussionCodeHostComments.Delete(ctx, pending[1].ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// Imported comments are created together with their link.
+	imported := &types.DiscussionComment{ThreadID: thread.ID, AuthorUserID: user.ID, Contents: "c5"}
+	importedLink := &types.DiscussionCodeHostComment{ThreadID: thread.ID, ServiceType: "github", ServiceID: "https://github.com/", ExternalThreadID: "100", ExternalCommentID: "103"}
+	if err := DiscussionCodeHostComments.CreateImported(ctx, imported, importedLink); err != nil {
+		t.Fatal(err)
+	}
+	if imported.ID == 0 || importedLink.CommentID != imported.ID {
+		t.Errorf("got comment %+v and link %+v, want them to be linked", imported, importedLink)
+	}
+
+	// Importing an already linked code host comment creates neither the
+	// comment nor the link.
+	duplicate := &types.DiscussionComment{ThreadID: thread.ID, AuthorUserID: user.ID, Contents: "c6"}
+	if err := DiscussionCodeHostComments.CreateImported(ctx, duplicate, &types.DiscussionCodeHostComment{ThreadID: thread.ID, ServiceType: "github", ServiceID: "https://github.com/", ExternalCommentID: "103"}); err == nil {
+		t.Error("got no error when importing a code host comment twice")
+	}
+	comments, err = DiscussionComments.List(ctx, &DiscussionCommentsListOptions{ThreadID: &thread.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(comments) != 5 {
+		t.Errorf("got %d comments, want 5", len(comments))
+	}
+
+	links, err = DiscussionCodeHostComments.ListByThread(ctx, thread.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, l := range links {
+		got = append(got, l.ExternalCommentID)
+	}
+	if want := []string{"100", "101", "102", "103"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got external comment IDs %q, want %q", got, want)
+	}
+}
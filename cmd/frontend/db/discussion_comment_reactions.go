@@ -0,0 +1,66 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/keegancsmith/sqlf"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/db/dbconn"
+)
+
+// DiscussionReactionEmojis are the emoji that users may react to discussion
+// comments with.
+var DiscussionReactionEmojis = []string{"👍", "👎", "😄", "🎉", "😕", "❤️", "🚀", "👀"}
+
+// discussionCommentReactions provides access to the `discussion_comment_reactions` table.
+//
+// For a detailed overview of the schema, see schema.md.
+type discussionCommentReactions struct{}
+
+// Add adds the user's reaction with the emoji to the comment. Adding a reaction
+// that already exists is a no-op.
+func (*discussionCommentReactions) Add(ctx context.Context, commentID int64, userID int32, emoji string) error {
+	if !isDiscussionReactionEmoji(emoji) {
+		return fmt.Errorf("invalid reaction emoji %q", emoji)
+	}
+	q := sqlf.Sprintf("INSERT INTO discussion_comment_reactions(comment_id, user_id, emoji) VALUES(%s, %s, %s) ON CONFLICT DO NOTHING", commentID, userID, emoji)
+	_, err := dbconn.Global.ExecContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	return err
+}
+
+// Remove removes the user's reaction with the emoji from the comment, if any.
+func (*discussionCommentReactions) Remove(ctx context.Context, commentID int64, userID int32, emoji string) error {
+	q := sqlf.Sprintf("DELETE FROM discussion_comment_reactions WHERE comment_id=%s AND user_id=%s AND emoji=%s", commentID, userID, emoji)
+	_, err := dbconn.Global.ExecContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	return err
+}
+
+// ListByComment returns the reactions to the comment, oldest first.
+func (*discussionCommentReactions) ListByComment(ctx context.Context, commentID int64) ([]*types.DiscussionCommentReaction, error) {
+	q := sqlf.Sprintf("SELECT comment_id, user_id, emoji, created_at FROM discussion_comment_reactions WHERE comment_id=%s ORDER BY created_at, user_id", commentID)
+	rows, err := dbconn.Global.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reactions []*types.DiscussionCommentReaction
+	for rows.Next() {
+		var r types.DiscussionCommentReaction
+		if err := rows.Scan(&r.CommentID, &r.UserID, &r.Emoji, &r.CreatedAt); err != nil {
+			return nil, err
+		}
+		reactions = append(reactions, &r)
+	}
+	return reactions, rows.Err()
+}
+
+func isDiscussionReactionEmoji(emoji string) bool {
+	for _, e := range DiscussionReactionEmojis {
+		if e == emoji {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/db/dbtesting"
+)
+
+func TestDiscussionComments_RepliesReactions(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	ctx := dbtesting.TestContext(t)
+
+	user, err := Users.Create(ctx, NewUser{Username: "u"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Repos.Upsert(ctx, api.InsertRepoOp{Name: "myrepo", Enabled: true}); err != nil {
+		t.Fatal(err)
+	}
+	repo, err := Repos.GetByName(ctx, "myrepo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var threads []*types.DiscussionThread
+	for _, title := range []string{"t1", "t2"} {
+		thread, err := DiscussionThreads.Create(ctx, &types.DiscussionThread{
+			AuthorUserID: user.ID,
+			Title:        title,
+			TargetRepo:   &types.DiscussionThreadTargetRepo{RepoID: repo.ID, Path: strPtr("a.go")},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		threads = append(threads, thread)
+	}
+	comment, err := DiscussionComments.Create(ctx, &types.DiscussionComment{ThreadID: threads[0].ID, AuthorUserID: user.ID, Contents: "c"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Reply to the comment.
+	reply, err := DiscussionComments.Create(ctx, &types.DiscussionComment{ThreadID: threads[0].ID, AuthorUserID: user.ID, Contents: "r", ParentCommentID: &comment.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	replies, err := DiscussionComments.List(ctx, &DiscussionCommentsListOptions{ParentCommentID: &comment.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(replies) != 1 || replies[0].ID != reply.ID || *replies[0].ParentCommentID != comment.ID {
+		t.Errorf("got replies %+v, want only %d", replies, reply.ID)
+	}
+	if _, err := DiscussionComments.Create(ctx, &types.DiscussionComment{ThreadID: threads[1].ID, AuthorUserID: user.ID, Contents: "r", ParentCommentID: &comment.ID}); err == nil {
+		t.Error("got nil error for a reply to a comment in another thread")
+	}
+
+	// Resolve the comment.
+	updated, err := DiscussionComments.Update(ctx, comment.ID, &DiscussionCommentsUpdateOptions{Resolve: boolPtr(true)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.ResolvedAt == nil {
+		t.Error("expected comment to be resolved")
+	}
+
+	// React to the comment.
+	for _, emoji := range []string{"👍", "🎉", "👍"} {
+		if err := DiscussionCommentReactions.Add(ctx, comment.ID, user.ID, emoji); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := DiscussionCommentReactions.Add(ctx, comment.ID, user.ID, "not an emoji"); err == nil {
+		t.Error("got nil error for invalid emoji")
+	}
+	if err := DiscussionCommentReactions.Remove(ctx, comment.ID, user.ID, "🎉"); err != nil {
+		t.Fatal(err)
+	}
+	reactions, err := DiscussionCommentReactions.ListByComment(ctx, comment.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, r := range reactions {
+		got = append(got, r.Emoji)
+	}
+	if want := []string{"👍"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got reactions %q, want %q", got, want)
+	}
+}
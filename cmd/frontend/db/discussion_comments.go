@@ -37,53 +37,80 @@ func (c *discussionComments) Create(ctx context.Context, newComment *types.Discu
 		return Mocks.DiscussionComments.Create(ctx, newComment)
 	}
 
-	// Validate the input comment.
+	if err := c.validateNew(ctx, newComment); err != nil {
+		return nil, err
+	}
+	if err := c.insert(ctx, dbconn.Global, newComment); err != nil {
+		return nil, err
+	}
+	return newComment, nil
+}
+
+// validateNew returns an error if the comment can't be created.
+func (c *discussionComments) validateNew(ctx context.Context, newComment *types.DiscussionComment) error {
 	if newComment == nil {
-		return nil, errors.New("newComment is nil")
+		return errors.New("newComment is nil")
 	}
 	if newComment.ID != 0 {
-		return nil, errors.New("newComment.ID must be zero")
+		return errors.New("newComment.ID must be zero")
 	}
 	if len([]rune(newComment.Contents)) > 100000 {
-		return nil, errors.New("comment content too long (must be less than 100,000 UTF-8 characters)")
+		return errors.New("comment content too long (must be less than 100,000 UTF-8 characters)")
 	}
 	if !newComment.CreatedAt.IsZero() {
-		return nil, errors.New("newComment.CreatedAt must not be specified")
+		return errors.New("newComment.CreatedAt must not be specified")
 	}
 	if !newComment.UpdatedAt.IsZero() {
-		return nil, errors.New("newComment.UpdatedAt must not be specified")
+		return errors.New("newComment.UpdatedAt must not be specified")
 	}
 	if newComment.DeletedAt != nil {
-		return nil, errors.New("newComment.DeletedAt must not be specified")
+		return errors.New("newComment.DeletedAt must not be specified")
 	}
+	if newComment.ResolvedAt != nil {
+		return errors.New("newComment.ResolvedAt must not be specified")
+	}
+	if newComment.ParentCommentID != nil {
+		parent, err := c.Get(ctx, *newComment.ParentCommentID)
+		if err != nil {
+			return err
+		}
+		if parent.ThreadID != newComment.ThreadID {
+			return errors.New("newComment.ParentCommentID must be a comment in the same thread")
+		}
+	}
+	return nil
+}
 
-	// Create the comment.
+// insert inserts the (validated) comment.
+func (*discussionComments) insert(ctx context.Context, q queryable, newComment *types.DiscussionComment) error {
 	newComment.CreatedAt = time.Now()
 	newComment.UpdatedAt = newComment.CreatedAt
 
-	err := dbconn.Global.QueryRowContext(ctx, `INSERT INTO discussion_comments(
+	return q.QueryRowContext(ctx, `INSERT INTO discussion_comments(
 		thread_id,
 		author_user_id,
 		contents,
 		created_at,
-		updated_at
-	) VALUES ($1, $2, $3, $4, $5) RETURNING id`,
+		updated_at,
+		parent_comment_id
+	) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`,
 		newComment.ThreadID,
 		newComment.AuthorUserID,
 		newComment.Contents,
 		newComment.CreatedAt,
 		newComment.UpdatedAt,
+		newComment.ParentCommentID,
 	).Scan(&newComment.ID)
-	if err != nil {
-		return nil, err
-	}
-	return newComment, nil
 }
 
 type DiscussionCommentsUpdateOptions struct {
 	// Contents, when non-nil, specifies the new contents of the comment.
 	Contents *string
 
+	// Resolve, when non-nil, specifies whether the comment is marked as
+	// resolved or not.
+	Resolve *bool
+
 	// Delete, when true, specifies that the comment should be deleted. This
 	// operation cannot be undone.
 	Delete bool
@@ -127,6 +154,16 @@ func (c *discussionComments) Update(ctx context.Context, commentID int64, opts *
 			return nil, err
 		}
 	}
+	if opts.Resolve != nil {
+		anyUpdate = true
+		var resolvedAt *time.Time
+		if *opts.Resolve {
+			resolvedAt = &now
+		}
+		if _, err := dbconn.Global.ExecContext(ctx, "UPDATE discussion_comments SET resolved_at=$1 WHERE id=$2 AND deleted_at IS NULL", resolvedAt, commentID); err != nil {
+			return nil, err
+		}
+	}
 	var deletingFirstComment bool
 	if opts.Delete || opts.hardDelete {
 		// Deleting the first comment in a thread implicitly means deleting the thread itself.
@@ -191,6 +228,14 @@ func (c *discussionComments) Update(ctx context.Context, commentID int64, opts *
 	return c.Get(ctx, commentID)
 }
 
+// SetTimes overwrites the creation, update and resolve times of the comment.
+// It is used when importing comments from another instance, to preserve their
+// original times, and should not be used otherwise.
+func (c *discussionComments) SetTimes(ctx context.Context, commentID int64, createdAt, updatedAt time.Time, resolvedAt *time.Time) error {
+	_, err := dbconn.Global.ExecContext(ctx, "UPDATE discussion_comments SET created_at=$1, updated_at=$2, resolved_at=$3 WHERE id=$4 AND deleted_at IS NULL", createdAt, updatedAt, resolvedAt, commentID)
+	return err
+}
+
 type DiscussionCommentsListOptions struct {
 	// LimitOffset specifies SQL LIMIT and OFFSET counts. It may be nil (no limit / offset).
 	*LimitOffset
@@ -207,6 +252,10 @@ type DiscussionCommentsListOptions struct {
 	// be returned.
 	CommentID *int64
 
+	// ParentCommentID, when non-nil, specifies that only replies to this
+	// comment should be returned.
+	ParentCommentID *int64
+
 	// Reported, when true, returns only threads that have at least one report.
 	Reported bool
 
@@ -265,6 +314,9 @@ func (*discussionComments) getListSQL(opts *DiscussionCommentsListOptions) (cond
 	if opts.CommentID != nil {
 		conds = append(conds, sqlf.Sprintf("id=%v", *opts.CommentID))
 	}
+	if opts.ParentCommentID != nil {
+		conds = append(conds, sqlf.Sprintf("parent_comment_id=%v", *opts.ParentCommentID))
+	}
 	if opts.Reported {
 		conds = append(conds, sqlf.Sprintf("array_length(reports,1) > 0"))
 	}
@@ -297,7 +349,9 @@ func (*discussionComments) getBySQL(ctx context.Context, query string, args ...i
 			c.contents,
 			c.created_at,
 			c.updated_at,
-			c.reports
+			c.reports,
+			c.parent_comment_id,
+			c.resolved_at
 		FROM discussion_comments c `+query, args...)
 	if err != nil {
 		return nil, err
@@ -315,6 +369,8 @@ func (*discussionComments) getBySQL(ctx context.Context, query string, args ...i
 			&comment.CreatedAt,
 			&comment.UpdatedAt,
 			pq.Array(&comment.Reports),
+			&comment.ParentCommentID,
+			&comment.ResolvedAt,
 		)
 		if err != nil {
 			return nil, err
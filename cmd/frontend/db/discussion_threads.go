@@ -16,6 +16,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/discussions/searchquery"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/actor"
 	"github.com/sourcegraph/sourcegraph/pkg/api"
 	"github.com/sourcegraph/sourcegraph/pkg/db/dbconn"
 	"github.com/sourcegraph/sourcegraph/pkg/vcs/git"
@@ -70,6 +71,15 @@ func (t *discussionThreads) Create(ctx context.Context, newThread *types.Discuss
 	if newThread.DeletedAt != nil {
 		return nil, errors.New("newThread.DeletedAt must not be specified")
 	}
+	if newThread.ResolvedAt != nil {
+		return nil, errors.New("newThread.ResolvedAt must not be specified")
+	}
+	if len(newThread.Labels) > 0 {
+		return nil, errors.New("newThread.Labels must not be specified")
+	}
+	if len(newThread.AssigneeUserIDs) > 0 {
+		return nil, errors.New("newThread.AssigneeUserIDs must not be specified")
+	}
 	if newThread.TargetRepo != nil {
 		if rev := newThread.TargetRepo.Revision; rev != nil {
 			if !git.IsAbsoluteRevision(*rev) {
@@ -143,6 +153,19 @@ type DiscussionThreadsUpdateOptions struct {
 	// Archive, when non-nil, specifies whether the thread is archived or not.
 	Archive *bool
 
+	// Resolve, when non-nil, specifies whether the thread is resolved or not
+	// (i.e., open).
+	Resolve *bool
+
+	// Labels, when non-nil, specifies the thread's new labels (replacing its
+	// existing labels).
+	Labels *[]string
+
+	// AddAssigneeUserIDs and RemoveAssigneeUserIDs specify users to assign to
+	// and unassign from the thread.
+	AddAssigneeUserIDs    []int32
+	RemoveAssigneeUserIDs []int32
+
 	// Delete, when true, specifies that the thread should be deleted. This
 	// operation cannot be undone.
 	Delete bool
@@ -163,6 +186,11 @@ func (t *discussionThreads) Update(ctx context.Context, threadID int64, opts *Di
 	if opts == nil {
 		return nil, errors.New("options must not be nil")
 	}
+	if len(opts.AddAssigneeUserIDs) > 0 {
+		if err := checkDiscussionThreadAssignees(ctx, opts.AddAssigneeUserIDs); err != nil {
+			return nil, err
+		}
+	}
 	now := time.Now()
 
 	// TODO(slimsag:discussions): should be in a transaction
@@ -178,6 +206,43 @@ func (t *discussionThreads) Update(ctx context.Context, threadID int64, opts *Di
 			return nil, err
 		}
 	}
+	if opts.Resolve != nil {
+		anyUpdate = true
+		q := "UPDATE discussion_threads SET resolved_at=NULL WHERE id=$1 AND deleted_at IS NULL"
+		args := []interface{}{threadID}
+		if *opts.Resolve {
+			// Keep the original time if the thread is already resolved.
+			q = "UPDATE discussion_threads SET resolved_at=COALESCE(resolved_at, $2) WHERE id=$1 AND deleted_at IS NULL"
+			args = append(args, now)
+		}
+		if _, err := dbconn.Global.ExecContext(ctx, q, args...); err != nil {
+			return nil, err
+		}
+	}
+	if opts.Labels != nil {
+		anyUpdate = true
+		labels, err := normalizeDiscussionThreadLabels(*opts.Labels)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := dbconn.Global.ExecContext(ctx, "UPDATE discussion_threads SET labels=$1 WHERE id=$2 AND deleted_at IS NULL", pq.Array(labels), threadID); err != nil {
+			return nil, err
+		}
+	}
+	if len(opts.AddAssigneeUserIDs) > 0 {
+		anyUpdate = true
+		for _, userID := range opts.AddAssigneeUserIDs {
+			if _, err := dbconn.Global.ExecContext(ctx, "INSERT INTO discussion_thread_assignees(thread_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING", threadID, userID); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(opts.RemoveAssigneeUserIDs) > 0 {
+		anyUpdate = true
+		if _, err := dbconn.Global.ExecContext(ctx, "DELETE FROM discussion_thread_assignees WHERE thread_id=$1 AND user_id = ANY($2)", threadID, pq.Array(opts.RemoveAssigneeUserIDs)); err != nil {
+			return nil, err
+		}
+	}
 	if opts.Delete {
 		anyUpdate = true
 		if _, err := dbconn.Global.ExecContext(ctx, "UPDATE discussion_threads SET deleted_at=$1 WHERE id=$2 AND deleted_at IS NULL", now, threadID); err != nil {
@@ -245,6 +310,94 @@ func (t *discussionThreads) Update(ctx context.Context, threadID int64, opts *Di
 	return t.Get(ctx, threadID)
 }
 
+// SetTimes overwrites the creation, update, archive and resolve times of the
+// thread. It is used when importing threads from another instance, to
+// preserve their original times, and should not be used otherwise.
+func (t *discussionThreads) SetTimes(ctx context.Context, threadID int64, createdAt, updatedAt time.Time, archivedAt, resolvedAt *time.Time) error {
+	_, err := dbconn.Global.ExecContext(ctx, "UPDATE discussion_threads SET created_at=$1, updated_at=$2, archived_at=$3, resolved_at=$4 WHERE id=$5 AND deleted_at IS NULL", createdAt, updatedAt, archivedAt, resolvedAt, threadID)
+	return err
+}
+
+// SetImportID sets the ID that identifies an imported thread on the instance
+// that it was exported from, so that importing it again can be detected with
+// ImportedThreadExists.
+func (t *discussionThreads) SetImportID(ctx context.Context, threadID int64, importID string) error {
+	_, err := dbconn.Global.ExecContext(ctx, "UPDATE discussion_threads SET import_id=$1 WHERE id=$2 AND deleted_at IS NULL", importID, threadID)
+	return err
+}
+
+// ImportedThreadExists reports whether a (non-deleted) thread was imported
+// with the import ID.
+func (t *discussionThreads) ImportedThreadExists(ctx context.Context, importID string) (bool, error) {
+	if Mocks.DiscussionThreads.ImportedThreadExists != nil {
+		return Mocks.DiscussionThreads.ImportedThreadExists(ctx, importID)
+	}
+
+	var exists bool
+	err := dbconn.Global.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM discussion_threads WHERE import_id=$1 AND deleted_at IS NULL)", importID).Scan(&exists)
+	return exists, err
+}
+
+// checkDiscussionThreadAssignees returns an error if any of the users does not
+// exist or has been deleted, so that only existing users are assigned to
+// threads.
+func checkDiscussionThreadAssignees(ctx context.Context, userIDs []int32) error {
+	rows, err := dbconn.Global.QueryContext(ctx, "SELECT id FROM users WHERE id = ANY($1) AND deleted_at IS NULL", pq.Array(userIDs))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	exists := make(map[int32]bool, len(userIDs))
+	for rows.Next() {
+		var userID int32
+		if err := rows.Scan(&userID); err != nil {
+			return err
+		}
+		exists[userID] = true
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, userID := range userIDs {
+		if !exists[userID] {
+			return NewUserNotFoundError(userID)
+		}
+	}
+	return nil
+}
+
+const (
+	maxDiscussionThreadLabels      = 20
+	maxDiscussionThreadLabelLength = 100
+)
+
+// normalizeDiscussionThreadLabels trims whitespace from the labels, removes
+// duplicates and sorts them. It returns an error if a label is empty or too
+// long, or if there are too many labels.
+func normalizeDiscussionThreadLabels(labels []string) ([]string, error) {
+	set := make(map[string]struct{}, len(labels))
+	normalized := []string{} // not nil, because the labels column is NOT NULL
+	for _, label := range labels {
+		label = strings.TrimSpace(label)
+		if label == "" {
+			return nil, errors.New("label must be present (and not whitespace)")
+		}
+		if len([]rune(label)) > maxDiscussionThreadLabelLength {
+			return nil, fmt.Errorf("label too long (must be at most %d UTF-8 characters)", maxDiscussionThreadLabelLength)
+		}
+		if _, ok := set[label]; ok {
+			continue
+		}
+		set[label] = struct{}{}
+		normalized = append(normalized, label)
+	}
+	if len(normalized) > maxDiscussionThreadLabels {
+		return nil, fmt.Errorf("too many labels (must be at most %d)", maxDiscussionThreadLabels)
+	}
+	sort.Strings(normalized)
+	return normalized, nil
+}
+
 type DiscussionThreadsListOptions struct {
 	// LimitOffset specifies SQL LIMIT and OFFSET counts. It may be nil (no limit / offset).
 	*LimitOffset
@@ -279,6 +432,10 @@ type DiscussionThreadsListOptions struct {
 	CreatedBefore *time.Time
 	CreatedAfter  *time.Time
 
+	// ActiveAfter, when non-nil, specifies that only threads that were
+	// updated or received a new comment after this time should be returned.
+	ActiveAfter *time.Time
+
 	// Whether or not to return results in ascending (oldest first) order. When
 	// false, descending (latest first) order is used.
 	AscendingOrder bool
@@ -286,6 +443,20 @@ type DiscussionThreadsListOptions struct {
 	// Reported, when true, specifies that only threads with at least one
 	// reported comment should be returned.
 	Reported bool
+
+	// Resolved, when non-nil, specifies that only threads that are resolved
+	// (true) or open (false) should be returned.
+	Resolved *bool
+
+	// Labels, when len() > 0, specifies that only threads with all of these
+	// labels should be returned.
+	Labels    []string
+	NotLabels []string
+
+	// AssigneeUserIDs, when len() > 0, specifies that only threads assigned
+	// to one of these users should be returned.
+	AssigneeUserIDs    []int32
+	NotAssigneeUserIDs []int32
 }
 
 // SetFromQuery sets the options based on the search query string.
@@ -293,6 +464,14 @@ func (opts *DiscussionThreadsListOptions) SetFromQuery(ctx context.Context, quer
 	userList := func(value string) (users []*types.User) {
 		for _, username := range strings.Fields(value) {
 			username = strings.TrimSpace(strings.TrimPrefix(username, "@"))
+			if a := actor.FromContext(ctx); username == "me" && a.IsAuthenticated() {
+				// "me" refers to the current user.
+				user, err := Users.GetByID(ctx, a.UID)
+				if err == nil {
+					users = append(users, user)
+				}
+				continue
+			}
 			user, err := Users.GetByUsername(ctx, username)
 			if err != nil {
 				continue
@@ -308,6 +487,20 @@ func (opts *DiscussionThreadsListOptions) SetFromQuery(ctx context.Context, quer
 		return
 	}
 
+	setResolved := func(state string, negated bool) {
+		var resolved bool
+		switch strings.ToLower(state) {
+		case "open":
+			resolved = false
+		case "resolved":
+			resolved = true
+		default:
+			return
+		}
+		resolved = resolved != negated
+		opts.Resolved = &resolved
+	}
+
 	findInvolvedThreadIDs := func(value string) (threadIDs []int64) {
 		set := map[int64]struct{}{}
 		for _, user := range userList(value) {
@@ -421,6 +614,33 @@ func (opts *DiscussionThreadsListOptions) SetFromQuery(ctx context.Context, quer
 		"reported": func(value string) {
 			reported, _ = strconv.ParseBool(value)
 		},
+
+		// syntax: "is:open" or "is:resolved"
+		"is": func(value string) {
+			setResolved(value, false)
+		},
+		"-is": func(value string) {
+			setResolved(value, true)
+		},
+
+		// syntax: "label:security" or `label:"needs review"`
+		"label": func(value string) {
+			opts.Labels = append(opts.Labels, value)
+		},
+		"-label": func(value string) {
+			opts.NotLabels = append(opts.NotLabels, value)
+		},
+
+		// syntax: "assignee:me" or "assignee:@slimsag" or `assignee:"slimsag @jack"`
+		"assignee": func(value string) {
+			opts.AssigneeUserIDs = append(opts.AssigneeUserIDs, userIDsList(value)...)
+			if len(opts.AssigneeUserIDs) == 0 {
+				opts.AssigneeUserIDs = []int32{-1}
+			}
+		},
+		"-assignee": func(value string) {
+			opts.NotAssigneeUserIDs = append(opts.NotAssigneeUserIDs, userIDsList(value)...)
+		},
 	}
 	remaining, operations := searchquery.Parse(query)
 	for _, operation := range operations {
@@ -580,6 +800,28 @@ func (*discussionThreads) getListSQL(opts *DiscussionThreadsListOptions) (conds
 	if opts.CreatedAfter != nil {
 		conds = append(conds, sqlf.Sprintf("created_at > %v", *opts.CreatedAfter))
 	}
+	if opts.ActiveAfter != nil {
+		conds = append(conds, sqlf.Sprintf("(updated_at > %v OR id IN (SELECT thread_id FROM discussion_comments WHERE created_at > %v AND deleted_at IS NULL))", *opts.ActiveAfter, *opts.ActiveAfter))
+	}
+	if opts.Resolved != nil {
+		if *opts.Resolved {
+			conds = append(conds, sqlf.Sprintf("resolved_at IS NOT NULL"))
+		} else {
+			conds = append(conds, sqlf.Sprintf("resolved_at IS NULL"))
+		}
+	}
+	if len(opts.Labels) > 0 {
+		conds = append(conds, sqlf.Sprintf("labels @> %v", pq.Array(opts.Labels)))
+	}
+	if len(opts.NotLabels) > 0 {
+		conds = append(conds, sqlf.Sprintf("NOT (labels && %v)", pq.Array(opts.NotLabels)))
+	}
+	if len(opts.AssigneeUserIDs) > 0 {
+		conds = append(conds, sqlf.Sprintf("id IN (SELECT thread_id FROM discussion_thread_assignees WHERE user_id = ANY(%v))", pq.Array(opts.AssigneeUserIDs)))
+	}
+	if len(opts.NotAssigneeUserIDs) > 0 {
+		conds = append(conds, sqlf.Sprintf("id NOT IN (SELECT thread_id FROM discussion_thread_assignees WHERE user_id = ANY(%v))", pq.Array(opts.NotAssigneeUserIDs)))
+	}
 
 	if opts.TargetRepoID != nil || opts.TargetRepoPath != nil || opts.NotTargetRepoID != nil || opts.NotTargetRepoPath != nil {
 		targetRepoConds := []*sqlf.Query{}
@@ -671,7 +913,10 @@ func (t *discussionThreads) getBySQL(ctx context.Context, query string, args ...
 			t.target_repo_id,
 			t.created_at,
 			t.archived_at,
-			t.updated_at
+			t.updated_at,
+			t.resolved_at,
+			t.labels,
+			ARRAY(SELECT a.user_id FROM discussion_thread_assignees a JOIN users u ON u.id=a.user_id WHERE a.thread_id=t.id AND u.deleted_at IS NULL ORDER BY a.user_id)
 		FROM discussion_threads t `+query, args...)
 	if err != nil {
 		return nil, err
@@ -681,8 +926,9 @@ func (t *discussionThreads) getBySQL(ctx context.Context, query string, args ...
 	defer rows.Close()
 	for rows.Next() {
 		var (
-			thread       types.DiscussionThread
-			targetRepoID *int64
+			thread          types.DiscussionThread
+			targetRepoID    *int64
+			assigneeUserIDs []int64
 		)
 		err := rows.Scan(
 			&thread.ID,
@@ -692,10 +938,16 @@ func (t *discussionThreads) getBySQL(ctx context.Context, query string, args ...
 			&thread.CreatedAt,
 			&thread.ArchivedAt,
 			&thread.UpdatedAt,
+			&thread.ResolvedAt,
+			pq.Array(&thread.Labels),
+			pq.Array(&assigneeUserIDs),
 		)
 		if err != nil {
 			return nil, err
 		}
+		for _, userID := range assigneeUserIDs {
+			thread.AssigneeUserIDs = append(thread.AssigneeUserIDs, int32(userID))
+		}
 		if targetRepoID != nil {
 			thread.TargetRepo, err = t.getTargetRepo(ctx, *targetRepoID)
 			if err != nil {
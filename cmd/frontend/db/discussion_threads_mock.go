@@ -12,6 +12,8 @@ type MockDiscussionThreads struct {
 	Update func(ctx context.Context, threadID int64, opts *DiscussionThreadsUpdateOptions) (*types.DiscussionThread, error)
 	List   func(ctx context.Context, opt *DiscussionThreadsListOptions) ([]*types.DiscussionThread, error)
 	Count  func(ctx context.Context, opt *DiscussionThreadsListOptions) (int, error)
+
+	ImportedThreadExists func(ctx context.Context, importID string) (bool, error)
 }
 
 func (s *MockDiscussionThreads) MockCreate_Return(t *testing.T, returns *types.DiscussionThread, returnsErr error) (called *bool, calledWith *types.DiscussionThread) {
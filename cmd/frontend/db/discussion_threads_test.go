@@ -2,12 +2,14 @@ package db
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
 	"github.com/sourcegraph/sourcegraph/pkg/api"
 	"github.com/sourcegraph/sourcegraph/pkg/db/dbtesting"
+	"github.com/sourcegraph/sourcegraph/pkg/errcode"
 )
 
 // TODO(slimsag:discussions): future: test that DiscussionThreadsListOptions.AuthorUserID works
@@ -124,6 +126,200 @@ func TestDiscussionThreads_Update(t *testing.T) {
 	}
 }
 
+func TestDiscussionThreads_ResolveLabelsAssignees(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	ctx := dbtesting.TestContext(t)
+
+	user, err := Users.Create(ctx, NewUser{
+		Email:                 "a@a.com",
+		Username:              "u",
+		Password:              "p",
+		EmailVerificationCode: "c",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	user2, err := Users.Create(ctx, NewUser{
+		Email:                 "b@b.com",
+		Username:              "u2",
+		Password:              "p",
+		EmailVerificationCode: "c",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Create a repository to comply with the postgres repo constraint.
+	if err := Repos.Upsert(ctx, api.InsertRepoOp{Name: "myrepo", Description: "", Fork: false, Enabled: true}); err != nil {
+		t.Fatal(err)
+	}
+	repo, err := Repos.GetByName(ctx, "myrepo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Create the threads.
+	var threads []*types.DiscussionThread
+	for _, title := range []string{"Fix this", "Looks good"} {
+		thread, err := DiscussionThreads.Create(ctx, &types.DiscussionThread{
+			AuthorUserID: user.ID,
+			Title:        title,
+			TargetRepo: &types.DiscussionThreadTargetRepo{
+				RepoID: repo.ID,
+				Path:   strPtr("foo/bar/mux.go"),
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		threads = append(threads, thread)
+	}
+
+	// Label and assign the first thread.
+	labels := []string{" security", "bug", "security"}
+	gotThread, err := DiscussionThreads.Update(ctx, threads[0].ID, &DiscussionThreadsUpdateOptions{
+		Labels:             &labels,
+		AddAssigneeUserIDs: []int32{user2.ID, user.ID, user2.ID},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"bug", "security"}; !reflect.DeepEqual(gotThread.Labels, want) {
+		t.Errorf("got labels %q, want %q", gotThread.Labels, want)
+	}
+	if want := []int32{user.ID, user2.ID}; !reflect.DeepEqual(gotThread.AssigneeUserIDs, want) {
+		t.Errorf("got assignees %v, want %v", gotThread.AssigneeUserIDs, want)
+	}
+	gotThread, err = DiscussionThreads.Update(ctx, threads[0].ID, &DiscussionThreadsUpdateOptions{
+		RemoveAssigneeUserIDs: []int32{user.ID},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int32{user2.ID}; !reflect.DeepEqual(gotThread.AssigneeUserIDs, want) {
+		t.Errorf("got assignees %v, want %v", gotThread.AssigneeUserIDs, want)
+	}
+
+	// Nonexistent and deleted users can't be assigned, and deleted users are
+	// no longer listed as assignees.
+	user3, err := Users.Create(ctx, NewUser{
+		Email:                 "c@c.com",
+		Username:              "u3",
+		Password:              "p",
+		EmailVerificationCode: "c",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DiscussionThreads.Update(ctx, threads[0].ID, &DiscussionThreadsUpdateOptions{
+		AddAssigneeUserIDs: []int32{user3.ID},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Users.Delete(ctx, user3.ID); err != nil {
+		t.Fatal(err)
+	}
+	for _, userID := range []int32{user3.ID, 12345} {
+		_, err := DiscussionThreads.Update(ctx, threads[0].ID, &DiscussionThreadsUpdateOptions{
+			AddAssigneeUserIDs: []int32{userID},
+		})
+		if !errcode.IsNotFound(err) {
+			t.Errorf("user %d: got error %v, want not found", userID, err)
+		}
+	}
+	gotThread, err = DiscussionThreads.Get(ctx, threads[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int32{user2.ID}; !reflect.DeepEqual(gotThread.AssigneeUserIDs, want) {
+		t.Errorf("got assignees %v, want %v", gotThread.AssigneeUserIDs, want)
+	}
+
+	// Resolve the second thread.
+	gotThread, err = DiscussionThreads.Update(ctx, threads[1].ID, &DiscussionThreadsUpdateOptions{
+		Resolve: boolPtr(true),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotThread.ResolvedAt == nil {
+		t.Fatal("expected thread to be resolved")
+	}
+
+	tests := []struct {
+		opts *DiscussionThreadsListOptions
+		want []int64
+	}{
+		{opts: &DiscussionThreadsListOptions{Resolved: boolPtr(false)}, want: []int64{threads[0].ID}},
+		{opts: &DiscussionThreadsListOptions{Resolved: boolPtr(true)}, want: []int64{threads[1].ID}},
+		{opts: &DiscussionThreadsListOptions{Labels: []string{"security", "bug"}}, want: []int64{threads[0].ID}},
+		{opts: &DiscussionThreadsListOptions{Labels: []string{"security", "other"}}},
+		{opts: &DiscussionThreadsListOptions{NotLabels: []string{"bug"}}, want: []int64{threads[1].ID}},
+		{opts: &DiscussionThreadsListOptions{AssigneeUserIDs: []int32{user2.ID}}, want: []int64{threads[0].ID}},
+		{opts: &DiscussionThreadsListOptions{AssigneeUserIDs: []int32{user.ID}}},
+		{opts: &DiscussionThreadsListOptions{NotAssigneeUserIDs: []int32{user2.ID}}, want: []int64{threads[1].ID}},
+	}
+	for _, test := range tests {
+		test.opts.AscendingOrder = true
+		threads, err := DiscussionThreads.List(ctx, test.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []int64
+		for _, thread := range threads {
+			got = append(got, thread.ID)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%+v: got threads %v, want %v", test.opts, got, test.want)
+		}
+	}
+
+	// Repeated assignee: filters are combined.
+	var opts DiscussionThreadsListOptions
+	opts.SetFromQuery(ctx, "assignee:u assignee:@u2 -assignee:nobody")
+	if want := []int32{user.ID, user2.ID}; !reflect.DeepEqual(opts.AssigneeUserIDs, want) {
+		t.Errorf("got assignee filter %v, want %v", opts.AssigneeUserIDs, want)
+	}
+
+	// Reopen the second thread.
+	gotThread, err = DiscussionThreads.Update(ctx, threads[1].ID, &DiscussionThreadsUpdateOptions{
+		Resolve: boolPtr(false),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotThread.ResolvedAt != nil {
+		t.Fatal("expected thread to be open")
+	}
+}
+
+func TestNormalizeDiscussionThreadLabels(t *testing.T) {
+	got, err := normalizeDiscussionThreadLabels([]string{"security ", "bug", "security"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"bug", "security"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	got, err = normalizeDiscussionThreadLabels(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got == nil || len(got) != 0 {
+		t.Errorf("got %#v, want empty non-nil slice", got)
+	}
+
+	if _, err := normalizeDiscussionThreadLabels([]string{" "}); err == nil {
+		t.Error("got nil error for empty label")
+	}
+	if _, err := normalizeDiscussionThreadLabels([]string{strings.Repeat("x", maxDiscussionThreadLabelLength+1)}); err == nil {
+		t.Error("got nil error for long label")
+	}
+}
+
 func TestDiscussionThreads_Count(t *testing.T) {
 	if testing.Short() {
 		t.Skip()
@@ -252,6 +448,71 @@ func TestDiscussionThreads_List(t *testing.T) {
 	}
 }
 
+func TestDiscussionThreads_ListActiveAfter(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	ctx := dbtesting.TestContext(t)
+
+	user, err := Users.Create(ctx, NewUser{
+		Email:                 "a@a.com",
+		Username:              "u",
+		Password:              "p",
+		EmailVerificationCode: "c",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Create a repository to comply with the postgres repo constraint.
+	if err := Repos.Upsert(ctx, api.InsertRepoOp{Name: "myrepo", Description: "", Fork: false, Enabled: true}); err != nil {
+		t.Fatal(err)
+	}
+	repo, err := Repos.GetByName(ctx, "myrepo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var threads []*types.DiscussionThread
+	for i := 0; i < 2; i++ {
+		thread, err := DiscussionThreads.Create(ctx, &types.DiscussionThread{
+			AuthorUserID: user.ID,
+			Title:        "Hello world!",
+			TargetRepo:   &types.DiscussionThreadTargetRepo{RepoID: repo.ID},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		threads = append(threads, thread)
+	}
+	since := threads[1].UpdatedAt
+
+	// No thread was active after the last one was created.
+	got, err := DiscussionThreads.List(ctx, &DiscussionThreadsListOptions{ActiveAfter: &since})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Fatalf("got %d threads, want 0", len(got))
+	}
+
+	// A new comment makes its thread active.
+	if _, err := DiscussionComments.Create(ctx, &types.DiscussionComment{
+		ThreadID:     threads[0].ID,
+		AuthorUserID: user.ID,
+		Contents:     "Hello again!",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	got, err = DiscussionThreads.List(ctx, &DiscussionThreadsListOptions{ActiveAfter: &since})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].ID != threads[0].ID {
+		t.Fatalf("got %+v, want only thread %d", got, threads[0].ID)
+	}
+}
+
 func TestDiscussionThreads_Delete(t *testing.T) {
 	if testing.Short() {
 		t.Skip()
@@ -319,6 +580,66 @@ func TestDiscussionThreads_Delete(t *testing.T) {
 	}
 }
 
+func TestDiscussionThreads_ImportID(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	ctx := dbtesting.TestContext(t)
+
+	user, err := Users.Create(ctx, NewUser{
+		Email:                 "a@a.com",
+		Username:              "u",
+		Password:              "p",
+		EmailVerificationCode: "c",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Create a repository to comply with the postgres repo constraint.
+	if err := Repos.Upsert(ctx, api.InsertRepoOp{Name: "myrepo", Description: "", Fork: false, Enabled: true}); err != nil {
+		t.Fatal(err)
+	}
+	repo, err := Repos.GetByName(ctx, "myrepo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	thread, err := DiscussionThreads.Create(ctx, &types.DiscussionThread{
+		AuthorUserID: user.ID,
+		Title:        "Hello world!",
+		TargetRepo:   &types.DiscussionThreadTargetRepo{RepoID: repo.ID},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const importID = "https://sourcegraph.example.com#1"
+	if exists, err := DiscussionThreads.ImportedThreadExists(ctx, importID); err != nil {
+		t.Fatal(err)
+	} else if exists {
+		t.Error("got exists == true before setting the import ID, want false")
+	}
+	if err := DiscussionThreads.SetImportID(ctx, thread.ID, importID); err != nil {
+		t.Fatal(err)
+	}
+	if exists, err := DiscussionThreads.ImportedThreadExists(ctx, importID); err != nil {
+		t.Fatal(err)
+	} else if !exists {
+		t.Error("got exists == false after setting the import ID, want true")
+	}
+
+	// A deleted thread does not count as imported, so it can be imported again.
+	if _, err := DiscussionThreads.Update(ctx, thread.ID, &DiscussionThreadsUpdateOptions{Delete: true}); err != nil {
+		t.Fatal(err)
+	}
+	if exists, err := DiscussionThreads.ImportedThreadExists(ctx, importID); err != nil {
+		t.Fatal(err)
+	} else if exists {
+		t.Error("got exists == true after deleting the thread, want false")
+	}
+}
+
 func boolPtr(b bool) *bool {
 	return &b
 }
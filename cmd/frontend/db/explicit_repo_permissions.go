@@ -0,0 +1,154 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/keegancsmith/sqlf"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/db/dbconn"
+)
+
+type explicitRepoPermissionNotFoundError struct {
+	id int64
+}
+
+func (err explicitRepoPermissionNotFoundError) Error() string {
+	return fmt.Sprintf("explicit repository permission not found: %d", err.id)
+}
+
+func (err explicitRepoPermissionNotFoundError) NotFound() bool {
+	return true
+}
+
+// explicitRepoPermissions provides access to the `explicit_repo_permissions` table, which stores
+// the repository read permissions that site admins grant to users and organizations. They are
+// enforced for repositories whose permissions don't come from a code host (such as those of
+// Gitolite or "Other" external services).
+//
+// Permissions of deleted users and organizations are never returned.
+type explicitRepoPermissions struct{}
+
+// Create saves a new permission, and sets its ID and CreatedAt fields.
+func (*explicitRepoPermissions) Create(ctx context.Context, p *types.ExplicitRepoPermission) error {
+	if Mocks.ExplicitRepoPermissions.Create != nil {
+		return Mocks.ExplicitRepoPermissions.Create(ctx, p)
+	}
+
+	if (p.RepoID == 0) == (p.RepoPattern == "") {
+		return errors.New("exactly one of a repository and a repository pattern must be specified")
+	}
+	if (p.UserID == 0) == (p.OrgID == 0) {
+		return errors.New("exactly one of a user and an organization must be specified")
+	}
+	if p.RepoPattern != "" {
+		if _, err := regexp.Compile(p.RepoPattern); err != nil {
+			return fmt.Errorf("invalid repository pattern: %s", err)
+		}
+	}
+
+	q := sqlf.Sprintf("INSERT INTO explicit_repo_permissions(repo_id, repo_pattern, user_id, org_id) VALUES(%s, %s, %s, %s) RETURNING id, created_at",
+		sql.NullInt64{Int64: int64(p.RepoID), Valid: p.RepoID != 0},
+		sql.NullString{String: p.RepoPattern, Valid: p.RepoPattern != ""},
+		sql.NullInt64{Int64: int64(p.UserID), Valid: p.UserID != 0},
+		sql.NullInt64{Int64: int64(p.OrgID), Valid: p.OrgID != 0},
+	)
+	return dbconn.Global.QueryRowContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...).Scan(&p.ID, &p.CreatedAt)
+}
+
+// GetByID returns the permission with the given ID.
+func (s *explicitRepoPermissions) GetByID(ctx context.Context, id int64) (*types.ExplicitRepoPermission, error) {
+	if Mocks.ExplicitRepoPermissions.GetByID != nil {
+		return Mocks.ExplicitRepoPermissions.GetByID(ctx, id)
+	}
+
+	perms, err := s.list(ctx, []*sqlf.Query{sqlf.Sprintf("p.id=%s", id)}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(perms) == 0 {
+		return nil, explicitRepoPermissionNotFoundError{id}
+	}
+	return perms[0], nil
+}
+
+// ExplicitRepoPermissionsListOptions contains options for listing explicit repository permissions.
+type ExplicitRepoPermissionsListOptions struct {
+	*LimitOffset
+}
+
+// List returns the permissions, oldest first.
+func (s *explicitRepoPermissions) List(ctx context.Context, opt ExplicitRepoPermissionsListOptions) ([]*types.ExplicitRepoPermission, error) {
+	if Mocks.ExplicitRepoPermissions.List != nil {
+		return Mocks.ExplicitRepoPermissions.List(ctx, opt)
+	}
+	return s.list(ctx, nil, opt.LimitOffset)
+}
+
+// Count counts the permissions.
+func (s *explicitRepoPermissions) Count(ctx context.Context, opt ExplicitRepoPermissionsListOptions) (int, error) {
+	q := sqlf.Sprintf("SELECT COUNT(*) %s", s.fromSQL(nil))
+	var count int
+	err := dbconn.Global.QueryRowContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...).Scan(&count)
+	return count, err
+}
+
+func (*explicitRepoPermissions) fromSQL(conds []*sqlf.Query) *sqlf.Query {
+	conds = append(conds,
+		sqlf.Sprintf("(p.user_id IS NULL OR EXISTS (SELECT 1 FROM users WHERE users.id=p.user_id AND users.deleted_at IS NULL))"),
+		sqlf.Sprintf("(p.org_id IS NULL OR EXISTS (SELECT 1 FROM orgs WHERE orgs.id=p.org_id AND orgs.deleted_at IS NULL))"),
+	)
+	return sqlf.Sprintf("FROM explicit_repo_permissions p LEFT JOIN repo ON repo.id=p.repo_id WHERE %s", sqlf.Join(conds, "AND"))
+}
+
+func (s *explicitRepoPermissions) list(ctx context.Context, conds []*sqlf.Query, limitOffset *LimitOffset) ([]*types.ExplicitRepoPermission, error) {
+	q := sqlf.Sprintf("SELECT p.id, p.repo_id, repo.name, p.repo_pattern, p.user_id, p.org_id, p.created_at %s ORDER BY p.id ASC %s", s.fromSQL(conds), limitOffset.SQL())
+	rows, err := dbconn.Global.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var perms []*types.ExplicitRepoPermission
+	for rows.Next() {
+		var (
+			p                     types.ExplicitRepoPermission
+			repoID, userID, orgID sql.NullInt64
+			repoName, repoPattern sql.NullString
+		)
+		if err := rows.Scan(&p.ID, &repoID, &repoName, &repoPattern, &userID, &orgID, &p.CreatedAt); err != nil {
+			return nil, err
+		}
+		p.RepoID = api.RepoID(repoID.Int64)
+		p.RepoName = api.RepoName(repoName.String)
+		p.RepoPattern = repoPattern.String
+		p.UserID = int32(userID.Int64)
+		p.OrgID = int32(orgID.Int64)
+		perms = append(perms, &p)
+	}
+	return perms, rows.Err()
+}
+
+// Delete deletes the permission with the given ID.
+func (*explicitRepoPermissions) Delete(ctx context.Context, id int64) error {
+	if Mocks.ExplicitRepoPermissions.Delete != nil {
+		return Mocks.ExplicitRepoPermissions.Delete(ctx, id)
+	}
+
+	res, err := dbconn.Global.ExecContext(ctx, "DELETE FROM explicit_repo_permissions WHERE id=$1", id)
+	if err != nil {
+		return err
+	}
+	nrows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if nrows == 0 {
+		return explicitRepoPermissionNotFoundError{id}
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+package db
+
+import (
+	"context"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+)
+
+type MockExplicitRepoPermissions struct {
+	Create  func(ctx context.Context, p *types.ExplicitRepoPermission) error
+	GetByID func(ctx context.Context, id int64) (*types.ExplicitRepoPermission, error)
+	List    func(ctx context.Context, opt ExplicitRepoPermissionsListOptions) ([]*types.ExplicitRepoPermission, error)
+	Delete  func(ctx context.Context, id int64) error
+}
@@ -0,0 +1,94 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/db/dbtesting"
+	"github.com/sourcegraph/sourcegraph/pkg/errcode"
+)
+
+func TestExplicitRepoPermissions(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	ctx := dbtesting.TestContext(t)
+
+	user, err := Users.Create(ctx, NewUser{Username: "u"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	org, err := Orgs.Create(ctx, "o", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Repos.Upsert(ctx, api.InsertRepoOp{Name: "myrepo", Enabled: true}); err != nil {
+		t.Fatal(err)
+	}
+	repo, err := Repos.GetByName(ctx, "myrepo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, invalid := range []*types.ExplicitRepoPermission{
+		{UserID: user.ID},
+		{RepoID: repo.ID, RepoPattern: "^x", UserID: user.ID},
+		{RepoID: repo.ID},
+		{RepoID: repo.ID, UserID: user.ID, OrgID: org.ID},
+		{RepoPattern: "(", UserID: user.ID},
+	} {
+		if err := ExplicitRepoPermissions.Create(ctx, invalid); err == nil {
+			t.Errorf("got nil error creating %+v, want error", invalid)
+		}
+	}
+
+	p1 := &types.ExplicitRepoPermission{RepoID: repo.ID, UserID: user.ID}
+	p2 := &types.ExplicitRepoPermission{RepoPattern: "^gitolite\\.example\\.com/", OrgID: org.ID}
+	for _, p := range []*types.ExplicitRepoPermission{p1, p2} {
+		if err := ExplicitRepoPermissions.Create(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := ExplicitRepoPermissions.GetByID(ctx, p1.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.RepoID != repo.ID || got.RepoName != "myrepo" || got.RepoPattern != "" || got.UserID != user.ID || got.OrgID != 0 {
+		t.Errorf("got %+v, want permission for user on myrepo", got)
+	}
+
+	perms, err := ExplicitRepoPermissions.List(ctx, ExplicitRepoPermissionsListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(perms) != 2 || perms[0].ID != p1.ID || perms[1].ID != p2.ID || perms[1].RepoPattern != p2.RepoPattern || perms[1].OrgID != org.ID {
+		t.Errorf("got %+v, want both permissions", perms)
+	}
+	if count, err := ExplicitRepoPermissions.Count(ctx, ExplicitRepoPermissionsListOptions{}); err != nil {
+		t.Fatal(err)
+	} else if count != 2 {
+		t.Errorf("got count %d, want 2", count)
+	}
+
+	// Permissions of deleted users are not returned.
+	if err := Users.Delete(ctx, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ExplicitRepoPermissions.GetByID(ctx, p1.ID); !errcode.IsNotFound(err) {
+		t.Errorf("got error %v, want NotFound for permission of deleted user", err)
+	}
+
+	if err := ExplicitRepoPermissions.Delete(ctx, p2.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := ExplicitRepoPermissions.Delete(ctx, p2.ID); !errcode.IsNotFound(err) {
+		t.Errorf("got error %v, want NotFound", err)
+	}
+	if perms, err := ExplicitRepoPermissions.List(ctx, ExplicitRepoPermissionsListOptions{}); err != nil {
+		t.Fatal(err)
+	} else if len(perms) != 0 {
+		t.Errorf("got %+v after delete, want none", perms)
+	}
+}
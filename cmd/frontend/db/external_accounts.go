@@ -196,6 +196,15 @@ func (*userExternalAccounts) Delete(ctx context.Context, id int32) error {
 type ExternalAccountsListOptions struct {
 	UserID                           int32
 	ServiceType, ServiceID, ClientID string
+
+	// AnyClientID, when true, specifies that accounts with the ServiceType and ServiceID should be
+	// returned regardless of their client ID (instead of only those with ClientID).
+	AnyClientID bool
+
+	// AccountID, when non-empty, specifies that only accounts with this ID on the external
+	// service should be returned.
+	AccountID string
+
 	*LimitOffset
 }
 
@@ -296,9 +305,14 @@ func (*userExternalAccounts) listSQL(opt ExternalAccountsListOptions) (conds []*
 	if opt.UserID != 0 {
 		conds = append(conds, sqlf.Sprintf("user_id=%d", opt.UserID))
 	}
-	if opt.ServiceType != "" || opt.ServiceID != "" || opt.ClientID != "" {
+	if opt.AnyClientID {
+		conds = append(conds, sqlf.Sprintf("(service_type=%s AND service_id=%s)", opt.ServiceType, opt.ServiceID))
+	} else if opt.ServiceType != "" || opt.ServiceID != "" || opt.ClientID != "" {
 		conds = append(conds, sqlf.Sprintf("(service_type=%s AND service_id=%s AND client_id=%s)", opt.ServiceType, opt.ServiceID, opt.ClientID))
 	}
+	if opt.AccountID != "" {
+		conds = append(conds, sqlf.Sprintf("account_id=%s", opt.AccountID))
+	}
 	return conds
 }
 
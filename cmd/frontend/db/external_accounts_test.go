@@ -115,3 +115,42 @@ func simplifyExternalAccount(account *extsvc.ExternalAccount) {
 	account.CreatedAt = time.Time{}
 	account.UpdatedAt = time.Time{}
 }
+
+func TestExternalAccounts_ListByAccountID(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	ctx := dbtesting.TestContext(t)
+
+	spec := extsvc.ExternalAccountSpec{
+		ServiceType: "xa",
+		ServiceID:   "xb",
+		ClientID:    "xc",
+		AccountID:   "xd",
+	}
+	userID, err := ExternalAccounts.CreateUserAndSave(ctx, NewUser{Username: "u"}, spec, extsvc.ExternalAccountData{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec2 := spec
+	spec2.AccountID = "xe"
+	if _, err := ExternalAccounts.CreateUserAndSave(ctx, NewUser{Username: "u2"}, spec2, extsvc.ExternalAccountData{}); err != nil {
+		t.Fatal(err)
+	}
+
+	accounts, err := ExternalAccounts.List(ctx, ExternalAccountsListOptions{ServiceType: "xa", ServiceID: "xb", AnyClientID: true, AccountID: "xd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accounts) != 1 || accounts[0].UserID != userID {
+		t.Errorf("got accounts %+v, want only the account of user %d", accounts, userID)
+	}
+
+	accounts, err = ExternalAccounts.List(ctx, ExternalAccountsListOptions{ServiceType: "xa", ServiceID: "other", AnyClientID: true, AccountID: "xd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accounts) != 0 {
+		t.Errorf("got accounts %+v for other service, want none", accounts)
+	}
+}
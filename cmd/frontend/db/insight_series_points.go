@@ -0,0 +1,128 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/keegancsmith/sqlf"
+	"github.com/lib/pq"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/db/dbconn"
+)
+
+// insightSeriesPoints records the data points of code insight series: the
+// number of files matching a search query in a repository's default branch as
+// of a point in time.
+//
+// Points are keyed on the query (not on the series that defines it), so series
+// with the same query share their points.
+type insightSeriesPoints struct{}
+
+// Record records the number of files matching the query in the repository as
+// of the given time, when its default branch was at commitID (or "" if the
+// repository had no commits yet). An existing point for the same query,
+// repository and time is replaced.
+func (*insightSeriesPoints) Record(ctx context.Context, query string, repoID api.RepoID, t time.Time, commitID api.CommitID, value int) error {
+	var commit *string
+	if commitID != "" {
+		commit = (*string)(&commitID)
+	}
+	q := sqlf.Sprintf(`
+INSERT INTO insight_series_points(query, repo_id, time, commit_id, value) VALUES(%s, %s, %s, %s, %s)
+ON CONFLICT (query, repo_id, time) DO UPDATE SET commit_id=EXCLUDED.commit_id, value=EXCLUDED.value, recorded_at=now()`,
+		query, repoID, t.UTC(), commit, value)
+	_, err := dbconn.Global.ExecContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	return err
+}
+
+// RecordedTimes returns the times at which points for the query in the
+// repository have been recorded.
+func (*insightSeriesPoints) RecordedTimes(ctx context.Context, query string, repoID api.RepoID) (map[time.Time]bool, error) {
+	q := sqlf.Sprintf("SELECT time FROM insight_series_points WHERE query=%s AND repo_id=%s", query, repoID)
+	rows, err := dbconn.Global.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	times := map[time.Time]bool{}
+	for rows.Next() {
+		var t time.Time
+		if err := rows.Scan(&t); err != nil {
+			return nil, err
+		}
+		times[t.UTC()] = true
+	}
+	return times, rows.Err()
+}
+
+// InsightSeriesOptions specifies the options for InsightSeriesPoints.Series.
+type InsightSeriesOptions struct {
+	Query string
+
+	// RepoIDs are the repositories whose points are summed. Callers must only
+	// pass repositories that the current user may read, because the points
+	// were recorded by an internal actor for all repositories.
+	RepoIDs []api.RepoID
+
+	// Times are the points in time to return the series's values at.
+	Times []time.Time
+}
+
+// Series returns the number of files matching the query at each of opt.Times,
+// summed over the repositories, in the same order. Repositories whose point at
+// a time was not yet recorded do not contribute to the value at that time.
+func (*insightSeriesPoints) Series(ctx context.Context, opt InsightSeriesOptions) ([]*types.InsightSeriesPoint, error) {
+	if Mocks.InsightSeriesPoints.Series != nil {
+		return Mocks.InsightSeriesPoints.Series(ctx, opt)
+	}
+
+	times := make([]string, len(opt.Times))
+	for i, t := range opt.Times {
+		times[i] = t.UTC().Format(time.RFC3339)
+	}
+	ids := make([]int64, len(opt.RepoIDs))
+	for i, id := range opt.RepoIDs {
+		ids[i] = int64(id)
+	}
+	conds := []*sqlf.Query{
+		sqlf.Sprintf("p.query=%s", opt.Query),
+		sqlf.Sprintf("p.time = ANY(%v::timestamptz[])", pq.Array(times)),
+		sqlf.Sprintf("p.repo_id = ANY(%v)", pq.Array(ids)),
+		sqlf.Sprintf("repo.enabled"),
+	}
+	q := sqlf.Sprintf(`
+SELECT p.time, SUM(p.value), COUNT(*)
+FROM insight_series_points p
+JOIN repo ON repo.id = p.repo_id
+WHERE %s
+GROUP BY p.time`, sqlf.Join(conds, "AND"))
+
+	rows, err := dbconn.Global.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	byTime := map[time.Time]*types.InsightSeriesPoint{}
+	for rows.Next() {
+		var p types.InsightSeriesPoint
+		if err := rows.Scan(&p.Time, &p.Value, &p.Repositories); err != nil {
+			return nil, err
+		}
+		byTime[p.Time.UTC()] = &p
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	points := make([]*types.InsightSeriesPoint, len(opt.Times))
+	for i, t := range opt.Times {
+		if p, ok := byTime[t.UTC()]; ok {
+			p.Time = t
+			points[i] = p
+		} else {
+			points[i] = &types.InsightSeriesPoint{Time: t}
+		}
+	}
+	return points, nil
+}
@@ -0,0 +1,11 @@
+package db
+
+import (
+	"context"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+)
+
+type MockInsightSeriesPoints struct {
+	Series func(ctx context.Context, opt InsightSeriesOptions) ([]*types.InsightSeriesPoint, error)
+}
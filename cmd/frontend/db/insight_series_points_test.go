@@ -0,0 +1,96 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/db/dbtesting"
+)
+
+func TestInsightSeriesPoints(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	ctx := dbtesting.TestContext(t)
+
+	var repoIDs []api.RepoID
+	for _, name := range []api.RepoName{"a", "b"} {
+		if err := Repos.Upsert(ctx, api.InsertRepoOp{Name: name, Enabled: true}); err != nil {
+			t.Fatal(err)
+		}
+		repo, err := Repos.GetByName(ctx, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		repoIDs = append(repoIDs, repo.ID)
+	}
+	a, b := repoIDs[0], repoIDs[1]
+
+	t1 := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	t2 := time.Date(2018, 2, 1, 0, 0, 0, 0, time.UTC)
+	t3 := time.Date(2018, 3, 1, 0, 0, 0, 0, time.UTC)
+	for _, p := range []struct {
+		query    string
+		repoID   api.RepoID
+		time     time.Time
+		commitID api.CommitID
+		value    int
+	}{
+		{"q", a, t1, "", 0},
+		{"q", a, t2, "c1", 3},
+		{"q", b, t2, "c2", 4},
+		{"q", b, t2, "c3", 5}, // replaces the previous point
+		{"other", a, t2, "c1", 7},
+	} {
+		if err := InsightSeriesPoints.Record(ctx, p.query, p.repoID, p.time, p.commitID, p.value); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if times, err := InsightSeriesPoints.RecordedTimes(ctx, "q", a); err != nil {
+		t.Fatal(err)
+	} else if want := map[time.Time]bool{t1: true, t2: true}; !reflect.DeepEqual(times, want) {
+		t.Errorf("got recorded times %v, want %v", times, want)
+	}
+
+	tests := map[string]struct {
+		repoIDs []api.RepoID
+		want    []*types.InsightSeriesPoint
+	}{
+		"all repositories": {
+			repoIDs: []api.RepoID{a, b},
+			want: []*types.InsightSeriesPoint{
+				{Time: t1, Value: 0, Repositories: 1},
+				{Time: t2, Value: 8, Repositories: 2},
+				{Time: t3},
+			},
+		},
+		"no repositories": {
+			repoIDs: []api.RepoID{},
+			want:    []*types.InsightSeriesPoint{{Time: t1}, {Time: t2}, {Time: t3}},
+		},
+		"single repository": {
+			repoIDs: []api.RepoID{b},
+			want: []*types.InsightSeriesPoint{
+				{Time: t1},
+				{Time: t2, Value: 5, Repositories: 1},
+				{Time: t3},
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			points, err := InsightSeriesPoints.Series(ctx, InsightSeriesOptions{Query: "q", RepoIDs: test.repoIDs, Times: []time.Time{t1, t2, t3}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(points, test.want) {
+				t.Errorf("got %s, want %s", spew.Sdump(points), spew.Sdump(test.want))
+			}
+		})
+	}
+}
@@ -17,6 +17,9 @@ type MockStores struct {
 	Users      MockUsers
 	UserEmails MockUserEmails
 
+	UserPermissions         MockUserPermissions
+	ExplicitRepoPermissions MockExplicitRepoPermissions
+
 	Phabricator MockPhabricator
 
 	ExternalAccounts MockExternalAccounts
@@ -24,4 +27,6 @@ type MockStores struct {
 	OrgInvitations MockOrgInvitations
 
 	ExternalServices MockExternalServices
+
+	InsightSeriesPoints MockInsightSeriesPoints
 }
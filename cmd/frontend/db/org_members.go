@@ -16,6 +16,9 @@ import (
 
 type orgMembers struct{}
 
+// Create adds the user to the organization. Because members of an organization are granted its
+// repository permissions (see ExplicitRepoPermissions), it requests a sync of the user's
+// permissions.
 func (*orgMembers) Create(ctx context.Context, orgID, userID int32) (*types.OrgMembership, error) {
 	m := types.OrgMembership{
 		OrgID:  orgID,
@@ -33,6 +36,9 @@ func (*orgMembers) Create(ctx context.Context, orgID, userID int32) (*types.OrgM
 		}
 		return nil, err
 	}
+	if err := UserPermissions.RequestSync(ctx, userID); err != nil {
+		return nil, err
+	}
 	return &m, nil
 }
 
@@ -47,13 +53,20 @@ func (m *orgMembers) GetByOrgIDAndUserID(ctx context.Context, orgID, userID int3
 	return m.getOneBySQL(ctx, "INNER JOIN users ON org_members.user_id=users.id WHERE org_id=$1 AND user_id=$2 AND users.deleted_at IS NULL LIMIT 1", orgID, userID)
 }
 
+// Remove removes the user from the organization. Like Create, it requests a sync of the user's
+// permissions.
 func (*orgMembers) Remove(ctx context.Context, orgID, userID int32) error {
-	_, err := dbconn.Global.ExecContext(ctx, "DELETE FROM org_members WHERE (org_id=$1 AND user_id=$2)", orgID, userID)
-	return err
+	if _, err := dbconn.Global.ExecContext(ctx, "DELETE FROM org_members WHERE (org_id=$1 AND user_id=$2)", orgID, userID); err != nil {
+		return err
+	}
+	return UserPermissions.RequestSync(ctx, userID)
 }
 
 // GetByOrgID returns a list of all members of a given organization.
 func (*orgMembers) GetByOrgID(ctx context.Context, orgID int32) ([]*types.OrgMembership, error) {
+	if Mocks.OrgMembers.GetByOrgID != nil {
+		return Mocks.OrgMembers.GetByOrgID(ctx, orgID)
+	}
 	org, err := Orgs.GetByID(ctx, orgID)
 	if err != nil {
 		return nil, err
@@ -109,6 +122,8 @@ func (*orgMembers) getBySQL(ctx context.Context, query string, args ...interface
 // CreateMembershipInOrgsForAllUsers causes *ALL* users to become members of every org in the
 // orgNames list.
 //
+// It requests a sync of the permissions of the users who became members (see Create).
+//
 // The provided dbh is used as the DB handle to execute the query. It may be either a global
 // DB handle or a transaction. If nil, the global DB handle is used.
 func (*orgMembers) CreateMembershipInOrgsForAllUsers(ctx context.Context, dbh interface {
@@ -131,7 +146,9 @@ func (*orgMembers) CreateMembershipInOrgsForAllUsers(ctx context.Context, dbh in
 						  LEFT JOIN org_members ON org_members.org_id=org_ids.id AND
 									org_members.user_id=user_ids.id
 						  WHERE org_members.id is null)
-			INSERT INTO org_members(org_id,user_id) SELECT to_join.org_id, to_join.user_id FROM to_join;`,
+				 inserted AS (INSERT INTO org_members(org_id,user_id) SELECT to_join.org_id, to_join.user_id FROM to_join RETURNING user_id)
+			INSERT INTO user_permissions(user_id, sync_requested_at) SELECT DISTINCT user_id, now() FROM inserted
+			ON CONFLICT (user_id) DO UPDATE SET sync_requested_at=now();`,
 		sqlf.Join(orgNameVars, ","))
 
 	if dbh == nil {
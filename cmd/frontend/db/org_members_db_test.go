@@ -106,3 +106,59 @@ func TestOrgMembers_CreateMembershipInOrgsForAllUsers(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestOrgMembers_requestPermissionsSync(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := dbtesting.TestContext(t)
+
+	org, err := Orgs.Create(ctx, "org", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err := Users.Create(ctx, NewUser{Username: "u"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkRequested := func(what string) {
+		t.Helper()
+		p, err := UserPermissions.Get(ctx, user.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.SyncRequestedAt == nil || (p.SyncedAt != nil && !p.SyncRequestedAt.After(*p.SyncedAt)) {
+			t.Errorf("%s: got %+v, want permissions sync requested", what, p)
+		}
+	}
+
+	if _, err := OrgMembers.Create(ctx, org.ID, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	checkRequested("after adding member")
+
+	if err := UserPermissions.SetSynced(ctx, user.ID, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := OrgMembers.Remove(ctx, org.ID, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	checkRequested("after removing member")
+
+	if err := UserPermissions.SetSynced(ctx, user.ID, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := OrgMembers.CreateMembershipInOrgsForAllUsers(ctx, nil, []string{"org"}); err != nil {
+		t.Fatal(err)
+	}
+	checkRequested("after adding all users")
+
+	if err := UserPermissions.SetSynced(ctx, user.ID, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := Orgs.Delete(ctx, org.ID); err != nil {
+		t.Fatal(err)
+	}
+	checkRequested("after deleting organization")
+}
@@ -9,6 +9,7 @@ import (
 )
 
 type MockOrgMembers struct {
+	GetByOrgID          func(ctx context.Context, orgID int32) ([]*types.OrgMembership, error)
 	GetByOrgIDAndUserID func(ctx context.Context, orgID, userID int32) (*types.OrgMembership, error)
 }
 
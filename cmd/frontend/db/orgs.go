@@ -30,6 +30,10 @@ type orgs struct{}
 // GetByUserID returns a list of all organizations for the user. An empty slice is
 // returned if the user is not authenticated or is not a member of any org.
 func (*orgs) GetByUserID(ctx context.Context, userID int32) ([]*types.Org, error) {
+	if Mocks.Orgs.GetByUserID != nil {
+		return Mocks.Orgs.GetByUserID(ctx, userID)
+	}
+
 	rows, err := dbconn.Global.QueryContext(ctx, "SELECT orgs.id, orgs.name, orgs.display_name,  orgs.created_at, orgs.updated_at FROM org_members LEFT OUTER JOIN orgs ON org_members.org_id = orgs.id WHERE user_id=$1 AND orgs.deleted_at IS NULL", userID)
 	if err != nil {
 		return []*types.Org{}, err
@@ -269,6 +273,14 @@ func (o *orgs) Delete(ctx context.Context, id int32) error {
 		return err
 	}
 
+	// The members lose the repository permissions granted to the organization (see
+	// ExplicitRepoPermissions), so sync their permissions.
+	if _, err := tx.ExecContext(ctx, `
+INSERT INTO user_permissions(user_id, sync_requested_at) SELECT user_id, now() FROM org_members WHERE org_id=$1
+ON CONFLICT (user_id) DO UPDATE SET sync_requested_at=now()`, id); err != nil {
+		return err
+	}
+
 	return nil
 }
 
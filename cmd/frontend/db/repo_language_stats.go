@@ -0,0 +1,110 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/keegancsmith/sqlf"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/db/dbconn"
+	"github.com/sourcegraph/sourcegraph/pkg/inventory"
+)
+
+// repoLanguageStats records the language breakdown of repositories' default
+// branches over time.
+//
+// A recording (a "snapshot") consists of one row per language, all with the
+// same recorded_at. Snapshots are only recorded when the default branch
+// changes, so the breakdown of a repository as of a point in time is its most
+// recent snapshot at or before that time.
+type repoLanguageStats struct{}
+
+// LatestCommitID returns the commit at which the language breakdown of the
+// repository was most recently recorded, or "" if it was never recorded.
+func (*repoLanguageStats) LatestCommitID(ctx context.Context, repoID api.RepoID) (api.CommitID, error) {
+	q := sqlf.Sprintf("SELECT commit_id FROM repo_language_stats WHERE repo_id=%s ORDER BY recorded_at DESC LIMIT 1", repoID)
+	var commitID api.CommitID
+	err := dbconn.Global.QueryRowContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...).Scan(&commitID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return commitID, err
+}
+
+// Record records the language breakdown of the repository at the given commit
+// of its default branch. Nothing is recorded if langs is empty.
+func (*repoLanguageStats) Record(ctx context.Context, repoID api.RepoID, commitID api.CommitID, langs []*inventory.Lang) error {
+	if len(langs) == 0 {
+		return nil
+	}
+	values := make([]*sqlf.Query, 0, len(langs))
+	for _, l := range langs {
+		values = append(values, sqlf.Sprintf("(%s, %s, %s, %s)", repoID, commitID, l.Name, int64(l.TotalBytes)))
+	}
+	// All rows are inserted in one statement, so they share the same
+	// recorded_at (the transaction start time).
+	q := sqlf.Sprintf("INSERT INTO repo_language_stats(repo_id, commit_id, language, total_bytes) VALUES %s", sqlf.Join(values, ", "))
+	_, err := dbconn.Global.ExecContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	return err
+}
+
+// LanguageStatisticsSeriesOptions specifies the options for
+// RepoLanguageStats.Series.
+type LanguageStatisticsSeriesOptions struct {
+	// RepoID, if nonzero, limits the series to a single repository. Otherwise
+	// the breakdowns of all repositories are summed.
+	RepoID api.RepoID
+
+	// Dates are the points in time to return the language breakdown as of.
+	Dates []time.Time
+}
+
+// Series returns the language breakdown as of each of opt.Dates, in the same
+// order. Repositories whose language breakdown was not yet recorded at a date
+// do not contribute to the breakdown at that date.
+func (s *repoLanguageStats) Series(ctx context.Context, opt LanguageStatisticsSeriesOptions) ([]*types.LanguageStatisticsPoint, error) {
+	points := make([]*types.LanguageStatisticsPoint, 0, len(opt.Dates))
+	for _, date := range opt.Dates {
+		langs, err := s.asOf(ctx, opt.RepoID, date)
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, &types.LanguageStatisticsPoint{Date: date, Languages: langs})
+	}
+	return points, nil
+}
+
+func (*repoLanguageStats) asOf(ctx context.Context, repoID api.RepoID, date time.Time) ([]*types.LanguageStatistic, error) {
+	conds := []*sqlf.Query{sqlf.Sprintf("recorded_at <= %s", date)}
+	if repoID != 0 {
+		conds = append(conds, sqlf.Sprintf("repo_id = %s", repoID))
+	}
+	q := sqlf.Sprintf(`
+SELECT s.language, SUM(s.total_bytes)
+FROM repo_language_stats s
+JOIN (
+	SELECT DISTINCT ON (repo_id) repo_id, recorded_at
+	FROM repo_language_stats
+	WHERE %s
+	ORDER BY repo_id, recorded_at DESC
+) latest ON s.repo_id = latest.repo_id AND s.recorded_at = latest.recorded_at
+GROUP BY s.language
+ORDER BY SUM(s.total_bytes) DESC, s.language`, sqlf.Join(conds, "AND"))
+
+	rows, err := dbconn.Global.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	langs := []*types.LanguageStatistic{}
+	for rows.Next() {
+		var l types.LanguageStatistic
+		if err := rows.Scan(&l.Name, &l.TotalBytes); err != nil {
+			return nil, err
+		}
+		langs = append(langs, &l)
+	}
+	return langs, rows.Err()
+}
@@ -0,0 +1,94 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/db/dbtesting"
+	"github.com/sourcegraph/sourcegraph/pkg/inventory"
+)
+
+func TestRepoLanguageStats(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	ctx := dbtesting.TestContext(t)
+
+	var repoIDs []api.RepoID
+	for _, name := range []api.RepoName{"a", "b"} {
+		if err := Repos.Upsert(ctx, api.InsertRepoOp{Name: name, Enabled: true}); err != nil {
+			t.Fatal(err)
+		}
+		repo, err := Repos.GetByName(ctx, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		repoIDs = append(repoIDs, repo.ID)
+	}
+	a, b := repoIDs[0], repoIDs[1]
+
+	before := time.Now().Add(-time.Hour)
+	if err := RepoLanguageStats.Record(ctx, a, "c1", []*inventory.Lang{{Name: "JavaScript", TotalBytes: 10}, {Name: "Go", TotalBytes: 5}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := RepoLanguageStats.Record(ctx, b, "c2", []*inventory.Lang{{Name: "Go", TotalBytes: 6}}); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	middle := time.Now()
+	time.Sleep(10 * time.Millisecond)
+	if err := RepoLanguageStats.Record(ctx, a, "c3", []*inventory.Lang{{Name: "TypeScript", TotalBytes: 12}}); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	after := time.Now()
+
+	if commitID, err := RepoLanguageStats.LatestCommitID(ctx, a); err != nil {
+		t.Fatal(err)
+	} else if commitID != "c3" {
+		t.Errorf("got latest commit %q, want %q", commitID, "c3")
+	}
+
+	tests := map[string]struct {
+		repoID api.RepoID
+		want   [][]*types.LanguageStatistic
+	}{
+		"all repositories": {
+			want: [][]*types.LanguageStatistic{
+				{},
+				{{Name: "Go", TotalBytes: 11}, {Name: "JavaScript", TotalBytes: 10}},
+				{{Name: "TypeScript", TotalBytes: 12}, {Name: "Go", TotalBytes: 6}},
+			},
+		},
+		"one repository": {
+			repoID: a,
+			want: [][]*types.LanguageStatistic{
+				{},
+				{{Name: "JavaScript", TotalBytes: 10}, {Name: "Go", TotalBytes: 5}},
+				{{Name: "TypeScript", TotalBytes: 12}},
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			points, err := RepoLanguageStats.Series(ctx, LanguageStatisticsSeriesOptions{
+				RepoID: test.repoID,
+				Dates:  []time.Time{before, middle, after},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got [][]*types.LanguageStatistic
+			for _, p := range points {
+				got = append(got, p.Languages)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %s, want %s", spew.Sdump(got), spew.Sdump(test.want))
+			}
+		})
+	}
+}
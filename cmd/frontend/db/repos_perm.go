@@ -7,8 +7,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
 	"github.com/sourcegraph/sourcegraph/pkg/actor"
 	"github.com/sourcegraph/sourcegraph/pkg/api"
-	"github.com/sourcegraph/sourcegraph/pkg/extsvc"
-	log15 "gopkg.in/inconshreveable/log15.v2"
+	"github.com/sourcegraph/sourcegraph/pkg/errcode"
 )
 
 var mockAuthzFilter func(ctx context.Context, repos []*types.Repo, p authz.Perm) ([]*types.Repo, error)
@@ -40,7 +39,7 @@ func authzFilter(ctx context.Context, repos []*types.Repo, p authz.Perm) ([]*typ
 		}
 	}
 
-	filteredRepoNames, err := getFilteredRepoNames(ctx, currentUser, authz.ToRepos(repos), p)
+	filteredRepoNames, err := getFilteredRepoNames(ctx, currentUser, repos, p)
 	if err != nil {
 		return nil, err
 	}
@@ -63,66 +62,47 @@ func isInternalActor(ctx context.Context) bool {
 	return actor.FromContext(ctx).Internal
 }
 
-func getFilteredRepoNames(ctx context.Context, currentUser *types.User, repos map[authz.Repo]struct{}, p authz.Perm) (accepted map[api.RepoName]struct{}, err error) {
-	var accts []*extsvc.ExternalAccount
+// getFilteredRepoNames returns the names of the repositories that the user (or an anonymous user,
+// if currentUser is nil) has permission p on.
+//
+// The permissions on repositories for which an authz provider is the source of permissions are
+// read from the permissions synced in the background by package permsync (see UserPermissions). The
+// authz providers themselves (which usually call out to code hosts) are never called here, so that
+// no request has to wait on them.
+func getFilteredRepoNames(ctx context.Context, currentUser *types.User, repos []*types.Repo, p authz.Perm) (accepted map[api.RepoName]struct{}, err error) {
 	authzAllowByDefault, authzProviders := authz.GetProviders()
-	if len(authzProviders) > 0 && currentUser != nil {
-		accts, err = ExternalAccounts.List(ctx, ExternalAccountsListOptions{UserID: currentUser.ID})
-		if err != nil {
-			return nil, err
-		}
-	}
 
-	accepted = make(map[api.RepoName]struct{})  // repositories that have been claimed and have read permissions
-	unverified := make(map[authz.Repo]struct{}) // repositories that have not been claimed by any authz provider
-	for repo := range repos {
-		unverified[repo] = struct{}{}
-	}
+	accepted = make(map[api.RepoName]struct{}) // repositories that have been claimed and have read permissions
+	unverified := authz.ToRepos(repos)         // repositories that have not been claimed by any authz provider
 
-	// Walk through all authz providers, checking repo permissions against each. If any own a given
-	// repo, we use its permissions for that repo.
+	// Walk through all authz providers to determine which repos "belong" to an authz provider.
+	claimed := make(map[authz.Repo]struct{})
 	for _, authzProvider := range authzProviders {
 		if len(unverified) == 0 {
 			break
 		}
-
-		// determine external account to use
-		var providerAcct *extsvc.ExternalAccount
-		for _, acct := range accts {
-			if acct.ServiceID == authzProvider.ServiceID() && acct.ServiceType == authzProvider.ServiceType() {
-				providerAcct = acct
-				break
-			}
-		}
-		if providerAcct == nil && currentUser != nil { // no existing external account for authz provider
-			if pr, err := authzProvider.FetchAccount(ctx, currentUser, accts); err == nil {
-				providerAcct = pr
-				if providerAcct != nil {
-					err := ExternalAccounts.AssociateUserAndSave(ctx, currentUser.ID, providerAcct.ExternalAccountSpec, providerAcct.ExternalAccountData)
-					if err != nil {
-						return nil, err
-					}
-				}
-			} else {
-				log15.Warn("Could not fetch authz provider account for user", "username", currentUser.Username, "authzProvider", authzProvider.ServiceID(), "error", err)
-			}
+		mine, others := authzProvider.Repos(ctx, unverified)
+		for repo := range mine {
+			claimed[repo] = struct{}{}
 		}
+		// continue checking repos that didn't belong to this authz provider
+		unverified = others
+	}
 
-		// determine which repos "belong" to this authz provider
-		myUnverified, nextUnverified := authzProvider.Repos(ctx, unverified)
-
-		// check the perms on those repos
-		perms, err := authzProvider.RepoPerms(ctx, providerAcct, myUnverified)
+	if len(claimed) > 0 {
+		permitted, err := syncedRepoPerms(ctx, currentUser, p)
 		if err != nil {
 			return nil, err
 		}
-		for unverifiedRepo := range myUnverified {
-			if repoPerms, ok := perms[unverifiedRepo.RepoName]; ok && repoPerms[p] {
-				accepted[unverifiedRepo.RepoName] = struct{}{}
+		repoIDs := make(map[api.RepoName]api.RepoID, len(repos))
+		for _, repo := range repos {
+			repoIDs[repo.Name] = repo.ID
+		}
+		for repo := range claimed {
+			if _, ok := permitted[repoIDs[repo.RepoName]]; ok {
+				accepted[repo.RepoName] = struct{}{}
 			}
 		}
-		// continue checking repos that didn't belong to this authz provider
-		unverified = nextUnverified
 	}
 
 	if authzAllowByDefault {
@@ -133,3 +113,32 @@ func getFilteredRepoNames(ctx context.Context, currentUser *types.User, repos ma
 
 	return accepted, nil
 }
+
+// syncedRepoPerms returns the IDs of the repositories that the user (or an anonymous user, if
+// currentUser is nil) has permission p on, as of the last background sync of their permissions.
+func syncedRepoPerms(ctx context.Context, currentUser *types.User, p authz.Perm) (map[api.RepoID]struct{}, error) {
+	if p != authz.Read {
+		return nil, nil // only read permissions are synced
+	}
+
+	var userID int32 // 0 for anonymous users
+	if currentUser != nil {
+		userID = currentUser.ID
+	}
+	perms, err := UserPermissions.Get(ctx, userID)
+	if errcode.IsNotFound(err) {
+		// 🚨 SECURITY: The user's permissions have not been synced yet (which the syncer does
+		// promptly for new users and upon sign-in). Until then, they may not access any repository
+		// that an authz provider is the source of permissions for.
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	permitted := make(map[api.RepoID]struct{}, len(perms.ReadRepoIDs))
+	for _, id := range perms.ReadRepoIDs {
+		permitted[id] = struct{}{}
+	}
+	return permitted, nil
+}
@@ -18,14 +18,17 @@ type authzFilter_Test struct {
 	authzAllowByDefault bool
 	authzProviders      []authz.Provider
 
+	// userPerms is the synced read permissions (repository IDs) of each user, by user ID (0 for
+	// anonymous users). The permissions of users missing from the map were never synced.
+	userPerms map[int32][]api.RepoID
+
 	calls []authzFilter_call
 }
 
 type authzFilter_call struct {
 	description string
 
-	user         *types.User
-	userAccounts []*extsvc.ExternalAccount
+	user *types.User
 
 	repos []*types.Repo
 	perm  authz.Perm
@@ -36,6 +39,7 @@ type authzFilter_call struct {
 func (r authzFilter_Test) run(t *testing.T) {
 	t.Logf("Test case %q", r.description)
 	authz.SetProviders(r.authzAllowByDefault, r.authzProviders)
+	defer func() { Mocks.UserPermissions = MockUserPermissions{} }()
 
 	for _, c := range r.calls {
 		t.Logf("Call %q", c.description)
@@ -50,15 +54,22 @@ func (r authzFilter_Test) run(t *testing.T) {
 			}
 			return c.user, nil
 		}
+		Mocks.UserPermissions.Get = func(ctx context.Context, userID int32) (*types.UserPermissions, error) {
+			if (c.user != nil && c.user.ID != userID) || (c.user == nil && userID != 0) {
+				t.Fatalf("unexpected user ID %d", userID)
+			}
+			repoIDs, ok := r.userPerms[userID]
+			if !ok {
+				return nil, userPermissionsNotFoundError{userID}
+			}
+			return &types.UserPermissions{UserID: userID, ReadRepoIDs: repoIDs}, nil
+		}
 
 		ctx := context.Background()
 		if c.user != nil {
 			ctx = actor.WithActor(ctx, &actor.Actor{UID: c.user.ID})
 		}
 
-		Mocks.ExternalAccounts.AssociateUserAndSave = func(userID int32, spec extsvc.ExternalAccountSpec, data extsvc.ExternalAccountData) error { return nil }
-		Mocks.ExternalAccounts.List = func(ExternalAccountsListOptions) ([]*extsvc.ExternalAccount, error) { return c.userAccounts, nil }
-
 		filteredRepos, err := authzFilter(ctx, c.repos, c.perm)
 		if err != nil {
 			t.Fatal(err)
@@ -78,444 +89,113 @@ func (r authzFilter_Test) run(t *testing.T) {
 }
 
 func Test_authzFilter(t *testing.T) {
+	var (
+		u1r0          = &types.Repo{ID: 1, Name: "gitlab.mine/u1/r0"}
+		u2r0          = &types.Repo{ID: 2, Name: "gitlab.mine/u2/r0"}
+		sharedPrivate = &types.Repo{ID: 3, Name: "gitlab.mine/sharedPrivate/r0"}
+		org           = &types.Repo{ID: 4, Name: "gitlab.mine/org/r0"}
+		other         = &types.Repo{ID: 5, Name: "otherHost/r0"}
+		allRepos      = []*types.Repo{u1r0, u2r0, sharedPrivate, org, other}
+	)
+	gitlabProvider := &MockAuthzProvider{
+		serviceID:   "https://gitlab.mine/",
+		serviceType: "gitlab",
+		repos: map[api.RepoName]struct{}{
+			"gitlab.mine/u1/r0":            {},
+			"gitlab.mine/u2/r0":            {},
+			"gitlab.mine/sharedPrivate/r0": {},
+			"gitlab.mine/org/r0":           {},
+		},
+	}
+	userPerms := map[int32][]api.RepoID{
+		0: {4},
+		1: {1, 3, 4},
+		2: {2, 3, 4},
+	}
+
 	tests := []authzFilter_Test{
 		{
-			description:         "1 authz provider, ext account exists",
+			description:         "1 authz provider",
 			authzAllowByDefault: true,
-			authzProviders: []authz.Provider{
-				&MockAuthzProvider{
-					serviceID:   "https://gitlab.mine/",
-					serviceType: "gitlab",
-					repos: map[api.RepoName]struct{}{
-						"gitlab.mine/u1/r0":            {},
-						"gitlab.mine/u2/r0":            {},
-						"gitlab.mine/sharedPrivate/r0": {},
-						"gitlab.mine/org/r0":           {},
-					},
-					perms: map[extsvc.ExternalAccount]map[api.RepoName]map[authz.Perm]bool{
-						*acct(1, "gitlab", "https://gitlab.mine/", "u1"): {
-							"gitlab.mine/u1/r0":            {authz.Read: true},
-							"gitlab.mine/sharedPrivate/r0": {authz.Read: true},
-							"gitlab.mine/org/r0":           {authz.Read: true},
-						},
-						*acct(2, "gitlab", "https://gitlab.mine/", "u2"): {
-							"gitlab.mine/u2/r0":            {authz.Read: true},
-							"gitlab.mine/sharedPrivate/r0": {authz.Read: true},
-							"gitlab.mine/org/r0":           {authz.Read: true},
-						},
-						{}: {
-							"gitlab.mine/org/r0": {authz.Read: true},
-						},
-					},
-				},
-			},
+			authzProviders:      []authz.Provider{gitlabProvider},
+			userPerms:           userPerms,
 			calls: []authzFilter_call{
 				{
-					description:  "u1 can read its own repo",
-					user:         &types.User{ID: 1},
-					userAccounts: []*extsvc.ExternalAccount{acct(1, "gitlab", "https://gitlab.mine/", "u1")},
-					repos: []*types.Repo{
-						{Name: "gitlab.mine/u1/r0"},
-					},
-					perm: authz.Read,
-					expFilteredRepos: []*types.Repo{
-						{Name: "gitlab.mine/u1/r0"},
-					},
+					description:      "u1 can read its own repo",
+					user:             &types.User{ID: 1},
+					repos:            []*types.Repo{u1r0},
+					perm:             authz.Read,
+					expFilteredRepos: []*types.Repo{u1r0},
 				},
 				{
-					description:  "u1 not allowed to read u2's repo",
-					user:         &types.User{ID: 1},
-					userAccounts: []*extsvc.ExternalAccount{acct(1, "gitlab", "https://gitlab.mine/", "u1")},
-					repos: []*types.Repo{
-						{Name: "gitlab.mine/u1/r0"},
-						{Name: "gitlab.mine/u2/r0"},
-						{Name: "gitlab.mine/sharedPrivate/r0"},
-						{Name: "gitlab.mine/org/r0"},
-					},
-					perm: authz.Read,
-					expFilteredRepos: []*types.Repo{
-						{Name: "gitlab.mine/u1/r0"},
-						{Name: "gitlab.mine/sharedPrivate/r0"},
-						{Name: "gitlab.mine/org/r0"},
-					},
+					description:      "u1 can read its own, shared, public, and unmanaged repos",
+					user:             &types.User{ID: 1},
+					repos:            allRepos,
+					perm:             authz.Read,
+					expFilteredRepos: []*types.Repo{u1r0, sharedPrivate, org, other},
 				},
 				{
-					description:  "u2 not allowed to read u0's repo",
-					user:         &types.User{ID: 1},
-					userAccounts: []*extsvc.ExternalAccount{acct(2, "gitlab", "https://gitlab.mine/", "u2")},
-					repos: []*types.Repo{
-						{Name: "gitlab.mine/u1/r0"},
-						{Name: "gitlab.mine/u2/r0"},
-						{Name: "gitlab.mine/sharedPrivate/r0"},
-						{Name: "gitlab.mine/org/r0"},
-					},
-					perm: authz.Read,
-					expFilteredRepos: []*types.Repo{
-						{Name: "gitlab.mine/u2/r0"},
-						{Name: "gitlab.mine/sharedPrivate/r0"},
-						{Name: "gitlab.mine/org/r0"},
-					},
-				}, {
-					description:  "u99 not allowed to read anyone's repo",
-					user:         &types.User{ID: 1},
-					userAccounts: []*extsvc.ExternalAccount{acct(99, "gitlab", "https://gitlab.mine/", "u99")},
-					repos: []*types.Repo{
-						{Name: "gitlab.mine/u1/r0"},
-						{Name: "gitlab.mine/u2/r0"},
-						{Name: "gitlab.mine/sharedPrivate/r0"},
-						{Name: "gitlab.mine/org/r0"},
-					},
-					expFilteredRepos: []*types.Repo{
-						{Name: "gitlab.mine/org/r0"},
-					},
-					perm: authz.Read,
-				}, {
-					description:  "u99 can read unmanaged repo",
-					user:         &types.User{ID: 1},
-					userAccounts: []*extsvc.ExternalAccount{acct(99, "gitlab", "https://gitlab.mine/", "u99")},
-					repos: []*types.Repo{
-						{Name: "other.mine/r"},
-					},
-					expFilteredRepos: []*types.Repo{
-						{Name: "other.mine/r"},
-					},
-					perm: authz.Read,
-				}, {
-					description:  "u1 can read its own, public, and unmanaged repos",
-					user:         &types.User{ID: 1},
-					userAccounts: []*extsvc.ExternalAccount{acct(1, "gitlab", "https://gitlab.mine/", "u1")},
-					repos: []*types.Repo{
-						{Name: "gitlab.mine/u1/r0"},
-						{Name: "gitlab.mine/u2/r0"},
-						{Name: "gitlab.mine/sharedPrivate/r0"},
-						{Name: "gitlab.mine/org/r0"},
-						{Name: "otherHost/r0"},
-					},
-					expFilteredRepos: []*types.Repo{
-						{Name: "gitlab.mine/u1/r0"},
-						{Name: "gitlab.mine/sharedPrivate/r0"},
-						{Name: "gitlab.mine/org/r0"},
-						{Name: "otherHost/r0"},
-					},
-					perm: authz.Read,
-				}, {
-					description:  "authenticated but 0 accounts can read public anad unmanaged repos",
-					user:         &types.User{ID: 1},
-					userAccounts: nil,
-					repos: []*types.Repo{
-						{Name: "gitlab.mine/u1/r0"},
-						{Name: "gitlab.mine/u2/r0"},
-						{Name: "gitlab.mine/sharedPrivate/r0"},
-						{Name: "gitlab.mine/org/r0"},
-						{Name: "otherHost/r0"},
-					},
-					expFilteredRepos: []*types.Repo{
-						{Name: "gitlab.mine/org/r0"},
-						{Name: "otherHost/r0"},
-					},
-					perm: authz.Read,
-				}, {
-					description:  "unauthenticated can read public and unmanaged repos",
-					user:         nil,
-					userAccounts: nil,
-					repos: []*types.Repo{
-						{Name: "gitlab.mine/u1/r0"},
-						{Name: "gitlab.mine/u2/r0"},
-						{Name: "gitlab.mine/sharedPrivate/r0"},
-						{Name: "gitlab.mine/org/r0"},
-						{Name: "otherHost/r0"},
-					},
-					expFilteredRepos: []*types.Repo{
-						{Name: "gitlab.mine/org/r0"},
-						{Name: "otherHost/r0"},
-					},
-					perm: authz.Read,
-				}, {
-					description:  "admin user can read all repos",
-					user:         &types.User{ID: 777, SiteAdmin: true},
-					userAccounts: nil,
-					repos: []*types.Repo{
-						{Name: "gitlab.mine/u1/r0"},
-						{Name: "gitlab.mine/u2/r0"},
-						{Name: "gitlab.mine/sharedPrivate/r0"},
-						{Name: "gitlab.mine/org/r0"},
-						{Name: "otherHost/r0"},
-					},
-					expFilteredRepos: []*types.Repo{
-						{Name: "gitlab.mine/u1/r0"},
-						{Name: "gitlab.mine/u2/r0"},
-						{Name: "gitlab.mine/sharedPrivate/r0"},
-						{Name: "gitlab.mine/org/r0"},
-						{Name: "otherHost/r0"},
-					},
-					perm: authz.Read,
+					description:      "u2 not allowed to read u1's repo",
+					user:             &types.User{ID: 2},
+					repos:            allRepos,
+					perm:             authz.Read,
+					expFilteredRepos: []*types.Repo{u2r0, sharedPrivate, org, other},
 				},
-			},
-		},
-		{
-			description:         "2 authz providers, ext accounts exist",
-			authzAllowByDefault: true,
-			authzProviders: []authz.Provider{
-				&MockAuthzProvider{
-					serviceID:   "https://gitlab0.mine/",
-					serviceType: "gitlab",
-					repos: map[api.RepoName]struct{}{
-						"gitlab0.mine/u1/r0":  {},
-						"gitlab0.mine/u2/r0":  {},
-						"gitlab0.mine/org/r0": {},
-					},
-					perms: map[extsvc.ExternalAccount]map[api.RepoName]map[authz.Perm]bool{
-						*acct(1, "gitlab", "https://gitlab0.mine/", "u1"): {
-							"gitlab0.mine/u1/r0":  {authz.Read: true},
-							"gitlab0.mine/org/r0": {authz.Read: true},
-						},
-						*acct(2, "gitlab", "https://gitlab0.mine/", "u2"): {
-							"gitlab0.mine/u1/r0":  {},
-							"gitlab0.mine/u2/r0":  {authz.Read: true},
-							"gitlab0.mine/org/r0": {authz.Read: true},
-						},
-					},
+				{
+					description:      "u3 whose permissions were never synced can read unmanaged repos only",
+					user:             &types.User{ID: 3},
+					repos:            allRepos,
+					perm:             authz.Read,
+					expFilteredRepos: []*types.Repo{other},
 				},
-				&MockAuthzProvider{
-					serviceID:   "https://gitlab1.mine/",
-					serviceType: "gitlab",
-					repos: map[api.RepoName]struct{}{
-						"gitlab1.mine/u1/r0":  {},
-						"gitlab1.mine/u2/r0":  {},
-						"gitlab1.mine/org/r0": {},
-					},
-					perms: map[extsvc.ExternalAccount]map[api.RepoName]map[authz.Perm]bool{
-						*acct(1, "gitlab", "https://gitlab1.mine/", "u1"): {
-							"gitlab1.mine/u1/r0":  {authz.Read: true},
-							"gitlab1.mine/org/r0": {authz.Read: true},
-						},
-						*acct(2, "gitlab", "https://gitlab1.mine/", "u2"): {
-							"gitlab1.mine/u2/r0":  {authz.Read: true},
-							"gitlab1.mine/org/r0": {authz.Read: true},
-						},
-					},
+				{
+					description:      "unauthenticated can read public and unmanaged repos",
+					user:             nil,
+					repos:            allRepos,
+					perm:             authz.Read,
+					expFilteredRepos: []*types.Repo{org, other},
 				},
-			},
-			calls: []authzFilter_call{
 				{
-					description: "u1 can read its own repos, but not others'",
-					user:        &types.User{ID: 1},
-					userAccounts: []*extsvc.ExternalAccount{
-						acct(1, "gitlab", "https://gitlab0.mine/", "u1"),
-						acct(1, "gitlab", "https://gitlab1.mine/", "u1"),
-					},
-					repos: []*types.Repo{
-						{Name: "gitlab0.mine/u1/r0"},
-						{Name: "gitlab0.mine/u2/r0"},
-						{Name: "gitlab0.mine/org/r0"},
-						{Name: "gitlab1.mine/u1/r0"},
-						{Name: "gitlab1.mine/u2/r0"},
-						{Name: "gitlab1.mine/org/r0"},
-						{Name: "gitlab2.mine/u2/r0"},
-						{Name: "otherHost/r0"},
-					},
-					expFilteredRepos: []*types.Repo{
-						{Name: "gitlab0.mine/u1/r0"},
-						{Name: "gitlab0.mine/org/r0"},
-						{Name: "gitlab1.mine/u1/r0"},
-						{Name: "gitlab1.mine/org/r0"},
-						{Name: "gitlab2.mine/u2/r0"},
-						{Name: "otherHost/r0"},
-					},
-					perm: authz.Read,
+					description:      "other permissions are not granted on managed repos",
+					user:             &types.User{ID: 1},
+					repos:            allRepos,
+					perm:             authz.Perm("write"),
+					expFilteredRepos: []*types.Repo{other},
 				},
 				{
-					description: "u1 with external account on one instance, can't read repos from the other'",
-					user:        &types.User{ID: 1},
-					userAccounts: []*extsvc.ExternalAccount{
-						acct(1, "gitlab", "https://gitlab1.mine/", "u1"),
-					},
-					repos: []*types.Repo{
-						{Name: "gitlab0.mine/u1/r0"},
-						{Name: "gitlab0.mine/u2/r0"},
-						{Name: "gitlab0.mine/org/r0"},
-						{Name: "gitlab1.mine/u1/r0"},
-						{Name: "gitlab1.mine/u2/r0"},
-						{Name: "gitlab1.mine/org/r0"},
-						{Name: "gitlab2.mine/u2/r0"},
-						{Name: "otherHost/r0"},
-					},
-					expFilteredRepos: []*types.Repo{
-						{Name: "gitlab1.mine/u1/r0"},
-						{Name: "gitlab1.mine/org/r0"},
-						{Name: "gitlab2.mine/u2/r0"},
-						{Name: "otherHost/r0"},
-					},
-					perm: authz.Read,
+					description:      "admin user can read all repos",
+					user:             &types.User{ID: 777, SiteAdmin: true},
+					repos:            allRepos,
+					perm:             authz.Read,
+					expFilteredRepos: allRepos,
 				},
 			},
 		},
 		{
-			description:         "2 authz providers, ext account exists, authzAllowByDefault=false",
+			description:         "1 authz provider, authzAllowByDefault=false",
 			authzAllowByDefault: false,
-			authzProviders: []authz.Provider{
-				&MockAuthzProvider{
-					serviceID:   "https://gitlab0.mine/",
-					serviceType: "gitlab",
-					repos: map[api.RepoName]struct{}{
-						"gitlab0.mine/u1/r0":  {},
-						"gitlab0.mine/u2/r0":  {},
-						"gitlab0.mine/org/r0": {},
-					},
-					perms: map[extsvc.ExternalAccount]map[api.RepoName]map[authz.Perm]bool{
-						*acct(1, "gitlab", "https://gitlab0.mine/", "u1"): {
-							"gitlab0.mine/u1/r0":  {authz.Read: true},
-							"gitlab0.mine/org/r0": {authz.Read: true},
-						},
-						*acct(2, "gitlab", "https://gitlab0.mine/", "u2"): {
-							"gitlab0.mine/u1/r0":  {},
-							"gitlab0.mine/u2/r0":  {authz.Read: true},
-							"gitlab0.mine/org/r0": {authz.Read: true},
-						},
-					},
-				},
-				&MockAuthzProvider{
-					serviceID:   "https://gitlab1.mine/",
-					serviceType: "gitlab",
-					repos: map[api.RepoName]struct{}{
-						"gitlab1.mine/u1/r0":  {},
-						"gitlab1.mine/u2/r0":  {},
-						"gitlab1.mine/org/r0": {},
-					},
-					perms: map[extsvc.ExternalAccount]map[api.RepoName]map[authz.Perm]bool{
-						*acct(1, "gitlab", "https://gitlab1.mine/", "u1"): {
-							"gitlab1.mine/u1/r0":  {authz.Read: true},
-							"gitlab1.mine/org/r0": {authz.Read: true},
-						},
-						*acct(2, "gitlab", "https://gitlab1.mine/", "u2"): {
-							"gitlab1.mine/u2/r0":  {authz.Read: true},
-							"gitlab1.mine/org/r0": {authz.Read: true},
-						},
-					},
-				},
-			},
+			authzProviders:      []authz.Provider{gitlabProvider},
+			userPerms:           userPerms,
 			calls: []authzFilter_call{
 				{
-					description: "u1 can read its own repos, but not others'",
-					user:        &types.User{ID: 1},
-					userAccounts: []*extsvc.ExternalAccount{
-						acct(1, "gitlab", "https://gitlab0.mine/", "u1"),
-						acct(1, "gitlab", "https://gitlab1.mine/", "u1"),
-					},
-					repos: []*types.Repo{
-						{Name: "gitlab0.mine/u1/r0"},
-						{Name: "gitlab0.mine/u2/r0"},
-						{Name: "gitlab0.mine/org/r0"},
-						{Name: "gitlab1.mine/u1/r0"},
-						{Name: "gitlab1.mine/u2/r0"},
-						{Name: "gitlab1.mine/org/r0"},
-						{Name: "gitlab2.mine/u2/r0"},
-						{Name: "otherHost/r0"},
-					},
-					expFilteredRepos: []*types.Repo{
-						{Name: "gitlab0.mine/u1/r0"},
-						{Name: "gitlab0.mine/org/r0"},
-						{Name: "gitlab1.mine/u1/r0"},
-						{Name: "gitlab1.mine/org/r0"},
-					},
-					perm: authz.Read,
+					description:      "u1 can read its own repos, but not unmanaged repos",
+					user:             &types.User{ID: 1},
+					repos:            allRepos,
+					perm:             authz.Read,
+					expFilteredRepos: []*types.Repo{u1r0, sharedPrivate, org},
 				},
 			},
 		},
 		{
-			description:         "1 authz provider, ext account doesn't exist",
+			description:         "no authz providers",
 			authzAllowByDefault: true,
-			authzProviders: []authz.Provider{
-				&MockAuthzProvider{
-					serviceID:    "https://gitlab.mine/",
-					serviceType:  "gitlab",
-					okServiceIDs: map[string]struct{}{"https://okta.mine/": {}},
-					repos: map[api.RepoName]struct{}{
-						"gitlab.mine/u1/r0":     {},
-						"gitlab.mine/u2/r0":     {},
-						"gitlab.mine/org/r0":    {},
-						"gitlab.mine/public/r0": {},
-					},
-					perms: map[extsvc.ExternalAccount]map[api.RepoName]map[authz.Perm]bool{
-						*acct(1, "gitlab", "https://gitlab.mine/", "u1"): {
-							"gitlab.mine/u1/r0":     {authz.Read: true},
-							"gitlab.mine/org/r0":    {authz.Read: true},
-							"gitlab.mine/public/r0": {authz.Read: true},
-						},
-						*acct(2, "gitlab", "https://gitlab.mine/", "u2"): {
-							"gitlab.mine/u2/r0":     {authz.Read: true},
-							"gitlab.mine/org/r0":    {authz.Read: true},
-							"gitlab.mine/public/r0": {authz.Read: true},
-						},
-						// entry for nil account / anonymous users
-						{}: {
-							"gitlab.mine/public/r0": {authz.Read: true},
-						},
-					},
-				},
-			},
 			calls: []authzFilter_call{
 				{
-					description:  "u1 has access to the right repos",
-					user:         &types.User{ID: 1},
-					userAccounts: []*extsvc.ExternalAccount{acct(1, "saml", "https://okta.mine/", "u1")},
-					repos: []*types.Repo{
-						{Name: "gitlab.mine/u1/r0"},
-						{Name: "gitlab.mine/u2/r0"},
-						{Name: "gitlab.mine/org/r0"},
-						{Name: "gitlab.mine/public/r0"},
-					},
-					perm: authz.Read,
-					expFilteredRepos: []*types.Repo{
-						{Name: "gitlab.mine/u1/r0"},
-						{Name: "gitlab.mine/org/r0"},
-						{Name: "gitlab.mine/public/r0"},
-					},
-				},
-				{
-					description:  "u99 has access to publice repos only",
-					user:         &types.User{ID: 1},
-					userAccounts: []*extsvc.ExternalAccount{acct(1, "saml", "https://okta.mine/", "u99")},
-					repos: []*types.Repo{
-						{Name: "gitlab.mine/u1/r0"},
-						{Name: "gitlab.mine/u2/r0"},
-						{Name: "gitlab.mine/org/r0"},
-						{Name: "gitlab.mine/public/r0"},
-					},
-					expFilteredRepos: []*types.Repo{
-						{Name: "gitlab.mine/public/r0"},
-					},
-					perm: authz.Read,
-				},
-				{
-					description:  "service ID does not match",
-					user:         &types.User{ID: 1},
-					userAccounts: []*extsvc.ExternalAccount{acct(1, "saml", "https://rando.mine/", "u1")},
-					repos: []*types.Repo{
-						{Name: "gitlab.mine/u1/r0"},
-						{Name: "gitlab.mine/u2/r0"},
-						{Name: "gitlab.mine/org/r0"},
-						{Name: "gitlab.mine/public/r0"},
-					},
-					expFilteredRepos: []*types.Repo{
-						{Name: "gitlab.mine/public/r0"},
-					},
-					perm: authz.Read,
-				},
-				{
-					description:  "unauthenticated user",
-					user:         nil,
-					userAccounts: nil,
-					repos: []*types.Repo{
-						{Name: "gitlab.mine/u1/r0"},
-						{Name: "gitlab.mine/u2/r0"},
-						{Name: "gitlab.mine/org/r0"},
-						{Name: "gitlab.mine/public/r0"},
-					},
-					expFilteredRepos: []*types.Repo{
-						{Name: "gitlab.mine/public/r0"},
-					},
-					perm: authz.Read,
+					description:      "u1 can read all repos",
+					user:             &types.User{ID: 1},
+					repos:            allRepos,
+					perm:             authz.Read,
+					expFilteredRepos: allRepos,
 				},
 			},
 		},
@@ -525,198 +205,20 @@ func Test_authzFilter(t *testing.T) {
 	}
 }
 
-func Test_authzFilter_createsNewUsers(t *testing.T) {
-	associateUserAndSaveCount := make(map[int32]map[extsvc.ExternalAccountSpec]int)
-	Mocks.ExternalAccounts.AssociateUserAndSave = func(userID int32, spec extsvc.ExternalAccountSpec, data extsvc.ExternalAccountData) error {
-		if _, ok := associateUserAndSaveCount[userID]; !ok {
-			associateUserAndSaveCount[userID] = make(map[extsvc.ExternalAccountSpec]int)
-		}
-		associateUserAndSaveCount[userID][spec]++
-		return nil
-	}
-	mockUser23Accounts := []*extsvc.ExternalAccount{{
-		UserID: 23,
-		ExternalAccountSpec: extsvc.ExternalAccountSpec{
-			ServiceType: "okta",
-			ServiceID:   "https://okta.mine/",
-			AccountID:   "101",
-		},
-	}, {
-		UserID: 23,
-		ExternalAccountSpec: extsvc.ExternalAccountSpec{
-			ServiceType: "other",
-			ServiceID:   "https://other.mine/",
-			AccountID:   "99",
-		},
-	}}
-	Mocks.ExternalAccounts.List = func(op ExternalAccountsListOptions) ([]*extsvc.ExternalAccount, error) {
-		if op.UserID == 23 {
-			return mockUser23Accounts, nil
-		}
-		return nil, nil
-	}
-	Mocks.Users.GetByCurrentAuthUser = func(ctx context.Context) (*types.User, error) {
-		if actr := actor.FromContext(ctx); actr != nil {
-			return &types.User{ID: actr.UID}, nil
-		}
-		return nil, nil
-	}
-	authz.SetProviders(true, []authz.Provider{
-		&MockAuthzProvider{
-			serviceID:    "https://gitlab.mine/",
-			serviceType:  "gitlab",
-			okServiceIDs: map[string]struct{}{"https://okta.mine/": {}},
-			repos:        map[api.RepoName]struct{}{},
-			perms: map[extsvc.ExternalAccount]map[api.RepoName]map[authz.Perm]bool{
-				*acct(23, "gitlab", "https://gitlab.mine/", "101"): {},
-			},
-		},
-	})
-
-	var (
-		expNewAcct           = extsvc.ExternalAccountSpec{ServiceID: "https://gitlab.mine/", ServiceType: "gitlab", AccountID: "101"}
-		unAuthdCtx           = context.Background()
-		authd23Ctx           = actor.WithActor(unAuthdCtx, &actor.Actor{UID: 23})
-		authd99Ctx           = actor.WithActor(unAuthdCtx, &actor.Actor{UID: 99})
-		account23CreatedOnce = map[int32]map[extsvc.ExternalAccountSpec]int{
-			23: {
-				expNewAcct: 1,
-			},
-		}
-		account23CreatedTwice = map[int32]map[extsvc.ExternalAccountSpec]int{
-			23: {
-				expNewAcct: 2,
-			},
-		}
-	)
-	// Initial counts 0
-	if exp := map[int32]map[extsvc.ExternalAccountSpec]int{}; !reflect.DeepEqual(associateUserAndSaveCount, exp) {
-		t.Errorf("expected counts to be %s, but was %s", asJSON(t, exp), asJSON(t, associateUserAndSaveCount))
-	}
-
-	// Unauthed filter does not trigger new account creation
-	if _, err := authzFilter(unAuthdCtx, []*types.Repo{{ID: 77}}, authz.Read); err != nil {
-		t.Fatal(err)
-	}
-	if exp := map[int32]map[extsvc.ExternalAccountSpec]int{}; !reflect.DeepEqual(associateUserAndSaveCount, exp) {
-		t.Errorf("expected counts to be %s, but was %s", asJSON(t, exp), asJSON(t, associateUserAndSaveCount))
-	}
-
-	// Authed filter triggers new account creation
-	if _, err := authzFilter(authd23Ctx, []*types.Repo{{ID: 77}}, authz.Read); err != nil {
-		t.Fatal(err)
-	}
-	if !reflect.DeepEqual(associateUserAndSaveCount, account23CreatedOnce) {
-		t.Errorf("expected counts to be %+v, but was %+v", account23CreatedOnce, associateUserAndSaveCount)
-	}
-
-	// Unauthed filter does not trigger new account creation
-	if _, err := authzFilter(unAuthdCtx, []*types.Repo{{ID: 77}}, authz.Read); err != nil {
-		t.Fatal(err)
-	}
-	if !reflect.DeepEqual(associateUserAndSaveCount, account23CreatedOnce) {
-		t.Errorf("expected counts to be %+v, but was %+v", account23CreatedOnce, associateUserAndSaveCount)
-	}
-
-	// Authed filter triggers new account creation
-	if _, err := authzFilter(authd23Ctx, []*types.Repo{{ID: 77}}, authz.Read); err != nil {
-		t.Fatal(err)
-	}
-	if !reflect.DeepEqual(associateUserAndSaveCount, account23CreatedTwice) {
-		t.Errorf("expected counts to be %+v, but was %+v", account23CreatedTwice, associateUserAndSaveCount)
-	}
-
-	// Authed filter under another user for whom FetchAccount returns empty doesn't trigger new account creation
-	if _, err := authzFilter(authd99Ctx, []*types.Repo{{ID: 77}}, authz.Read); err != nil {
-		t.Fatal(err)
-	}
-	if !reflect.DeepEqual(associateUserAndSaveCount, account23CreatedTwice) {
-		t.Errorf("expected counts to be %+v, but was %+v", account23CreatedTwice, associateUserAndSaveCount)
-	}
-
-	// Authed filter does NOT trigger new account creation if new account is already provided
-	mockUser23Accounts = append(mockUser23Accounts, &extsvc.ExternalAccount{
-		UserID: 23,
-		ExternalAccountSpec: extsvc.ExternalAccountSpec{
-			ServiceType: "gitlab",
-			ServiceID:   "https://gitlab.mine/",
-			AccountID:   "101",
-		},
-	})
-	if _, err := authzFilter(authd23Ctx, []*types.Repo{{ID: 77, ExternalRepo: &api.ExternalRepoSpec{}}}, authz.Read); err != nil {
-		t.Fatal(err)
-	}
-	if !reflect.DeepEqual(associateUserAndSaveCount, account23CreatedTwice) {
-		t.Errorf("expected counts to be %+v, but was %+v", account23CreatedTwice, associateUserAndSaveCount)
-	}
-}
-
-func acct(userID int32, serviceType, serviceID, accountID string) *extsvc.ExternalAccount {
-	return &extsvc.ExternalAccount{
-		UserID: userID,
-		ExternalAccountSpec: extsvc.ExternalAccountSpec{
-			ServiceType: serviceType,
-			ServiceID:   serviceID,
-			AccountID:   accountID,
-		},
-	}
-}
-
+// MockAuthzProvider is an authz provider that claims a fixed set of repositories. Its permissions
+// must never be computed at request time (only in the background, by package permsync).
 type MockAuthzProvider struct {
 	serviceID   string
 	serviceType string
-
-	// okServiceIDs indicate services whose external accounts will be straightforwardly translated
-	// into external accounts belonging to this provider.
-	okServiceIDs map[string]struct{}
-
-	// perms is the map from external user account to repository permissions. The key set must
-	// include all user external accounts that are available in this mock instance.
-	perms map[extsvc.ExternalAccount]map[api.RepoName]map[authz.Perm]bool
-	repos map[api.RepoName]struct{}
+	repos       map[api.RepoName]struct{}
 }
 
 func (m *MockAuthzProvider) FetchAccount(ctx context.Context, user *types.User, current []*extsvc.ExternalAccount) (mine *extsvc.ExternalAccount, err error) {
-	if user == nil {
-		return nil, nil
-	}
-	for _, acct := range current {
-		if (extsvc.ExternalAccount{}) == *acct {
-			continue
-		}
-		if _, ok := m.okServiceIDs[acct.ServiceID]; ok {
-			myAcct := *acct
-			myAcct.ServiceType = m.serviceType
-			myAcct.ServiceID = m.serviceID
-			if _, acctExistsInPerms := m.perms[myAcct]; acctExistsInPerms {
-				return &myAcct, nil
-			}
-		}
-	}
-	return nil, nil
+	panic("FetchAccount must not be called at request time")
 }
 
 func (m *MockAuthzProvider) RepoPerms(ctx context.Context, acct *extsvc.ExternalAccount, repos map[authz.Repo]struct{}) (map[api.RepoName]map[authz.Perm]bool, error) {
-	retPerms := make(map[api.RepoName]map[authz.Perm]bool)
-	repos, _ = m.Repos(ctx, repos)
-
-	if acct == nil {
-		acct = &extsvc.ExternalAccount{}
-	}
-	if _, existsInPerms := m.perms[*acct]; !existsInPerms {
-		acct = &extsvc.ExternalAccount{}
-	}
-
-	var userPerms map[api.RepoName]map[authz.Perm]bool = m.perms[*acct]
-	for repo := range repos {
-		if userRepoPerms, ok := userPerms[repo.RepoName]; ok {
-			retPerms[repo.RepoName] = make(map[authz.Perm]bool)
-			for k, v := range userRepoPerms {
-				retPerms[repo.RepoName][k] = v
-			}
-		}
-	}
-	return retPerms, nil
+	panic("RepoPerms must not be called at request time")
 }
 
 func (m *MockAuthzProvider) Repos(ctx context.Context, repos map[authz.Repo]struct{}) (mine map[authz.Repo]struct{}, others map[authz.Repo]struct{}) {
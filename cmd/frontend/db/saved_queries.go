@@ -3,9 +3,11 @@ package db
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
 	"github.com/sourcegraph/sourcegraph/pkg/db/dbconn"
 )
 
@@ -77,11 +79,92 @@ func (s *savedQueries) Set(ctx context.Context, info *SavedQueryInfo) error {
 	return nil
 }
 
+// Delete deletes the saved query information, snapshot and run history for
+// the given query.
 func (s *savedQueries) Delete(ctx context.Context, query string) error {
 	_, err := dbconn.Global.ExecContext(
 		ctx,
 		"DELETE FROM saved_queries WHERE query=$1",
 		query,
 	)
+	if err != nil {
+		return err
+	}
+	_, err = dbconn.Global.ExecContext(
+		ctx,
+		"DELETE FROM saved_query_snapshots WHERE query=$1",
+		query,
+	)
+	if err != nil {
+		return err
+	}
+	_, err = dbconn.Global.ExecContext(
+		ctx,
+		"DELETE FROM saved_query_runs WHERE query=$1",
+		query,
+	)
 	return err
 }
+
+// GetSnapshot gets the snapshot of matches for the given file content query.
+// nil is returned if there is no existing snapshot.
+func (s *savedQueries) GetSnapshot(ctx context.Context, query string) (*api.SavedQuerySnapshot, error) {
+	var matches []byte
+	err := dbconn.Global.QueryRowContext(
+		ctx,
+		"SELECT matches FROM saved_query_snapshots WHERE query=$1",
+		query,
+	).Scan(&matches)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "QueryRow")
+	}
+	snapshot := &api.SavedQuerySnapshot{Query: query}
+	if err := json.Unmarshal(matches, &snapshot.Matches); err != nil {
+		return nil, errors.Wrap(err, "Unmarshal")
+	}
+	return snapshot, nil
+}
+
+// SetSnapshot sets the snapshot of matches for the given snapshot.Query.
+//
+// It is not safe to call concurrently for the same snapshot.Query, as it uses
+// a poor man's upsert implementation.
+func (s *savedQueries) SetSnapshot(ctx context.Context, snapshot *api.SavedQuerySnapshot) error {
+	matches := snapshot.Matches
+	if matches == nil {
+		matches = []*api.SavedQueryMatch{}
+	}
+	b, err := json.Marshal(matches)
+	if err != nil {
+		return errors.Wrap(err, "Marshal")
+	}
+	res, err := dbconn.Global.ExecContext(
+		ctx,
+		"UPDATE saved_query_snapshots SET matches=$1, updated_at=now() WHERE query=$2",
+		string(b),
+		snapshot.Query,
+	)
+	if err != nil {
+		return errors.Wrap(err, "UPDATE")
+	}
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "RowsAffected")
+	}
+	if updated == 0 {
+		// Didn't update any row, so insert a new one.
+		_, err := dbconn.Global.ExecContext(
+			ctx,
+			"INSERT INTO saved_query_snapshots(query, matches) VALUES($1, $2)",
+			snapshot.Query,
+			string(b),
+		)
+		if err != nil {
+			return errors.Wrap(err, "INSERT")
+		}
+	}
+	return nil
+}
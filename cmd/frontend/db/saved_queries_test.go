@@ -0,0 +1,48 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/db/dbtesting"
+)
+
+func TestSavedQueries_Snapshot(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	ctx := dbtesting.TestContext(t)
+
+	const query = "TODO repo:^a$"
+	if snapshot, err := SavedQueries.GetSnapshot(ctx, query); err != nil {
+		t.Fatal(err)
+	} else if snapshot != nil {
+		t.Fatalf("got snapshot %+v, want nil", snapshot)
+	}
+
+	for _, matches := range [][]*api.SavedQueryMatch{
+		{},
+		{{Repo: "a", Path: "b.go", Line: 3, Preview: "// TODO: c"}},
+	} {
+		if err := SavedQueries.SetSnapshot(ctx, &api.SavedQuerySnapshot{Query: query, Matches: matches}); err != nil {
+			t.Fatal(err)
+		}
+		snapshot, err := SavedQueries.GetSnapshot(ctx, query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := (&api.SavedQuerySnapshot{Query: query, Matches: matches}); !reflect.DeepEqual(snapshot, want) {
+			t.Errorf("got %+v, want %+v", snapshot, want)
+		}
+	}
+
+	if err := SavedQueries.Delete(ctx, query); err != nil {
+		t.Fatal(err)
+	}
+	if snapshot, err := SavedQueries.GetSnapshot(ctx, query); err != nil {
+		t.Fatal(err)
+	} else if snapshot != nil {
+		t.Errorf("got snapshot %+v after delete, want nil", snapshot)
+	}
+}
@@ -0,0 +1,73 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/keegancsmith/sqlf"
+	"github.com/lib/pq"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/db/dbconn"
+)
+
+// savedQueryDigestItems is the queue of saved query notifications that are
+// held back to be sent in hourly or daily digests.
+type savedQueryDigestItems struct{}
+
+// savedQueryDigestItemsRetention is how long notifications are kept in the
+// queue. Older notifications (which could not be sent, e.g. because the
+// recipient has no email address) are dropped.
+const savedQueryDigestItemsRetention = 7 * 24 * time.Hour
+
+// Add queues a notification to be sent in a digest.
+func (*savedQueryDigestItems) Add(ctx context.Context, item *api.SavedQueryDigestItem) error {
+	recipientUserID := sql.NullInt64{Int64: int64(item.RecipientUserID), Valid: item.RecipientUserID != 0}
+	recipientOrgID := sql.NullInt64{Int64: int64(item.RecipientOrgID), Valid: item.RecipientOrgID != 0}
+	q := sqlf.Sprintf("INSERT INTO saved_query_digest_items(subject, saved_query_key, recipient_user_id, recipient_org_id, channel, frequency, description, query, added_count, removed_count) VALUES(%s, %s, %s, %s, %s, %s, %s, %s, %s, %s)",
+		item.Spec.Subject.String(), item.Spec.Key, recipientUserID, recipientOrgID, item.Channel, item.Frequency, item.Description, item.Query, item.AddedCount, item.RemovedCount)
+	if _, err := dbconn.Global.ExecContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...); err != nil {
+		return err
+	}
+
+	q = sqlf.Sprintf("DELETE FROM saved_query_digest_items WHERE created_at < %s", time.Now().Add(-savedQueryDigestItemsRetention))
+	_, err := dbconn.Global.ExecContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	return err
+}
+
+// List returns all queued notifications, oldest first.
+func (*savedQueryDigestItems) List(ctx context.Context) ([]*api.SavedQueryDigestItem, error) {
+	q := sqlf.Sprintf("SELECT id, subject, saved_query_key, recipient_user_id, recipient_org_id, channel, frequency, description, query, added_count, removed_count, created_at FROM saved_query_digest_items ORDER BY id")
+	rows, err := dbconn.Global.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []*api.SavedQueryDigestItem
+	for rows.Next() {
+		var (
+			item                            api.SavedQueryDigestItem
+			subject                         string
+			recipientUserID, recipientOrgID sql.NullInt64
+		)
+		if err := rows.Scan(&item.ID, &subject, &item.Spec.Key, &recipientUserID, &recipientOrgID, &item.Channel, &item.Frequency, &item.Description, &item.Query, &item.AddedCount, &item.RemovedCount, &item.CreatedAt); err != nil {
+			return nil, err
+		}
+		item.Spec.Subject, err = api.ParseSettingsSubject(subject)
+		if err != nil {
+			return nil, err
+		}
+		item.RecipientUserID = int32(recipientUserID.Int64)
+		item.RecipientOrgID = int32(recipientOrgID.Int64)
+		items = append(items, &item)
+	}
+	return items, rows.Err()
+}
+
+// Delete removes the notifications with the given IDs from the queue.
+func (*savedQueryDigestItems) Delete(ctx context.Context, ids []int64) error {
+	q := sqlf.Sprintf("DELETE FROM saved_query_digest_items WHERE id = ANY(%v)", pq.Array(ids))
+	_, err := dbconn.Global.ExecContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	return err
+}
@@ -0,0 +1,57 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/db/dbtesting"
+)
+
+func TestSavedQueryDigestItems(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	ctx := dbtesting.TestContext(t)
+
+	user, err := Users.Create(ctx, NewUser{Username: "u"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	org, err := Orgs.Create(ctx, "o", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec := api.SavedQueryIDSpec{Subject: api.SettingsSubject{Org: &org.ID}, Key: "k"}
+
+	for _, item := range []*api.SavedQueryDigestItem{
+		{Spec: spec, RecipientUserID: user.ID, Channel: "email", Frequency: api.SavedQueryNotificationFrequencyDaily, Description: "d", Query: "q1", AddedCount: 2},
+		{Spec: spec, RecipientOrgID: org.ID, Channel: "slack", Frequency: api.SavedQueryNotificationFrequencyHourly, Description: "d", Query: "q2", AddedCount: 1, RemovedCount: 3},
+	} {
+		if err := SavedQueryDigestItems.Add(ctx, item); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	items, err := SavedQueryDigestItems.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("got %d items, want 2", len(items))
+	}
+	if i := items[0]; i.Spec.Subject.Org == nil || *i.Spec.Subject.Org != org.ID || i.Spec.Key != "k" || i.RecipientUserID != user.ID || i.RecipientOrgID != 0 || i.Query != "q1" || i.AddedCount != 2 {
+		t.Errorf("got first item %+v, want email digest item for user", i)
+	}
+	if i := items[1]; i.RecipientUserID != 0 || i.RecipientOrgID != org.ID || i.Channel != "slack" || i.RemovedCount != 3 {
+		t.Errorf("got second item %+v, want Slack digest item for org", i)
+	}
+
+	if err := SavedQueryDigestItems.Delete(ctx, []int64{items[0].ID}); err != nil {
+		t.Fatal(err)
+	}
+	if items, err := SavedQueryDigestItems.List(ctx); err != nil {
+		t.Fatal(err)
+	} else if len(items) != 1 || items[0].Query != "q2" {
+		t.Errorf("got items %+v after delete, want only the second item", items)
+	}
+}
@@ -0,0 +1,88 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/keegancsmith/sqlf"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/db/dbconn"
+)
+
+// savedQueryNotificationPreferences stores users' preferences for the
+// notifications they receive for saved queries, including their subscriptions
+// to organizations' saved queries. Like webhook deliveries, they
+// are keyed by the saved query's settings subject and key.
+type savedQueryNotificationPreferences struct{}
+
+// Get returns the user's notification preference for the saved query, or nil
+// if the user has not set one.
+func (*savedQueryNotificationPreferences) Get(ctx context.Context, userID int32, spec api.SavedQueryIDSpec) (*api.SavedQueryNotificationPreference, error) {
+	q := sqlf.Sprintf("SELECT user_id, frequency, unsubscribed, subscribed, notify_email, notify_slack FROM saved_query_notification_preferences WHERE user_id=%s AND subject=%s AND saved_query_key=%s",
+		userID, spec.Subject.String(), spec.Key)
+	pref, err := scanSavedQueryNotificationPreference(dbconn.Global.QueryRowContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return pref, err
+}
+
+// List returns the notification preferences of all users who set them for the
+// saved query.
+func (*savedQueryNotificationPreferences) List(ctx context.Context, spec api.SavedQueryIDSpec) ([]*api.SavedQueryNotificationPreference, error) {
+	q := sqlf.Sprintf("SELECT user_id, frequency, unsubscribed, subscribed, notify_email, notify_slack FROM saved_query_notification_preferences WHERE subject=%s AND saved_query_key=%s ORDER BY user_id",
+		spec.Subject.String(), spec.Key)
+	rows, err := dbconn.Global.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var prefs []*api.SavedQueryNotificationPreference
+	for rows.Next() {
+		pref, err := scanSavedQueryNotificationPreference(rows)
+		if err != nil {
+			return nil, err
+		}
+		prefs = append(prefs, pref)
+	}
+	return prefs, rows.Err()
+}
+
+func scanSavedQueryNotificationPreference(row interface{ Scan(...interface{}) error }) (*api.SavedQueryNotificationPreference, error) {
+	var (
+		pref         api.SavedQueryNotificationPreference
+		frequency    sql.NullString
+		email, slack sql.NullBool
+	)
+	if err := row.Scan(&pref.UserID, &frequency, &pref.Unsubscribed, &pref.Subscribed, &email, &slack); err != nil {
+		return nil, err
+	}
+	pref.Frequency = frequency.String
+	if email.Valid {
+		pref.Email = &email.Bool
+	}
+	if slack.Valid {
+		pref.Slack = &slack.Bool
+	}
+	return &pref, nil
+}
+
+// Set sets the user's (pref.UserID's) notification preference for the saved
+// query, replacing any existing preference.
+func (*savedQueryNotificationPreferences) Set(ctx context.Context, spec api.SavedQueryIDSpec, pref *api.SavedQueryNotificationPreference) error {
+	frequency := sql.NullString{String: pref.Frequency, Valid: pref.Frequency != ""}
+	var email, slack sql.NullBool
+	if pref.Email != nil {
+		email = sql.NullBool{Bool: *pref.Email, Valid: true}
+	}
+	if pref.Slack != nil {
+		slack = sql.NullBool{Bool: *pref.Slack, Valid: true}
+	}
+	q := sqlf.Sprintf(`
+INSERT INTO saved_query_notification_preferences(user_id, subject, saved_query_key, frequency, unsubscribed, subscribed, notify_email, notify_slack) VALUES(%s, %s, %s, %s, %s, %s, %s, %s)
+ON CONFLICT (user_id, subject, saved_query_key) DO UPDATE SET frequency=EXCLUDED.frequency, unsubscribed=EXCLUDED.unsubscribed, subscribed=EXCLUDED.subscribed, notify_email=EXCLUDED.notify_email, notify_slack=EXCLUDED.notify_slack, updated_at=now()`,
+		pref.UserID, spec.Subject.String(), spec.Key, frequency, pref.Unsubscribed, pref.Subscribed, email, slack)
+	_, err := dbconn.Global.ExecContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	return err
+}
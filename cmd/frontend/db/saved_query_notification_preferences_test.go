@@ -0,0 +1,59 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/db/dbtesting"
+)
+
+func TestSavedQueryNotificationPreferences(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	ctx := dbtesting.TestContext(t)
+
+	user, err := Users.Create(ctx, NewUser{Username: "u"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec := api.SavedQueryIDSpec{Subject: api.SettingsSubject{Site: true}, Key: "k"}
+	other := api.SavedQueryIDSpec{Subject: api.SettingsSubject{Site: true}, Key: "other"}
+
+	if pref, err := SavedQueryNotificationPreferences.Get(ctx, user.ID, spec); err != nil {
+		t.Fatal(err)
+	} else if pref != nil {
+		t.Errorf("got preference %+v, want nil", pref)
+	}
+
+	if err := SavedQueryNotificationPreferences.Set(ctx, spec, &api.SavedQueryNotificationPreference{UserID: user.ID, Frequency: api.SavedQueryNotificationFrequencyDaily}); err != nil {
+		t.Fatal(err)
+	}
+	// Replaces the previous preference.
+	want := &api.SavedQueryNotificationPreference{UserID: user.ID, Unsubscribed: true}
+	if err := SavedQueryNotificationPreferences.Set(ctx, spec, want); err != nil {
+		t.Fatal(err)
+	}
+	yes, no := true, false
+	wantOther := &api.SavedQueryNotificationPreference{UserID: user.ID, Frequency: api.SavedQueryNotificationFrequencyHourly, Subscribed: true, Email: &no, Slack: &yes}
+	if err := SavedQueryNotificationPreferences.Set(ctx, other, wantOther); err != nil {
+		t.Fatal(err)
+	}
+
+	if pref, err := SavedQueryNotificationPreferences.Get(ctx, user.ID, spec); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(pref, want) {
+		t.Errorf("got preference %+v, want %+v", pref, want)
+	}
+	if prefs, err := SavedQueryNotificationPreferences.List(ctx, spec); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(prefs, []*api.SavedQueryNotificationPreference{want}) {
+		t.Errorf("got preferences %+v, want [%+v]", prefs, want)
+	}
+	if pref, err := SavedQueryNotificationPreferences.Get(ctx, user.ID, other); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(pref, wantOther) {
+		t.Errorf("got preference %+v, want %+v", pref, wantOther)
+	}
+}
@@ -0,0 +1,87 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/keegancsmith/sqlf"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/db/dbconn"
+)
+
+// savedQueryRuns is the history of executions of saved queries. Like the
+// saved query information in the saved_queries table, it is keyed by the
+// search query, because saved queries with the same query are executed once.
+type savedQueryRuns struct{}
+
+// savedQueryRunsRetention is how long saved query runs are kept.
+const savedQueryRunsRetention = 90 * 24 * time.Hour
+
+// Log records an execution of a saved query. Runs older than 90 days are
+// removed.
+func (*savedQueryRuns) Log(ctx context.Context, run *api.SavedQueryRun) error {
+	newResults := run.NewResults
+	if newResults == nil {
+		newResults = []*api.SavedQueryRunResult{}
+	}
+	newResultsJSON, err := json.Marshal(newResults)
+	if err != nil {
+		return err
+	}
+	executedAt := run.ExecutedAt
+	if executedAt.IsZero() {
+		executedAt = time.Now()
+	}
+	var resultCount sql.NullInt64
+	if run.ResultCount != nil {
+		resultCount = sql.NullInt64{Int64: int64(*run.ResultCount), Valid: true}
+	}
+	errorMessage := sql.NullString{String: run.Error, Valid: run.Error != ""}
+	q := sqlf.Sprintf("INSERT INTO saved_query_runs(query, executed_at, exec_duration_ns, result_count, new_result_count, limit_hit, error, new_results) VALUES(%s, %s, %s, %s, %s, %s, %s, %s)",
+		run.Query, executedAt, run.ExecDuration.Nanoseconds(), resultCount, run.NewResultCount, run.LimitHit, errorMessage, string(newResultsJSON))
+	if _, err := dbconn.Global.ExecContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...); err != nil {
+		return err
+	}
+
+	q = sqlf.Sprintf("DELETE FROM saved_query_runs WHERE executed_at < %s", time.Now().Add(-savedQueryRunsRetention))
+	_, err = dbconn.Global.ExecContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	return err
+}
+
+// List returns the most recent runs of the saved query, newest first.
+func (*savedQueryRuns) List(ctx context.Context, query string, limit int) ([]*api.SavedQueryRun, error) {
+	q := sqlf.Sprintf("SELECT executed_at, exec_duration_ns, result_count, new_result_count, limit_hit, error, new_results FROM saved_query_runs WHERE query=%s ORDER BY executed_at DESC, id DESC LIMIT %s",
+		query, limit)
+	rows, err := dbconn.Global.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []*api.SavedQueryRun
+	for rows.Next() {
+		run := api.SavedQueryRun{Query: query}
+		var (
+			execDurationNs int64
+			resultCount    sql.NullInt64
+			errorMessage   sql.NullString
+			newResultsJSON []byte
+		)
+		if err := rows.Scan(&run.ExecutedAt, &execDurationNs, &resultCount, &run.NewResultCount, &run.LimitHit, &errorMessage, &newResultsJSON); err != nil {
+			return nil, err
+		}
+		run.ExecDuration = time.Duration(execDurationNs)
+		if resultCount.Valid {
+			n := int(resultCount.Int64)
+			run.ResultCount = &n
+		}
+		run.Error = errorMessage.String
+		if err := json.Unmarshal(newResultsJSON, &run.NewResults); err != nil {
+			return nil, err
+		}
+		runs = append(runs, &run)
+	}
+	return runs, rows.Err()
+}
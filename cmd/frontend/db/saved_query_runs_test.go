@@ -0,0 +1,54 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/db/dbtesting"
+)
+
+func TestSavedQueryRuns(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	ctx := dbtesting.TestContext(t)
+
+	now := time.Now().Truncate(time.Second)
+	newResults := []*api.SavedQueryRunResult{{Repo: "github.com/a/b", Path: "c.go", Line: 3, Preview: "// TODO"}}
+	intPtr := func(n int) *int { return &n }
+	for _, run := range []*api.SavedQueryRun{
+		{Query: "q", ExecutedAt: now.Add(-2 * time.Hour), ExecDuration: time.Second, Error: "timeout"},
+		{Query: "q", ExecutedAt: now.Add(-time.Hour), ExecDuration: 2 * time.Second, ResultCount: intPtr(5), NewResultCount: 1, LimitHit: true, NewResults: newResults},
+		{Query: "other", ExecutedAt: now, NewResultCount: 1},
+		{Query: "q", ExecutedAt: now.Add(-100 * 24 * time.Hour)}, // removed because it's too old
+	} {
+		if err := SavedQueryRuns.Log(ctx, run); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	runs, err := SavedQueryRuns.List(ctx, "q", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 2 {
+		t.Fatalf("got %d runs, want 2", len(runs))
+	}
+	if r := runs[0]; !r.ExecutedAt.Equal(now.Add(-time.Hour)) || r.ExecDuration != 2*time.Second || r.ResultCount == nil || *r.ResultCount != 5 || r.NewResultCount != 1 || !r.LimitHit || r.Error != "" || !reflect.DeepEqual(r.NewResults, newResults) {
+		t.Errorf("got latest run %+v", r)
+	}
+	if r := runs[1]; r.Error != "timeout" || r.ResultCount != nil || r.NewResultCount != 0 || len(r.NewResults) != 0 {
+		t.Errorf("got earliest run %+v", r)
+	}
+
+	if err := SavedQueries.Delete(ctx, "q"); err != nil {
+		t.Fatal(err)
+	}
+	if runs, err := SavedQueryRuns.List(ctx, "q", 10); err != nil {
+		t.Fatal(err)
+	} else if len(runs) != 0 {
+		t.Errorf("got %d runs after deleting saved query, want 0", len(runs))
+	}
+}
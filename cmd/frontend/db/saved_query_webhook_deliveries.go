@@ -0,0 +1,67 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/keegancsmith/sqlf"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/db/dbconn"
+)
+
+// savedQueryWebhookDeliveries is the log of attempts to deliver notifications
+// to saved queries' webhooks. Saved queries are defined in settings (not in
+// the DB), so deliveries are keyed by the settings subject and saved query
+// key.
+type savedQueryWebhookDeliveries struct{}
+
+// savedQueryWebhookDeliveriesRetention is how long delivery log entries are
+// kept.
+const savedQueryWebhookDeliveriesRetention = 30 * 24 * time.Hour
+
+// Log records an attempt to deliver a notification to a saved query's webhook.
+// Entries older than 30 days are removed.
+func (*savedQueryWebhookDeliveries) Log(ctx context.Context, d *api.SavedQueryWebhookDelivery) error {
+	statusCode := sql.NullInt64{Int64: int64(d.StatusCode), Valid: d.StatusCode != 0}
+	errorMessage := sql.NullString{String: d.Error, Valid: d.Error != ""}
+	q := sqlf.Sprintf("INSERT INTO saved_query_webhook_deliveries(subject, saved_query_key, delivery_id, event, url, attempt, status_code, error, duration_ms) VALUES(%s, %s, %s, %s, %s, %s, %s, %s, %s)",
+		d.Spec.Subject.String(), d.Spec.Key, d.DeliveryID, d.Event, d.URL, d.Attempt, statusCode, errorMessage, int64(d.Duration/time.Millisecond))
+	if _, err := dbconn.Global.ExecContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...); err != nil {
+		return err
+	}
+
+	q = sqlf.Sprintf("DELETE FROM saved_query_webhook_deliveries WHERE created_at < %s", time.Now().Add(-savedQueryWebhookDeliveriesRetention))
+	_, err := dbconn.Global.ExecContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	return err
+}
+
+// List returns the most recent delivery attempts for the saved query, newest
+// first.
+func (*savedQueryWebhookDeliveries) List(ctx context.Context, spec api.SavedQueryIDSpec, limit int) ([]*api.SavedQueryWebhookDelivery, error) {
+	q := sqlf.Sprintf("SELECT delivery_id, event, url, attempt, status_code, error, duration_ms, created_at FROM saved_query_webhook_deliveries WHERE subject=%s AND saved_query_key=%s ORDER BY created_at DESC, id DESC LIMIT %s",
+		spec.Subject.String(), spec.Key, limit)
+	rows, err := dbconn.Global.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []*api.SavedQueryWebhookDelivery
+	for rows.Next() {
+		d := api.SavedQueryWebhookDelivery{Spec: spec}
+		var (
+			statusCode   sql.NullInt64
+			errorMessage sql.NullString
+			durationMs   int64
+		)
+		if err := rows.Scan(&d.DeliveryID, &d.Event, &d.URL, &d.Attempt, &statusCode, &errorMessage, &durationMs, &d.CreatedAt); err != nil {
+			return nil, err
+		}
+		d.StatusCode = int(statusCode.Int64)
+		d.Error = errorMessage.String
+		d.Duration = time.Duration(durationMs) * time.Millisecond
+		deliveries = append(deliveries, &d)
+	}
+	return deliveries, rows.Err()
+}
@@ -0,0 +1,50 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/db/dbtesting"
+)
+
+func TestSavedQueryWebhookDeliveries(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	ctx := dbtesting.TestContext(t)
+
+	userID := int32(1)
+	spec := api.SavedQueryIDSpec{Subject: api.SettingsSubject{User: &userID}, Key: "k"}
+	other := api.SavedQueryIDSpec{Subject: api.SettingsSubject{Site: true}, Key: "k"}
+
+	for _, d := range []*api.SavedQueryWebhookDelivery{
+		{Spec: spec, DeliveryID: "d1", Event: "results", URL: "https://example.com", Attempt: 1, Error: "timeout", Duration: time.Second},
+		{Spec: spec, DeliveryID: "d1", Event: "results", URL: "https://example.com", Attempt: 2, StatusCode: 200, Duration: 50 * time.Millisecond},
+		{Spec: other, DeliveryID: "d2", Event: "test", URL: "https://example.com", Attempt: 1, StatusCode: 200},
+	} {
+		if err := SavedQueryWebhookDeliveries.Log(ctx, d); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deliveries, err := SavedQueryWebhookDeliveries.List(ctx, spec, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deliveries) != 2 {
+		t.Fatalf("got %d deliveries, want 2", len(deliveries))
+	}
+	if d := deliveries[0]; d.Attempt != 2 || d.StatusCode != 200 || d.Error != "" || d.Duration != 50*time.Millisecond {
+		t.Errorf("got latest delivery %+v, want successful attempt 2", d)
+	}
+	if d := deliveries[1]; d.Attempt != 1 || d.StatusCode != 0 || d.Error != "timeout" || d.Duration != time.Second {
+		t.Errorf("got earliest delivery %+v, want failed attempt 1", d)
+	}
+
+	if deliveries, err := SavedQueryWebhookDeliveries.List(ctx, spec, 1); err != nil {
+		t.Fatal(err)
+	} else if len(deliveries) != 1 {
+		t.Errorf("got %d deliveries with limit 1, want 1", len(deliveries))
+	}
+}
@@ -146,6 +146,7 @@ func (o *settings) getLatest(ctx context.Context, queryTarget queryable, subject
 // inside and outside an explicit transaction.
 type queryable interface {
 	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
 }
 
 func (o *settings) parseQueryRows(ctx context.Context, rows *sql.Rows) ([]*api.Settings, error) {
@@ -1,19 +1,29 @@
 package db
 
 var (
-	AccessTokens              = &accessTokens{}
-	ExternalServices          = &externalServices{}
-	DiscussionThreads         = &discussionThreads{}
-	DiscussionComments        = &discussionComments{}
-	DiscussionMailReplyTokens = &discussionMailReplyTokens{}
-	Repos                     = &repos{}
-	Phabricator               = &phabricator{}
-	SavedQueries              = &savedQueries{}
-	Orgs                      = &orgs{}
-	OrgMembers                = &orgMembers{}
-	Settings                  = &settings{}
-	Users                     = &users{}
-	UserEmails                = &userEmails{}
+	AccessTokens                      = &accessTokens{}
+	ExternalServices                  = &externalServices{}
+	DiscussionThreads                 = &discussionThreads{}
+	DiscussionComments                = &discussionComments{}
+	DiscussionCommentReactions        = &discussionCommentReactions{}
+	DiscussionMailReplyTokens         = &discussionMailReplyTokens{}
+	DiscussionCodeHostComments        = &discussionCodeHostComments{}
+	Repos                             = &repos{}
+	RepoLanguageStats                 = &repoLanguageStats{}
+	InsightSeriesPoints               = &insightSeriesPoints{}
+	Phabricator                       = &phabricator{}
+	SavedQueries                      = &savedQueries{}
+	SavedQueryRuns                    = &savedQueryRuns{}
+	SavedQueryNotificationPreferences = &savedQueryNotificationPreferences{}
+	SavedQueryDigestItems             = &savedQueryDigestItems{}
+	SavedQueryWebhookDeliveries       = &savedQueryWebhookDeliveries{}
+	Orgs                              = &orgs{}
+	OrgMembers                        = &orgMembers{}
+	Settings                          = &settings{}
+	Users                             = &users{}
+	UserEmails                        = &userEmails{}
+	UserPermissions                   = &userPermissions{}
+	ExplicitRepoPermissions           = &explicitRepoPermissions{}
 
 	SurveyResponses = &surveyResponses{}
 
@@ -0,0 +1,157 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/keegancsmith/sqlf"
+	"github.com/lib/pq"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/db/dbconn"
+)
+
+// userPermissionsNotFoundError is the error that is returned when a user's permissions have never
+// been synced (or requested to be).
+type userPermissionsNotFoundError struct {
+	userID int32
+}
+
+func (err userPermissionsNotFoundError) Error() string {
+	return fmt.Sprintf("user permissions not found: %d", err.userID)
+}
+
+func (err userPermissionsNotFoundError) NotFound() bool {
+	return true
+}
+
+// userPermissions provides access to the `user_permissions` table, which stores the set of
+// repositories that each user may access, as computed from the authz providers by the background
+// permissions syncer (see package permsync). Requests only ever read from this table; they never
+// call the authz providers.
+//
+// The permissions of anonymous users are stored with a user ID of 0.
+type userPermissions struct{}
+
+// Get returns the most recently synced permissions of the user.
+func (*userPermissions) Get(ctx context.Context, userID int32) (*types.UserPermissions, error) {
+	if Mocks.UserPermissions.Get != nil {
+		return Mocks.UserPermissions.Get(ctx, userID)
+	}
+
+	p := types.UserPermissions{UserID: userID}
+	var repoIDs []int64
+	err := dbconn.Global.QueryRowContext(ctx,
+		"SELECT read_repo_ids, synced_at, sync_attempted_at, sync_requested_at, sync_error FROM user_permissions WHERE user_id=$1",
+		userID,
+	).Scan(pq.Array(&repoIDs), &p.SyncedAt, &p.SyncAttemptedAt, &p.SyncRequestedAt, &p.SyncError)
+	if err == sql.ErrNoRows {
+		return nil, userPermissionsNotFoundError{userID}
+	}
+	if err != nil {
+		return nil, err
+	}
+	p.ReadRepoIDs = make([]api.RepoID, len(repoIDs))
+	for i, id := range repoIDs {
+		p.ReadRepoIDs[i] = api.RepoID(id)
+	}
+	return &p, nil
+}
+
+// RequestSync schedules the user's permissions to be synced as soon as possible (instead of when
+// they become stale).
+func (*userPermissions) RequestSync(ctx context.Context, userID int32) error {
+	if Mocks.UserPermissions.RequestSync != nil {
+		return Mocks.UserPermissions.RequestSync(ctx, userID)
+	}
+
+	_, err := dbconn.Global.ExecContext(ctx, `
+INSERT INTO user_permissions(user_id, sync_requested_at) VALUES($1, now())
+ON CONFLICT (user_id) DO UPDATE SET sync_requested_at=now()`, userID)
+	return err
+}
+
+// RequestSyncAll schedules the permissions of all users (and of anonymous users) to be synced as
+// soon as possible. It is used when the authz providers change, because repositories that a new
+// provider is the source of permissions for are inaccessible to users until their permissions are
+// synced again.
+func (*userPermissions) RequestSyncAll(ctx context.Context) error {
+	if Mocks.UserPermissions.RequestSyncAll != nil {
+		return Mocks.UserPermissions.RequestSyncAll(ctx)
+	}
+
+	_, err := dbconn.Global.ExecContext(ctx, `
+INSERT INTO user_permissions(user_id, sync_requested_at)
+SELECT 0, now() UNION ALL SELECT id, now() FROM users WHERE deleted_at IS NULL AND NOT site_admin
+ON CONFLICT (user_id) DO UPDATE SET sync_requested_at=now()`)
+	return err
+}
+
+// SetSynced records the result of a successful sync of the user's permissions, replacing the
+// previously synced permissions.
+func (*userPermissions) SetSynced(ctx context.Context, userID int32, readRepoIDs []api.RepoID) error {
+	if Mocks.UserPermissions.SetSynced != nil {
+		return Mocks.UserPermissions.SetSynced(ctx, userID, readRepoIDs)
+	}
+
+	repoIDs := make([]int64, len(readRepoIDs))
+	for i, id := range readRepoIDs {
+		repoIDs[i] = int64(id)
+	}
+	_, err := dbconn.Global.ExecContext(ctx, `
+INSERT INTO user_permissions(user_id, read_repo_ids, synced_at, sync_attempted_at) VALUES($1, $2, now(), now())
+ON CONFLICT (user_id) DO UPDATE SET read_repo_ids=$2, synced_at=now(), sync_attempted_at=now(), sync_error=NULL`,
+		userID, pq.Array(repoIDs))
+	return err
+}
+
+// SetSyncError records that syncing the user's permissions failed. The previously synced
+// permissions (if any) are kept.
+func (*userPermissions) SetSyncError(ctx context.Context, userID int32, syncErr string) error {
+	if Mocks.UserPermissions.SetSyncError != nil {
+		return Mocks.UserPermissions.SetSyncError(ctx, userID, syncErr)
+	}
+
+	_, err := dbconn.Global.ExecContext(ctx, `
+INSERT INTO user_permissions(user_id, sync_attempted_at, sync_error) VALUES($1, now(), $2)
+ON CONFLICT (user_id) DO UPDATE SET sync_attempted_at=now(), sync_error=$2`,
+		userID, syncErr)
+	return err
+}
+
+// ListSyncCandidates returns the IDs of up to limit users whose permissions need to be synced,
+// most urgent first: those whose sync was requested, then those that were never synced, then those
+// whose last sync attempt was before staleBefore (oldest first).
+//
+// Site admins and deleted users are never returned, because their permissions are not used.
+func (*userPermissions) ListSyncCandidates(ctx context.Context, staleBefore time.Time, limit int) ([]int32, error) {
+	if Mocks.UserPermissions.ListSyncCandidates != nil {
+		return Mocks.UserPermissions.ListSyncCandidates(ctx, staleBefore, limit)
+	}
+
+	q := sqlf.Sprintf(`
+SELECT u.id FROM users u
+LEFT JOIN user_permissions p ON p.user_id=u.id
+WHERE u.deleted_at IS NULL AND NOT u.site_admin
+AND (p.sync_attempted_at IS NULL OR p.sync_attempted_at < %s OR p.sync_requested_at > p.sync_attempted_at)
+ORDER BY (p.sync_requested_at IS NOT NULL AND (p.sync_attempted_at IS NULL OR p.sync_requested_at > p.sync_attempted_at)) DESC,
+	p.sync_attempted_at ASC NULLS FIRST, u.id ASC
+LIMIT %s`, staleBefore, limit)
+
+	rows, err := dbconn.Global.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var userIDs []int32
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, id)
+	}
+	return userIDs, rows.Err()
+}
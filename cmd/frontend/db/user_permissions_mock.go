@@ -0,0 +1,18 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+)
+
+type MockUserPermissions struct {
+	Get                func(ctx context.Context, userID int32) (*types.UserPermissions, error)
+	RequestSync        func(ctx context.Context, userID int32) error
+	RequestSyncAll     func(ctx context.Context) error
+	SetSynced          func(ctx context.Context, userID int32, readRepoIDs []api.RepoID) error
+	SetSyncError       func(ctx context.Context, userID int32, syncErr string) error
+	ListSyncCandidates func(ctx context.Context, staleBefore time.Time, limit int) ([]int32, error)
+}
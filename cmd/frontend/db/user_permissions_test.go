@@ -0,0 +1,110 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/db/dbtesting"
+	"github.com/sourcegraph/sourcegraph/pkg/errcode"
+)
+
+func TestUserPermissions(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	ctx := dbtesting.TestContext(t)
+
+	// The first user is a site admin, whose permissions are never synced.
+	var userIDs []int32
+	for _, username := range []string{"admin", "u1", "u2", "u3"} {
+		user, err := Users.Create(ctx, NewUser{Username: username})
+		if err != nil {
+			t.Fatal(err)
+		}
+		userIDs = append(userIDs, user.ID)
+	}
+	u1, u2, u3 := userIDs[1], userIDs[2], userIDs[3]
+	if err := Users.Delete(ctx, u3); err != nil {
+		t.Fatal(err)
+	}
+
+	past := time.Now().Add(-time.Hour)
+	checkCandidates := func(staleBefore time.Time, want []int32) {
+		t.Helper()
+		got, err := UserPermissions.ListSyncCandidates(ctx, staleBefore, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got sync candidates %v, want %v", got, want)
+		}
+	}
+
+	// Users whose permissions were never synced are candidates (but not site admins or deleted
+	// users).
+	if _, err := UserPermissions.Get(ctx, u1); !errcode.IsNotFound(err) {
+		t.Errorf("got error %v, want not found", err)
+	}
+	checkCandidates(past, []int32{u1, u2})
+
+	if err := UserPermissions.SetSynced(ctx, u1, []api.RepoID{3, 1}); err != nil {
+		t.Fatal(err)
+	}
+	p, err := UserPermissions.Get(ctx, u1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []api.RepoID{3, 1}; !reflect.DeepEqual(p.ReadRepoIDs, want) {
+		t.Errorf("got read repo IDs %v, want %v", p.ReadRepoIDs, want)
+	}
+	if p.SyncedAt == nil || p.SyncAttemptedAt == nil || p.SyncError != nil {
+		t.Errorf("got %+v, want synced without error", p)
+	}
+	checkCandidates(past, []int32{u2})
+
+	// Failed syncs are not retried until they are stale.
+	if err := UserPermissions.SetSyncError(ctx, u2, "boom"); err != nil {
+		t.Fatal(err)
+	}
+	if p, err := UserPermissions.Get(ctx, u2); err != nil {
+		t.Fatal(err)
+	} else if p.SyncedAt != nil || p.SyncError == nil || *p.SyncError != "boom" {
+		t.Errorf("got %+v, want sync error", p)
+	}
+	checkCandidates(past, nil)
+
+	// Requested syncs come first, and keep the previously synced permissions.
+	if err := UserPermissions.RequestSync(ctx, u1); err != nil {
+		t.Fatal(err)
+	}
+	checkCandidates(past, []int32{u1})
+	checkCandidates(time.Now().Add(time.Hour), []int32{u1, u2})
+	if p, err := UserPermissions.Get(ctx, u1); err != nil {
+		t.Fatal(err)
+	} else if len(p.ReadRepoIDs) != 2 || p.SyncRequestedAt == nil {
+		t.Errorf("got %+v, want previous permissions and requested sync", p)
+	}
+
+	if err := UserPermissions.SetSynced(ctx, u1, nil); err != nil {
+		t.Fatal(err)
+	}
+	if p, err := UserPermissions.Get(ctx, u1); err != nil {
+		t.Fatal(err)
+	} else if len(p.ReadRepoIDs) != 0 {
+		t.Errorf("got read repo IDs %v, want none", p.ReadRepoIDs)
+	}
+	checkCandidates(past, nil)
+
+	// u2's last sync attempt was before u1's.
+	if err := UserPermissions.RequestSyncAll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	checkCandidates(past, []int32{u2, u1})
+	if p, err := UserPermissions.Get(ctx, 0); err != nil {
+		t.Fatal(err)
+	} else if p.SyncRequestedAt == nil {
+		t.Errorf("got %+v, want requested sync of anonymous users' permissions", p)
+	}
+}
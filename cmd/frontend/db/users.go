@@ -401,6 +401,9 @@ func (u *users) Delete(ctx context.Context, id int32) error {
 	if _, err := tx.ExecContext(ctx, "UPDATE registry_extensions SET deleted_at=now() WHERE deleted_at IS NULL AND publisher_user_id=$1", id); err != nil {
 		return err
 	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM user_permissions WHERE user_id=$1", id); err != nil {
+		return err
+	}
 
 	// Soft-delete discussions data.
 	if _, err := tx.ExecContext(ctx, "UPDATE discussion_mail_reply_tokens SET deleted_at=now() WHERE deleted_at IS NULL AND user_id=$1", id); err != nil {
@@ -445,6 +448,9 @@ func (u *users) HardDelete(ctx context.Context, id int32) error {
 	if _, err := tx.ExecContext(ctx, "DELETE FROM user_external_accounts WHERE user_id=$1", id); err != nil {
 		return err
 	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM user_permissions WHERE user_id=$1", id); err != nil {
+		return err
+	}
 	if _, err := tx.ExecContext(ctx, "DELETE FROM survey_responses WHERE user_id=$1", id); err != nil {
 		return err
 	}
@@ -510,6 +516,57 @@ func (u *users) SetIsSiteAdmin(ctx context.Context, id int32, isSiteAdmin bool)
 	return err
 }
 
+// SetDeactivated deactivates or reactivates a user. Deactivating a user also revokes their sessions
+// and deletes their access tokens (and those they created for other users), so that they lose
+// access immediately. Deactivated users can't sign in until they are reactivated.
+//
+// 🚨 SECURITY: The caller must ensure that the actor is permitted to deactivate the user.
+func (u *users) SetDeactivated(ctx context.Context, id int32, deactivated bool) (err error) {
+	if Mocks.Users.SetDeactivated != nil {
+		return Mocks.Users.SetDeactivated(id, deactivated)
+	}
+
+	tx, err := dbconn.Global.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			rollErr := tx.Rollback()
+			if rollErr != nil {
+				err = multierror.Append(err, rollErr)
+			}
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	var q string
+	if deactivated {
+		q = "UPDATE users SET deactivated_at=COALESCE(deactivated_at, now()), invalidated_sessions_at=now(), updated_at=now() WHERE id=$1 AND deleted_at IS NULL"
+	} else {
+		q = "UPDATE users SET deactivated_at=NULL, updated_at=now() WHERE id=$1 AND deleted_at IS NULL"
+	}
+	res, err := tx.ExecContext(ctx, q, id)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return userNotFoundErr{args: []interface{}{id}}
+	}
+
+	if deactivated {
+		if _, err := tx.ExecContext(ctx, "UPDATE access_tokens SET deleted_at=now() WHERE deleted_at IS NULL AND (subject_user_id=$1 OR creator_user_id=$1)", id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // CheckAndDecrementInviteQuota should be called before the user (identified
 // by userID) is allowed to invite any other user. If ok is false, then the
 // user is not allowed to invite any other user (either because they've
@@ -660,7 +717,7 @@ func (u *users) getOneBySQL(ctx context.Context, query string, args ...interface
 
 // getBySQL returns users matching the SQL query, if any exist.
 func (*users) getBySQL(ctx context.Context, query string, args ...interface{}) ([]*types.User, error) {
-	rows, err := dbconn.Global.QueryContext(ctx, "SELECT u.id, u.username, u.display_name, u.avatar_url, u.created_at, u.updated_at, u.site_admin, u.tags FROM users u "+query, args...)
+	rows, err := dbconn.Global.QueryContext(ctx, "SELECT u.id, u.username, u.display_name, u.avatar_url, u.created_at, u.updated_at, u.site_admin, u.tags, u.deactivated_at, u.invalidated_sessions_at FROM users u "+query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -670,7 +727,7 @@ func (*users) getBySQL(ctx context.Context, query string, args ...interface{}) (
 	for rows.Next() {
 		var u types.User
 		var displayName, avatarURL sql.NullString
-		err := rows.Scan(&u.ID, &u.Username, &displayName, &avatarURL, &u.CreatedAt, &u.UpdatedAt, &u.SiteAdmin, pq.Array(&u.Tags))
+		err := rows.Scan(&u.ID, &u.Username, &displayName, &avatarURL, &u.CreatedAt, &u.UpdatedAt, &u.SiteAdmin, pq.Array(&u.Tags), &u.DeactivatedAt, &u.InvalidatedSessionsAt)
 		if err != nil {
 			return nil, err
 		}
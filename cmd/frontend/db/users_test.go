@@ -499,6 +499,74 @@ func TestUsers_Delete(t *testing.T) {
 	}
 }
 
+func TestUsers_SetDeactivated(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	ctx := dbtesting.TestContext(t)
+
+	admin, err := Users.Create(ctx, NewUser{Username: "admin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err := Users.Create(ctx, NewUser{Username: "u"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, ownToken, err := AccessTokens.Create(ctx, user.ID, []string{"user:all"}, "n", user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, createdToken, err := AccessTokens.Create(ctx, admin.ID, []string{"user:all"}, "n", user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, adminToken, err := AccessTokens.Create(ctx, admin.ID, []string{"user:all"}, "n", admin.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Users.SetDeactivated(ctx, user.ID, true); err != nil {
+		t.Fatal(err)
+	}
+	user, err = Users.GetByID(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.DeactivatedAt == nil || user.InvalidatedSessionsAt == nil {
+		t.Fatalf("got DeactivatedAt %v and InvalidatedSessionsAt %v, want both set", user.DeactivatedAt, user.InvalidatedSessionsAt)
+	}
+
+	// The user's access tokens (and those they created) are deleted, but not others.
+	for _, token := range []string{ownToken, createdToken} {
+		if _, err := AccessTokens.Lookup(ctx, token, "user:all"); err != ErrAccessTokenNotFound {
+			t.Errorf("got error %v, want ErrAccessTokenNotFound", err)
+		}
+	}
+	if _, err := AccessTokens.Lookup(ctx, adminToken, "user:all"); err != nil {
+		t.Error(err)
+	}
+
+	// Reactivate.
+	if err := Users.SetDeactivated(ctx, user.ID, false); err != nil {
+		t.Fatal(err)
+	}
+	user, err = Users.GetByID(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.DeactivatedAt != nil {
+		t.Errorf("got DeactivatedAt %v, want nil", user.DeactivatedAt)
+	}
+	if user.InvalidatedSessionsAt == nil {
+		t.Error("got InvalidatedSessionsAt nil, want it to be unchanged")
+	}
+
+	if err := Users.SetDeactivated(ctx, 12345, true); !errcode.IsNotFound(err) {
+		t.Errorf("got error %v, want not found", err)
+	}
+}
+
 func normalizeUsers(users []*types.User) []*types.User {
 	for _, u := range users {
 		u.CreatedAt = u.CreatedAt.Local().Round(time.Second)
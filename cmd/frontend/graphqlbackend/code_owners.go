@@ -0,0 +1,37 @@
+package graphqlbackend
+
+import (
+	"context"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/codeowners"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+)
+
+func (r *gitTreeEntryResolver) CodeOwners(ctx context.Context) ([]*codeOwnerResolver, error) {
+	file, err := codeowners.ForCommit(ctx, r.commit.repo.repo, api.CommitID(r.commit.oid))
+	if err != nil || file == nil {
+		return []*codeOwnerResolver{}, err
+	}
+	owners, err := codeowners.ResolveOwners(ctx, r.commit.repo.repo, file.Owners(r.path))
+	if err != nil {
+		return nil, err
+	}
+	resolvers := make([]*codeOwnerResolver, len(owners))
+	for i, owner := range owners {
+		resolvers[i] = &codeOwnerResolver{owner: owner}
+	}
+	return resolvers, nil
+}
+
+type codeOwnerResolver struct {
+	owner *codeowners.Owner
+}
+
+func (r *codeOwnerResolver) Name() string { return r.owner.Name }
+
+func (r *codeOwnerResolver) User() *UserResolver {
+	if r.owner.User == nil {
+		return nil
+	}
+	return &UserResolver{user: r.owner.User}
+}
@@ -0,0 +1,228 @@
+package graphqlbackend
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/discussions"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/gitserver"
+	"github.com/sourcegraph/sourcegraph/pkg/vcs/git"
+	"sourcegraph.com/sourcegraph/go-diff/diff"
+)
+
+// discussionAnchorResolver resolves where a discussion thread's target (its
+// path and selection) is in another revision.
+type discussionAnchorResolver struct {
+	path      *string                       // nil if the file no longer exists
+	selection *discussions.TrackedSelection // nil if the thread has no selection or the file no longer exists
+	t         *types.DiscussionThreadTargetRepo
+}
+
+func (r *discussionAnchorResolver) Path() *string { return r.path }
+
+func (r *discussionAnchorResolver) Selection() *discussionSelectionRangeResolver {
+	if r.selection == nil || r.selection.Outdated() {
+		return nil
+	}
+	return toDiscussionSelectionRange(r.t, r.selection.LineRange)
+}
+
+func (r *discussionAnchorResolver) Outdated() bool {
+	return r.path == nil || (r.selection != nil && r.selection.Outdated())
+}
+
+func (r *discussionAnchorResolver) Confidence() float64 {
+	switch {
+	case r.path == nil:
+		return 0
+	case r.selection != nil:
+		return r.selection.Confidence
+	default:
+		return 1
+	}
+}
+
+func toDiscussionSelectionRange(t *types.DiscussionThreadTargetRepo, lines discussions.LineRange) *discussionSelectionRangeResolver {
+	return &discussionSelectionRangeResolver{
+		startLine:      int32(lines.StartLine),
+		startCharacter: *t.StartCharacter,
+		endLine:        int32(lines.EndLine),
+		endCharacter:   *t.EndCharacter,
+	}
+}
+
+func (r *discussionThreadTargetRepoResolver) RelativeAnchor(ctx context.Context, args *struct {
+	Rev string
+}) (*discussionAnchorResolver, error) {
+	return r.anchor(ctx, args.Rev)
+}
+
+// anchor determines where the thread's target is in the given revision: it
+// follows the file through renames since the revision (or, failing that, the
+// branch) that the thread was created on, maps the selection through the
+// file's diff, and falls back to fuzzily matching the selection's snapshot
+// lines. It returns nil if the thread has no path or the revision doesn't
+// exist.
+func (r *discussionThreadTargetRepoResolver) anchor(ctx context.Context, rev string) (*discussionAnchorResolver, error) {
+	if r.t.Path == nil {
+		return nil, nil
+	}
+	repo, err := repositoryByIDInt32(ctx, r.t.RepoID)
+	if err != nil {
+		return nil, err
+	}
+	commitID, err := backend.Repos.ResolveRev(ctx, repo.repo, rev)
+	if err != nil {
+		if git.IsRevisionNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	cachedRepo, err := backend.CachedGitRepo(ctx, repo.repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var baseRev string
+	if r.t.Revision != nil {
+		baseRev = *r.t.Revision
+	} else if r.t.Branch != nil {
+		baseRev = *r.t.Branch
+	}
+
+	path := *r.t.Path
+	var hunks []*diff.Hunk // nil if the diff is unknown
+	if baseRev != "" {
+		baseCommitID, err := backend.Repos.ResolveRev(ctx, repo.repo, baseRev)
+		if err != nil && !git.IsRevisionNotFound(err) {
+			return nil, err
+		}
+		if err == nil {
+			var newPath *string
+			newPath, hunks, err = discussionFileDiff(ctx, *cachedRepo, baseCommitID, commitID, path)
+			if err != nil {
+				return nil, err
+			}
+			if newPath == nil {
+				return &discussionAnchorResolver{t: r.t}, nil // the file was removed
+			}
+			path = *newPath
+		}
+	}
+
+	// If the thread wasn't created on a specific revision or branch (or it no
+	// longer exists), we cannot walk the history. Instead, we must assume its
+	// path and only fuzzily match its selection.
+	content, err := git.ReadFile(ctx, *cachedRepo, commitID, path)
+	if err != nil {
+		// File does not exist in this revision.
+		return &discussionAnchorResolver{t: r.t}, nil
+	}
+
+	anchor := &discussionAnchorResolver{path: &path, t: r.t}
+	if r.t.HasSelection() {
+		tracked := discussions.TrackSelection(discussions.ThreadSelection(r.t), hunks, string(content))
+		anchor.selection = &tracked
+	}
+	return anchor, nil
+}
+
+// discussionFileDiff returns the path in the head commit of the file at path in
+// the base commit (following renames), and the diff hunks of the file between
+// the commits (an empty, non-nil slice if the file is unchanged). The path is
+// nil if the file was removed.
+func discussionFileDiff(ctx context.Context, repo gitserver.Repo, base, head api.CommitID, path string) (newPath *string, hunks []*diff.Hunk, err error) {
+	if base == head {
+		return &path, []*diff.Hunk{}, nil
+	}
+	if strings.HasPrefix(string(base), "-") || strings.HasPrefix(string(head), "-") {
+		// This should not be possible since the commit IDs were returned by
+		// ResolveRev, but be extra careful to avoid letting user input add
+		// additional `git diff` command-line flags.
+		return nil, nil, errors.New("invalid commit ID")
+	}
+
+	nameStatus, err := execGit(ctx, repo, []string{"diff", "--find-renames", "--name-status", "-z", string(base), string(head), "--"})
+	if err != nil {
+		return nil, nil, err
+	}
+	p, changed := followRenames(nameStatus, path)
+	if !changed {
+		return &path, []*diff.Hunk{}, nil
+	}
+	if p == "" {
+		return nil, nil, nil
+	}
+
+	rawDiff, err := execGit(ctx, repo, []string{"diff", "--find-renames", "--full-index", "--no-prefix", string(base), string(head), "--", path, p})
+	if err != nil {
+		return nil, nil, err
+	}
+	fileDiffs, err := diff.ParseMultiFileDiff(rawDiff)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, fileDiff := range fileDiffs {
+		if fileDiff.OrigName == path {
+			hunks = fileDiff.Hunks
+			break
+		}
+	}
+	if hunks == nil {
+		hunks = []*diff.Hunk{} // renamed without changes
+	}
+	return &p, hunks, nil
+}
+
+// followRenames returns the new path of the file at path, given the output of
+// `git diff --name-status -z`. It returns changed == false if the file is not
+// in the diff, and an empty new path if the file was removed.
+func followRenames(nameStatus []byte, path string) (newPath string, changed bool) {
+	fields := bytes.Split(bytes.TrimSuffix(nameStatus, []byte{0}), []byte{0})
+	for i := 0; i < len(fields); i++ {
+		status := string(fields[i])
+		if status == "" {
+			continue
+		}
+		switch status[0] {
+		case 'R', 'C':
+			// Renames and copies are followed by the old and new paths.
+			if i+2 >= len(fields) {
+				return "", false
+			}
+			oldPath, p := string(fields[i+1]), string(fields[i+2])
+			i += 2
+			if status[0] == 'R' && oldPath == path {
+				return p, true
+			}
+		default:
+			if i+1 >= len(fields) {
+				return "", false
+			}
+			p := string(fields[i+1])
+			i++
+			if p == path {
+				if status[0] == 'D' {
+					return "", true
+				}
+				return p, true
+			}
+		}
+	}
+	return "", false
+}
+
+func execGit(ctx context.Context, repo gitserver.Repo, args []string) ([]byte, error) {
+	rdr, err := git.ExecReader(ctx, repo, args)
+	if err != nil {
+		return nil, err
+	}
+	defer rdr.Close()
+	return ioutil.ReadAll(rdr)
+}
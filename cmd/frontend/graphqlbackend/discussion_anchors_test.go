@@ -0,0 +1,24 @@
+package graphqlbackend
+
+import "testing"
+
+func TestFollowRenames(t *testing.T) {
+	nameStatus := []byte("M\x00a.go\x00R087\x00old/b.go\x00new/b.go\x00C100\x00c.go\x00c2.go\x00D\x00d.go\x00")
+	tests := []struct {
+		path        string
+		wantPath    string
+		wantChanged bool
+	}{
+		{path: "a.go", wantPath: "a.go", wantChanged: true},
+		{path: "old/b.go", wantPath: "new/b.go", wantChanged: true},
+		{path: "c.go", wantPath: "", wantChanged: false}, // copied, so the original still exists unchanged
+		{path: "d.go", wantPath: "", wantChanged: true},
+		{path: "e.go", wantPath: "", wantChanged: false},
+	}
+	for _, test := range tests {
+		gotPath, gotChanged := followRenames(nameStatus, test.path)
+		if gotPath != test.wantPath || gotChanged != test.wantChanged {
+			t.Errorf("%s: got (%q, %v), want (%q, %v)", test.path, gotPath, gotChanged, test.wantPath, test.wantChanged)
+		}
+	}
+}
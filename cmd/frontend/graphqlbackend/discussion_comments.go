@@ -14,7 +14,9 @@ import (
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/discussions"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/actor"
 	"github.com/sourcegraph/sourcegraph/pkg/conf"
+	"github.com/sourcegraph/sourcegraph/pkg/errcode"
 	"github.com/sourcegraph/sourcegraph/pkg/markdown"
 )
 
@@ -117,6 +119,96 @@ func (r *discussionCommentResolver) CanDelete(ctx context.Context) bool {
 	return true
 }
 
+func (r *discussionCommentResolver) ParentComment(ctx context.Context) (*discussionCommentResolver, error) {
+	if r.c.ParentCommentID == nil {
+		return nil, nil
+	}
+	parent, err := db.DiscussionComments.Get(ctx, *r.c.ParentCommentID)
+	if _, ok := err.(*db.ErrCommentNotFound); ok {
+		return nil, nil // deleted
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "DiscussionComments.Get")
+	}
+	return &discussionCommentResolver{c: parent}, nil
+}
+
+func (r *discussionCommentResolver) Replies(ctx context.Context, args *struct {
+	graphqlutil.ConnectionArgs
+}) *discussionCommentsConnectionResolver {
+	// 🚨 SECURITY: Replies are in the same thread as this comment, so anyone
+	// with access to this comment also has access to them.
+	opt := &db.DiscussionCommentsListOptions{ParentCommentID: &r.c.ID}
+	args.ConnectionArgs.Set(&opt.LimitOffset)
+	return &discussionCommentsConnectionResolver{opt: opt}
+}
+
+func (r *discussionCommentResolver) ResolvedAt(ctx context.Context) *string {
+	if r.c.ResolvedAt == nil {
+		return nil
+	}
+	return strptr(r.c.ResolvedAt.Format(time.RFC3339))
+}
+
+func (r *discussionCommentResolver) Reactions(ctx context.Context) ([]*discussionReactionResolver, error) {
+	reactions, err := db.DiscussionCommentReactions.ListByComment(ctx, r.c.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "DiscussionCommentReactions.ListByComment")
+	}
+	var viewerUserID int32
+	if a := actor.FromContext(ctx); a.IsAuthenticated() {
+		viewerUserID = a.UID
+	}
+
+	// Group the reactions by emoji, in the order that each emoji was first
+	// used.
+	var (
+		groups  []*discussionReactionResolver
+		byEmoji = map[string]*discussionReactionResolver{}
+	)
+	for _, reaction := range reactions {
+		group, ok := byEmoji[reaction.Emoji]
+		if !ok {
+			group = &discussionReactionResolver{emoji: reaction.Emoji}
+			byEmoji[reaction.Emoji] = group
+			groups = append(groups, group)
+		}
+		group.userIDs = append(group.userIDs, reaction.UserID)
+		if reaction.UserID == viewerUserID {
+			group.viewerHasReacted = true
+		}
+	}
+	return groups, nil
+}
+
+type discussionReactionResolver struct {
+	emoji            string
+	userIDs          []int32
+	viewerHasReacted bool
+}
+
+func (r *discussionReactionResolver) Emoji() string { return r.emoji }
+
+func (r *discussionReactionResolver) Users(ctx context.Context) ([]*UserResolver, error) {
+	users := make([]*UserResolver, 0, len(r.userIDs))
+	for _, userID := range r.userIDs {
+		user, err := UserByIDInt32(ctx, userID)
+		if errcode.IsNotFound(err) {
+			continue // the user was deleted after reacting
+		} else if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+func (r *discussionReactionResolver) ViewerHasReacted() bool { return r.viewerHasReacted }
+
+func (*schemaResolver) DiscussionReactionEmojis() []string {
+	return db.DiscussionReactionEmojis
+}
+
 func (*schemaResolver) DiscussionComments(ctx context.Context, args *struct {
 	graphqlutil.ConnectionArgs
 	AuthorUserID *graphql.ID
@@ -200,6 +292,84 @@ func (r *discussionsMutationResolver) AddCommentToThread(ctx context.Context, ar
 	return &discussionThreadResolver{t: updatedThread}, nil
 }
 
+func (r *discussionsMutationResolver) ReplyToComment(ctx context.Context, args *struct {
+	CommentID graphql.ID
+	Contents  string
+}) (*discussionThreadResolver, error) {
+	// 🚨 SECURITY: Only signed in users with a verified email may reply to
+	// comments (see AddCommentToThread).
+	currentUser, err := checkSignedInAndEmailVerified(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(args.Contents) == "" {
+		return nil, errors.New("cannot add empty replies to comments")
+	}
+
+	commentID, err := unmarshalDiscussionID(args.CommentID)
+	if err != nil {
+		return nil, err
+	}
+	parent, err := db.DiscussionComments.Get(ctx, commentID)
+	if err != nil {
+		return nil, errors.Wrap(err, "DiscussionComments.Get")
+	}
+
+	updatedThread, err := discussions.InsecureAddCommentToThread(ctx, &types.DiscussionComment{
+		ThreadID:        parent.ThreadID,
+		AuthorUserID:    currentUser.user.ID,
+		Contents:        args.Contents,
+		ParentCommentID: &parent.ID,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "AddCommentToThread")
+	}
+	return &discussionThreadResolver{t: updatedThread}, nil
+}
+
+func (r *discussionsMutationResolver) AddReactionToComment(ctx context.Context, args *struct {
+	CommentID graphql.ID
+	Emoji     string
+}) (*discussionCommentResolver, error) {
+	return r.updateReaction(ctx, args.CommentID, func(commentID int64, userID int32) error {
+		return db.DiscussionCommentReactions.Add(ctx, commentID, userID, args.Emoji)
+	})
+}
+
+func (r *discussionsMutationResolver) RemoveReactionFromComment(ctx context.Context, args *struct {
+	CommentID graphql.ID
+	Emoji     string
+}) (*discussionCommentResolver, error) {
+	return r.updateReaction(ctx, args.CommentID, func(commentID int64, userID int32) error {
+		return db.DiscussionCommentReactions.Remove(ctx, commentID, userID, args.Emoji)
+	})
+}
+
+func (r *discussionsMutationResolver) updateReaction(ctx context.Context, id graphql.ID, update func(commentID int64, userID int32) error) (*discussionCommentResolver, error) {
+	// 🚨 SECURITY: Only signed in users may react to discussion comments.
+	currentUser, err := CurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if currentUser == nil {
+		return nil, errors.New("no current user")
+	}
+
+	commentID, err := unmarshalDiscussionID(id)
+	if err != nil {
+		return nil, err
+	}
+	comment, err := db.DiscussionComments.Get(ctx, commentID)
+	if err != nil {
+		return nil, errors.Wrap(err, "DiscussionComments.Get")
+	}
+	if err := update(comment.ID, currentUser.user.ID); err != nil {
+		return nil, err
+	}
+	return &discussionCommentResolver{c: comment}, nil
+}
+
 func (r *discussionsMutationResolver) UpdateComment(ctx context.Context, args *struct {
 	Input *struct {
 		CommentID    graphql.ID
@@ -207,6 +377,7 @@ func (r *discussionsMutationResolver) UpdateComment(ctx context.Context, args *s
 		Delete       *bool
 		Report       *string
 		ClearReports *bool
+		Resolve      *bool
 	}
 }) (*discussionThreadResolver, error) {
 	commentID, err := unmarshalDiscussionID(args.Input.CommentID)
@@ -278,6 +449,7 @@ func (r *discussionsMutationResolver) UpdateComment(ctx context.Context, args *s
 		Delete:       delete,
 		Report:       args.Input.Report,
 		ClearReports: clearReports,
+		Resolve:      args.Input.Resolve,
 	})
 	if err != nil {
 		return nil, errors.Wrap(err, "DiscussionComments.Update")
@@ -18,6 +18,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
 	"github.com/sourcegraph/sourcegraph/pkg/api"
 	"github.com/sourcegraph/sourcegraph/pkg/conf"
+	"github.com/sourcegraph/sourcegraph/pkg/errcode"
 	"github.com/sourcegraph/sourcegraph/pkg/jsonc"
 	"github.com/sourcegraph/sourcegraph/schema"
 )
@@ -269,9 +270,13 @@ func (r *discussionsMutationResolver) CreateThread(ctx context.Context, args *st
 
 func (r *discussionsMutationResolver) UpdateThread(ctx context.Context, args *struct {
 	Input *struct {
-		ThreadID graphql.ID
-		Archive  *bool
-		Delete   *bool
+		ThreadID        graphql.ID
+		Archive         *bool
+		Resolve         *bool
+		Labels          *[]string
+		AddAssignees    *[]graphql.ID
+		RemoveAssignees *[]graphql.ID
+		Delete          *bool
 	}
 }) (*discussionThreadResolver, error) {
 	// 🚨 SECURITY: Only signed in users may update a discussion thread.
@@ -296,9 +301,56 @@ func (r *discussionsMutationResolver) UpdateThread(ctx context.Context, args *st
 	if err != nil {
 		return nil, err
 	}
+	unmarshalUserIDs := func(ids *[]graphql.ID) ([]int32, error) {
+		if ids == nil {
+			return nil, nil
+		}
+		userIDs := make([]int32, len(*ids))
+		for i, id := range *ids {
+			userID, err := UnmarshalUserID(id)
+			if err != nil {
+				return nil, err
+			}
+			userIDs[i] = userID
+		}
+		return userIDs, nil
+	}
+	addAssigneeUserIDs, err := unmarshalUserIDs(args.Input.AddAssignees)
+	if err != nil {
+		return nil, err
+	}
+	removeAssigneeUserIDs, err := unmarshalUserIDs(args.Input.RemoveAssignees)
+	if err != nil {
+		return nil, err
+	}
+
+	// Determine which users are newly assigned, so that only they are
+	// notified.
+	var newAssigneeUserIDs []int32
+	if len(addAssigneeUserIDs) > 0 {
+		oldThread, err := db.DiscussionThreads.Get(ctx, threadID)
+		if err != nil {
+			return nil, err
+		}
+		oldAssignees := make(map[int32]struct{}, len(oldThread.AssigneeUserIDs))
+		for _, userID := range oldThread.AssigneeUserIDs {
+			oldAssignees[userID] = struct{}{}
+		}
+		for _, userID := range addAssigneeUserIDs {
+			if _, ok := oldAssignees[userID]; !ok {
+				oldAssignees[userID] = struct{}{}
+				newAssigneeUserIDs = append(newAssigneeUserIDs, userID)
+			}
+		}
+	}
+
 	thread, err := db.DiscussionThreads.Update(ctx, threadID, &db.DiscussionThreadsUpdateOptions{
-		Archive: args.Input.Archive,
-		Delete:  delete,
+		Archive:               args.Input.Archive,
+		Resolve:               args.Input.Resolve,
+		Labels:                args.Input.Labels,
+		AddAssigneeUserIDs:    addAssigneeUserIDs,
+		RemoveAssigneeUserIDs: removeAssigneeUserIDs,
+		Delete:                delete,
 	})
 	if err != nil {
 		return nil, errors.Wrap(err, "DiscussionThreads.Update")
@@ -307,6 +359,7 @@ func (r *discussionsMutationResolver) UpdateThread(ctx context.Context, args *st
 		// deleted
 		return nil, nil
 	}
+	discussions.NotifyAssigned(thread, currentUser.user, newAssigneeUserIDs)
 	return &discussionThreadResolver{t: thread}, nil
 }
 
@@ -433,69 +486,11 @@ func (r *discussionThreadTargetRepoResolver) Selection() *discussionThreadTarget
 func (r *discussionThreadTargetRepoResolver) RelativePath(ctx context.Context, args *struct {
 	Rev string
 }) (*string, error) {
-	if r.t.Path == nil {
-		return nil, nil
-	}
-	repo, err := repositoryByIDInt32(ctx, r.t.RepoID)
-	if err != nil {
-		return nil, err
-	}
-	if r.t.Revision == nil && r.t.Branch == nil {
-		// The thread wasn't created on a specific revision or branch, so we
-		// cannot walk the history. Instead, we must assume its location and
-		// check in the relative revision.
-		commit, err := repo.Commit(ctx, &repositoryCommitArgs{Rev: args.Rev})
-		if err != nil {
-			return nil, err
-		}
-		_, err = commit.File(ctx, &struct{ Path string }{Path: *r.t.Path})
-		if err != nil {
-			// File does not exist in this revision.
-			return nil, nil
-		}
-		return r.t.Path, nil // File exists at that path.
-	}
-
-	var rev string
-	if r.t.Revision != nil {
-		rev = *r.t.Revision
-	} else if r.t.Branch != nil {
-		rev = *r.t.Branch
-	}
-	comparison, err := repo.Comparison(ctx, &repositoryComparisonInput{
-		Base: &rev,
-		Head: &args.Rev,
-	})
-	if err != nil {
-		return nil, err
-	}
-	currentPath := *r.t.Path
-	fileDiffs, err := comparison.FileDiffs(&struct{ First *int32 }{}).Nodes(ctx)
-	if err != nil {
+	anchor, err := r.anchor(ctx, args.Rev)
+	if anchor == nil || err != nil {
 		return nil, err
 	}
-	for _, fileDiff := range fileDiffs {
-		oldPath := fileDiff.OldPath()
-		newPath := fileDiff.NewPath()
-
-		if oldPath == nil && newPath != nil {
-			// newPath was added. We don't need to do anything because this
-			// could only indicate the file we're tracking was added.
-		} else if oldPath != nil && newPath == nil {
-			// oldPath was removed
-			if currentPath == *oldPath {
-				// The file we are tracking was removed!
-				return nil, nil
-			}
-		} else if oldPath != nil && newPath != nil {
-			// oldPath was renamed to newPath
-			if currentPath == *oldPath {
-				// The file we are tracking was renamed.
-				currentPath = *newPath
-			}
-		}
-	}
-	return &currentPath, nil
+	return anchor.path, nil
 }
 
 type discussionSelectionRangeResolver struct {
@@ -507,110 +502,17 @@ func (r *discussionSelectionRangeResolver) StartCharacter() int32 { return r.sta
 func (r *discussionSelectionRangeResolver) EndLine() int32        { return r.endLine }
 func (r *discussionSelectionRangeResolver) EndCharacter() int32   { return r.endCharacter }
 
-func discussionSelectionRelativeTo(oldSel *types.DiscussionThreadTargetRepo, newContent string) *discussionSelectionRangeResolver {
-	mustFindLines := 4
-
-	search := func(searchForLines string) *discussionSelectionRangeResolver {
-		if len(strings.Split(searchForLines, "\n")) < mustFindLines {
-			// We do not have enough search lines to find a good match.
-			return nil
-		}
-		matches := strings.Count(newContent, searchForLines)
-		switch {
-		case matches > 1:
-			// The lines we are searching for produced too many matches.
-			return nil
-		case matches == 1:
-			// We found a perfect match.
-			idx := strings.Index(newContent, searchForLines)
-			startLine := int32(len(strings.Split(newContent[:idx], "\n")))
-			return &discussionSelectionRangeResolver{
-				startCharacter: *oldSel.StartCharacter,
-				endCharacter:   *oldSel.EndCharacter,
-				startLine:      startLine,
-				endLine:        startLine + int32(len(*oldSel.Lines)),
-			}
-		default:
-			return nil
-		}
-	}
-
-	// Start removing lines until we find a result (or fail to find one).
-	allLines := *oldSel.LinesBefore
-	allLines = append(allLines, *oldSel.Lines...)
-	allLines = append(allLines, *oldSel.LinesAfter...)
-	removeLines := 0
-	for {
-		if removeLines > len(allLines) {
-			return nil
-		}
-		// Try removing N lines from the top.
-		if r := search(strings.Join(allLines[removeLines:], "\n")); r != nil {
-			offset := int32(len(*oldSel.LinesBefore) - 1 - removeLines)
-			r.startLine += offset
-			r.endLine += offset
-			return r
-		}
-
-		// Try removing N lines from the bottom.
-		if r := search(strings.Join(allLines[:len(allLines)-removeLines], "\n")); r != nil {
-			offset := int32(len(*oldSel.LinesAfter) - 1 - removeLines)
-			r.startLine += offset
-			r.endLine += offset
-			return r
-		}
-		removeLines++
-	}
-}
-
 func (r *discussionThreadTargetRepoResolver) RelativeSelection(ctx context.Context, args *struct {
 	Rev string
 }) (*discussionSelectionRangeResolver, error) {
 	if !r.t.HasSelection() {
 		return nil, nil
 	}
-	path, err := r.RelativePath(ctx, args)
-	if err != nil {
-		return nil, err
-	}
-	if path == nil {
-		return nil, nil
-	}
-	repo, err := repositoryByIDInt32(ctx, r.t.RepoID)
-	if err != nil {
-		return nil, err
-	}
-	commit, err := repo.Commit(ctx, &repositoryCommitArgs{Rev: args.Rev})
-	if err != nil {
-		return nil, err
-	}
-	oldSel := &discussionSelectionRangeResolver{
-		startLine:      *r.t.StartLine,
-		startCharacter: *r.t.StartCharacter,
-		endLine:        *r.t.EndLine,
-		endCharacter:   *r.t.EndCharacter,
-	}
-	if r.t.Revision != nil && *r.t.Revision == string(commit.OID()) {
-		return oldSel, nil // nothing to do (requested relative revision is identical to the stored revision)
-	}
-	if r.t.Branch != nil {
-		branchCommit, err := repo.Commit(ctx, &repositoryCommitArgs{Rev: *r.t.Branch})
-		if err != nil {
-			return nil, err
-		}
-		if branchCommit.OID() == commit.OID() {
-			return oldSel, nil // nothing to do (requested relative revision is identical to the stored branch revision)
-		}
-	}
-	file, err := commit.File(ctx, &struct{ Path string }{Path: *path})
-	if err != nil {
+	anchor, err := r.anchor(ctx, args.Rev)
+	if anchor == nil || err != nil {
 		return nil, err
 	}
-	newContent, err := file.Content(ctx)
-	if err != nil {
-		return nil, err
-	}
-	return discussionSelectionRelativeTo(r.t, newContent), nil
+	return anchor.Selection(), nil
 }
 
 type discussionThreadTargetResolver struct {
@@ -667,6 +569,41 @@ func (d *discussionThreadResolver) ArchivedAt(ctx context.Context) *string {
 	return strptr(d.t.ArchivedAt.Format(time.RFC3339))
 }
 
+func (d *discussionThreadResolver) State() string {
+	if d.t.ResolvedAt != nil {
+		return "RESOLVED"
+	}
+	return "OPEN"
+}
+
+func (d *discussionThreadResolver) ResolvedAt(ctx context.Context) *string {
+	if d.t.ResolvedAt == nil {
+		return nil
+	}
+	return strptr(d.t.ResolvedAt.Format(time.RFC3339))
+}
+
+func (d *discussionThreadResolver) Labels() []string {
+	if d.t.Labels == nil {
+		return []string{}
+	}
+	return d.t.Labels
+}
+
+func (d *discussionThreadResolver) Assignees(ctx context.Context) ([]*UserResolver, error) {
+	assignees := make([]*UserResolver, 0, len(d.t.AssigneeUserIDs))
+	for _, userID := range d.t.AssigneeUserIDs {
+		user, err := UserByIDInt32(ctx, userID)
+		if errcode.IsNotFound(err) {
+			continue // the user was deleted after the thread was fetched
+		} else if err != nil {
+			return nil, err
+		}
+		assignees = append(assignees, user)
+	}
+	return assignees, nil
+}
+
 func (d *discussionThreadResolver) Comments(ctx context.Context, args *struct {
 	graphqlutil.ConnectionArgs
 }) *discussionCommentsConnectionResolver {
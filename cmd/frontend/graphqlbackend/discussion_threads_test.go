@@ -4,10 +4,11 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/discussions"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
 )
 
-func TestDiscussionSelectionRelativeTo(t *testing.T) {
+func TestDiscussionThreadSelectionTracking(t *testing.T) {
 	i32 := func(i int32) *int32 {
 		return &i
 	}
@@ -70,7 +71,7 @@ func TestDiscussionSelectionRelativeTo(t *testing.T) {
 				LinesAfter:  &[]string{"4", "5", "6"},
 			},
 			newContent: "0\n1\n2\n3\n",
-			want:       &discussionSelectionRangeResolver{startLine: 0, startCharacter: 0, endLine: 1, endCharacter: 1},
+			want:       &discussionSelectionRangeResolver{startLine: 3, startCharacter: 0, endLine: 4, endCharacter: 1},
 		},
 		{
 			name: "no_match",
@@ -86,7 +87,10 @@ func TestDiscussionSelectionRelativeTo(t *testing.T) {
 	}
 	for _, tst := range tests {
 		t.Run(tst.name, func(t *testing.T) {
-			got := discussionSelectionRelativeTo(tst.oldSelection, tst.newContent)
+			var got *discussionSelectionRangeResolver
+			if tracked := discussions.TrackSelection(discussions.ThreadSelection(tst.oldSelection), nil, tst.newContent); !tracked.Outdated() {
+				got = toDiscussionSelectionRange(tst.oldSelection, tracked.LineRange)
+			}
 			if !reflect.DeepEqual(got, tst.want) {
 				t.Logf("got  %+v\n", got)
 				t.Fatalf("want %+v\n", tst.want)
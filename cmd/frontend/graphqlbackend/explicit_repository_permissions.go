@@ -0,0 +1,201 @@
+package graphqlbackend
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+)
+
+type explicitRepositoryPermissionResolver struct {
+	perm *types.ExplicitRepoPermission
+}
+
+const explicitRepositoryPermissionIDKind = "ExplicitRepositoryPermission"
+
+func explicitRepositoryPermissionByID(ctx context.Context, id graphql.ID) (*explicitRepositoryPermissionResolver, error) {
+	// 🚨 SECURITY: Only site admins may read explicit repository permissions.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	permID, err := unmarshalExplicitRepositoryPermissionID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	perm, err := db.ExplicitRepoPermissions.GetByID(ctx, permID)
+	if err != nil {
+		return nil, err
+	}
+	return &explicitRepositoryPermissionResolver{perm: perm}, nil
+}
+
+func marshalExplicitRepositoryPermissionID(id int64) graphql.ID {
+	return relay.MarshalID(explicitRepositoryPermissionIDKind, id)
+}
+
+func unmarshalExplicitRepositoryPermissionID(id graphql.ID) (permID int64, err error) {
+	if kind := relay.UnmarshalKind(id); kind != explicitRepositoryPermissionIDKind {
+		err = fmt.Errorf("expected graphql ID to have kind %q; got %q", explicitRepositoryPermissionIDKind, kind)
+		return
+	}
+	err = relay.UnmarshalSpec(id, &permID)
+	return
+}
+
+func (r *explicitRepositoryPermissionResolver) ID() graphql.ID {
+	return marshalExplicitRepositoryPermissionID(r.perm.ID)
+}
+
+func (r *explicitRepositoryPermissionResolver) Repository(ctx context.Context) (*repositoryResolver, error) {
+	if r.perm.RepoID == 0 {
+		return nil, nil
+	}
+	return repositoryByIDInt32(ctx, r.perm.RepoID)
+}
+
+func (r *explicitRepositoryPermissionResolver) RepositoryPattern() *string {
+	if r.perm.RepoPattern == "" {
+		return nil
+	}
+	return &r.perm.RepoPattern
+}
+
+func (r *explicitRepositoryPermissionResolver) User(ctx context.Context) (*UserResolver, error) {
+	if r.perm.UserID == 0 {
+		return nil, nil
+	}
+	return UserByIDInt32(ctx, r.perm.UserID)
+}
+
+func (r *explicitRepositoryPermissionResolver) Organization(ctx context.Context) (*OrgResolver, error) {
+	if r.perm.OrgID == 0 {
+		return nil, nil
+	}
+	return OrgByIDInt32(ctx, r.perm.OrgID)
+}
+
+func (r *explicitRepositoryPermissionResolver) CreatedAt() string {
+	return r.perm.CreatedAt.Format(time.RFC3339)
+}
+
+func (*schemaResolver) AddExplicitRepositoryPermission(ctx context.Context, args *struct {
+	Input *struct {
+		Repository        *graphql.ID
+		RepositoryPattern *string
+		User              *graphql.ID
+		Organization      *graphql.ID
+	}
+}) (*explicitRepositoryPermissionResolver, error) {
+	// 🚨 SECURITY: Only site admins may grant explicit repository permissions.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	var perm types.ExplicitRepoPermission
+	var err error
+	if args.Input.Repository != nil {
+		if perm.RepoID, err = unmarshalRepositoryID(*args.Input.Repository); err != nil {
+			return nil, err
+		}
+	}
+	if args.Input.RepositoryPattern != nil {
+		perm.RepoPattern = *args.Input.RepositoryPattern
+	}
+	if args.Input.User != nil {
+		if perm.UserID, err = UnmarshalUserID(*args.Input.User); err != nil {
+			return nil, err
+		}
+	}
+	if args.Input.Organization != nil {
+		if perm.OrgID, err = UnmarshalOrgID(*args.Input.Organization); err != nil {
+			return nil, err
+		}
+	}
+
+	// The permissions of all users are synced when the authz providers are updated with the new
+	// permission (within a few seconds).
+	if err := db.ExplicitRepoPermissions.Create(ctx, &perm); err != nil {
+		return nil, err
+	}
+	return &explicitRepositoryPermissionResolver{perm: &perm}, nil
+}
+
+func (*schemaResolver) DeleteExplicitRepositoryPermission(ctx context.Context, args *struct {
+	ExplicitRepositoryPermission graphql.ID
+}) (*EmptyResponse, error) {
+	// 🚨 SECURITY: Only site admins may revoke explicit repository permissions.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	id, err := unmarshalExplicitRepositoryPermissionID(args.ExplicitRepositoryPermission)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.ExplicitRepoPermissions.Delete(ctx, id); err != nil {
+		return nil, err
+	}
+	return &EmptyResponse{}, nil
+}
+
+func (r *schemaResolver) ExplicitRepositoryPermissions(ctx context.Context, args *struct {
+	graphqlutil.ConnectionArgs
+}) (*explicitRepositoryPermissionConnectionResolver, error) {
+	// 🚨 SECURITY: Only site admins may read explicit repository permissions.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+		return nil, err
+	}
+	var opt db.ExplicitRepoPermissionsListOptions
+	args.ConnectionArgs.Set(&opt.LimitOffset)
+	return &explicitRepositoryPermissionConnectionResolver{opt: opt}, nil
+}
+
+type explicitRepositoryPermissionConnectionResolver struct {
+	opt db.ExplicitRepoPermissionsListOptions
+
+	// cache results because they are used by multiple fields
+	once  sync.Once
+	perms []*types.ExplicitRepoPermission
+	err   error
+}
+
+func (r *explicitRepositoryPermissionConnectionResolver) compute(ctx context.Context) ([]*types.ExplicitRepoPermission, error) {
+	r.once.Do(func() {
+		r.perms, r.err = db.ExplicitRepoPermissions.List(ctx, r.opt)
+	})
+	return r.perms, r.err
+}
+
+func (r *explicitRepositoryPermissionConnectionResolver) Nodes(ctx context.Context) ([]*explicitRepositoryPermissionResolver, error) {
+	perms, err := r.compute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resolvers := make([]*explicitRepositoryPermissionResolver, 0, len(perms))
+	for _, perm := range perms {
+		resolvers = append(resolvers, &explicitRepositoryPermissionResolver{perm: perm})
+	}
+	return resolvers, nil
+}
+
+func (r *explicitRepositoryPermissionConnectionResolver) TotalCount(ctx context.Context) (int32, error) {
+	count, err := db.ExplicitRepoPermissions.Count(ctx, r.opt)
+	return int32(count), err
+}
+
+func (r *explicitRepositoryPermissionConnectionResolver) PageInfo(ctx context.Context) (*graphqlutil.PageInfo, error) {
+	perms, err := r.compute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return graphqlutil.HasNextPage(r.opt.LimitOffset != nil && len(perms) >= r.opt.Limit), nil
+}
@@ -0,0 +1,49 @@
+package graphqlbackend
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/graph-gophers/graphql-go/gqltesting"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+)
+
+func TestAddExplicitRepositoryPermission(t *testing.T) {
+	resetMocks()
+	db.Mocks.Users.GetByCurrentAuthUser = func(context.Context) (*types.User, error) {
+		return &types.User{SiteAdmin: true}, nil
+	}
+	db.Mocks.ExplicitRepoPermissions.Create = func(ctx context.Context, p *types.ExplicitRepoPermission) error {
+		if want := (types.ExplicitRepoPermission{RepoPattern: "^gitolite\\.example\\.com/", OrgID: 1}); *p != want {
+			t.Errorf("got permission %+v, want %+v", *p, want)
+		}
+		p.ID = 2
+		p.CreatedAt = time.Date(2018, 6, 7, 0, 0, 0, 0, time.UTC)
+		return nil
+	}
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: GraphQLSchema,
+			Query: `
+				mutation {
+					addExplicitRepositoryPermission(input: {repositoryPattern: "^gitolite\\.example\\.com/", organization: "T3JnOjE="}) {
+						id
+						repositoryPattern
+						createdAt
+					}
+				}
+			`,
+			ExpectedResult: `
+				{
+					"addExplicitRepositoryPermission": {
+						"id": "RXhwbGljaXRSZXBvc2l0b3J5UGVybWlzc2lvbjoy",
+						"repositoryPattern": "^gitolite\\.example\\.com/",
+						"createdAt": "2018-06-07T00:00:00Z"
+					}
+				}
+			`,
+		},
+	})
+}
@@ -110,10 +110,11 @@ func (r *gitTreeEntryResolver) Highlight(ctx context.Context, args *struct {
 		html   template.HTML
 		result = &highlightedFileResolver{}
 	)
-	html, result.aborted, err = highlight.Code(ctx, content, r.path, args.DisableTimeout, args.IsLightTheme)
+	html, result.aborted, err = highlight.CachedCode(ctx, content, r.path, args.DisableTimeout, args.IsLightTheme)
 	if err != nil {
 		return nil, err
 	}
+	backend.RecordFileView(r.commit.repo.repo, r.path)
 	result.html = string(html)
 	return result, nil
 }
@@ -99,6 +99,11 @@ func (r *nodeResolver) ToExternalService() (*externalServiceResolver, bool) {
 	return n, ok
 }
 
+func (r *nodeResolver) ToExplicitRepositoryPermission() (*explicitRepositoryPermissionResolver, bool) {
+	n, ok := r.node.(*explicitRepositoryPermissionResolver)
+	return n, ok
+}
+
 func (r *nodeResolver) ToGitRef() (*gitRefResolver, bool) {
 	n, ok := r.node.(*gitRefResolver)
 	return n, ok
@@ -171,6 +176,8 @@ func nodeByID(ctx context.Context, id graphql.ID) (node, error) {
 		return externalAccountByID(ctx, id)
 	case externalServiceIDKind:
 		return externalServiceByID(ctx, id)
+	case explicitRepositoryPermissionIDKind:
+		return explicitRepositoryPermissionByID(ctx, id)
 	case "GitRef":
 		return gitRefByID(ctx, id)
 	case "Repository":
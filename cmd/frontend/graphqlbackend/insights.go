@@ -0,0 +1,450 @@
+package graphqlbackend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/search/query"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/actor"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/env"
+	"github.com/sourcegraph/sourcegraph/pkg/jsonc"
+	"github.com/sourcegraph/sourcegraph/pkg/vcs/git"
+	"github.com/sourcegraph/sourcegraph/schema"
+	log15 "gopkg.in/inconshreveable/log15.v2"
+)
+
+var (
+	insightsBackfillInterval       = env.Get("INSIGHTS_BACKFILL_INTERVAL", "1h", "how often to compute missing data points of code insight series (0 disables computing them)")
+	insightsBackfillMaxSearches, _ = strconv.Atoi(env.Get("INSIGHTS_BACKFILL_MAX_SEARCHES", "1000", "maximum number of searches to compute code insight data points each time the backfill runs"))
+)
+
+const (
+	defaultInsightInterval = "month"
+	defaultInsightSamples  = 24
+	maxInsightSamples      = 120
+
+	// maxInsightMatches bounds the number of matches searched for each data
+	// point of a repository. Counts of files with more matches are capped.
+	maxInsightMatches = 10000
+)
+
+// StartInsightsBackfiller periodically computes the data points of all code
+// insight series (in the "insights" property of user, organization and global
+// settings) that have not been computed yet. Each data point is computed by
+// searching the commit that each repository's default branch was at as of the
+// point in time.
+func StartInsightsBackfiller() {
+	interval, err := time.ParseDuration(insightsBackfillInterval)
+	if err != nil {
+		log15.Error("Invalid INSIGHTS_BACKFILL_INTERVAL, not computing code insights.", "error", err)
+		return
+	}
+	if interval <= 0 {
+		return
+	}
+	for {
+		if err := backfillInsights(context.Background()); err != nil {
+			log15.Error("Failed to compute code insights.", "error", err)
+		}
+		time.Sleep(interval)
+	}
+}
+
+// errInsightsBackfillBudget is returned when a run of the backfill has done
+// as many searches as it may (see insightsBackfillMaxSearches). The remaining
+// data points are computed by the next runs.
+var errInsightsBackfillBudget = errors.New("code insights backfill search budget exhausted")
+
+// insightsBackfill is a single run of the backfill.
+type insightsBackfill struct {
+	searchesLeft int // the number of searches this run may still do
+
+	listedRepos bool
+	repos       []*types.Repo // all enabled repositories (listed at most once per run)
+}
+
+func backfillInsights(ctx context.Context) error {
+	// The counts are computed for all repositories, regardless of who defined
+	// the series.
+	ctx = actor.WithActor(ctx, &actor.Actor{Internal: true})
+
+	allSettings, err := db.Settings.ListAll(ctx)
+	if err != nil {
+		return err
+	}
+	b := &insightsBackfill{searchesLeft: insightsBackfillMaxSearches}
+	for _, s := range allSettings {
+		var settings schema.Settings
+		if err := jsonc.Unmarshal(s.Contents, &settings); err != nil {
+			log15.Debug("Ignoring code insights in invalid settings.", "subject", s.Subject, "error", err)
+			continue
+		}
+		for _, series := range settings.Insights {
+			if err := b.series(ctx, series); err == errInsightsBackfillBudget {
+				log15.Debug("Code insights backfill search budget exhausted, continuing with the next run.")
+				return nil
+			} else if err != nil {
+				log15.Warn("Failed to compute code insight series.", "query", series.Query, "error", err)
+			}
+		}
+	}
+	return nil
+}
+
+func (b *insightsBackfill) series(ctx context.Context, series *schema.InsightSeries) error {
+	if err := checkInsightQuery(series.Query); err != nil {
+		return err
+	}
+	times, err := insightSampleTimes(time.Now(), series.Interval, series.Samples)
+	if err != nil {
+		return err
+	}
+
+	if len(series.Repositories) > 0 {
+		for _, name := range series.Repositories {
+			repo, err := db.Repos.GetByName(ctx, api.RepoName(name))
+			if err != nil {
+				log15.Debug("Ignoring repository in code insight series.", "repo", name, "error", err)
+				continue
+			}
+			if err := b.seriesRepo(ctx, series.Query, repo, times); err == errInsightsBackfillBudget {
+				return err
+			} else if err != nil {
+				log15.Debug("Failed to compute code insight series for repository.", "repo", repo.Name, "error", err)
+			}
+		}
+		return nil
+	}
+
+	if !b.listedRepos {
+		if b.repos, err = listInsightRepos(ctx); err != nil {
+			return err
+		}
+		b.listedRepos = true
+	}
+	for _, repo := range b.repos {
+		if err := b.seriesRepo(ctx, series.Query, repo, times); err == errInsightsBackfillBudget {
+			return err
+		} else if err != nil {
+			log15.Debug("Failed to compute code insight series for repository.", "repo", repo.Name, "error", err)
+		}
+	}
+	return nil
+}
+
+// seriesRepo computes and records the data points of the query in the
+// repository at each of the times that weren't recorded yet.
+func (b *insightsBackfill) seriesRepo(ctx context.Context, q string, repo *types.Repo, times []time.Time) error {
+	recorded, err := db.InsightSeriesPoints.RecordedTimes(ctx, q, repo.ID)
+	if err != nil {
+		return err
+	}
+	var missing []time.Time
+	for _, t := range times {
+		if !recorded[t] {
+			missing = append(missing, t)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+	defer cancel()
+
+	cachedRepo, err := backend.CachedGitRepo(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	// Consecutive data points are often at the same commit (for repositories
+	// that changed less often than the interval), so only search again when
+	// the commit differs.
+	var (
+		prevCommitID api.CommitID
+		prevValue    int
+	)
+	for _, t := range missing {
+		commits, err := git.Commits(ctx, *cachedRepo, git.CommitsOptions{
+			Range:  "HEAD",
+			N:      1,
+			Before: t.Format(time.RFC3339),
+		})
+		if err != nil {
+			return err
+		}
+
+		var (
+			commitID api.CommitID
+			value    int
+		)
+		if len(commits) > 0 {
+			commitID = commits[0].ID
+			if commitID == prevCommitID {
+				value = prevValue
+			} else {
+				if b.searchesLeft <= 0 {
+					return errInsightsBackfillBudget
+				}
+				b.searchesLeft--
+				value, err = insightSearchCount(ctx, q, repo.Name, commitID)
+				if err != nil {
+					return err
+				}
+			}
+			prevCommitID, prevValue = commitID, value
+		}
+		if err := db.InsightSeriesPoints.Record(ctx, q, repo.ID, t, commitID, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insightSearchCount returns the number of files matching the query in the
+// repository at the given commit.
+func insightSearchCount(ctx context.Context, q string, repo api.RepoName, commitID api.CommitID) (int, error) {
+	search, err := (&schemaResolver{}).Search(&struct{ Query string }{
+		Query: fmt.Sprintf("%s repo:^%s$@%s count:%d", q, regexp.QuoteMeta(string(repo)), commitID, maxInsightMatches),
+	})
+	if err != nil {
+		return 0, err
+	}
+	results, err := search.Results(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if len(results.cloning) > 0 || len(results.timedout) > 0 {
+		// Try again on the next backfill.
+		return 0, errors.New("repository is cloning or search timed out")
+	}
+	var n int
+	for _, r := range results.results {
+		if r.fileMatch != nil {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// checkInsightQuery returns an error if the query can't be used for a code
+// insight series: it must be a search on file contents, and it must not
+// specify revisions or a result count (because those are determined by the
+// series).
+func checkInsightQuery(q string) error {
+	parsed, err := query.ParseAndCheck(q)
+	if err != nil {
+		return err
+	}
+	if typ, _ := parsed.StringValues(query.FieldType); len(typ) > 0 {
+		return errors.New("code insight queries must not contain type: filters")
+	}
+	if count, _ := parsed.StringValues(query.FieldCount); len(count) > 0 {
+		return errors.New("code insight queries must not contain count: filters")
+	}
+	repos, _ := parsed.RegexpPatterns(query.FieldRepo)
+	for _, repo := range repos {
+		if strings.Contains(repo, "@") {
+			return errors.New("code insight queries must not contain repo: filters with revisions")
+		}
+	}
+	return nil
+}
+
+// insightSampleTimes returns the times of the data points of a code insight
+// series with the given interval ("day", "week" or "month", defaulting to
+// "month") and number of samples (defaulting to 24), in ascending order. The
+// times are at the start of each interval in UTC (weeks start on Monday),
+// ending with the start of the interval that contains now. Because the times
+// don't depend on when the series was defined, data points are shared among
+// series with the same query.
+func insightSampleTimes(now time.Time, interval string, samples int) ([]time.Time, error) {
+	if interval == "" {
+		interval = defaultInsightInterval
+	}
+	if samples == 0 {
+		samples = defaultInsightSamples
+	}
+	if samples < 0 || samples > maxInsightSamples {
+		return nil, fmt.Errorf("code insight samples must be between 1 and %d", maxInsightSamples)
+	}
+
+	now = now.UTC()
+	var (
+		last         time.Time
+		months, days int
+	)
+	switch interval {
+	case "day":
+		last = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		days = 1
+	case "week":
+		weekday := (int(now.Weekday()) + 6) % 7 // days since Monday
+		last = time.Date(now.Year(), now.Month(), now.Day()-weekday, 0, 0, 0, 0, time.UTC)
+		days = 7
+	case "month":
+		last = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		months = 1
+	default:
+		return nil, errors.New("invalid code insight interval")
+	}
+	times := make([]time.Time, samples)
+	for i := range times {
+		k := samples - 1 - i
+		times[i] = last.AddDate(0, -k*months, -k*days)
+	}
+	return times, nil
+}
+
+func (r *schemaResolver) Insights(ctx context.Context) ([]*insightSeriesResolver, error) {
+	config, err := r.ViewerSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	configSubjects, err := config.Subjects(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		insights []*insightSeriesResolver
+		repos    = &insightRepoIDs{}
+	)
+	for _, subject := range configSubjects {
+		var settings schema.Settings
+		if err := subject.readSettings(ctx, &settings); err != nil {
+			return nil, err
+		}
+		for _, series := range settings.Insights {
+			insights = append(insights, &insightSeriesResolver{subject: subject, series: series, repos: repos})
+		}
+	}
+	return insights, nil
+}
+
+type insightSeriesResolver struct {
+	subject *settingsSubject
+	series  *schema.InsightSeries
+
+	// repos is shared by the series resolved in the same request, if set.
+	repos *insightRepoIDs
+}
+
+func (r *insightSeriesResolver) Subject() *settingsSubject { return r.subject }
+
+func (r *insightSeriesResolver) Key() string { return r.series.Key }
+
+func (r *insightSeriesResolver) Title() string { return r.series.Title }
+
+func (r *insightSeriesResolver) Query() string { return r.series.Query }
+
+func (r *insightSeriesResolver) Points(ctx context.Context) ([]*insightSeriesPointResolver, error) {
+	times, err := insightSampleTimes(time.Now(), r.series.Interval, r.series.Samples)
+	if err != nil {
+		return nil, err
+	}
+
+	repos := r.repos
+	if repos == nil {
+		repos = &insightRepoIDs{}
+	}
+	repoIDs, err := repos.series(ctx, r.series)
+	if err != nil {
+		return nil, err
+	}
+	points, err := db.InsightSeriesPoints.Series(ctx, db.InsightSeriesOptions{Query: r.series.Query, RepoIDs: repoIDs, Times: times})
+	if err != nil {
+		return nil, err
+	}
+	resolvers := make([]*insightSeriesPointResolver, len(points))
+	for i, p := range points {
+		// A point is complete when the counts of all repositories are included.
+		resolvers[i] = &insightSeriesPointResolver{point: p, complete: p.Repositories >= len(repoIDs)}
+	}
+	return resolvers, nil
+}
+
+// insightRepoIDs computes the IDs of the repositories whose counts are summed
+// for the current user. The IDs of all enabled repositories are listed at most
+// once, so that resolving many series in a request only lists them once.
+type insightRepoIDs struct {
+	once   sync.Once
+	all    []api.RepoID
+	allErr error
+}
+
+// series returns the IDs of the series's repositories (or all enabled
+// repositories if it has none) that the user may read.
+func (r *insightRepoIDs) series(ctx context.Context, series *schema.InsightSeries) ([]api.RepoID, error) {
+	// 🚨 SECURITY: The points were recorded for all repositories (by an internal
+	// actor), so only sum those of repositories that the user may read. The repos
+	// store enforces repository permissions.
+	repoIDs := []api.RepoID{}
+	if len(series.Repositories) > 0 {
+		for _, name := range series.Repositories {
+			repo, err := db.Repos.GetByName(ctx, api.RepoName(name))
+			if err != nil {
+				continue
+			}
+			repoIDs = append(repoIDs, repo.ID)
+		}
+		return repoIDs, nil
+	}
+
+	r.once.Do(func() {
+		repos, err := listInsightRepos(ctx)
+		if err != nil {
+			r.allErr = err
+			return
+		}
+		r.all = repoIDs
+		for _, repo := range repos {
+			r.all = append(r.all, repo.ID)
+		}
+	})
+	return r.all, r.allErr
+}
+
+// listInsightRepos returns all enabled repositories (that the actor may read).
+// They are listed in pages to bound the size of each query.
+func listInsightRepos(ctx context.Context) ([]*types.Repo, error) {
+	const pageSize = 500
+	opt := db.ReposListOptions{Enabled: true}
+	total, err := db.Repos.Count(ctx, opt)
+	if err != nil {
+		return nil, err
+	}
+	var repos []*types.Repo
+	// The repos store filters each page by repository permissions, so a page
+	// may be shorter than pageSize before the end. Page until the total instead.
+	for offset := 0; offset < total; offset += pageSize {
+		opt.LimitOffset = &db.LimitOffset{Limit: pageSize, Offset: offset}
+		page, err := db.Repos.List(ctx, opt)
+		if err != nil {
+			return nil, err
+		}
+		repos = append(repos, page...)
+	}
+	return repos, nil
+}
+
+type insightSeriesPointResolver struct {
+	point    *types.InsightSeriesPoint
+	complete bool
+}
+
+func (r *insightSeriesPointResolver) Time() string { return r.point.Time.Format(time.RFC3339) }
+
+func (r *insightSeriesPointResolver) Value() int32 { return int32(r.point.Value) }
+
+func (r *insightSeriesPointResolver) Complete() bool { return r.complete }
@@ -0,0 +1,137 @@
+package graphqlbackend
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/actor"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestInsightSampleTimes(t *testing.T) {
+	now := time.Date(2018, 3, 28, 12, 0, 0, 0, time.UTC) // a Wednesday
+	tests := map[string][]time.Time{
+		"day": {
+			time.Date(2018, 3, 26, 0, 0, 0, 0, time.UTC),
+			time.Date(2018, 3, 27, 0, 0, 0, 0, time.UTC),
+			time.Date(2018, 3, 28, 0, 0, 0, 0, time.UTC),
+		},
+		"week": {
+			time.Date(2018, 3, 12, 0, 0, 0, 0, time.UTC),
+			time.Date(2018, 3, 19, 0, 0, 0, 0, time.UTC),
+			time.Date(2018, 3, 26, 0, 0, 0, 0, time.UTC),
+		},
+		"month": {
+			time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2018, 2, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2018, 3, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	for interval, want := range tests {
+		got, err := insightSampleTimes(now, interval, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %v, want %v", interval, got, want)
+		}
+	}
+
+	// A Sunday is in the week that started on the previous Monday.
+	sunday := time.Date(2018, 4, 1, 23, 0, 0, 0, time.UTC)
+	if got, err := insightSampleTimes(sunday, "week", 1); err != nil {
+		t.Fatal(err)
+	} else if want := []time.Time{time.Date(2018, 3, 26, 0, 0, 0, 0, time.UTC)}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	if got, err := insightSampleTimes(now, "", 0); err != nil {
+		t.Fatal(err)
+	} else if len(got) != 24 || !got[23].Equal(time.Date(2018, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("got %v, want 24 monthly samples", got)
+	}
+
+	if _, err := insightSampleTimes(now, "year", 3); err == nil {
+		t.Error("got nil error for invalid interval")
+	}
+	if _, err := insightSampleTimes(now, "day", maxInsightSamples+1); err == nil {
+		t.Error("got nil error for too many samples")
+	}
+}
+
+func TestCheckInsightQuery(t *testing.T) {
+	tests := map[string]bool{
+		"lang:go log15.":                    true,
+		"repo:github.com/a/b file:\\.go$ x": true,
+		"type:diff x":                       false,
+		"repo:github.com/a/b@develop x":     false,
+		"count:1000 x":                      false,
+	}
+	for q, valid := range tests {
+		if err := checkInsightQuery(q); (err == nil) != valid {
+			t.Errorf("%q: got error %v, want valid %v", q, err, valid)
+		}
+	}
+}
+
+func TestInsightSeriesPoints_authz(t *testing.T) {
+	resetMocks()
+	defer resetMocks()
+
+	// User 1 may read repositories 1 and 2, user 2 only repository 2.
+	readable := map[int32][]api.RepoID{1: {1, 2}, 2: {2}}
+	db.Mocks.Repos.Count = func(ctx context.Context, opt db.ReposListOptions) (int, error) {
+		return 2, nil // not filtered by permissions
+	}
+	var listCalls int
+	db.Mocks.Repos.List = func(ctx context.Context, opt db.ReposListOptions) ([]*types.Repo, error) {
+		listCalls++
+		if opt.LimitOffset == nil || opt.LimitOffset.Offset != 0 {
+			t.Errorf("got list options %+v, want the first page", opt)
+		}
+		var repos []*types.Repo
+		for _, id := range readable[actor.FromContext(ctx).UID] {
+			repos = append(repos, &types.Repo{ID: id})
+		}
+		return repos, nil
+	}
+	counts := map[api.RepoID]int{1: 10, 2: 3}
+	db.Mocks.InsightSeriesPoints.Series = func(ctx context.Context, opt db.InsightSeriesOptions) ([]*types.InsightSeriesPoint, error) {
+		points := make([]*types.InsightSeriesPoint, len(opt.Times))
+		for i, t := range opt.Times {
+			points[i] = &types.InsightSeriesPoint{Time: t}
+			for _, id := range opt.RepoIDs {
+				points[i].Value += counts[id]
+				points[i].Repositories++
+			}
+		}
+		return points, nil
+	}
+
+	for userID, want := range map[int32]int32{1: 13, 2: 3} {
+		ctx := actor.WithActor(context.Background(), &actor.Actor{UID: userID})
+
+		// The series resolved in the same request share the listed
+		// repositories.
+		listCalls = 0
+		repos := &insightRepoIDs{}
+		for _, q := range []string{"x", "y"} {
+			r := &insightSeriesResolver{series: &schema.InsightSeries{Query: q, Samples: 1}, repos: repos}
+			points, err := r.Points(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(points) != 1 || points[0].Value() != want || !points[0].Complete() {
+				t.Errorf("user %d: got points %+v, want one complete point with value %d", userID, points, want)
+			}
+		}
+		if listCalls != 1 {
+			t.Errorf("user %d: got %d Repos.List calls, want 1", userID, listCalls)
+		}
+	}
+}
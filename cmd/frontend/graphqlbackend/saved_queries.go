@@ -4,14 +4,18 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	graphql "github.com/graph-gophers/graphql-go"
 	"github.com/graph-gophers/graphql-go/relay"
 	"github.com/sourcegraph/jsonx"
-	"github.com/sourcegraph/sourcegraph/cmd/query-runner/queryrunnerapi"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/pkg/api"
 	"github.com/sourcegraph/sourcegraph/pkg/conf"
+	"github.com/sourcegraph/sourcegraph/pkg/errcode"
 	"github.com/sourcegraph/sourcegraph/pkg/jsonc"
+	"github.com/sourcegraph/sourcegraph/pkg/queryrunnerapi"
 	"github.com/sourcegraph/sourcegraph/pkg/randstring"
 )
 
@@ -22,6 +26,7 @@ type savedQueryResolver struct {
 	description                         string
 	query                               string
 	showOnHomepage, notify, notifySlack bool
+	notifyFrequency                     string
 }
 
 func savedQueryByID(ctx context.Context, id graphql.ID) (*savedQueryResolver, error) {
@@ -52,6 +57,10 @@ func savedQueryByID(ctx context.Context, id graphql.ID) (*savedQueryResolver, er
 }
 
 func (r savedQueryResolver) ID() graphql.ID {
+	return marshalSavedQueryID(r.spec())
+}
+
+func (r savedQueryResolver) spec() api.SavedQueryIDSpec {
 	var subject api.SettingsSubject
 	switch {
 	case r.subject.user != nil:
@@ -61,10 +70,10 @@ func (r savedQueryResolver) ID() graphql.ID {
 	case r.subject.site != nil:
 		subject.Site = true
 	}
-	return marshalSavedQueryID(api.SavedQueryIDSpec{
+	return api.SavedQueryIDSpec{
 		Subject: subject,
 		Key:     r.key,
-	})
+	}
 }
 
 func marshalSavedQueryID(spec api.SavedQueryIDSpec) graphql.ID {
@@ -88,6 +97,79 @@ func (r savedQueryResolver) NotifySlack() bool {
 	return r.notifySlack
 }
 
+func (r savedQueryResolver) ViewerNotificationFrequency(ctx context.Context) (string, error) {
+	frequency := r.notifyFrequency
+	pref, err := viewerSavedQueryNotificationPreference(ctx, r.spec())
+	if err != nil {
+		return "", err
+	}
+	if pref != nil && pref.Frequency != "" {
+		frequency = pref.Frequency
+	}
+	if frequency == "" {
+		frequency = api.SavedQueryNotificationFrequencyImmediate
+	}
+	return strings.ToUpper(frequency), nil
+}
+
+func (r savedQueryResolver) ViewerUnsubscribed(ctx context.Context) (bool, error) {
+	pref, err := viewerSavedQueryNotificationPreference(ctx, r.spec())
+	if err != nil {
+		return false, err
+	}
+	return pref != nil && pref.Unsubscribed, nil
+}
+
+// viewerSavedQueryNotificationPreference returns the current user's
+// notification preference for the saved query, or nil if there is no current
+// user or they haven't set one.
+func viewerSavedQueryNotificationPreference(ctx context.Context, spec api.SavedQueryIDSpec) (*api.SavedQueryNotificationPreference, error) {
+	user, err := db.Users.GetByCurrentAuthUser(ctx)
+	if errcode.IsNotFound(err) || err == db.ErrNoCurrentUser {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return db.SavedQueryNotificationPreferences.Get(ctx, user.ID, spec)
+}
+
+func (r savedQueryResolver) WebhookDeliveries(ctx context.Context, args *struct {
+	First int32
+}) ([]*savedQueryWebhookDeliveryResolver, error) {
+	if args.First < 0 || args.First > 100 {
+		return nil, errors.New("first must be between 0 and 100")
+	}
+	deliveries, err := db.SavedQueryWebhookDeliveries.List(ctx, r.spec(), int(args.First))
+	if err != nil {
+		return nil, err
+	}
+	resolvers := make([]*savedQueryWebhookDeliveryResolver, len(deliveries))
+	for i, d := range deliveries {
+		resolvers[i] = &savedQueryWebhookDeliveryResolver{delivery: d}
+	}
+	return resolvers, nil
+}
+
+func (r savedQueryResolver) History(ctx context.Context, args *struct {
+	First int32
+}) ([]*savedQueryRunResolver, error) {
+	if args.First < 0 || args.First > 1000 {
+		return nil, errors.New("first must be between 0 and 1000")
+	}
+	runs, err := db.SavedQueryRuns.List(ctx, r.query, int(args.First))
+	if err != nil {
+		return nil, err
+	}
+	// List returns the newest runs first, but a time series is more useful
+	// oldest first.
+	resolvers := make([]*savedQueryRunResolver, len(runs))
+	for i, run := range runs {
+		resolvers[len(runs)-1-i] = &savedQueryRunResolver{run: run}
+	}
+	return resolvers, nil
+}
+
 func (r savedQueryResolver) Subject() *settingsSubject { return r.subject }
 
 func (r savedQueryResolver) Key() *string {
@@ -105,14 +187,15 @@ func (r savedQueryResolver) Query() string { return r.query }
 
 func toSavedQueryResolver(index int, subject *settingsSubject, entry api.ConfigSavedQuery) *savedQueryResolver {
 	return &savedQueryResolver{
-		subject:        subject,
-		key:            entry.Key,
-		index:          index,
-		description:    entry.Description,
-		query:          entry.Query,
-		showOnHomepage: entry.ShowOnHomepage,
-		notify:         entry.Notify,
-		notifySlack:    entry.NotifySlack,
+		subject:         subject,
+		key:             entry.Key,
+		index:           index,
+		description:     entry.Description,
+		query:           entry.Query,
+		showOnHomepage:  entry.ShowOnHomepage,
+		notify:          entry.Notify,
+		notifySlack:     entry.NotifySlack,
+		notifyFrequency: entry.NotifyFrequency,
 	}
 }
 
@@ -332,3 +415,173 @@ func (r *schemaResolver) SendSavedSearchTestNotification(ctx context.Context, ar
 	go queryrunnerapi.Client.TestNotification(context.Background(), spec)
 	return &EmptyResponse{}, nil
 }
+
+func (r *schemaResolver) UpdateSavedQueryNotificationPreference(ctx context.Context, args *struct {
+	SavedQuery   graphql.ID
+	Frequency    *string
+	Unsubscribed *bool
+}) (*EmptyResponse, error) {
+	err := updateViewerSavedQueryNotificationPreference(ctx, args.SavedQuery, func(_ *savedQueryResolver, pref *api.SavedQueryNotificationPreference) error {
+		if args.Frequency != nil {
+			pref.Frequency = strings.ToLower(*args.Frequency)
+		}
+		if args.Unsubscribed != nil {
+			pref.Unsubscribed = *args.Unsubscribed
+			if pref.Unsubscribed {
+				pref.Subscribed = false
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &EmptyResponse{}, nil
+}
+
+// updateViewerSavedQueryNotificationPreference calls update to modify the
+// current user's notification preference for the saved query, and saves the
+// modified preference.
+func updateViewerSavedQueryNotificationPreference(ctx context.Context, id graphql.ID, update func(*savedQueryResolver, *api.SavedQueryNotificationPreference) error) error {
+	// 🚨 SECURITY: Look it up to ensure the actor has access to it.
+	savedQuery, err := savedQueryByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	spec := savedQuery.spec()
+
+	// 🚨 SECURITY: Only the current user's own preference is changed.
+	user, err := db.Users.GetByCurrentAuthUser(ctx)
+	if err != nil {
+		return err
+	}
+	pref, err := db.SavedQueryNotificationPreferences.Get(ctx, user.ID, spec)
+	if err != nil {
+		return err
+	}
+	if pref == nil {
+		pref = &api.SavedQueryNotificationPreference{UserID: user.ID}
+	}
+	if err := update(savedQuery, pref); err != nil {
+		return err
+	}
+	return db.SavedQueryNotificationPreferences.Set(ctx, spec, pref)
+}
+
+type savedQueryWebhookDeliveryResolver struct {
+	delivery *api.SavedQueryWebhookDelivery
+}
+
+func (r *savedQueryWebhookDeliveryResolver) DeliveryID() string { return r.delivery.DeliveryID }
+
+func (r *savedQueryWebhookDeliveryResolver) Event() string { return r.delivery.Event }
+
+func (r *savedQueryWebhookDeliveryResolver) URL() string { return r.delivery.URL }
+
+func (r *savedQueryWebhookDeliveryResolver) Attempt() int32 { return int32(r.delivery.Attempt) }
+
+func (r *savedQueryWebhookDeliveryResolver) StatusCode() *int32 {
+	if r.delivery.StatusCode == 0 {
+		return nil
+	}
+	statusCode := int32(r.delivery.StatusCode)
+	return &statusCode
+}
+
+func (r *savedQueryWebhookDeliveryResolver) Error() *string {
+	if r.delivery.Error == "" {
+		return nil
+	}
+	return &r.delivery.Error
+}
+
+func (r *savedQueryWebhookDeliveryResolver) DurationMilliseconds() int32 {
+	return int32(r.delivery.Duration / time.Millisecond)
+}
+
+func (r *savedQueryWebhookDeliveryResolver) CreatedAt() string {
+	return r.delivery.CreatedAt.Format(time.RFC3339)
+}
+
+type savedQueryRunResolver struct {
+	run *api.SavedQueryRun
+}
+
+func (r *savedQueryRunResolver) ExecutedAt() string { return r.run.ExecutedAt.Format(time.RFC3339) }
+
+func (r *savedQueryRunResolver) DurationMilliseconds() int32 {
+	return int32(r.run.ExecDuration / time.Millisecond)
+}
+
+func (r *savedQueryRunResolver) ResultCount() *int32 {
+	if r.run.ResultCount == nil {
+		return nil
+	}
+	n := int32(*r.run.ResultCount)
+	return &n
+}
+
+func (r *savedQueryRunResolver) NewResultCount() int32 { return int32(r.run.NewResultCount) }
+
+func (r *savedQueryRunResolver) LimitHit() bool { return r.run.LimitHit }
+
+func (r *savedQueryRunResolver) Error() *string {
+	if r.run.Error == "" {
+		return nil
+	}
+	return &r.run.Error
+}
+
+func (r *savedQueryRunResolver) NewResults() []*savedQueryRunResultResolver {
+	resolvers := make([]*savedQueryRunResultResolver, len(r.run.NewResults))
+	for i, result := range r.run.NewResults {
+		resolvers[i] = &savedQueryRunResultResolver{result: result}
+	}
+	return resolvers
+}
+
+type savedQueryRunResultResolver struct {
+	result *api.SavedQueryRunResult
+}
+
+func (r *savedQueryRunResultResolver) Repository() string { return string(r.result.Repo) }
+
+func (r *savedQueryRunResultResolver) Commit() *string {
+	if r.result.Commit == "" {
+		return nil
+	}
+	commit := string(r.result.Commit)
+	return &commit
+}
+
+func (r *savedQueryRunResultResolver) Path() *string {
+	if r.result.Path == "" {
+		return nil
+	}
+	return &r.result.Path
+}
+
+func (r *savedQueryRunResultResolver) Line() *int32 {
+	if r.result.Line == 0 {
+		return nil
+	}
+	line := int32(r.result.Line)
+	return &line
+}
+
+func (r *savedQueryRunResultResolver) Preview() *string {
+	if r.result.Preview == "" {
+		return nil
+	}
+	return &r.result.Preview
+}
+
+func (r *savedQueryRunResultResolver) URL() string {
+	if r.result.Commit != "" {
+		return fmt.Sprintf("/%s/-/commit/%s", r.result.Repo, r.result.Commit)
+	}
+	if r.result.Line > 0 {
+		return fmt.Sprintf("/%s/-/blob/%s#L%d", r.result.Repo, r.result.Path, r.result.Line)
+	}
+	return fmt.Sprintf("/%s/-/blob/%s", r.result.Repo, r.result.Path)
+}
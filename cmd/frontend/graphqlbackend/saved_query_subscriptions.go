@@ -0,0 +1,114 @@
+package graphqlbackend
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+)
+
+// Subscription states of a member of the organization that owns a saved query.
+const (
+	savedQuerySubscriptionDefault    = "DEFAULT"    // notified if the saved query notifies all members
+	savedQuerySubscriptionSubscribed = "SUBSCRIBED" // always notified
+	savedQuerySubscriptionMuted      = "MUTED"      // never notified
+)
+
+func savedQuerySubscriptionState(pref *api.SavedQueryNotificationPreference) string {
+	switch {
+	case pref == nil:
+		return savedQuerySubscriptionDefault
+	case pref.Unsubscribed:
+		return savedQuerySubscriptionMuted
+	case pref.Subscribed:
+		return savedQuerySubscriptionSubscribed
+	default:
+		return savedQuerySubscriptionDefault
+	}
+}
+
+func (r savedQueryResolver) ViewerSubscription(ctx context.Context) (string, error) {
+	pref, err := viewerSavedQueryNotificationPreference(ctx, r.spec())
+	if err != nil {
+		return "", err
+	}
+	return savedQuerySubscriptionState(pref), nil
+}
+
+func (r savedQueryResolver) Subscriptions(ctx context.Context) ([]*savedQuerySubscriptionResolver, error) {
+	prefs, err := db.SavedQueryNotificationPreferences.List(ctx, r.spec())
+	if err != nil {
+		return nil, err
+	}
+	resolvers := make([]*savedQuerySubscriptionResolver, len(prefs))
+	for i, pref := range prefs {
+		resolvers[i] = &savedQuerySubscriptionResolver{pref: pref}
+	}
+	return resolvers, nil
+}
+
+type savedQuerySubscriptionResolver struct {
+	pref *api.SavedQueryNotificationPreference
+}
+
+func (r *savedQuerySubscriptionResolver) User(ctx context.Context) (*UserResolver, error) {
+	return UserByIDInt32(ctx, r.pref.UserID)
+}
+
+func (r *savedQuerySubscriptionResolver) State() string { return savedQuerySubscriptionState(r.pref) }
+
+func (r *savedQuerySubscriptionResolver) Email() *bool { return r.pref.Email }
+
+func (r *savedQuerySubscriptionResolver) Slack() *bool { return r.pref.Slack }
+
+func (r *savedQuerySubscriptionResolver) Frequency() *string {
+	if r.pref.Frequency == "" {
+		return nil
+	}
+	frequency := strings.ToUpper(r.pref.Frequency)
+	return &frequency
+}
+
+func (r *schemaResolver) SetSavedQuerySubscription(ctx context.Context, args *struct {
+	SavedQuery graphql.ID
+	State      string
+}) (*EmptyResponse, error) {
+	err := updateViewerSavedQueryNotificationPreference(ctx, args.SavedQuery, func(savedQuery *savedQueryResolver, pref *api.SavedQueryNotificationPreference) error {
+		switch args.State {
+		case savedQuerySubscriptionDefault:
+			pref.Subscribed, pref.Unsubscribed = false, false
+		case savedQuerySubscriptionSubscribed:
+			if savedQuery.subject.org == nil {
+				return errors.New("only organization saved queries can be subscribed to")
+			}
+			pref.Subscribed, pref.Unsubscribed = true, false
+		case savedQuerySubscriptionMuted:
+			pref.Subscribed, pref.Unsubscribed = false, true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &EmptyResponse{}, nil
+}
+
+func (r *schemaResolver) SetSavedQueryNotificationChannels(ctx context.Context, args *struct {
+	SavedQuery   graphql.ID
+	Email, Slack *bool
+}) (*EmptyResponse, error) {
+	err := updateViewerSavedQueryNotificationPreference(ctx, args.SavedQuery, func(savedQuery *savedQueryResolver, pref *api.SavedQueryNotificationPreference) error {
+		if savedQuery.subject.org == nil {
+			return errors.New("notification channels can only be set for organization saved queries")
+		}
+		pref.Email, pref.Slack = args.Email, args.Slack
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &EmptyResponse{}, nil
+}
@@ -47,6 +47,11 @@ type Mutation {
     updateExternalService(input: UpdateExternalServiceInput!): ExternalService!
     # Delete an external service. Only site admins may perform this mutation.
     deleteExternalService(externalService: ID!): EmptyResponse!
+    # Grants a user or organization read access to a repository (or to all repositories whose names match a
+    # pattern). Only site admins may perform this mutation.
+    addExplicitRepositoryPermission(input: AddExplicitRepositoryPermissionInput!): ExplicitRepositoryPermission!
+    # Revokes an explicit repository permission. Only site admins may perform this mutation.
+    deleteExplicitRepositoryPermission(explicitRepositoryPermission: ID!): EmptyResponse!
     # Enables or disables a repository. A disabled repository is only
     # accessible to site admins and never appears in search results.
     #
@@ -254,6 +259,40 @@ type Mutation {
         # ID of the saved search.
         id: ID!
     ): EmptyResponse
+    # Updates the current user's notification preference for the saved search. Omitted arguments keep their
+    # current values.
+    #
+    # Only users with access to the saved search may perform this action.
+    updateSavedQueryNotificationPreference(
+        # ID of the saved search.
+        savedQuery: ID!
+        # How often to receive notifications for the saved search.
+        frequency: SavedQueryNotificationFrequency
+        # Whether to stop receiving notifications for the saved search.
+        unsubscribed: Boolean
+    ): EmptyResponse!
+    # Sets the current user's subscription to the saved search. Only saved searches in organization settings can
+    # be subscribed to, but any saved search can be muted.
+    #
+    # Only users with access to the saved search may perform this action.
+    setSavedQuerySubscription(
+        # ID of the saved search.
+        savedQuery: ID!
+        # The subscription state.
+        state: SavedQuerySubscriptionState!
+    ): EmptyResponse!
+    # Sets how the current user receives notifications for a saved search in organization settings. Null values
+    # use the default (email only).
+    #
+    # Only users with access to the saved search may perform this action.
+    setSavedQueryNotificationChannels(
+        # ID of the saved search.
+        savedQuery: ID!
+        # Whether to receive notifications by email.
+        email: Boolean
+        # Whether to receive notifications on Slack (using the Slack webhook in the user's settings).
+        slack: Boolean
+    ): EmptyResponse!
     # All mutations that update settings (global, organization, and user settings) are under this field.
     #
     # Only the settings subject whose settings are being mutated (and site admins) may perform this mutation.
@@ -284,6 +323,11 @@ type Mutation {
     #
     # Only site admins may perform this mutation.
     setUserIsSiteAdmin(userID: ID!, siteAdmin: Boolean!): EmptyResponse
+    # Schedules the user's repository permissions to be synced from the code hosts as soon as possible (instead
+    # of when they become stale).
+    #
+    # Only site admins may perform this mutation.
+    scheduleUserPermissionsSync(user: ID!): EmptyResponse!
     # Reloads the site by restarting the server. This is not supported for all deployment
     # types. This may cause downtime.
     #
@@ -314,6 +358,19 @@ input AddExternalServiceInput {
     config: String!
 }
 
+# A new explicit repository permission. Exactly one of repository and repositoryPattern, and exactly one of
+# user and organization, must be set.
+input AddExplicitRepositoryPermissionInput {
+    # The repository that the permission applies to.
+    repository: ID
+    # A regular expression that matches the names of the repositories that the permission applies to.
+    repositoryPattern: String
+    # The user who is granted the permission.
+    user: ID
+    # The organization whose members are granted the permission.
+    organization: ID
+}
+
 # Fields to update for an existing external service.
 input UpdateExternalServiceInput {
     # The id of the external service to update.
@@ -431,6 +488,19 @@ input DiscussionThreadUpdateInput {
     # When non-null, indicates that the thread should be archived.
     Archive: Boolean
 
+    # When non-null, indicates that the thread should be resolved (true) or
+    # reopened (false).
+    Resolve: Boolean
+
+    # When non-null, replaces the thread's labels.
+    Labels: [String!]
+
+    # The IDs of users to assign to the thread. They are notified.
+    AddAssignees: [ID!]
+
+    # The IDs of users to unassign from the thread.
+    RemoveAssignees: [ID!]
+
     # When non-null, indicates that the thread should be deleted. Only admins
     # can perform this action.
     Delete: Boolean
@@ -455,6 +525,10 @@ input DiscussionCommentUpdateInput {
     #
     # An error will be returned if the comment's canClearReports field is false.
     clearReports: Boolean
+
+    # When non-null, indicates that the comment should be marked as resolved
+    # (true) or unresolved (false).
+    resolve: Boolean
 }
 
 # Mutations for discussions.
@@ -472,6 +546,17 @@ type DiscussionsMutation {
 
     # Updates an existing comment. Returns the updated thread.
     updateComment(input: DiscussionCommentUpdateInput!): DiscussionThread!
+
+    # Adds a reply to an existing comment. Returns the updated thread.
+    replyToComment(commentID: ID!, contents: String!): DiscussionThread!
+
+    # Adds the viewer's reaction with the emoji to a comment. Returns the updated comment.
+    #
+    # The emoji must be one of those in the Query.discussionReactionEmojis list.
+    addReactionToComment(commentID: ID!, emoji: String!): DiscussionComment!
+
+    # Removes the viewer's reaction with the emoji from a comment. Returns the updated comment.
+    removeReactionFromComment(commentID: ID!, emoji: String!): DiscussionComment!
 }
 
 # Describes options for rendering Markdown.
@@ -651,6 +736,11 @@ type Query {
         # Returns the first n repositories from the list.
         first: Int
     ): ExternalServiceConnection!
+    # Lists all explicit repository permissions. Only site admins may perform this query.
+    explicitRepositoryPermissions(
+        # Returns the first n permissions from the list.
+        first: Int
+    ): ExplicitRepositoryPermissionConnection!
     # List all repositories.
     repositories(
         # Returns the first n repositories from the list.
@@ -748,6 +838,8 @@ type Query {
         # When present, lists only the comments created by this author.
         authorUserID: ID
     ): DiscussionCommentConnection!
+    # The emoji that users may react to discussion comments with.
+    discussionReactionEmojis: [String!]!
     # Renders Markdown to HTML. The returned HTML is already sanitized and
     # escaped and thus is always safe to render.
     renderMarkdown(markdown: String!, options: MarkdownOptions): String!
@@ -770,6 +862,9 @@ type Query {
     ): Search
     # All saved queries configured for the current user, merged from all configurations.
     savedQueries: [SavedQuery!]!
+    # All code insight series configured for the current user, merged from all configurations (in the
+    # "insights" settings property).
+    insights: [InsightSeries!]!
     # All repository groups for the current user, merged from all configurations.
     repoGroups: [RepoGroup!]!
     # The current site.
@@ -962,6 +1057,124 @@ type SavedQuery {
     notify: Boolean!
     # Whether or not to notify on Slack.
     notifySlack: Boolean!
+    # How often the viewer receives notifications for this saved query: their own preference if they set one, or
+    # else the saved query's default (the "notifyFrequency" property of the saved query in settings).
+    viewerNotificationFrequency: SavedQueryNotificationFrequency!
+    # Whether the viewer unsubscribed from notifications for this saved query.
+    viewerUnsubscribed: Boolean!
+    # The viewer's subscription to this saved query.
+    viewerSubscription: SavedQuerySubscriptionState!
+    # The notification preferences and subscriptions of users who set them for this saved query.
+    subscriptions: [SavedQuerySubscription!]!
+    # The most recent attempts to deliver notifications to the saved query's
+    # webhook (configured in the "notifyWebhook" property of the saved query in
+    # settings), newest first. Attempts older than 30 days are not returned.
+    webhookDeliveries(
+        # The maximum number of attempts to return.
+        first: Int = 20
+    ): [SavedQueryWebhookDelivery!]!
+    # The most recent runs of the saved query, oldest first (suitable for
+    # charting the number of results over time). Runs older than 90 days are not
+    # returned.
+    history(
+        # The maximum number of runs to return.
+        first: Int = 100
+    ): [SavedQueryRun!]!
+}
+
+# How often a recipient receives notifications for a saved query.
+enum SavedQueryNotificationFrequency {
+    # A notification is sent for each run of the saved query that finds new results.
+    IMMEDIATE
+    # New results are batched into a digest that is sent at most hourly.
+    HOURLY
+    # New results are batched into a digest that is sent at most daily.
+    DAILY
+}
+
+# A user's subscription state for a saved query.
+enum SavedQuerySubscriptionState {
+    # For saved queries in organization settings, the user is notified if the saved query notifies all organization
+    # members. For other saved queries, the user is notified if they are a recipient.
+    DEFAULT
+    # The user is notified, even if the saved query doesn't notify all organization members.
+    SUBSCRIBED
+    # The user is never notified.
+    MUTED
+}
+
+# A user's notification preferences and subscription for a saved query.
+type SavedQuerySubscription {
+    # The user.
+    user: User!
+    # The user's subscription state.
+    state: SavedQuerySubscriptionState!
+    # Whether the user is notified by email, or null for the default.
+    email: Boolean
+    # Whether the user is notified on Slack, or null for the default.
+    slack: Boolean
+    # How often the user is notified, or null for the saved query's default.
+    frequency: SavedQueryNotificationFrequency
+}
+
+# A run of a saved query.
+type SavedQueryRun {
+    # When the saved query was run.
+    executedAt: String!
+    # The number of milliseconds the search took.
+    durationMilliseconds: Int!
+    # The total number of matching lines, or null if it is not known. It is only
+    # known for runs of searches on file contents on all repositories: diff and
+    # commit searches only search for results since the previous run, and runs
+    # on repository updates only search the updated repository.
+    resultCount: Int
+    # The number of results that the previous run did not find.
+    newResultCount: Int!
+    # Whether the search stopped before finding all results.
+    limitHit: Boolean!
+    # Why the run failed, or null if it succeeded.
+    error: String
+    # A sample of the new results found by the run.
+    newResults: [SavedQueryRunResult!]!
+}
+
+# A new result found by a run of a saved query.
+type SavedQueryRunResult {
+    # The name of the repository.
+    repository: String!
+    # The commit ID, for a commit or diff search result.
+    commit: String
+    # The file path, for a search result on file contents.
+    path: String
+    # The 1-indexed line number, for a search result on file contents that
+    # matched a line (not just the path).
+    line: Int
+    # The commit message or matching line.
+    preview: String
+    # The URL to the result.
+    url: String!
+}
+
+# An attempt to deliver a notification to a saved query's webhook.
+type SavedQueryWebhookDelivery {
+    # The ID of the notification. All attempts to deliver the same
+    # notification have the same delivery ID, which is sent in the
+    # X-Sourcegraph-Delivery header.
+    deliveryID: String!
+    # The type of notification ("results" or "test").
+    event: String!
+    # The webhook URL.
+    url: String!
+    # The 1-indexed number of this attempt.
+    attempt: Int!
+    # The HTTP status code of the response, or null if no response was received.
+    statusCode: Int
+    # Why the attempt failed, or null if it succeeded.
+    error: String
+    # The number of milliseconds the attempt took.
+    durationMilliseconds: Int!
+    # When the attempt was made.
+    createdAt: String!
 }
 
 # A search query description.
@@ -1077,6 +1290,37 @@ type ExternalService implements Node {
     updatedAt: String!
 }
 
+# A list of explicit repository permissions.
+type ExplicitRepositoryPermissionConnection {
+    # A list of explicit repository permissions.
+    nodes: [ExplicitRepositoryPermission!]!
+
+    # The total number of explicit repository permissions in the connection.
+    totalCount: Int!
+
+    # Pagination information.
+    pageInfo: PageInfo!
+}
+
+# A permission, granted by a site admin, to read a repository (or all repositories whose names match a
+# pattern). It restricts access to the repositories it applies to, unless their permissions are already
+# determined by a code host or LDAP auth provider.
+type ExplicitRepositoryPermission implements Node {
+    # The permission's unique ID.
+    id: ID!
+    # The repository that the permission applies to, or null if it applies to repositoryPattern.
+    repository: Repository
+    # The regular expression that matches the names of the repositories that the permission applies to, or
+    # null if it applies to a single repository.
+    repositoryPattern: String
+    # The user who is granted the permission, or null if it is granted to an organization.
+    user: User
+    # The organization whose members are granted the permission, or null if it is granted to a user.
+    organization: Org
+    # When the permission was created.
+    createdAt: String!
+}
+
 # A list of repositories.
 type RepositoryConnection {
     # A list of repositories.
@@ -1532,6 +1776,14 @@ enum SymbolKind {
     TYPEPARAMETER
 }
 
+# A node in the outline of a file's symbols.
+type SymbolOutlineNode {
+    # The symbol.
+    symbol: Symbol!
+    # The symbols contained in this symbol, in the order they appear in the file.
+    children: [SymbolOutlineNode!]!
+}
+
 # A list of symbols.
 type SymbolConnection {
     # A list of symbols.
@@ -1842,6 +2094,8 @@ type GitTree implements TreeEntry {
         # Recurse into sub-trees.
         recursive: Boolean = false
     ): [File!]!
+    # The owners of this tree, according to the repository's CODEOWNERS file (empty if there is none).
+    codeOwners: [CodeOwner!]!
     # A list of entries in this tree.
     entries(
         # Returns the first n files in the tree.
@@ -1925,6 +2179,15 @@ type File {
     repository: Repository!
 }
 
+# An owner of a file or directory, according to its repository's CODEOWNERS file.
+type CodeOwner {
+    # The owner as written in the CODEOWNERS file (e.g., "@alice", "@org/team" or "alice@example.com").
+    name: String!
+    # The Sourcegraph user that the owner refers to, or null if it could not be resolved. A handle (such as
+    # "@alice") is resolved through the users' external accounts on the repository's code host.
+    user: User
+}
+
 # A Git blob in a repository.
 type GitBlob implements TreeEntry & File2 {
     # The full path (relative to the repository root) of this blob.
@@ -1954,6 +2217,8 @@ type GitBlob implements TreeEntry & File2 {
     externalURLs: [ExternalLink!]!
     # Blame the blob.
     blame(startLine: Int!, endLine: Int!): [Hunk!]!
+    # The owners of this blob, according to the repository's CODEOWNERS file (empty if there is none).
+    codeOwners: [CodeOwner!]!
     # Highlight the blob contents.
     highlight(disableTimeout: Boolean!, isLightTheme: Boolean!): HighlightedFile!
     # Submodule metadata if this tree points to a submodule
@@ -1965,6 +2230,9 @@ type GitBlob implements TreeEntry & File2 {
         # Return symbols matching the query.
         query: String
     ): SymbolConnection!
+    # The outline of this blob: the symbols defined in it, nested inside the symbols that contain them (e.g.,
+    # methods inside their class), in the order they appear in the blob.
+    outline: [SymbolOutlineNode!]!
     # Always false, since a blob is a file, not directory.
     isSingleChild(
         # Returns the first n files in the tree.
@@ -2131,6 +2399,11 @@ type User implements Node & SettingsSubject {
     #
     # Only the user and site admins can access this field.
     surveyResponses: [SurveyResponse!]!
+    # The status of the background syncing of the user's repository permissions from the code hosts. The
+    # permissions as of the last successful sync are the ones that are enforced.
+    #
+    # Only site admins can access this field.
+    permissionsSyncStatus: UserPermissionsSyncStatus!
     # The URL to view this user's customer information (for Sourcegraph.com site admins).
     #
     # Only Sourcegraph.com site admins may query this field.
@@ -2459,15 +2732,42 @@ type DiscussionThreadTargetRepo {
     # Where the selection would be relative to the given Git revision specifier
     # (branch/commit/etc).
     #
-    # The implementation relies on a hueristic which is generally good enough,
-    # but under certain circumstances may not be as accurate as e.g. determining
-    # this placement by walking through the Git history.
+    # The selection is mapped through the diff of the file (following renames)
+    # since the revision the thread was created on, and falls back to fuzzily
+    # matching the lines of the selection and the lines around it.
     #
-    # If determining the relative placement is not possible (file was renamed
-    # or removed, the selection no longer exists in the file, or the hueristic
-    # failed) null is returned and it should be assumed the selection does not
-    # exist in this revision.
+    # If determining the relative placement is not possible (file was removed,
+    # or the selection was changed too much to be found confidently) null is
+    # returned and it should be assumed the selection does not exist in this
+    # revision. See relativeAnchor for details.
     relativeSelection(rev: String!): DiscussionSelectionRange
+
+    # Where the path and selection would be relative to the given Git revision
+    # specifier (branch/commit/etc), and whether the thread is outdated in that
+    # revision.
+    #
+    # null is returned if the path field is null or the revision does not exist.
+    relativeAnchor(rev: String!): DiscussionThreadAnchor
+}
+
+# Where a discussion thread's target is in a revision other than the one it was
+# created on.
+type DiscussionThreadAnchor {
+    # The path of the file or directory in the revision, or null if it was
+    # removed.
+    path: String
+
+    # The selection in the revision, or null if the thread has no selection or
+    # it is outdated.
+    selection: DiscussionSelectionRange
+
+    # Whether the code the thread was about was removed, or changed too much to
+    # be found confidently in the revision.
+    outdated: Boolean!
+
+    # How confident the placement of the thread in the revision is, from 0 (not
+    # at all) to 1 (certain, e.g. because the selected lines are unchanged).
+    confidence: Float!
 }
 
 # The target of a discussion thread. Today, the only possible target is a
@@ -2508,6 +2808,18 @@ type DiscussionThread {
     # The date when the discussion thread was archived (or null if it has not).
     archivedAt: String
 
+    # Whether the thread is open or resolved.
+    state: DiscussionThreadState!
+
+    # The date when the discussion thread was resolved (or null if it is open).
+    resolvedAt: String
+
+    # The labels of the thread, in alphabetical order.
+    labels: [String!]!
+
+    # The users who are assigned to the thread.
+    assignees: [User!]!
+
     # The comments in the discussion thread.
     comments(
         # Returns the first n comments from the list.
@@ -2515,6 +2827,14 @@ type DiscussionThread {
     ): DiscussionCommentConnection!
 }
 
+# The state of a discussion thread.
+enum DiscussionThreadState {
+    # The thread is open (e.g. the code it is about needs to be fixed).
+    OPEN
+    # The thread is resolved.
+    RESOLVED
+}
+
 # A comment made within a discussion thread.
 type DiscussionComment {
     # The discussion comment ID (globally unique).
@@ -2569,6 +2889,34 @@ type DiscussionComment {
     #
     # This is always false when discussions.abuseProtection in the site config is set to false.
     canClearReports: Boolean!
+
+    # The comment that this comment is a reply to, or null if it is not a reply.
+    parentComment: DiscussionComment
+
+    # The replies to this comment.
+    replies(
+        # Returns the first n replies from the list.
+        first: Int
+    ): DiscussionCommentConnection!
+
+    # The date when the comment was marked as resolved (or null if it has not).
+    resolvedAt: String
+
+    # The reactions to this comment, grouped by emoji (in the order that each
+    # emoji was first used).
+    reactions: [DiscussionReaction!]!
+}
+
+# The reactions with an emoji to a discussion comment.
+type DiscussionReaction {
+    # The emoji.
+    emoji: String!
+
+    # The users who reacted with the emoji.
+    users: [User!]!
+
+    # Whether the viewer reacted with the emoji.
+    viewerHasReacted: Boolean!
 }
 
 # A list of discussion threads.
@@ -2718,6 +3066,68 @@ type Site implements SettingsSubject {
     #
     # Only site admins may retrieve this information.
     managementConsoleState: ManagementConsoleState!
+    # The language breakdown of the latest commit on repositories' default branches over time (e.g., to
+    # track a migration from one language to another). The breakdown is recorded periodically in the
+    # background, so recent changes may not be reflected yet.
+    #
+    # Only site admins may retrieve this information.
+    languageStatistics(
+        # If set, only this repository is included. Otherwise, the breakdowns of all repositories are summed.
+        repository: ID
+        # The time between data points.
+        interval: LanguageStatisticsInterval = DAY
+        # The number of data points, ending with the current time.
+        points: Int = 30
+    ): [LanguageStatisticsPoint!]!
+}
+
+# The time between data points of language statistics.
+enum LanguageStatisticsInterval {
+    DAY
+    WEEK
+    MONTH
+}
+
+# The language breakdown of one or more repositories as of a point in time.
+type LanguageStatisticsPoint {
+    # The point in time (in RFC 3339 format).
+    date: String!
+    # The languages used, sorted by total size in descending order.
+    languages: [LanguageStatistic!]!
+}
+
+# The total size of the code written in a language.
+type LanguageStatistic {
+    # The name of the language (e.g., "Go" or "TypeScript").
+    name: String!
+    # The total size in bytes. This is a Float because it may exceed the range of Int.
+    totalBytes: Float!
+}
+
+# A code insight series: the number of files matching a search query at regularly sampled points in time in the
+# history of each repository's default branch. The data points are computed in the background, so the data
+# points of a new series are incomplete at first.
+type InsightSeries {
+    # The subject whose settings this series was defined in.
+    subject: SettingsSubject!
+    # The unique key of this series (unique only among all other series of the same subject).
+    key: String!
+    # The title.
+    title: String!
+    # The search query whose matching files are counted.
+    query: String!
+    # The data points, in ascending order of time.
+    points: [InsightSeriesPoint!]!
+}
+
+# The number of files matching a code insight series's query as of a point in time.
+type InsightSeriesPoint {
+    # The point in time (in RFC 3339 format).
+    time: String!
+    # The number of matching files, summed over the repositories whose count has been computed.
+    value: Int!
+    # Whether the count has been computed for all of the series's repositories. If false, value is a partial sum.
+    complete: Boolean!
 }
 
 # Information about this site's management console.
@@ -2862,6 +3272,24 @@ type UserUsageStatistics {
     lastActiveCodeHostIntegrationTime: String
 }
 
+# The status of the background syncing of a user's repository permissions from the code hosts.
+type UserPermissionsSyncStatus {
+    # The last time the permissions were successfully synced, or null if they never were.
+    syncedAt: String
+    # The last time syncing the permissions was attempted.
+    lastAttemptedAt: String
+    # The last time a sync was requested (upon sign-in or with the scheduleUserPermissionsSync mutation).
+    requestedAt: String
+    # The error of the last sync attempt, if it failed.
+    error: String
+    # Whether the permissions were not successfully synced within the sync interval (the
+    # permissions.syncInterval site configuration property).
+    stale: Boolean!
+    # The number of repositories that the user can read as of the last successful sync, among those whose
+    # permissions come from a code host.
+    readableRepositoriesCount: Int!
+}
+
 # A user event.
 enum UserEvent {
     PAGEVIEW
@@ -0,0 +1,351 @@
+package graphqlbackend
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/neelance/parallel"
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/goroutine"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/search/query"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/gitserver"
+	"github.com/sourcegraph/sourcegraph/pkg/rcache"
+	"github.com/sourcegraph/sourcegraph/pkg/vcs/git"
+	log15 "gopkg.in/inconshreveable/log15.v2"
+)
+
+// blameFilter filters text search line matches by the commit that last
+// changed the matched line (as reported by git blame). It implements the
+// blame.author:, blame.before: and blame.after: query fields.
+type blameFilter struct {
+	authors    []*regexp.Regexp // if set, the line's author must match at least one
+	notAuthors []*regexp.Regexp // the line's author must match none
+	before     time.Time        // if nonzero, the line must have been authored before this time
+	after      time.Time        // if nonzero, the line must have been authored after this time
+}
+
+// newBlameFilter returns the blame filter specified by the query, or nil if
+// the query has no blame fields.
+func newBlameFilter(ctx context.Context, q *query.Query, now time.Time) (*blameFilter, error) {
+	authors, notAuthors := q.RegexpPatterns(query.FieldBlameAuthor)
+	befores, _ := q.StringValues(query.FieldBlameBefore)
+	afters, _ := q.StringValues(query.FieldBlameAfter)
+	if len(authors) == 0 && len(notAuthors) == 0 && len(befores) == 0 && len(afters) == 0 {
+		return nil, nil
+	}
+
+	var f blameFilter
+	var err error
+	if f.authors, err = compileBlameAuthors(ctx, authors); err != nil {
+		return nil, err
+	}
+	if f.notAuthors, err = compileBlameAuthors(ctx, notAuthors); err != nil {
+		return nil, err
+	}
+	for _, s := range befores {
+		t, err := parseBlameDate(s, now)
+		if err != nil {
+			return nil, errors.WithMessage(err, query.FieldBlameBefore)
+		}
+		if f.before.IsZero() || t.Before(f.before) {
+			f.before = t
+		}
+	}
+	for _, s := range afters {
+		t, err := parseBlameDate(s, now)
+		if err != nil {
+			return nil, errors.WithMessage(err, query.FieldBlameAfter)
+		}
+		if t.After(f.after) {
+			f.after = t
+		}
+	}
+	return &f, nil
+}
+
+// compileBlameAuthors compiles the blame.author: patterns, expanding
+// "@username" to the user's verified email addresses (like author: in commit
+// searches). Patterns are matched case-insensitively.
+func compileBlameAuthors(ctx context.Context, patterns []string) ([]*regexp.Regexp, error) {
+	patterns, err := expandUsernamesToEmails(ctx, patterns)
+	if err != nil {
+		return nil, errors.WithMessage(err, fmt.Sprintf("expanding usernames in field %s", query.FieldBlameAuthor))
+	}
+	res := make([]*regexp.Regexp, len(patterns))
+	for i, p := range patterns {
+		re, err := regexp.Compile("(?i)" + p)
+		if err != nil {
+			return nil, err
+		}
+		res[i] = re
+	}
+	return res, nil
+}
+
+// matchAuthor reports whether a line last changed by author matches the
+// filter. Like git log --author, author patterns are matched against the
+// whole author string of the form "Full Name <user@example.com>".
+func (f *blameFilter) matchAuthor(author git.Signature) bool {
+	s := fmt.Sprintf("%s <%s>", author.Name, author.Email)
+	for _, re := range f.notAuthors {
+		if re.MatchString(s) {
+			return false
+		}
+	}
+	if len(f.authors) > 0 {
+		var ok bool
+		for _, re := range f.authors {
+			if re.MatchString(s) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	if !f.before.IsZero() && !author.Date.Before(f.before) {
+		return false
+	}
+	if !f.after.IsZero() && !author.Date.After(f.after) {
+		return false
+	}
+	return true
+}
+
+// filterLineMatches returns the line matches whose line was last changed by a
+// commit that matches the filter.
+func (f *blameFilter) filterLineMatches(ctx context.Context, fm *fileMatchResolver) ([]*lineMatch, error) {
+	if len(fm.JLineMatches) == 0 {
+		return nil, nil
+	}
+
+	// Blame all matched lines with a single git blame.
+	startLine, endLine := -1, -1
+	for _, lm := range fm.JLineMatches {
+		line := int(lm.JLineNumber) + 1 // line matches are 0-indexed, git blame is 1-indexed
+		if startLine == -1 || line < startLine {
+			startLine = line
+		}
+		if line > endLine {
+			endLine = line
+		}
+	}
+	hunks, err := blameLines(ctx, fm.repo, fm.commitID, fm.JPath, startLine, endLine)
+	if err != nil {
+		return nil, err
+	}
+
+	var lineMatches []*lineMatch
+	for _, lm := range fm.JLineMatches {
+		line := int(lm.JLineNumber) + 1
+		for _, h := range hunks {
+			if h.StartLine <= line && line < h.EndLine {
+				if f.matchAuthor(h.Author) {
+					lineMatches = append(lineMatches, lm)
+				}
+				break
+			}
+		}
+	}
+	return lineMatches, nil
+}
+
+const (
+	// blameFilterFileMatchLimitFactor is how many times more file matches
+	// than requested are fetched from the text search when a blame filter is
+	// present, so that enough remain after filtering.
+	blameFilterFileMatchLimitFactor = 10
+
+	// maxBlameFilterFileMatchLimit bounds the number of file matches fetched
+	// (and blamed) for a search with a blame filter.
+	maxBlameFilterFileMatchLimit = 1000
+)
+
+// blameFilterFileMatchLimit returns the file match limit to use for the text
+// search of a query with a blame filter that requests limit results.
+func blameFilterFileMatchLimit(limit int32) int32 {
+	raised := limit * blameFilterFileMatchLimitFactor
+	if raised > maxBlameFilterFileMatchLimit {
+		raised = maxBlameFilterFileMatchLimit
+	}
+	if raised < limit {
+		return limit
+	}
+	return raised
+}
+
+// limitBlameFilteredFileMatches returns at most maxResults of the file matches
+// that remain after filtering the searched file matches (the results of a
+// text search limited to searchLimit file matches) by blame. It updates
+// common's result count, which the text search computed before filtering, and
+// sets its limitHit if matches that pass the filter may have been lost to
+// either limit.
+func limitBlameFilteredFileMatches(filtered []*fileMatchResolver, searched, searchLimit, maxResults int, common *searchResultsCommon) []*fileMatchResolver {
+	if len(filtered) > maxResults {
+		filtered = filtered[:maxResults]
+		common.limitHit = true
+	}
+	if len(filtered) < searched && searched >= searchLimit {
+		// The filter dropped matches, and the text search stopped at its
+		// limit, so there may be more matches that pass the filter.
+		common.limitHit = true
+	}
+	common.resultCount = 0
+	for _, fm := range filtered {
+		common.resultCount += (&searchResultResolver{fileMatch: fm}).resultCount()
+	}
+	return filtered
+}
+
+// filterFileMatchesByBlame removes the line matches that do not match the blame
+// filter, and the file matches that have no remaining line matches (including
+// file matches on only the path). File matches that could not be blamed are
+// removed.
+func filterFileMatchesByBlame(ctx context.Context, f *blameFilter, fileMatches []*fileMatchResolver) (_ []*fileMatchResolver, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "filterFileMatchesByBlame")
+	span.SetTag("fileMatches", len(fileMatches))
+	defer func() {
+		if err != nil {
+			ext.Error.Set(span, true)
+			span.SetTag("err", err.Error())
+		}
+		span.Finish()
+	}()
+
+	var (
+		run    = parallel.NewRun(8) // number of concurrent blame ops
+		mu     sync.Mutex
+		remove = make(map[*fileMatchResolver]bool, len(fileMatches))
+	)
+	for _, fm := range fileMatches {
+		fm := fm // shadow so it doesn't change in the goroutine
+		run.Acquire()
+		goroutine.Go(func() {
+			defer run.Release()
+
+			lineMatches, err := f.filterLineMatches(ctx, fm)
+			if err != nil {
+				if ctx.Err() != nil {
+					run.Error(ctx.Err())
+				} else {
+					log15.Warn("Failed to blame file match for blame search filter.", "repo", fm.repo.Name, "path", fm.JPath, "error", err)
+				}
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			fm.JLineMatches = lineMatches
+			if len(lineMatches) == 0 {
+				remove[fm] = true
+			}
+		})
+	}
+	err = run.Wait()
+
+	filtered := make([]*fileMatchResolver, 0, len(fileMatches)-len(remove))
+	for _, fm := range fileMatches {
+		if !remove[fm] {
+			filtered = append(filtered, fm)
+		}
+	}
+	return filtered, err
+}
+
+// blameCache caches blame hunks by repository, commit, path and line range.
+// Because the commit is absolute, the hunks for a key never change.
+var blameCache = rcache.NewWithTTL("blame", 7*24*60*60)
+
+// blameLines returns the blame hunks for lines startLine through endLine
+// (1-indexed, inclusive) of the file at the given commit (or the default
+// branch if commitID is empty).
+func blameLines(ctx context.Context, repo *types.Repo, commitID api.CommitID, path string, startLine, endLine int) ([]*git.Hunk, error) {
+	gitserverRepo := gitserver.Repo{Name: repo.Name}
+	if commitID == "" {
+		// Resolve the default branch so that results can be cached.
+		var err error
+		commitID, err = git.ResolveRevision(ctx, gitserverRepo, nil, "HEAD", &git.ResolveRevisionOptions{NoEnsureRevision: true})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	key := fmt.Sprintf("%s:%s:%s:%d:%d", repo.Name, commitID, path, startLine, endLine)
+	if b, ok := blameCache.Get(key); ok {
+		var hunks []*git.Hunk
+		if err := json.Unmarshal(b, &hunks); err == nil {
+			return hunks, nil
+		}
+	}
+
+	hunks, err := git.BlameFile(ctx, gitserverRepo, path, &git.BlameOptions{
+		NewestCommit: commitID,
+		StartLine:    startLine,
+		EndLine:      endLine,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if b, err := json.Marshal(hunks); err == nil {
+		blameCache.Set(key, b)
+	}
+	return hunks, nil
+}
+
+// parseBlameDate parses an absolute date (such as "2018-06-25", "June 25 2018"
+// or an RFC 3339 timestamp) or a date relative to now (such as "3 weeks ago",
+// "2.days.ago" or "yesterday").
+func parseBlameDate(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	lower := strings.ToLower(s)
+	switch lower {
+	case "now":
+		return now, nil
+	case "today":
+		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()), nil
+	case "yesterday":
+		return now.AddDate(0, 0, -1), nil
+	}
+
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02", "January 2 2006", "January 2, 2006", "Jan 2 2006", "Jan 2, 2006"} {
+		if t, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+
+	// Relative dates: "N unit(s) [ago]", with spaces or dots as separators.
+	fields := strings.FieldsFunc(lower, func(r rune) bool { return r == ' ' || r == '.' })
+	if len(fields) == 3 && fields[2] == "ago" {
+		fields = fields[:2]
+	}
+	if len(fields) == 2 {
+		if n, err := strconv.Atoi(fields[0]); err == nil && n >= 0 {
+			switch strings.TrimSuffix(fields[1], "s") {
+			case "second":
+				return now.Add(-time.Duration(n) * time.Second), nil
+			case "minute":
+				return now.Add(-time.Duration(n) * time.Minute), nil
+			case "hour":
+				return now.Add(-time.Duration(n) * time.Hour), nil
+			case "day":
+				return now.AddDate(0, 0, -n), nil
+			case "week":
+				return now.AddDate(0, 0, -7*n), nil
+			case "month":
+				return now.AddDate(0, -n, 0), nil
+			case "year":
+				return now.AddDate(-n, 0, 0), nil
+			}
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q (use a date such as \"2018-06-25\" or a relative date such as \"3 weeks ago\")", s)
+}
@@ -0,0 +1,140 @@
+package graphqlbackend
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/search/query"
+	"github.com/sourcegraph/sourcegraph/pkg/vcs/git"
+)
+
+func TestParseBlameDate(t *testing.T) {
+	now := time.Date(2018, 7, 15, 12, 30, 0, 0, time.UTC)
+	tests := map[string]time.Time{
+		"now":                  now,
+		"today":                time.Date(2018, 7, 15, 0, 0, 0, 0, time.UTC),
+		"yesterday":            time.Date(2018, 7, 14, 12, 30, 0, 0, time.UTC),
+		"2018-06-25":           time.Date(2018, 6, 25, 0, 0, 0, 0, time.UTC),
+		"2018-06-25T10:00:00Z": time.Date(2018, 6, 25, 10, 0, 0, 0, time.UTC),
+		"June 25 2018":         time.Date(2018, 6, 25, 0, 0, 0, 0, time.UTC),
+		"jun 25, 2018":         time.Date(2018, 6, 25, 0, 0, 0, 0, time.UTC),
+		"3 weeks ago":          time.Date(2018, 6, 24, 12, 30, 0, 0, time.UTC),
+		"2.days.ago":           time.Date(2018, 7, 13, 12, 30, 0, 0, time.UTC),
+		"1 month":              time.Date(2018, 6, 15, 12, 30, 0, 0, time.UTC),
+		"90 minutes ago":       time.Date(2018, 7, 15, 11, 0, 0, 0, time.UTC),
+	}
+	for input, want := range tests {
+		got, err := parseBlameDate(input, now)
+		if err != nil {
+			t.Errorf("%q: %s", input, err)
+			continue
+		}
+		if !got.Equal(want) {
+			t.Errorf("%q: got %s, want %s", input, got, want)
+		}
+	}
+
+	for _, input := range []string{"", "last thursday", "3 fortnights ago", "-1 days ago"} {
+		if _, err := parseBlameDate(input, now); err == nil {
+			t.Errorf("%q: got nil error", input)
+		}
+	}
+}
+
+func TestBlameFilter(t *testing.T) {
+	now := time.Date(2018, 7, 15, 0, 0, 0, 0, time.UTC)
+	newFilter := func(t *testing.T, q string) *blameFilter {
+		t.Helper()
+		parsed, err := query.ParseAndCheck(q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, err := newBlameFilter(context.Background(), parsed, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return f
+	}
+
+	if f := newFilter(t, "TODO author:alice"); f != nil {
+		t.Errorf("got filter %+v for query without blame fields, want nil", f)
+	}
+
+	alice := git.Signature{Name: "Alice", Email: "alice@example.com", Date: time.Date(2018, 7, 1, 0, 0, 0, 0, time.UTC)}
+	bob := git.Signature{Name: "Bob", Email: "bob@example.com", Date: time.Date(2018, 7, 10, 0, 0, 0, 0, time.UTC)}
+	oldAlice := alice
+	oldAlice.Date = time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		query  string
+		author git.Signature
+		want   bool
+	}{
+		{"TODO blame.author:alice", alice, true},
+		{"TODO blame.author:alice", bob, false},
+		{"TODO blame.author:ALICE@example", alice, true},
+		{"TODO blame.author:alice blame.author:bob", bob, true},
+		{"TODO -blame.author:alice", alice, false},
+		{"TODO -blame.author:alice", bob, true},
+		{`TODO blame.after:"1 month ago"`, alice, true},
+		{`TODO blame.after:"1 month ago"`, oldAlice, false},
+		{`TODO blame.since:2018-07-05`, alice, false},
+		{`TODO blame.before:2018-07-05`, alice, true},
+		{`TODO blame.before:2018-07-05`, bob, false},
+		{`TODO blame.author:alice blame.after:2018-01-01`, oldAlice, false},
+	}
+	for _, test := range tests {
+		if got := newFilter(t, test.query).matchAuthor(test.author); got != test.want {
+			t.Errorf("%q: author %s: got %v, want %v", test.query, test.author.Name, got, test.want)
+		}
+	}
+}
+
+func TestBlameFilterFileMatchLimit(t *testing.T) {
+	for limit, want := range map[int32]int32{
+		30:   300,
+		100:  1000,
+		500:  1000,
+		5000: 5000, // never lower than requested
+	} {
+		if got := blameFilterFileMatchLimit(limit); got != want {
+			t.Errorf("%d: got %d, want %d", limit, got, want)
+		}
+	}
+}
+
+func TestLimitBlameFilteredFileMatches(t *testing.T) {
+	fileMatches := func(n int) []*fileMatchResolver {
+		fms := make([]*fileMatchResolver, n)
+		for i := range fms {
+			fms[i] = &fileMatchResolver{JLineMatches: []*lineMatch{{}, {}}}
+		}
+		return fms
+	}
+
+	tests := []struct {
+		name                                        string
+		filtered, searched, searchLimit, maxResults int
+		wantResults                                 int
+		wantLimitHit                                bool
+	}{
+		{name: "nothing dropped", filtered: 10, searched: 10, searchLimit: 300, maxResults: 30, wantResults: 10},
+		{name: "dropped below the search limit", filtered: 5, searched: 10, searchLimit: 300, maxResults: 30, wantResults: 5},
+		{name: "dropped at the search limit", filtered: 5, searched: 300, searchLimit: 300, maxResults: 30, wantResults: 5, wantLimitHit: true},
+		{name: "more than requested", filtered: 50, searched: 100, searchLimit: 300, maxResults: 30, wantResults: 30, wantLimitHit: true},
+	}
+	for _, test := range tests {
+		common := &searchResultsCommon{resultCount: int32(2 * test.searched)}
+		got := limitBlameFilteredFileMatches(fileMatches(test.filtered), test.searched, test.searchLimit, test.maxResults, common)
+		if len(got) != test.wantResults {
+			t.Errorf("%s: got %d results, want %d", test.name, len(got), test.wantResults)
+		}
+		if common.limitHit != test.wantLimitHit {
+			t.Errorf("%s: got limitHit %v, want %v", test.name, common.limitHit, test.wantLimitHit)
+		}
+		if want := int32(2 * test.wantResults); common.resultCount != want {
+			t.Errorf("%s: got resultCount %d, want %d", test.name, common.resultCount, want)
+		}
+	}
+}
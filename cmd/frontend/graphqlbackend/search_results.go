@@ -698,6 +698,10 @@ func (r *searchResolver) doResults(ctx context.Context, forceOnlyResultType stri
 	if err := args.Pattern.Validate(); err != nil {
 		return nil, &badRequestError{err}
 	}
+	blameFilter, err := newBlameFilter(ctx, r.query, time.Now())
+	if err != nil {
+		return nil, &badRequestError{err}
+	}
 
 	// Determine which types of results to return.
 	var resultTypes []string
@@ -821,13 +825,32 @@ func (r *searchResolver) doResults(ctx context.Context, forceOnlyResultType stri
 			goroutine.Go(func() {
 				defer wg.Done()
 
-				fileResults, fileCommon, err := searchFilesInRepos(ctx, &args)
+				fileArgs := args
+				if blameFilter != nil {
+					// The blame filter runs on the results of the text
+					// search, so fetch more results than requested to still
+					// return enough of them.
+					pattern := *args.Pattern
+					pattern.FileMatchLimit = blameFilterFileMatchLimit(pattern.FileMatchLimit)
+					fileArgs.Pattern = &pattern
+				}
+				fileResults, fileCommon, err := searchFilesInRepos(ctx, &fileArgs)
 				// Timeouts are reported through searchResultsCommon so don't report an error for them
 				if err != nil && !isContextError(ctx, err) {
 					multiErrMu.Lock()
 					multiErr = multierror.Append(multiErr, errors.Wrap(err, "text search failed"))
 					multiErrMu.Unlock()
 				}
+				if blameFilter != nil && len(fileResults) > 0 && fileCommon != nil {
+					searched := len(fileResults)
+					fileResults, err = filterFileMatchesByBlame(ctx, blameFilter, fileResults)
+					if err != nil && !isContextError(ctx, err) {
+						multiErrMu.Lock()
+						multiErr = multierror.Append(multiErr, errors.Wrap(err, "blame filter failed"))
+						multiErrMu.Unlock()
+					}
+					fileResults = limitBlameFilteredFileMatches(fileResults, searched, int(fileArgs.Pattern.FileMatchLimit), int(r.maxResults()), fileCommon)
+				}
 				for _, r := range fileResults {
 					key := r.uri
 					fileMatchesMu.Lock()
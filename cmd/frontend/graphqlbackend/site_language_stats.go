@@ -0,0 +1,102 @@
+package graphqlbackend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+)
+
+// maxLanguageStatisticsPoints bounds the number of data points (each of which
+// is a DB query) requested in a single call.
+const maxLanguageStatisticsPoints = 366
+
+func (r *siteResolver) LanguageStatistics(ctx context.Context, args *struct {
+	Repository *graphql.ID
+	Interval   string
+	Points     int32
+}) ([]*languageStatisticsPointResolver, error) {
+	// 🚨 SECURITY: Only site admins may view language statistics, because they
+	// include information about all repositories.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	if args.Points < 1 || args.Points > maxLanguageStatisticsPoints {
+		return nil, fmt.Errorf("points must be between 1 and %d", maxLanguageStatisticsPoints)
+	}
+	opt := db.LanguageStatisticsSeriesOptions{}
+	if args.Repository != nil {
+		repoID, err := unmarshalRepositoryID(*args.Repository)
+		if err != nil {
+			return nil, err
+		}
+		opt.RepoID = repoID
+	}
+	var err error
+	opt.Dates, err = languageStatisticsDates(time.Now(), args.Interval, int(args.Points))
+	if err != nil {
+		return nil, err
+	}
+
+	points, err := db.RepoLanguageStats.Series(ctx, opt)
+	if err != nil {
+		return nil, err
+	}
+	resolvers := make([]*languageStatisticsPointResolver, len(points))
+	for i, p := range points {
+		resolvers[i] = &languageStatisticsPointResolver{point: p}
+	}
+	return resolvers, nil
+}
+
+// languageStatisticsDates returns n dates separated by the given interval, in
+// ascending order and ending with now.
+func languageStatisticsDates(now time.Time, interval string, n int) ([]time.Time, error) {
+	var months, days int
+	switch interval {
+	case "DAY":
+		days = 1
+	case "WEEK":
+		days = 7
+	case "MONTH":
+		months = 1
+	default:
+		return nil, errors.New("invalid language statistics interval")
+	}
+	dates := make([]time.Time, n)
+	for i := range dates {
+		k := n - 1 - i
+		dates[i] = now.AddDate(0, -k*months, -k*days)
+	}
+	return dates, nil
+}
+
+type languageStatisticsPointResolver struct {
+	point *types.LanguageStatisticsPoint
+}
+
+func (r *languageStatisticsPointResolver) Date() string {
+	return r.point.Date.Format(time.RFC3339)
+}
+
+func (r *languageStatisticsPointResolver) Languages() []*languageStatisticResolver {
+	langs := make([]*languageStatisticResolver, len(r.point.Languages))
+	for i, l := range r.point.Languages {
+		langs[i] = &languageStatisticResolver{lang: l}
+	}
+	return langs
+}
+
+type languageStatisticResolver struct {
+	lang *types.LanguageStatistic
+}
+
+func (r *languageStatisticResolver) Name() string { return r.lang.Name }
+
+func (r *languageStatisticResolver) TotalBytes() float64 { return float64(r.lang.TotalBytes) }
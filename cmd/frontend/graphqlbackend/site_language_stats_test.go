@@ -0,0 +1,41 @@
+package graphqlbackend
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLanguageStatisticsDates(t *testing.T) {
+	now := time.Date(2018, 3, 31, 12, 0, 0, 0, time.UTC)
+	tests := map[string][]time.Time{
+		"DAY": {
+			time.Date(2018, 3, 29, 12, 0, 0, 0, time.UTC),
+			time.Date(2018, 3, 30, 12, 0, 0, 0, time.UTC),
+			now,
+		},
+		"WEEK": {
+			time.Date(2018, 3, 17, 12, 0, 0, 0, time.UTC),
+			time.Date(2018, 3, 24, 12, 0, 0, 0, time.UTC),
+			now,
+		},
+		"MONTH": {
+			time.Date(2018, 1, 31, 12, 0, 0, 0, time.UTC),
+			time.Date(2018, 3, 3, 12, 0, 0, 0, time.UTC), // February 31st is normalized
+			now,
+		},
+	}
+	for interval, want := range tests {
+		got, err := languageStatisticsDates(now, interval, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %v, want %v", interval, got, want)
+		}
+	}
+
+	if _, err := languageStatisticsDates(now, "YEAR", 3); err == nil {
+		t.Error("got nil error for invalid interval")
+	}
+}
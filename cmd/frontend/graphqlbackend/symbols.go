@@ -143,3 +143,47 @@ func (r *symbolResolver) Location() *locationResolver { return r.location }
 func (r *symbolResolver) URL(ctx context.Context) string { return r.location.URL(ctx) }
 
 func (r *symbolResolver) CanonicalURL() string { return r.location.CanonicalURL() }
+
+func (r *gitTreeEntryResolver) Outline(ctx context.Context) (res []*symbolOutlineNodeResolver, err error) {
+	ctx, done := context.WithTimeout(ctx, 5*time.Second)
+	defer done()
+	defer func() {
+		if ctx.Err() != nil && len(res) == 0 {
+			err = errors.New("processing symbols is taking longer than expected. Try again in a while")
+		}
+	}()
+	baseURI, err := gituri.Parse("git://" + string(r.commit.repo.repo.Name) + "?" + string(r.commit.oid))
+	if err != nil {
+		return nil, err
+	}
+	symbols, err := backend.Symbols.Outline(ctx, protocol.OutlineArgs{
+		Repo:     r.commit.repo.repo.Name,
+		CommitID: api.CommitID(r.commit.oid),
+		Path:     r.path,
+	})
+	return toSymbolOutlineNodeResolvers(symbols, baseURI, r.commit), err
+}
+
+func toSymbolOutlineNodeResolvers(symbols []*protocol.OutlineSymbol, baseURI *gituri.URI, commit *gitCommitResolver) []*symbolOutlineNodeResolver {
+	resolvers := make([]*symbolOutlineNodeResolver, 0, len(symbols))
+	for _, symbol := range symbols {
+		resolver := toSymbolResolver(symbolToLSPSymbolInformation(symbol.Symbol, baseURI), strings.ToLower(symbol.Language), commit)
+		if resolver == nil {
+			continue
+		}
+		resolvers = append(resolvers, &symbolOutlineNodeResolver{
+			symbol:   resolver,
+			children: toSymbolOutlineNodeResolvers(symbol.Children, baseURI, commit),
+		})
+	}
+	return resolvers
+}
+
+type symbolOutlineNodeResolver struct {
+	symbol   *symbolResolver
+	children []*symbolOutlineNodeResolver
+}
+
+func (r *symbolOutlineNodeResolver) Symbol() *symbolResolver { return r.symbol }
+
+func (r *symbolOutlineNodeResolver) Children() []*symbolOutlineNodeResolver { return r.children }
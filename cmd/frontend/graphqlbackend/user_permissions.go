@@ -0,0 +1,81 @@
+package graphqlbackend
+
+import (
+	"context"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/permsync"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/errcode"
+)
+
+func (r *UserResolver) PermissionsSyncStatus(ctx context.Context) (*userPermissionsSyncStatusResolver, error) {
+	// 🚨 SECURITY: Only site admins may see which repositories users can access.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	perms, err := db.UserPermissions.Get(ctx, r.user.ID)
+	if errcode.IsNotFound(err) {
+		perms = &types.UserPermissions{UserID: r.user.ID}
+	} else if err != nil {
+		return nil, err
+	}
+	return &userPermissionsSyncStatusResolver{perms: perms}, nil
+}
+
+type userPermissionsSyncStatusResolver struct {
+	perms *types.UserPermissions
+}
+
+func formatOptionalTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	s := t.Format(time.RFC3339)
+	return &s
+}
+
+func (r *userPermissionsSyncStatusResolver) SyncedAt() *string {
+	return formatOptionalTime(r.perms.SyncedAt)
+}
+
+func (r *userPermissionsSyncStatusResolver) LastAttemptedAt() *string {
+	return formatOptionalTime(r.perms.SyncAttemptedAt)
+}
+
+func (r *userPermissionsSyncStatusResolver) RequestedAt() *string {
+	return formatOptionalTime(r.perms.SyncRequestedAt)
+}
+
+func (r *userPermissionsSyncStatusResolver) Error() *string { return r.perms.SyncError }
+
+func (r *userPermissionsSyncStatusResolver) Stale() bool { return permsync.Stale(r.perms) }
+
+func (r *userPermissionsSyncStatusResolver) ReadableRepositoriesCount() int32 {
+	return int32(len(r.perms.ReadRepoIDs))
+}
+
+func (*schemaResolver) ScheduleUserPermissionsSync(ctx context.Context, args *struct {
+	User graphql.ID
+}) (*EmptyResponse, error) {
+	// 🚨 SECURITY: Only site admins may schedule permissions syncs.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	userID, err := UnmarshalUserID(args.User)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Users.GetByID(ctx, userID); err != nil {
+		return nil, err
+	}
+	if err := db.UserPermissions.RequestSync(ctx, userID); err != nil {
+		return nil, err
+	}
+	return &EmptyResponse{}, nil
+}
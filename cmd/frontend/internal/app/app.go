@@ -55,6 +55,7 @@ func NewHandler() http.Handler {
 	r.Get(router.SignIn).Handler(trace.TraceRoute(http.HandlerFunc(userpasswd.HandleSignIn)))
 	r.Get(router.SignOut).Handler(trace.TraceRoute(http.HandlerFunc(serveSignOut)))
 	r.Get(router.VerifyEmail).Handler(trace.TraceRoute(http.HandlerFunc(serveVerifyEmail)))
+	r.Get(router.SavedSearchUnsubscribe).Handler(trace.TraceRoute(http.HandlerFunc(serveSavedSearchUnsubscribe)))
 	r.Get(router.ResetPasswordInit).Handler(trace.TraceRoute(http.HandlerFunc(userpasswd.HandleResetPasswordInit)))
 	r.Get(router.ResetPasswordCode).Handler(trace.TraceRoute(http.HandlerFunc(userpasswd.HandleResetPasswordCode)))
 
@@ -0,0 +1,102 @@
+package app
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+	"net/url"
+
+	"github.com/gorilla/csrf"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/pkg/actor"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	log15 "gopkg.in/inconshreveable/log15.v2"
+)
+
+// serveSavedSearchUnsubscribe unsubscribes the current user from notifications
+// for a saved search. Links to it are included in saved search notification
+// digests.
+//
+// Following the link (a GET request) only shows a confirmation form, because
+// links in emails are also followed by mail scanners and prefetchers, and other
+// sites can make users' browsers request any URL. The form POSTs back to the
+// same URL with the CSRF token, which unsubscribes the user.
+func serveSavedSearchUnsubscribe(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	actr := actor.FromContext(ctx)
+	if !actr.IsAuthenticated() {
+		redirectTo := r.URL.String()
+		q := make(url.Values)
+		q.Set("returnTo", redirectTo)
+		http.Redirect(w, r, "/sign-in?"+q.Encode(), http.StatusFound)
+		return
+	}
+
+	subject, err := api.ParseSettingsSubject(r.URL.Query().Get("subject"))
+	if err != nil {
+		http.Error(w, "Invalid saved search subject.", http.StatusBadRequest)
+		return
+	}
+	spec := api.SavedQueryIDSpec{Subject: subject, Key: r.URL.Query().Get("key")}
+	if spec.Key == "" {
+		http.Error(w, "Missing saved search key.", http.StatusBadRequest)
+		return
+	}
+
+	if r.Method != "POST" {
+		renderSavedSearchUnsubscribeTemplate(w, r)
+		return
+	}
+
+	// 🚨 SECURITY: only the current user's own notification preference is changed.
+	usr, err := db.Users.GetByCurrentAuthUser(ctx)
+	if err != nil {
+		httpLogAndError(w, "Could not get current user", http.StatusUnauthorized)
+		return
+	}
+
+	pref, err := db.SavedQueryNotificationPreferences.Get(ctx, usr.ID, spec)
+	if err != nil {
+		httpLogAndError(w, "Unexpected error when unsubscribing from saved search.", http.StatusInternalServerError, "userID", usr.ID, "error", err)
+		return
+	}
+	if pref == nil {
+		pref = &api.SavedQueryNotificationPreference{UserID: usr.ID}
+	}
+	pref.Unsubscribed = true
+	if err := db.SavedQueryNotificationPreferences.Set(ctx, spec, pref); err != nil {
+		log15.Error("Failed to unsubscribe from saved search.", "userID", usr.ID, "subject", spec.Subject, "key", spec.Key, "error", err)
+		http.Error(w, "Unexpected error when unsubscribing from saved search.", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/search/searches", http.StatusSeeOther)
+}
+
+func renderSavedSearchUnsubscribeTemplate(w http.ResponseWriter, r *http.Request) {
+	data := struct {
+		Action    string
+		CSRFField template.HTML
+	}{
+		Action:    r.URL.RequestURI(),
+		CSRFField: csrf.TemplateField(r),
+	}
+
+	var buf bytes.Buffer
+	if err := savedSearchUnsubscribeTemplate.Execute(&buf, data); err != nil {
+		log15.Error("Error rendering saved search unsubscribe page template.", "err", err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	buf.WriteTo(w)
+}
+
+var savedSearchUnsubscribeTemplate = template.Must(template.New("").Parse(`
+<form method="POST" action="{{.Action}}">
+{{.CSRFField}}
+<p>Stop receiving notifications for this saved search?</p>
+<button type="submit">Unsubscribe</button>
+<a href="/search/searches">Cancel</a>
+</form>
+`))
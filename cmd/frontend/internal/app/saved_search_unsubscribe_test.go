@@ -0,0 +1,26 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/pkg/actor"
+)
+
+func TestServeSavedSearchUnsubscribe_getDoesNotUnsubscribe(t *testing.T) {
+	// The handler must not read or write the notification preferences (which would fail without
+	// a database) for GET requests.
+	req := httptest.NewRequest("GET", "/-/saved-searches/unsubscribe?subject=user+1&key=k", nil)
+	req = req.WithContext(actor.WithActor(req.Context(), &actor.Actor{UID: 1}))
+	rec := httptest.NewRecorder()
+	serveSavedSearchUnsubscribe(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d", rec.Code, http.StatusOK)
+	}
+	if body := rec.Body.String(); !strings.Contains(body, `method="POST"`) || !strings.Contains(body, `action="/-/saved-searches/unsubscribe?subject=user&#43;1&amp;key=k"`) {
+		t.Errorf("got body %q, want confirmation form that POSTs to the same URL", body)
+	}
+}
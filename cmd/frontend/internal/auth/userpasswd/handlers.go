@@ -181,6 +181,11 @@ func HandleSignIn(w http.ResponseWriter, r *http.Request) {
 		httpLogAndError(w, "Authentication failed", http.StatusUnauthorized)
 		return
 	}
+	// 🚨 SECURITY: Deactivated users may not sign in.
+	if usr.DeactivatedAt != nil {
+		httpLogAndError(w, "Authentication failed", http.StatusUnauthorized, "err", "user is deactivated", "userID", usr.ID)
+		return
+	}
 	actor := &actor.Actor{UID: usr.ID}
 
 	// Write the session cookie
@@ -15,17 +15,22 @@ import (
 	"time"
 
 	"github.com/keegancsmith/tmpfriend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/globals"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/hooks"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/app/pkg/updatecheck"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/cli/loghandlers"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/goroutine"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/discussions/codehostsync"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/discussions/mailreply"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/permsync"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/siteid"
 	"github.com/sourcegraph/sourcegraph/pkg/conf"
 	"github.com/sourcegraph/sourcegraph/pkg/db/dbconn"
 	"github.com/sourcegraph/sourcegraph/pkg/debugserver"
 	"github.com/sourcegraph/sourcegraph/pkg/env"
+	"github.com/sourcegraph/sourcegraph/pkg/highlight"
 	"github.com/sourcegraph/sourcegraph/pkg/processrestart"
 	"github.com/sourcegraph/sourcegraph/pkg/sysreq"
 	"github.com/sourcegraph/sourcegraph/pkg/tracer"
@@ -55,6 +60,7 @@ func init() {
 	// If CACHE_DIR is specified, use that
 	cacheDir := env.Get("CACHE_DIR", "/tmp", "directory to store cached archives.")
 	vfsutil.ArchiveCacheDir = filepath.Join(cacheDir, "frontend-archive-cache")
+	highlight.CacheDir = filepath.Join(cacheDir, "frontend-highlight-cache")
 }
 
 // configureExternalURL determines the external URL of the application.
@@ -168,6 +174,12 @@ func Main() error {
 	}
 
 	goroutine.Go(mailreply.StartWorker)
+	goroutine.Go(mailreply.StartSMTPServer)
+	goroutine.Go(codehostsync.StartWorker)
+	goroutine.Go(permsync.StartWorker)
+	goroutine.Go(backend.StartHighlightCachePrewarmer)
+	goroutine.Go(backend.StartLanguageStatsRecorder)
+	goroutine.Go(graphqlbackend.StartInsightsBackfiller)
 	go updatecheck.Start()
 	if hooks.AfterDBInit != nil {
 		hooks.AfterDBInit()
@@ -2,10 +2,12 @@ package httpapi
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/scim"
 	"github.com/sourcegraph/sourcegraph/pkg/actor"
 	"github.com/sourcegraph/sourcegraph/pkg/conf"
 	"github.com/sourcegraph/sourcegraph/pkg/errcode"
@@ -29,6 +31,15 @@ func AccessTokenAuthMiddleware(next http.Handler) http.Handler {
 			}
 		}
 
+		if headerValue := r.Header.Get("Authorization"); headerValue != "" && token == "" && isSCIMRequest(r) {
+			// SCIM clients (identity providers) send the token in an "Authorization: Bearer TOKEN"
+			// header. Other requests don't accept that scheme, so that they continue to ignore
+			// Authorization headers that are meant for proxies in front of Sourcegraph.
+			if parts := strings.SplitN(headerValue, " ", 2); len(parts) == 2 && strings.EqualFold(parts[0], "Bearer") {
+				token = strings.TrimSpace(parts[1])
+			}
+		}
+
 		if headerValue := r.Header.Get("Authorization"); headerValue != "" && token == "" {
 			// Handle Authorization header
 			var err error
@@ -111,3 +122,8 @@ func AccessTokenAuthMiddleware(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// isSCIMRequest reports whether the request is for the SCIM API.
+func isSCIMRequest(r *http.Request) bool {
+	return strings.HasPrefix(r.URL.Path, "/.api"+scim.PathPrefix+"/")
+}
@@ -103,6 +103,31 @@ func TestAccessTokenAuthMiddleware(t *testing.T) {
 		})
 	}
 
+	t.Run("bearer token for SCIM API", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/.api/scim/v2/Users", nil)
+		req.Header.Set("Authorization", "Bearer abcdef")
+		var calledAccessTokensLookup bool
+		db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded, requiredScope string) (subjectUserID int32, err error) {
+			calledAccessTokensLookup = true
+			if want := "abcdef"; tokenHexEncoded != want {
+				t.Errorf("got %q, want %q", tokenHexEncoded, want)
+			}
+			return 123, nil
+		}
+		defer func() { db.Mocks = db.MockStores{} }()
+		checkHTTPResponse(t, req, http.StatusOK, "user 123")
+		if !calledAccessTokensLookup {
+			t.Error("!calledAccessTokensLookup")
+		}
+	})
+
+	// Test that bearer tokens (which may be meant for a proxy) are ignored outside of the SCIM API.
+	t.Run("bearer token outside SCIM API", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/.api/graphql", nil)
+		req.Header.Set("Authorization", "Bearer abcdef")
+		checkHTTPResponse(t, req, http.StatusOK, "no user")
+	})
+
 	// Test that an access token overwrites the actor set by a prior auth middleware.
 	t.Run("actor present, valid non-sudo token", func(t *testing.T) {
 		req, _ := http.NewRequest("GET", "/", nil)
@@ -0,0 +1,57 @@
+package httpapi
+
+import (
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/globals"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/discussions/portable"
+	"github.com/sourcegraph/sourcegraph/pkg/errcode"
+	log15 "gopkg.in/inconshreveable/log15.v2"
+)
+
+// serveDiscussionsExport writes all discussion threads as newline-delimited
+// JSON (see package portable).
+func serveDiscussionsExport(w http.ResponseWriter, r *http.Request) error {
+	// 🚨 SECURITY: Only site admins may export discussions, which include
+	// threads on every repository.
+	if err := backend.CheckCurrentUserIsSiteAdmin(r.Context()); err != nil {
+		return &errcode.HTTPErr{Status: http.StatusForbidden, Err: err}
+	}
+
+	filename := "discussions-" + time.Now().UTC().Format("2006-01-02") + ".ndjson"
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	skipped, err := portable.Export(r.Context(), w, globals.ExternalURL.String())
+	if err != nil {
+		return err
+	}
+	for _, thread := range skipped {
+		log15.Warn("Skipped exporting discussion thread.", "title", thread.Title, "reason", thread.Reason)
+	}
+	return nil
+}
+
+// serveDiscussionsImport creates the discussion threads in the request body,
+// which must be in the format written by serveDiscussionsExport, and responds
+// with a report of what was imported and what could not be matched.
+func serveDiscussionsImport(w http.ResponseWriter, r *http.Request) error {
+	// 🚨 SECURITY: Only site admins may import discussions, which may be
+	// attributed to any user.
+	if err := backend.CheckCurrentUserIsSiteAdmin(r.Context()); err != nil {
+		return &errcode.HTTPErr{Status: http.StatusForbidden, Err: err}
+	}
+	user, err := db.Users.GetByCurrentAuthUser(r.Context())
+	if err != nil {
+		return err
+	}
+
+	report, err := portable.Import(r.Context(), r.Body, user)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, report)
+}
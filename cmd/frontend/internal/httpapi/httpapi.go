@@ -13,7 +13,9 @@ import (
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/envvar"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/app/pkg/updatecheck"
 	apirouter "github.com/sourcegraph/sourcegraph/cmd/frontend/internal/httpapi/router"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/discussions/mailreply"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/handlerutil"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/scim"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/registry"
 	"github.com/sourcegraph/sourcegraph/pkg/env"
 	"github.com/sourcegraph/sourcegraph/pkg/trace"
@@ -39,6 +41,14 @@ func NewHandler(m *mux.Router) http.Handler {
 
 	m.Get(apirouter.Telemetry).Handler(trace.TraceRoute(telemetryHandler))
 
+	m.Get(apirouter.InboundEmail).Handler(trace.TraceRoute(handler(mailreply.ServeWebhook)))
+
+	m.Get(apirouter.DiscussionsExport).Handler(trace.TraceRoute(handler(serveDiscussionsExport)))
+	m.Get(apirouter.DiscussionsImport).Handler(trace.TraceRoute(handler(serveDiscussionsImport)))
+
+	m.Get(apirouter.SCIMResources).Handler(trace.TraceRoute(scim.Handler))
+	m.Get(apirouter.SCIMResource).Handler(trace.TraceRoute(scim.Handler))
+
 	if envvar.SourcegraphDotComMode() {
 		m.Path("/updates").Methods("GET").Name("updatecheck").Handler(trace.TraceRoute(http.HandlerFunc(updatecheck.Handler)))
 	}
@@ -82,6 +92,16 @@ func NewInternalHandler(m *mux.Router) http.Handler {
 	m.Get(apirouter.SavedQueriesGetInfo).Handler(trace.TraceRoute(handler(serveSavedQueriesGetInfo)))
 	m.Get(apirouter.SavedQueriesSetInfo).Handler(trace.TraceRoute(handler(serveSavedQueriesSetInfo)))
 	m.Get(apirouter.SavedQueriesDeleteInfo).Handler(trace.TraceRoute(handler(serveSavedQueriesDeleteInfo)))
+	m.Get(apirouter.SavedQueriesGetSnapshot).Handler(trace.TraceRoute(handler(serveSavedQueriesGetSnapshot)))
+	m.Get(apirouter.SavedQueriesSetSnapshot).Handler(trace.TraceRoute(handler(serveSavedQueriesSetSnapshot)))
+	m.Get(apirouter.SavedQueriesLogWebhookDelivery).Handler(trace.TraceRoute(handler(serveSavedQueriesLogWebhookDelivery)))
+	m.Get(apirouter.SavedQueriesLogRun).Handler(trace.TraceRoute(handler(serveSavedQueriesLogRun)))
+	m.Get(apirouter.SavedQueriesListRuns).Handler(trace.TraceRoute(handler(serveSavedQueriesListRuns)))
+	m.Get(apirouter.SavedQueriesGetNotificationPreferences).Handler(trace.TraceRoute(handler(serveSavedQueriesGetNotificationPreferences)))
+	m.Get(apirouter.SavedQueriesAddDigestItem).Handler(trace.TraceRoute(handler(serveSavedQueriesAddDigestItem)))
+	m.Get(apirouter.SavedQueriesListDigestItems).Handler(trace.TraceRoute(handler(serveSavedQueriesListDigestItems)))
+	m.Get(apirouter.SavedQueriesDeleteDigestItems).Handler(trace.TraceRoute(handler(serveSavedQueriesDeleteDigestItems)))
+	m.Get(apirouter.DiscussionsCreateThread).Handler(trace.TraceRoute(handler(serveDiscussionsCreateThread)))
 	m.Get(apirouter.OrgsListUsers).Handler(trace.TraceRoute(handler(serveOrgsListUsers)))
 	m.Get(apirouter.OrgsGetByName).Handler(trace.TraceRoute(handler(serveOrgsGetByName)))
 	m.Get(apirouter.UsersGetByUsername).Handler(trace.TraceRoute(handler(serveUsersGetByUsername)))
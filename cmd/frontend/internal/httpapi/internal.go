@@ -15,6 +15,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/globals"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/discussions"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
 	"github.com/sourcegraph/sourcegraph/pkg/api"
 	"github.com/sourcegraph/sourcegraph/pkg/conf"
@@ -335,6 +336,191 @@ func serveSavedQueriesDeleteInfo(w http.ResponseWriter, r *http.Request) error {
 	return nil
 }
 
+func serveSavedQueriesGetSnapshot(w http.ResponseWriter, r *http.Request) error {
+	var query string
+	err := json.NewDecoder(r.Body).Decode(&query)
+	if err != nil {
+		return errors.Wrap(err, "Decode")
+	}
+	snapshot, err := db.SavedQueries.GetSnapshot(r.Context(), query)
+	if err != nil {
+		return errors.Wrap(err, "SavedQueries.GetSnapshot")
+	}
+	if err := json.NewEncoder(w).Encode(snapshot); err != nil {
+		return errors.Wrap(err, "Encode")
+	}
+	return nil
+}
+
+func serveSavedQueriesSetSnapshot(w http.ResponseWriter, r *http.Request) error {
+	var snapshot *api.SavedQuerySnapshot
+	err := json.NewDecoder(r.Body).Decode(&snapshot)
+	if err != nil {
+		return errors.Wrap(err, "Decode")
+	}
+	err = db.SavedQueries.SetSnapshot(r.Context(), snapshot)
+	if err != nil {
+		return errors.Wrap(err, "SavedQueries.SetSnapshot")
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("OK"))
+	return nil
+}
+
+func serveSavedQueriesLogWebhookDelivery(w http.ResponseWriter, r *http.Request) error {
+	var delivery *api.SavedQueryWebhookDelivery
+	err := json.NewDecoder(r.Body).Decode(&delivery)
+	if err != nil {
+		return errors.Wrap(err, "Decode")
+	}
+	err = db.SavedQueryWebhookDeliveries.Log(r.Context(), delivery)
+	if err != nil {
+		return errors.Wrap(err, "SavedQueryWebhookDeliveries.Log")
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("OK"))
+	return nil
+}
+
+func serveSavedQueriesLogRun(w http.ResponseWriter, r *http.Request) error {
+	var run *api.SavedQueryRun
+	err := json.NewDecoder(r.Body).Decode(&run)
+	if err != nil {
+		return errors.Wrap(err, "Decode")
+	}
+	err = db.SavedQueryRuns.Log(r.Context(), run)
+	if err != nil {
+		return errors.Wrap(err, "SavedQueryRuns.Log")
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("OK"))
+	return nil
+}
+
+func serveSavedQueriesListRuns(w http.ResponseWriter, r *http.Request) error {
+	var args api.SavedQueriesListRunsArgs
+	err := json.NewDecoder(r.Body).Decode(&args)
+	if err != nil {
+		return errors.Wrap(err, "Decode")
+	}
+	runs, err := db.SavedQueryRuns.List(r.Context(), args.Query, args.Limit)
+	if err != nil {
+		return errors.Wrap(err, "SavedQueryRuns.List")
+	}
+	if err := json.NewEncoder(w).Encode(runs); err != nil {
+		return errors.Wrap(err, "Encode")
+	}
+	return nil
+}
+
+func serveSavedQueriesGetNotificationPreferences(w http.ResponseWriter, r *http.Request) error {
+	var spec api.SavedQueryIDSpec
+	err := json.NewDecoder(r.Body).Decode(&spec)
+	if err != nil {
+		return errors.Wrap(err, "Decode")
+	}
+	prefs, err := db.SavedQueryNotificationPreferences.List(r.Context(), spec)
+	if err != nil {
+		return errors.Wrap(err, "SavedQueryNotificationPreferences.List")
+	}
+	if err := json.NewEncoder(w).Encode(prefs); err != nil {
+		return errors.Wrap(err, "Encode")
+	}
+	return nil
+}
+
+func serveSavedQueriesAddDigestItem(w http.ResponseWriter, r *http.Request) error {
+	var item *api.SavedQueryDigestItem
+	err := json.NewDecoder(r.Body).Decode(&item)
+	if err != nil {
+		return errors.Wrap(err, "Decode")
+	}
+	err = db.SavedQueryDigestItems.Add(r.Context(), item)
+	if err != nil {
+		return errors.Wrap(err, "SavedQueryDigestItems.Add")
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("OK"))
+	return nil
+}
+
+func serveSavedQueriesListDigestItems(w http.ResponseWriter, r *http.Request) error {
+	items, err := db.SavedQueryDigestItems.List(r.Context())
+	if err != nil {
+		return errors.Wrap(err, "SavedQueryDigestItems.List")
+	}
+	if err := json.NewEncoder(w).Encode(items); err != nil {
+		return errors.Wrap(err, "Encode")
+	}
+	return nil
+}
+
+func serveSavedQueriesDeleteDigestItems(w http.ResponseWriter, r *http.Request) error {
+	var ids []int64
+	err := json.NewDecoder(r.Body).Decode(&ids)
+	if err != nil {
+		return errors.Wrap(err, "Decode")
+	}
+	err = db.SavedQueryDigestItems.Delete(r.Context(), ids)
+	if err != nil {
+		return errors.Wrap(err, "SavedQueryDigestItems.Delete")
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("OK"))
+	return nil
+}
+
+func serveDiscussionsCreateThread(w http.ResponseWriter, r *http.Request) error {
+	var args api.DiscussionsCreateThreadArgs
+	err := json.NewDecoder(r.Body).Decode(&args)
+	if err != nil {
+		return errors.Wrap(err, "Decode")
+	}
+	repo, err := db.Repos.GetByName(r.Context(), args.Repo)
+	if err != nil {
+		return errors.Wrap(err, "Repos.GetByName")
+	}
+
+	newThread := &types.DiscussionThread{
+		AuthorUserID: args.AuthorUserID,
+		Title:        args.Title,
+		TargetRepo:   &types.DiscussionThreadTargetRepo{RepoID: repo.ID},
+	}
+	if args.Path != "" {
+		newThread.TargetRepo.Path = &args.Path
+		if args.Line > 0 {
+			// Select the whole line.
+			startLine, endLine, character := int32(args.Line-1), int32(args.Line), int32(0)
+			linesBefore, lines, linesAfter := []string{}, []string{args.Preview}, []string{}
+			newThread.TargetRepo.StartLine = &startLine
+			newThread.TargetRepo.EndLine = &endLine
+			newThread.TargetRepo.StartCharacter = &character
+			newThread.TargetRepo.EndCharacter = &character
+			newThread.TargetRepo.LinesBefore = &linesBefore
+			newThread.TargetRepo.Lines = &lines
+			newThread.TargetRepo.LinesAfter = &linesAfter
+		}
+	}
+	thread, err := db.DiscussionThreads.Create(r.Context(), newThread)
+	if err != nil {
+		return errors.Wrap(err, "DiscussionThreads.Create")
+	}
+	newComment := &types.DiscussionComment{
+		ThreadID:     thread.ID,
+		AuthorUserID: args.AuthorUserID,
+		Contents:     args.Contents,
+	}
+	if _, err := db.DiscussionComments.Create(r.Context(), newComment); err != nil {
+		return errors.Wrap(err, "DiscussionComments.Create")
+	}
+	discussions.NotifyNewThread(thread, newComment)
+
+	if err := json.NewEncoder(w).Encode(thread.ID); err != nil {
+		return errors.Wrap(err, "Encode")
+	}
+	return nil
+}
+
 func serveSettingsGetForSubject(w http.ResponseWriter, r *http.Request) error {
 	var subject api.SettingsSubject
 	if err := json.NewDecoder(r.Body).Decode(&subject); err != nil {
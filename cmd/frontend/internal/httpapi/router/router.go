@@ -15,35 +15,53 @@ const (
 	RepoRefresh = "repo.refresh"
 	Telemetry   = "telemetry"
 
-	SavedQueriesListAll    = "internal.saved-queries.list-all"
-	SavedQueriesGetInfo    = "internal.saved-queries.get-info"
-	SavedQueriesSetInfo    = "internal.saved-queries.set-info"
-	SavedQueriesDeleteInfo = "internal.saved-queries.delete-info"
-	SettingsGetForSubject  = "internal.settings.get-for-subject"
-	OrgsListUsers          = "internal.orgs.list-users"
-	OrgsGetByName          = "internal.orgs.get-by-name"
-	UsersGetByUsername     = "internal.users.get-by-username"
-	UserEmailsGetEmail     = "internal.user-emails.get-email"
-	ExternalURL            = "internal.app-url"
-	GitServerAddrs         = "internal.git-server-addrs"
-	CanSendEmail           = "internal.can-send-email"
-	SendEmail              = "internal.send-email"
-	Extension              = "internal.extension"
-	GitInfoRefs            = "internal.git.info-refs"
-	GitResolveRevision     = "internal.git.resolve-revision"
-	GitTar                 = "internal.git.tar"
-	GitUploadPack          = "internal.git.upload-pack"
-	PhabricatorRepoCreate  = "internal.phabricator.repo.create"
-	ReposCreateIfNotExists = "internal.repos.create-if-not-exists"
-	ReposGetByName         = "internal.repos.get-by-name"
-	ReposInventoryUncached = "internal.repos.inventory-uncached"
-	ReposInventory         = "internal.repos.inventory"
-	ReposList              = "internal.repos.list"
-	ReposListEnabled       = "internal.repos.list-enabled"
-	ReposUpdateMetadata    = "internal.repos.update-metadata"
-	Configuration          = "internal.configuration"
-	ExternalServiceConfigs = "internal.external-services.configs"
-	ExternalServicesList   = "internal.external-services.list"
+	InboundEmail = "inbound-email"
+
+	DiscussionsExport = "discussions.export"
+	DiscussionsImport = "discussions.import"
+
+	SCIMResources = "scim.resources"
+	SCIMResource  = "scim.resource"
+
+	SavedQueriesListAll                    = "internal.saved-queries.list-all"
+	SavedQueriesGetInfo                    = "internal.saved-queries.get-info"
+	SavedQueriesSetInfo                    = "internal.saved-queries.set-info"
+	SavedQueriesDeleteInfo                 = "internal.saved-queries.delete-info"
+	SavedQueriesGetSnapshot                = "internal.saved-queries.get-snapshot"
+	SavedQueriesSetSnapshot                = "internal.saved-queries.set-snapshot"
+	SavedQueriesLogWebhookDelivery         = "internal.saved-queries.log-webhook-delivery"
+	SavedQueriesLogRun                     = "internal.saved-queries.log-run"
+	SavedQueriesListRuns                   = "internal.saved-queries.list-runs"
+	SavedQueriesGetNotificationPreferences = "internal.saved-queries.get-notification-preferences"
+	SavedQueriesAddDigestItem              = "internal.saved-queries.add-digest-item"
+	SavedQueriesListDigestItems            = "internal.saved-queries.list-digest-items"
+	SavedQueriesDeleteDigestItems          = "internal.saved-queries.delete-digest-items"
+	SettingsGetForSubject                  = "internal.settings.get-for-subject"
+	DiscussionsCreateThread                = "internal.discussions.create-thread"
+	OrgsListUsers                          = "internal.orgs.list-users"
+	OrgsGetByName                          = "internal.orgs.get-by-name"
+	UsersGetByUsername                     = "internal.users.get-by-username"
+	UserEmailsGetEmail                     = "internal.user-emails.get-email"
+	ExternalURL                            = "internal.app-url"
+	GitServerAddrs                         = "internal.git-server-addrs"
+	CanSendEmail                           = "internal.can-send-email"
+	SendEmail                              = "internal.send-email"
+	Extension                              = "internal.extension"
+	GitInfoRefs                            = "internal.git.info-refs"
+	GitResolveRevision                     = "internal.git.resolve-revision"
+	GitTar                                 = "internal.git.tar"
+	GitUploadPack                          = "internal.git.upload-pack"
+	PhabricatorRepoCreate                  = "internal.phabricator.repo.create"
+	ReposCreateIfNotExists                 = "internal.repos.create-if-not-exists"
+	ReposGetByName                         = "internal.repos.get-by-name"
+	ReposInventoryUncached                 = "internal.repos.inventory-uncached"
+	ReposInventory                         = "internal.repos.inventory"
+	ReposList                              = "internal.repos.list"
+	ReposListEnabled                       = "internal.repos.list-enabled"
+	ReposUpdateMetadata                    = "internal.repos.update-metadata"
+	Configuration                          = "internal.configuration"
+	ExternalServiceConfigs                 = "internal.external-services.configs"
+	ExternalServicesList                   = "internal.external-services.list"
 )
 
 // New creates a new API router with route URL pattern definitions but
@@ -58,6 +76,11 @@ func New(base *mux.Router) *mux.Router {
 	addRegistryRoute(base)
 	addGraphQLRoute(base)
 	addTelemetryRoute(base)
+	base.Path("/inbound-email").Methods("POST").Name(InboundEmail)
+	base.Path("/discussions/export").Methods("GET").Name(DiscussionsExport)
+	base.Path("/discussions/import").Methods("POST").Name(DiscussionsImport)
+	base.Path("/scim/v2/{Resource}").Name(SCIMResources)
+	base.Path("/scim/v2/{Resource}/{ID}").Name(SCIMResource)
 
 	// repo contains routes that are NOT specific to a revision. In these routes, the URL may not contain a revspec after the repo (that is, no "github.com/foo/bar@myrevspec").
 	repoPath := `/repos/` + routevar.Repo
@@ -83,7 +106,17 @@ func NewInternal(base *mux.Router) *mux.Router {
 	base.Path("/saved-queries/get-info").Methods("POST").Name(SavedQueriesGetInfo)
 	base.Path("/saved-queries/set-info").Methods("POST").Name(SavedQueriesSetInfo)
 	base.Path("/saved-queries/delete-info").Methods("POST").Name(SavedQueriesDeleteInfo)
+	base.Path("/saved-queries/get-snapshot").Methods("POST").Name(SavedQueriesGetSnapshot)
+	base.Path("/saved-queries/set-snapshot").Methods("POST").Name(SavedQueriesSetSnapshot)
+	base.Path("/saved-queries/log-webhook-delivery").Methods("POST").Name(SavedQueriesLogWebhookDelivery)
+	base.Path("/saved-queries/log-run").Methods("POST").Name(SavedQueriesLogRun)
+	base.Path("/saved-queries/list-runs").Methods("POST").Name(SavedQueriesListRuns)
+	base.Path("/saved-queries/get-notification-preferences").Methods("POST").Name(SavedQueriesGetNotificationPreferences)
+	base.Path("/saved-queries/add-digest-item").Methods("POST").Name(SavedQueriesAddDigestItem)
+	base.Path("/saved-queries/list-digest-items").Methods("POST").Name(SavedQueriesListDigestItems)
+	base.Path("/saved-queries/delete-digest-items").Methods("POST").Name(SavedQueriesDeleteDigestItems)
 	base.Path("/settings/get-for-subject").Methods("POST").Name(SettingsGetForSubject)
+	base.Path("/discussions/create-thread").Methods("POST").Name(DiscussionsCreateThread)
 	base.Path("/orgs/list-users").Methods("POST").Name(OrgsListUsers)
 	base.Path("/orgs/get-by-name").Methods("POST").Name(OrgsGetByName)
 	base.Path("/users/get-by-username").Methods("POST").Name(UsersGetByUsername)
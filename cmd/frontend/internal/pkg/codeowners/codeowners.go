@@ -0,0 +1,119 @@
+// Package codeowners parses CODEOWNERS files, which specify the owners of
+// files in a repository, and resolves the owners to Sourcegraph users and
+// organizations.
+//
+// The file format is that of GitHub and GitLab: each line consists of a
+// gitignore-style path pattern followed by the owners of the matching files,
+// which are "@username", "@org/team" or email address values. When multiple
+// lines match a file, the last one takes precedence. See
+// https://help.github.com/articles/about-codeowners/.
+package codeowners
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Paths are the paths (relative to the repository root) where a CODEOWNERS
+// file is looked for, in order of precedence.
+var Paths = []string{"CODEOWNERS", ".github/CODEOWNERS", ".gitlab/CODEOWNERS", "docs/CODEOWNERS"}
+
+// File is a parsed CODEOWNERS file.
+type File struct {
+	Rules []*Rule
+}
+
+// Rule is a line of a CODEOWNERS file, which specifies the owners of the files
+// that match its pattern.
+type Rule struct {
+	Pattern string
+	Owners  []string // the owners as written in the file (e.g., "@alice" or "alice@example.com")
+
+	re *regexp.Regexp
+}
+
+// Parse parses the contents of a CODEOWNERS file. Lines whose pattern is
+// invalid are ignored, as are GitLab section headers.
+func Parse(data []byte) *File {
+	var f File
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "[") {
+			continue
+		}
+		fields := strings.Fields(line)
+		re, err := compilePattern(fields[0])
+		if err != nil {
+			continue
+		}
+		var owners []string
+		for _, owner := range fields[1:] {
+			if strings.HasPrefix(owner, "#") {
+				break // trailing comment
+			}
+			owners = append(owners, owner)
+		}
+		f.Rules = append(f.Rules, &Rule{Pattern: fields[0], Owners: owners, re: re})
+	}
+	return &f
+}
+
+// Match reports whether the rule's pattern matches the path (relative to the
+// repository root). A pattern that matches a directory matches all files in
+// it.
+func (r *Rule) Match(path string) bool {
+	return r.re.MatchString(strings.Trim(path, "/"))
+}
+
+// Owners returns the owners of the file or directory at the path (relative to
+// the repository root), as written in the file. It returns nil if the path has
+// no owners.
+func (f *File) Owners(path string) []string {
+	for i := len(f.Rules) - 1; i >= 0; i-- {
+		if f.Rules[i].Match(path) {
+			return f.Rules[i].Owners
+		}
+	}
+	return nil
+}
+
+// compilePattern compiles a gitignore-style pattern to a regexp that matches
+// paths relative to the repository root (without leading or trailing
+// slashes).
+//
+// A pattern that contains a slash (other than a trailing slash) is relative to
+// the repository root. Otherwise, it matches at any depth.
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	pattern = strings.TrimSuffix(pattern, "/")
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	var b strings.Builder
+	if anchored {
+		b.WriteString("^")
+	} else {
+		b.WriteString("^(?:.*/)?")
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '\\' && i+1 < len(pattern):
+			b.WriteString(regexp.QuoteMeta(pattern[i+1 : i+2]))
+			i++
+		default:
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	// Match the files in a matching directory, too.
+	b.WriteString("(?:/.*)?$")
+	return regexp.Compile(b.String())
+}
@@ -0,0 +1,86 @@
+package codeowners
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	f := Parse([]byte(`# Comment
+*       @global-owner
+
+[Docs]
+/docs/  docs@example.com   # trailing comment
+*.go    @gopher @sourcegraph/go-team
+/cmd/**/main.go @cmd-owner
+vendor
+/build/logs/
+`))
+	var got [][]string
+	for _, rule := range f.Rules {
+		got = append(got, append([]string{rule.Pattern}, rule.Owners...))
+	}
+	want := [][]string{
+		{"*", "@global-owner"},
+		{"/docs/", "docs@example.com"},
+		{"*.go", "@gopher", "@sourcegraph/go-team"},
+		{"/cmd/**/main.go", "@cmd-owner"},
+		{"vendor"},
+		{"/build/logs/"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got rules %q, want %q", got, want)
+	}
+
+	tests := map[string][]string{
+		"README.md":                   {"@global-owner"},
+		"docs":                        {"docs@example.com"},
+		"docs/index.md":               {"docs@example.com"},
+		"sub/docs/index.md":           {"@global-owner"},
+		"docs/example.go":             {"@gopher", "@sourcegraph/go-team"},
+		"cmd/main.go":                 {"@cmd-owner"},
+		"cmd/frontend/shared/main.go": {"@cmd-owner"},
+		"cmd/frontend/main_test.go":   {"@gopher", "@sourcegraph/go-team"},
+		"vendor/github.com/a/a.go":    nil,
+		"sub/vendor/a.go":             nil,
+		"build/logs/today.log":        nil,
+		"build/other.log":             {"@global-owner"},
+	}
+	for path, want := range tests {
+		if got := f.Owners(path); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got owners %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestRule_Match(t *testing.T) {
+	tests := []struct {
+		pattern, path string
+		want          bool
+	}{
+		{pattern: "*", path: "a/b/c", want: true},
+		{pattern: "*.js", path: "a/b.js", want: true},
+		{pattern: "*.js", path: "a/b.jsx", want: false},
+		{pattern: "/a/*.js", path: "a/b.js", want: true},
+		{pattern: "/a/*.js", path: "a/b/c.js", want: false},
+		{pattern: "a/b", path: "x/a/b", want: false},
+		{pattern: "b", path: "x/a/b", want: true},
+		{pattern: "b", path: "x/ab", want: false},
+		{pattern: "**/logs", path: "x/y/logs/a.log", want: true},
+		{pattern: "/logs/**", path: "logs/a/b.log", want: true},
+		{pattern: "a?c", path: "abc", want: true},
+		{pattern: "a?c", path: "a/c", want: false},
+		{pattern: `\#file`, path: "#file", want: true},
+		{pattern: "a.b", path: "axb", want: false},
+	}
+	for _, test := range tests {
+		re, err := compilePattern(test.pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rule := &Rule{Pattern: test.pattern, re: re}
+		if got := rule.Match(test.path); got != test.want {
+			t.Errorf("pattern %q, path %q: got %v, want %v", test.pattern, test.path, got, test.want)
+		}
+	}
+}
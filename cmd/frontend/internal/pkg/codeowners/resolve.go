@@ -0,0 +1,170 @@
+package codeowners
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/actor"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/errcode"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc/github"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc/gitlab"
+	"github.com/sourcegraph/sourcegraph/pkg/vcs/git"
+)
+
+// ForCommit returns the CODEOWNERS file of the repository at the commit, or
+// nil if it has none.
+func ForCommit(ctx context.Context, repo *types.Repo, commitID api.CommitID) (*File, error) {
+	cachedRepo, err := backend.CachedGitRepo(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range Paths {
+		data, err := git.ReadFile(ctx, *cachedRepo, commitID, path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "read %s", path)
+		}
+		return Parse(data), nil
+	}
+	return nil, nil
+}
+
+// Owner is an owner of a file, which may be resolved to a Sourcegraph user.
+type Owner struct {
+	Name string // the owner as written in the CODEOWNERS file
+
+	// User is the Sourcegraph user that the owner refers to, or nil if the
+	// owner could not be resolved.
+	User *types.User
+}
+
+// ResolveOwners resolves the owners (as written in the repository's CODEOWNERS
+// file) to Sourcegraph users:
+//
+//   - "@name" resolves to the user with an external account with the login
+//     "name" on the repository's code host.
+//   - "alice@example.com" resolves to the user with the verified email
+//     address.
+//   - "@org/team" is not resolved, because Sourcegraph has no teams.
+//
+// Handles are not resolved to Sourcegraph users with the same username,
+// because anyone could sign up with that username.
+//
+// 🚨 SECURITY: Owners who resolve to a user who can't read the repository are
+// omitted, so that they aren't notified about (or shown as owning) it.
+func ResolveOwners(ctx context.Context, repo *types.Repo, names []string) ([]*Owner, error) {
+	var codeHostUserIDs map[string]int32 // lazily loaded
+	owners := make([]*Owner, 0, len(names))
+	for _, name := range names {
+		owner := &Owner{Name: name}
+		switch {
+		case strings.HasPrefix(name, "@") && !strings.Contains(name, "/"):
+			if codeHostUserIDs == nil {
+				var err error
+				codeHostUserIDs, err = codeHostLogins(ctx, repo)
+				if err != nil {
+					return nil, err
+				}
+			}
+			userID, ok := codeHostUserIDs[strings.ToLower(name[1:])]
+			if !ok {
+				break
+			}
+			user, err := db.Users.GetByID(ctx, userID)
+			if err != nil && !errcode.IsNotFound(err) {
+				return nil, err
+			}
+			owner.User = user
+		case strings.Contains(name, "@") && !strings.HasPrefix(name, "@"):
+			user, err := db.Users.GetByVerifiedEmail(ctx, name)
+			if err != nil && !errcode.IsNotFound(err) {
+				return nil, err
+			}
+			owner.User = user
+		}
+		if owner.User != nil {
+			ok, err := canReadRepo(ctx, owner.User, repo)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+		}
+		owners = append(owners, owner)
+	}
+	return owners, nil
+}
+
+// codeHostLogins returns the IDs of the users with an external account on the
+// repository's code host, keyed by the (lowercased) login of the account.
+func codeHostLogins(ctx context.Context, repo *types.Repo) (map[string]int32, error) {
+	userIDs := map[string]int32{}
+	if repo.ExternalRepo == nil {
+		return userIDs, nil
+	}
+	accounts, err := db.ExternalAccounts.List(ctx, db.ExternalAccountsListOptions{
+		ServiceType: repo.ExternalRepo.ServiceType,
+		ServiceID:   repo.ExternalRepo.ServiceID,
+		AnyClientID: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, account := range accounts {
+		var login string
+		switch account.ServiceType {
+		case github.ServiceType:
+			user, _, err := github.GetExternalAccountData(&account.ExternalAccountData)
+			if err != nil || user == nil || user.Login == nil {
+				continue
+			}
+			login = *user.Login
+		case gitlab.ServiceType:
+			user, _, err := gitlab.GetExternalAccountData(&account.ExternalAccountData)
+			if err != nil || user == nil {
+				continue
+			}
+			login = user.Username
+		}
+		if login != "" {
+			userIDs[strings.ToLower(login)] = account.UserID
+		}
+	}
+	return userIDs, nil
+}
+
+// canReadRepo reports whether the user has read access to the repository.
+func canReadRepo(ctx context.Context, user *types.User, repo *types.Repo) (bool, error) {
+	_, err := db.Repos.Get(actor.WithActor(ctx, &actor.Actor{UID: user.ID}), repo.ID)
+	if errcode.IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// OwnerUsers returns the users who are the owners, without duplicates.
+func OwnerUsers(owners []*Owner) []*types.User {
+	var (
+		users []*types.User
+		set   = make(map[int32]struct{})
+	)
+	for _, owner := range owners {
+		if owner.User == nil {
+			continue
+		}
+		if _, ok := set[owner.User.ID]; !ok {
+			set[owner.User.ID] = struct{}{}
+			users = append(users, owner.User)
+		}
+	}
+	return users
+}
@@ -0,0 +1,110 @@
+package codeowners
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/actor"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/errcode"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc"
+)
+
+func TestResolveOwners(t *testing.T) {
+	defer func() { db.Mocks = db.MockStores{} }()
+
+	users := map[int32]*types.User{
+		1: {ID: 1, Username: "alice"},
+		2: {ID: 2, Username: "bob"},
+		3: {ID: 3, Username: "carol"}, // can't read the repository
+		4: {ID: 4, Username: "gopher"},
+	}
+	account := func(userID int32, serviceType, serviceID, accountData string) *extsvc.ExternalAccount {
+		a := &extsvc.ExternalAccount{UserID: userID}
+		a.ServiceType, a.ServiceID = serviceType, serviceID
+		data := json.RawMessage(accountData)
+		a.AccountData = &data
+		return a
+	}
+	db.Mocks.ExternalAccounts.List = func(opt db.ExternalAccountsListOptions) ([]*extsvc.ExternalAccount, error) {
+		if want := (db.ExternalAccountsListOptions{ServiceType: "github", ServiceID: "https://github.com/", AnyClientID: true}); opt != want {
+			t.Errorf("got options %+v, want %+v", opt, want)
+		}
+		return []*extsvc.ExternalAccount{
+			account(1, "github", "https://github.com/", `{"login":"Alice-GH"}`),
+			account(3, "github", "https://github.com/", `{"login":"carol"}`),
+		}, nil
+	}
+	db.Mocks.Users.GetByID = func(ctx context.Context, id int32) (*types.User, error) {
+		if user, ok := users[id]; ok {
+			return user, nil
+		}
+		return nil, db.NewUserNotFoundError(id)
+	}
+	db.Mocks.Users.GetByVerifiedEmail = func(ctx context.Context, email string) (*types.User, error) {
+		switch email {
+		case "bob@example.com":
+			return users[2], nil
+		case "carol@example.com":
+			return users[3], nil
+		}
+		return nil, db.NewUserNotFoundError(0)
+	}
+	db.Mocks.Repos.Get = func(ctx context.Context, id api.RepoID) (*types.Repo, error) {
+		if actor.FromContext(ctx).UID == 3 {
+			return nil, &errcode.Mock{IsNotFound: true}
+		}
+		return &types.Repo{ID: id}, nil
+	}
+
+	repo := &types.Repo{
+		ID:           1,
+		Name:         "github.com/foo/bar",
+		ExternalRepo: &api.ExternalRepoSpec{ID: "r", ServiceType: "github", ServiceID: "https://github.com/"},
+	}
+	owners, err := ResolveOwners(context.Background(), repo, []string{
+		"@alice-gh",          // resolved through the GitHub account
+		"@gopher",            // a Sourcegraph username, but not a GitHub login
+		"bob@example.com",    // resolved through the verified email
+		"@carol",             // can't read the repository
+		"carol@example.com",  // can't read the repository
+		"@sourcegraph/team",  // teams are not resolved
+		"nobody@example.com", // no such user
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*Owner{
+		{Name: "@alice-gh", User: users[1]},
+		{Name: "@gopher"},
+		{Name: "bob@example.com", User: users[2]},
+		{Name: "@sourcegraph/team"},
+		{Name: "nobody@example.com"},
+	}
+	if !reflect.DeepEqual(owners, want) {
+		t.Errorf("got owners %+v, want %+v", owners, want)
+	}
+	if got, want := OwnerUsers(append(owners, &Owner{Name: "bob@example.com", User: users[2]})), []*types.User{users[1], users[2]}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got owner users %+v, want %+v", got, want)
+	}
+}
+
+func TestResolveOwners_noExternalRepo(t *testing.T) {
+	defer func() { db.Mocks = db.MockStores{} }()
+
+	db.Mocks.ExternalAccounts.List = func(db.ExternalAccountsListOptions) ([]*extsvc.ExternalAccount, error) {
+		t.Fatal("unexpected call to ExternalAccounts.List")
+		return nil, nil
+	}
+	owners, err := ResolveOwners(context.Background(), &types.Repo{ID: 1, Name: "r"}, []string{"@alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []*Owner{{Name: "@alice"}}; !reflect.DeepEqual(owners, want) {
+		t.Errorf("got owners %+v, want %+v", owners, want)
+	}
+}
@@ -0,0 +1,270 @@
+package discussions
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"sourcegraph.com/sourcegraph/go-diff/diff"
+)
+
+// MinAnchorConfidence is the confidence below which a tracked selection is
+// considered outdated: the code it referred to was changed too much (or could
+// not be found) to say where the selection is now.
+const MinAnchorConfidence = 0.8
+
+// Selection is a selection in a file, with a snapshot of the selected lines
+// and the lines around them (see LinesForSelection).
+type Selection struct {
+	LineRange
+	LinesBefore, Lines, LinesAfter []string
+}
+
+// ThreadSelection returns the selection of the thread's target. The target must
+// have a selection (see (*types.DiscussionThreadTargetRepo).HasSelection).
+func ThreadSelection(t *types.DiscussionThreadTargetRepo) Selection {
+	return Selection{
+		LineRange:   LineRange{StartLine: int(*t.StartLine), EndLine: int(*t.EndLine)},
+		LinesBefore: *t.LinesBefore,
+		Lines:       *t.Lines,
+		LinesAfter:  *t.LinesAfter,
+	}
+}
+
+// TrackedSelection is the location of a selection in a newer version of the
+// file.
+type TrackedSelection struct {
+	LineRange
+
+	// Confidence is how likely it is that LineRange refers to the same code as
+	// the original selection, from 0 (not at all) to 1 (certainly).
+	Confidence float64
+}
+
+// Outdated tells if the selection could not be tracked with enough confidence.
+func (s TrackedSelection) Outdated() bool { return s.Confidence < MinAnchorConfidence }
+
+// TrackSelection returns the location of the selection in newContent, a newer
+// version of the file that the selection was made in.
+//
+// If hunks is non-nil, it is the diff of the file from the version that the
+// selection was made in to newContent (an empty, non-nil slice means the file
+// is unchanged), and the selection is mapped through it. If the diff changed
+// the selected lines (or hunks is nil), the selection's snapshot lines are
+// also fuzzily matched against newContent, and the more confident location is
+// returned.
+func TrackSelection(sel Selection, hunks []*diff.Hunk, newContent string) TrackedSelection {
+	var best TrackedSelection
+	if hunks != nil {
+		best = MapLineRangeThroughHunks(sel.LineRange, hunks)
+		if best.Confidence == 1 {
+			return best
+		}
+	}
+
+	near := sel.StartLine
+	if best.Confidence > 0 {
+		near = best.StartLine
+	}
+	if fuzzy := FuzzyMatchSelection(sel, newContent, near); fuzzy.Confidence > best.Confidence {
+		best = fuzzy
+	}
+	return best
+}
+
+// MapLineRangeThroughHunks maps the line range in the old version of a file to
+// the new version of the file, given the diff hunks between the versions. The
+// confidence is the fraction of the selected lines that are unchanged (and not
+// separated by added lines) in the new version.
+func MapLineRangeThroughHunks(r LineRange, hunks []*diff.Hunk) TrackedSelection {
+	mapLine := lineMapper(hunks)
+
+	if r.EndLine <= r.StartLine {
+		// An empty range (such as a cursor position) only has a position.
+		newLine, ok := mapLine(r.StartLine)
+		if !ok {
+			return TrackedSelection{}
+		}
+		return TrackedSelection{LineRange: LineRange{StartLine: newLine, EndLine: newLine}, Confidence: 1}
+	}
+
+	var (
+		kept              int
+		newStart, newLast int
+	)
+	for line := r.StartLine; line < r.EndLine; line++ {
+		newLine, ok := mapLine(line)
+		if !ok {
+			continue
+		}
+		if kept == 0 {
+			newStart = newLine
+		}
+		newLast = newLine
+		kept++
+	}
+	if kept == 0 {
+		return TrackedSelection{}
+	}
+	total := r.EndLine - r.StartLine
+	if span := newLast - newStart + 1; span > total {
+		total = span // lines were added inside the range
+	}
+	return TrackedSelection{
+		LineRange:  LineRange{StartLine: newStart, EndLine: newLast + 1},
+		Confidence: float64(kept) / float64(total),
+	}
+}
+
+// lineMapper returns a function that maps a (zero-based) line number in the old
+// version of a file to the line number in the new version, given the diff
+// hunks between the versions (in order). It returns false if the line was
+// removed or changed.
+func lineMapper(hunks []*diff.Hunk) func(int) (int, bool) {
+	type hunkMapping struct {
+		origStart, origEnd int         // range of old lines covered by the hunk
+		lines              map[int]int // old line -> new line, for unchanged lines in the hunk
+		delta              int         // new line - old line, for lines after the hunk
+	}
+	mappings := make([]hunkMapping, 0, len(hunks))
+	for _, hunk := range hunks {
+		// Hunk line numbers are 1-based, except that an empty side of a hunk
+		// refers to the line before which lines were added or removed.
+		origLine, newLine := int(hunk.OrigStartLine)-1, int(hunk.NewStartLine)-1
+		if hunk.OrigLines == 0 {
+			origLine++
+		}
+		if hunk.NewLines == 0 {
+			newLine++
+		}
+		m := hunkMapping{origStart: origLine, lines: map[int]int{}}
+		for _, line := range bytes.SplitAfter(hunk.Body, []byte("\n")) {
+			if len(line) == 0 {
+				continue
+			}
+			switch line[0] {
+			case ' ':
+				m.lines[origLine] = newLine
+				origLine++
+				newLine++
+			case '-':
+				origLine++
+			case '+':
+				newLine++
+			}
+		}
+		m.origEnd = origLine
+		m.delta = newLine - origLine
+		mappings = append(mappings, m)
+	}
+
+	return func(line int) (int, bool) {
+		delta := 0
+		for _, m := range mappings {
+			if line < m.origStart {
+				break
+			}
+			if line < m.origEnd {
+				newLine, ok := m.lines[line]
+				return newLine, ok
+			}
+			delta = m.delta
+		}
+		return line + delta, true
+	}
+}
+
+// Weights of the selected lines and of the context lines around them in the
+// fuzzy matching score.
+const (
+	fuzzySelectionWeight = 0.7
+	fuzzyContextWeight   = 0.3
+)
+
+// FuzzyMatchSelection returns the location in content that best matches the
+// selection's snapshot lines. The confidence is a similarity score of the
+// selected lines and (with less weight) the lines around them. Of equally good
+// matches, the one closest to the line near is returned.
+func FuzzyMatchSelection(sel Selection, content string, near int) TrackedSelection {
+	if len(sel.Lines) == 0 && len(sel.LinesBefore) == 0 && len(sel.LinesAfter) == 0 {
+		return TrackedSelection{}
+	}
+	lines := strings.Split(content, "\n")
+	lineAt := func(i int) (string, bool) {
+		if i < 0 || i >= len(lines) {
+			return "", false
+		}
+		return lines[i], true
+	}
+	similarityAt := func(want []string, start int) float64 {
+		var sum float64
+		for i, w := range want {
+			if l, ok := lineAt(start + i); ok {
+				sum += lineSimilarity(w, l)
+			}
+		}
+		return sum
+	}
+
+	var (
+		best     TrackedSelection
+		bestDist int
+	)
+	for start := 0; start+len(sel.Lines) <= len(lines); start++ {
+		var selected, context float64
+		if len(sel.Lines) > 0 {
+			selected = similarityAt(sel.Lines, start) / float64(len(sel.Lines))
+		}
+		numContext := len(sel.LinesBefore) + len(sel.LinesAfter)
+		if numContext > 0 {
+			context = (similarityAt(sel.LinesBefore, start-len(sel.LinesBefore)) + similarityAt(sel.LinesAfter, start+len(sel.Lines))) / float64(numContext)
+		}
+		var score float64
+		switch {
+		case len(sel.Lines) > 0 && numContext > 0:
+			score = fuzzySelectionWeight*selected + fuzzyContextWeight*context
+		case len(sel.Lines) > 0:
+			score = selected
+		default:
+			score = context
+		}
+
+		dist := start - near
+		if dist < 0 {
+			dist = -dist
+		}
+		if score > best.Confidence || (score == best.Confidence && score > 0 && dist < bestDist) {
+			best = TrackedSelection{
+				LineRange:  LineRange{StartLine: start, EndLine: start + len(sel.Lines)},
+				Confidence: score,
+			}
+			bestDist = dist
+		}
+	}
+	return best
+}
+
+// lineSimilarity returns the similarity of two lines from 0 (entirely
+// different) to 1 (equal, ignoring leading and trailing whitespace), using the
+// Sørensen–Dice coefficient of their character bigrams.
+func lineSimilarity(a, b string) float64 {
+	a, b = strings.TrimSpace(a), strings.TrimSpace(b)
+	if a == b {
+		return 1
+	}
+	if len(a) < 2 || len(b) < 2 {
+		return 0
+	}
+	bigrams := make(map[string]int, len(a)-1)
+	for i := 0; i < len(a)-1; i++ {
+		bigrams[a[i:i+2]]++
+	}
+	var common int
+	for i := 0; i < len(b)-1; i++ {
+		if bigrams[b[i:i+2]] > 0 {
+			bigrams[b[i:i+2]]--
+			common++
+		}
+	}
+	return 2 * float64(common) / float64(len(a)-1+len(b)-1)
+}
@@ -0,0 +1,176 @@
+package discussions
+
+import (
+	"reflect"
+	"testing"
+
+	"sourcegraph.com/sourcegraph/go-diff/diff"
+)
+
+func TestMapLineRangeThroughHunks(t *testing.T) {
+	// The old file has lines "0" through "9".
+	tests := []struct {
+		name      string
+		selection LineRange
+		hunks     []*diff.Hunk
+		want      TrackedSelection
+	}{
+		{
+			name:      "unchanged",
+			selection: LineRange{StartLine: 3, EndLine: 5},
+			hunks:     []*diff.Hunk{},
+			want:      TrackedSelection{LineRange: LineRange{StartLine: 3, EndLine: 5}, Confidence: 1},
+		},
+		{
+			name:      "added_lines_before",
+			selection: LineRange{StartLine: 3, EndLine: 5},
+			hunks: []*diff.Hunk{
+				{OrigStartLine: 1, OrigLines: 1, NewStartLine: 1, NewLines: 3, Body: []byte(" 0\n+a\n+b\n")},
+			},
+			want: TrackedSelection{LineRange: LineRange{StartLine: 5, EndLine: 7}, Confidence: 1},
+		},
+		{
+			name:      "pure_insertion_before",
+			selection: LineRange{StartLine: 3, EndLine: 5},
+			hunks: []*diff.Hunk{
+				{OrigStartLine: 0, OrigLines: 0, NewStartLine: 1, NewLines: 1, Body: []byte("+a\n")},
+			},
+			want: TrackedSelection{LineRange: LineRange{StartLine: 4, EndLine: 6}, Confidence: 1},
+		},
+		{
+			name:      "removed_lines_before",
+			selection: LineRange{StartLine: 3, EndLine: 5},
+			hunks: []*diff.Hunk{
+				{OrigStartLine: 1, OrigLines: 3, NewStartLine: 1, NewLines: 1, Body: []byte(" 0\n-1\n-2\n")},
+			},
+			want: TrackedSelection{LineRange: LineRange{StartLine: 1, EndLine: 3}, Confidence: 1},
+		},
+		{
+			name:      "changes_after",
+			selection: LineRange{StartLine: 3, EndLine: 5},
+			hunks: []*diff.Hunk{
+				{OrigStartLine: 8, OrigLines: 1, NewStartLine: 8, NewLines: 1, Body: []byte("-7\n+x\n")},
+			},
+			want: TrackedSelection{LineRange: LineRange{StartLine: 3, EndLine: 5}, Confidence: 1},
+		},
+		{
+			name:      "changed_selected_line",
+			selection: LineRange{StartLine: 3, EndLine: 5},
+			hunks: []*diff.Hunk{
+				{OrigStartLine: 3, OrigLines: 3, NewStartLine: 3, NewLines: 3, Body: []byte(" 2\n-3\n+x\n 4\n")},
+			},
+			want: TrackedSelection{LineRange: LineRange{StartLine: 4, EndLine: 5}, Confidence: 0.5},
+		},
+		{
+			name:      "added_line_inside",
+			selection: LineRange{StartLine: 3, EndLine: 5},
+			hunks: []*diff.Hunk{
+				{OrigStartLine: 4, OrigLines: 2, NewStartLine: 4, NewLines: 3, Body: []byte(" 3\n+x\n 4\n")},
+			},
+			want: TrackedSelection{LineRange: LineRange{StartLine: 3, EndLine: 6}, Confidence: 2.0 / 3},
+		},
+		{
+			name:      "removed_selection",
+			selection: LineRange{StartLine: 3, EndLine: 5},
+			hunks: []*diff.Hunk{
+				{OrigStartLine: 4, OrigLines: 2, NewStartLine: 3, NewLines: 0, Body: []byte("-3\n-4\n")},
+			},
+			want: TrackedSelection{},
+		},
+		{
+			name:      "empty_selection",
+			selection: LineRange{StartLine: 3, EndLine: 3},
+			hunks: []*diff.Hunk{
+				{OrigStartLine: 1, OrigLines: 1, NewStartLine: 1, NewLines: 2, Body: []byte(" 0\n+a\n")},
+			},
+			want: TrackedSelection{LineRange: LineRange{StartLine: 4, EndLine: 4}, Confidence: 1},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := MapLineRangeThroughHunks(test.selection, test.hunks)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestTrackSelection(t *testing.T) {
+	sel := Selection{
+		LineRange:   LineRange{StartLine: 3, EndLine: 5},
+		LinesBefore: []string{"func a() {", "\tx := 1", "\ty := 2"},
+		Lines:       []string{"\tfoo(x, y)", "\tbar(x)"},
+		LinesAfter:  []string{"\treturn", "}", ""},
+	}
+
+	tests := []struct {
+		name         string
+		hunks        []*diff.Hunk
+		newContent   string
+		want         LineRange
+		wantOutdated bool
+	}{
+		{
+			name:       "moved_without_diff",
+			newContent: "package p\n\nfunc a() {\n\tx := 1\n\ty := 2\n\tfoo(x, y)\n\tbar(x)\n\treturn\n}\n",
+			want:       LineRange{StartLine: 5, EndLine: 7},
+		},
+		{
+			name:       "reindented",
+			newContent: "func a() {\n  x := 1\n  y := 2\n  foo(x, y)\n  bar(x)\n  return\n}\n",
+			want:       LineRange{StartLine: 3, EndLine: 5},
+		},
+		{
+			name:       "slightly_changed",
+			newContent: "func a() {\n\tx := 1\n\ty := 2\n\tfoo(x, y, z)\n\tbar(x)\n\treturn\n}\n",
+			want:       LineRange{StartLine: 3, EndLine: 5},
+		},
+		{
+			name: "changed_in_diff_found_by_fuzzy_match",
+			hunks: []*diff.Hunk{
+				{OrigStartLine: 4, OrigLines: 1, NewStartLine: 4, NewLines: 1, Body: []byte("-\tfoo(x, y)\n+\tfoo(x, y, z)\n")},
+			},
+			newContent: "func a() {\n\tx := 1\n\ty := 2\n\tfoo(x, y, z)\n\tbar(x)\n\treturn\n}\n",
+			want:       LineRange{StartLine: 3, EndLine: 5},
+		},
+		{
+			name:         "removed",
+			newContent:   "func a() {\n\tx := 1\n\treturn\n}\n",
+			wantOutdated: true,
+		},
+		{
+			name:         "rewritten",
+			newContent:   "func b(ctx context.Context) error {\n\treturn nil\n}\n",
+			wantOutdated: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := TrackSelection(sel, test.hunks, test.newContent)
+			if got.Outdated() != test.wantOutdated {
+				t.Fatalf("got outdated %v (confidence %v), want %v", got.Outdated(), got.Confidence, test.wantOutdated)
+			}
+			if !test.wantOutdated && got.LineRange != test.want {
+				t.Errorf("got %+v, want %+v", got.LineRange, test.want)
+			}
+		})
+	}
+}
+
+func TestLineSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"foo(x)", "  foo(x)", 1},
+		{"abcd", "wxyz", 0},
+		{"abc", "abd", 0.5},
+		{"", "x", 0},
+	}
+	for _, test := range tests {
+		if got := lineSimilarity(test.a, test.b); got != test.want {
+			t.Errorf("lineSimilarity(%q, %q) = %v, want %v", test.a, test.b, got, test.want)
+		}
+	}
+}
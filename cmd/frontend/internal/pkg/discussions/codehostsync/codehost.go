@@ -0,0 +1,332 @@
+package codehostsync
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc/github"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc/gitlab"
+	log15 "gopkg.in/inconshreveable/log15.v2"
+)
+
+// codeHost is a code host on which discussion threads are mirrored as pull
+// request review comments.
+//
+// Methods that post comments accept the OAuth access token of the author's
+// code host account. If it is empty, the token of the code host connection is
+// used.
+type codeHost interface {
+	spec() codeHostKey
+
+	// findPullRequest returns the open pull request whose head is the branch
+	// of the repository, or nil if there is none.
+	findPullRequest(ctx context.Context, repo *api.ExternalRepoSpec, branch string) (*pullRequest, error)
+
+	// createThread starts a thread of review comments on the lines (1-based)
+	// of the file in the pull request's head commit.
+	createThread(ctx context.Context, repo *api.ExternalRepoSpec, pr *pullRequest, path string, startLine, endLine int32, token, body string) (threadID, commentID string, err error)
+
+	// listThread lists the comments in the thread (including the first one),
+	// oldest first.
+	listThread(ctx context.Context, repo *api.ExternalRepoSpec, number int32, threadID string) ([]*externalComment, error)
+
+	// reply adds a comment to the thread.
+	reply(ctx context.Context, repo *api.ExternalRepoSpec, number int32, threadID, token, body string) (commentID string, err error)
+}
+
+// codeHostKey identifies a code host by the ServiceType and ServiceID of its
+// repositories' api.ExternalRepoSpec.
+type codeHostKey struct {
+	serviceType, serviceID string
+}
+
+type pullRequest struct {
+	Number int32 // GitHub pull request number or GitLab merge request IID
+
+	BaseCommitID, StartCommitID, HeadCommitID string
+}
+
+// externalComment is a pull request review comment on a code host.
+type externalComment struct {
+	ID              string
+	AuthorAccountID string // the ID of the author's account (extsvc.ExternalAccountSpec.AccountID)
+	Body            string
+}
+
+// configuredCodeHosts returns the GitHub and GitLab connections on which
+// discussion threads can be mirrored.
+func configuredCodeHosts(ctx context.Context) (map[codeHostKey]codeHost, error) {
+	hosts := map[codeHostKey]codeHost{}
+
+	githubs, err := db.ExternalServices.ListGitHubConnections(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range githubs {
+		baseURL, err := url.Parse(c.Url)
+		if err != nil {
+			log15.Warn("discussions: code host sync worker: invalid GitHub URL", "url", c.Url, "error", err)
+			continue
+		}
+		baseURL = extsvc.NormalizeBaseURL(baseURL)
+		apiURL, _ := github.APIRoot(baseURL)
+		h := &githubCodeHost{
+			key:    codeHostKey{serviceType: github.ServiceType, serviceID: baseURL.String()},
+			client: github.NewClient(apiURL, c.Token, nil),
+		}
+		hosts[h.key] = h
+	}
+
+	gitlabs, err := db.ExternalServices.ListGitLabConnections(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range gitlabs {
+		baseURL, err := url.Parse(c.Url)
+		if err != nil {
+			log15.Warn("discussions: code host sync worker: invalid GitLab URL", "url", c.Url, "error", err)
+			continue
+		}
+		baseURL = extsvc.NormalizeBaseURL(baseURL)
+		h := &gitlabCodeHost{
+			key:      codeHostKey{serviceType: gitlab.ServiceType, serviceID: baseURL.String()},
+			provider: gitlab.NewClientProvider(baseURL, nil),
+			token:    c.Token,
+		}
+		hosts[h.key] = h
+	}
+	return hosts, nil
+}
+
+type githubCodeHost struct {
+	key    codeHostKey
+	client *github.Client
+
+	// nameWithOwners caches the result of nameWithOwner by the repository's
+	// ID. Code hosts are created for each sync, so repository renames are
+	// picked up by the next sync.
+	nameWithOwners map[string]string
+}
+
+func (h *githubCodeHost) spec() codeHostKey { return h.key }
+
+// nameWithOwner returns the owner and name of the repository, which the REST
+// API (unlike the GraphQL API) identifies repositories by.
+func (h *githubCodeHost) nameWithOwner(ctx context.Context, repo *api.ExternalRepoSpec) (owner, name string, err error) {
+	nameWithOwner, ok := h.nameWithOwners[repo.ID]
+	if !ok {
+		r, err := h.client.GetRepositoryByNodeID(ctx, "", repo.ID)
+		if err != nil {
+			return "", "", err
+		}
+		nameWithOwner = r.NameWithOwner
+		if h.nameWithOwners == nil {
+			h.nameWithOwners = map[string]string{}
+		}
+		h.nameWithOwners[repo.ID] = nameWithOwner
+	}
+	return github.SplitRepositoryNameWithOwner(nameWithOwner)
+}
+
+func (h *githubCodeHost) findPullRequest(ctx context.Context, repo *api.ExternalRepoSpec, branch string) (*pullRequest, error) {
+	owner, name, err := h.nameWithOwner(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	prs, err := h.client.ListOpenPullRequestsForBranch(ctx, "", owner, name, branch)
+	if err != nil {
+		return nil, err
+	}
+	for _, pr := range prs {
+		if pr.Head.Ref == branch {
+			return &pullRequest{Number: pr.Number, HeadCommitID: pr.Head.SHA}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (h *githubCodeHost) createThread(ctx context.Context, repo *api.ExternalRepoSpec, pr *pullRequest, path string, startLine, endLine int32, token, body string) (threadID, commentID string, err error) {
+	owner, name, err := h.nameWithOwner(ctx, repo)
+	if err != nil {
+		return "", "", err
+	}
+	newComment := &github.NewReviewComment{
+		Body:     body,
+		CommitID: pr.HeadCommitID,
+		Path:     path,
+		Line:     endLine,
+		Side:     "RIGHT",
+	}
+	if startLine < endLine {
+		newComment.StartLine = startLine
+		newComment.StartSide = "RIGHT"
+	}
+	comment, err := h.client.CreateReviewComment(ctx, token, owner, name, pr.Number, newComment)
+	if err != nil {
+		return "", "", err
+	}
+	id := strconv.FormatInt(comment.ID, 10)
+	return id, id, nil
+}
+
+func (h *githubCodeHost) listThread(ctx context.Context, repo *api.ExternalRepoSpec, number int32, threadID string) ([]*externalComment, error) {
+	rootID, err := strconv.ParseInt(threadID, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	owner, name, err := h.nameWithOwner(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	var thread []*externalComment
+	for page := 1; ; page++ {
+		comments, hasNextPage, err := h.client.ListReviewComments(ctx, "", owner, name, number, page)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range comments {
+			if c.ID == rootID || c.InReplyToID == rootID {
+				thread = append(thread, &externalComment{
+					ID:              strconv.FormatInt(c.ID, 10),
+					AuthorAccountID: strconv.FormatInt(c.User.ID, 10),
+					Body:            c.Body,
+				})
+			}
+		}
+		if !hasNextPage {
+			return thread, nil
+		}
+	}
+}
+
+func (h *githubCodeHost) reply(ctx context.Context, repo *api.ExternalRepoSpec, number int32, threadID, token, body string) (commentID string, err error) {
+	rootID, err := strconv.ParseInt(threadID, 10, 64)
+	if err != nil {
+		return "", err
+	}
+	owner, name, err := h.nameWithOwner(ctx, repo)
+	if err != nil {
+		return "", err
+	}
+	comment, err := h.client.CreateReviewComment(ctx, token, owner, name, number, &github.NewReviewComment{Body: body, InReplyTo: rootID})
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(comment.ID, 10), nil
+}
+
+type gitlabCodeHost struct {
+	key      codeHostKey
+	provider *gitlab.ClientProvider
+	token    string // personal access token of the connection
+}
+
+func (h *gitlabCodeHost) spec() codeHostKey { return h.key }
+
+// client returns a client authenticated by the user's OAuth token, or (if
+// empty) by the connection's personal access token.
+func (h *gitlabCodeHost) client(token string) *gitlab.Client {
+	if token != "" {
+		return h.provider.GetOAuthClient(token)
+	}
+	return h.provider.GetPATClient(h.token, "")
+}
+
+func (h *gitlabCodeHost) findPullRequest(ctx context.Context, repo *api.ExternalRepoSpec, branch string) (*pullRequest, error) {
+	projID, err := strconv.Atoi(repo.ID)
+	if err != nil {
+		return nil, err
+	}
+	mrs, err := h.client("").ListOpenMergeRequestsForBranch(ctx, projID, branch)
+	if err != nil {
+		return nil, err
+	}
+	for _, mr := range mrs {
+		if mr.SourceBranch != branch {
+			continue
+		}
+		// Only single merge requests include the diff refs, which are needed
+		// to position comments on the diff.
+		mr, err := h.client("").GetMergeRequest(ctx, projID, mr.IID)
+		if err != nil {
+			return nil, err
+		}
+		if mr.DiffRefs == nil {
+			return nil, nil
+		}
+		return &pullRequest{
+			Number:        mr.IID,
+			BaseCommitID:  mr.DiffRefs.BaseSHA,
+			StartCommitID: mr.DiffRefs.StartSHA,
+			HeadCommitID:  mr.DiffRefs.HeadSHA,
+		}, nil
+	}
+	return nil, nil
+}
+
+func (h *gitlabCodeHost) createThread(ctx context.Context, repo *api.ExternalRepoSpec, pr *pullRequest, path string, startLine, endLine int32, token, body string) (threadID, commentID string, err error) {
+	projID, err := strconv.Atoi(repo.ID)
+	if err != nil {
+		return "", "", err
+	}
+	// GitLab diff comments are on a single line, so we use the last line of
+	// the selection (like GitHub does).
+	discussion, err := h.client(token).CreateMergeRequestDiscussion(ctx, projID, pr.Number, body, &gitlab.DiffPosition{
+		DiffRefs: gitlab.DiffRefs{
+			BaseSHA:  pr.BaseCommitID,
+			StartSHA: pr.StartCommitID,
+			HeadSHA:  pr.HeadCommitID,
+		},
+		PositionType: "text",
+		OldPath:      path,
+		NewPath:      path,
+		NewLine:      endLine,
+	})
+	if err != nil {
+		return "", "", err
+	}
+	if len(discussion.Notes) == 0 {
+		return "", "", errors.New("GitLab discussion has no notes")
+	}
+	return discussion.ID, strconv.FormatInt(discussion.Notes[0].ID, 10), nil
+}
+
+func (h *gitlabCodeHost) listThread(ctx context.Context, repo *api.ExternalRepoSpec, number int32, threadID string) ([]*externalComment, error) {
+	projID, err := strconv.Atoi(repo.ID)
+	if err != nil {
+		return nil, err
+	}
+	discussion, err := h.client("").GetMergeRequestDiscussion(ctx, projID, number, threadID)
+	if err != nil {
+		return nil, err
+	}
+	var thread []*externalComment
+	for _, note := range discussion.Notes {
+		if note.System {
+			continue
+		}
+		thread = append(thread, &externalComment{
+			ID:              strconv.FormatInt(note.ID, 10),
+			AuthorAccountID: strconv.FormatInt(int64(note.Author.ID), 10),
+			Body:            note.Body,
+		})
+	}
+	return thread, nil
+}
+
+func (h *gitlabCodeHost) reply(ctx context.Context, repo *api.ExternalRepoSpec, number int32, threadID, token, body string) (commentID string, err error) {
+	projID, err := strconv.Atoi(repo.ID)
+	if err != nil {
+		return "", err
+	}
+	note, err := h.client(token).AddMergeRequestDiscussionNote(ctx, projID, number, threadID, body)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(note.ID, 10), nil
+}
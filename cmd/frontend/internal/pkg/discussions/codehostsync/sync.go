@@ -0,0 +1,375 @@
+package codehostsync
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/discussions"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/vcs/git"
+	"golang.org/x/oauth2"
+	log15 "gopkg.in/inconshreveable/log15.v2"
+)
+
+// syncThread mirrors the thread to a review comment on the matching open pull
+// request (if it hasn't been mirrored yet), imports the replies made on the
+// code host, and posts the comments made on Sourcegraph as replies.
+func syncThread(ctx context.Context, host codeHost, repo *types.Repo, thread *types.DiscussionThread) error {
+	comments, err := db.DiscussionComments.List(ctx, &db.DiscussionCommentsListOptions{ThreadID: &thread.ID})
+	if err != nil {
+		return errors.Wrap(err, "DiscussionComments.List")
+	}
+	if len(comments) == 0 {
+		return nil
+	}
+	links, err := db.DiscussionCodeHostComments.ListByThread(ctx, thread.ID)
+	if err != nil {
+		return errors.Wrap(err, "DiscussionCodeHostComments.ListByThread")
+	}
+
+	if len(links) == 0 {
+		link, err := startThread(ctx, host, repo, thread, comments[0])
+		if err != nil || link == nil {
+			return err
+		}
+		links = append(links, link)
+	}
+	root := links[0]
+	if root.ExternalThreadID == "" {
+		// The thread's first comment may or may not have been posted, so we
+		// can't continue without risking to post it twice.
+		log15.Warn("discussions: code host sync worker: skipping thread whose first comment is pending", "thread", thread.ID)
+		return nil
+	}
+
+	// Import the replies first, so that they are linked (and not posted back
+	// to the code host) when the new Sourcegraph comments are posted.
+	external, err := host.listThread(ctx, repo.ExternalRepo, root.PullRequestNumber, root.ExternalThreadID)
+	if err != nil {
+		return errors.Wrap(err, "listThread")
+	}
+	if err := reconcilePending(ctx, thread, comments, links, external); err != nil {
+		return err
+	}
+	for _, reply := range unlinkedExternalComments(external, links) {
+		if err := importReply(ctx, host, thread, root, reply); err != nil {
+			return err
+		}
+	}
+
+	for _, comment := range unlinkedComments(comments, links) {
+		link := *root
+		link.CommentID = comment.ID
+		link.ExternalCommentID = ""
+		err := postPending(ctx, &link, func() (threadID, commentID string, err error) {
+			err = postComment(ctx, host, thread, comment, func(token, body string) (err error) {
+				commentID, err = host.reply(ctx, repo.ExternalRepo, root.PullRequestNumber, root.ExternalThreadID, token, body)
+				return err
+			})
+			return root.ExternalThreadID, commentID, errors.Wrap(err, "reply")
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// postPending records the pending link before calling post to post its
+// comment to the code host, and completes the link with the IDs that post
+// returns. The link is deleted if posting fails, so that it is retried.
+//
+// If the link can't be completed, it stays pending so that the comment isn't
+// posted again. reconcilePending completes it when the thread is synced
+// again.
+func postPending(ctx context.Context, link *types.DiscussionCodeHostComment, post func() (threadID, commentID string, err error)) error {
+	if err := db.DiscussionCodeHostComments.Create(ctx, link); err != nil {
+		return errors.Wrap(err, "DiscussionCodeHostComments.Create")
+	}
+	threadID, commentID, err := post()
+	if err != nil {
+		if err2 := db.DiscussionCodeHostComments.Delete(ctx, link.ID); err2 != nil {
+			log15.Error("discussions: code host sync worker: unable to delete pending link", "comment", link.CommentID, "error", err2)
+		}
+		return err
+	}
+	link.ExternalThreadID, link.ExternalCommentID = threadID, commentID
+	if err := db.DiscussionCodeHostComments.SetExternalIDs(ctx, link.ID, threadID, commentID); err != nil {
+		return errors.Wrap(err, "DiscussionCodeHostComments.SetExternalIDs")
+	}
+	return nil
+}
+
+// reconcilePending completes the pending links of replies whose posting
+// succeeded but whose link could not be completed, by finding the code host
+// comments with the bodies that the replies were posted with. Otherwise,
+// those code host comments would be imported as new comments.
+func reconcilePending(ctx context.Context, thread *types.DiscussionThread, comments []*types.DiscussionComment, links []*types.DiscussionCodeHostComment, external []*externalComment) error {
+	byID := make(map[int64]*types.DiscussionComment, len(comments))
+	for _, c := range comments {
+		byID[c.ID] = c
+	}
+	unlinked := unlinkedExternalComments(external, links)
+	for _, link := range links {
+		comment, ok := byID[link.CommentID]
+		if link.ExternalCommentID != "" || !ok {
+			continue
+		}
+		bodies, err := commentBodies(ctx, thread, comment)
+		if err != nil {
+			return err
+		}
+		for i, c := range unlinked {
+			if c.Body != bodies[0] && c.Body != bodies[1] {
+				continue
+			}
+			if err := db.DiscussionCodeHostComments.SetExternalIDs(ctx, link.ID, link.ExternalThreadID, c.ID); err != nil {
+				return errors.Wrap(err, "DiscussionCodeHostComments.SetExternalIDs")
+			}
+			link.ExternalCommentID = c.ID
+			unlinked = append(unlinked[:i], unlinked[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// startThread mirrors the thread's first comment to a review comment on the
+// open pull request whose head is the thread's branch. It returns nil if there
+// is no such pull request, or if the thread's selection can't be found in the
+// pull request's head commit.
+func startThread(ctx context.Context, host codeHost, repo *types.Repo, thread *types.DiscussionThread, first *types.DiscussionComment) (*types.DiscussionCodeHostComment, error) {
+	t := thread.TargetRepo
+	pr, err := host.findPullRequest(ctx, repo.ExternalRepo, *t.Branch)
+	if err != nil {
+		return nil, errors.Wrap(err, "findPullRequest")
+	}
+	if pr == nil {
+		return nil, nil
+	}
+	lines, ok, err := headLines(ctx, repo, t, pr.HeadCommitID)
+	if err != nil || !ok {
+		return nil, err
+	}
+	startLine, endLine := reviewLines(lines)
+
+	link := &types.DiscussionCodeHostComment{
+		ThreadID:          thread.ID,
+		CommentID:         first.ID,
+		ServiceType:       repo.ExternalRepo.ServiceType,
+		ServiceID:         repo.ExternalRepo.ServiceID,
+		ExternalRepoID:    repo.ExternalRepo.ID,
+		PullRequestNumber: pr.Number,
+	}
+	err = postPending(ctx, link, func() (threadID, commentID string, err error) {
+		err = postComment(ctx, host, thread, first, func(token, body string) (err error) {
+			threadID, commentID, err = host.createThread(ctx, repo.ExternalRepo, pr, *t.Path, startLine, endLine, token, body)
+			return err
+		})
+		return threadID, commentID, errors.Wrap(err, "createThread")
+	})
+	if err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+// headLines returns the lines that the thread's selection refers to in the
+// pull request's head commit. It returns false if the file or the selection
+// can't be found there (e.g. because the commit hasn't been fetched yet).
+func headLines(ctx context.Context, repo *types.Repo, t *types.DiscussionThreadTargetRepo, headCommitID string) (discussions.LineRange, bool, error) {
+	sel := discussions.ThreadSelection(t)
+	if t.Revision != nil && *t.Revision == headCommitID {
+		return sel.LineRange, true, nil
+	}
+	cachedRepo, err := backend.CachedGitRepo(ctx, repo)
+	if err != nil {
+		return discussions.LineRange{}, false, err
+	}
+	content, err := git.ReadFile(ctx, *cachedRepo, api.CommitID(headCommitID), *t.Path)
+	if err != nil {
+		log15.Debug("discussions: code host sync worker: unable to read file in pull request head commit", "repo", repo.Name, "commit", headCommitID, "path", *t.Path, "error", err)
+		return discussions.LineRange{}, false, nil
+	}
+	tracked := discussions.TrackSelection(sel, nil, string(content))
+	if tracked.Outdated() {
+		return discussions.LineRange{}, false, nil
+	}
+	return tracked.LineRange, true, nil
+}
+
+// reviewLines returns the 1-based first and last lines of the line range, as
+// used by code host review comments.
+func reviewLines(r discussions.LineRange) (startLine, endLine int32) {
+	startLine, endLine = int32(r.StartLine)+1, int32(r.EndLine)
+	if endLine < startLine {
+		endLine = startLine // an empty range (such as a cursor position)
+	}
+	return startLine, endLine
+}
+
+// importReply adds a reply made on the code host to the thread. Replies by
+// code host users who have not signed in to Sourcegraph with their code host
+// account are skipped, because comments must have a Sourcegraph author.
+func importReply(ctx context.Context, host codeHost, thread *types.DiscussionThread, root *types.DiscussionCodeHostComment, reply *externalComment) error {
+	userID, err := userForAccount(ctx, root.ServiceType, root.ServiceID, reply.AuthorAccountID)
+	if err != nil {
+		return err
+	}
+	if userID == 0 {
+		log15.Debug("discussions: code host sync worker: skipping reply by unknown code host user", "thread", thread.ID, "account", reply.AuthorAccountID)
+		return nil
+	}
+	comment := &types.DiscussionComment{
+		ThreadID:     thread.ID,
+		AuthorUserID: userID,
+		Contents:     reply.Body,
+	}
+	link := *root
+	link.ExternalCommentID = reply.ID
+	if err := db.DiscussionCodeHostComments.CreateImported(ctx, comment, &link); err != nil {
+		return errors.Wrap(err, "DiscussionCodeHostComments.CreateImported")
+	}
+	discussions.NotifyNewComment(thread, comment)
+	return nil
+}
+
+// unlinkedExternalComments returns the code host comments that are not linked
+// to a discussion comment (i.e., that were made on the code host and have not
+// been imported yet).
+func unlinkedExternalComments(external []*externalComment, links []*types.DiscussionCodeHostComment) []*externalComment {
+	linked := make(map[string]bool, len(links))
+	for _, link := range links {
+		linked[link.ExternalCommentID] = true
+	}
+	var unlinked []*externalComment
+	for _, c := range external {
+		if !linked[c.ID] {
+			unlinked = append(unlinked, c)
+		}
+	}
+	return unlinked
+}
+
+// unlinkedComments returns the discussion comments that are not linked to a
+// code host comment (i.e., that were made on Sourcegraph and have not been
+// posted to the code host yet).
+func unlinkedComments(comments []*types.DiscussionComment, links []*types.DiscussionCodeHostComment) []*types.DiscussionComment {
+	linked := make(map[int64]bool, len(links))
+	for _, link := range links {
+		linked[link.CommentID] = true
+	}
+	var unlinked []*types.DiscussionComment
+	for _, c := range comments {
+		if !linked[c.ID] {
+			unlinked = append(unlinked, c)
+		}
+	}
+	return unlinked
+}
+
+// postComment posts the comment to the code host with post. It is posted with
+// the author's own code host account if they have signed in with it, and
+// otherwise (or if that fails, e.g. because the author can't comment on the
+// pull request) with the connection's token, on behalf of the author.
+func postComment(ctx context.Context, host codeHost, thread *types.DiscussionThread, comment *types.DiscussionComment, post func(token, body string) error) error {
+	bodies, err := commentBodies(ctx, thread, comment)
+	if err != nil {
+		return err
+	}
+
+	spec := host.spec()
+	if token, err := userToken(ctx, comment.AuthorUserID, spec.serviceType, spec.serviceID); err != nil {
+		return err
+	} else if token != "" {
+		err := post(token, bodies[0])
+		if err == nil {
+			return nil
+		}
+		log15.Warn("discussions: code host sync worker: unable to post comment with author's code host account", "comment", comment.ID, "error", err)
+	}
+	return post("", bodies[1])
+}
+
+// commentBodies returns the bodies that the comment is posted with to the code
+// host: with the author's own code host account, and on behalf of the author.
+func commentBodies(ctx context.Context, thread *types.DiscussionThread, comment *types.DiscussionComment) ([2]string, error) {
+	u, err := discussions.URLToInlineComment(ctx, thread, comment)
+	if err != nil {
+		return [2]string{}, errors.Wrap(err, "URLToInlineComment")
+	}
+	var url string
+	if u != nil {
+		url = u.String()
+	}
+	author, err := db.Users.GetByID(ctx, comment.AuthorUserID)
+	if err != nil {
+		return [2]string{}, errors.Wrap(err, "Users.GetByID")
+	}
+	return [2]string{formatComment("", comment.Contents, url), formatComment(author.Username, comment.Contents, url)}, nil
+}
+
+// formatComment returns the body of a code host comment for a discussion
+// comment. If username is non-empty, the comment is posted on behalf of that
+// Sourcegraph user.
+func formatComment(username, contents, url string) string {
+	if username == "" {
+		if url == "" {
+			return contents
+		}
+		return fmt.Sprintf("%s\n\n[View on Sourcegraph](%s)", contents, url)
+	}
+	if url == "" {
+		return fmt.Sprintf("**@%s** commented on Sourcegraph:\n\n%s", username, contents)
+	}
+	return fmt.Sprintf("**@%s** commented on [Sourcegraph](%s):\n\n%s", username, url, contents)
+}
+
+// userToken returns the OAuth access token of the user's account on the code
+// host, or an empty string if the user has not signed in with one.
+func userToken(ctx context.Context, userID int32, serviceType, serviceID string) (string, error) {
+	accounts, err := db.ExternalAccounts.List(ctx, db.ExternalAccountsListOptions{
+		UserID:      userID,
+		ServiceType: serviceType,
+		ServiceID:   serviceID,
+		AnyClientID: true,
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "ExternalAccounts.List")
+	}
+	for _, account := range accounts {
+		if account.AuthData == nil {
+			continue
+		}
+		var tok oauth2.Token
+		if err := account.GetAuthData(&tok); err != nil {
+			continue
+		}
+		if tok.AccessToken != "" {
+			return tok.AccessToken, nil
+		}
+	}
+	return "", nil
+}
+
+// userForAccount returns the ID of the Sourcegraph user who has signed in with
+// the code host account, or 0 if there is none.
+func userForAccount(ctx context.Context, serviceType, serviceID, accountID string) (int32, error) {
+	accounts, err := db.ExternalAccounts.List(ctx, db.ExternalAccountsListOptions{
+		ServiceType: serviceType,
+		ServiceID:   serviceID,
+		AnyClientID: true,
+		AccountID:   accountID,
+	})
+	if err != nil {
+		return 0, errors.Wrap(err, "ExternalAccounts.List")
+	}
+	if len(accounts) == 0 {
+		return 0, nil
+	}
+	return accounts[0].UserID, nil
+}
@@ -0,0 +1,122 @@
+package codehostsync
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/discussions"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+)
+
+func TestReviewLines(t *testing.T) {
+	tests := []struct {
+		lines              discussions.LineRange
+		wantStart, wantEnd int32
+	}{
+		{discussions.LineRange{StartLine: 3, EndLine: 4}, 4, 4},
+		{discussions.LineRange{StartLine: 3, EndLine: 6}, 4, 6},
+		{discussions.LineRange{StartLine: 3, EndLine: 3}, 4, 4},
+		{discussions.LineRange{StartLine: 0, EndLine: 1}, 1, 1},
+	}
+	for _, test := range tests {
+		start, end := reviewLines(test.lines)
+		if start != test.wantStart || end != test.wantEnd {
+			t.Errorf("reviewLines(%+v) = %d, %d, want %d, %d", test.lines, start, end, test.wantStart, test.wantEnd)
+		}
+	}
+}
+
+func TestFormatComment(t *testing.T) {
+	tests := []struct {
+		name, username, url, want string
+	}{
+		{
+			name: "own account",
+			url:  "https://sourcegraph.example.com/r/-/blob/a.go#tab=discussions&threadID=1&commentID=2",
+			want: "Looks good\n\n[View on Sourcegraph](https://sourcegraph.example.com/r/-/blob/a.go#tab=discussions&threadID=1&commentID=2)",
+		},
+		{
+			name:     "on behalf of user",
+			username: "alice",
+			url:      "https://sourcegraph.example.com/r/-/blob/a.go#tab=discussions&threadID=1&commentID=2",
+			want:     "**@alice** commented on [Sourcegraph](https://sourcegraph.example.com/r/-/blob/a.go#tab=discussions&threadID=1&commentID=2):\n\nLooks good",
+		},
+		{
+			name:     "no url",
+			username: "alice",
+			want:     "**@alice** commented on Sourcegraph:\n\nLooks good",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := formatComment(test.username, "Looks good", test.url); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestUnlinked(t *testing.T) {
+	links := []*types.DiscussionCodeHostComment{
+		{CommentID: 1, ExternalCommentID: "100"}, // mirrored thread
+		{CommentID: 3, ExternalCommentID: "102"}, // imported reply
+		{CommentID: 4},                           // pending reply
+	}
+
+	external := []*externalComment{{ID: "100"}, {ID: "101"}, {ID: "102"}, {ID: "103"}}
+	if got, want := unlinkedExternalComments(external, links), []*externalComment{{ID: "101"}, {ID: "103"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got unlinked external comments %+v, want %+v", got, want)
+	}
+
+	comments := []*types.DiscussionComment{{ID: 1}, {ID: 2}, {ID: 3}, {ID: 4}}
+	if got, want := unlinkedComments(comments, links), []*types.DiscussionComment{{ID: 2}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got unlinked comments %+v, want %+v", got, want)
+	}
+}
+
+func TestSyncable(t *testing.T) {
+	strPtr := func(s string) *string { return &s }
+	i32 := func(i int32) *int32 { return &i }
+	selection := func(t *types.DiscussionThreadTargetRepo) *types.DiscussionThreadTargetRepo {
+		t.StartLine, t.EndLine, t.StartCharacter, t.EndCharacter = i32(1), i32(2), i32(0), i32(0)
+		t.LinesBefore, t.Lines, t.LinesAfter = &[]string{}, &[]string{"a"}, &[]string{}
+		return t
+	}
+	now := time.Now()
+
+	tests := []struct {
+		name   string
+		thread *types.DiscussionThread
+		want   bool
+	}{
+		{
+			name:   "selection on branch",
+			thread: &types.DiscussionThread{TargetRepo: selection(&types.DiscussionThreadTargetRepo{Path: strPtr("a.go"), Branch: strPtr("b")})},
+			want:   true,
+		},
+		{
+			name:   "no branch",
+			thread: &types.DiscussionThread{TargetRepo: selection(&types.DiscussionThreadTargetRepo{Path: strPtr("a.go"), Revision: strPtr("c")})},
+		},
+		{
+			name:   "no selection",
+			thread: &types.DiscussionThread{TargetRepo: &types.DiscussionThreadTargetRepo{Path: strPtr("a.go"), Branch: strPtr("b")}},
+		},
+		{
+			name:   "archived",
+			thread: &types.DiscussionThread{ArchivedAt: &now, TargetRepo: selection(&types.DiscussionThreadTargetRepo{Path: strPtr("a.go"), Branch: strPtr("b")})},
+		},
+		{
+			name:   "no target",
+			thread: &types.DiscussionThread{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := syncable(test.thread); got != test.want {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+// Package codehostsync mirrors discussion threads to review comments on the
+// matching GitHub pull requests and GitLab merge requests, and syncs the
+// replies to them in both directions.
+package codehostsync
+
+import (
+	"context"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/conf"
+	"github.com/sourcegraph/sourcegraph/pkg/rcache"
+	log15 "gopkg.in/inconshreveable/log15.v2"
+)
+
+const (
+	// syncInterval is how often discussion threads are synced with code hosts.
+	syncInterval = time.Minute
+
+	// maxPolledThreads is the maximum number of threads that are polled for
+	// new code host replies per sync. Each poll makes code host API calls, so
+	// this bounds the API usage of the worker regardless of the number of
+	// threads.
+	maxPolledThreads = 50
+
+	// pollPageSize is the number of threads listed at once while polling.
+	pollPageSize = 100
+)
+
+func enabled() bool {
+	dc := conf.Get().Discussions
+	return dc != nil && dc.SyncCodeHostComments
+}
+
+// StartWorker should be invoked only after the DB has been initialized. It
+// starts the background worker which is responsible for syncing discussion
+// threads with code host review comments, if enabled in the site
+// configuration.
+//
+// It should be invoked in a separate goroutine.
+func StartWorker() {
+	conf.Watch(func() {
+		if !enabled() {
+			return
+		}
+
+		// Only one frontend instance should ever run this worker (otherwise
+		// comments would be mirrored more than once), so we use a distributed
+		// lock to guarantee this.
+		for enabled() {
+			ctx, release, ok := rcache.TryAcquireMutex(context.Background(), "discussionsCodeHostSyncWorker")
+			if !ok {
+				// Failed to acquire the mutex. Wait before trying again.
+				time.Sleep(30 * time.Second)
+				continue
+			}
+
+			log15.Debug("discussions: code host sync worker running")
+			workForever(ctx)
+			log15.Debug("discussions: code host sync worker stopped", "ctx", ctx.Err())
+			release()
+		}
+	})
+}
+
+func workForever(ctx context.Context) {
+	var s syncer
+	for {
+		if ctx.Err() != nil || !enabled() {
+			return // e.g. if we lost the distributed mutex
+		}
+		if err := s.sync(ctx); err != nil {
+			log15.Error("discussions: code host sync worker: error while syncing", "error", err)
+		}
+		time.Sleep(syncInterval)
+	}
+}
+
+// syncer syncs discussion threads on a file selection in a repository on a
+// configured GitHub or GitLab connection.
+//
+// Threads that were active on Sourcegraph since the last sync are synced
+// right away. Replies made on the code host can only be found by polling, so
+// the other threads are polled in turn, at most maxPolledThreads per sync.
+type syncer struct {
+	lastSync time.Time // when the last successful sync started, zero before the first one
+	offset   int       // offset of the next thread to poll, in ascending order
+}
+
+func (s *syncer) sync(ctx context.Context) error {
+	hosts, err := configuredCodeHosts(ctx)
+	if err != nil {
+		return err
+	}
+	if len(hosts) == 0 {
+		return nil
+	}
+
+	started := time.Now()
+	synced := map[int64]bool{}
+	if !s.lastSync.IsZero() {
+		// Allow for some clock skew between this instance and the database.
+		activeAfter := s.lastSync.Add(-syncInterval)
+		threads, err := db.DiscussionThreads.List(ctx, &db.DiscussionThreadsListOptions{
+			ActiveAfter:    &activeAfter,
+			AscendingOrder: true,
+		})
+		if err != nil {
+			return err
+		}
+		for _, thread := range threads {
+			if ctx.Err() != nil {
+				return nil
+			}
+			syncThreadOnHost(ctx, hosts, thread)
+			synced[thread.ID] = true
+		}
+	}
+	s.lastSync = started
+
+	return s.poll(ctx, hosts, synced)
+}
+
+// poll syncs up to maxPolledThreads threads that were not already synced,
+// continuing from where the last poll stopped and wrapping around after the
+// last thread.
+func (s *syncer) poll(ctx context.Context, hosts map[codeHostKey]codeHost, synced map[int64]bool) error {
+	polled := 0
+	for polled < maxPolledThreads {
+		threads, err := db.DiscussionThreads.List(ctx, &db.DiscussionThreadsListOptions{
+			LimitOffset:    &db.LimitOffset{Limit: pollPageSize, Offset: s.offset},
+			AscendingOrder: true,
+		})
+		if err != nil {
+			return err
+		}
+		for _, thread := range threads {
+			if ctx.Err() != nil || polled == maxPolledThreads {
+				return nil
+			}
+			s.offset++
+			if synced[thread.ID] {
+				continue
+			}
+			if syncThreadOnHost(ctx, hosts, thread) {
+				polled++
+			}
+		}
+		if len(threads) < pollPageSize {
+			// Start over from the first thread in the next sync.
+			s.offset = 0
+			return nil
+		}
+	}
+	return nil
+}
+
+// syncThreadOnHost syncs the thread with the code host of its repository. It
+// reports whether the thread was synced, i.e. whether code host API calls
+// were made.
+func syncThreadOnHost(ctx context.Context, hosts map[codeHostKey]codeHost, thread *types.DiscussionThread) bool {
+	if !syncable(thread) {
+		return false
+	}
+	repo, err := db.Repos.Get(ctx, thread.TargetRepo.RepoID)
+	if err != nil {
+		log15.Error("discussions: code host sync worker: error while getting repository", "thread", thread.ID, "error", err)
+		return false
+	}
+	if repo.ExternalRepo == nil {
+		return false
+	}
+	host, ok := hosts[codeHostKey{serviceType: repo.ExternalRepo.ServiceType, serviceID: repo.ExternalRepo.ServiceID}]
+	if !ok {
+		return false
+	}
+	if err := syncThread(ctx, host, repo, thread); err != nil {
+		log15.Error("discussions: code host sync worker: error while syncing thread", "thread", thread.ID, "error", err)
+	}
+	return true
+}
+
+// syncable tells if the thread can be mirrored to a pull request: it must be
+// on a selection in a file on a branch (which is matched against the head
+// branch of pull requests).
+func syncable(thread *types.DiscussionThread) bool {
+	t := thread.TargetRepo
+	return thread.ArchivedAt == nil && t != nil && t.Path != nil && t.Branch != nil && t.HasSelection()
+}
@@ -0,0 +1,78 @@
+package codehostsync
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+)
+
+func TestSyncerPoll(t *testing.T) {
+	defer func() { db.Mocks = db.MockStores{} }()
+
+	strPtr := func(s string) *string { return &s }
+	i32 := func(i int32) *int32 { return &i }
+	var threads []*types.DiscussionThread
+	for id := int64(1); id <= 120; id++ {
+		threads = append(threads, &types.DiscussionThread{
+			ID: id,
+			TargetRepo: &types.DiscussionThreadTargetRepo{
+				RepoID:         1,
+				Path:           strPtr("a.go"),
+				Branch:         strPtr("b"),
+				StartLine:      i32(1),
+				EndLine:        i32(2),
+				StartCharacter: i32(0),
+				EndCharacter:   i32(0),
+				LinesBefore:    &[]string{},
+				Lines:          &[]string{"a"},
+				LinesAfter:     &[]string{},
+			},
+		})
+	}
+	db.Mocks.DiscussionThreads.List = func(ctx context.Context, opts *db.DiscussionThreadsListOptions) ([]*types.DiscussionThread, error) {
+		if opts.LimitOffset == nil || opts.Offset >= len(threads) {
+			return nil, nil
+		}
+		end := opts.Offset + opts.Limit
+		if end > len(threads) {
+			end = len(threads)
+		}
+		return threads[opts.Offset:end], nil
+	}
+	spec := &api.ExternalRepoSpec{ID: "r", ServiceType: "github", ServiceID: "https://github.com/"}
+	db.Mocks.Repos.Get = func(ctx context.Context, id api.RepoID) (*types.Repo, error) {
+		return &types.Repo{ID: id, ExternalRepo: spec}, nil
+	}
+	var synced []int64
+	db.Mocks.DiscussionComments.List = func(ctx context.Context, opts *db.DiscussionCommentsListOptions) ([]*types.DiscussionComment, error) {
+		synced = append(synced, *opts.ThreadID)
+		return nil, nil // nothing to mirror, so no code host API calls are made
+	}
+	hosts := map[codeHostKey]codeHost{{serviceType: spec.ServiceType, serviceID: spec.ServiceID}: nil}
+
+	var s syncer
+	tests := []struct {
+		skip        map[int64]bool
+		first, last int64
+		n           int
+	}{
+		{first: 1, last: 50, n: 50},
+		// Threads that were already synced are not polled again.
+		{skip: map[int64]bool{51: true}, first: 52, last: 101, n: 50},
+		// The last threads are polled, then polling starts over.
+		{first: 102, last: 120, n: 19},
+		{first: 1, last: 50, n: 50},
+	}
+	for i, test := range tests {
+		synced = nil
+		if err := s.poll(context.Background(), hosts, test.skip); err != nil {
+			t.Fatal(err)
+		}
+		if len(synced) != test.n || synced[0] != test.first || synced[len(synced)-1] != test.last {
+			t.Errorf("poll %d: got %d threads polled (%v), want %d from %d to %d", i, len(synced), synced, test.n, test.first, test.last)
+		}
+	}
+}
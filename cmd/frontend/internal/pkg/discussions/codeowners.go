@@ -0,0 +1,46 @@
+package discussions
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/codeowners"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+)
+
+// CodeOwners returns the owners of the thread's target file, according to the
+// CODEOWNERS file in the repository at the thread's revision (or branch, or
+// the default branch).
+//
+// It returns nil if the thread does not target a file or the repository has
+// no CODEOWNERS file.
+func CodeOwners(ctx context.Context, thread *types.DiscussionThread) ([]*codeowners.Owner, error) {
+	t := thread.TargetRepo
+	if t == nil || t.Path == nil {
+		return nil, nil
+	}
+	repo, err := db.Repos.Get(ctx, t.RepoID)
+	if err != nil {
+		return nil, errors.Wrap(err, "db.Repos.Get")
+	}
+
+	rev := "HEAD"
+	switch {
+	case t.Revision != nil:
+		rev = *t.Revision
+	case t.Branch != nil:
+		rev = *t.Branch
+	}
+	commitID, err := backend.Repos.ResolveRev(ctx, repo, rev)
+	if err != nil {
+		return nil, errors.Wrap(err, "ResolveRev")
+	}
+
+	file, err := codeowners.ForCommit(ctx, repo, commitID)
+	if err != nil || file == nil {
+		return nil, err
+	}
+	return codeowners.ResolveOwners(ctx, repo, file.Owners(*t.Path))
+}
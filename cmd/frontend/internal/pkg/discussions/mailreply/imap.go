@@ -140,7 +140,7 @@ func messagePartTextContent(part io.ReadCloser, header textproto.MIMEHeader) (io
 // NewMailReader returns a new reader that reads mail from the configured IMAP
 // server. If no IMAP server is configured nil, nil is returned.
 func NewMailReader() (*MailReader, error) {
-	if !conf.CanReadEmail() {
+	if conf.Get().EmailImap == nil {
 		return nil, nil
 	}
 	conf := conf.Get()
@@ -0,0 +1,278 @@
+package mailreply
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/textproto"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/discussions"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/errcode"
+	log15 "gopkg.in/inconshreveable/log15.v2"
+)
+
+// replyResult is the outcome of handling a received email.
+type replyResult int
+
+const (
+	// replyIgnored means the email is not addressed to a sub-address with an
+	// authorization token. It is not a reply, so it is left alone.
+	replyIgnored replyResult = iota
+
+	// replyRejected means the email has an invalid authorization token or no
+	// effective content. It should be discarded.
+	replyRejected
+
+	// replyAdded means the email was added as a comment to its thread.
+	replyAdded
+)
+
+// handleReply adds the text content of an email sent to the given mailbox
+// names (such as "notifications+SomeSecret123") as a comment to the thread
+// that the authorization token in one of them grants access to. If the email
+// is a reply to the notification about a comment (according to its
+// In-Reply-To header value), the comment is added as a reply to that comment.
+//
+// It is used by all the ways of receiving email (IMAP, inbound email provider
+// webhooks and the SMTP server), and textContent is only called once the email
+// is known to be authorized.
+func handleReply(ctx context.Context, mailboxNames []string, subject, inReplyTo string, textContent func() ([]byte, error)) (replyResult, error) {
+	// 🚨 SECURITY: Check that one of the messages "to" addresses
+	// includes a valid sub-address authorization token. e.g.
+	// "notifications+SomeSecret123@sourcegraph.com". This guarantees
+	// that this email came from the user we sent the notification to
+	// previously (whereas e.g. relying on the "From" address field
+	// would be completely insecure doing to being easily spoofed).
+	//
+	// See https://tools.ietf.org/html/rfc5233 for details on sub-addressing.
+	var (
+		haveAuthorization bool
+		userID            int32
+		threadID          int64
+		err               error
+	)
+	for _, mailboxName := range mailboxNames {
+		// Parse the token ("SomeSecret123") out of the mailbox name ("notifications+SomeSecret123").
+		split := strings.Split(mailboxName, "+")
+		if len(split) < 2 {
+			continue
+		}
+		token := split[len(split)-1]
+
+		// Verify the token.
+		userID, threadID, err = db.DiscussionMailReplyTokens.Get(ctx, token)
+		if err == db.ErrInvalidToken {
+			log15.Debug("discussions: mailreply: ignoring email with invalid authorization token", "subject", subject, "mailbox_name", mailboxName)
+			return replyRejected, nil // Invalid token / attacker
+		}
+		if err != nil {
+			log15.Error("discussions: mailreply: error while looking up token", "error", err)
+			continue
+		}
+		haveAuthorization = true
+		break
+	}
+	if !haveAuthorization {
+		return replyIgnored, nil
+	}
+
+	// 🚨 SECURITY: Tokens remain valid after the user is deactivated (e.g.
+	// through SCIM) or deleted, so check that the user may still post.
+	user, err := db.Users.GetByID(ctx, userID)
+	if errcode.IsNotFound(err) || (err == nil && user.DeactivatedAt != nil) {
+		log15.Debug("discussions: mailreply: ignoring email from deactivated or deleted user", "subject", subject, "user", userID)
+		return replyRejected, nil
+	}
+	if err != nil {
+		return replyIgnored, errors.Wrap(err, "Users.GetByID")
+	}
+
+	text, err := textContent()
+	if err != nil {
+		return replyIgnored, errors.Wrap(err, "TextContent")
+	}
+
+	contents := strings.TrimSpace(string(trimGmailReplyQuote(text)))
+	if contents == "" {
+		log15.Debug("discussions: mailreply: ignoring email with no effective content", "subject", subject, "content", string(text))
+		return replyRejected, nil // ignore empty replies
+	}
+
+	parentCommentID, err := replyParentCommentID(ctx, threadID, inReplyTo)
+	if err != nil {
+		return replyIgnored, errors.Wrap(err, "replyParentCommentID")
+	}
+	_, err = discussions.InsecureAddCommentToThread(ctx, &types.DiscussionComment{
+		ThreadID:        threadID,
+		AuthorUserID:    userID,
+		Contents:        contents,
+		ParentCommentID: parentCommentID,
+	})
+	if err != nil {
+		return replyIgnored, errors.Wrap(err, "InsecureAddCommentToThread")
+	}
+	return replyAdded, nil
+}
+
+// notificationMessageID matches the Message-ID of a notification email about a
+// comment (such as "notifications+123.456@example.com" for comment 456 in
+// thread 123), see discussions.NotifyNewComment.
+var notificationMessageID = regexp.MustCompile(`\+(\d+)\.(\d+)@`)
+
+// parseInReplyTo returns the thread and comment IDs of the notification email
+// that an In-Reply-To header value refers to.
+func parseInReplyTo(inReplyTo string) (threadID, commentID int64, ok bool) {
+	m := notificationMessageID.FindStringSubmatch(inReplyTo)
+	if m == nil {
+		return 0, 0, false
+	}
+	threadID, err := strconv.ParseInt(m[1], 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	commentID, err = strconv.ParseInt(m[2], 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return threadID, commentID, true
+}
+
+// replyParentCommentID returns the ID of the comment that an email reply to
+// the thread is a reply to, or nil if it is a reply to the thread itself.
+//
+// Replies to the notification about the thread's first comment (i.e., the
+// new thread) are added to the thread itself, as are replies to notifications
+// about comments that no longer exist.
+func replyParentCommentID(ctx context.Context, threadID int64, inReplyTo string) (*int64, error) {
+	inReplyToThreadID, commentID, ok := parseInReplyTo(inReplyTo)
+	if !ok || inReplyToThreadID != threadID {
+		// 🚨 SECURITY: The token only grants access to its thread, so ignore
+		// references to comments in other threads.
+		return nil, nil
+	}
+	comments, err := db.DiscussionComments.List(ctx, &db.DiscussionCommentsListOptions{
+		LimitOffset: &db.LimitOffset{Limit: 1},
+		ThreadID:    &threadID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(comments) > 0 && comments[0].ID == commentID {
+		return nil, nil // the first comment
+	}
+	comment, err := db.DiscussionComments.Get(ctx, commentID)
+	if _, notFound := err.(*db.ErrCommentNotFound); notFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if comment.ThreadID != threadID {
+		return nil, nil
+	}
+	return &comment.ID, nil
+}
+
+// mailboxNames returns the mailbox names (the part before the "@") of the
+// email addresses in the address lists, which may include display names (such
+// as "Alice <alice+SomeSecret123@example.com>, bob@example.com"). Invalid
+// address lists are skipped.
+func mailboxNames(addressLists []string) []string {
+	var names []string
+	for _, list := range addressLists {
+		addresses, err := mail.ParseAddressList(list)
+		if err != nil {
+			continue
+		}
+		for _, address := range addresses {
+			if i := strings.LastIndex(address.Address, "@"); i != -1 {
+				names = append(names, address.Address[:i])
+			}
+		}
+	}
+	return names
+}
+
+// inboundEmail is an email received from an inbound email provider or by the
+// SMTP server.
+type inboundEmail struct {
+	recipients []string // address lists of the recipients (see mailboxNames)
+	subject    string
+	inReplyTo  string // the In-Reply-To header value
+	text       []byte // the text content, or nil if the email has none
+}
+
+// handle adds the email as a comment to its thread (see handleReply).
+func (e *inboundEmail) handle(ctx context.Context) (replyResult, error) {
+	return handleReply(ctx, mailboxNames(e.recipients), e.subject, e.inReplyTo, func() ([]byte, error) {
+		return e.text, nil
+	})
+}
+
+// parseRawEmail parses an RFC 5322 email message.
+func parseRawEmail(r io.Reader) (*inboundEmail, error) {
+	msg, err := mail.ReadMessage(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "ReadMessage")
+	}
+	text, err := entityTextContent(textproto.MIMEHeader(msg.Header), msg.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "entityTextContent")
+	}
+	return &inboundEmail{
+		recipients: append(msg.Header["To"], msg.Header["Cc"]...),
+		subject:    msg.Header.Get("Subject"),
+		inReplyTo:  msg.Header.Get("In-Reply-To"),
+		text:       text,
+	}, nil
+}
+
+// entityTextContent returns the plain text content of a MIME entity (an email
+// message or a part of a multipart message). For multipart entities, it is the
+// content of the first plain text part. If there is no plain text, nil is
+// returned.
+func entityTextContent(header textproto.MIMEHeader, body io.Reader) ([]byte, error) {
+	if contentType := header.Get("Content-Type"); contentType != "" {
+		mediaType, params, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			return nil, errors.Wrap(err, "ParseMediaType")
+		}
+		if strings.HasPrefix(mediaType, "multipart/") {
+			mr := multipart.NewReader(body, params["boundary"])
+			for {
+				part, err := mr.NextPart()
+				if err == io.EOF {
+					return nil, nil // couldn't find any plain text
+				}
+				if err != nil {
+					return nil, errors.Wrap(err, "NextPart")
+				}
+				text, err := entityTextContent(part.Header, part)
+				if err != nil || text != nil {
+					return text, err
+				}
+			}
+		}
+		if mediaType != "text/plain" {
+			return nil, nil // e.g. HTML or an attachment
+		}
+	}
+
+	text, err := messagePartTextContent(ioutil.NopCloser(body), header)
+	if err != nil {
+		return nil, errors.Wrap(err, "messagePartTextContent")
+	}
+	if text == nil {
+		return nil, nil // unknown encoding
+	}
+	return ioutil.ReadAll(text)
+}
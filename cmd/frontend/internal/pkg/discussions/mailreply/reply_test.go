@@ -0,0 +1,154 @@
+package mailreply
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+)
+
+func TestMailboxNames(t *testing.T) {
+	got := mailboxNames([]string{
+		"reply+abc@example.com",
+		"Alice <alice+def@example.com>, bob@example.com",
+		"not an address",
+		"",
+	})
+	want := []string{"reply+abc", "alice+def", "bob"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestParseRawEmail(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          string
+		wantRecipients []string
+		wantText       string
+	}{
+		{
+			name: "plain",
+			input: "To: reply+abc@example.com\r\n" +
+				"Cc: Bob <bob@example.com>\r\n" +
+				"Subject: Re: mux.go\r\n" +
+				"\r\n" +
+				"Looks good\r\n",
+			wantRecipients: []string{"reply+abc@example.com", "Bob <bob@example.com>"},
+			wantText:       "Looks good\r\n",
+		},
+		{
+			name: "multipart",
+			input: "To: reply+abc@example.com\r\n" +
+				"Subject: Re: mux.go\r\n" +
+				"Content-Type: multipart/mixed; boundary=outer\r\n" +
+				"\r\n" +
+				"--outer\r\n" +
+				"Content-Type: multipart/alternative; boundary=inner\r\n" +
+				"\r\n" +
+				"--inner\r\n" +
+				"Content-Type: text/html; charset=utf-8\r\n" +
+				"\r\n" +
+				"<p>Looks good</p>\r\n" +
+				"--inner\r\n" +
+				"Content-Type: text/plain; charset=utf-8\r\n" +
+				"Content-Transfer-Encoding: quoted-printable\r\n" +
+				"\r\n" +
+				"Looks =E2=9C=93\r\n" +
+				"--inner--\r\n" +
+				"--outer\r\n" +
+				"Content-Type: image/png\r\n" +
+				"\r\n" +
+				"...\r\n" +
+				"--outer--\r\n",
+			wantRecipients: []string{"reply+abc@example.com"},
+			wantText:       "Looks ✓",
+		},
+		{
+			name: "no text",
+			input: "To: reply+abc@example.com\r\n" +
+				"Content-Type: text/html\r\n" +
+				"\r\n" +
+				"<p>Looks good</p>\r\n",
+			wantRecipients: []string{"reply+abc@example.com"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			email, err := parseRawEmail(strings.NewReader(test.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(email.recipients, test.wantRecipients) {
+				t.Errorf("got recipients %q, want %q", email.recipients, test.wantRecipients)
+			}
+			if string(email.text) != test.wantText {
+				t.Errorf("got text %q, want %q", email.text, test.wantText)
+			}
+		})
+	}
+}
+
+func TestParseInReplyTo(t *testing.T) {
+	tests := []struct {
+		inReplyTo                   string
+		wantThreadID, wantCommentID int64
+		wantOK                      bool
+	}{
+		{inReplyTo: "<notifications+12.345@example.com>", wantThreadID: 12, wantCommentID: 345, wantOK: true},
+		{inReplyTo: "notifications+12.345@example.com", wantThreadID: 12, wantCommentID: 345, wantOK: true},
+		{inReplyTo: "<CAF=abc@mail.gmail.com>"},
+		{inReplyTo: ""},
+	}
+	for _, test := range tests {
+		threadID, commentID, ok := parseInReplyTo(test.inReplyTo)
+		if threadID != test.wantThreadID || commentID != test.wantCommentID || ok != test.wantOK {
+			t.Errorf("parseInReplyTo(%q) = %d, %d, %v, want %d, %d, %v", test.inReplyTo, threadID, commentID, ok, test.wantThreadID, test.wantCommentID, test.wantOK)
+		}
+	}
+}
+
+func TestHandleReply_deactivatedUser(t *testing.T) {
+	defer func() { db.Mocks = db.MockStores{} }()
+
+	deactivatedAt := time.Now()
+	users := map[int32]*types.User{
+		1: {ID: 1, Username: "alice", DeactivatedAt: &deactivatedAt},
+	}
+	db.Mocks.DiscussionMailReplyTokens.Get = func(ctx context.Context, token string) (int32, int64, error) {
+		switch token {
+		case "deactivated":
+			return 1, 10, nil
+		case "deleted":
+			return 2, 10, nil
+		}
+		return 0, 0, db.ErrInvalidToken
+	}
+	db.Mocks.Users.GetByID = func(ctx context.Context, id int32) (*types.User, error) {
+		if user, ok := users[id]; ok {
+			return user, nil
+		}
+		return nil, db.NewUserNotFoundError(id)
+	}
+	db.Mocks.DiscussionComments.Create = func(context.Context, *types.DiscussionComment) (*types.DiscussionComment, error) {
+		t.Error("unexpected call to DiscussionComments.Create")
+		return nil, nil
+	}
+
+	for _, token := range []string{"deactivated", "deleted"} {
+		result, err := handleReply(context.Background(), []string{"notifications+" + token}, "Re: thread", "", func() ([]byte, error) {
+			t.Error("unexpected call to textContent")
+			return []byte("hello"), nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result != replyRejected {
+			t.Errorf("%s: got result %v, want replyRejected", token, result)
+		}
+	}
+}
@@ -0,0 +1,308 @@
+package mailreply
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/pkg/conf"
+	log15 "gopkg.in/inconshreveable/log15.v2"
+)
+
+const (
+	// maxSMTPConnections is the maximum number of concurrent connections to
+	// the SMTP server. Each connection may hold a message of up to
+	// maxSMTPMessageSize in memory.
+	maxSMTPConnections = 10
+
+	// maxSMTPMessageSize is the maximum size of an email received by the SMTP
+	// server.
+	maxSMTPMessageSize = 30 << 20
+
+	// maxSMTPRecipients is the maximum number of recipients of an email
+	// received by the SMTP server.
+	maxSMTPRecipients = 100
+
+	// smtpTimeout is how long the SMTP server waits for a command or data
+	// before closing the connection.
+	smtpTimeout = 5 * time.Minute
+)
+
+// smtpListener is the listener of the running SMTP server.
+var smtpListener struct {
+	mu       sync.Mutex
+	addr     string
+	listener net.Listener
+}
+
+func init() {
+	conf.ContributeValidator(validateSMTPConfig)
+}
+
+// validateSMTPConfig reports problems with the configuration of the SMTP
+// server.
+func validateSMTPConfig(c conf.Unified) (problems []string) {
+	if c.EmailInbound != nil && c.EmailInbound.Provider == "smtp" && c.EmailInbound.SmtpListenAddress == "" {
+		problems = append(problems, `email.inbound smtpListenAddress is required for the "smtp" provider`)
+	}
+	return problems
+}
+
+// StartSMTPServer starts the SMTP server that receives email replies, if it is
+// configured as the inbound email provider with a listen address. It is
+// restarted when the configured listen address changes.
+//
+// Unlike the IMAP worker, it runs on every frontend instance, because any of
+// them can receive an email.
+//
+// It should be invoked in a separate goroutine.
+func StartSMTPServer() {
+	conf.Watch(func() {
+		var addr, hostname string
+		if c := conf.Get().EmailInbound; c != nil && c.Provider == "smtp" {
+			addr = c.SmtpListenAddress
+			if i := strings.LastIndex(c.Address, "@"); i != -1 {
+				hostname = c.Address[i+1:]
+			}
+		}
+
+		smtpListener.mu.Lock()
+		defer smtpListener.mu.Unlock()
+		if addr == smtpListener.addr {
+			return
+		}
+		if smtpListener.listener != nil {
+			smtpListener.listener.Close()
+			smtpListener.listener = nil
+			smtpListener.addr = ""
+		}
+		if addr == "" {
+			return
+		}
+
+		l, err := net.Listen("tcp", addr)
+		if err != nil {
+			log15.Error("discussions: mailreply SMTP server: unable to listen", "addr", addr, "error", err)
+			return
+		}
+		smtpListener.addr, smtpListener.listener = addr, l
+		log15.Debug("discussions: mailreply SMTP server listening", "addr", addr)
+		s := &smtpServer{hostname: hostname, deliver: deliverSMTP, maxConns: maxSMTPConnections}
+		go func() {
+			err := s.serve(l)
+			log15.Debug("discussions: mailreply SMTP server stopped", "addr", addr, "error", err)
+		}()
+	})
+}
+
+// deliverSMTP handles an email received by the SMTP server.
+func deliverSMTP(ctx context.Context, recipients []string, data []byte) (replyResult, error) {
+	email, err := parseRawEmail(bytes.NewReader(data))
+	if err != nil {
+		log15.Debug("discussions: mailreply SMTP server: rejecting invalid email", "error", err)
+		return replyRejected, nil
+	}
+
+	// The envelope recipients are the addresses that the email was actually
+	// delivered to, which (e.g. for Bcc recipients) need not be in the
+	// headers.
+	email.recipients = append(recipients, email.recipients...)
+	return email.handle(ctx)
+}
+
+// smtpServer is a minimal SMTP server (see RFC 5321) that receives emails and
+// delivers them locally. It does not relay emails or support extensions such
+// as STARTTLS and AUTH.
+type smtpServer struct {
+	hostname string // the host name that the server greets clients with
+
+	// deliver handles a received email, which the envelope recipients are
+	// the addresses of.
+	deliver func(ctx context.Context, recipients []string, data []byte) (replyResult, error)
+
+	// maxConns is the maximum number of concurrent connections (0 means no
+	// limit). Further connections are refused with a temporary error, so that
+	// the sender retries later.
+	maxConns int
+}
+
+// serve accepts connections on the listener until it is closed.
+func (s *smtpServer) serve(l net.Listener) error {
+	var sem chan struct{}
+	if s.maxConns > 0 {
+		sem = make(chan struct{}, s.maxConns)
+	}
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		if sem == nil {
+			go s.handleConn(conn)
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+			go func() {
+				defer func() { <-sem }()
+				s.handleConn(conn)
+			}()
+		default:
+			go refuseConn(conn)
+		}
+	}
+}
+
+// refuseConn tells the client that the server is too busy and closes the
+// connection.
+func refuseConn(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+	textproto.NewConn(conn).PrintfLine("421 4.3.2 Too many connections, try again later")
+}
+
+func (s *smtpServer) handleConn(conn net.Conn) {
+	defer conn.Close()
+	tp := textproto.NewConn(conn)
+
+	hostname := s.hostname
+	if hostname == "" {
+		hostname = "localhost"
+	}
+
+	var (
+		haveSender bool
+		recipients []string
+	)
+	reset := func() {
+		haveSender = false
+		recipients = nil
+	}
+
+	if err := tp.PrintfLine("220 %s ESMTP Sourcegraph", hostname); err != nil {
+		return
+	}
+	for {
+		conn.SetDeadline(time.Now().Add(smtpTimeout))
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg := line, ""
+		if i := strings.Index(line, " "); i != -1 {
+			verb, arg = line[:i], strings.TrimSpace(line[i+1:])
+		}
+
+		var reply string
+		switch strings.ToUpper(verb) {
+		case "HELO":
+			reset()
+			reply = "250 " + hostname
+		case "EHLO":
+			reset()
+			reply = fmt.Sprintf("250-%s\r\n250-8BITMIME\r\n250 SIZE %d", hostname, maxSMTPMessageSize)
+		case "MAIL":
+			if !strings.HasPrefix(strings.ToUpper(arg), "FROM:") {
+				reply = "501 5.5.4 Syntax: MAIL FROM:<address>"
+				break
+			}
+			reset()
+			haveSender = true
+			reply = "250 2.1.0 OK"
+		case "RCPT":
+			address, ok := pathAddress(arg, "TO:")
+			switch {
+			case !haveSender:
+				reply = "503 5.5.1 MAIL first"
+			case !ok:
+				reply = "501 5.5.4 Syntax: RCPT TO:<address>"
+			case len(recipients) >= maxSMTPRecipients:
+				reply = "452 4.5.3 Too many recipients"
+			default:
+				recipients = append(recipients, address)
+				reply = "250 2.1.5 OK"
+			}
+		case "DATA":
+			if len(recipients) == 0 {
+				reply = "503 5.5.1 RCPT first"
+				break
+			}
+			if err := tp.PrintfLine("354 End data with <CR><LF>.<CR><LF>"); err != nil {
+				return
+			}
+			r := tp.DotReader()
+			data, err := ioutil.ReadAll(io.LimitReader(r, maxSMTPMessageSize+1))
+			if err == nil {
+				_, err = io.Copy(ioutil.Discard, r) // read the rest of a message that is too large
+			}
+			if err != nil {
+				return
+			}
+			if len(data) > maxSMTPMessageSize {
+				reply = "552 5.3.4 Message too big"
+			} else {
+				reply = s.handleData(recipients, data)
+			}
+			reset()
+		case "RSET":
+			reset()
+			reply = "250 2.0.0 OK"
+		case "NOOP":
+			reply = "250 2.0.0 OK"
+		case "VRFY":
+			reply = "252 2.5.0 Cannot VRFY user"
+		case "QUIT":
+			tp.PrintfLine("221 2.0.0 Bye")
+			return
+		default:
+			reply = "502 5.5.2 Command not recognized"
+		}
+		if err := tp.PrintfLine("%s", reply); err != nil {
+			return
+		}
+	}
+}
+
+// handleData delivers a received email and returns the SMTP reply.
+func (s *smtpServer) handleData(recipients []string, data []byte) string {
+	result, err := s.deliver(context.Background(), recipients, data)
+	if err != nil {
+		// Fail temporarily so that the sender retries delivering the email
+		// later.
+		log15.Error("discussions: mailreply SMTP server: error while handling email", "error", err)
+		return "451 4.3.0 Error while handling message"
+	}
+	switch result {
+	case replyAdded:
+		return "250 2.0.0 OK"
+	case replyRejected:
+		return "550 5.7.1 Message rejected"
+	default:
+		return "550 5.1.1 Mailbox unavailable"
+	}
+}
+
+// pathAddress returns the address in the argument of a MAIL or RCPT command
+// (such as "TO:<alice@example.com>" for the prefix "TO:"), which may be
+// followed by parameters.
+func pathAddress(arg, prefix string) (string, bool) {
+	if !strings.HasPrefix(strings.ToUpper(arg), prefix) {
+		return "", false
+	}
+	arg = strings.TrimSpace(arg[len(prefix):])
+	if !strings.HasPrefix(arg, "<") {
+		return "", false
+	}
+	end := strings.Index(arg, ">")
+	if end == -1 || end == 1 {
+		return "", false
+	}
+	return arg[1:end], true
+}
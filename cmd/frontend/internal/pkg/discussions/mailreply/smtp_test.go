@@ -0,0 +1,159 @@
+package mailreply
+
+import (
+	"context"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/pkg/conf"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestSMTPServer(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	type delivery struct {
+		recipients []string
+		data       string
+	}
+	deliveries := make(chan delivery, 1)
+	s := &smtpServer{
+		hostname: "inbound.example.com",
+		deliver: func(ctx context.Context, recipients []string, data []byte) (replyResult, error) {
+			deliveries <- delivery{recipients: recipients, data: string(data)}
+			if strings.Contains(string(data), "rejected") {
+				return replyRejected, nil
+			}
+			return replyAdded, nil
+		},
+	}
+	go s.serve(l)
+
+	const msg = "To: reply+abc@example.com\r\nSubject: Re: mux.go\r\n\r\nLooks good\r\n.\r\n"
+	if err := smtp.SendMail(l.Addr().String(), nil, "alice@example.com", []string{"reply+abc@example.com", "bob@example.com"}, []byte(msg)); err != nil {
+		t.Fatal(err)
+	}
+	want := delivery{
+		recipients: []string{"reply+abc@example.com", "bob@example.com"},
+		data:       "To: reply+abc@example.com\nSubject: Re: mux.go\n\nLooks good\n.\n", // dot-unstuffed, with line endings converted
+	}
+	if got := <-deliveries; !reflect.DeepEqual(got, want) {
+		t.Errorf("got delivery %+v, want %+v", got, want)
+	}
+
+	err = smtp.SendMail(l.Addr().String(), nil, "alice@example.com", []string{"reply+abc@example.com"}, []byte("Subject: rejected\r\n\r\n"))
+	if err == nil || !strings.HasPrefix(err.Error(), "550") {
+		t.Errorf("got error %v for rejected email, want 550", err)
+	}
+	<-deliveries
+}
+
+func TestSMTPServer_maxConns(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	s := &smtpServer{maxConns: 1}
+	go s.serve(l)
+
+	greeting := func(conn net.Conn) string {
+		line, err := textproto.NewConn(conn).ReadLine()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return line
+	}
+
+	conn1, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := greeting(conn1); !strings.HasPrefix(got, "220 ") {
+		t.Errorf("got greeting %q for first connection, want 220", got)
+	}
+
+	conn2, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn2.Close()
+	if got := greeting(conn2); !strings.HasPrefix(got, "421 ") {
+		t.Errorf("got greeting %q for connection over the limit, want 421", got)
+	}
+
+	// Once the first connection is closed, new connections are accepted.
+	conn1.Close()
+	for i := 0; ; i++ {
+		conn3, err := net.Dial("tcp", l.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := greeting(conn3)
+		conn3.Close()
+		if strings.HasPrefix(got, "220 ") {
+			break
+		}
+		if i == 50 {
+			t.Fatalf("got greeting %q after closing the first connection, want 220", got)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestValidateSMTPConfig(t *testing.T) {
+	tests := map[string]struct {
+		input        conf.Unified
+		wantProblems []string
+	}{
+		"with listen address": {
+			input: conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+				EmailInbound: &schema.InboundEmailConfig{Provider: "smtp", Address: "reply@example.com", SmtpListenAddress: ":25"},
+			}},
+		},
+		"without listen address": {
+			input: conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+				EmailInbound: &schema.InboundEmailConfig{Provider: "smtp", Address: "reply@example.com"},
+			}},
+			wantProblems: []string{"smtpListenAddress is required"},
+		},
+		"webhook provider": {
+			input: conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+				EmailInbound: &schema.InboundEmailConfig{Provider: "mailgun", Address: "reply@example.com"},
+			}},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			conf.TestValidator(t, test.input, validateSMTPConfig, test.wantProblems)
+		})
+	}
+}
+
+func TestPathAddress(t *testing.T) {
+	tests := []struct {
+		arg, want string
+		wantOK    bool
+	}{
+		{arg: "TO:<reply+abc@example.com>", want: "reply+abc@example.com", wantOK: true},
+		{arg: "to: <reply+abc@example.com> NOTIFY=NEVER", want: "reply+abc@example.com", wantOK: true},
+		{arg: "TO:reply+abc@example.com"},
+		{arg: "TO:<>"},
+		{arg: "FROM:<alice@example.com>"},
+	}
+	for _, test := range tests {
+		got, ok := pathAddress(test.arg, "TO:")
+		if got != test.want || ok != test.wantOK {
+			t.Errorf("pathAddress(%q) = %q, %v, want %q, %v", test.arg, got, ok, test.want, test.wantOK)
+		}
+	}
+}
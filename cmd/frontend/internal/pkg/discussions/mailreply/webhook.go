@@ -0,0 +1,262 @@
+package mailreply
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/pkg/conf"
+	"github.com/sourcegraph/sourcegraph/pkg/errcode"
+	"github.com/sourcegraph/sourcegraph/pkg/rcache"
+	log15 "gopkg.in/inconshreveable/log15.v2"
+)
+
+// maxWebhookSize is the maximum size of an inbound email webhook request body
+// (including attachments, which are ignored).
+const maxWebhookSize = 30 << 20
+
+// maxMailgunTimestampSkew is how far a Mailgun webhook request's signed
+// timestamp may be from the current time. Requests outside of this window are
+// rejected, so that a captured request can't be replayed later.
+const maxMailgunTimestampSkew = 5 * time.Minute
+
+// mailgunTokens holds the tokens of the Mailgun webhook requests received
+// within the timestamp window, so that a request replayed within the window
+// is rejected as well.
+var mailgunTokens = rcache.NewWithTTL("mailreply_mailgun_token", int(2*maxMailgunTimestampSkew/time.Second))
+
+// mailgunTokenSeen reports whether the Mailgun webhook request token was
+// seen before, and remembers it.
+var mailgunTokenSeen = func(token string) bool {
+	if _, ok := mailgunTokens.Get(token); ok {
+		return true
+	}
+	mailgunTokens.Set(token, []byte{1})
+	return false
+}
+
+// ServeWebhook handles a request from the configured inbound email provider's
+// webhook (see the email.inbound site configuration property), which delivers
+// a received email.
+//
+// 🚨 SECURITY: This handler is accessible to anonymous users. It authenticates
+// the request with the webhook secret, and the email itself is authorized by
+// the token in its recipient address (see handleReply).
+func ServeWebhook(w http.ResponseWriter, r *http.Request) error {
+	c := conf.Get().EmailInbound
+	if c == nil || c.Provider == "smtp" {
+		return &errcode.HTTPErr{Status: http.StatusNotFound, Err: errors.New("no inbound email webhook is configured")}
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxWebhookSize)
+
+	email, err := parseWebhook(r, c.Provider, c.WebhookSecret)
+	if err != nil {
+		return err
+	}
+	result, err := email.handle(r.Context())
+	if err != nil {
+		// Fail so that the provider retries delivering the email later.
+		return errors.Wrap(err, "handle")
+	}
+	if result != replyAdded {
+		// Acknowledge the email anyway, because delivering it again would not
+		// change the result.
+		log15.Debug("discussions: mailreply webhook: ignoring email", "provider", c.Provider, "subject", email.subject)
+	}
+	return nil
+}
+
+// parseWebhook authenticates the webhook request with the secret and returns
+// the email that it delivers.
+func parseWebhook(r *http.Request, provider, secret string) (*inboundEmail, error) {
+	if secret == "" {
+		return nil, &errcode.HTTPErr{Status: http.StatusForbidden, Err: errors.New("email.inbound webhookSecret is not configured")}
+	}
+	switch provider {
+	case "mailgun":
+		return parseMailgunWebhook(r, secret)
+	case "postmark":
+		if !validBasicAuth(r, secret) {
+			return nil, &errcode.HTTPErr{Status: http.StatusUnauthorized, Err: errors.New("invalid password")}
+		}
+		return parsePostmarkWebhook(r)
+	case "sendgrid":
+		if !validBasicAuth(r, secret) {
+			return nil, &errcode.HTTPErr{Status: http.StatusUnauthorized, Err: errors.New("invalid password")}
+		}
+		return parseSendGridWebhook(r)
+	default:
+		return nil, errors.Errorf("unknown inbound email provider %q", provider)
+	}
+}
+
+// validBasicAuth tells if the request's HTTP basic authentication password is
+// the secret (the username is ignored).
+func validBasicAuth(r *http.Request, secret string) bool {
+	_, password, ok := r.BasicAuth()
+	return ok && subtle.ConstantTimeCompare([]byte(password), []byte(secret)) == 1
+}
+
+// parseForm parses the request's URL-encoded or multipart form.
+func parseForm(r *http.Request) error {
+	if err := r.ParseMultipartForm(maxWebhookSize); err != nil && err != http.ErrNotMultipart {
+		return &errcode.HTTPErr{Status: http.StatusBadRequest, Err: err}
+	}
+	return nil
+}
+
+// parseMailgunWebhook parses a Mailgun "store and notify" or "forward" route
+// request, whose signature is verified with the webhook signing key. See
+// https://documentation.mailgun.com/en/latest/user_manual.html#receiving-messages-via-http-through-a-forward-action.
+func parseMailgunWebhook(r *http.Request, signingKey string) (*inboundEmail, error) {
+	if err := parseForm(r); err != nil {
+		return nil, err
+	}
+	timestamp, token := r.FormValue("timestamp"), r.FormValue("token")
+	if !validMailgunSignature(signingKey, timestamp, token, r.FormValue("signature")) {
+		return nil, &errcode.HTTPErr{Status: http.StatusUnauthorized, Err: errors.New("invalid signature")}
+	}
+	if !validMailgunTimestamp(timestamp, time.Now()) {
+		return nil, &errcode.HTTPErr{Status: http.StatusUnauthorized, Err: errors.New("signature timestamp is too old")}
+	}
+	if mailgunTokenSeen(token) {
+		return nil, &errcode.HTTPErr{Status: http.StatusUnauthorized, Err: errors.New("request was already received")}
+	}
+
+	// Prefer the text without the quoted email that is replied to.
+	text := r.FormValue("stripped-text")
+	if text == "" {
+		text = r.FormValue("body-plain")
+	}
+	return &inboundEmail{
+		recipients: []string{r.FormValue("recipient"), r.FormValue("To")},
+		subject:    r.FormValue("subject"),
+		inReplyTo:  r.FormValue("In-Reply-To"),
+		text:       []byte(text),
+	}, nil
+}
+
+// validMailgunSignature tells if the signature is the hex-encoded HMAC-SHA256
+// of the timestamp and token, keyed with the signing key.
+func validMailgunSignature(signingKey, timestamp, token, signature string) bool {
+	mac := hmac.New(sha256.New, []byte(signingKey))
+	mac.Write([]byte(timestamp + token))
+	return hmac.Equal([]byte(hex.EncodeToString(mac.Sum(nil))), []byte(signature))
+}
+
+// validMailgunTimestamp tells if the timestamp (in seconds since the Unix
+// epoch) is within maxMailgunTimestampSkew of now.
+func validMailgunTimestamp(timestamp string, now time.Time) bool {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	skew := now.Sub(time.Unix(seconds, 0))
+	return -maxMailgunTimestampSkew <= skew && skew <= maxMailgunTimestampSkew
+}
+
+// postmarkAddress is an email address in a Postmark inbound webhook payload.
+type postmarkAddress struct {
+	Email string
+}
+
+// postmarkHeader is an email header in a Postmark inbound webhook payload.
+type postmarkHeader struct {
+	Name  string
+	Value string
+}
+
+// postmarkInboundEmail is the payload of a Postmark inbound webhook. See
+// https://postmarkapp.com/developer/webhooks/inbound-webhook.
+type postmarkInboundEmail struct {
+	OriginalRecipient string
+	ToFull            []postmarkAddress
+	CcFull            []postmarkAddress
+	Subject           string
+	Headers           []postmarkHeader
+	TextBody          string
+	StrippedTextReply string
+}
+
+// parsePostmarkWebhook parses a Postmark inbound webhook request.
+func parsePostmarkWebhook(r *http.Request) (*inboundEmail, error) {
+	var payload postmarkInboundEmail
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		return nil, &errcode.HTTPErr{Status: http.StatusBadRequest, Err: err}
+	}
+
+	email := &inboundEmail{
+		recipients: []string{payload.OriginalRecipient},
+		subject:    payload.Subject,
+		text:       []byte(payload.StrippedTextReply),
+	}
+	for _, a := range append(payload.ToFull, payload.CcFull...) {
+		email.recipients = append(email.recipients, a.Email)
+	}
+	for _, h := range payload.Headers {
+		if strings.EqualFold(h.Name, "In-Reply-To") {
+			email.inReplyTo = h.Value
+		}
+	}
+	if len(email.text) == 0 {
+		email.text = []byte(payload.TextBody)
+	}
+	return email, nil
+}
+
+// parseSendGridWebhook parses a SendGrid Inbound Parse webhook request, with
+// or without the "send raw" option. See
+// https://sendgrid.com/docs/for-developers/parsing-email/setting-up-the-inbound-parse-webhook/.
+func parseSendGridWebhook(r *http.Request) (*inboundEmail, error) {
+	if err := parseForm(r); err != nil {
+		return nil, err
+	}
+
+	var email *inboundEmail
+	if raw := r.FormValue("email"); raw != "" {
+		var err error
+		email, err = parseRawEmail(strings.NewReader(raw))
+		if err != nil {
+			return nil, &errcode.HTTPErr{Status: http.StatusBadRequest, Err: err}
+		}
+	} else {
+		email = &inboundEmail{
+			recipients: []string{r.FormValue("to"), r.FormValue("cc")},
+			subject:    r.FormValue("subject"),
+			inReplyTo:  rawHeaderValue(r.FormValue("headers"), "In-Reply-To"),
+			text:       []byte(r.FormValue("text")),
+		}
+	}
+
+	// The envelope holds the addresses that the email was actually delivered
+	// to, which (e.g. for Bcc recipients) need not be in the headers.
+	var envelope struct {
+		To []string `json:"to"`
+	}
+	if s := r.FormValue("envelope"); s != "" {
+		if err := json.Unmarshal([]byte(s), &envelope); err != nil {
+			return nil, &errcode.HTTPErr{Status: http.StatusBadRequest, Err: errors.Wrap(err, "envelope")}
+		}
+	}
+	email.recipients = append(envelope.To, email.recipients...)
+	return email, nil
+}
+
+// rawHeaderValue returns the value of the named header in the raw email
+// headers, or "" if the headers are invalid or don't include it.
+func rawHeaderValue(rawHeaders, name string) string {
+	header, err := textproto.NewReader(bufio.NewReader(strings.NewReader(strings.TrimRight(rawHeaders, "\r\n") + "\r\n\r\n"))).ReadMIMEHeader()
+	if err != nil {
+		return ""
+	}
+	return header.Get(name)
+}
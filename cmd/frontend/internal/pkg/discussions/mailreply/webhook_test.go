@@ -0,0 +1,208 @@
+package mailreply
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/pkg/errcode"
+)
+
+func TestParseWebhook_Mailgun(t *testing.T) {
+	seenTokens := map[string]bool{}
+	origTokenSeen := mailgunTokenSeen
+	mailgunTokenSeen = func(token string) bool {
+		seen := seenTokens[token]
+		seenTokens[token] = true
+		return seen
+	}
+	defer func() { mailgunTokenSeen = origTokenSeen }()
+
+	form := func(timestamp time.Time, token string) url.Values {
+		ts := strconv.FormatInt(timestamp.Unix(), 10)
+		mac := hmac.New(sha256.New, []byte("key-secret"))
+		mac.Write([]byte(ts + token))
+		return url.Values{
+			"recipient":     {"reply+abc@example.com"},
+			"To":            {"Sourcegraph <reply+abc@example.com>"},
+			"subject":       {"Re: mux.go"},
+			"In-Reply-To":   {"<notifications+1.2@example.com>"},
+			"body-plain":    {"Looks good\n\nOn Wed, ... wrote:\n> hello"},
+			"stripped-text": {"Looks good"},
+			"timestamp":     {ts},
+			"token":         {token},
+			"signature":     {hex.EncodeToString(mac.Sum(nil))},
+		}
+	}
+	req := func(form url.Values) *http.Request {
+		r := httptest.NewRequest("POST", "/.api/inbound-email", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return r
+	}
+
+	email, err := parseWebhook(req(form(time.Now(), "token1")), "mailgun", "key-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &inboundEmail{
+		recipients: []string{"reply+abc@example.com", "Sourcegraph <reply+abc@example.com>"},
+		subject:    "Re: mux.go",
+		inReplyTo:  "<notifications+1.2@example.com>",
+		text:       []byte("Looks good"),
+	}
+	if !reflect.DeepEqual(email, want) {
+		t.Errorf("got %+v, want %+v", email, want)
+	}
+
+	if _, err := parseWebhook(req(form(time.Now(), "token2")), "mailgun", "key-other"); !errcode.IsHTTPErrorCode(err, http.StatusUnauthorized) {
+		t.Errorf("got error %v with wrong signing key, want unauthorized", err)
+	}
+	if _, err := parseWebhook(req(form(time.Now(), "token1")), "mailgun", "key-secret"); !errcode.IsHTTPErrorCode(err, http.StatusUnauthorized) {
+		t.Errorf("got error %v for replayed token, want unauthorized", err)
+	}
+	if _, err := parseWebhook(req(form(time.Now().Add(-time.Hour), "token3")), "mailgun", "key-secret"); !errcode.IsHTTPErrorCode(err, http.StatusUnauthorized) {
+		t.Errorf("got error %v for old timestamp, want unauthorized", err)
+	}
+}
+
+func TestValidMailgunSignature(t *testing.T) {
+	const (
+		timestamp = "1529006854"
+		token     = "a8ce0edb2dd8301dee6c2405235584e45aa91d1e9f979f3de0"
+	)
+	if !validMailgunSignature("key-secret", timestamp, token, "9cd776314f7488fdda60be8528377038e612bc076e3d695bbc1a86abd071b1d4") {
+		t.Error("valid signature was rejected")
+	}
+	if validMailgunSignature("key-secret", timestamp, token, "") {
+		t.Error("empty signature was accepted")
+	}
+	if validMailgunSignature("key-secret", "1529006855", token, "9cd776314f7488fdda60be8528377038e612bc076e3d695bbc1a86abd071b1d4") {
+		t.Error("signature of another timestamp was accepted")
+	}
+}
+
+func TestValidMailgunTimestamp(t *testing.T) {
+	now := time.Unix(1529006854, 0)
+	tests := map[string]bool{
+		"1529006854": true,
+		"1529006554": true,  // 5 minutes old
+		"1529006553": false, // more than 5 minutes old
+		"1529007154": true,  // 5 minutes ahead (clock skew)
+		"1529007155": false,
+		"":           false,
+		"x":          false,
+	}
+	for timestamp, want := range tests {
+		if got := validMailgunTimestamp(timestamp, now); got != want {
+			t.Errorf("%q: got %v, want %v", timestamp, got, want)
+		}
+	}
+}
+
+func TestParseWebhook_Postmark(t *testing.T) {
+	const payload = `{
+		"OriginalRecipient": "reply+abc@example.com",
+		"ToFull": [{"Email": "reply+abc@example.com", "Name": "Sourcegraph"}],
+		"CcFull": [{"Email": "bob@example.com", "Name": ""}],
+		"Subject": "Re: mux.go",
+		"Headers": [{"Name": "In-Reply-To", "Value": "<notifications+1.2@example.com>"}],
+		"TextBody": "Looks good\n\n> hello",
+		"StrippedTextReply": ""
+	}`
+	req := func(password string) *http.Request {
+		r := httptest.NewRequest("POST", "/.api/inbound-email", strings.NewReader(payload))
+		r.SetBasicAuth("inbound", password)
+		return r
+	}
+
+	email, err := parseWebhook(req("secret"), "postmark", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &inboundEmail{
+		recipients: []string{"reply+abc@example.com", "reply+abc@example.com", "bob@example.com"},
+		subject:    "Re: mux.go",
+		inReplyTo:  "<notifications+1.2@example.com>",
+		text:       []byte("Looks good\n\n> hello"),
+	}
+	if !reflect.DeepEqual(email, want) {
+		t.Errorf("got %+v, want %+v", email, want)
+	}
+
+	if _, err := parseWebhook(req("wrong"), "postmark", "secret"); !errcode.IsHTTPErrorCode(err, http.StatusUnauthorized) {
+		t.Errorf("got error %v with wrong password, want unauthorized", err)
+	}
+	if _, err := parseWebhook(req(""), "postmark", ""); !errcode.IsHTTPErrorCode(err, http.StatusForbidden) {
+		t.Errorf("got error %v with no configured secret, want forbidden", err)
+	}
+}
+
+func TestParseWebhook_SendGrid(t *testing.T) {
+	req := func(fields map[string]string) *http.Request {
+		var body bytes.Buffer
+		w := multipart.NewWriter(&body)
+		for name, value := range fields {
+			if err := w.WriteField(name, value); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		r := httptest.NewRequest("POST", "/.api/inbound-email", &body)
+		r.Header.Set("Content-Type", w.FormDataContentType())
+		r.SetBasicAuth("inbound", "secret")
+		return r
+	}
+
+	t.Run("parsed", func(t *testing.T) {
+		email, err := parseWebhook(req(map[string]string{
+			"envelope": `{"to":["reply+abc@example.com"],"from":"alice@example.com"}`,
+			"to":       "Sourcegraph <notifications@example.com>",
+			"subject":  "Re: mux.go",
+			"headers":  "To: Sourcegraph <notifications@example.com>\nIn-Reply-To: <notifications+1.2@example.com>\n",
+			"text":     "Looks good",
+		}), "sendgrid", "secret")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := &inboundEmail{
+			recipients: []string{"reply+abc@example.com", "Sourcegraph <notifications@example.com>", ""},
+			subject:    "Re: mux.go",
+			inReplyTo:  "<notifications+1.2@example.com>",
+			text:       []byte("Looks good"),
+		}
+		if !reflect.DeepEqual(email, want) {
+			t.Errorf("got %+v, want %+v", email, want)
+		}
+	})
+
+	t.Run("raw", func(t *testing.T) {
+		email, err := parseWebhook(req(map[string]string{
+			"envelope": `{"to":["reply+abc@example.com"],"from":"alice@example.com"}`,
+			"email":    "To: reply+abc@example.com\r\nSubject: Re: mux.go\r\nIn-Reply-To: <notifications+1.2@example.com>\r\n\r\nLooks good\r\n",
+		}), "sendgrid", "secret")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := &inboundEmail{
+			recipients: []string{"reply+abc@example.com", "reply+abc@example.com"},
+			subject:    "Re: mux.go",
+			inReplyTo:  "<notifications+1.2@example.com>",
+			text:       []byte("Looks good\r\n"),
+		}
+		if !reflect.DeepEqual(email, want) {
+			t.Errorf("got %+v, want %+v", email, want)
+		}
+	})
+}
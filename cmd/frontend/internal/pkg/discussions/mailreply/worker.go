@@ -1,17 +1,14 @@
-// Package mailreply implements an IMAP inbox monitor to consume email replies
-// to discussions.
+// Package mailreply consumes email replies to discussions, by monitoring an
+// IMAP inbox or by receiving them from an inbound email provider's webhook or
+// the built-in SMTP server.
 package mailreply
 
 import (
 	"context"
 	"regexp"
-	"strings"
 	"time"
 
 	"github.com/pkg/errors"
-	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
-	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/discussions"
-	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
 	"github.com/sourcegraph/sourcegraph/pkg/conf"
 	"github.com/sourcegraph/sourcegraph/pkg/rcache"
 	log15 "gopkg.in/inconshreveable/log15.v2"
@@ -24,7 +21,7 @@ import (
 // It should be invoked in a separate goroutine.
 func StartWorker() {
 	conf.Watch(func() {
-		if !conf.CanReadEmail() {
+		if conf.Get().EmailImap == nil {
 			return
 		}
 
@@ -66,66 +63,17 @@ func workForever(ctx context.Context) {
 			return errors.Wrap(err, "ReadUnread")
 		}
 		for msg := range ch {
-			// 🚨 SECURITY: Check that one of the messages "to" addresses
-			// includes a valid sub-address authorization token. e.g.
-			// "notifications+SomeSecret123@sourcegraph.com". This guarantees
-			// that this email came from the user we sent the notification to
-			// previously (whereas e.g. relying on the "From" address field
-			// would be completely insecure doing to being easily spoofed).
-			//
-			// See https://tools.ietf.org/html/rfc5233 for details on sub-addressing.
-			var (
-				haveAuthorization bool
-				userID            int32
-				threadID          int64
-			)
+			var names []string
 			for _, toAddress := range msg.Envelope.To {
-				// Parse the token ("SomeSecret123") out of the mailbox name ("notifications+SomeSecret123").
-				split := strings.Split(toAddress.MailboxName, "+")
-				if len(split) < 2 {
-					continue
-				}
-				token := split[len(split)-1]
-
-				// Verify the token.
-				userID, threadID, err = db.DiscussionMailReplyTokens.Get(ctx, token)
-				if err == db.ErrInvalidToken {
-					log15.Debug("discussions: mailreply worker: ignoring email with invalid authorization token", "subject", msg.Envelope.Subject, "mailbox_name", toAddress.MailboxName)
-					msg.MarkSeenAndDeleted()
-					break // Invalid token / attacker
-				}
-				if err != nil {
-					log15.Error("discussions: mailreply worker: error while looking up token", "error", err)
-					continue
-				}
-				haveAuthorization = true
-				break
+				names = append(names, toAddress.MailboxName)
 			}
-			if !haveAuthorization {
-				continue // ignore the message
-			}
-
-			textContent, err := msg.TextContent()
+			result, err := handleReply(ctx, names, msg.Envelope.Subject, msg.Envelope.InReplyTo, msg.TextContent)
 			if err != nil {
-				log15.Error("discussions: mailreply worker: error while reading TextContent", "error", err)
+				log15.Error("discussions: mailreply worker: error while handling email", "error", err)
 				continue
 			}
-
-			contents := strings.TrimSpace(string(trimGmailReplyQuote(textContent)))
-			if contents == "" {
-				log15.Debug("discussions: mailreply worker: ignoring email with no effective content", "subject", msg.Envelope.Subject, "content", string(textContent))
-				msg.MarkSeenAndDeleted()
-				continue // ignore empty replies
-			}
-
-			_, err = discussions.InsecureAddCommentToThread(ctx, &types.DiscussionComment{
-				ThreadID:     threadID,
-				AuthorUserID: userID,
-				Contents:     contents,
-			})
-			if err != nil {
-				log15.Error("discussions: mailreply worker: error while adding comment to thread", "error", err)
-				continue
+			if result == replyIgnored {
+				continue // ignore the message
 			}
 
 			// Now that we're finished handling this message, mark it as seen
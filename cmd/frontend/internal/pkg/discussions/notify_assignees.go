@@ -0,0 +1,120 @@
+package discussions
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/goroutine"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/actor"
+	"github.com/sourcegraph/sourcegraph/pkg/conf"
+	"github.com/sourcegraph/sourcegraph/pkg/errcode"
+	"github.com/sourcegraph/sourcegraph/pkg/txemail"
+	"github.com/sourcegraph/sourcegraph/pkg/txemail/txtypes"
+	log15 "gopkg.in/inconshreveable/log15.v2"
+)
+
+// NotifyAssigned should be invoked after users have been assigned to a
+// discussion thread, in order to notify them. The user who assigned them is
+// not notified, nor are assignees who can't read the thread's repository.
+//
+// It returns immediately and does not block.
+func NotifyAssigned(thread *types.DiscussionThread, assignedBy *types.User, assigneeUserIDs []int32) {
+	if len(assigneeUserIDs) == 0 {
+		return
+	}
+	goroutine.Go(func() {
+		if !conf.CanSendEmail() {
+			// Can't send email, so we have nothing to do.
+			return
+		}
+
+		ctx := context.Background()
+		for _, userID := range assigneeUserIDs {
+			if userID == assignedBy.ID {
+				continue
+			}
+			if err := notifyAssigned(ctx, thread, assignedBy, userID); err != nil {
+				log15.Error("discussions: NotifyAssigned", "error", err)
+			}
+		}
+	})
+}
+
+func notifyAssigned(ctx context.Context, thread *types.DiscussionThread, assignedBy *types.User, userID int32) error {
+	url, err := URLToInlineThread(ctx, thread)
+	if err != nil {
+		return errors.Wrap(err, "URLToInlineThread")
+	}
+	if url == nil {
+		return nil // can't generate a link to this thread target type
+	}
+	if ok, err := canReadThread(ctx, userID, thread); err != nil || !ok {
+		return err
+	}
+
+	q := url.Query()
+	q.Set("utm_source", "assigned-email")
+	url.RawQuery = q.Encode()
+
+	email, verified, err := db.UserEmails.GetPrimaryEmail(ctx, userID)
+	if err != nil && !errcode.IsNotFound(err) {
+		return errors.Wrap(err, "GetPrimaryEmail")
+	}
+	if errcode.IsNotFound(err) || !verified {
+		// User has no email or it is not verified, do not send them any emails.
+		return nil
+	}
+
+	var repoName string
+	if thread.TargetRepo != nil {
+		repo, err := db.Repos.Get(ctx, thread.TargetRepo.RepoID)
+		if err != nil {
+			return errors.Wrap(err, "db.Repos.Get")
+		}
+		repoName = string(repo.Name)
+	}
+
+	return txemail.Send(ctx, txemail.Message{
+		To:       []string{email},
+		Template: threadAssignedEmailTemplate,
+		Data: struct {
+			ThreadTitle        string
+			AssignedByUsername string
+			RepoName           string
+			URL                string
+		}{
+			ThreadTitle:        thread.Title,
+			AssignedByUsername: assignedBy.Username,
+			RepoName:           repoName,
+			URL:                url.String(),
+		},
+	})
+}
+
+// canReadThread reports whether the user has read access to the thread's
+// repository (if any). Users who can't read it must not be sent the thread's
+// contents or a reply token.
+func canReadThread(ctx context.Context, userID int32, thread *types.DiscussionThread) (bool, error) {
+	if thread.TargetRepo == nil {
+		return true, nil
+	}
+	_, err := db.Repos.Get(actor.WithActor(ctx, &actor.Actor{UID: userID}), thread.TargetRepo.RepoID)
+	if errcode.IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, errors.Wrap(err, "db.Repos.Get")
+}
+
+var threadAssignedEmailTemplate = txemail.MustValidate(txtypes.Templates{
+	Subject: `{{with .RepoName}}[{{.}}] {{end}}You were assigned to: {{.ThreadTitle}}`,
+	Text: `@{{.AssignedByUsername}} assigned you to the discussion thread "{{.ThreadTitle}}".
+
+View it on Sourcegraph:
+
+  {{.URL}}
+`,
+	HTML: `<p><strong>@{{.AssignedByUsername}}</strong> assigned you to the discussion thread <strong>{{.ThreadTitle}}</strong>.</p>
+<p><a href="{{.URL}}">View it on Sourcegraph</a></p>`,
+})
@@ -10,6 +10,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/goroutine"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/codeowners"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/discussions/mentions"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
 	"github.com/sourcegraph/sourcegraph/pkg/conf"
@@ -83,9 +84,12 @@ type notifier struct {
 // subscription store, so we rely on some simple mechanics to get a good-enough
 // result:
 //
-// 	1. If you were previously mentioned in the thread, you are subscribed.
-// 	2. If you previously authored a comment, you are subscribed.
-//
+//  1. If you were previously mentioned in the thread, you are subscribed.
+//  2. If you previously authored a comment, you are subscribed.
+//  3. If you are assigned to the thread (and can read its repository), you
+//     are subscribed.
+//  4. If the thread is new and you own its file (according to the
+//     repository's CODEOWNERS file), you are subscribed.
 func (n *notifier) subscribers(ctx context.Context) ([]string, error) {
 	comments, err := db.DiscussionComments.List(ctx, &db.DiscussionCommentsListOptions{
 		LimitOffset: &db.LimitOffset{
@@ -122,6 +126,36 @@ func (n *notifier) subscribers(ctx context.Context) ([]string, error) {
 			}
 		}
 	}
+	for _, userID := range n.thread.AssigneeUserIDs {
+		assignee, err := db.Users.GetByID(ctx, userID)
+		if errcode.IsNotFound(err) {
+			continue // the user was deleted after the thread was fetched
+		} else if err != nil {
+			return nil, errors.Wrap(err, "Assignee: GetByID")
+		}
+		if ok, err := canReadThread(ctx, assignee.ID, n.thread); err != nil {
+			return nil, errors.Wrap(err, "Assignee")
+		} else if !ok {
+			continue
+		}
+		if _, ok := set[assignee.Username]; !ok {
+			set[assignee.Username] = struct{}{}
+			subscribers = append(subscribers, assignee.Username)
+		}
+	}
+	if n.typ == newThreadNotification {
+		owners, err := CodeOwners(ctx, n.thread)
+		if err != nil {
+			// Still notify the other subscribers.
+			log15.Warn("discussions: unable to determine code owners", "thread", n.thread.ID, "error", err)
+		}
+		for _, user := range codeowners.OwnerUsers(owners) {
+			if _, ok := set[user.Username]; !ok {
+				set[user.Username] = struct{}{}
+				subscribers = append(subscribers, user.Username)
+			}
+		}
+	}
 	return subscribers, nil
 }
 
@@ -157,8 +191,7 @@ func (n *notifier) notifyUsername(ctx context.Context, username string) error {
 			return errors.Wrap(err, "DiscussionMailReplyTokens.Generate")
 		}
 
-		conf := conf.Get()
-		emailParts := strings.Split(conf.EmailImap.Username, "@")
+		emailParts := strings.Split(conf.InboundEmailAddress(), "@")
 		secureReplyTo := fmt.Sprintf("%s+%s@%s", emailParts[0], secureToken, emailParts[1])
 		replyTo = &secureReplyTo
 
@@ -0,0 +1,63 @@
+package discussions
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/actor"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/errcode"
+)
+
+func TestSubscribers_assignees(t *testing.T) {
+	defer func() { db.Mocks = db.MockStores{} }()
+
+	users := map[int32]*types.User{
+		1: {ID: 1, Username: "alice"},
+		2: {ID: 2, Username: "bob"}, // can't read the repository
+	}
+	db.Mocks.DiscussionComments.List = func(context.Context, *db.DiscussionCommentsListOptions) ([]*types.DiscussionComment, error) {
+		return nil, nil
+	}
+	db.Mocks.Users.GetByID = func(ctx context.Context, id int32) (*types.User, error) {
+		if user, ok := users[id]; ok {
+			return user, nil
+		}
+		return nil, db.NewUserNotFoundError(id)
+	}
+	db.Mocks.Repos.Get = func(ctx context.Context, id api.RepoID) (*types.Repo, error) {
+		if actor.FromContext(ctx).UID == 2 {
+			return nil, &errcode.Mock{IsNotFound: true}
+		}
+		return &types.Repo{ID: id}, nil
+	}
+
+	n := &notifier{
+		typ: newCommentNotification,
+		thread: &types.DiscussionThread{
+			ID:              1,
+			TargetRepo:      &types.DiscussionThreadTargetRepo{RepoID: 1},
+			AssigneeUserIDs: []int32{1, 2, 3}, // user 3 was deleted
+		},
+	}
+	subscribers, err := n.subscribers(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"alice"}; !reflect.DeepEqual(subscribers, want) {
+		t.Errorf("got subscribers %v, want %v", subscribers, want)
+	}
+
+	// Without a target repository, all assignees are subscribed.
+	n.thread.TargetRepo = nil
+	subscribers, err = n.subscribers(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"alice", "bob"}; !reflect.DeepEqual(subscribers, want) {
+		t.Errorf("got subscribers %v, want %v", subscribers, want)
+	}
+}
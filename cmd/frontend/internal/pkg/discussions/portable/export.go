@@ -0,0 +1,203 @@
+package portable
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/errcode"
+)
+
+// exportBatchSize is the number of threads read from the DB at a time.
+const exportBatchSize = 100
+
+// Export writes all (non-deleted) discussion threads to w, oldest first, one
+// JSON-encoded Thread per line. The source is the URL of this instance, which
+// identifies the exported threads when they are imported.
+//
+// Threads whose target repository no longer exists are skipped and returned,
+// because they can't be imported anywhere.
+//
+// 🚨 SECURITY: The caller must ensure that the actor is a site admin.
+func Export(ctx context.Context, w io.Writer, source string) (skipped []*SkippedThread, err error) {
+	e := &exporter{
+		source: source,
+		repos:  map[api.RepoID]api.RepoName{},
+		users:  map[int32]*User{},
+	}
+	enc := json.NewEncoder(w)
+	for offset := 0; ; offset += exportBatchSize {
+		threads, err := db.DiscussionThreads.List(ctx, &db.DiscussionThreadsListOptions{
+			LimitOffset:    &db.LimitOffset{Limit: exportBatchSize, Offset: offset},
+			AscendingOrder: true,
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "DiscussionThreads.List")
+		}
+		for _, thread := range threads {
+			t, err := e.thread(ctx, thread)
+			if err != nil {
+				return nil, errors.Wrapf(err, "exporting thread %d", thread.ID)
+			}
+			if t == nil {
+				skipped = append(skipped, &SkippedThread{Title: thread.Title, Reason: "repository not found"})
+				continue
+			}
+			if err := enc.Encode(t); err != nil {
+				return nil, err
+			}
+		}
+		if len(threads) < exportBatchSize {
+			return skipped, nil
+		}
+	}
+}
+
+// exporter converts threads to their exported form, caching the repositories
+// and users it looks up.
+type exporter struct {
+	source string
+	repos  map[api.RepoID]api.RepoName // "" means not found
+	users  map[int32]*User
+}
+
+// thread returns the exported form of the thread, or nil if its target
+// repository no longer exists.
+func (e *exporter) thread(ctx context.Context, thread *types.DiscussionThread) (*Thread, error) {
+	author, err := e.user(ctx, thread.AuthorUserID)
+	if err != nil {
+		return nil, err
+	}
+	t := &Thread{
+		ID:         thread.ID,
+		Source:     e.source,
+		Title:      thread.Title,
+		Author:     author,
+		CreatedAt:  thread.CreatedAt,
+		UpdatedAt:  thread.UpdatedAt,
+		ArchivedAt: thread.ArchivedAt,
+		ResolvedAt: thread.ResolvedAt,
+		Labels:     thread.Labels,
+	}
+	for _, userID := range thread.AssigneeUserIDs {
+		assignee, err := e.user(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if assignee != nil {
+			t.Assignees = append(t.Assignees, assignee)
+		}
+	}
+	if tr := thread.TargetRepo; tr != nil {
+		repo, err := e.repo(ctx, tr.RepoID)
+		if err != nil {
+			return nil, err
+		}
+		if repo == "" {
+			return nil, nil
+		}
+		t.TargetRepo = &TargetRepo{
+			Repo:           repo,
+			Path:           tr.Path,
+			Branch:         tr.Branch,
+			Revision:       tr.Revision,
+			StartLine:      tr.StartLine,
+			EndLine:        tr.EndLine,
+			StartCharacter: tr.StartCharacter,
+			EndCharacter:   tr.EndCharacter,
+			LinesBefore:    tr.LinesBefore,
+			Lines:          tr.Lines,
+			LinesAfter:     tr.LinesAfter,
+		}
+	}
+
+	comments, err := db.DiscussionComments.List(ctx, &db.DiscussionCommentsListOptions{ThreadID: &thread.ID})
+	if err != nil {
+		return nil, errors.Wrap(err, "DiscussionComments.List")
+	}
+	t.Comments = make([]*Comment, 0, len(comments))
+	for _, comment := range comments {
+		c, err := e.comment(ctx, comment)
+		if err != nil {
+			return nil, err
+		}
+		t.Comments = append(t.Comments, c)
+	}
+	return t, nil
+}
+
+func (e *exporter) comment(ctx context.Context, comment *types.DiscussionComment) (*Comment, error) {
+	author, err := e.user(ctx, comment.AuthorUserID)
+	if err != nil {
+		return nil, err
+	}
+	c := &Comment{
+		ID:         comment.ID,
+		ParentID:   comment.ParentCommentID,
+		Author:     author,
+		Contents:   comment.Contents,
+		CreatedAt:  comment.CreatedAt,
+		UpdatedAt:  comment.UpdatedAt,
+		ResolvedAt: comment.ResolvedAt,
+		Reports:    comment.Reports,
+	}
+	reactions, err := db.DiscussionCommentReactions.ListByComment(ctx, comment.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "DiscussionCommentReactions.ListByComment")
+	}
+	for _, reaction := range reactions {
+		user, err := e.user(ctx, reaction.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if user != nil {
+			c.Reactions = append(c.Reactions, &Reaction{Emoji: reaction.Emoji, User: user})
+		}
+	}
+	return c, nil
+}
+
+// repo returns the name of the repository, or "" if it no longer exists.
+func (e *exporter) repo(ctx context.Context, id api.RepoID) (api.RepoName, error) {
+	if name, ok := e.repos[id]; ok {
+		return name, nil
+	}
+	repo, err := db.Repos.Get(ctx, id)
+	if errcode.IsNotFound(err) {
+		e.repos[id] = ""
+		return "", nil
+	} else if err != nil {
+		return "", errors.Wrap(err, "Repos.Get")
+	}
+	e.repos[id] = repo.Name
+	return repo.Name, nil
+}
+
+// user returns the exported form of the user, or nil if the user no longer
+// exists.
+func (e *exporter) user(ctx context.Context, id int32) (*User, error) {
+	if user, ok := e.users[id]; ok {
+		return user, nil
+	}
+	u, err := db.Users.GetByID(ctx, id)
+	if errcode.IsNotFound(err) {
+		e.users[id] = nil
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "Users.GetByID")
+	}
+	user := &User{Username: u.Username}
+	email, verified, err := db.UserEmails.GetPrimaryEmail(ctx, id)
+	if err != nil && !errcode.IsNotFound(err) {
+		return nil, errors.Wrap(err, "UserEmails.GetPrimaryEmail")
+	}
+	if verified {
+		user.Email = email
+	}
+	e.users[id] = user
+	return user, nil
+}
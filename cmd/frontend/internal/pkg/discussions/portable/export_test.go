@@ -0,0 +1,52 @@
+package portable
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/errcode"
+)
+
+func TestExport_skipsDeletedRepos(t *testing.T) {
+	db.Mocks.DiscussionThreads.List = func(ctx context.Context, opt *db.DiscussionThreadsListOptions) ([]*types.DiscussionThread, error) {
+		return []*types.DiscussionThread{
+			{ID: 1, Title: "a", AuthorUserID: 1, TargetRepo: &types.DiscussionThreadTargetRepo{RepoID: 1}},
+			{ID: 2, Title: "b", AuthorUserID: 1, TargetRepo: &types.DiscussionThreadTargetRepo{RepoID: 2}},
+		}, nil
+	}
+	db.Mocks.DiscussionComments.List = func(ctx context.Context, opt *db.DiscussionCommentsListOptions) ([]*types.DiscussionComment, error) {
+		return nil, nil
+	}
+	db.Mocks.Users.GetByID = func(ctx context.Context, id int32) (*types.User, error) {
+		return &types.User{ID: id, Username: "alice"}, nil
+	}
+	db.Mocks.UserEmails.GetPrimaryEmail = func(ctx context.Context, id int32) (string, bool, error) {
+		return "alice@example.com", true, nil
+	}
+	db.Mocks.Repos.Get = func(ctx context.Context, id api.RepoID) (*types.Repo, error) {
+		if id == 1 {
+			return &types.Repo{ID: 1, Name: "github.com/gorilla/mux"}, nil
+		}
+		return nil, &errcode.Mock{Message: "repo not found", IsNotFound: true}
+	}
+	defer func() { db.Mocks = db.MockStores{} }()
+
+	var buf bytes.Buffer
+	skipped, err := Export(context.Background(), &buf, "https://sourcegraph.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []*SkippedThread{{Title: "b", Reason: "repository not found"}}; !reflect.DeepEqual(skipped, want) {
+		t.Errorf("got skipped threads %+v, want %+v", skipped, want)
+	}
+	want := `{"id":1,"source":"https://sourcegraph.example.com","title":"a","author":{"username":"alice","email":"alice@example.com"},"createdAt":"0001-01-01T00:00:00Z","updatedAt":"0001-01-01T00:00:00Z","targetRepo":{"repo":"github.com/gorilla/mux"},"comments":[]}
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got export %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,99 @@
+// Package portable exports and imports discussions in a format that can be
+// moved between Sourcegraph instances.
+//
+// An export is newline-delimited JSON: each line is a Thread, including its
+// target and comments. Repositories are referred to by name and users by
+// username and verified email, because IDs are not meaningful on another
+// instance.
+package portable
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+)
+
+// Thread is an exported discussion thread.
+type Thread struct {
+	// ID and Source identify the thread by its ID on the instance that it was
+	// exported from and that instance's URL, so that importing the same thread
+	// again is detected.
+	ID     int64  `json:"id,omitempty"`
+	Source string `json:"source,omitempty"`
+
+	Title      string      `json:"title"`
+	Author     *User       `json:"author"`
+	CreatedAt  time.Time   `json:"createdAt"`
+	UpdatedAt  time.Time   `json:"updatedAt"`
+	ArchivedAt *time.Time  `json:"archivedAt,omitempty"`
+	ResolvedAt *time.Time  `json:"resolvedAt,omitempty"`
+	Labels     []string    `json:"labels,omitempty"`
+	Assignees  []*User     `json:"assignees,omitempty"`
+	TargetRepo *TargetRepo `json:"targetRepo"`
+	Comments   []*Comment  `json:"comments"`
+}
+
+// TargetRepo is the repository (and optionally the file and selection) that an
+// exported thread is about.
+type TargetRepo struct {
+	Repo     api.RepoName `json:"repo"`
+	Path     *string      `json:"path,omitempty"`
+	Branch   *string      `json:"branch,omitempty"`
+	Revision *string      `json:"revision,omitempty"`
+
+	StartLine      *int32    `json:"startLine,omitempty"`
+	EndLine        *int32    `json:"endLine,omitempty"`
+	StartCharacter *int32    `json:"startCharacter,omitempty"`
+	EndCharacter   *int32    `json:"endCharacter,omitempty"`
+	LinesBefore    *[]string `json:"linesBefore,omitempty"`
+	Lines          *[]string `json:"lines,omitempty"`
+	LinesAfter     *[]string `json:"linesAfter,omitempty"`
+}
+
+// Comment is an exported discussion comment.
+type Comment struct {
+	// ID identifies the comment within the export, so that replies can refer
+	// to it. It has no meaning on the importing instance.
+	ID       int64  `json:"id"`
+	ParentID *int64 `json:"parentID,omitempty"`
+
+	Author     *User       `json:"author"`
+	Contents   string      `json:"contents"`
+	CreatedAt  time.Time   `json:"createdAt"`
+	UpdatedAt  time.Time   `json:"updatedAt"`
+	ResolvedAt *time.Time  `json:"resolvedAt,omitempty"`
+	Reports    []string    `json:"reports,omitempty"`
+	Reactions  []*Reaction `json:"reactions,omitempty"`
+}
+
+// Reaction is an exported emoji reaction to a comment.
+type Reaction struct {
+	Emoji string `json:"emoji"`
+	User  *User  `json:"user"`
+}
+
+// User refers to a user by their username and, if they have one, their
+// verified primary email address.
+type User struct {
+	Username string `json:"username"`
+	Email    string `json:"email,omitempty"`
+}
+
+// String returns a human-readable reference to the user, for reports.
+func (u *User) String() string {
+	if u.Email != "" {
+		return u.Username + " <" + u.Email + ">"
+	}
+	return u.Username
+}
+
+// importID returns the ID that the thread is recorded with when it is
+// imported, or an empty string if it has no ID (and can't be recognized when
+// imported again).
+func (t *Thread) importID() string {
+	if t.ID == 0 || t.Source == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s#%d", t.Source, t.ID)
+}
@@ -0,0 +1,355 @@
+package portable
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/errcode"
+)
+
+// ImportReport describes the result of an import, including everything that
+// could not be matched on this instance.
+type ImportReport struct {
+	ThreadsImported  int              `json:"threadsImported"`
+	CommentsImported int              `json:"commentsImported"`
+	SkippedThreads   []*SkippedThread `json:"skippedThreads"`
+
+	// UnmatchedRepos are the repositories that do not exist on this instance.
+	// Threads about them are skipped.
+	UnmatchedRepos []api.RepoName `json:"unmatchedRepos"`
+
+	// UnmatchedUsers are the users that do not exist on this instance. Threads
+	// and comments they authored are attributed to the importing user, and
+	// their assignments and reactions are dropped.
+	UnmatchedUsers []string `json:"unmatchedUsers"`
+
+	// UsernameMatchedComments are the comments whose author was matched by
+	// username only (not by verified email). Usernames may refer to different
+	// people on different instances, so these should be checked.
+	UsernameMatchedComments []*UsernameMatchedComment `json:"usernameMatchedComments"`
+}
+
+// SkippedThread is a thread that was not imported (or exported).
+type SkippedThread struct {
+	Title  string `json:"title"`
+	Reason string `json:"reason"`
+}
+
+// UsernameMatchedComment is an imported comment whose author was matched by
+// username only.
+type UsernameMatchedComment struct {
+	Thread    string `json:"thread"`    // the title of the thread
+	CommentID int64  `json:"commentID"` // the ID of the comment on this instance
+	Author    string `json:"author"`    // the exported author
+}
+
+// Import reads threads in the format written by Export from r and creates
+// them, preserving their times. Repositories are matched by name, and users by
+// verified email and then by username.
+//
+// Threads that were already imported are skipped, so an import that failed
+// partway can be retried. A thread that fails to import is deleted, so it is
+// imported again when the import is retried.
+//
+// Importing does not send notifications.
+//
+// 🚨 SECURITY: The caller must ensure that the actor (the importer) is a site
+// admin.
+func Import(ctx context.Context, r io.Reader, importer *types.User) (*ImportReport, error) {
+	im := newImporterState(importer)
+	dec := json.NewDecoder(r)
+	for {
+		var thread Thread
+		if err := dec.Decode(&thread); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, errors.Wrap(err, "decoding thread")
+		}
+		if err := im.importThread(ctx, &thread); err != nil {
+			return nil, errors.Wrapf(err, "importing thread %q", thread.Title)
+		}
+	}
+	return im.finish(), nil
+}
+
+// importerState holds the state of an import. It caches lookups of
+// repositories and users (0 means not found) and records what could not be
+// matched.
+type importerState struct {
+	importer *types.User
+	report   ImportReport
+
+	repos           map[api.RepoName]api.RepoID
+	users           map[User]int32
+	usernameMatches map[User]bool // users matched by username only
+	unmatchedRepos  map[api.RepoName]struct{}
+	unmatchedUsers  map[string]struct{}
+}
+
+func newImporterState(importer *types.User) *importerState {
+	return &importerState{
+		importer:        importer,
+		repos:           map[api.RepoName]api.RepoID{},
+		users:           map[User]int32{},
+		usernameMatches: map[User]bool{},
+		unmatchedRepos:  map[api.RepoName]struct{}{},
+		unmatchedUsers:  map[string]struct{}{},
+	}
+}
+
+func (im *importerState) importThread(ctx context.Context, thread *Thread) error {
+	importID := thread.importID()
+	if importID != "" {
+		exists, err := db.DiscussionThreads.ImportedThreadExists(ctx, importID)
+		if err != nil {
+			return errors.Wrap(err, "DiscussionThreads.ImportedThreadExists")
+		}
+		if exists {
+			im.skip(thread, "thread was already imported")
+			return nil
+		}
+	}
+	if thread.TargetRepo == nil {
+		im.skip(thread, "thread has no target repository")
+		return nil
+	}
+	repoID, err := im.repo(ctx, thread.TargetRepo.Repo)
+	if err != nil {
+		return err
+	}
+	if repoID == 0 {
+		im.skip(thread, "repository "+string(thread.TargetRepo.Repo)+" not found")
+		return nil
+	}
+
+	authorID, err := im.authorUserID(ctx, thread.Author)
+	if err != nil {
+		return err
+	}
+	tr := thread.TargetRepo
+	newThread, err := db.DiscussionThreads.Create(ctx, &types.DiscussionThread{
+		AuthorUserID: authorID,
+		Title:        thread.Title,
+		TargetRepo: &types.DiscussionThreadTargetRepo{
+			RepoID:         repoID,
+			Path:           tr.Path,
+			Branch:         tr.Branch,
+			Revision:       tr.Revision,
+			StartLine:      tr.StartLine,
+			EndLine:        tr.EndLine,
+			StartCharacter: tr.StartCharacter,
+			EndCharacter:   tr.EndCharacter,
+			LinesBefore:    tr.LinesBefore,
+			Lines:          tr.Lines,
+			LinesAfter:     tr.LinesAfter,
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "DiscussionThreads.Create")
+	}
+	if err := im.importThreadContents(ctx, newThread.ID, importID, thread); err != nil {
+		if _, err2 := db.DiscussionThreads.Update(ctx, newThread.ID, &db.DiscussionThreadsUpdateOptions{Delete: true}); err2 != nil {
+			return errors.Wrapf(err, "deleting partially imported thread failed (%s)", err2)
+		}
+		return err
+	}
+	im.report.ThreadsImported++
+	return nil
+}
+
+// importThreadContents imports everything but the thread itself (which has
+// been created already).
+func (im *importerState) importThreadContents(ctx context.Context, threadID int64, importID string, thread *Thread) error {
+	if importID != "" {
+		if err := db.DiscussionThreads.SetImportID(ctx, threadID, importID); err != nil {
+			return errors.Wrap(err, "DiscussionThreads.SetImportID")
+		}
+	}
+
+	update := &db.DiscussionThreadsUpdateOptions{}
+	if len(thread.Labels) > 0 {
+		update.Labels = &thread.Labels
+	}
+	for _, assignee := range thread.Assignees {
+		userID, err := im.userID(ctx, assignee)
+		if err != nil {
+			return err
+		}
+		if userID != 0 {
+			update.AddAssigneeUserIDs = append(update.AddAssigneeUserIDs, userID)
+		}
+	}
+	if update.Labels != nil || len(update.AddAssigneeUserIDs) > 0 {
+		if _, err := db.DiscussionThreads.Update(ctx, threadID, update); err != nil {
+			return errors.Wrap(err, "DiscussionThreads.Update")
+		}
+	}
+
+	// Comments are exported in creation order, so a comment's parent is always
+	// imported before it.
+	commentIDs := map[int64]int64{} // exported ID -> new ID
+	for _, comment := range thread.Comments {
+		newID, err := im.importComment(ctx, threadID, thread.Title, comment, commentIDs)
+		if err != nil {
+			return errors.Wrapf(err, "importing comment %d", comment.ID)
+		}
+		commentIDs[comment.ID] = newID
+	}
+
+	// Set the times last, because the updates above bump them.
+	if err := db.DiscussionThreads.SetTimes(ctx, threadID, thread.CreatedAt, thread.UpdatedAt, thread.ArchivedAt, thread.ResolvedAt); err != nil {
+		return errors.Wrap(err, "DiscussionThreads.SetTimes")
+	}
+	return nil
+}
+
+func (im *importerState) importComment(ctx context.Context, threadID int64, threadTitle string, comment *Comment, commentIDs map[int64]int64) (int64, error) {
+	authorID, err := im.authorUserID(ctx, comment.Author)
+	if err != nil {
+		return 0, err
+	}
+	newComment := &types.DiscussionComment{
+		ThreadID:     threadID,
+		AuthorUserID: authorID,
+		Contents:     comment.Contents,
+	}
+	if comment.ParentID != nil {
+		if parentID, ok := commentIDs[*comment.ParentID]; ok {
+			newComment.ParentCommentID = &parentID
+		}
+	}
+	newComment, err = db.DiscussionComments.Create(ctx, newComment)
+	if err != nil {
+		return 0, errors.Wrap(err, "DiscussionComments.Create")
+	}
+
+	for _, report := range comment.Reports {
+		report := report
+		if _, err := db.DiscussionComments.Update(ctx, newComment.ID, &db.DiscussionCommentsUpdateOptions{Report: &report}); err != nil {
+			return 0, errors.Wrap(err, "DiscussionComments.Update")
+		}
+	}
+	for _, reaction := range comment.Reactions {
+		userID, err := im.userID(ctx, reaction.User)
+		if err != nil {
+			return 0, err
+		}
+		if userID == 0 {
+			continue
+		}
+		if err := db.DiscussionCommentReactions.Add(ctx, newComment.ID, userID, reaction.Emoji); err != nil {
+			return 0, errors.Wrap(err, "DiscussionCommentReactions.Add")
+		}
+	}
+
+	if err := db.DiscussionComments.SetTimes(ctx, newComment.ID, comment.CreatedAt, comment.UpdatedAt, comment.ResolvedAt); err != nil {
+		return 0, errors.Wrap(err, "DiscussionComments.SetTimes")
+	}
+	if comment.Author != nil && im.usernameMatches[*comment.Author] {
+		im.report.UsernameMatchedComments = append(im.report.UsernameMatchedComments, &UsernameMatchedComment{
+			Thread:    threadTitle,
+			CommentID: newComment.ID,
+			Author:    comment.Author.String(),
+		})
+	}
+	im.report.CommentsImported++
+	return newComment.ID, nil
+}
+
+func (im *importerState) skip(thread *Thread, reason string) {
+	im.report.SkippedThreads = append(im.report.SkippedThreads, &SkippedThread{Title: thread.Title, Reason: reason})
+}
+
+// repo returns the ID of the repository with the name, or 0 if there is none.
+func (im *importerState) repo(ctx context.Context, name api.RepoName) (api.RepoID, error) {
+	if id, ok := im.repos[name]; ok {
+		return id, nil
+	}
+	repo, err := db.Repos.GetByName(ctx, name)
+	if errcode.IsNotFound(err) {
+		im.repos[name] = 0
+		im.unmatchedRepos[name] = struct{}{}
+		return 0, nil
+	} else if err != nil {
+		return 0, errors.Wrap(err, "Repos.GetByName")
+	}
+	im.repos[name] = repo.ID
+	return repo.ID, nil
+}
+
+// authorUserID returns the ID of the user, falling back to the importing user
+// if the user can't be matched.
+func (im *importerState) authorUserID(ctx context.Context, user *User) (int32, error) {
+	id, err := im.userID(ctx, user)
+	if id == 0 && err == nil {
+		id = im.importer.ID
+	}
+	return id, err
+}
+
+// userID returns the ID of the user matching the exported user, or 0 if there
+// is none. Users are matched by verified email first, because usernames may
+// refer to different people on different instances.
+func (im *importerState) userID(ctx context.Context, user *User) (int32, error) {
+	if user == nil {
+		// The user had been deleted at the time of the export.
+		return 0, nil
+	}
+	if id, ok := im.users[*user]; ok {
+		return id, nil
+	}
+
+	var u *types.User
+	if user.Email != "" {
+		var err error
+		u, err = db.Users.GetByVerifiedEmail(ctx, user.Email)
+		if err != nil && !errcode.IsNotFound(err) {
+			return 0, errors.Wrap(err, "Users.GetByVerifiedEmail")
+		}
+	}
+	if u == nil && user.Username != "" {
+		var err error
+		u, err = db.Users.GetByUsername(ctx, user.Username)
+		if err != nil && !errcode.IsNotFound(err) {
+			return 0, errors.Wrap(err, "Users.GetByUsername")
+		}
+		im.usernameMatches[*user] = u != nil
+	}
+
+	var id int32
+	if u != nil {
+		id = u.ID
+	} else {
+		im.unmatchedUsers[user.String()] = struct{}{}
+	}
+	im.users[*user] = id
+	return id, nil
+}
+
+// finish returns the report, with the unmatched repositories and users sorted.
+func (im *importerState) finish() *ImportReport {
+	report := im.report
+	report.UnmatchedRepos = []api.RepoName{}
+	for name := range im.unmatchedRepos {
+		report.UnmatchedRepos = append(report.UnmatchedRepos, name)
+	}
+	sort.Slice(report.UnmatchedRepos, func(i, j int) bool { return report.UnmatchedRepos[i] < report.UnmatchedRepos[j] })
+	report.UnmatchedUsers = []string{}
+	for user := range im.unmatchedUsers {
+		report.UnmatchedUsers = append(report.UnmatchedUsers, user)
+	}
+	sort.Strings(report.UnmatchedUsers)
+	if report.SkippedThreads == nil {
+		report.SkippedThreads = []*SkippedThread{}
+	}
+	if report.UsernameMatchedComments == nil {
+		report.UsernameMatchedComments = []*UsernameMatchedComment{}
+	}
+	return &report
+}
@@ -0,0 +1,130 @@
+package portable
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/errcode"
+)
+
+func TestImport_skipsUnmatchedRepos(t *testing.T) {
+	db.Mocks.Repos.GetByName = func(ctx context.Context, name api.RepoName) (*types.Repo, error) {
+		return nil, &errcode.Mock{Message: "repo not found", IsNotFound: true}
+	}
+	defer func() { db.Mocks = db.MockStores{} }()
+
+	export := `{"title":"a","targetRepo":{"repo":"github.com/gorilla/mux"},"comments":[]}
+{"title":"b","comments":[]}
+{"title":"c","targetRepo":{"repo":"github.com/gorilla/mux"},"comments":[]}
+`
+	report, err := Import(context.Background(), strings.NewReader(export), &types.User{ID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &ImportReport{
+		SkippedThreads: []*SkippedThread{
+			{Title: "a", Reason: "repository github.com/gorilla/mux not found"},
+			{Title: "b", Reason: "thread has no target repository"},
+			{Title: "c", Reason: "repository github.com/gorilla/mux not found"},
+		},
+		UnmatchedRepos:          []api.RepoName{"github.com/gorilla/mux"},
+		UnmatchedUsers:          []string{},
+		UsernameMatchedComments: []*UsernameMatchedComment{},
+	}
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("got report %+v, want %+v", report, want)
+	}
+}
+
+func TestImporterState_userID(t *testing.T) {
+	db.Mocks.Users.GetByVerifiedEmail = func(ctx context.Context, email string) (*types.User, error) {
+		if email == "alice@example.com" {
+			return &types.User{ID: 2, Username: "alice2"}, nil
+		}
+		return nil, db.MockUserNotFoundErr
+	}
+	db.Mocks.Users.GetByUsername = func(ctx context.Context, username string) (*types.User, error) {
+		switch username {
+		case "alice":
+			return &types.User{ID: 3, Username: "alice"}, nil
+		case "bob":
+			return &types.User{ID: 4, Username: "bob"}, nil
+		}
+		return nil, db.MockUserNotFoundErr
+	}
+	defer func() { db.Mocks = db.MockStores{} }()
+
+	im := newImporterState(&types.User{ID: 1})
+	tests := []struct {
+		user           *User
+		want, wantAuth int32
+	}{
+		{user: &User{Username: "alice", Email: "alice@example.com"}, want: 2, wantAuth: 2}, // email takes precedence
+		{user: &User{Username: "bob", Email: "bob@example.com"}, want: 4, wantAuth: 4},
+		{user: &User{Username: "carol", Email: "carol@example.com"}, want: 0, wantAuth: 1},
+		{user: &User{Username: "dave"}, want: 0, wantAuth: 1},
+		{user: nil, want: 0, wantAuth: 1},
+	}
+	for _, test := range tests {
+		got, err := im.userID(context.Background(), test.user)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != test.want {
+			t.Errorf("%v: got user ID %d, want %d", test.user, got, test.want)
+		}
+		got, err = im.authorUserID(context.Background(), test.user)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != test.wantAuth {
+			t.Errorf("%v: got author user ID %d, want %d", test.user, got, test.wantAuth)
+		}
+	}
+	if got, want := im.finish().UnmatchedUsers, []string{"carol <carol@example.com>", "dave"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got unmatched users %q, want %q", got, want)
+	}
+	wantUsernameMatches := map[User]bool{
+		{Username: "bob", Email: "bob@example.com"}:     true,
+		{Username: "carol", Email: "carol@example.com"}: false,
+		{Username: "dave"}: false,
+	}
+	if !reflect.DeepEqual(im.usernameMatches, wantUsernameMatches) {
+		t.Errorf("got username matches %v, want %v", im.usernameMatches, wantUsernameMatches)
+	}
+}
+
+func TestImport_skipsImportedThreads(t *testing.T) {
+	db.Mocks.DiscussionThreads.ImportedThreadExists = func(ctx context.Context, importID string) (bool, error) {
+		return importID == "https://a.example.com#1", nil
+	}
+	db.Mocks.DiscussionThreads.Create = func(ctx context.Context, newThread *types.DiscussionThread) (*types.DiscussionThread, error) {
+		t.Errorf("unexpected call to DiscussionThreads.Create for thread %q", newThread.Title)
+		return nil, errors.New("unexpected call")
+	}
+	db.Mocks.Repos.GetByName = func(ctx context.Context, name api.RepoName) (*types.Repo, error) {
+		return nil, &errcode.Mock{Message: "repo not found", IsNotFound: true}
+	}
+	defer func() { db.Mocks = db.MockStores{} }()
+
+	export := `{"id":1,"source":"https://a.example.com","title":"a","targetRepo":{"repo":"github.com/gorilla/mux"},"comments":[]}
+{"id":1,"source":"https://b.example.com","title":"b","targetRepo":{"repo":"github.com/gorilla/mux"},"comments":[]}
+`
+	report, err := Import(context.Background(), strings.NewReader(export), &types.User{ID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*SkippedThread{
+		{Title: "a", Reason: "thread was already imported"},
+		{Title: "b", Reason: "repository github.com/gorilla/mux not found"},
+	}
+	if !reflect.DeepEqual(report.SkippedThreads, want) {
+		t.Errorf("got skipped threads %+v, want %+v", report.SkippedThreads, want)
+	}
+}
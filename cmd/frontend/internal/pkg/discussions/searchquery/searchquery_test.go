@@ -36,6 +36,12 @@ func TestParse(t *testing.T) {
 			wantRemaining:  "fuzzytitleprefixmatch:",
 			wantOperations: [][2]string{{"foo", "bar"}},
 		},
+		{
+			name:           "state_assignee_label",
+			input:          `is:open assignee:me label:security -label:"won't fix" todo`,
+			wantRemaining:  "todo",
+			wantOperations: [][2]string{{"is", "open"}, {"assignee", "me"}, {"label", "security"}, {"-label", "won't fix"}},
+		},
 		{
 			name:           "escaped_operation",
 			input:          `fuzzytitleprefixmatch: foo\:bar`,
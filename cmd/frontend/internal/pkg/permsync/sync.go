@@ -0,0 +1,131 @@
+package permsync
+
+import (
+	"context"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc"
+	log15 "gopkg.in/inconshreveable/log15.v2"
+)
+
+// syncUser computes the permissions of the user (or of anonymous users, if user is nil) on the
+// repositories from the authz providers and stores them. If that fails, the error is recorded and
+// the previously synced permissions are kept.
+//
+// The repositories must be all repositories (see listRepos).
+func syncUser(ctx context.Context, user *types.User, repos []*types.Repo) error {
+	var userID int32 // 0 for anonymous users
+	if user != nil {
+		userID = user.ID
+	}
+
+	repoIDs, err := readRepoIDs(ctx, user, repos)
+	if err != nil {
+		if err2 := db.UserPermissions.SetSyncError(ctx, userID, err.Error()); err2 != nil {
+			log15.Error("permsync: error while recording sync error", "user", userID, "error", err2)
+		}
+		return err
+	}
+	return db.UserPermissions.SetSynced(ctx, userID, repoIDs)
+}
+
+// listRepos returns all repositories, whose permissions are synced. They are listed once for all
+// users synced at the same time.
+//
+// The context must be that of an internal actor, so that all repositories are listed.
+func listRepos(ctx context.Context) ([]*types.Repo, error) {
+	return db.Repos.List(ctx, db.ReposListOptions{Enabled: true, Disabled: true})
+}
+
+// readRepoIDs returns the IDs of the repositories that the user (or an anonymous user, if user is
+// nil) may read, among those for which an authz provider is the source of permissions.
+func readRepoIDs(ctx context.Context, user *types.User, repos []*types.Repo) ([]api.RepoID, error) {
+	_, authzProviders := authz.GetProviders()
+	if len(authzProviders) == 0 {
+		return nil, nil
+	}
+
+	readable, err := readableRepos(ctx, user, authz.ToRepos(repos), authzProviders, authz.Read)
+	if err != nil {
+		return nil, err
+	}
+
+	repoIDs := make([]api.RepoID, 0, len(readable))
+	for _, repo := range repos {
+		if _, ok := readable[repo.Name]; ok {
+			repoIDs = append(repoIDs, repo.ID)
+		}
+	}
+	return repoIDs, nil
+}
+
+// readableRepos returns the names of the repositories that the user (or an anonymous user, if user
+// is nil) has permission p on, among those that are claimed by an authz provider. Repositories that
+// are not claimed by any authz provider are never returned; whether they may be accessed is decided
+// at request time (see authz.GetProviders).
+//
+// If the user has no external account for an authz provider, it is fetched from the provider and
+// saved.
+func readableRepos(ctx context.Context, user *types.User, repos map[authz.Repo]struct{}, authzProviders []authz.Provider, p authz.Perm) (accepted map[api.RepoName]struct{}, err error) {
+	var accts []*extsvc.ExternalAccount
+	if user != nil {
+		accts, err = db.ExternalAccounts.List(ctx, db.ExternalAccountsListOptions{UserID: user.ID})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	accepted = make(map[api.RepoName]struct{}) // repositories that have been claimed and have the permission
+	unverified := repos                        // repositories that have not been claimed by any authz provider
+
+	// Walk through all authz providers, checking repo permissions against each. If any own a given
+	// repo, we use its permissions for that repo.
+	for _, authzProvider := range authzProviders {
+		if len(unverified) == 0 {
+			break
+		}
+
+		// determine external account to use
+		var providerAcct *extsvc.ExternalAccount
+		for _, acct := range accts {
+			if acct.ServiceID == authzProvider.ServiceID() && acct.ServiceType == authzProvider.ServiceType() {
+				providerAcct = acct
+				break
+			}
+		}
+		if providerAcct == nil && user != nil { // no existing external account for authz provider
+			if pr, err := authzProvider.FetchAccount(ctx, user, accts); err == nil {
+				providerAcct = pr
+				if providerAcct != nil && providerAcct.ServiceType != authz.SourcegraphServiceType {
+					err := db.ExternalAccounts.AssociateUserAndSave(ctx, user.ID, providerAcct.ExternalAccountSpec, providerAcct.ExternalAccountData)
+					if err != nil {
+						return nil, err
+					}
+				}
+			} else {
+				log15.Warn("Could not fetch authz provider account for user", "username", user.Username, "authzProvider", authzProvider.ServiceID(), "error", err)
+			}
+		}
+
+		// determine which repos "belong" to this authz provider
+		myUnverified, nextUnverified := authzProvider.Repos(ctx, unverified)
+
+		// check the perms on those repos
+		perms, err := authzProvider.RepoPerms(ctx, providerAcct, myUnverified)
+		if err != nil {
+			return nil, err
+		}
+		for unverifiedRepo := range myUnverified {
+			if repoPerms, ok := perms[unverifiedRepo.RepoName]; ok && repoPerms[p] {
+				accepted[unverifiedRepo.RepoName] = struct{}{}
+			}
+		}
+		// continue checking repos that didn't belong to this authz provider
+		unverified = nextUnverified
+	}
+
+	return accepted, nil
+}
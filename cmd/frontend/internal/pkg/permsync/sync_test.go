@@ -0,0 +1,421 @@
+package permsync
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc"
+)
+
+type readableRepos_Test struct {
+	description string
+
+	authzProviders []authz.Provider
+
+	calls []readableRepos_call
+}
+
+type readableRepos_call struct {
+	description string
+
+	user         *types.User
+	userAccounts []*extsvc.ExternalAccount
+
+	repos []api.RepoName
+
+	expReadable []api.RepoName
+}
+
+func (r readableRepos_Test) run(t *testing.T) {
+	t.Logf("Test case %q", r.description)
+	defer func() { db.Mocks = db.MockStores{} }()
+
+	for _, c := range r.calls {
+		t.Logf("Call %q", c.description)
+
+		db.Mocks.ExternalAccounts.AssociateUserAndSave = func(userID int32, spec extsvc.ExternalAccountSpec, data extsvc.ExternalAccountData) error { return nil }
+		db.Mocks.ExternalAccounts.List = func(db.ExternalAccountsListOptions) ([]*extsvc.ExternalAccount, error) { return c.userAccounts, nil }
+
+		repos := make(map[authz.Repo]struct{}, len(c.repos))
+		for _, name := range c.repos {
+			repos[authz.Repo{RepoName: name}] = struct{}{}
+		}
+		readable, err := readableRepos(context.Background(), c.user, repos, r.authzProviders, authz.Read)
+		if err != nil {
+			t.Fatal(err)
+		}
+		exp := make(map[api.RepoName]struct{}, len(c.expReadable))
+		for _, name := range c.expReadable {
+			exp[name] = struct{}{}
+		}
+		if !reflect.DeepEqual(readable, exp) {
+			t.Errorf("Expected readable repos\n\t%v\n, but got\n\t%v", exp, readable)
+		}
+	}
+}
+
+func Test_readableRepos(t *testing.T) {
+	tests := []readableRepos_Test{
+		{
+			description: "1 authz provider, ext account exists",
+			authzProviders: []authz.Provider{
+				&mockAuthzProvider{
+					serviceID:   "https://gitlab.mine/",
+					serviceType: "gitlab",
+					repos: map[api.RepoName]struct{}{
+						"gitlab.mine/u1/r0":            {},
+						"gitlab.mine/u2/r0":            {},
+						"gitlab.mine/sharedPrivate/r0": {},
+						"gitlab.mine/org/r0":           {},
+					},
+					perms: map[extsvc.ExternalAccount]map[api.RepoName]map[authz.Perm]bool{
+						*acct(1, "gitlab", "https://gitlab.mine/", "u1"): {
+							"gitlab.mine/u1/r0":            {authz.Read: true},
+							"gitlab.mine/sharedPrivate/r0": {authz.Read: true},
+							"gitlab.mine/org/r0":           {authz.Read: true},
+						},
+						*acct(2, "gitlab", "https://gitlab.mine/", "u2"): {
+							"gitlab.mine/u2/r0":            {authz.Read: true},
+							"gitlab.mine/sharedPrivate/r0": {authz.Read: true},
+							"gitlab.mine/org/r0":           {authz.Read: true},
+						},
+						{}: {
+							"gitlab.mine/org/r0": {authz.Read: true},
+						},
+					},
+				},
+			},
+			calls: []readableRepos_call{
+				{
+					description:  "u1 can read its own repo",
+					user:         &types.User{ID: 1},
+					userAccounts: []*extsvc.ExternalAccount{acct(1, "gitlab", "https://gitlab.mine/", "u1")},
+					repos:        []api.RepoName{"gitlab.mine/u1/r0"},
+					expReadable:  []api.RepoName{"gitlab.mine/u1/r0"},
+				},
+				{
+					description:  "u1 not allowed to read u2's repo",
+					user:         &types.User{ID: 1},
+					userAccounts: []*extsvc.ExternalAccount{acct(1, "gitlab", "https://gitlab.mine/", "u1")},
+					repos:        []api.RepoName{"gitlab.mine/u1/r0", "gitlab.mine/u2/r0", "gitlab.mine/sharedPrivate/r0", "gitlab.mine/org/r0"},
+					expReadable:  []api.RepoName{"gitlab.mine/u1/r0", "gitlab.mine/sharedPrivate/r0", "gitlab.mine/org/r0"},
+				},
+				{
+					description:  "u99 not allowed to read anyone's repo",
+					user:         &types.User{ID: 1},
+					userAccounts: []*extsvc.ExternalAccount{acct(99, "gitlab", "https://gitlab.mine/", "u99")},
+					repos:        []api.RepoName{"gitlab.mine/u1/r0", "gitlab.mine/u2/r0", "gitlab.mine/sharedPrivate/r0", "gitlab.mine/org/r0"},
+					expReadable:  []api.RepoName{"gitlab.mine/org/r0"},
+				},
+				{
+					description:  "unmanaged repos are not returned",
+					user:         &types.User{ID: 1},
+					userAccounts: []*extsvc.ExternalAccount{acct(1, "gitlab", "https://gitlab.mine/", "u1")},
+					repos:        []api.RepoName{"gitlab.mine/u1/r0", "otherHost/r0"},
+					expReadable:  []api.RepoName{"gitlab.mine/u1/r0"},
+				},
+				{
+					description:  "authenticated but 0 accounts can read public repos",
+					user:         &types.User{ID: 1},
+					userAccounts: nil,
+					repos:        []api.RepoName{"gitlab.mine/u1/r0", "gitlab.mine/u2/r0", "gitlab.mine/sharedPrivate/r0", "gitlab.mine/org/r0"},
+					expReadable:  []api.RepoName{"gitlab.mine/org/r0"},
+				},
+				{
+					description:  "unauthenticated can read public repos",
+					user:         nil,
+					userAccounts: nil,
+					repos:        []api.RepoName{"gitlab.mine/u1/r0", "gitlab.mine/u2/r0", "gitlab.mine/sharedPrivate/r0", "gitlab.mine/org/r0"},
+					expReadable:  []api.RepoName{"gitlab.mine/org/r0"},
+				},
+			},
+		},
+		{
+			description: "2 authz providers, ext accounts exist",
+			authzProviders: []authz.Provider{
+				&mockAuthzProvider{
+					serviceID:   "https://gitlab0.mine/",
+					serviceType: "gitlab",
+					repos: map[api.RepoName]struct{}{
+						"gitlab0.mine/u1/r0":  {},
+						"gitlab0.mine/u2/r0":  {},
+						"gitlab0.mine/org/r0": {},
+					},
+					perms: map[extsvc.ExternalAccount]map[api.RepoName]map[authz.Perm]bool{
+						*acct(1, "gitlab", "https://gitlab0.mine/", "u1"): {
+							"gitlab0.mine/u1/r0":  {authz.Read: true},
+							"gitlab0.mine/org/r0": {authz.Read: true},
+						},
+					},
+				},
+				&mockAuthzProvider{
+					serviceID:   "https://gitlab1.mine/",
+					serviceType: "gitlab",
+					repos: map[api.RepoName]struct{}{
+						"gitlab1.mine/u1/r0":  {},
+						"gitlab1.mine/u2/r0":  {},
+						"gitlab1.mine/org/r0": {},
+					},
+					perms: map[extsvc.ExternalAccount]map[api.RepoName]map[authz.Perm]bool{
+						*acct(1, "gitlab", "https://gitlab1.mine/", "u1"): {
+							"gitlab1.mine/u1/r0":  {authz.Read: true},
+							"gitlab1.mine/org/r0": {authz.Read: true},
+						},
+					},
+				},
+			},
+			calls: []readableRepos_call{
+				{
+					description: "u1 can read its own repos, but not others'",
+					user:        &types.User{ID: 1},
+					userAccounts: []*extsvc.ExternalAccount{
+						acct(1, "gitlab", "https://gitlab0.mine/", "u1"),
+						acct(1, "gitlab", "https://gitlab1.mine/", "u1"),
+					},
+					repos: []api.RepoName{
+						"gitlab0.mine/u1/r0", "gitlab0.mine/u2/r0", "gitlab0.mine/org/r0",
+						"gitlab1.mine/u1/r0", "gitlab1.mine/u2/r0", "gitlab1.mine/org/r0",
+						"gitlab2.mine/u2/r0",
+					},
+					expReadable: []api.RepoName{"gitlab0.mine/u1/r0", "gitlab0.mine/org/r0", "gitlab1.mine/u1/r0", "gitlab1.mine/org/r0"},
+				},
+				{
+					description:  "u1 with external account on one instance, can't read repos from the other",
+					user:         &types.User{ID: 1},
+					userAccounts: []*extsvc.ExternalAccount{acct(1, "gitlab", "https://gitlab1.mine/", "u1")},
+					repos: []api.RepoName{
+						"gitlab0.mine/u1/r0", "gitlab0.mine/u2/r0", "gitlab0.mine/org/r0",
+						"gitlab1.mine/u1/r0", "gitlab1.mine/u2/r0", "gitlab1.mine/org/r0",
+					},
+					expReadable: []api.RepoName{"gitlab1.mine/u1/r0", "gitlab1.mine/org/r0"},
+				},
+			},
+		},
+		{
+			description: "1 authz provider, ext account doesn't exist",
+			authzProviders: []authz.Provider{
+				&mockAuthzProvider{
+					serviceID:    "https://gitlab.mine/",
+					serviceType:  "gitlab",
+					okServiceIDs: map[string]struct{}{"https://okta.mine/": {}},
+					repos: map[api.RepoName]struct{}{
+						"gitlab.mine/u1/r0":     {},
+						"gitlab.mine/u2/r0":     {},
+						"gitlab.mine/public/r0": {},
+					},
+					perms: map[extsvc.ExternalAccount]map[api.RepoName]map[authz.Perm]bool{
+						*acct(1, "gitlab", "https://gitlab.mine/", "u1"): {
+							"gitlab.mine/u1/r0":     {authz.Read: true},
+							"gitlab.mine/public/r0": {authz.Read: true},
+						},
+						// entry for nil account / anonymous users
+						{}: {
+							"gitlab.mine/public/r0": {authz.Read: true},
+						},
+					},
+				},
+			},
+			calls: []readableRepos_call{
+				{
+					description:  "u1 has access to the right repos",
+					user:         &types.User{ID: 1},
+					userAccounts: []*extsvc.ExternalAccount{acct(1, "saml", "https://okta.mine/", "u1")},
+					repos:        []api.RepoName{"gitlab.mine/u1/r0", "gitlab.mine/u2/r0", "gitlab.mine/public/r0"},
+					expReadable:  []api.RepoName{"gitlab.mine/u1/r0", "gitlab.mine/public/r0"},
+				},
+				{
+					description:  "u99 has access to public repos only",
+					user:         &types.User{ID: 1},
+					userAccounts: []*extsvc.ExternalAccount{acct(1, "saml", "https://okta.mine/", "u99")},
+					repos:        []api.RepoName{"gitlab.mine/u1/r0", "gitlab.mine/u2/r0", "gitlab.mine/public/r0"},
+					expReadable:  []api.RepoName{"gitlab.mine/public/r0"},
+				},
+				{
+					description:  "service ID does not match",
+					user:         &types.User{ID: 1},
+					userAccounts: []*extsvc.ExternalAccount{acct(1, "saml", "https://rando.mine/", "u1")},
+					repos:        []api.RepoName{"gitlab.mine/u1/r0", "gitlab.mine/u2/r0", "gitlab.mine/public/r0"},
+					expReadable:  []api.RepoName{"gitlab.mine/public/r0"},
+				},
+			},
+		},
+	}
+	for _, test := range tests {
+		test.run(t)
+	}
+}
+
+func Test_readableRepos_createsNewAccounts(t *testing.T) {
+	defer func() { db.Mocks = db.MockStores{} }()
+
+	associated := make(map[int32][]extsvc.ExternalAccountSpec)
+	db.Mocks.ExternalAccounts.AssociateUserAndSave = func(userID int32, spec extsvc.ExternalAccountSpec, data extsvc.ExternalAccountData) error {
+		associated[userID] = append(associated[userID], spec)
+		return nil
+	}
+	user23Accounts := []*extsvc.ExternalAccount{acct(23, "okta", "https://okta.mine/", "101")}
+	db.Mocks.ExternalAccounts.List = func(op db.ExternalAccountsListOptions) ([]*extsvc.ExternalAccount, error) {
+		if op.UserID == 23 {
+			return user23Accounts, nil
+		}
+		return nil, nil
+	}
+	authzProviders := []authz.Provider{
+		&mockAuthzProvider{
+			serviceID:    "https://gitlab.mine/",
+			serviceType:  "gitlab",
+			okServiceIDs: map[string]struct{}{"https://okta.mine/": {}},
+			repos:        map[api.RepoName]struct{}{},
+			perms: map[extsvc.ExternalAccount]map[api.RepoName]map[authz.Perm]bool{
+				*acct(23, "gitlab", "https://gitlab.mine/", "101"): {},
+			},
+		},
+	}
+	repos := map[authz.Repo]struct{}{{RepoName: "r"}: {}}
+	newAcct := extsvc.ExternalAccountSpec{ServiceID: "https://gitlab.mine/", ServiceType: "gitlab", AccountID: "101"}
+
+	// Anonymous users have no accounts.
+	if _, err := readableRepos(context.Background(), nil, repos, authzProviders, authz.Read); err != nil {
+		t.Fatal(err)
+	}
+	if len(associated) != 0 {
+		t.Errorf("got associated accounts %v, want none", associated)
+	}
+
+	// The account is fetched from the authz provider and saved.
+	if _, err := readableRepos(context.Background(), &types.User{ID: 23}, repos, authzProviders, authz.Read); err != nil {
+		t.Fatal(err)
+	}
+	if want := map[int32][]extsvc.ExternalAccountSpec{23: {newAcct}}; !reflect.DeepEqual(associated, want) {
+		t.Errorf("got associated accounts %v, want %v", associated, want)
+	}
+
+	// No account is saved if the authz provider returns none.
+	if _, err := readableRepos(context.Background(), &types.User{ID: 99}, repos, authzProviders, authz.Read); err != nil {
+		t.Fatal(err)
+	}
+	if len(associated[99]) != 0 {
+		t.Errorf("got associated accounts %v, want none for user 99", associated[99])
+	}
+
+	// No account is saved if the user already has one for the authz provider.
+	user23Accounts = append(user23Accounts, acct(23, "gitlab", "https://gitlab.mine/", "101"))
+	if _, err := readableRepos(context.Background(), &types.User{ID: 23}, repos, authzProviders, authz.Read); err != nil {
+		t.Fatal(err)
+	}
+	if len(associated[23]) != 1 {
+		t.Errorf("got %d associated accounts for user 23, want 1", len(associated[23]))
+	}
+
+	// Accounts of authz providers whose permissions are stored in Sourcegraph are not saved.
+	builtinProviders := []authz.Provider{
+		&mockAuthzProvider{
+			serviceID:    "explicit",
+			serviceType:  authz.SourcegraphServiceType,
+			okServiceIDs: map[string]struct{}{"https://okta.mine/": {}},
+			repos:        map[api.RepoName]struct{}{"r": {}},
+			perms: map[extsvc.ExternalAccount]map[api.RepoName]map[authz.Perm]bool{
+				*acct(23, authz.SourcegraphServiceType, "explicit", "101"): {"r": {authz.Read: true}},
+			},
+		},
+	}
+	readable, err := readableRepos(context.Background(), &types.User{ID: 23}, repos, builtinProviders, authz.Read)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[api.RepoName]struct{}{"r": {}}; !reflect.DeepEqual(readable, want) {
+		t.Errorf("got readable repos %v, want %v", readable, want)
+	}
+	if len(associated[23]) != 1 {
+		t.Errorf("got %d associated accounts for user 23, want 1", len(associated[23]))
+	}
+}
+
+func acct(userID int32, serviceType, serviceID, accountID string) *extsvc.ExternalAccount {
+	return &extsvc.ExternalAccount{
+		UserID: userID,
+		ExternalAccountSpec: extsvc.ExternalAccountSpec{
+			ServiceType: serviceType,
+			ServiceID:   serviceID,
+			AccountID:   accountID,
+		},
+	}
+}
+
+type mockAuthzProvider struct {
+	serviceID   string
+	serviceType string
+
+	// okServiceIDs indicate services whose external accounts will be straightforwardly translated
+	// into external accounts belonging to this provider.
+	okServiceIDs map[string]struct{}
+
+	// perms is the map from external user account to repository permissions. The key set must
+	// include all user external accounts that are available in this mock instance.
+	perms map[extsvc.ExternalAccount]map[api.RepoName]map[authz.Perm]bool
+	repos map[api.RepoName]struct{}
+}
+
+func (m *mockAuthzProvider) FetchAccount(ctx context.Context, user *types.User, current []*extsvc.ExternalAccount) (mine *extsvc.ExternalAccount, err error) {
+	if user == nil {
+		return nil, nil
+	}
+	for _, acct := range current {
+		if (extsvc.ExternalAccount{}) == *acct {
+			continue
+		}
+		if _, ok := m.okServiceIDs[acct.ServiceID]; ok {
+			myAcct := *acct
+			myAcct.ServiceType = m.serviceType
+			myAcct.ServiceID = m.serviceID
+			if _, acctExistsInPerms := m.perms[myAcct]; acctExistsInPerms {
+				return &myAcct, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockAuthzProvider) RepoPerms(ctx context.Context, acct *extsvc.ExternalAccount, repos map[authz.Repo]struct{}) (map[api.RepoName]map[authz.Perm]bool, error) {
+	retPerms := make(map[api.RepoName]map[authz.Perm]bool)
+	repos, _ = m.Repos(ctx, repos)
+
+	if acct == nil {
+		acct = &extsvc.ExternalAccount{}
+	}
+	if _, existsInPerms := m.perms[*acct]; !existsInPerms {
+		acct = &extsvc.ExternalAccount{}
+	}
+
+	var userPerms map[api.RepoName]map[authz.Perm]bool = m.perms[*acct]
+	for repo := range repos {
+		if userRepoPerms, ok := userPerms[repo.RepoName]; ok {
+			retPerms[repo.RepoName] = make(map[authz.Perm]bool)
+			for k, v := range userRepoPerms {
+				retPerms[repo.RepoName][k] = v
+			}
+		}
+	}
+	return retPerms, nil
+}
+
+func (m *mockAuthzProvider) Repos(ctx context.Context, repos map[authz.Repo]struct{}) (mine map[authz.Repo]struct{}, others map[authz.Repo]struct{}) {
+	mine, others = make(map[authz.Repo]struct{}), make(map[authz.Repo]struct{})
+	for repo := range repos {
+		if _, ok := m.repos[repo.RepoName]; ok {
+			mine[repo] = struct{}{}
+		} else {
+			others[repo] = struct{}{}
+		}
+	}
+	return mine, others
+}
+
+func (m *mockAuthzProvider) ServiceID() string   { return m.serviceID }
+func (m *mockAuthzProvider) ServiceType() string { return m.serviceType }
+func (m *mockAuthzProvider) Validate() []string  { return nil }
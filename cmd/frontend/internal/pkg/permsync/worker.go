@@ -0,0 +1,137 @@
+// Package permsync syncs the repository permissions of users from the authz providers (usually
+// code hosts) to the database in the background.
+//
+// Requests only ever read the synced permissions (see db.UserPermissions), so that no request has
+// to wait on (or use up the rate limit of) a code host. Permissions are synced when a user signs in
+// (see db.UserPermissions.RequestSync), when a user is new, and when they become stale.
+package permsync
+
+import (
+	"context"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/actor"
+	"github.com/sourcegraph/sourcegraph/pkg/conf"
+	"github.com/sourcegraph/sourcegraph/pkg/errcode"
+	"github.com/sourcegraph/sourcegraph/pkg/rcache"
+	log15 "gopkg.in/inconshreveable/log15.v2"
+)
+
+const (
+	// pollInterval is how often the worker checks for users whose permissions need to be synced.
+	// It is short so that permissions are synced promptly upon sign-in.
+	pollInterval = 5 * time.Second
+
+	// batchSize is the maximum number of users whose permissions are synced per poll.
+	batchSize = 100
+
+	defaultSyncInterval = time.Hour
+)
+
+// SyncInterval returns how often each user's permissions are synced (in addition to upon
+// sign-in), as configured in the site configuration.
+func SyncInterval() time.Duration {
+	if v := conf.Get().PermissionsSyncInterval; v > 0 {
+		return time.Duration(v) * time.Minute
+	}
+	return defaultSyncInterval
+}
+
+// Stale reports whether the permissions are out of date: they were never successfully synced, or
+// not within the sync interval.
+func Stale(p *types.UserPermissions) bool {
+	return p == nil || p.SyncedAt == nil || p.SyncedAt.Before(time.Now().Add(-SyncInterval()))
+}
+
+// StartWorker should be invoked only after the DB has been initialized. It starts the background
+// worker which is responsible for syncing users' repository permissions from the authz providers.
+//
+// It should be invoked in a separate goroutine.
+func StartWorker() {
+	// Only one frontend instance should run this worker (otherwise the authz providers would be
+	// called more than necessary), so we use a distributed lock to guarantee this.
+	for {
+		ctx, release, ok := rcache.TryAcquireMutex(context.Background(), "permissionsSyncWorker")
+		if !ok {
+			// Failed to acquire the mutex. Wait before trying again.
+			time.Sleep(30 * time.Second)
+			continue
+		}
+
+		log15.Debug("permsync: worker running")
+		workForever(ctx)
+		log15.Debug("permsync: worker stopped", "ctx", ctx.Err())
+		release()
+	}
+}
+
+func workForever(ctx context.Context) {
+	// 🚨 SECURITY: The worker must see all repositories to compute permissions on them. The
+	// permissions themselves are computed for each user by the authz providers.
+	ctx = actor.WithActor(ctx, &actor.Actor{Internal: true})
+
+	for {
+		if ctx.Err() != nil {
+			return // e.g. if we lost the distributed mutex
+		}
+		if err := syncStale(ctx); err != nil {
+			log15.Error("permsync: error while syncing permissions", "error", err)
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+// syncStale syncs the permissions of the users whose permissions need to be synced.
+func syncStale(ctx context.Context) error {
+	if _, authzProviders := authz.GetProviders(); len(authzProviders) == 0 {
+		return nil
+	}
+	staleBefore := time.Now().Add(-SyncInterval())
+
+	// The permissions of anonymous users are only needed if anonymous users may use the site.
+	var syncAnonymous bool
+	if conf.AuthPublic() {
+		p, err := db.UserPermissions.Get(ctx, 0)
+		if err != nil && !errcode.IsNotFound(err) {
+			return err
+		}
+		syncAnonymous = p == nil || p.SyncAttemptedAt == nil || p.SyncAttemptedAt.Before(staleBefore) || (p.SyncRequestedAt != nil && p.SyncRequestedAt.After(*p.SyncAttemptedAt))
+	}
+
+	userIDs, err := db.UserPermissions.ListSyncCandidates(ctx, staleBefore, batchSize)
+	if err != nil {
+		return err
+	}
+	if !syncAnonymous && len(userIDs) == 0 {
+		return nil
+	}
+
+	// List the repositories once for all users synced in this batch.
+	repos, err := listRepos(ctx)
+	if err != nil {
+		return err
+	}
+
+	if syncAnonymous {
+		if err := syncUser(ctx, nil, repos); err != nil {
+			log15.Warn("permsync: error while syncing permissions of anonymous users", "error", err)
+		}
+	}
+	for _, userID := range userIDs {
+		if ctx.Err() != nil {
+			return nil
+		}
+		user, err := db.Users.GetByID(ctx, userID)
+		if err != nil {
+			log15.Error("permsync: error while getting user", "user", userID, "error", err)
+			continue
+		}
+		if err := syncUser(ctx, user, repos); err != nil {
+			log15.Warn("permsync: error while syncing permissions", "user", userID, "error", err)
+		}
+	}
+	return nil
+}
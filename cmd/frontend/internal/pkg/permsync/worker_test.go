@@ -0,0 +1,61 @@
+package permsync
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/conf"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc"
+)
+
+func TestSyncStale_listsReposOnce(t *testing.T) {
+	defer func() { db.Mocks = db.MockStores{} }()
+	conf.Mock(&conf.Unified{})
+	defer conf.Mock(nil)
+	authz.SetProviders(false, []authz.Provider{&mockAuthzProvider{
+		serviceID:   "https://gitlab.mine/",
+		serviceType: "gitlab",
+		repos:       map[api.RepoName]struct{}{"gitlab.mine/org/r0": {}},
+		perms: map[extsvc.ExternalAccount]map[api.RepoName]map[authz.Perm]bool{
+			{}: {"gitlab.mine/org/r0": {authz.Read: true}},
+		},
+	}})
+	defer authz.SetProviders(true, nil)
+
+	db.Mocks.UserPermissions.ListSyncCandidates = func(ctx context.Context, staleBefore time.Time, limit int) ([]int32, error) {
+		return []int32{1, 2, 3}, nil
+	}
+	db.Mocks.Users.GetByID = func(ctx context.Context, id int32) (*types.User, error) {
+		return &types.User{ID: id}, nil
+	}
+	db.Mocks.ExternalAccounts.List = func(db.ExternalAccountsListOptions) ([]*extsvc.ExternalAccount, error) {
+		return nil, nil
+	}
+	var listCalls int
+	db.Mocks.Repos.List = func(ctx context.Context, opt db.ReposListOptions) ([]*types.Repo, error) {
+		listCalls++
+		return []*types.Repo{{ID: 1, Name: "gitlab.mine/org/r0"}, {ID: 2, Name: "github.com/other/r"}}, nil
+	}
+	synced := map[int32][]api.RepoID{}
+	db.Mocks.UserPermissions.SetSynced = func(ctx context.Context, userID int32, readRepoIDs []api.RepoID) error {
+		synced[userID] = readRepoIDs
+		return nil
+	}
+
+	if err := syncStale(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if listCalls != 1 {
+		t.Errorf("got %d Repos.List calls, want 1", listCalls)
+	}
+	want := map[int32][]api.RepoID{1: {1}, 2: {1}, 3: {1}}
+	if !reflect.DeepEqual(synced, want) {
+		t.Errorf("got synced permissions %v, want %v", synced, want)
+	}
+}
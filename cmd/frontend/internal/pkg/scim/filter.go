@@ -0,0 +1,63 @@
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// filter is a SCIM filter (RFC 7644 section 3.4.2.2). Only filters that compare one attribute for
+// equality (such as `userName eq "alice"`) are supported, because identity providers only use
+// those to look up the users and groups they provision.
+type filter struct {
+	attr  string // the lowercase attribute path (e.g., "username" or "emails.value")
+	value string
+}
+
+func parseFilter(s string) (*filter, error) {
+	parts := strings.SplitN(strings.TrimSpace(s), " ", 3)
+	if len(parts) != 3 || !strings.EqualFold(parts[1], "eq") {
+		return nil, fmt.Errorf(`unsupported filter %q (only filters of the form 'attribute eq "value"' are supported)`, s)
+	}
+	var value string
+	if err := json.Unmarshal([]byte(strings.TrimSpace(parts[2])), &value); err != nil {
+		return nil, fmt.Errorf("unsupported filter %q (the value must be a string)", s)
+	}
+	return &filter{attr: strings.ToLower(parts[0]), value: value}, nil
+}
+
+// patchPath is the "path" of a PATCH operation (RFC 7644 section 3.5.2), such as "active",
+// `emails[type eq "work"].value` or `members[value eq "123"]`.
+type patchPath struct {
+	attr    string  // the lowercase attribute name (e.g., "emails")
+	filter  *filter // the value filter, if any
+	subAttr string  // the lowercase sub-attribute name (e.g., "value"), if any
+}
+
+func parsePatchPath(s string) (*patchPath, error) {
+	// Attributes may be qualified with the URN of their schema (e.g., "urn:...:core:2.0:User:active").
+	for _, schema := range []string{userSchema, groupSchema} {
+		if prefix := schema + ":"; len(s) > len(prefix) && strings.EqualFold(s[:len(prefix)], prefix) {
+			s = s[len(prefix):]
+		}
+	}
+
+	var p patchPath
+	if i := strings.Index(s, "["); i != -1 {
+		j := strings.LastIndex(s, "]")
+		if j < i {
+			return nil, fmt.Errorf("invalid path %q", s)
+		}
+		f, err := parseFilter(s[i+1 : j])
+		if err != nil {
+			return nil, err
+		}
+		p.filter = f
+		s = s[:i] + s[j+1:]
+	}
+	if i := strings.Index(s, "."); i != -1 {
+		s, p.subAttr = s[:i], strings.ToLower(s[i+1:])
+	}
+	p.attr = strings.ToLower(s)
+	return &p, nil
+}
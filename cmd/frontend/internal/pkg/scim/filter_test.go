@@ -0,0 +1,67 @@
+package scim
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := map[string]*filter{
+		`userName eq "alice"`:               {attr: "username", value: "alice"},
+		`emails.value EQ "a@example.com"`:   {attr: "emails.value", value: "a@example.com"},
+		`displayName eq "Eng \"Team\" 1"`:   {attr: "displayname", value: `Eng "Team" 1`},
+		`  externalId eq "00u1abc"  `:       {attr: "externalid", value: "00u1abc"},
+		`userName sw "a"`:                   nil,
+		`userName eq alice`:                 nil,
+		`userName eq "a" and active eq "b"`: nil,
+		`userName`:                          nil,
+	}
+	for input, want := range tests {
+		t.Run(input, func(t *testing.T) {
+			f, err := parseFilter(input)
+			if want == nil {
+				if err == nil {
+					t.Fatalf("got filter %+v, want error", f)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(f, want) {
+				t.Errorf("got %+v, want %+v", f, want)
+			}
+		})
+	}
+}
+
+func TestParsePatchPath(t *testing.T) {
+	tests := map[string]*patchPath{
+		"active":                    {attr: "active"},
+		"name.givenName":            {attr: "name", subAttr: "givenname"},
+		userSchema + ":displayName": {attr: "displayname"},
+		`emails[type eq "work"].value`: {
+			attr:    "emails",
+			filter:  &filter{attr: "type", value: "work"},
+			subAttr: "value",
+		},
+		`members[value eq "123"]`: {attr: "members", filter: &filter{attr: "value", value: "123"}},
+	}
+	for input, want := range tests {
+		t.Run(input, func(t *testing.T) {
+			p, err := parsePatchPath(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(p, want) {
+				t.Errorf("got %+v, want %+v", p, want)
+			}
+		})
+	}
+
+	for _, input := range []string{`members]value eq "1"[`, `members[value ne "1"]`} {
+		if _, err := parsePatchPath(input); err == nil {
+			t.Errorf("%q: got nil error, want error", input)
+		}
+	}
+}
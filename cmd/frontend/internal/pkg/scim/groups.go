@@ -0,0 +1,318 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/errcode"
+)
+
+// groupResource is a SCIM Group resource (RFC 7643 section 4.2). Groups are Sourcegraph
+// organizations. The organization's name is derived from the group's displayName when the group
+// is created, and it does not change when the displayName changes.
+type groupResource struct {
+	Schemas     []string               `json:"schemas"`
+	ID          string                 `json:"id,omitempty"`
+	DisplayName string                 `json:"displayName"`
+	Members     []multiValuedAttribute `json:"members,omitempty"`
+	Meta        *meta                  `json:"meta,omitempty"`
+}
+
+func toGroupResource(ctx context.Context, org *types.Org) (*groupResource, error) {
+	members, err := db.OrgMembers.GetByOrgID(ctx, org.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &groupResource{
+		Schemas:     []string{groupSchema},
+		ID:          strconv.Itoa(int(org.ID)),
+		DisplayName: org.Name,
+		Meta:        newMeta("Group", org.ID, org.CreatedAt, org.UpdatedAt),
+	}
+	if org.DisplayName != nil && *org.DisplayName != "" {
+		g.DisplayName = *org.DisplayName
+	}
+	for _, m := range members {
+		user, err := db.Users.GetByID(ctx, m.UserID)
+		if err != nil {
+			return nil, err
+		}
+		g.Members = append(g.Members, multiValuedAttribute{Value: strconv.Itoa(int(user.ID)), Display: user.Username})
+	}
+	return g, nil
+}
+
+func writeGroup(ctx context.Context, w http.ResponseWriter, status int, orgID int32) error {
+	org, err := db.Orgs.GetByID(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	g, err := toGroupResource(ctx, org)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Location", g.Meta.Location)
+	return writeJSON(w, status, g)
+}
+
+func serveGroupsList(w http.ResponseWriter, r *http.Request) error {
+	q, err := parseListQuery(r)
+	if err != nil {
+		return err
+	}
+
+	var orgs []*types.Org
+	var total int
+	if q.filter != nil {
+		org, err := getOrgByFilter(r.Context(), q.filter)
+		if err != nil {
+			return err
+		}
+		if org != nil {
+			total = 1
+			if q.startIndex == 1 && q.count > 0 {
+				orgs = append(orgs, org)
+			}
+		}
+	} else {
+		orgs, err = db.Orgs.List(r.Context(), &db.OrgsListOptions{LimitOffset: q.limitOffset()})
+		if err != nil {
+			return err
+		}
+		total, err = db.Orgs.Count(r.Context(), db.OrgsListOptions{})
+		if err != nil {
+			return err
+		}
+	}
+
+	resources := make([]*groupResource, 0, len(orgs))
+	for _, org := range orgs {
+		g, err := toGroupResource(r.Context(), org)
+		if err != nil {
+			return err
+		}
+		resources = append(resources, g)
+	}
+	return writeJSON(w, http.StatusOK, &listResponse{
+		Schemas:      []string{listResponseSchema},
+		TotalResults: total,
+		StartIndex:   q.startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	})
+}
+
+// getOrgByFilter returns the organization that matches the filter, or nil if there is none.
+func getOrgByFilter(ctx context.Context, f *filter) (*types.Org, error) {
+	var org *types.Org
+	var err error
+	switch f.attr {
+	case "id":
+		id, parseErr := strconv.ParseInt(f.value, 10, 32)
+		if parseErr != nil {
+			return nil, nil
+		}
+		org, err = db.Orgs.GetByID(ctx, int32(id))
+	case "displayname":
+		name, normalizeErr := auth.NormalizeUsername(f.value)
+		if normalizeErr != nil {
+			return nil, nil
+		}
+		org, err = db.Orgs.GetByName(ctx, name)
+	case "externalid":
+		return nil, nil
+	default:
+		return nil, &scimError{status: http.StatusBadRequest, scimType: "invalidFilter", detail: fmt.Sprintf("Filtering groups by %q is not supported.", f.attr)}
+	}
+	if _, ok := err.(*db.OrgNotFoundError); ok {
+		return nil, nil
+	}
+	return org, err
+}
+
+func serveGroupGet(w http.ResponseWriter, r *http.Request, orgID int32) error {
+	return writeGroup(r.Context(), w, http.StatusOK, orgID)
+}
+
+func serveGroupCreate(w http.ResponseWriter, r *http.Request) error {
+	var in groupResource
+	if err := readJSON(r, &in); err != nil {
+		return err
+	}
+	if in.DisplayName == "" {
+		return &scimError{status: http.StatusBadRequest, scimType: "invalidValue", detail: "The displayName attribute is required."}
+	}
+	name, err := auth.NormalizeUsername(in.DisplayName)
+	if err != nil {
+		return &scimError{status: http.StatusBadRequest, scimType: "invalidValue", detail: fmt.Sprintf("Invalid displayName %q: %s", in.DisplayName, err)}
+	}
+	if _, err := db.Orgs.GetByName(r.Context(), name); err == nil {
+		return &scimError{status: http.StatusConflict, scimType: "uniqueness", detail: fmt.Sprintf("An organization named %q already exists.", name)}
+	} else if _, ok := err.(*db.OrgNotFoundError); !ok {
+		return err
+	}
+
+	// The members must exist before the organization is created, so that a request with an
+	// unknown member doesn't leave an empty organization behind.
+	memberIDs, err := parseMemberIDs(r.Context(), in.Members)
+	if err != nil {
+		return err
+	}
+	var displayName *string
+	if in.DisplayName != name {
+		displayName = &in.DisplayName
+	}
+	org, err := db.Orgs.Create(r.Context(), name, displayName)
+	if err != nil {
+		return &scimError{status: http.StatusBadRequest, scimType: "invalidValue", detail: err.Error()}
+	}
+	if err := setOrgMembers(r.Context(), org.ID, memberIDs); err != nil {
+		return err
+	}
+	return writeGroup(r.Context(), w, http.StatusCreated, org.ID)
+}
+
+func serveGroupReplace(w http.ResponseWriter, r *http.Request, orgID int32) error {
+	var in groupResource
+	if err := readJSON(r, &in); err != nil {
+		return err
+	}
+	org, err := db.Orgs.GetByID(r.Context(), orgID)
+	if err != nil {
+		return err
+	}
+	if err := updateGroup(r.Context(), org, &in); err != nil {
+		return err
+	}
+	return writeGroup(r.Context(), w, http.StatusOK, orgID)
+}
+
+func serveGroupPatch(w http.ResponseWriter, r *http.Request, orgID int32) error {
+	var req patchRequest
+	if err := readJSON(r, &req); err != nil {
+		return err
+	}
+	org, err := db.Orgs.GetByID(r.Context(), orgID)
+	if err != nil {
+		return err
+	}
+
+	// Apply the operations to the current resource, and then save it as if it had been replaced.
+	g, err := toGroupResource(r.Context(), org)
+	if err != nil {
+		return err
+	}
+	for _, op := range req.Operations {
+		if err := op.apply(applyGroupPatch(g)); err != nil {
+			return err
+		}
+	}
+	if g.Members == nil {
+		g.Members = []multiValuedAttribute{}
+	}
+	if err := updateGroup(r.Context(), org, g); err != nil {
+		return err
+	}
+	return writeGroup(r.Context(), w, http.StatusOK, orgID)
+}
+
+// updateGroup updates the organization to match the resource. If the resource has no members
+// attribute, the organization's members are left unchanged.
+func updateGroup(ctx context.Context, org *types.Org, g *groupResource) error {
+	if g.DisplayName != "" && (org.DisplayName == nil || g.DisplayName != *org.DisplayName) && g.DisplayName != org.Name {
+		if _, err := db.Orgs.Update(ctx, org.ID, &g.DisplayName); err != nil {
+			return err
+		}
+	}
+	if g.Members != nil {
+		memberIDs, err := parseMemberIDs(ctx, g.Members)
+		if err != nil {
+			return err
+		}
+		if err := setOrgMembers(ctx, org.ID, memberIDs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseMemberIDs returns the user IDs of the members, which must be existing users.
+func parseMemberIDs(ctx context.Context, members []multiValuedAttribute) ([]int32, error) {
+	ids := make([]int32, 0, len(members))
+	seen := make(map[int32]bool, len(members))
+	for _, m := range members {
+		id, err := strconv.ParseInt(m.Value, 10, 32)
+		if err == nil {
+			_, err = db.Users.GetByID(ctx, int32(id))
+		}
+		if err != nil {
+			if _, ok := err.(*strconv.NumError); ok || errcode.IsNotFound(err) {
+				return nil, &scimError{status: http.StatusBadRequest, scimType: "invalidValue", detail: fmt.Sprintf("Member %q is not a user.", m.Value)}
+			}
+			return nil, err
+		}
+		if !seen[int32(id)] {
+			seen[int32(id)] = true
+			ids = append(ids, int32(id))
+		}
+	}
+	return ids, nil
+}
+
+// setOrgMembers sets the organization's members to the given users.
+func setOrgMembers(ctx context.Context, orgID int32, userIDs []int32) error {
+	current, err := db.OrgMembers.GetByOrgID(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	have := make(map[int32]bool, len(current))
+	for _, m := range current {
+		have[m.UserID] = true
+	}
+	want := make(map[int32]bool, len(userIDs))
+	for _, userID := range userIDs {
+		want[userID] = true
+		if !have[userID] {
+			if _, err := db.OrgMembers.Create(ctx, orgID, userID); err != nil {
+				return err
+			}
+		}
+	}
+	for _, m := range current {
+		if !want[m.UserID] {
+			if err := db.OrgMembers.Remove(ctx, orgID, m.UserID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// applyGroupPatch returns a func that applies a PATCH operation to the group resource. Attributes
+// other than displayName and members (such as "externalId") are ignored.
+func applyGroupPatch(g *groupResource) func(op string, path *patchPath, value json.RawMessage) error {
+	return func(op string, path *patchPath, value json.RawMessage) error {
+		switch path.attr {
+		case "displayname":
+			if op == "remove" {
+				return nil
+			}
+			return unmarshalValue(value, &g.DisplayName)
+
+		case "members":
+			values, err := unmarshalMultiValued(value)
+			if err != nil {
+				return err
+			}
+			g.Members = patchMultiValued(g.Members, op, path.filter, values)
+		}
+		return nil
+	}
+}
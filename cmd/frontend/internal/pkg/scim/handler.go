@@ -0,0 +1,303 @@
+// Package scim implements a SCIM 2.0 service provider (RFC 7643 and RFC 7644), which lets identity
+// providers create, update, deactivate and delete users and manage their organization memberships.
+//
+// Users are SCIM User resources, and organizations are SCIM Group resources. Only site admins may
+// use the API. Identity providers authenticate with a site admin's access token, which they send
+// in the "Authorization: Bearer TOKEN" request header.
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/globals"
+	"github.com/sourcegraph/sourcegraph/pkg/actor"
+	"github.com/sourcegraph/sourcegraph/pkg/errcode"
+	log15 "gopkg.in/inconshreveable/log15.v2"
+)
+
+const (
+	userSchema                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	groupSchema                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	serviceProviderConfigSchema = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	listResponseSchema          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	patchOpSchema               = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	errorSchema                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+)
+
+// PathPrefix is the path of the SCIM API, relative to the HTTP API root.
+const PathPrefix = "/scim/v2"
+
+// defaultCount is the number of resources returned in a list response if the request does not
+// specify a count.
+const defaultCount = 100
+
+// Handler serves the SCIM API. It reads the resource type and ID from the "Resource" and "ID"
+// route variables.
+var Handler http.Handler = http.HandlerFunc(serveSCIM)
+
+func serveSCIM(w http.ResponseWriter, r *http.Request) {
+	if err := serve(w, r); err != nil {
+		writeError(w, err)
+	}
+}
+
+func serve(w http.ResponseWriter, r *http.Request) error {
+	// 🚨 SECURITY: Only site admins may provision users.
+	if err := backend.CheckCurrentUserIsSiteAdmin(r.Context()); err != nil {
+		if err == backend.ErrNotAuthenticated {
+			return &scimError{status: http.StatusUnauthorized, detail: "Authentication with a site admin's access token is required."}
+		}
+		return &scimError{status: http.StatusForbidden, detail: err.Error()}
+	}
+
+	vars := mux.Vars(r)
+	resource, id := vars["Resource"], vars["ID"]
+	switch {
+	case resource == "ServiceProviderConfig" && id == "" && r.Method == "GET":
+		return writeJSON(w, http.StatusOK, serviceProviderConfig())
+
+	case resource == "Users" && id == "":
+		switch r.Method {
+		case "GET":
+			return serveUsersList(w, r)
+		case "POST":
+			return serveUserCreate(w, r)
+		}
+	case resource == "Users":
+		userID, err := parseID(id)
+		if err != nil {
+			return err
+		}
+		switch r.Method {
+		case "GET":
+			return serveUserGet(w, r, userID)
+		case "PUT":
+			return serveUserReplace(w, r, userID)
+		case "PATCH":
+			return serveUserPatch(w, r, userID)
+		case "DELETE":
+			return serveUserDelete(w, r, userID)
+		}
+
+	case resource == "Groups" && id == "":
+		switch r.Method {
+		case "GET":
+			return serveGroupsList(w, r)
+		case "POST":
+			return serveGroupCreate(w, r)
+		}
+	case resource == "Groups":
+		orgID, err := parseID(id)
+		if err != nil {
+			return err
+		}
+		switch r.Method {
+		case "GET":
+			return serveGroupGet(w, r, orgID)
+		case "PUT":
+			return serveGroupReplace(w, r, orgID)
+		case "PATCH":
+			return serveGroupPatch(w, r, orgID)
+		case "DELETE":
+			return &scimError{status: http.StatusNotImplemented, detail: "Deleting organizations is not supported. A site admin can delete the organization in Sourcegraph."}
+		}
+
+	default:
+		return &scimError{status: http.StatusNotFound, detail: fmt.Sprintf("Unknown resource type %q.", resource)}
+	}
+	return &scimError{status: http.StatusMethodNotAllowed, detail: fmt.Sprintf("Unsupported method %s.", r.Method)}
+}
+
+// parseID parses the ID of a user or organization. Invalid IDs are reported as not found.
+func parseID(id string) (int32, error) {
+	v, err := strconv.ParseInt(id, 10, 32)
+	if err != nil {
+		return 0, &scimError{status: http.StatusNotFound, detail: fmt.Sprintf("Resource %q not found.", id)}
+	}
+	return int32(v), nil
+}
+
+// scimError is an error that is reported to the client as a SCIM error response (RFC 7644 section
+// 3.12).
+type scimError struct {
+	status   int
+	scimType string // e.g., "uniqueness" or "invalidFilter"
+	detail   string
+}
+
+func (e *scimError) Error() string { return e.detail }
+
+// toSCIMError converts err to a scimError, so that it can be reported to the client.
+func toSCIMError(err error) *scimError {
+	switch {
+	case err == nil:
+		return nil
+	case db.IsUsernameExists(err):
+		return &scimError{status: http.StatusConflict, scimType: "uniqueness", detail: "A user with the same username already exists."}
+	case db.IsEmailExists(err):
+		return &scimError{status: http.StatusConflict, scimType: "uniqueness", detail: "A user with the same email address already exists."}
+	case errcode.IsNotFound(err):
+		return &scimError{status: http.StatusNotFound, detail: "Resource not found."}
+	}
+	if _, ok := err.(*db.OrgNotFoundError); ok {
+		return &scimError{status: http.StatusNotFound, detail: "Resource not found."}
+	}
+	if e, ok := err.(*scimError); ok {
+		return e
+	}
+	log15.Error("SCIM request failed.", "error", err)
+	return &scimError{status: http.StatusInternalServerError, detail: "Unexpected error."}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	e := toSCIMError(err)
+	_ = writeJSON(w, e.status, struct {
+		Schemas  []string `json:"schemas"`
+		Status   string   `json:"status"`
+		SCIMType string   `json:"scimType,omitempty"`
+		Detail   string   `json:"detail,omitempty"`
+	}{
+		Schemas:  []string{errorSchema},
+		Status:   strconv.Itoa(e.status),
+		SCIMType: e.scimType,
+		Detail:   e.detail,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) error {
+	w.Header().Set("Content-Type", "application/scim+json; charset=utf-8")
+	w.WriteHeader(status)
+	return json.NewEncoder(w).Encode(v)
+}
+
+// readJSON decodes the request body into v.
+func readJSON(r *http.Request, v interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return &scimError{status: http.StatusBadRequest, scimType: "invalidSyntax", detail: fmt.Sprintf("Invalid request body: %s", err)}
+	}
+	return nil
+}
+
+// meta is the "meta" attribute of a resource.
+type meta struct {
+	ResourceType string    `json:"resourceType"`
+	Created      time.Time `json:"created"`
+	LastModified time.Time `json:"lastModified"`
+	Location     string    `json:"location"`
+}
+
+func newMeta(resourceType string, id int32, created, lastModified time.Time) *meta {
+	return &meta{
+		ResourceType: resourceType,
+		Created:      created,
+		LastModified: lastModified,
+		Location:     resourceURL(resourceType+"s", id),
+	}
+}
+
+// resourceURL returns the URL of the resource with the given type ("Users" or "Groups") and ID.
+func resourceURL(resourceType string, id int32) string {
+	return globals.ExternalURL.ResolveReference(&url.URL{Path: fmt.Sprintf("/.api%s/%s/%d", PathPrefix, resourceType, id)}).String()
+}
+
+// multiValuedAttribute is an item of a multi-valued attribute, such as "emails" or "members" (RFC
+// 7643 section 2.4).
+type multiValuedAttribute struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+// listResponse is the response to a query for resources (RFC 7644 section 3.4.2).
+type listResponse struct {
+	Schemas      []string    `json:"schemas"`
+	TotalResults int         `json:"totalResults"`
+	StartIndex   int         `json:"startIndex"`
+	ItemsPerPage int         `json:"itemsPerPage"`
+	Resources    interface{} `json:"Resources"`
+}
+
+// listQuery is a query for resources (RFC 7644 section 3.4.2).
+type listQuery struct {
+	filter     *filter // nil if there is no filter
+	startIndex int     // 1-based
+	count      int
+}
+
+func parseListQuery(r *http.Request) (*listQuery, error) {
+	q := &listQuery{startIndex: 1, count: defaultCount}
+	if v := r.URL.Query().Get("filter"); v != "" {
+		f, err := parseFilter(v)
+		if err != nil {
+			return nil, &scimError{status: http.StatusBadRequest, scimType: "invalidFilter", detail: err.Error()}
+		}
+		q.filter = f
+	}
+	// Invalid and out-of-range values are interpreted as the defaults (RFC 7644 section 3.4.2.4).
+	if v, err := strconv.Atoi(r.URL.Query().Get("startIndex")); err == nil && v > 1 {
+		q.startIndex = v
+	}
+	if v, err := strconv.Atoi(r.URL.Query().Get("count")); err == nil && v >= 0 && v < defaultCount {
+		q.count = v
+	}
+	return q, nil
+}
+
+func (q *listQuery) limitOffset() *db.LimitOffset {
+	return &db.LimitOffset{Limit: q.count, Offset: q.startIndex - 1}
+}
+
+func serviceProviderConfig() interface{} {
+	type supported struct {
+		Supported bool `json:"supported"`
+	}
+	type filterSupported struct {
+		Supported  bool `json:"supported"`
+		MaxResults int  `json:"maxResults"`
+	}
+	type bulkSupported struct {
+		Supported      bool `json:"supported"`
+		MaxOperations  int  `json:"maxOperations"`
+		MaxPayloadSize int  `json:"maxPayloadSize"`
+	}
+	type authenticationScheme struct {
+		Type        string `json:"type"`
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}
+	return struct {
+		Schemas               []string               `json:"schemas"`
+		Patch                 supported              `json:"patch"`
+		Bulk                  bulkSupported          `json:"bulk"`
+		Filter                filterSupported        `json:"filter"`
+		ChangePassword        supported              `json:"changePassword"`
+		Sort                  supported              `json:"sort"`
+		ETag                  supported              `json:"etag"`
+		AuthenticationSchemes []authenticationScheme `json:"authenticationSchemes"`
+	}{
+		Schemas: []string{serviceProviderConfigSchema},
+		Patch:   supported{Supported: true},
+		Filter:  filterSupported{Supported: true, MaxResults: defaultCount},
+		AuthenticationSchemes: []authenticationScheme{{
+			Type:        "oauthbearertoken",
+			Name:        "OAuth Bearer Token",
+			Description: "Authentication with a Sourcegraph access token of a site admin.",
+		}},
+	}
+}
+
+// isSelf reports whether id is the ID of the user whose access token was used for the request.
+// Identity providers may not deactivate or delete that user, because they would lose access to
+// the API.
+func isSelf(r *http.Request, id int32) bool {
+	return actor.FromContext(r.Context()).UID == id
+}
@@ -0,0 +1,45 @@
+package scim
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/actor"
+)
+
+func TestHandler_requiresSiteAdmin(t *testing.T) {
+	db.Mocks.Users.GetByCurrentAuthUser = func(ctx context.Context) (*types.User, error) {
+		a := actor.FromContext(ctx)
+		if !a.IsAuthenticated() {
+			return nil, db.ErrNoCurrentUser
+		}
+		return &types.User{ID: a.UID, SiteAdmin: a.UID == 1}, nil
+	}
+	defer func() { db.Mocks = db.MockStores{} }()
+
+	tests := map[string]struct {
+		actor      *actor.Actor
+		wantStatus int
+	}{
+		"unauthenticated": {actor: &actor.Actor{}, wantStatus: http.StatusUnauthorized},
+		"non-site admin":  {actor: &actor.Actor{UID: 2}, wantStatus: http.StatusForbidden},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/.api/scim/v2/Users", nil)
+			req = req.WithContext(actor.WithActor(context.Background(), test.actor))
+			rr := httptest.NewRecorder()
+			Handler.ServeHTTP(rr, req)
+			if rr.Code != test.wantStatus {
+				t.Errorf("got status %d, want %d", rr.Code, test.wantStatus)
+			}
+			if got, want := rr.Header().Get("Content-Type"), "application/scim+json; charset=utf-8"; got != want {
+				t.Errorf("got Content-Type %q, want %q", got, want)
+			}
+		})
+	}
+}
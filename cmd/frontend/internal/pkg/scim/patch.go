@@ -0,0 +1,173 @@
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// patchRequest is the request body of a PATCH request (RFC 7644 section 3.5.2).
+type patchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []patchOperation `json:"Operations"`
+}
+
+type patchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+// apply calls applyFunc with the lowercase operation ("add", "replace" or "remove") for each
+// attribute that the operation modifies. If the operation has no path, applyFunc is called for
+// each attribute of the value.
+func (o *patchOperation) apply(applyFunc func(op string, path *patchPath, value json.RawMessage) error) error {
+	op := strings.ToLower(o.Op)
+	if op != "add" && op != "replace" && op != "remove" {
+		return &scimError{status: http.StatusBadRequest, scimType: "invalidValue", detail: fmt.Sprintf("Unsupported PATCH operation %q.", o.Op)}
+	}
+
+	if o.Path != "" {
+		path, err := parsePatchPath(o.Path)
+		if err != nil {
+			return &scimError{status: http.StatusBadRequest, scimType: "invalidPath", detail: err.Error()}
+		}
+		if op != "remove" && len(o.Value) == 0 {
+			return &scimError{status: http.StatusBadRequest, scimType: "invalidValue", detail: fmt.Sprintf("The %s operation requires a value.", op)}
+		}
+		return applyFunc(op, path, o.Value)
+	}
+
+	if op == "remove" {
+		return &scimError{status: http.StatusBadRequest, scimType: "noTarget", detail: "The remove operation requires a path."}
+	}
+	var attrs map[string]json.RawMessage
+	if err := json.Unmarshal(o.Value, &attrs); err != nil {
+		return &scimError{status: http.StatusBadRequest, scimType: "invalidValue", detail: "The value of an operation without a path must be an object."}
+	}
+	names := make([]string, 0, len(attrs))
+	for name := range attrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		path, err := parsePatchPath(name)
+		if err != nil {
+			return &scimError{status: http.StatusBadRequest, scimType: "invalidPath", detail: err.Error()}
+		}
+		if err := applyFunc(op, path, attrs[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func unmarshalValue(value json.RawMessage, v interface{}) error {
+	if err := json.Unmarshal(value, v); err != nil {
+		return &scimError{status: http.StatusBadRequest, scimType: "invalidValue", detail: fmt.Sprintf("Invalid value %s.", value)}
+	}
+	return nil
+}
+
+// unmarshalBool unmarshals a boolean value. Some identity providers (such as Azure AD) send
+// booleans as strings (such as "False") in PATCH requests, so those are accepted, too.
+func unmarshalBool(value json.RawMessage) (bool, error) {
+	var v interface{}
+	if err := unmarshalValue(value, &v); err != nil {
+		return false, err
+	}
+	switch v := v.(type) {
+	case bool:
+		return v, nil
+	case string:
+		if b, err := strconv.ParseBool(strings.ToLower(v)); err == nil {
+			return b, nil
+		}
+	}
+	return false, &scimError{status: http.StatusBadRequest, scimType: "invalidValue", detail: fmt.Sprintf("Invalid boolean value %s.", value)}
+}
+
+// unmarshalMultiValued unmarshals the value of a multi-valued attribute, which may be a list of
+// items, a single item, or (if the path refers to the "value" sub-attribute) a string.
+func unmarshalMultiValued(value json.RawMessage) ([]multiValuedAttribute, error) {
+	if len(value) == 0 || string(value) == "null" {
+		return nil, nil
+	}
+	var items []multiValuedAttribute
+	if err := json.Unmarshal(value, &items); err == nil {
+		return items, nil
+	}
+	var item multiValuedAttribute
+	if err := json.Unmarshal(value, &item); err == nil {
+		return []multiValuedAttribute{item}, nil
+	}
+	var s string
+	if err := unmarshalValue(value, &s); err != nil {
+		return nil, err
+	}
+	return []multiValuedAttribute{{Value: s}}, nil
+}
+
+// patchMultiValued applies a PATCH operation to the items of a multi-valued attribute. If f is
+// non-nil, the operation only applies to the items that match it.
+func patchMultiValued(items []multiValuedAttribute, op string, f *filter, values []multiValuedAttribute) []multiValuedAttribute {
+	switch op {
+	case "add":
+		return append(items, values...)
+
+	case "replace":
+		if f == nil {
+			return values
+		}
+		var out []multiValuedAttribute
+		replaced := false
+		for _, item := range items {
+			if f.matches(item) {
+				if !replaced {
+					out = append(out, values...)
+					replaced = true
+				}
+				continue
+			}
+			out = append(out, item)
+		}
+		if !replaced {
+			out = append(out, values...)
+		}
+		return out
+
+	case "remove":
+		removeValues := make(map[string]bool, len(values))
+		for _, v := range values {
+			removeValues[strings.ToLower(v.Value)] = true
+		}
+		var out []multiValuedAttribute
+		for _, item := range items {
+			switch {
+			case f != nil && f.matches(item):
+			case f == nil && len(values) == 0:
+			case removeValues[strings.ToLower(item.Value)]:
+			default:
+				out = append(out, item)
+			}
+		}
+		return out
+	}
+	return items
+}
+
+// matches reports whether the item of a multi-valued attribute matches the filter, which is
+// relative to the attribute (as in `emails[type eq "work"]`).
+func (f *filter) matches(item multiValuedAttribute) bool {
+	switch f.attr {
+	case "value":
+		return strings.EqualFold(item.Value, f.value)
+	case "type":
+		// Types are not stored, so items without a type match any type.
+		return item.Type == "" || strings.EqualFold(item.Type, f.value)
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package scim
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestPatchOperation_user(t *testing.T) {
+	newUser := func() *userResource {
+		active := true
+		return &userResource{
+			UserName:    "alice",
+			DisplayName: "Alice",
+			Emails:      []multiValuedAttribute{{Value: "alice@example.com", Primary: true}},
+			Active:      &active,
+		}
+	}
+
+	tests := map[string]struct {
+		ops               string
+		wantActive        bool
+		wantDisplayName   string
+		wantEmails        []string
+		wantEmailsChanged bool
+	}{
+		"no path (Okta)": {
+			ops:             `[{"op":"replace","value":{"active":false}}]`,
+			wantActive:      false,
+			wantDisplayName: "Alice",
+			wantEmails:      []string{"alice@example.com"},
+		},
+		"string boolean (Azure AD)": {
+			ops:             `[{"op":"Replace","path":"active","value":"False"}]`,
+			wantActive:      false,
+			wantDisplayName: "Alice",
+			wantEmails:      []string{"alice@example.com"},
+		},
+		"name": {
+			ops:             `[{"op":"replace","path":"name","value":{"givenName":"Alice","familyName":"Smith"}}]`,
+			wantActive:      true,
+			wantDisplayName: "Alice Smith",
+			wantEmails:      []string{"alice@example.com"},
+		},
+		"replace email by type": {
+			ops:               `[{"op":"replace","path":"emails[type eq \"work\"].value","value":"alice@example.org"}]`,
+			wantActive:        true,
+			wantDisplayName:   "Alice",
+			wantEmails:        []string{"alice@example.org"},
+			wantEmailsChanged: true,
+		},
+		"add and remove emails": {
+			ops: `[
+				{"op":"add","path":"emails","value":[{"value":"a2@example.com"},{"value":"a3@example.com"}]},
+				{"op":"remove","path":"emails[value eq \"alice@example.com\"]"}
+			]`,
+			wantActive:        true,
+			wantDisplayName:   "Alice",
+			wantEmails:        []string{"a2@example.com", "a3@example.com"},
+			wantEmailsChanged: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var ops []patchOperation
+			if err := json.Unmarshal([]byte(test.ops), &ops); err != nil {
+				t.Fatal(err)
+			}
+			u := newUser()
+			var emailsChanged bool
+			for _, op := range ops {
+				if err := op.apply(applyUserPatch(u, &emailsChanged)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if *u.Active != test.wantActive {
+				t.Errorf("got active %v, want %v", *u.Active, test.wantActive)
+			}
+			if u.DisplayName != test.wantDisplayName {
+				t.Errorf("got displayName %q, want %q", u.DisplayName, test.wantDisplayName)
+			}
+			if emails := u.emailAddresses(); !reflect.DeepEqual(emails, test.wantEmails) {
+				t.Errorf("got emails %q, want %q", emails, test.wantEmails)
+			}
+			if emailsChanged != test.wantEmailsChanged {
+				t.Errorf("got emailsChanged %v, want %v", emailsChanged, test.wantEmailsChanged)
+			}
+		})
+	}
+}
+
+func TestPatchOperation_invalid(t *testing.T) {
+	tests := map[string]string{
+		"unknown op":          `{"op":"move","path":"active","value":true}`,
+		"remove without path": `{"op":"remove"}`,
+		"missing value":       `{"op":"replace","path":"active"}`,
+		"invalid boolean":     `{"op":"replace","path":"active","value":"maybe"}`,
+	}
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			var op patchOperation
+			if err := json.Unmarshal([]byte(input), &op); err != nil {
+				t.Fatal(err)
+			}
+			var emailsChanged bool
+			err := op.apply(applyUserPatch(&userResource{}, &emailsChanged))
+			if e, ok := err.(*scimError); !ok || e.status != 400 {
+				t.Errorf("got error %v, want a scimError with status 400", err)
+			}
+		})
+	}
+}
+
+func TestPatchMultiValued_members(t *testing.T) {
+	members := []multiValuedAttribute{{Value: "1"}, {Value: "2"}}
+	got := patchMultiValued(members, "remove", &filter{attr: "value", value: "1"}, nil)
+	if want := []multiValuedAttribute{{Value: "2"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	got = patchMultiValued(members, "remove", nil, []multiValuedAttribute{{Value: "2"}})
+	if want := []multiValuedAttribute{{Value: "1"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if got := patchMultiValued(members, "remove", nil, nil); len(got) != 0 {
+		t.Errorf("got %+v, want no members", got)
+	}
+}
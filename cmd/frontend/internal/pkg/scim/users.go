@@ -0,0 +1,445 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/errcode"
+)
+
+// userResource is a SCIM User resource (RFC 7643 section 4.1).
+type userResource struct {
+	Schemas     []string               `json:"schemas"`
+	ID          string                 `json:"id,omitempty"`
+	UserName    string                 `json:"userName"`
+	DisplayName string                 `json:"displayName,omitempty"`
+	Name        *userName              `json:"name,omitempty"`
+	Emails      []multiValuedAttribute `json:"emails,omitempty"`
+	Active      *bool                  `json:"active,omitempty"`
+	Groups      []multiValuedAttribute `json:"groups,omitempty"` // read-only
+	Meta        *meta                  `json:"meta,omitempty"`
+}
+
+type userName struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+// displayName returns the display name of the user, falling back to the user's full name.
+func (u *userResource) displayName() string {
+	if u.DisplayName != "" || u.Name == nil {
+		return u.DisplayName
+	}
+	if u.Name.Formatted != "" {
+		return u.Name.Formatted
+	}
+	return strings.TrimSpace(u.Name.GivenName + " " + u.Name.FamilyName)
+}
+
+// emailAddresses returns the user's email addresses, with the primary email address first.
+func (u *userResource) emailAddresses() []string {
+	var emails []string
+	seen := map[string]bool{}
+	add := func(email string) {
+		if email != "" && !seen[strings.ToLower(email)] {
+			seen[strings.ToLower(email)] = true
+			emails = append(emails, email)
+		}
+	}
+	for _, e := range u.Emails {
+		if e.Primary {
+			add(e.Value)
+		}
+	}
+	for _, e := range u.Emails {
+		add(e.Value)
+	}
+	return emails
+}
+
+func toUserResource(ctx context.Context, user *types.User) (*userResource, error) {
+	emails, err := db.UserEmails.ListByUser(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	orgs, err := db.Orgs.GetByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	active := user.DeactivatedAt == nil
+	u := &userResource{
+		Schemas:     []string{userSchema},
+		ID:          strconv.Itoa(int(user.ID)),
+		UserName:    user.Username,
+		DisplayName: user.DisplayName,
+		Active:      &active,
+		Meta:        newMeta("User", user.ID, user.CreatedAt, user.UpdatedAt),
+	}
+	if user.DisplayName != "" {
+		u.Name = &userName{Formatted: user.DisplayName}
+	}
+	// The primary email address is the oldest verified one (see db.UserEmails.GetPrimaryEmail).
+	primary := -1
+	for i, e := range emails {
+		if e.VerifiedAt != nil {
+			primary = i
+			break
+		}
+	}
+	for i, e := range emails {
+		u.Emails = append(u.Emails, multiValuedAttribute{Value: e.Email, Primary: i == primary})
+	}
+	for _, org := range orgs {
+		u.Groups = append(u.Groups, multiValuedAttribute{Value: strconv.Itoa(int(org.ID)), Display: org.Name})
+	}
+	return u, nil
+}
+
+func writeUser(ctx context.Context, w http.ResponseWriter, status int, userID int32) error {
+	user, err := db.Users.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	u, err := toUserResource(ctx, user)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Location", u.Meta.Location)
+	return writeJSON(w, status, u)
+}
+
+func serveUsersList(w http.ResponseWriter, r *http.Request) error {
+	q, err := parseListQuery(r)
+	if err != nil {
+		return err
+	}
+
+	var users []*types.User
+	var total int
+	if q.filter != nil {
+		user, err := getUserByFilter(r.Context(), q.filter)
+		if err != nil {
+			return err
+		}
+		if user != nil {
+			total = 1
+			if q.startIndex == 1 && q.count > 0 {
+				users = append(users, user)
+			}
+		}
+	} else {
+		users, err = db.Users.List(r.Context(), &db.UsersListOptions{LimitOffset: q.limitOffset()})
+		if err != nil {
+			return err
+		}
+		total, err = db.Users.Count(r.Context(), &db.UsersListOptions{})
+		if err != nil {
+			return err
+		}
+	}
+
+	resources := make([]*userResource, 0, len(users))
+	for _, user := range users {
+		u, err := toUserResource(r.Context(), user)
+		if err != nil {
+			return err
+		}
+		resources = append(resources, u)
+	}
+	return writeJSON(w, http.StatusOK, &listResponse{
+		Schemas:      []string{listResponseSchema},
+		TotalResults: total,
+		StartIndex:   q.startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	})
+}
+
+// getUserByFilter returns the user that matches the filter, or nil if there is none.
+func getUserByFilter(ctx context.Context, f *filter) (*types.User, error) {
+	var user *types.User
+	var err error
+	switch f.attr {
+	case "id":
+		id, parseErr := strconv.ParseInt(f.value, 10, 32)
+		if parseErr != nil {
+			return nil, nil
+		}
+		user, err = db.Users.GetByID(ctx, int32(id))
+	case "username":
+		username, normalizeErr := auth.NormalizeUsername(f.value)
+		if normalizeErr != nil {
+			return nil, nil
+		}
+		user, err = db.Users.GetByUsername(ctx, username)
+	case "emails", "emails.value":
+		user, err = db.Users.GetByVerifiedEmail(ctx, f.value)
+	case "externalid":
+		// External IDs are not stored, so identity providers fall back to matching by userName.
+		return nil, nil
+	default:
+		return nil, &scimError{status: http.StatusBadRequest, scimType: "invalidFilter", detail: fmt.Sprintf("Filtering users by %q is not supported.", f.attr)}
+	}
+	if errcode.IsNotFound(err) {
+		return nil, nil
+	}
+	return user, err
+}
+
+func serveUserGet(w http.ResponseWriter, r *http.Request, userID int32) error {
+	return writeUser(r.Context(), w, http.StatusOK, userID)
+}
+
+func serveUserCreate(w http.ResponseWriter, r *http.Request) error {
+	var in userResource
+	if err := readJSON(r, &in); err != nil {
+		return err
+	}
+	username, err := normalizeUsername(in.UserName)
+	if err != nil {
+		return err
+	}
+
+	// Email addresses are managed by the identity provider, so they are trusted as verified.
+	emails := in.emailAddresses()
+	newUser := db.NewUser{Username: username, DisplayName: in.displayName()}
+	if len(emails) > 0 {
+		newUser.Email = emails[0]
+		newUser.EmailIsVerified = true
+	}
+	user, err := db.Users.Create(r.Context(), newUser)
+	if err != nil {
+		return err
+	}
+	if len(emails) > 1 {
+		if err := setUserEmails(r.Context(), user.ID, emails); err != nil {
+			return err
+		}
+	}
+	if in.Active != nil && !*in.Active {
+		if err := db.Users.SetDeactivated(r.Context(), user.ID, true); err != nil {
+			return err
+		}
+	}
+	return writeUser(r.Context(), w, http.StatusCreated, user.ID)
+}
+
+func serveUserReplace(w http.ResponseWriter, r *http.Request, userID int32) error {
+	var in userResource
+	if err := readJSON(r, &in); err != nil {
+		return err
+	}
+	user, err := db.Users.GetByID(r.Context(), userID)
+	if err != nil {
+		return err
+	}
+	if err := updateUser(r, user, &in); err != nil {
+		return err
+	}
+	return writeUser(r.Context(), w, http.StatusOK, userID)
+}
+
+func serveUserPatch(w http.ResponseWriter, r *http.Request, userID int32) error {
+	var req patchRequest
+	if err := readJSON(r, &req); err != nil {
+		return err
+	}
+	user, err := db.Users.GetByID(r.Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	// Apply the operations to the current resource, and then save it as if it had been replaced.
+	u, err := toUserResource(r.Context(), user)
+	if err != nil {
+		return err
+	}
+	var emailsChanged bool
+	for _, op := range req.Operations {
+		if err := op.apply(applyUserPatch(u, &emailsChanged)); err != nil {
+			return err
+		}
+	}
+	if !emailsChanged {
+		// Don't mark the user's unverified email addresses as verified.
+		u.Emails = nil
+	} else if u.Emails == nil {
+		u.Emails = []multiValuedAttribute{}
+	}
+	if err := updateUser(r, user, u); err != nil {
+		return err
+	}
+	return writeUser(r.Context(), w, http.StatusOK, userID)
+}
+
+// updateUser updates the user to match the resource. Attributes that are absent from the resource
+// (except displayName) are left unchanged.
+func updateUser(r *http.Request, user *types.User, u *userResource) error {
+	ctx := r.Context()
+
+	var update db.UserUpdate
+	if u.UserName != "" {
+		username, err := normalizeUsername(u.UserName)
+		if err != nil {
+			return err
+		}
+		if username != user.Username {
+			update.Username = username
+		}
+	}
+	if displayName := u.displayName(); displayName != user.DisplayName {
+		update.DisplayName = &displayName
+	}
+	if update != (db.UserUpdate{}) {
+		if err := db.Users.Update(ctx, user.ID, update); err != nil {
+			return err
+		}
+	}
+
+	if u.Emails != nil {
+		if err := setUserEmails(ctx, user.ID, u.emailAddresses()); err != nil {
+			return err
+		}
+	}
+
+	if u.Active != nil {
+		if deactivate := !*u.Active; deactivate != (user.DeactivatedAt != nil) {
+			if deactivate && isSelf(r, user.ID) {
+				return &scimError{status: http.StatusBadRequest, scimType: "mutability", detail: "The user whose access token is used for SCIM requests can't be deactivated."}
+			}
+			if err := db.Users.SetDeactivated(ctx, user.ID, deactivate); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func serveUserDelete(w http.ResponseWriter, r *http.Request, userID int32) error {
+	if isSelf(r, userID) {
+		return &scimError{status: http.StatusBadRequest, scimType: "mutability", detail: "The user whose access token is used for SCIM requests can't be deleted."}
+	}
+	// Deleting the user also deletes their access tokens, and their sessions are no longer valid.
+	if err := db.Users.Delete(r.Context(), userID); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func normalizeUsername(name string) (string, error) {
+	if name == "" {
+		return "", &scimError{status: http.StatusBadRequest, scimType: "invalidValue", detail: "The userName attribute is required."}
+	}
+	username, err := auth.NormalizeUsername(name)
+	if err != nil {
+		return "", &scimError{status: http.StatusBadRequest, scimType: "invalidValue", detail: fmt.Sprintf("Invalid userName %q: %s", name, err)}
+	}
+	return username, nil
+}
+
+// setUserEmails sets the user's email addresses, which are marked as verified because they are
+// managed by the identity provider.
+func setUserEmails(ctx context.Context, userID int32, emails []string) error {
+	current, err := db.UserEmails.ListByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	have := make(map[string]*db.UserEmail, len(current))
+	for _, e := range current {
+		have[strings.ToLower(e.Email)] = e
+	}
+	want := make(map[string]bool, len(emails))
+	for _, email := range emails {
+		want[strings.ToLower(email)] = true
+		if e, ok := have[strings.ToLower(email)]; ok {
+			if e.VerifiedAt == nil {
+				if err := db.UserEmails.SetVerified(ctx, userID, e.Email, true); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if err := db.UserEmails.Add(ctx, userID, email, nil); err != nil {
+			return err
+		}
+		if err := db.UserEmails.SetVerified(ctx, userID, email, true); err != nil {
+			return err
+		}
+	}
+	for _, e := range current {
+		if !want[strings.ToLower(e.Email)] {
+			if err := db.UserEmails.Remove(ctx, userID, e.Email); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// applyUserPatch returns a func that applies a PATCH operation to the user resource. Only the
+// attributes that Sourcegraph stores are supported; other attributes (such as "title" or
+// "externalId") are ignored. It sets *emailsChanged if an operation changes the email addresses.
+func applyUserPatch(u *userResource, emailsChanged *bool) func(op string, path *patchPath, value json.RawMessage) error {
+	return func(op string, path *patchPath, value json.RawMessage) error {
+		switch path.attr {
+		case "active":
+			if op == "remove" {
+				return nil
+			}
+			active, err := unmarshalBool(value)
+			if err != nil {
+				return err
+			}
+			u.Active = &active
+
+		case "username":
+			if op == "remove" {
+				return nil
+			}
+			return unmarshalValue(value, &u.UserName)
+
+		case "displayname":
+			if op == "remove" {
+				u.DisplayName = ""
+				return nil
+			}
+			return unmarshalValue(value, &u.DisplayName)
+
+		case "name":
+			if op == "remove" {
+				return nil
+			}
+			switch path.subAttr {
+			case "":
+				var name userName
+				if err := unmarshalValue(value, &name); err != nil {
+					return err
+				}
+				if displayName := (&userResource{Name: &name}).displayName(); displayName != "" {
+					u.DisplayName = displayName
+				}
+			case "formatted":
+				return unmarshalValue(value, &u.DisplayName)
+			}
+
+		case "emails":
+			values, err := unmarshalMultiValued(value)
+			if err != nil {
+				return err
+			}
+			u.Emails = patchMultiValued(u.Emails, op, path.filter, values)
+			*emailsChanged = true
+		}
+		return nil
+	}
+}
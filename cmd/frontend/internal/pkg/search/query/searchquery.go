@@ -28,6 +28,11 @@ const (
 	FieldCommitter = "committer"
 	FieldMessage   = "message"
 
+	// For text search only (filter line matches by the last change to the line):
+	FieldBlameAuthor = "blame.author"
+	FieldBlameBefore = "blame.before"
+	FieldBlameAfter  = "blame.after"
+
 	// Temporary experimental fields:
 	FieldIndex   = "index"
 	FieldCount   = "count" // Searches that specify `count:` will fetch at least that number of results, or the full result set
@@ -57,6 +62,10 @@ var (
 			FieldCommitter: regexpNegatableFieldType,
 			FieldMessage:   regexpNegatableFieldType,
 
+			FieldBlameAuthor: regexpNegatableFieldType,
+			FieldBlameBefore: stringFieldType,
+			FieldBlameAfter:  stringFieldType,
+
 			// Experimental fields:
 			FieldIndex:   {Literal: types.StringType, Quoted: types.StringType, Singular: true},
 			FieldCount:   {Literal: types.StringType, Quoted: types.StringType, Singular: true},
@@ -73,6 +82,9 @@ var (
 			"until":    FieldBefore,
 			"m":        FieldMessage,
 			"msg":      FieldMessage,
+
+			"blame.since": FieldBlameAfter,
+			"blame.until": FieldBlameBefore,
 		},
 	}
 )
@@ -126,7 +126,8 @@ func scanDefault(s *scanner) stateFn {
 
 func scanText(s *scanner) stateFn {
 	// Characters that may come before a ':' (TokenColon) in a TokenLiteral.
-	preColonChars := "abcdefghijklmnopqrstuvwxyz0123456789"
+	// The '.' allows namespaced field names (e.g., "blame.author").
+	preColonChars := "abcdefghijklmnopqrstuvwxyz0123456789."
 
 	for {
 		if s.eof() {
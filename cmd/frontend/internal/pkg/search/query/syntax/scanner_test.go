@@ -30,6 +30,7 @@ func TestScanner(t *testing.T) {
 		"^a .b":    {wantTypes: []TokenType{TokenLiteral, TokenSep, TokenLiteral}, wantValues: []string{"^a", " ", ".b"}},
 		"a:b c:d":  {wantTypes: []TokenType{TokenLiteral, TokenColon, TokenLiteral, TokenSep, TokenLiteral, TokenColon, TokenLiteral}, wantValues: []string{"a", ":", "b", " ", "c", ":", "d"}},
 		"a:b:c":    {wantTypes: []TokenType{TokenLiteral, TokenColon, TokenLiteral}, wantValues: []string{"a", ":", "b:c"}},
+		"a.b:c":    {wantTypes: []TokenType{TokenLiteral, TokenColon, TokenLiteral}, wantValues: []string{"a.b", ":", "c"}},
 		`a:""`:     {wantTypes: []TokenType{TokenLiteral, TokenColon, TokenQuoted}},
 		`a:"b"`:    {wantTypes: []TokenType{TokenLiteral, TokenColon, TokenQuoted}, wantValues: []string{"a", ":", `"b"`}},
 		`a:'b'`:    {wantTypes: []TokenType{TokenLiteral, TokenColon, TokenQuoted}},
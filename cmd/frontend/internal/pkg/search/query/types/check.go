@@ -57,6 +57,15 @@ func (c *Config) Check(query *syntax.Query) (*Query, error) {
 	return &checkedQuery, nil
 }
 
+// isField reports whether field is a recognized field name or alias.
+func (c *Config) isField(field string) bool {
+	if _, ok := c.FieldAliases[field]; ok {
+		return true
+	}
+	_, ok := c.FieldTypes[field]
+	return ok
+}
+
 func (c *Config) resolveField(field string, not bool) (resolvedField string, typ FieldType, err error) {
 	// Resolve field alias, if any.
 	if resolvedField, ok := c.FieldAliases[field]; ok {
@@ -86,6 +95,15 @@ func (c *Config) resolveField(field string, not bool) (resolvedField string, typ
 }
 
 func (c *Config) checkExpr(expr *syntax.Expr) (field string, fieldType FieldType, value *Value, err error) {
+	// Field names may contain a '.' (e.g., "blame.author"), but so do many
+	// terms that contain a ':' (e.g., "example.com:8080"). Treat an
+	// unrecognized field name that contains a '.' as part of the term.
+	if strings.Contains(expr.Field, ".") && !c.isField(expr.Field) {
+		term := *expr
+		term.Not = false
+		expr = &syntax.Expr{Pos: expr.Pos, Not: expr.Not, Value: term.String(), ValueType: syntax.TokenLiteral}
+	}
+
 	// Resolve field name.
 	resolvedField, fieldType, err := c.resolveField(expr.Field, expr.Not)
 	if err != nil {
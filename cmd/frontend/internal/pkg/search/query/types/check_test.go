@@ -45,6 +45,10 @@ func TestCheck(t *testing.T) {
 				Quoted:   BoolType,
 				Singular: true,
 			},
+			"x.y": {
+				Literal: RegexpType,
+				Quoted:  RegexpType,
+			},
 		},
 		FieldAliases: map[string]string{
 			"f":  "",
@@ -72,6 +76,9 @@ func TestCheck(t *testing.T) {
 		"b:yes":   {want: map[string][]value{"b": {{Value: true}}}},
 		"b:no":    {want: map[string][]value{"b": {{Value: false}}}},
 		`b:"yes"`: {want: map[string][]value{"b": {{Value: true}}}},
+		"x.y:a":   {want: map[string][]value{"x.y": {{Value: regexp.MustCompile("a")}}}},
+		"a.b:c":   {want: map[string][]value{"": {{Value: regexp.MustCompile("a.b:c")}}}},
+		`a.b:"c"`: {want: map[string][]value{"": {{Value: regexp.MustCompile(`a.b:"c"`)}}}},
 		`a "b" 'cd'`: {want: map[string][]value{"": {
 			{Value: regexp.MustCompile("a")},
 			{Value: "b"},
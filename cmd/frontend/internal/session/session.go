@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/pkg/actor"
 	"github.com/sourcegraph/sourcegraph/pkg/conf"
@@ -54,6 +55,7 @@ func init() {
 // enforce the maxAge field in its session store implementations, so we include the expiry here.
 type sessionInfo struct {
 	Actor        *actor.Actor  `json:"actor"`
+	LoginTime    time.Time     `json:"loginTime"`
 	LastActive   time.Time     `json:"lastActive"`
 	ExpiryPeriod time.Duration `json:"expiryPeriod"`
 }
@@ -175,7 +177,16 @@ func SetActor(w http.ResponseWriter, r *http.Request, actor *actor.Actor, expiry
 				expiryPeriod = defaultExpiryPeriod
 			}
 		}
-		value = &sessionInfo{Actor: actor, ExpiryPeriod: expiryPeriod, LastActive: time.Now()}
+		now := time.Now()
+		value = &sessionInfo{Actor: actor, ExpiryPeriod: expiryPeriod, LoginTime: now, LastActive: now}
+
+		// Sync the user's repository permissions promptly upon sign-in, so that changes on the code
+		// host are reflected without waiting for the permissions to become stale.
+		if _, authzProviders := authz.GetProviders(); len(authzProviders) > 0 && actor.UID != 0 {
+			if err := db.UserPermissions.RequestSync(r.Context(), actor.UID); err != nil {
+				log15.Warn("Failed to request sync of user permissions upon sign-in.", "user", actor.UID, "error", err)
+			}
+		}
 	}
 	return SetData(w, r, "actor", value)
 }
@@ -295,7 +306,8 @@ func authenticateByCookie(r *http.Request, w http.ResponseWriter) context.Contex
 		}
 
 		// Check that user still exists.
-		if _, err := db.Users.GetByID(r.Context(), info.Actor.UID); err != nil {
+		user, err := db.Users.GetByID(r.Context(), info.Actor.UID)
+		if err != nil {
 			if errcode.IsNotFound(err) {
 				_ = deleteSession(w, r) // clear the bad value
 			} else {
@@ -306,6 +318,14 @@ func authenticateByCookie(r *http.Request, w http.ResponseWriter) context.Contex
 			return r.Context() // not authenticated
 		}
 
+		// 🚨 SECURITY: Check that the user has not been deactivated and that the session was not
+		// revoked. (Sessions created before sessionInfo.LoginTime was added have a zero LoginTime, so
+		// they are treated as revoked, too.)
+		if user.DeactivatedAt != nil || (user.InvalidatedSessionsAt != nil && !info.LoginTime.After(*user.InvalidatedSessionsAt)) {
+			_ = deleteSession(w, r)
+			return r.Context() // not authenticated
+		}
+
 		// Renew session
 		if time.Since(info.LastActive) > 5*time.Minute {
 			info.LastActive = time.Now()
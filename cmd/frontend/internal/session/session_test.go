@@ -238,6 +238,51 @@ func TestCookieMiddleware(t *testing.T) {
 	}
 }
 
+func TestCookieMiddleware_revokedSession(t *testing.T) {
+	cleanup := ResetMockSessionStore(t)
+	defer cleanup()
+
+	// Start a session, then set the user's deactivation and session revocation times relative to it.
+	w := httptest.NewRecorder()
+	if err := SetActor(w, httptest.NewRequest("GET", "/", nil), &actor.Actor{UID: 123}, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	loginTime := time.Now()
+	before, after := loginTime.Add(-time.Minute), loginTime.Add(time.Minute)
+
+	tests := map[string]struct {
+		user     types.User
+		wantAuth bool
+	}{
+		"active":                          {user: types.User{ID: 123}, wantAuth: true},
+		"sessions revoked before sign-in": {user: types.User{ID: 123, InvalidatedSessionsAt: &before}, wantAuth: true},
+		"sessions revoked after sign-in":  {user: types.User{ID: 123, InvalidatedSessionsAt: &after}},
+		"deactivated":                     {user: types.User{ID: 123, DeactivatedAt: &before, InvalidatedSessionsAt: &before}},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			db.Mocks.Users.GetByID = func(ctx context.Context, id int32) (*types.User, error) {
+				return &test.user, nil
+			}
+			defer func() { db.Mocks = db.MockStores{} }()
+
+			req := httptest.NewRequest("GET", "/", nil)
+			for _, cookie := range w.Result().Cookies() {
+				req.AddCookie(cookie)
+			}
+			rr := httptest.NewRecorder()
+			CookieMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if got := actor.FromContext(r.Context()).IsAuthenticated(); got != test.wantAuth {
+					t.Errorf("got authenticated %v, want %v", got, test.wantAuth)
+				}
+			})).ServeHTTP(rr, req)
+			if deleted := strings.Contains(rr.Header().Get("Set-Cookie"), cookieName+"=;"); deleted == test.wantAuth {
+				t.Errorf("got deleted %v, want %v", deleted, !test.wantAuth)
+			}
+		})
+	}
+}
+
 // sessionCookie returns the session cookie from the header of the given request.
 func sessionCookie(r *http.Request) string {
 	c, err := r.Cookie(cookieName)
@@ -17,6 +17,12 @@ type DiscussionThread struct {
 	ArchivedAt   *time.Time
 	UpdatedAt    time.Time
 	DeletedAt    *time.Time
+	ResolvedAt   *time.Time
+	Labels       []string
+
+	// AssigneeUserIDs are the users assigned to the thread (from the
+	// discussion_thread_assignees table), in the order they were assigned.
+	AssigneeUserIDs []int32
 }
 
 // DiscussionThreadTargetRepo mirrors the underlying discussion_threads_target_repo field types exactly.
@@ -55,4 +61,32 @@ type DiscussionComment struct {
 	UpdatedAt    time.Time
 	DeletedAt    *time.Time
 	Reports      []string
+
+	ParentCommentID *int64 // the comment that this comment is a reply to, if any
+	ResolvedAt      *time.Time
+}
+
+// DiscussionCommentReaction mirrors the underlying discussion_comment_reactions field types
+// exactly. It is an emoji reaction of a user to a discussion comment.
+type DiscussionCommentReaction struct {
+	CommentID int64
+	UserID    int32
+	Emoji     string
+	CreatedAt time.Time
+}
+
+// DiscussionCodeHostComment mirrors the underlying discussion_code_host_comments field types
+// exactly. It links a discussion comment to the code host pull request comment that it was
+// mirrored to or imported from.
+type DiscussionCodeHostComment struct {
+	ID                int64
+	ThreadID          int64
+	CommentID         int64
+	ServiceType       string
+	ServiceID         string
+	ExternalRepoID    string
+	PullRequestNumber int32
+	ExternalThreadID  string // empty if the thread is pending (see ExternalCommentID)
+	ExternalCommentID string // empty if the comment is pending (being posted to the code host)
+	CreatedAt         time.Time
 }
@@ -61,6 +61,14 @@ type User struct {
 	UpdatedAt   time.Time
 	SiteAdmin   bool
 	Tags        []string
+
+	// DeactivatedAt is when the user was deactivated, or nil if the user is active. Deactivated
+	// users can't sign in or use the API.
+	DeactivatedAt *time.Time
+
+	// InvalidatedSessionsAt is when the user's sessions were last revoked (on deactivation). Sessions
+	// started before then are no longer valid.
+	InvalidatedSessionsAt *time.Time
 }
 
 type Org struct {
@@ -86,6 +94,30 @@ type PhabricatorRepo struct {
 	Callsign string
 }
 
+// UserPermissions is the set of repositories that a user may access, as most
+// recently synced in the background from the authz providers.
+type UserPermissions struct {
+	UserID          int32 // 0 for anonymous users
+	ReadRepoIDs     []api.RepoID
+	SyncedAt        *time.Time // time of the last successful sync
+	SyncAttemptedAt *time.Time
+	SyncRequestedAt *time.Time
+	SyncError       *string // error of the last sync attempt, if it failed
+}
+
+// ExplicitRepoPermission grants a user or an organization's members read access to a repository
+// (or to all repositories whose names match a pattern). Exactly one of RepoID and RepoPattern, and
+// exactly one of UserID and OrgID, is set.
+type ExplicitRepoPermission struct {
+	ID          int64
+	RepoID      api.RepoID
+	RepoName    api.RepoName // the name of the repository with RepoID
+	RepoPattern string       // a regular expression that is matched against repository names
+	UserID      int32
+	OrgID       int32
+	CreatedAt   time.Time
+}
+
 type UserUsageStatistics struct {
 	UserID                      int32
 	PageViews                   int32
@@ -118,3 +150,24 @@ type SurveyResponse struct {
 	Better    *string
 	CreatedAt time.Time
 }
+
+// LanguageStatisticsPoint is the language breakdown of one or more
+// repositories' default branches as of a point in time.
+type LanguageStatisticsPoint struct {
+	Date      time.Time
+	Languages []*LanguageStatistic // sorted by TotalBytes, descending
+}
+
+// LanguageStatistic is the total size of the code written in a language.
+type LanguageStatistic struct {
+	Name       string
+	TotalBytes int64
+}
+
+// InsightSeriesPoint is the number of files matching a code insight series's
+// query in one or more repositories' default branches as of a point in time.
+type InsightSeriesPoint struct {
+	Time         time.Time
+	Value        int
+	Repositories int // the number of repositories whose count is included in Value
+}
@@ -2,6 +2,7 @@
 package main // import "github.com/sourcegraph/sourcegraph/cmd/gitserver"
 
 import (
+	"context"
 	"log"
 	"net"
 	"net/http"
@@ -16,8 +17,10 @@ import (
 	log15 "gopkg.in/inconshreveable/log15.v2"
 
 	"github.com/sourcegraph/sourcegraph/cmd/gitserver/server"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
 	"github.com/sourcegraph/sourcegraph/pkg/debugserver"
 	"github.com/sourcegraph/sourcegraph/pkg/env"
+	"github.com/sourcegraph/sourcegraph/pkg/queryrunnerapi"
 	"github.com/sourcegraph/sourcegraph/pkg/tracer"
 )
 
@@ -43,6 +46,7 @@ func main() {
 	gitserver := server.Server{
 		ReposDir:                reposDir,
 		DeleteStaleRepositories: runRepoCleanup,
+		RepoUpdated:             notifyRepoUpdated,
 	}
 	gitserver.RegisterMetrics()
 
@@ -99,3 +103,12 @@ func main() {
 	// shutdown they will be orphaned and continue running.
 	gitserver.Stop()
 }
+
+// notifyRepoUpdated tells the query-runner that the repository's default
+// branch advanced, so that it can run the saved searches that apply to the
+// repository on the new commits.
+func notifyRepoUpdated(repo api.RepoName, oldCommit, newCommit api.CommitID) {
+	if err := queryrunnerapi.Client.RepoUpdated(context.Background(), repo, oldCommit, newCommit); err != nil {
+		log15.Debug("Failed to notify query-runner of repository update.", "repo", repo, "error", err)
+	}
+}
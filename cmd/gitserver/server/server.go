@@ -134,6 +134,11 @@ type Server struct {
 
 	repoUpdateLocksMu sync.Mutex // protects the map below and also updates to locks.once
 	repoUpdateLocks   map[api.RepoName]*locks
+
+	// RepoUpdated, if set, is called (in its own goroutine) when updating a
+	// repository advances its default branch (HEAD) from oldCommit to
+	// newCommit. It is not called for clones.
+	RepoUpdated func(repo api.RepoName, oldCommit, newCommit api.CommitID)
 }
 
 type locks struct {
@@ -1197,6 +1202,8 @@ func (s *Server) doRepoUpdate2(repo api.RepoName, url string) error {
 		}
 	}
 
+	oldHead := headCommit(ctx, dir)
+
 	cmd := exec.CommandContext(ctx, "git", "fetch", "--prune", url, "+refs/heads/*:refs/heads/*", "+refs/tags/*:refs/tags/*", "+refs/pull/*:refs/pull/*")
 	cmd.Dir = dir
 
@@ -1260,9 +1267,31 @@ func (s *Server) doRepoUpdate2(repo api.RepoName, url string) error {
 		log15.Error("Failed to set HEAD", "repo", repo, "error", err, "output", string(output))
 		return errors.Wrap(err, "Failed to set HEAD")
 	}
+
+	if s.RepoUpdated != nil {
+		if newHead := headCommit(ctx, dir); oldHead != "" && newHead != "" && newHead != oldHead {
+			go s.RepoUpdated(repo, oldHead, newHead)
+		}
+	}
 	return nil
 }
 
+// headCommit returns the commit that HEAD points to in the repository in dir,
+// or "" if it can't be determined (e.g., because the repository is empty).
+func headCommit(ctx context.Context, dir string) api.CommitID {
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "HEAD")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	commit := api.CommitID(bytes.TrimSpace(out))
+	if len(commit) != 40 {
+		return ""
+	}
+	return commit
+}
+
 func (s *Server) ensureRevision(ctx context.Context, repo api.RepoName, url, rev, repoDir string) (didUpdate bool) {
 	if rev == "" || rev == "HEAD" {
 		return false
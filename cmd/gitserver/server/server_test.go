@@ -16,6 +16,7 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
 	"github.com/sourcegraph/sourcegraph/pkg/mutablelimiter"
 )
 
@@ -388,3 +389,77 @@ func TestCloneRepo(t *testing.T) {
 		t.Fatal("failed to clone")
 	}
 }
+
+func TestRepoUpdated(t *testing.T) {
+	remote, cleanup1 := tmpDir(t)
+	defer cleanup1()
+
+	cmd := func(dir, name string, arg ...string) string {
+		t.Helper()
+		c := exec.Command(name, arg...)
+		c.Dir = dir
+		c.Env = []string{
+			"GIT_COMMITTER_NAME=a",
+			"GIT_COMMITTER_EMAIL=a@a.com",
+			"GIT_AUTHOR_NAME=a",
+			"GIT_AUTHOR_EMAIL=a@a.com",
+		}
+		b, err := c.Output()
+		if err != nil {
+			t.Fatalf("%s %s failed: %s", name, strings.Join(arg, " "), err)
+		}
+		return strings.TrimSpace(string(b))
+	}
+
+	cmd(remote, "git", "init", ".")
+	cmd(remote, "git", "commit", "--allow-empty", "-m", "a")
+	oldCommit := cmd(remote, "git", "rev-parse", "HEAD")
+
+	reposDir, cleanup2 := tmpDir(t)
+	defer cleanup2()
+	dst := filepath.Join(reposDir, "example.com/foo/bar")
+	if err := os.MkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	cmd(reposDir, "git", "clone", "--mirror", remote, dst)
+
+	type update struct{ old, new api.CommitID }
+	updates := make(chan update, 1)
+	s := &Server{
+		ReposDir:         reposDir,
+		ctx:              context.Background(),
+		locker:           &RepositoryLocker{},
+		cloneLimiter:     mutablelimiter.New(1),
+		cloneableLimiter: mutablelimiter.New(1),
+		RepoUpdated: func(repo api.RepoName, oldCommit, newCommit api.CommitID) {
+			if repo != "example.com/foo/bar" {
+				t.Errorf("got repo %q, want %q", repo, "example.com/foo/bar")
+			}
+			updates <- update{old: oldCommit, new: newCommit}
+		},
+	}
+
+	// An update that does not change HEAD is not reported.
+	if err := s.doRepoUpdate2("example.com/foo/bar", remote); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case u := <-updates:
+		t.Fatalf("got update %+v, want none", u)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	cmd(remote, "git", "commit", "--allow-empty", "-m", "b")
+	newCommit := cmd(remote, "git", "rev-parse", "HEAD")
+	if err := s.doRepoUpdate2("example.com/foo/bar", remote); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case u := <-updates:
+		if want := (update{old: api.CommitID(oldCommit), new: api.CommitID(newCommit)}); u != want {
+			t.Errorf("got update %+v, want %+v", u, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for update")
+	}
+}
@@ -11,8 +11,8 @@ import (
 	log15 "gopkg.in/inconshreveable/log15.v2"
 
 	"github.com/pkg/errors"
-	"github.com/sourcegraph/sourcegraph/cmd/query-runner/queryrunnerapi"
 	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/queryrunnerapi"
 )
 
 var allSavedQueries = &allSavedQueriesCached{}
@@ -229,6 +229,10 @@ func serveTestNotification(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 	}
+	if err := webhookNotifyTest(r.Context(), query); err != nil {
+		writeError(w, fmt.Errorf("error sending webhook notification: %s", err))
+		return
+	}
 
 	log15.Info("saved query test notification sent", "spec", args.Spec, "key", key)
 }
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	log15 "gopkg.in/inconshreveable/log15.v2"
+
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+)
+
+// maxContentMatches is the result count used for saved queries on file
+// contents that do not specify one with count:.
+const maxContentMatches = 1000
+
+// isCommitQuery reports whether the query searches commits or diffs (as
+// opposed to file contents), which means that it supports the after:"time"
+// operator.
+func isCommitQuery(query string) bool {
+	return strings.Contains(query, "type:diff") || strings.Contains(query, "type:commit")
+}
+
+// runContentQuery runs the given saved query on file contents. Such queries do
+// not support the after:"time" operator, so new and removed results are found
+// by comparing the matches against the snapshot of matches from the previous
+// execution.
+func (e *executorT) runContentQuery(ctx context.Context, spec api.SavedQueryIDSpec, query api.ConfigSavedQuery, info *api.SavedQueryInfo) (err error) {
+	newQuery := query.Query
+	if !strings.Contains(newQuery, "count:") {
+		newQuery = fmt.Sprintf("%s count:%d", newQuery, maxContentMatches)
+	}
+
+	snapshot, err := api.InternalClient.SavedQueriesGetSnapshot(ctx, query.Query)
+	if err != nil {
+		return errors.Wrap(err, "SavedQueriesGetSnapshot")
+	}
+
+	// As with commit queries, mark the saved query as having been executed
+	// regardless of whether or not the search fails.
+	start := time.Now()
+	v, execDuration, searchErr := performSearch(ctx, newQuery)
+	run := newRun(query.Query, start, execDuration, v, searchErr)
+	defer func() { logRun(ctx, run, err) }()
+
+	latestResult := time.Now()
+	if info != nil {
+		latestResult = info.LatestResult
+	}
+	if err := api.InternalClient.SavedQueriesSetInfo(ctx, &api.SavedQueryInfo{
+		Query:        query.Query,
+		LastExecuted: time.Now(),
+		LatestResult: latestResult,
+		ExecDuration: execDuration,
+	}); err != nil {
+		return errors.Wrap(err, "SavedQueriesSetInfo")
+	}
+	if searchErr != nil {
+		return searchErr
+	}
+
+	matches, err := extractContentMatches(v.Data.Search.Results.Results)
+	if err != nil {
+		return err
+	}
+	resultCount := len(matches)
+	run.ResultCount = &resultCount
+	if incompleteResults(v) {
+		return nil
+	}
+	added, removed, err := updateContentSnapshot(ctx, query, snapshot, nil, matches)
+	if err != nil {
+		return err
+	}
+	run.NewResultCount = len(added)
+	run.NewResults = contentRunResults(added)
+	if len(added) == 0 && len(removed) == 0 {
+		return nil
+	}
+
+	// Saved queries with a monitor only send notifications when the
+	// monitor's trigger condition is met.
+	notifyQuery, reason, triggered := checkMonitor(ctx, query, run)
+	if !triggered {
+		return nil
+	}
+	newResults := run.NewResults
+
+	// Send notifications in a separate goroutine for the same reason as
+	// commit queries.
+	go func() {
+		if err := notifyContentChanges(context.Background(), spec, notifyQuery, added, removed); err != nil {
+			log15.Error("executor: failed to send notifications", "error", err)
+		}
+		monitorOpenDiscussions(context.Background(), spec, query, reason, newResults)
+	}()
+	return nil
+}
+
+// incompleteResults reports (and logs) whether the search results are
+// incomplete. Comparing an incomplete set of matches against the snapshot
+// would report the missing matches as removed, so the previous snapshot is
+// kept until we get complete results.
+func incompleteResults(v *gqlSearchResponse) bool {
+	results := v.Data.Search.Results
+	if results.LimitHit || len(results.Cloning) > 0 || len(results.Timedout) > 0 {
+		log15.Warn("executor: incomplete results for saved query on file contents, not comparing against previous results", "limitHit", results.LimitHit, "cloning", len(results.Cloning), "timedout", len(results.Timedout))
+		return true
+	}
+	return false
+}
+
+// updateContentSnapshot stores the new matches of the saved query and returns
+// the matches that were added and removed since the snapshot. If repo is
+// non-nil, the matches are only those in that repository and replace just that
+// repository's matches in the snapshot.
+func updateContentSnapshot(ctx context.Context, query api.ConfigSavedQuery, snapshot *api.SavedQuerySnapshot, repo *api.RepoName, matches []*api.SavedQueryMatch) (added, removed []*api.SavedQueryMatch, err error) {
+	var oldMatches []*api.SavedQueryMatch
+	if snapshot != nil {
+		oldMatches = snapshot.Matches
+	}
+	allMatches := matches
+	if repo != nil && snapshot != nil {
+		// Keep the matches in other repositories.
+		oldMatches, allMatches = nil, nil
+		for _, m := range snapshot.Matches {
+			if m.Repo == *repo {
+				oldMatches = append(oldMatches, m)
+			} else {
+				allMatches = append(allMatches, m)
+			}
+		}
+		allMatches = append(allMatches, matches...)
+	}
+
+	if err := api.InternalClient.SavedQueriesSetSnapshot(ctx, &api.SavedQuerySnapshot{
+		Query:   query.Query,
+		Matches: allMatches,
+	}); err != nil {
+		return nil, nil, errors.Wrap(err, "SavedQueriesSetSnapshot")
+	}
+	if snapshot == nil {
+		// We've never executed this search query before, so there is nothing
+		// to compare against.
+		return nil, nil, nil
+	}
+	added, removed = diffContentMatches(oldMatches, matches)
+	return added, removed, nil
+}
+
+// extractContentMatches returns the lines matched by the file match search
+// results. A file match without line matches (such as a match on the file
+// path) is returned as a single match with a zero line number.
+func extractContentMatches(results []interface{}) ([]*api.SavedQueryMatch, error) {
+	var matches []*api.SavedQueryMatch
+	for _, result := range results {
+		// Round-trip the result through JSON instead of asserting on the
+		// structure of the untyped result.
+		b, err := json.Marshal(result)
+		if err != nil {
+			return nil, errors.Wrap(err, "Marshal")
+		}
+		var fileMatch struct {
+			Typename   string `json:"__typename"`
+			Repository struct {
+				Name api.RepoName
+			}
+			File struct {
+				Path string
+			}
+			LineMatches []struct {
+				Preview    string
+				LineNumber int
+			}
+		}
+		if err := json.Unmarshal(b, &fileMatch); err != nil {
+			return nil, errors.Wrap(err, "Unmarshal")
+		}
+		if fileMatch.Typename != "FileMatch" {
+			continue
+		}
+
+		if len(fileMatch.LineMatches) == 0 {
+			matches = append(matches, &api.SavedQueryMatch{
+				Repo: fileMatch.Repository.Name,
+				Path: fileMatch.File.Path,
+			})
+			continue
+		}
+		for _, lm := range fileMatch.LineMatches {
+			matches = append(matches, &api.SavedQueryMatch{
+				Repo:    fileMatch.Repository.Name,
+				Path:    fileMatch.File.Path,
+				Line:    lm.LineNumber + 1, // line matches are 0-indexed
+				Preview: lm.Preview,
+			})
+		}
+	}
+	return matches, nil
+}
+
+// diffContentMatches returns the matches in new that are not in old (added),
+// and the matches in old that are not in new (removed).
+//
+// Matches are compared by repository, path and the content of the matched
+// line, but not by line number, so that a matched line moving up or down in
+// the file (because lines were added or removed above it) is not reported.
+func diffContentMatches(old, new []*api.SavedQueryMatch) (added, removed []*api.SavedQueryMatch) {
+	key := func(m *api.SavedQueryMatch) string {
+		return fmt.Sprintf("%s\x00%s\x00%s", m.Repo, m.Path, strings.TrimSpace(m.Preview))
+	}
+
+	// The same line can be matched multiple times in a file, so count the
+	// occurrences of each key.
+	missing := func(a, b []*api.SavedQueryMatch) (missing []*api.SavedQueryMatch) {
+		counts := make(map[string]int, len(b))
+		for _, m := range b {
+			counts[key(m)]++
+		}
+		for _, m := range a {
+			if k := key(m); counts[k] > 0 {
+				counts[k]--
+			} else {
+				missing = append(missing, m)
+			}
+		}
+		return missing
+	}
+	return missing(new, old), missing(old, new)
+}
+
+// notifyContentChanges handles sending notifications for added and removed
+// results of a saved query on file contents.
+func notifyContentChanges(ctx context.Context, spec api.SavedQueryIDSpec, query api.ConfigSavedQuery, added, removed []*api.SavedQueryMatch) error {
+	log15.Info("sending notifications", "added_results", len(added), "removed_results", len(removed), "description", query.Description)
+
+	// Determine which users to notify.
+	recipients, err := getNotificationRecipients(ctx, spec, query)
+	if err != nil {
+		return err
+	}
+
+	n := &notifier{
+		spec:       spec,
+		query:      query,
+		newQuery:   query.Query,
+		added:      added,
+		removed:    removed,
+		recipients: recipients,
+	}
+
+	// Queue the notifications of recipients who receive digests.
+	if err := n.holdForDigests(ctx); err != nil {
+		return err
+	}
+
+	// Send Slack, email and webhook notifications.
+	n.slackNotify(ctx)
+	n.emailNotify(ctx)
+	n.webhookNotify(ctx)
+	return nil
+}
+
+func pluralSuffix(n int) string {
+	if n == 1 {
+		return ""
+	}
+	return "s"
+}
+
+// maxNotifiedContentMatches is the maximum number of added (or removed)
+// matches listed in a notification.
+const maxNotifiedContentMatches = 10
+
+// contentMatchLines formats the matches for display in a notification, one per
+// line. At most maxNotifiedContentMatches are included; the number of omitted
+// matches is returned.
+func contentMatchLines(matches []*api.SavedQueryMatch) (lines []string, omitted int) {
+	if len(matches) > maxNotifiedContentMatches {
+		omitted = len(matches) - maxNotifiedContentMatches
+		matches = matches[:maxNotifiedContentMatches]
+	}
+	for _, m := range matches {
+		location := fmt.Sprintf("%s/%s", m.Repo, m.Path)
+		if m.Line > 0 {
+			location = fmt.Sprintf("%s:%d", location, m.Line)
+		}
+		if preview := strings.TrimSpace(m.Preview); preview != "" {
+			location = fmt.Sprintf("%s: %s", location, preview)
+		}
+		lines = append(lines, location)
+	}
+	return lines, omitted
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+)
+
+func TestExtractContentMatches(t *testing.T) {
+	var results []interface{}
+	if err := json.Unmarshal([]byte(`[
+		{
+			"__typename": "FileMatch",
+			"repository": {"name": "github.com/a/b"},
+			"file": {"path": "c.go"},
+			"lineMatches": [
+				{"preview": "// TODO: x", "lineNumber": 2},
+				{"preview": "// TODO: y", "lineNumber": 9}
+			]
+		},
+		{
+			"__typename": "FileMatch",
+			"repository": {"name": "github.com/a/b"},
+			"file": {"path": "TODO.md"},
+			"lineMatches": []
+		},
+		{
+			"__typename": "CommitSearchResult"
+		}
+	]`), &results); err != nil {
+		t.Fatal(err)
+	}
+
+	matches, err := extractContentMatches(results)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*api.SavedQueryMatch{
+		{Repo: "github.com/a/b", Path: "c.go", Line: 3, Preview: "// TODO: x"},
+		{Repo: "github.com/a/b", Path: "c.go", Line: 10, Preview: "// TODO: y"},
+		{Repo: "github.com/a/b", Path: "TODO.md"},
+	}
+	if !reflect.DeepEqual(matches, want) {
+		t.Errorf("got %+v, want %+v", matches, want)
+	}
+}
+
+func TestDiffContentMatches(t *testing.T) {
+	m := func(path string, line int, preview string) *api.SavedQueryMatch {
+		return &api.SavedQueryMatch{Repo: "r", Path: path, Line: line, Preview: preview}
+	}
+
+	tests := map[string]struct {
+		old, new       []*api.SavedQueryMatch
+		added, removed []*api.SavedQueryMatch
+	}{
+		"unchanged": {
+			old: []*api.SavedQueryMatch{m("a", 1, "x")},
+			new: []*api.SavedQueryMatch{m("a", 1, "x")},
+		},
+		"moved line": {
+			old: []*api.SavedQueryMatch{m("a", 1, "  x")},
+			new: []*api.SavedQueryMatch{m("a", 5, "\tx")},
+		},
+		"added and removed": {
+			old:     []*api.SavedQueryMatch{m("a", 1, "x"), m("b", 1, "x")},
+			new:     []*api.SavedQueryMatch{m("a", 1, "x"), m("a", 2, "y")},
+			added:   []*api.SavedQueryMatch{m("a", 2, "y")},
+			removed: []*api.SavedQueryMatch{m("b", 1, "x")},
+		},
+		"duplicate lines": {
+			old:   []*api.SavedQueryMatch{m("a", 1, "x")},
+			new:   []*api.SavedQueryMatch{m("a", 1, "x"), m("a", 7, "x")},
+			added: []*api.SavedQueryMatch{m("a", 7, "x")},
+		},
+		"changed line": {
+			old:     []*api.SavedQueryMatch{m("a", 1, "x")},
+			new:     []*api.SavedQueryMatch{m("a", 1, "x2")},
+			added:   []*api.SavedQueryMatch{m("a", 1, "x2")},
+			removed: []*api.SavedQueryMatch{m("a", 1, "x")},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			added, removed := diffContentMatches(test.old, test.new)
+			if !reflect.DeepEqual(added, test.added) {
+				t.Errorf("got added %+v, want %+v", added, test.added)
+			}
+			if !reflect.DeepEqual(removed, test.removed) {
+				t.Errorf("got removed %+v, want %+v", removed, test.removed)
+			}
+		})
+	}
+}
@@ -0,0 +1,330 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+	log15 "gopkg.in/inconshreveable/log15.v2"
+
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/txemail"
+	"github.com/sourcegraph/sourcegraph/pkg/txemail/txtypes"
+)
+
+// Digest channels (the values of api.SavedQueryDigestItem.Channel).
+const (
+	digestChannelEmail = "email"
+	digestChannelSlack = "slack"
+)
+
+// digestCheckInterval is how often the executor checks for digests that are
+// due to be sent.
+const digestCheckInterval = 5 * time.Minute
+
+// holdForDigests applies the users' notification preferences to the
+// notification: users who unsubscribed from the saved query are removed from
+// n.recipients, and so are recipients who receive hourly or daily digests,
+// for whom the notification is queued instead.
+func (n *notifier) holdForDigests(ctx context.Context) error {
+	prefs, err := api.InternalClient.SavedQueriesGetNotificationPreferences(ctx, n.spec)
+	if err != nil {
+		return errors.Wrap(err, "SavedQueriesGetNotificationPreferences")
+	}
+
+	item := api.SavedQueryDigestItem{
+		Spec:        n.spec,
+		Description: n.query.Description,
+		Query:       n.newQuery,
+	}
+	if n.results != nil {
+		item.AddedCount = len(n.results.Data.Search.Results.Results)
+	} else {
+		item.AddedCount, item.RemovedCount = len(n.added), len(n.removed)
+	}
+
+	var items []*api.SavedQueryDigestItem
+	n.recipients, items = splitDigestRecipients(n.query, n.recipients, prefs, item)
+	for _, item := range items {
+		if err := api.InternalClient.SavedQueriesAddDigestItem(ctx, item); err != nil {
+			log15.Error("executor: failed to queue saved search notification for digest", "recipient_user_id", item.RecipientUserID, "recipient_org_id", item.RecipientOrgID, "error", err)
+		}
+	}
+	return nil
+}
+
+// splitDigestRecipients returns the recipients to notify immediately, and the
+// digest items (copies of item, one per recipient and channel) to queue for
+// the recipients who receive digests. Users who unsubscribed are omitted.
+//
+// A recipient's notification frequency is their own preference if they set
+// one, or else the saved query's default.
+func splitDigestRecipients(query api.ConfigSavedQuery, rs recipients, prefs []*api.SavedQueryNotificationPreference, item api.SavedQueryDigestItem) (immediate recipients, items []*api.SavedQueryDigestItem) {
+	byUser := make(map[int32]*api.SavedQueryNotificationPreference, len(prefs))
+	for _, p := range prefs {
+		byUser[p.UserID] = p
+	}
+
+	for _, r := range rs {
+		frequency := query.NotifyFrequency
+		if p := byUser[r.spec.userID]; r.spec.userID != 0 && p != nil {
+			if p.Unsubscribed {
+				continue
+			}
+			if p.Frequency != "" {
+				frequency = p.Frequency
+			}
+		}
+		if frequency != api.SavedQueryNotificationFrequencyHourly && frequency != api.SavedQueryNotificationFrequencyDaily {
+			immediate = append(immediate, r)
+			continue
+		}
+
+		for _, channel := range []struct {
+			name    string
+			enabled bool
+		}{{digestChannelEmail, r.email}, {digestChannelSlack, r.slack}} {
+			if !channel.enabled {
+				continue
+			}
+			item := item
+			item.RecipientUserID, item.RecipientOrgID = r.spec.userID, r.spec.orgID
+			item.Channel = channel.name
+			item.Frequency = frequency
+			items = append(items, &item)
+		}
+	}
+	return immediate, items
+}
+
+// runDigests periodically sends the digests that are due.
+func runDigests(ctx context.Context) {
+	for {
+		if err := sendDueDigests(ctx, time.Now()); err != nil {
+			log15.Error("executor: failed to send saved search digests", "error", err)
+		}
+		time.Sleep(digestCheckInterval)
+	}
+}
+
+func sendDueDigests(ctx context.Context, now time.Time) error {
+	items, err := api.InternalClient.SavedQueriesListDigestItems(ctx)
+	if err != nil {
+		return errors.Wrap(err, "SavedQueriesListDigestItems")
+	}
+	for _, d := range dueDigests(items, now) {
+		if err := sendDigest(ctx, d); err != nil {
+			// The items stay queued, so sending is retried on the next check.
+			log15.Error("executor: failed to send saved search digest", "recipient", d.recipient, "channel", d.channel, "error", err)
+			continue
+		}
+		ids := make([]int64, len(d.items))
+		for i, item := range d.items {
+			ids[i] = item.ID
+		}
+		if err := api.InternalClient.SavedQueriesDeleteDigestItems(ctx, ids); err != nil {
+			return errors.Wrap(err, "SavedQueriesDeleteDigestItems")
+		}
+	}
+	return nil
+}
+
+// digest is a batch of queued notifications for a recipient that are sent
+// together.
+type digest struct {
+	recipient recipientSpec
+	channel   string
+	frequency string
+	items     []*api.SavedQueryDigestItem // oldest first
+}
+
+// dueDigests groups the queued notifications (oldest first) into digests and
+// returns the digests that are due: those whose oldest notification has waited
+// for at least the digest's period.
+func dueDigests(items []*api.SavedQueryDigestItem, now time.Time) []*digest {
+	type key struct {
+		recipient          recipientSpec
+		channel, frequency string
+	}
+	var (
+		digests []*digest
+		byKey   = map[key]*digest{}
+	)
+	for _, item := range items {
+		k := key{
+			recipient: recipientSpec{userID: item.RecipientUserID, orgID: item.RecipientOrgID},
+			channel:   item.Channel,
+			frequency: item.Frequency,
+		}
+		d := byKey[k]
+		if d == nil {
+			d = &digest{recipient: k.recipient, channel: k.channel, frequency: k.frequency}
+			byKey[k] = d
+			digests = append(digests, d)
+		}
+		d.items = append(d.items, item)
+	}
+
+	var due []*digest
+	for _, d := range digests {
+		if !d.items[0].CreatedAt.After(now.Add(-digestPeriod(d.frequency))) {
+			due = append(due, d)
+		}
+	}
+	return due
+}
+
+func digestPeriod(frequency string) time.Duration {
+	if frequency == api.SavedQueryNotificationFrequencyDaily {
+		return 24 * time.Hour
+	}
+	return time.Hour
+}
+
+// digestEntry summarizes the queued notifications for one saved query in a
+// digest.
+type digestEntry struct {
+	spec         api.SavedQueryIDSpec
+	description  string
+	query        string
+	addedCount   int
+	removedCount int
+}
+
+// digestEntries summarizes the digest's notifications per saved query, in the
+// order of each saved query's oldest notification.
+func digestEntries(items []*api.SavedQueryDigestItem) []*digestEntry {
+	var (
+		entries []*digestEntry
+		byKey   = map[string]*digestEntry{}
+	)
+	for _, item := range items {
+		key := savedQueryIDSpecKey(item.Spec)
+		e := byKey[key]
+		if e == nil {
+			// The oldest notification's query finds all of the new results
+			// since then (for diff and commit searches, later queries only
+			// find results after a later time).
+			e = &digestEntry{spec: item.Spec, query: item.Query}
+			byKey[key] = e
+			entries = append(entries, e)
+		}
+		e.description = item.Description
+		e.addedCount += item.AddedCount
+		e.removedCount += item.RemovedCount
+	}
+	return entries
+}
+
+// unsubscribeURL returns the URL that a user visits to unsubscribe from
+// notifications for the saved query.
+func unsubscribeURL(spec api.SavedQueryIDSpec) string {
+	q := url.Values{}
+	q.Set("subject", spec.Subject.String())
+	q.Set("key", spec.Key)
+	return absoluteURL("/-/saved-searches/unsubscribe?" + q.Encode())
+}
+
+func sendDigest(ctx context.Context, d *digest) error {
+	entries := digestEntries(d.items)
+	switch d.channel {
+	case digestChannelEmail:
+		if err := canSendEmail(ctx); err != nil {
+			return err
+		}
+		ctx, cancel := context.WithTimeout(ctx, time.Minute)
+		defer cancel()
+		return sendEmail(ctx, d.recipient.userID, "digest", digestEmailTemplates, digestEmailData(d.frequency, entries))
+
+	case digestChannelSlack:
+		if err := slackNotify(ctx, &recipient{spec: d.recipient, slack: true}, digestSlackText(d, entries)); err != nil {
+			return err
+		}
+		logEvent("", "SavedSearchSlackNotificationSent", "digest")
+		return nil
+
+	default:
+		return fmt.Errorf("unknown digest channel %q", d.channel)
+	}
+}
+
+type digestEmailEntry struct {
+	Description    string
+	URL            string
+	UnsubscribeURL string
+	AddedCount     int
+	RemovedCount   int
+}
+
+func digestEmailData(frequency string, entries []*digestEntry) interface{} {
+	emailEntries := make([]*digestEmailEntry, len(entries))
+	for i, e := range entries {
+		emailEntries[i] = &digestEmailEntry{
+			Description:    e.description,
+			URL:            searchURL(e.query, utmSourceEmail),
+			UnsubscribeURL: unsubscribeURL(e.spec),
+			AddedCount:     e.addedCount,
+			RemovedCount:   e.removedCount,
+		}
+	}
+	pluralSearches := "es"
+	if len(entries) == 1 {
+		pluralSearches = ""
+	}
+	return struct {
+		Frequency      string
+		Entries        []*digestEmailEntry
+		PluralSearches string
+	}{
+		Frequency:      frequency,
+		Entries:        emailEntries,
+		PluralSearches: pluralSearches,
+	}
+}
+
+var digestEmailTemplates = txemail.MustValidate(txtypes.Templates{
+	Subject: `[{{len .Entries}} saved search{{.PluralSearches}}] Your {{.Frequency}} digest of new results`,
+	Text: `
+New search results were found for {{len .Entries}} saved search{{.PluralSearches}}:
+{{range .Entries}}
+  "{{.Description}}": {{.AddedCount}} new{{if .RemovedCount}}, {{.RemovedCount}} removed{{end}}
+  View the results on Sourcegraph: {{.URL}}
+  Unsubscribe from this saved search: {{.UnsubscribeURL}}
+{{end}}
+You are receiving this {{.Frequency}} digest because of your notification preferences for these saved searches.
+`,
+	HTML: `
+<p>New search results were found for {{len .Entries}} saved search{{.PluralSearches}}:</p>
+
+<ul>{{range .Entries}}
+<li>
+<a href="{{.URL}}">&quot;{{.Description}}&quot;</a>: <strong>{{.AddedCount}}</strong> new{{if .RemovedCount}}, <strong>{{.RemovedCount}}</strong> removed{{end}}
+(<a href="{{.UnsubscribeURL}}">unsubscribe</a>)
+</li>{{end}}
+</ul>
+
+<p>You are receiving this {{.Frequency}} digest because of your notification preferences for these saved searches.</p>
+`,
+})
+
+// digestSlackText formats the digest as a Slack message. Unsubscribe links are
+// only included for users (not organizations).
+func digestSlackText(d *digest, entries []*digestEntry) string {
+	pluralSearches := "es"
+	if len(entries) == 1 {
+		pluralSearches = ""
+	}
+	text := fmt.Sprintf("New results found for %d saved search%s (%s digest):", len(entries), pluralSearches, d.frequency)
+	for _, e := range entries {
+		text += fmt.Sprintf("\n• <%s|\"%s\">: *%d* new", searchURL(e.query, utmSourceSlack), e.description, e.addedCount)
+		if e.removedCount > 0 {
+			text += fmt.Sprintf(", *%d* removed", e.removedCount)
+		}
+		if d.recipient.userID != 0 {
+			text += fmt.Sprintf(" (<%s|unsubscribe>)", unsubscribeURL(e.spec))
+		}
+	}
+	return text
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+)
+
+func TestSplitDigestRecipients(t *testing.T) {
+	orgID := int32(9)
+	spec := api.SavedQueryIDSpec{Subject: api.SettingsSubject{Org: &orgID}, Key: "k"}
+	item := api.SavedQueryDigestItem{Spec: spec, Description: "d", Query: "q", AddedCount: 3}
+	rs := recipients{
+		{spec: recipientSpec{userID: 1}, email: true},
+		{spec: recipientSpec{userID: 2}, email: true},
+		{spec: recipientSpec{userID: 3}, email: true},
+		{spec: recipientSpec{orgID: 9}, slack: true},
+	}
+	prefs := []*api.SavedQueryNotificationPreference{
+		{UserID: 1, Frequency: api.SavedQueryNotificationFrequencyImmediate},
+		{UserID: 2, Unsubscribed: true},
+	}
+
+	t.Run("immediate default", func(t *testing.T) {
+		immediate, items := splitDigestRecipients(api.ConfigSavedQuery{}, rs, prefs, item)
+		if want := (recipients{rs[0], rs[2], rs[3]}); !reflect.DeepEqual(immediate, want) {
+			t.Errorf("got immediate %v, want %v", immediate, want)
+		}
+		if len(items) != 0 {
+			t.Errorf("got %d items, want none", len(items))
+		}
+	})
+
+	t.Run("daily default", func(t *testing.T) {
+		immediate, items := splitDigestRecipients(api.ConfigSavedQuery{NotifyFrequency: api.SavedQueryNotificationFrequencyDaily}, rs, prefs, item)
+		if want := (recipients{rs[0]}); !reflect.DeepEqual(immediate, want) {
+			t.Errorf("got immediate %v, want %v", immediate, want)
+		}
+		withRecipient := func(userID, orgID int32, channel string) *api.SavedQueryDigestItem {
+			item := item
+			item.RecipientUserID, item.RecipientOrgID = userID, orgID
+			item.Channel = channel
+			item.Frequency = api.SavedQueryNotificationFrequencyDaily
+			return &item
+		}
+		want := []*api.SavedQueryDigestItem{
+			withRecipient(3, 0, digestChannelEmail),
+			withRecipient(0, 9, digestChannelSlack),
+		}
+		if !reflect.DeepEqual(items, want) {
+			t.Errorf("got items %+v, want %+v", items, want)
+		}
+	})
+}
+
+func TestDueDigests(t *testing.T) {
+	now := time.Date(2018, 6, 7, 12, 0, 0, 0, time.UTC)
+	items := []*api.SavedQueryDigestItem{
+		{ID: 1, RecipientUserID: 1, Channel: digestChannelEmail, Frequency: api.SavedQueryNotificationFrequencyHourly, CreatedAt: now.Add(-time.Hour)},
+		{ID: 2, RecipientUserID: 1, Channel: digestChannelEmail, Frequency: api.SavedQueryNotificationFrequencyDaily, CreatedAt: now.Add(-2 * time.Hour)},
+		{ID: 3, RecipientUserID: 2, Channel: digestChannelEmail, Frequency: api.SavedQueryNotificationFrequencyHourly, CreatedAt: now.Add(-30 * time.Minute)},
+		{ID: 4, RecipientUserID: 1, Channel: digestChannelEmail, Frequency: api.SavedQueryNotificationFrequencyHourly, CreatedAt: now.Add(-time.Minute)},
+		{ID: 5, RecipientOrgID: 1, Channel: digestChannelSlack, Frequency: api.SavedQueryNotificationFrequencyDaily, CreatedAt: now.Add(-25 * time.Hour)},
+	}
+	want := []*digest{
+		{recipient: recipientSpec{userID: 1}, channel: digestChannelEmail, frequency: api.SavedQueryNotificationFrequencyHourly, items: []*api.SavedQueryDigestItem{items[0], items[3]}},
+		{recipient: recipientSpec{orgID: 1}, channel: digestChannelSlack, frequency: api.SavedQueryNotificationFrequencyDaily, items: []*api.SavedQueryDigestItem{items[4]}},
+	}
+	if got := dueDigests(items, now); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestDigestEntries(t *testing.T) {
+	userID := int32(1)
+	a := api.SavedQueryIDSpec{Subject: api.SettingsSubject{User: &userID}, Key: "a"}
+	b := api.SavedQueryIDSpec{Subject: api.SettingsSubject{User: &userID}, Key: "b"}
+	items := []*api.SavedQueryDigestItem{
+		{Spec: b, Description: "b1", Query: "qb1", AddedCount: 1},
+		{Spec: a, Description: "a1", Query: "qa1", AddedCount: 2, RemovedCount: 1},
+		{Spec: b, Description: "b2", Query: "qb2", AddedCount: 3, RemovedCount: 4},
+	}
+	want := []*digestEntry{
+		{spec: b, description: "b2", query: "qb1", addedCount: 4, removedCount: 4},
+		{spec: a, description: "a1", query: "qa1", addedCount: 2, removedCount: 1},
+	}
+	if got := digestEntries(items); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
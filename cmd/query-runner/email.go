@@ -35,6 +35,10 @@ func (n *notifier) emailNotify(ctx context.Context) {
 		defer cancel()
 
 		for _, recipient := range n.recipients {
+			if !recipient.email {
+				continue
+			}
+
 			ownership := "the" // example: "new search results have been found for {{.Ownership}} saved search"
 			if n.spec.Subject.User != nil && *n.spec.Subject.User == recipient.spec.userID {
 				ownership = "your"
@@ -43,6 +47,13 @@ func (n *notifier) emailNotify(ctx context.Context) {
 				ownership = "your organization's"
 			}
 
+			if n.results == nil {
+				if err := n.emailNotifyContentChanges(ctx, recipient, ownership); err != nil {
+					log15.Error("Failed to send email notification for changed saved search results.", "userID", recipient.spec.userID, "error", err)
+				}
+				continue
+			}
+
 			plural := ""
 			if n.results.Data.Search.Results.ApproximateResultCount != "1" {
 				plural = "s"
@@ -86,6 +97,74 @@ View the new result{{.PluralResults}} on Sourcegraph: {{.URL}}
 `,
 })
 
+func (n *notifier) emailNotifyContentChanges(ctx context.Context, recipient *recipient, ownership string) error {
+	added, moreAdded := contentMatchLines(n.added)
+	removed, moreRemoved := contentMatchLines(n.removed)
+	return sendEmail(ctx, recipient.spec.userID, "results", changedSearchResultsEmailTemplates, struct {
+		URL           string
+		Description   string
+		Query         string
+		AddedCount    int
+		RemovedCount  int
+		Added         []string
+		Removed       []string
+		MoreAdded     int
+		MoreRemoved   int
+		Ownership     string
+		PluralResults string
+	}{
+		URL:           searchURL(n.newQuery, utmSourceEmail),
+		Description:   n.query.Description,
+		Query:         n.query.Query,
+		AddedCount:    len(n.added),
+		RemovedCount:  len(n.removed),
+		Added:         added,
+		Removed:       removed,
+		MoreAdded:     moreAdded,
+		MoreRemoved:   moreRemoved,
+		Ownership:     ownership,
+		PluralResults: pluralSuffix(len(n.added) + len(n.removed)),
+	})
+}
+
+var changedSearchResultsEmailTemplates = txemail.MustValidate(txtypes.Templates{
+	Subject: `[{{.AddedCount}} new, {{.RemovedCount}} removed result{{.PluralResults}}] {{.Description}}`,
+	Text: `
+{{.AddedCount}} new and {{.RemovedCount}} removed search result{{.PluralResults}} found for {{.Ownership}} saved search:
+
+  "{{.Description}}"
+{{if .Added}}
+New:{{range .Added}}
+  + {{.}}{{end}}{{if .MoreAdded}}
+  ...and {{.MoreAdded}} more{{end}}
+{{end}}{{if .Removed}}
+Removed:{{range .Removed}}
+  - {{.}}{{end}}{{if .MoreRemoved}}
+  ...and {{.MoreRemoved}} more{{end}}
+{{end}}
+View the current results on Sourcegraph: {{.URL}}
+`,
+	HTML: `
+<strong>{{.AddedCount}}</strong> new and <strong>{{.RemovedCount}}</strong> removed search result{{.PluralResults}} found for {{.Ownership}} saved search:
+
+<p style="padding-left: 16px">&quot;{{.Description}}&quot;</p>
+{{if .Added}}
+<p>New:</p>
+<ul>{{range .Added}}
+<li><code>{{.}}</code></li>{{end}}{{if .MoreAdded}}
+<li>...and {{.MoreAdded}} more</li>{{end}}
+</ul>
+{{end}}{{if .Removed}}
+<p>Removed:</p>
+<ul>{{range .Removed}}
+<li><code>{{.}}</code></li>{{end}}{{if .MoreRemoved}}
+<li>...and {{.MoreRemoved}} more</li>{{end}}
+</ul>
+{{end}}
+<p><a href="{{.URL}}">View the current results on Sourcegraph</a></p>
+`,
+})
+
 func emailNotifySubscribeUnsubscribe(ctx context.Context, recipient *recipient, query api.SavedQuerySpecAndConfig, template txtypes.Templates) error {
 	if !recipient.email {
 		return nil
@@ -35,6 +35,12 @@ const gqlSearchQuery = `query Search(
 				__typename
 				... on FileMatch {
 					resource
+					repository {
+						name
+					}
+					file {
+						path
+					}
 					limitHit
 					lineMatches {
 						preview
@@ -81,6 +87,7 @@ const gqlSearchQuery = `query Search(
 						}
 						oid
 						abbreviatedOID
+						url
 						author {
 							person {
 								displayName
@@ -113,6 +120,7 @@ type gqlSearchResponse struct {
 		Search struct {
 			Results struct {
 				ApproximateResultCount string
+				LimitHit               bool
 				Cloning                []*api.Repo
 				Timedout               []*api.Repo
 				Results                []interface{}
@@ -197,3 +205,38 @@ func extractTime(result interface{}) (t *time.Time, err error) {
 		return nil, fmt.Errorf("unexpected result __typename %q", typeName)
 	}
 }
+
+// commitResult is the commit of a commit search result.
+type commitResult struct {
+	Repository struct {
+		Name api.RepoName
+	}
+	OID     string
+	URL     string
+	Message string
+}
+
+// extractCommitResults returns the commits of the commit search results.
+func extractCommitResults(results []interface{}) ([]*commitResult, error) {
+	var commits []*commitResult
+	for _, result := range results {
+		// Round-trip the result through JSON instead of asserting on the
+		// structure of the untyped result.
+		b, err := json.Marshal(result)
+		if err != nil {
+			return nil, errors.Wrap(err, "Marshal")
+		}
+		var commitSearchResult struct {
+			Typename string `json:"__typename"`
+			Commit   *commitResult
+		}
+		if err := json.Unmarshal(b, &commitSearchResult); err != nil {
+			return nil, errors.Wrap(err, "Unmarshal")
+		}
+		if commitSearchResult.Typename != "CommitSearchResult" || commitSearchResult.Commit == nil {
+			continue
+		}
+		commits = append(commits, commitSearchResult.Commit)
+	}
+	return commits, nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	log15 "gopkg.in/inconshreveable/log15.v2"
+
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+)
+
+// maxRunNewResults is the maximum number of new results recorded in a saved
+// query's history for each run.
+const maxRunNewResults = 10
+
+// logRun records a run of the saved query in its history. If err is non-nil,
+// the run is recorded as failed (if the search itself didn't fail). Failures
+// to record the run are only logged, because the history is informational.
+func logRun(ctx context.Context, run *api.SavedQueryRun, err error) {
+	if err != nil && run.Error == "" {
+		run.Error = err.Error()
+	}
+	if len(run.NewResults) > maxRunNewResults {
+		run.NewResults = run.NewResults[:maxRunNewResults]
+	}
+	if err := api.InternalClient.SavedQueriesLogRun(ctx, run); err != nil {
+		log15.Warn("executor: failed to record saved query run", "error", err)
+	}
+}
+
+// newRun returns the record of a run of the query that started at start,
+// with the search results v (or searchErr if the search failed). The caller
+// fills in the result counts and new results.
+func newRun(query string, start time.Time, execDuration time.Duration, v *gqlSearchResponse, searchErr error) *api.SavedQueryRun {
+	run := &api.SavedQueryRun{
+		Query:        query,
+		ExecutedAt:   start,
+		ExecDuration: execDuration,
+	}
+	if searchErr != nil {
+		run.Error = searchErr.Error()
+	}
+	if v != nil {
+		run.LimitHit = v.Data.Search.Results.LimitHit
+	}
+	return run
+}
+
+// commitRunResults returns the history records of the new commit search
+// results.
+func commitRunResults(results []interface{}) ([]*api.SavedQueryRunResult, error) {
+	commits, err := extractCommitResults(results)
+	if err != nil {
+		return nil, err
+	}
+	runResults := make([]*api.SavedQueryRunResult, len(commits))
+	for i, c := range commits {
+		subject := c.Message
+		if n := strings.Index(subject, "\n"); n != -1 {
+			subject = subject[:n]
+		}
+		runResults[i] = &api.SavedQueryRunResult{
+			Repo:    c.Repository.Name,
+			Commit:  api.CommitID(c.OID),
+			Preview: subject,
+		}
+	}
+	return runResults, nil
+}
+
+// contentRunResults returns the history records of the new matches of a
+// search on file contents.
+func contentRunResults(matches []*api.SavedQueryMatch) []*api.SavedQueryRunResult {
+	runResults := make([]*api.SavedQueryRunResult, len(matches))
+	for i, m := range matches {
+		runResults[i] = &api.SavedQueryRunResult{
+			Repo:    m.Repo,
+			Path:    m.Path,
+			Line:    m.Line,
+			Preview: m.Preview,
+		}
+	}
+	return runResults
+}
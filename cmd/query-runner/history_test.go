@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+)
+
+func TestCommitRunResults(t *testing.T) {
+	var results []interface{}
+	if err := json.Unmarshal([]byte(`[
+		{
+			"__typename": "CommitSearchResult",
+			"commit": {
+				"repository": {"name": "github.com/a/b"},
+				"oid": "1111111111111111111111111111111111111111",
+				"url": "/github.com/a/b/-/commit/1111111111111111111111111111111111111111",
+				"message": "Remove deprecated API\n\nAll callers were migrated."
+			}
+		},
+		{
+			"__typename": "FileMatch"
+		}
+	]`), &results); err != nil {
+		t.Fatal(err)
+	}
+
+	runResults, err := commitRunResults(results)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*api.SavedQueryRunResult{
+		{Repo: "github.com/a/b", Commit: "1111111111111111111111111111111111111111", Preview: "Remove deprecated API"},
+	}
+	if !reflect.DeepEqual(runResults, want) {
+		t.Errorf("got %+v, want %+v", runResults, want)
+	}
+}
+
+func TestRunQuery_logsRunWhenSetInfoFails(t *testing.T) {
+	var runs []*api.SavedQueryRun
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.internal/saved-queries/get-info":
+			w.Write([]byte("null"))
+		case "/.internal/graphql":
+			w.Write([]byte(`{"data": {"search": {"results": {"results": [{
+				"__typename": "CommitSearchResult",
+				"commit": {"repository": {"name": "github.com/a/b"}, "oid": "1111111111111111111111111111111111111111", "message": "m"}
+			}]}}}}`))
+		case "/.internal/saved-queries/set-info":
+			http.Error(w, "database is down", http.StatusInternalServerError)
+		case "/.internal/saved-queries/log-run":
+			var run *api.SavedQueryRun
+			if err := json.NewDecoder(r.Body).Decode(&run); err != nil {
+				t.Error(err)
+				return
+			}
+			runs = append(runs, run)
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+	origURL := api.InternalClient.URL
+	api.InternalClient.URL = ts.URL
+	defer func() { api.InternalClient.URL = origURL }()
+
+	e := &executorT{}
+	query := api.ConfigSavedQuery{Query: "type:commit m", Notify: true}
+	if err := e.runQuery(context.Background(), api.SavedQueryIDSpec{Key: "k"}, query); err == nil {
+		t.Fatal("got nil error, want the SavedQueriesSetInfo error")
+	}
+	if len(runs) != 1 {
+		t.Fatalf("got %d runs logged, want 1", len(runs))
+	}
+	if runs[0].Query != query.Query || runs[0].Error == "" {
+		t.Errorf("got run %+v, want a failed run of the query", runs[0])
+	}
+}
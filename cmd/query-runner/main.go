@@ -11,22 +11,24 @@ import (
 	"os"
 	"os/signal"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/pkg/errors"
 	log15 "gopkg.in/inconshreveable/log15.v2"
 
-	"github.com/sourcegraph/sourcegraph/cmd/query-runner/queryrunnerapi"
 	"github.com/sourcegraph/sourcegraph/pkg/api"
 	"github.com/sourcegraph/sourcegraph/pkg/debugserver"
 	"github.com/sourcegraph/sourcegraph/pkg/env"
 	"github.com/sourcegraph/sourcegraph/pkg/eventlogger"
+	"github.com/sourcegraph/sourcegraph/pkg/queryrunnerapi"
 	"github.com/sourcegraph/sourcegraph/pkg/tracer"
 )
 
 var (
 	forceRunInterval = env.Get("FORCE_RUN_INTERVAL", "", "Force an interval to run saved queries at, instead of assuming query execution time * 30 (query that takes 2s to run, runs every 60s)")
+	pollInterval     = env.Get("POLL_INTERVAL", "1h", "Minimum interval to run saved queries at when they are also run on repository updates reported by gitserver (as a fallback for missed updates)")
 )
 
 const port = "3183"
@@ -49,6 +51,7 @@ func main() {
 	http.HandleFunc(queryrunnerapi.PathSavedQueryWasCreatedOrUpdated, serveSavedQueryWasCreatedOrUpdated)
 	http.HandleFunc(queryrunnerapi.PathSavedQueryWasDeleted, serveSavedQueryWasDeleted)
 	http.HandleFunc(queryrunnerapi.PathTestNotification, serveTestNotification)
+	http.HandleFunc(queryrunnerapi.PathRepoUpdated, serveRepoUpdated)
 
 	ctx := context.Background()
 
@@ -58,6 +61,7 @@ func main() {
 			log15.Error("executor: failed to run due to error", "error", err)
 		}
 	}()
+	go runDigests(ctx)
 
 	host := ""
 	if env.InsecureDev {
@@ -86,6 +90,19 @@ var executor = &executorT{}
 
 type executorT struct {
 	forceRunInterval *time.Duration
+	pollInterval     time.Duration
+
+	// mu is held while running a saved query, so that saved queries run one
+	// at a time (to avoid overloading searcher/gitserver) and so that
+	// polling and repository update runs of the same saved query don't race
+	// on its info and snapshot.
+	mu sync.Mutex
+
+	// notifiedCommits holds, for each saved query, the commits that runs on
+	// repository updates already sent notifications about. Polling only
+	// tracks a single latest result time across all repositories, so the
+	// next poll of the saved query skips these commits (and forgets them).
+	notifiedCommits map[string]map[repoCommit]bool
 }
 
 func (e *executorT) run(ctx context.Context) error {
@@ -99,19 +116,30 @@ func (e *executorT) run(ctx context.Context) error {
 		e.forceRunInterval = &forceRunInterval
 	}
 
+	// Parse POLL_INTERVAL value.
+	var err error
+	e.pollInterval, err = time.ParseDuration(pollInterval)
+	if err != nil {
+		log15.Error("executor: failed to parse POLL_INTERVAL", "error", err)
+		return nil
+	}
+
 	// Kick off fetching of the full list of saved queries from the frontend.
 	// Important to do this early on in case we get created/updated/deleted
 	// notifications for saved queries.
 	allSavedQueries.fetchInitialListFromFrontend()
 
-	// TODO(slimsag): Make gitserver notify us about repositories being updated
-	// as we could avoid executing queries if repositories haven't updated
-	// (impossible for new results to exist).
+	// Saved queries are run when gitserver reports that a repository they
+	// apply to was updated. They are also polled, for saved queries that
+	// can't be run on repository updates and in case updates are missed.
+	go e.runRepoUpdates(ctx)
 	for {
 		allSavedQueries := allSavedQueries.get()
 		start := time.Now()
 		for _, query := range allSavedQueries {
+			e.mu.Lock()
 			err := e.runQuery(ctx, query.Spec, query.Config)
+			e.mu.Unlock()
 			if err != nil {
 				log15.Error("executor: failed to run query", "error", err, "query_description", query.Config.Description)
 			}
@@ -129,17 +157,11 @@ func (e *executorT) run(ctx context.Context) error {
 
 // runQuery runs the given query if an appropriate amount of time has elapsed
 // since it last ran.
-func (e *executorT) runQuery(ctx context.Context, spec api.SavedQueryIDSpec, query api.ConfigSavedQuery) error {
-	if !query.Notify && !query.NotifySlack {
+func (e *executorT) runQuery(ctx context.Context, spec api.SavedQueryIDSpec, query api.ConfigSavedQuery) (err error) {
+	if !query.Notify && !query.NotifySlack && query.NotifyWebhook == nil && query.Monitor == nil {
 		// No need to run this query because there will be nobody to notify.
 		return nil
 	}
-	if !strings.Contains(query.Query, "type:diff") && !strings.Contains(query.Query, "type:commit") {
-		// TODO(slimsag): we temporarily do not support non-commit search
-		// queries, since those do not support the after:"time" operator.
-		return nil
-	}
-
 	info, err := api.InternalClient.SavedQueriesGetInfo(ctx, query.Query)
 	if err != nil {
 		return errors.Wrap(err, "SavedQueriesGetInfo")
@@ -159,6 +181,11 @@ func (e *executorT) runQuery(ctx context.Context, spec api.SavedQueryIDSpec, que
 		if runInterval < 10*time.Second {
 			runInterval = 10 * time.Second
 		}
+		// Saved queries that are run on repository updates only need to be
+		// polled occasionally.
+		if _, ok := repoUpdateScope(query); ok && runInterval < e.pollInterval {
+			runInterval = e.pollInterval
+		}
 		if e.forceRunInterval != nil {
 			runInterval = *e.forceRunInterval
 		}
@@ -167,6 +194,10 @@ func (e *executorT) runQuery(ctx context.Context, spec api.SavedQueryIDSpec, que
 		}
 	}
 
+	if !isCommitQuery(query.Query) {
+		return e.runContentQuery(ctx, spec, query, info)
+	}
+
 	// Construct a new query which finds search results introduced after the
 	// last time we queried.
 	var latestKnownResult time.Time
@@ -189,7 +220,15 @@ func (e *executorT) runQuery(ctx context.Context, spec api.SavedQueryIDSpec, que
 	// fails in order to avoid e.g. failed saved queries from executing
 	// constantly and potentially causing harm to the system. We'll retry at
 	// our normal interval, regardless of errors.
+	start := time.Now()
 	v, execDuration, searchErr := performSearch(ctx, newQuery)
+
+	// Record the run in the saved query's history when we're done, after the
+	// monitor's trigger condition (which compares against the previous run)
+	// has been checked.
+	run := newRun(query.Query, start, execDuration, v, searchErr)
+	defer func() { logRun(ctx, run, err) }()
+
 	if err := api.InternalClient.SavedQueriesSetInfo(ctx, &api.SavedQueryInfo{
 		Query:        query.Query,
 		LastExecuted: time.Now(),
@@ -198,18 +237,35 @@ func (e *executorT) runQuery(ctx context.Context, spec api.SavedQueryIDSpec, que
 	}); err != nil {
 		return errors.Wrap(err, "SavedQueriesSetInfo")
 	}
-
 	if searchErr != nil {
 		return searchErr
 	}
 
+	e.skipNotifiedCommits(query.Query, v)
+	run.NewResultCount = len(v.Data.Search.Results.Results)
+	if run.NewResults, err = commitRunResults(v.Data.Search.Results.Results); err != nil {
+		log15.Warn("executor: failed to extract new results of saved query run", "error", err)
+	}
+	newResults := run.NewResults
+
+	// Saved queries with a monitor only send notifications when the monitor's
+	// trigger condition is met.
+	notifyQuery, reason, triggered := query, "", false
+	if run.NewResultCount > 0 {
+		notifyQuery, reason, triggered = checkMonitor(ctx, query, run)
+	}
+	if !triggered {
+		return nil
+	}
+
 	// Send notifications for new search results in a separate goroutine, so
 	// that we don't block other search queries from running in sequence (which
 	// is done intentionally, to ensure no overloading of searcher/gitserver).
 	go func() {
-		if err := notify(context.Background(), spec, query, newQuery, v); err != nil {
+		if err := notify(context.Background(), spec, notifyQuery, newQuery, v); err != nil {
 			log15.Error("executor: failed to send notifications", "error", err)
 		}
+		monitorOpenDiscussions(context.Background(), spec, query, reason, newResults)
 	}()
 	return nil
 }
@@ -289,9 +345,15 @@ func notify(ctx context.Context, spec api.SavedQueryIDSpec, query api.ConfigSave
 		recipients: recipients,
 	}
 
-	// Send Slack and email notifications.
+	// Queue the notifications of recipients who receive digests.
+	if err := n.holdForDigests(ctx); err != nil {
+		return err
+	}
+
+	// Send Slack, email and webhook notifications.
 	n.slackNotify(ctx)
 	n.emailNotify(ctx)
+	n.webhookNotify(ctx)
 	return nil
 }
 
@@ -301,6 +363,10 @@ type notifier struct {
 	newQuery   string
 	results    *gqlSearchResponse
 	recipients recipients
+
+	// added and removed are set instead of results for saved queries on file
+	// contents.
+	added, removed []*api.SavedQueryMatch
 }
 
 const (
@@ -308,23 +374,32 @@ const (
 	utmSourceSlack = "saved-search-slack"
 )
 
-func searchURL(query, utmSource string) string {
+// resolveExternalURL resolves the reference against the external URL of the
+// Sourcegraph instance. It returns nil if the external URL can't be
+// determined.
+func resolveExternalURL(ref *url.URL) *url.URL {
 	if externalURL == nil {
 		// Determine the external URL.
 		externalURLStr, err := api.InternalClient.ExternalURL(context.Background())
 		if err != nil {
 			log15.Error("failed to get ExternalURL", err)
-			return ""
+			return nil
 		}
 		externalURL, err = url.Parse(externalURLStr)
 		if err != nil {
 			log15.Error("failed to parse ExternalURL", err)
-			return ""
+			return nil
 		}
 	}
+	return externalURL.ResolveReference(ref)
+}
 
+func searchURL(query, utmSource string) string {
 	// Construct URL to the search query.
-	u := externalURL.ResolveReference(&url.URL{Path: "search"})
+	u := resolveExternalURL(&url.URL{Path: "search"})
+	if u == nil {
+		return ""
+	}
 	q := u.Query()
 	q.Set("q", query)
 	q.Set("utm_source", utmSource)
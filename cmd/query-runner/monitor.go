@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+
+	log15 "gopkg.in/inconshreveable/log15.v2"
+
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+// Saved query monitor actions (the values of the "actions" property of a
+// saved query's monitor in settings).
+const (
+	monitorActionEmail      = "email"
+	monitorActionSlack      = "slack"
+	monitorActionWebhook    = "webhook"
+	monitorActionDiscussion = "discussion"
+)
+
+const utmSourceMonitor = "saved-search-monitor"
+
+// maxMonitorDiscussionThreads is the maximum number of discussion threads that
+// a monitor's "discussion" action opens for a single run.
+const maxMonitorDiscussionThreads = 5
+
+// checkMonitor reports whether notifications should be sent for a run of the
+// saved query that found changed results. Saved queries without a monitor
+// always send notifications.
+//
+// It returns the saved query whose notification settings reflect the
+// monitor's actions, and a description of the trigger condition that was met.
+// It must be called before the run is recorded in the saved query's history,
+// because the trigger conditions compare against the previous run.
+func checkMonitor(ctx context.Context, query api.ConfigSavedQuery, run *api.SavedQueryRun) (notifyQuery api.ConfigSavedQuery, reason string, triggered bool) {
+	if query.Monitor == nil {
+		return query, "", true
+	}
+
+	var prevCount *int
+	runs, err := api.InternalClient.SavedQueriesListRuns(ctx, query.Query, 1)
+	if err != nil {
+		log15.Warn("executor: failed to get previous run of saved query monitor", "query_description", query.Description, "error", err)
+	} else if len(runs) > 0 && runs[0].Error == "" {
+		n := monitorCount(runs[0])
+		prevCount = &n
+	}
+
+	newRepos := make([]api.RepoName, len(run.NewResults))
+	for i, r := range run.NewResults {
+		newRepos[i] = r.Repo
+	}
+	reason, triggered = monitorTriggered(query.Monitor.Trigger, monitorCount(run), prevCount, newRepos)
+	if !triggered {
+		return query, "", false
+	}
+	log15.Info("saved query monitor triggered", "query_description", query.Description, "reason", reason)
+	return monitorNotifyQuery(query), reason, true
+}
+
+// monitorCount returns the number of results of the run that monitor trigger
+// conditions apply to: the total number of matches if it is known, and
+// otherwise (for diff and commit searches) the number of new results.
+func monitorCount(run *api.SavedQueryRun) int {
+	if run.ResultCount != nil {
+		return *run.ResultCount
+	}
+	return run.NewResultCount
+}
+
+// monitorTriggered reports whether any of the trigger's conditions are met by a
+// run with count results (and prevCount results on the previous run, if known)
+// whose new results are in newRepos. It returns a description of the condition
+// that was met.
+func monitorTriggered(trigger *schema.SavedQueryMonitorTrigger, count int, prevCount *int, newRepos []api.RepoName) (reason string, triggered bool) {
+	if trigger == nil {
+		return "", false
+	}
+
+	if above := trigger.ResultCountAbove; above != nil && count > *above && (prevCount == nil || *prevCount <= *above) {
+		return fmt.Sprintf("the number of results (%d) rose above %d", count, *above), true
+	}
+
+	if trigger.ResultInRepo != "" {
+		// Like repo: in search queries, the pattern is case-insensitive.
+		re, err := regexp.Compile("(?i)" + trigger.ResultInRepo)
+		if err != nil {
+			log15.Warn("executor: invalid resultInRepo pattern in saved query monitor", "pattern", trigger.ResultInRepo, "error", err)
+		} else {
+			for _, repo := range newRepos {
+				if re.MatchString(string(repo)) {
+					return fmt.Sprintf("a new result is in %s", repo), true
+				}
+			}
+		}
+	}
+
+	if percent := trigger.ResultCountChangePercent; percent > 0 && prevCount != nil && count != *prevCount {
+		if *prevCount == 0 || math.Abs(float64(count-*prevCount))*100/float64(*prevCount) > percent {
+			return fmt.Sprintf("the number of results changed from %d to %d", *prevCount, count), true
+		}
+	}
+
+	return "", false
+}
+
+// monitorNotifyQuery returns the saved query with its notification settings
+// replaced by those of the monitor's actions.
+func monitorNotifyQuery(query api.ConfigSavedQuery) api.ConfigSavedQuery {
+	query.Notify = hasMonitorAction(query.Monitor, monitorActionEmail)
+	query.NotifySlack = hasMonitorAction(query.Monitor, monitorActionSlack)
+	if !hasMonitorAction(query.Monitor, monitorActionWebhook) {
+		query.NotifyWebhook = nil
+	}
+	return query
+}
+
+func hasMonitorAction(monitor *schema.SavedQueryMonitor, action string) bool {
+	for _, a := range monitor.Actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// monitorOpenDiscussions performs the monitor's "discussion" action (if any)
+// by opening a discussion thread on each file (or, for commit searches,
+// repository) with new results. The threads are authored by the user whose
+// settings define the saved query; the action is not supported for saved
+// queries in organization or global settings.
+func monitorOpenDiscussions(ctx context.Context, spec api.SavedQueryIDSpec, query api.ConfigSavedQuery, reason string, newResults []*api.SavedQueryRunResult) {
+	if query.Monitor == nil || !hasMonitorAction(query.Monitor, monitorActionDiscussion) {
+		return
+	}
+	if spec.Subject.User == nil {
+		log15.Warn("executor: the discussion action of saved query monitors is only supported in user settings", "query_description", query.Description)
+		return
+	}
+
+	opened := map[string]bool{}
+	for _, r := range newResults {
+		if len(opened) >= maxMonitorDiscussionThreads {
+			break
+		}
+		key := fmt.Sprintf("%s\x00%s\x00%s", r.Repo, r.Path, r.Commit)
+		if opened[key] {
+			continue // only one thread per file or commit
+		}
+		opened[key] = true
+
+		if _, err := api.InternalClient.DiscussionsCreateThread(ctx, monitorDiscussionThread(*spec.Subject.User, query, reason, r)); err != nil {
+			log15.Error("executor: failed to open discussion thread for saved query monitor", "query_description", query.Description, "repo", r.Repo, "error", err)
+			continue
+		}
+		logEvent("", "SavedSearchMonitorDiscussionOpened", "results")
+	}
+}
+
+// monitorDiscussionThread returns the discussion thread to open about the new
+// result.
+func monitorDiscussionThread(authorUserID int32, query api.ConfigSavedQuery, reason string, r *api.SavedQueryRunResult) *api.DiscussionsCreateThreadArgs {
+	subject := string(r.Repo)
+	switch {
+	case r.Path != "":
+		subject = r.Path
+	case r.Commit != "":
+		subject = fmt.Sprintf("%s@%.7s", r.Repo, r.Commit)
+	}
+	contents := fmt.Sprintf("The saved search monitor [%s](%s) was triggered because %s.", query.Description, searchURL(query.Query, utmSourceMonitor), reason)
+	if r.Commit != "" {
+		contents += fmt.Sprintf("\n\nNew commit: [%.7s](%s) %s", r.Commit, absoluteURL(fmt.Sprintf("/%s/-/commit/%s", r.Repo, r.Commit)), r.Preview)
+	}
+	return &api.DiscussionsCreateThreadArgs{
+		AuthorUserID: authorUserID,
+		Title:        fmt.Sprintf("%s: %s", query.Description, subject),
+		Contents:     contents,
+		Repo:         r.Repo,
+		Path:         r.Path,
+		Line:         r.Line,
+		Preview:      r.Preview,
+	}
+}
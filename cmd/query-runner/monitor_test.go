@@ -0,0 +1,114 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestMonitorTriggered(t *testing.T) {
+	intPtr := func(i int) *int { return &i }
+
+	tests := map[string]struct {
+		trigger   schema.SavedQueryMonitorTrigger
+		count     int
+		prevCount *int
+		newRepos  []api.RepoName
+		want      bool
+	}{
+		"count rose above threshold": {
+			trigger:   schema.SavedQueryMonitorTrigger{ResultCountAbove: intPtr(10)},
+			count:     11,
+			prevCount: intPtr(10),
+			want:      true,
+		},
+		"count already above threshold": {
+			trigger:   schema.SavedQueryMonitorTrigger{ResultCountAbove: intPtr(10)},
+			count:     12,
+			prevCount: intPtr(11),
+		},
+		"count above threshold on first run": {
+			trigger: schema.SavedQueryMonitorTrigger{ResultCountAbove: intPtr(0)},
+			count:   1,
+			want:    true,
+		},
+		"count not above threshold": {
+			trigger:   schema.SavedQueryMonitorTrigger{ResultCountAbove: intPtr(10)},
+			count:     10,
+			prevCount: intPtr(3),
+		},
+		"result in repo": {
+			trigger:  schema.SavedQueryMonitorTrigger{ResultInRepo: "^github\\.com/critical/"},
+			newRepos: []api.RepoName{"github.com/other/a", "github.com/Critical/b"},
+			want:     true,
+		},
+		"no result in repo": {
+			trigger:  schema.SavedQueryMonitorTrigger{ResultInRepo: "^github\\.com/critical/"},
+			newRepos: []api.RepoName{"github.com/other/critical"},
+		},
+		"count changed by more than percent": {
+			trigger:   schema.SavedQueryMonitorTrigger{ResultCountChangePercent: 20},
+			count:     75,
+			prevCount: intPtr(100),
+			want:      true,
+		},
+		"count changed by less than percent": {
+			trigger:   schema.SavedQueryMonitorTrigger{ResultCountChangePercent: 20},
+			count:     110,
+			prevCount: intPtr(100),
+		},
+		"count changed from zero": {
+			trigger:   schema.SavedQueryMonitorTrigger{ResultCountChangePercent: 20},
+			count:     1,
+			prevCount: intPtr(0),
+			want:      true,
+		},
+		"count change without previous run": {
+			trigger: schema.SavedQueryMonitorTrigger{ResultCountChangePercent: 20},
+			count:   100,
+		},
+		"any condition": {
+			trigger:   schema.SavedQueryMonitorTrigger{ResultCountAbove: intPtr(100), ResultCountChangePercent: 20},
+			count:     50,
+			prevCount: intPtr(10),
+			want:      true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			reason, triggered := monitorTriggered(&test.trigger, test.count, test.prevCount, test.newRepos)
+			if triggered != test.want {
+				t.Errorf("got triggered %v (reason %q), want %v", triggered, reason, test.want)
+			}
+		})
+	}
+}
+
+func TestMonitorNotifyQuery(t *testing.T) {
+	webhook := &schema.SavedQueryWebhook{Url: "https://example.com"}
+	query := api.ConfigSavedQuery{
+		Notify:        true,
+		NotifyWebhook: webhook,
+		Monitor:       &schema.SavedQueryMonitor{Actions: []string{"slack", "discussion"}},
+	}
+	got := monitorNotifyQuery(query)
+	if got.Notify || !got.NotifySlack || got.NotifyWebhook != nil {
+		t.Errorf("got notify %v, notifySlack %v, notifyWebhook %v; want only notifySlack", got.Notify, got.NotifySlack, got.NotifyWebhook)
+	}
+
+	query.Monitor.Actions = []string{"webhook"}
+	if got := monitorNotifyQuery(query); got.NotifyWebhook != webhook {
+		t.Errorf("got notifyWebhook %v, want %v", got.NotifyWebhook, webhook)
+	}
+}
+
+func TestMonitorCount(t *testing.T) {
+	resultCount := 7
+	if got := monitorCount(&api.SavedQueryRun{ResultCount: &resultCount, NewResultCount: 2}); got != 7 {
+		t.Errorf("with a result count: got %d, want 7", got)
+	}
+	if got := monitorCount(&api.SavedQueryRun{NewResultCount: 2}); got != 2 {
+		t.Errorf("without a result count: got %d, want 2", got)
+	}
+}
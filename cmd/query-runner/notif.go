@@ -54,17 +54,21 @@ func getNotificationRecipients(ctx context.Context, spec api.SavedQueryIDSpec, q
 		})
 
 	case spec.Subject.Org != nil:
-		if query.Notify {
-			// Email all org members.
+		// Members' subscriptions (opting in or muting) and channel preferences
+		// override the saved query's Notify setting.
+		prefs, err := api.InternalClient.SavedQueriesGetNotificationPreferences(ctx, spec)
+		if err != nil {
+			return nil, err
+		}
+		if query.Notify || anySubscribed(prefs) {
 			orgMembers, err := api.InternalClient.OrgsListUsers(ctx, *spec.Subject.Org)
 			if err != nil {
 				return nil, err
 			}
 			for _, userID := range orgMembers {
-				recipients.add(recipient{
-					spec:  recipientSpec{userID: userID},
-					email: true,
-				})
+				if r := orgMemberRecipient(userID, query, prefs); r != nil {
+					recipients.add(*r)
+				}
 			}
 		}
 
@@ -77,6 +81,52 @@ func getNotificationRecipients(ctx context.Context, spec api.SavedQueryIDSpec, q
 	return recipients, nil
 }
 
+// orgMemberRecipient returns the recipient for a member of the organization
+// that owns the saved query, or nil if the member isn't notified. Members are
+// notified by email if the saved query notifies all members (query.Notify) or
+// if they subscribed to it, unless they muted it or set other channel
+// preferences.
+func orgMemberRecipient(userID int32, query api.ConfigSavedQuery, prefs []*api.SavedQueryNotificationPreference) *recipient {
+	var pref *api.SavedQueryNotificationPreference
+	for _, p := range prefs {
+		if p.UserID == userID {
+			pref = p
+			break
+		}
+	}
+
+	r := recipient{spec: recipientSpec{userID: userID}, email: true}
+	if pref != nil {
+		if pref.Unsubscribed {
+			return nil
+		}
+		if !query.Notify && !pref.Subscribed {
+			return nil
+		}
+		if pref.Email != nil {
+			r.email = *pref.Email
+		}
+		if pref.Slack != nil {
+			r.slack = *pref.Slack
+		}
+	} else if !query.Notify {
+		return nil
+	}
+	if !r.email && !r.slack {
+		return nil
+	}
+	return &r
+}
+
+func anySubscribed(prefs []*api.SavedQueryNotificationPreference) bool {
+	for _, p := range prefs {
+		if p.Subscribed && !p.Unsubscribed {
+			return true
+		}
+	}
+	return false
+}
+
 type recipients []*recipient
 
 // add adds the new recipient, merging it into an existing slice element if one already exists for
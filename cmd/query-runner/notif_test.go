@@ -40,6 +40,10 @@ func TestGetNotificationRecipients(t *testing.T) {
 			return []int32{1, 2, 3}, nil
 		}
 		defer func() { api.MockOrgsListUsers = nil }()
+		api.MockSavedQueriesGetNotificationPreferences = func(spec api.SavedQueryIDSpec) ([]*api.SavedQueryNotificationPreference, error) {
+			return nil, nil
+		}
+		defer func() { api.MockSavedQueriesGetNotificationPreferences = nil }()
 		recipients, err := getNotificationRecipients(ctx,
 			api.SavedQueryIDSpec{
 				Subject: api.SettingsSubject{Org: &onetwothree},
@@ -61,6 +65,46 @@ func TestGetNotificationRecipients(t *testing.T) {
 			t.Errorf("got %v, want %v", recipients, want)
 		}
 	})
+
+	t.Run("org member subscriptions", func(t *testing.T) {
+		api.MockOrgsListUsers = func(orgID int32) (users []int32, err error) {
+			return []int32{1, 2, 3, 4}, nil
+		}
+		defer func() { api.MockOrgsListUsers = nil }()
+		yes, no := true, false
+		api.MockSavedQueriesGetNotificationPreferences = func(spec api.SavedQueryIDSpec) ([]*api.SavedQueryNotificationPreference, error) {
+			return []*api.SavedQueryNotificationPreference{
+				{UserID: 1, Subscribed: true},
+				{UserID: 2, Subscribed: true, Email: &no, Slack: &yes},
+				{UserID: 3, Unsubscribed: true},
+				{UserID: 5, Subscribed: true}, // not a member
+			}, nil
+		}
+		defer func() { api.MockSavedQueriesGetNotificationPreferences = nil }()
+
+		for _, notify := range []bool{false, true} {
+			recipients, err := getNotificationRecipients(ctx,
+				api.SavedQueryIDSpec{
+					Subject: api.SettingsSubject{Org: &onetwothree},
+				},
+				api.ConfigSavedQuery{Notify: notify},
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := []*recipient{
+				{spec: recipientSpec{userID: 1}, email: true},
+				{spec: recipientSpec{userID: 2}, slack: true},
+			}
+			if notify {
+				want = append(want, &recipient{spec: recipientSpec{userID: 4}, email: true})
+			}
+			want = append(want, &recipient{spec: recipientSpec{orgID: 123}})
+			if !reflect.DeepEqual(recipients, want) {
+				t.Errorf("notify=%v: got %v, want %v", notify, recipients, want)
+			}
+		}
+	})
 }
 
 func TestDiffNotificationRecipients(t *testing.T) {
@@ -0,0 +1,312 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	log15 "gopkg.in/inconshreveable/log15.v2"
+
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/queryrunnerapi"
+)
+
+// repoUpdates is the queue of repository updates reported by gitserver that
+// have not yet been processed by the executor.
+var repoUpdates = make(chan *queryrunnerapi.RepoUpdatedArgs, 1000)
+
+func serveRepoUpdated(w http.ResponseWriter, r *http.Request) {
+	var args *queryrunnerapi.RepoUpdatedArgs
+	if err := json.NewDecoder(r.Body).Decode(&args); err != nil {
+		writeError(w, errors.Wrap(err, "decoding JSON arguments"))
+		return
+	}
+
+	select {
+	case repoUpdates <- args:
+	default:
+		// The update isn't lost entirely: saved queries are still polled.
+		log15.Warn("executor: dropping repository update because the queue is full", "repo", args.Repo)
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// runRepoUpdates runs the saved queries that apply to each updated repository
+// on just the new commits of that repository.
+func (e *executorT) runRepoUpdates(ctx context.Context) {
+	for update := range repoUpdates {
+		for _, query := range allSavedQueries.get() {
+			scope, ok := repoUpdateScope(query.Config)
+			if !ok || !scope.matches(update.Repo) {
+				continue
+			}
+			e.mu.Lock()
+			err := e.runQueryForRepoUpdate(ctx, query.Spec, query.Config, update)
+			e.mu.Unlock()
+			if err != nil {
+				log15.Error("executor: failed to run query on repository update", "error", err, "query_description", query.Config.Description, "repo", update.Repo)
+			}
+		}
+	}
+}
+
+// runQueryForRepoUpdate runs the saved query on the repository's commits
+// between update.OldCommit and update.NewCommit (for commit queries), or on
+// update.NewCommit (for queries on file contents).
+//
+// Saved queries that have never been run are skipped; the regular polling of
+// saved queries establishes what results already exist.
+func (e *executorT) runQueryForRepoUpdate(ctx context.Context, spec api.SavedQueryIDSpec, query api.ConfigSavedQuery, update *queryrunnerapi.RepoUpdatedArgs) (err error) {
+	if !query.Notify && !query.NotifySlack && query.NotifyWebhook == nil {
+		// No need to run this query because there will be nobody to notify.
+		return nil
+	}
+
+	if !isCommitQuery(query.Query) {
+		snapshot, err := api.InternalClient.SavedQueriesGetSnapshot(ctx, query.Query)
+		if err != nil {
+			return errors.Wrap(err, "SavedQueriesGetSnapshot")
+		}
+		if snapshot == nil {
+			return nil
+		}
+
+		newQuery := fmt.Sprintf("%s %s", query.Query, repoRevFilter(update.Repo, string(update.NewCommit)))
+		if !strings.Contains(newQuery, "count:") {
+			newQuery = fmt.Sprintf("%s count:%d", newQuery, maxContentMatches)
+		}
+		start := time.Now()
+		v, execDuration, err := performSearch(ctx, newQuery)
+		run := newRun(query.Query, start, execDuration, v, err)
+		defer func() { logRun(ctx, run, err) }()
+		if err != nil {
+			return err
+		}
+		if incompleteResults(v) {
+			return nil
+		}
+		matches, err := extractContentMatches(v.Data.Search.Results.Results)
+		if err != nil {
+			return err
+		}
+		added, removed, err := updateContentSnapshot(ctx, query, snapshot, &update.Repo, matches)
+		if err != nil {
+			return err
+		}
+		run.NewResultCount = len(added)
+		run.NewResults = contentRunResults(added)
+		if len(added) == 0 && len(removed) == 0 {
+			return nil
+		}
+		go func() {
+			if err := notifyContentChanges(context.Background(), spec, query, added, removed); err != nil {
+				log15.Error("executor: failed to send notifications", "error", err)
+			}
+		}()
+		return nil
+	}
+
+	info, err := api.InternalClient.SavedQueriesGetInfo(ctx, query.Query)
+	if err != nil {
+		return errors.Wrap(err, "SavedQueriesGetInfo")
+	}
+	if info == nil {
+		return nil
+	}
+
+	newQuery := fmt.Sprintf("%s %s", query.Query, repoRevFilter(update.Repo, fmt.Sprintf("%s..%s", update.OldCommit, update.NewCommit)))
+	start := time.Now()
+	v, execDuration, err := performSearch(ctx, newQuery)
+	run := newRun(query.Query, start, execDuration, v, err)
+	defer func() { logRun(ctx, run, err) }()
+	if err != nil {
+		return err
+	}
+
+	// The saved query's latest result time is left alone, because it applies
+	// to all repositories and other repositories may have newer commits that
+	// the next poll hasn't seen yet. Instead, remember the commits so that
+	// the next poll doesn't notify about them again.
+	commits, err := extractCommitResults(v.Data.Search.Results.Results)
+	if err != nil {
+		return err
+	}
+	if e.notifiedCommits == nil {
+		e.notifiedCommits = map[string]map[repoCommit]bool{}
+	}
+	if e.notifiedCommits[query.Query] == nil {
+		e.notifiedCommits[query.Query] = map[repoCommit]bool{}
+	}
+	for _, c := range commits {
+		e.notifiedCommits[query.Query][repoCommit{Repo: c.Repository.Name, Commit: api.CommitID(c.OID)}] = true
+	}
+	run.NewResultCount = len(v.Data.Search.Results.Results)
+	if run.NewResults, err = commitRunResults(v.Data.Search.Results.Results); err != nil {
+		log15.Warn("executor: failed to extract new results of saved query run", "error", err)
+	}
+
+	// Send notifications in a separate goroutine for the same reason as in
+	// runQuery.
+	go func() {
+		if err := notify(context.Background(), spec, query, newQuery, v); err != nil {
+			log15.Error("executor: failed to send notifications", "error", err)
+		}
+	}()
+	return nil
+}
+
+// repoCommit identifies a commit in a repository.
+type repoCommit struct {
+	Repo   api.RepoName
+	Commit api.CommitID
+}
+
+// skipNotifiedCommits removes the commit search results that runs of the saved
+// query on repository updates already sent notifications about from v, and
+// forgets about those commits.
+func (e *executorT) skipNotifiedCommits(query string, v *gqlSearchResponse) {
+	notified := e.notifiedCommits[query]
+	if len(notified) == 0 {
+		return
+	}
+	delete(e.notifiedCommits, query)
+
+	var results []interface{}
+	for _, result := range v.Data.Search.Results.Results {
+		commits, err := extractCommitResults([]interface{}{result})
+		if err == nil && len(commits) == 1 && notified[repoCommit{Repo: commits[0].Repository.Name, Commit: api.CommitID(commits[0].OID)}] {
+			continue
+		}
+		results = append(results, result)
+	}
+	v.Data.Search.Results.Results = results
+}
+
+// repoUpdateScope returns the repositories whose updates the saved query is
+// run on, or false if it is not run on repository updates. Saved queries with
+// a monitor are not, because the monitor's trigger conditions compare the
+// results of complete runs.
+func repoUpdateScope(query api.ConfigSavedQuery) (*repoScope, bool) {
+	if query.Monitor != nil {
+		return nil, false
+	}
+	return parseRepoScope(query.Query)
+}
+
+// repoRevFilter returns a repo: filter that matches only the given repository
+// at the given revision (or commit range).
+func repoRevFilter(repo api.RepoName, rev string) string {
+	return fmt.Sprintf("repo:^%s$@%s", regexp.QuoteMeta(string(repo)), rev)
+}
+
+// repoScope describes the repositories that a search query applies to, as
+// determined by its repo: and -repo: filters.
+type repoScope struct {
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+}
+
+// parseRepoScope returns the repositories that the query applies to. It
+// returns false if the query can't be run on repository updates, because it
+// searches specific revisions (with repo:foo@rev) instead of the default
+// branch.
+//
+// The scope errs on the side of matching too many repositories: filters whose
+// meaning can't be determined here (such as repogroup: or an invalid regexp)
+// are ignored.
+func parseRepoScope(query string) (*repoScope, bool) {
+	scope := &repoScope{}
+	for _, token := range queryTokens(query) {
+		var negated bool
+		if strings.HasPrefix(token, "-") {
+			negated = true
+			token = token[1:]
+		}
+		i := strings.Index(token, ":")
+		if i == -1 {
+			continue
+		}
+		field, value := strings.ToLower(token[:i]), unquote(token[i+1:])
+		if field != "repo" && field != "r" {
+			continue
+		}
+		if strings.Contains(value, "@") {
+			return nil, false
+		}
+		re, err := regexp.Compile("(?i)" + value)
+		if err != nil {
+			continue
+		}
+		if negated {
+			scope.exclude = append(scope.exclude, re)
+		} else {
+			scope.include = append(scope.include, re)
+		}
+	}
+	return scope, true
+}
+
+// matches reports whether the repository is in the scope. Like in search, a
+// repository must match all repo: filters and none of the -repo: filters.
+func (s *repoScope) matches(repo api.RepoName) bool {
+	for _, re := range s.include {
+		if !re.MatchString(string(repo)) {
+			return false
+		}
+	}
+	for _, re := range s.exclude {
+		if re.MatchString(string(repo)) {
+			return false
+		}
+	}
+	return true
+}
+
+// queryTokens splits the query on whitespace, except for whitespace inside
+// double quotes.
+func queryTokens(query string) []string {
+	var (
+		tokens []string
+		token  strings.Builder
+		quoted bool
+		escape bool
+	)
+	for _, c := range query {
+		switch {
+		case escape:
+			escape = false
+		case quoted && c == '\\':
+			escape = true
+		case c == '"':
+			quoted = !quoted
+		case !quoted && (c == ' ' || c == '\t' || c == '\n'):
+			if token.Len() > 0 {
+				tokens = append(tokens, token.String())
+				token.Reset()
+			}
+			continue
+		}
+		token.WriteRune(c)
+	}
+	if token.Len() > 0 {
+		tokens = append(tokens, token.String())
+	}
+	return tokens
+}
+
+// unquote returns the value with surrounding double quotes removed (and
+// escape sequences interpreted), if it is quoted.
+func unquote(value string) string {
+	if len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
+		if s, err := strconv.Unquote(value); err == nil {
+			return s
+		}
+	}
+	return value
+}
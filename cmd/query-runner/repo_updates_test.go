@@ -0,0 +1,112 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+)
+
+func TestParseRepoScope(t *testing.T) {
+	tests := map[string]struct {
+		query    string
+		eligible bool
+		matches  []api.RepoName
+		excludes []api.RepoName
+	}{
+		"no repo filter": {
+			query:    "type:diff TODO",
+			eligible: true,
+			matches:  []api.RepoName{"github.com/a/b", "gitlab.com/c/d"},
+		},
+		"repo filters are ANDed": {
+			query:    "repo:github.com/ r:/b$ TODO",
+			eligible: true,
+			matches:  []api.RepoName{"github.com/a/b", "github.com/c/b"},
+			excludes: []api.RepoName{"github.com/a/c", "gitlab.com/a/b"},
+		},
+		"case-insensitive": {
+			query:    "repo:^GitHub\\.com/A/B$ TODO",
+			eligible: true,
+			matches:  []api.RepoName{"github.com/a/b"},
+			excludes: []api.RepoName{"github.com/a/bc"},
+		},
+		"negated": {
+			query:    "repo:github.com/a -repo:/forks/ -r:test TODO",
+			eligible: true,
+			matches:  []api.RepoName{"github.com/a/b"},
+			excludes: []api.RepoName{"github.com/a/forks/b", "github.com/a/test", "github.com/b/c"},
+		},
+		"quoted": {
+			query:    `repo:"github.com/a/b" "repo:x y"`,
+			eligible: true,
+			matches:  []api.RepoName{"github.com/a/b"},
+			excludes: []api.RepoName{"github.com/c/d"},
+		},
+		"invalid regexp is ignored": {
+			query:    "repo:github.com/( TODO",
+			eligible: true,
+			matches:  []api.RepoName{"github.com/a/b"},
+		},
+		"revision": {
+			query: "repo:github.com/a/b@develop TODO",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			scope, eligible := parseRepoScope(test.query)
+			if eligible != test.eligible {
+				t.Fatalf("got eligible %v, want %v", eligible, test.eligible)
+			}
+			for _, repo := range test.matches {
+				if !scope.matches(repo) {
+					t.Errorf("%s: got no match, want match", repo)
+				}
+			}
+			for _, repo := range test.excludes {
+				if scope.matches(repo) {
+					t.Errorf("%s: got match, want no match", repo)
+				}
+			}
+		})
+	}
+}
+
+func TestQueryTokens(t *testing.T) {
+	got := queryTokens(`repo:a  "foo bar" file:"x \" y"` + "\tz")
+	want := []string{"repo:a", `"foo bar"`, `file:"x \" y"`, "z"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestRepoRevFilter(t *testing.T) {
+	if got, want := repoRevFilter("github.com/a/b.c", "1111..2222"), `repo:^github\.com/a/b\.c$@1111..2222`; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestSkipNotifiedCommits(t *testing.T) {
+	commit := func(repo, oid string) interface{} {
+		return map[string]interface{}{
+			"__typename": "CommitSearchResult",
+			"commit": map[string]interface{}{
+				"repository": map[string]interface{}{"name": repo},
+				"oid":        oid,
+			},
+		}
+	}
+	e := &executorT{notifiedCommits: map[string]map[repoCommit]bool{
+		"type:diff TODO": {{Repo: "a", Commit: "1"}: true},
+	}}
+
+	var v gqlSearchResponse
+	v.Data.Search.Results.Results = []interface{}{commit("a", "1"), commit("a", "2"), commit("b", "1")}
+	e.skipNotifiedCommits("type:diff TODO", &v)
+	if want := []interface{}{commit("a", "2"), commit("b", "1")}; !reflect.DeepEqual(v.Data.Search.Results.Results, want) {
+		t.Errorf("got results %v, want %v", v.Data.Search.Results.Results, want)
+	}
+	if len(e.notifiedCommits) != 0 {
+		t.Errorf("got notified commits %v, want none", e.notifiedCommits)
+	}
+}
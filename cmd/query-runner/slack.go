@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	log15 "gopkg.in/inconshreveable/log15.v2"
 
@@ -11,17 +12,30 @@ import (
 )
 
 func (n *notifier) slackNotify(ctx context.Context) {
-	plural := ""
-	if n.results.Data.Search.Results.ApproximateResultCount != "1" {
-		plural = "s"
-	}
+	var text string
+	if n.results != nil {
+		plural := ""
+		if n.results.Data.Search.Results.ApproximateResultCount != "1" {
+			plural = "s"
+		}
 
-	text := fmt.Sprintf(`*%s* new result%s found for saved search <%s|"%s">`,
-		n.results.Data.Search.Results.ApproximateResultCount,
-		plural,
-		searchURL(n.newQuery, utmSourceSlack),
-		n.query.Description,
-	)
+		text = fmt.Sprintf(`*%s* new result%s found for saved search <%s|"%s">`,
+			n.results.Data.Search.Results.ApproximateResultCount,
+			plural,
+			searchURL(n.newQuery, utmSourceSlack),
+			n.query.Description,
+		)
+	} else {
+		text = fmt.Sprintf(`*%d* new and *%d* removed result%s found for saved search <%s|"%s">`,
+			len(n.added),
+			len(n.removed),
+			pluralSuffix(len(n.added)+len(n.removed)),
+			searchURL(n.newQuery, utmSourceSlack),
+			n.query.Description,
+		)
+		text += slackContentMatches("New", n.added)
+		text += slackContentMatches("Removed", n.removed)
+	}
 	for _, recipient := range n.recipients {
 		if err := slackNotify(ctx, recipient, text); err != nil {
 			log15.Error("Failed to post Slack notification message.", "recipient", recipient, "text", text, "error", err)
@@ -30,6 +44,19 @@ func (n *notifier) slackNotify(ctx context.Context) {
 	logEvent("", "SavedSearchSlackNotificationSent", "results")
 }
 
+// slackContentMatches formats the matches as a Slack message section.
+func slackContentMatches(heading string, matches []*api.SavedQueryMatch) string {
+	if len(matches) == 0 {
+		return ""
+	}
+	lines, omitted := contentMatchLines(matches)
+	text := fmt.Sprintf("\n%s:\n```\n%s\n```", heading, strings.Join(lines, "\n"))
+	if omitted > 0 {
+		text += fmt.Sprintf("\n_...and %d more_", omitted)
+	}
+	return text
+}
+
 func slackNotifySubscribed(ctx context.Context, recipient *recipient, query api.SavedQuerySpecAndConfig) error {
 	text := fmt.Sprintf(`Slack notifications enabled for the saved search <%s|"%s">. Notifications will be sent here when new results are available.`,
 		searchURL(query.Config.Query, utmSourceSlack),
@@ -0,0 +1,359 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	log15 "gopkg.in/inconshreveable/log15.v2"
+
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/env"
+	"github.com/sourcegraph/sourcegraph/pkg/randstring"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+const utmSourceWebhook = "saved-search-webhook"
+
+// Webhook notification event types, sent in the X-Sourcegraph-Event header and
+// the payload's "event" field.
+const (
+	webhookEventResults = "results"
+	webhookEventTest    = "test"
+)
+
+// webhookPayload is the JSON body POSTed to a saved query's webhook.
+type webhookPayload struct {
+	Event       string             `json:"event"`
+	SavedSearch webhookSavedSearch `json:"savedSearch"`
+	SearchURL   string             `json:"searchURL"`
+
+	// Results are the new results of a diff or commit search.
+	Results []*webhookResult `json:"results,omitempty"`
+
+	// Added and Removed are the changed results of a search on file contents.
+	Added   []*webhookResult `json:"added,omitempty"`
+	Removed []*webhookResult `json:"removed,omitempty"`
+}
+
+type webhookSavedSearch struct {
+	Description string `json:"description"`
+	Query       string `json:"query"`
+}
+
+// webhookResult is a single search result in a webhook payload. A commit
+// result has Commit and Message set; a file content result has Path (and Line
+// and Preview, unless it's a match on the path only) set.
+type webhookResult struct {
+	Repository api.RepoName `json:"repository"`
+	Commit     string       `json:"commit,omitempty"`
+	Message    string       `json:"message,omitempty"`
+	Path       string       `json:"path,omitempty"`
+	Line       int          `json:"line,omitempty"`
+	Preview    string       `json:"preview,omitempty"`
+	URL        string       `json:"url"`
+}
+
+func (n *notifier) webhookNotify(ctx context.Context) {
+	if n.query.NotifyWebhook == nil {
+		return
+	}
+
+	payload := &webhookPayload{
+		Event:       webhookEventResults,
+		SavedSearch: webhookSavedSearch{Description: n.query.Description, Query: n.query.Query},
+		SearchURL:   searchURL(n.newQuery, utmSourceWebhook),
+	}
+	if n.results != nil {
+		results, err := commitWebhookResults(n.results.Data.Search.Results.Results)
+		if err != nil {
+			log15.Error("Failed to construct webhook notification for saved search.", "error", err)
+			return
+		}
+		payload.Results = results
+	} else {
+		payload.Added = contentWebhookResults(n.added)
+		payload.Removed = contentWebhookResults(n.removed)
+	}
+
+	// Deliver asynchronously, because retries can take a while.
+	go func() {
+		if err := deliverWebhook(context.Background(), n.spec, n.query.NotifyWebhook, payload, webhookMaxAttempts); err != nil {
+			log15.Error("Failed to deliver webhook notification for saved search.", "url", trimWebhookURL(n.query.NotifyWebhook.Url), "error", err)
+		}
+	}()
+	logEvent("", "SavedSearchWebhookNotificationSent", "results")
+}
+
+// commitWebhookResults returns the webhook payload results for the commit
+// search results.
+func commitWebhookResults(results []interface{}) ([]*webhookResult, error) {
+	commits, err := extractCommitResults(results)
+	if err != nil {
+		return nil, err
+	}
+	webhookResults := make([]*webhookResult, len(commits))
+	for i, c := range commits {
+		webhookResults[i] = &webhookResult{
+			Repository: c.Repository.Name,
+			Commit:     c.OID,
+			Message:    c.Message,
+			URL:        absoluteURL(c.URL),
+		}
+	}
+	return webhookResults, nil
+}
+
+// contentWebhookResults returns the webhook payload results for the matches of
+// a search on file contents.
+func contentWebhookResults(matches []*api.SavedQueryMatch) []*webhookResult {
+	webhookResults := make([]*webhookResult, len(matches))
+	for i, m := range matches {
+		u := fmt.Sprintf("/%s/-/blob/%s", m.Repo, m.Path)
+		if m.Line > 0 {
+			u = fmt.Sprintf("%s#L%d", u, m.Line)
+		}
+		webhookResults[i] = &webhookResult{
+			Repository: m.Repo,
+			Path:       m.Path,
+			Line:       m.Line,
+			Preview:    m.Preview,
+			URL:        absoluteURL(u),
+		}
+	}
+	return webhookResults
+}
+
+// absoluteURL returns the absolute URL on the Sourcegraph instance for the
+// given path (such as "/github.com/foo/bar/-/blob/baz.go#L3").
+func absoluteURL(path string) string {
+	ref, err := url.Parse(path)
+	if err != nil {
+		return path
+	}
+	u := resolveExternalURL(ref)
+	if u == nil {
+		return path
+	}
+	return u.String()
+}
+
+// webhookMaxAttempts is the number of times delivery of a notification is
+// attempted before giving up.
+const webhookMaxAttempts = 5
+
+// webhookBackoff is the delay before the second attempt to deliver a
+// notification. It doubles after each subsequent attempt.
+var webhookBackoff = 10 * time.Second
+
+// logWebhookDelivery records a delivery attempt. It is a variable so that tests
+// can mock it.
+var logWebhookDelivery = api.InternalClient.SavedQueriesLogWebhookDelivery
+
+var webhookClient = &http.Client{
+	Timeout:   10 * time.Second,
+	Transport: &http.Transport{DialContext: dialWebhook},
+	// Don't follow redirects, so that the payload is only sent to the
+	// configured URL.
+	CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+}
+
+// deliverWebhook POSTs the payload to the webhook, making up to maxAttempts
+// attempts with exponential backoff if the request fails or the response is a
+// 5xx or 429 (Too Many Requests). Each attempt is recorded in the saved
+// query's webhook delivery log.
+func deliverWebhook(ctx context.Context, spec api.SavedQueryIDSpec, webhook *schema.SavedQueryWebhook, payload *webhookPayload, maxAttempts int) error {
+	if err := checkWebhookURL(webhook.Url); err != nil {
+		return err
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return errors.Wrap(err, "Marshal")
+	}
+
+	deliveryID := randstring.NewLen(20)
+	backoff := webhookBackoff
+	for attempt := 1; ; attempt++ {
+		start := time.Now()
+		statusCode, err := postWebhook(ctx, webhook, deliveryID, payload.Event, body)
+		delivery := &api.SavedQueryWebhookDelivery{
+			Spec:       spec,
+			DeliveryID: deliveryID,
+			Event:      payload.Event,
+			URL:        trimWebhookURL(webhook.Url),
+			Attempt:    attempt,
+			StatusCode: statusCode,
+			Duration:   time.Since(start),
+		}
+		if err != nil {
+			delivery.Error = err.Error()
+		}
+		if logErr := logWebhookDelivery(ctx, delivery); logErr != nil {
+			log15.Warn("Failed to log saved search webhook delivery.", "deliveryID", deliveryID, "attempt", attempt, "error", logErr)
+		}
+
+		if err == nil {
+			return nil
+		}
+		if attempt >= maxAttempts || !retryableWebhookStatus(statusCode) {
+			return errors.Wrapf(err, "delivery %s failed after %d attempt(s)", deliveryID, attempt)
+		}
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		backoff *= 2
+	}
+}
+
+// postWebhook POSTs the signed body to the webhook and returns the response
+// status code (or 0 if no response was received). A non-2xx response is an
+// error.
+func postWebhook(ctx context.Context, webhook *schema.SavedQueryWebhook, deliveryID, event string, body []byte) (statusCode int, err error) {
+	req, err := http.NewRequest("POST", webhook.Url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Sourcegraph-Webhook")
+	req.Header.Set("X-Sourcegraph-Event", event)
+	req.Header.Set("X-Sourcegraph-Delivery", deliveryID)
+	if webhook.Secret != "" {
+		req.Header.Set("X-Sourcegraph-Signature", "sha256="+webhookSignature(webhook.Secret, body))
+	}
+
+	resp, err := webhookClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// webhookSignature returns the hex-encoded HMAC-SHA256 of the body keyed with
+// the secret. Receivers verify a payload by computing the same signature and
+// comparing it to the X-Sourcegraph-Signature header.
+func webhookSignature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// retryableWebhookStatus reports whether a failed delivery with the given
+// response status code (0 if no response was received) should be retried.
+func retryableWebhookStatus(statusCode int) bool {
+	return statusCode == 0 || statusCode == http.StatusTooManyRequests || statusCode >= 500
+}
+
+// checkWebhookURL returns an error if the webhook URL is not an HTTPS URL.
+// Plain HTTP URLs are allowed in insecure dev mode.
+func checkWebhookURL(webhookURL string) error {
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		return errors.Wrap(err, "invalid webhook URL")
+	}
+	if u.Scheme == "https" || (env.InsecureDev && u.Scheme == "http") {
+		return nil
+	}
+	return fmt.Errorf("webhook URL %q must use https", webhookURL)
+}
+
+// webhookDialer dials the connections of webhook requests.
+var webhookDialer = &net.Dialer{Timeout: 10 * time.Second}
+
+// dialWebhook is the DialContext of the webhook HTTP client. Any user can
+// configure a webhook, so it refuses to connect to internal addresses (which
+// would let users probe hosts and ports on the internal network through the
+// webhook delivery log). It dials the resolved address itself, so that the
+// host can't resolve to a different address between the check and the dial.
+// Internal addresses are allowed in insecure dev mode.
+func dialWebhook(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("no addresses found for webhook host %s", host)
+	}
+	if !env.InsecureDev {
+		for _, ip := range ips {
+			if err := checkWebhookIP(ip.IP); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return webhookDialer.DialContext(ctx, network, net.JoinHostPort(ips[0].IP.String(), port))
+}
+
+// internalNetworks are the private and shared (carrier-grade NAT) address
+// ranges that webhooks may not be delivered to.
+var internalNetworks = func() []*net.IPNet {
+	var nets []*net.IPNet
+	for _, cidr := range []string{"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "100.64.0.0/10", "fc00::/7"} {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		nets = append(nets, n)
+	}
+	return nets
+}()
+
+// checkWebhookIP returns an error if ip is a loopback, private, link-local or
+// unspecified address.
+func checkWebhookIP(ip net.IP) error {
+	if ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsUnspecified() {
+		return fmt.Errorf("webhook address %s is not allowed", ip)
+	}
+	for _, n := range internalNetworks {
+		if n.Contains(ip) {
+			return fmt.Errorf("webhook address %s is not allowed", ip)
+		}
+	}
+	return nil
+}
+
+// webhookNotifyTest sends a test notification to the saved query's webhook and
+// waits for it to be delivered (without retrying).
+func webhookNotifyTest(ctx context.Context, query api.SavedQuerySpecAndConfig) error {
+	if query.Config.NotifyWebhook == nil {
+		return nil
+	}
+	payload := &webhookPayload{
+		Event:       webhookEventTest,
+		SavedSearch: webhookSavedSearch{Description: query.Config.Description, Query: query.Config.Query},
+		SearchURL:   searchURL(query.Config.Query, utmSourceWebhook),
+	}
+	if err := deliverWebhook(ctx, query.Spec, query.Config.NotifyWebhook, payload, 1); err != nil {
+		return err
+	}
+	logEvent("", "SavedSearchWebhookNotificationSent", webhookEventTest)
+	return nil
+}
+
+// trimWebhookURL is used in log messages so that secrets in the webhook URL's
+// query string (some services put tokens there) are not logged.
+func trimWebhookURL(webhookURL string) string {
+	if i := strings.IndexAny(webhookURL, "?#"); i != -1 {
+		return webhookURL[:i]
+	}
+	return webhookURL
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/env"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestDeliverWebhook(t *testing.T) {
+	var (
+		requests   int
+		deliveryID string
+	)
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := r.Header.Get("X-Sourcegraph-Signature"), "sha256="+webhookSignature("s3cr3t", body); got != want {
+			t.Errorf("got signature %q, want %q", got, want)
+		}
+		if got, want := r.Header.Get("X-Sourcegraph-Event"), webhookEventResults; got != want {
+			t.Errorf("got event %q, want %q", got, want)
+		}
+		if requests == 1 {
+			deliveryID = r.Header.Get("X-Sourcegraph-Delivery")
+		} else if got := r.Header.Get("X-Sourcegraph-Delivery"); got != deliveryID {
+			t.Errorf("got delivery ID %q on retry, want %q", got, deliveryID)
+		}
+		var payload webhookPayload
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Fatal(err)
+		}
+		if payload.SavedSearch.Query != "q" {
+			t.Errorf("got query %q, want %q", payload.SavedSearch.Query, "q")
+		}
+
+		if requests < 3 {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		}
+	}))
+	defer ts.Close()
+
+	origClient, origBackoff := webhookClient, webhookBackoff
+	webhookClient, webhookBackoff = ts.Client(), time.Millisecond
+	var deliveries []*api.SavedQueryWebhookDelivery
+	logWebhookDelivery = func(ctx context.Context, d *api.SavedQueryWebhookDelivery) error {
+		deliveries = append(deliveries, d)
+		return nil
+	}
+	defer func() {
+		webhookClient, webhookBackoff = origClient, origBackoff
+		logWebhookDelivery = api.InternalClient.SavedQueriesLogWebhookDelivery
+	}()
+
+	// Secrets in the query string are not logged.
+	webhook := &schema.SavedQueryWebhook{Url: ts.URL + "/hook?token=t0k3n", Secret: "s3cr3t"}
+	payload := &webhookPayload{Event: webhookEventResults, SavedSearch: webhookSavedSearch{Query: "q"}}
+	if err := deliverWebhook(context.Background(), api.SavedQueryIDSpec{Key: "k"}, webhook, payload, webhookMaxAttempts); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 3 {
+		t.Errorf("got %d requests, want 3", requests)
+	}
+	if len(deliveries) != 3 {
+		t.Fatalf("got %d logged deliveries, want 3", len(deliveries))
+	}
+	for i, d := range deliveries {
+		wantStatus, wantErr := http.StatusServiceUnavailable, true
+		if i == 2 {
+			wantStatus, wantErr = http.StatusOK, false
+		}
+		if d.Attempt != i+1 || d.StatusCode != wantStatus || (d.Error != "") != wantErr || d.DeliveryID != deliveryID || d.URL != ts.URL+"/hook" {
+			t.Errorf("delivery %d: got %+v", i, d)
+		}
+	}
+
+	// Client errors are not retried.
+	requests = 0
+	deliveries = nil
+	ts.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.Error(w, "bad request", http.StatusBadRequest)
+	})
+	if err := deliverWebhook(context.Background(), api.SavedQueryIDSpec{Key: "k"}, webhook, payload, webhookMaxAttempts); err == nil {
+		t.Error("got nil error for 400 response")
+	}
+	if requests != 1 || len(deliveries) != 1 {
+		t.Errorf("got %d requests and %d logged deliveries, want 1 and 1", requests, len(deliveries))
+	}
+}
+
+func TestCheckWebhookURL(t *testing.T) {
+	if err := checkWebhookURL("https://example.com/hook"); err != nil {
+		t.Error(err)
+	}
+	for _, u := range []string{"http://example.com/hook", "ftp://example.com", "example.com"} {
+		if err := checkWebhookURL(u); err == nil {
+			t.Errorf("%q: got nil error", u)
+		}
+	}
+}
+
+func TestCheckWebhookIP(t *testing.T) {
+	for _, ip := range []string{"93.184.216.34", "8.8.8.8", "2606:2800:220:1:248:1893:25c8:1946"} {
+		if err := checkWebhookIP(net.ParseIP(ip)); err != nil {
+			t.Errorf("%s: %s", ip, err)
+		}
+	}
+	for _, ip := range []string{"127.0.0.1", "::1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "100.64.0.1", "169.254.169.254", "fe80::1", "fd00::1", "0.0.0.0", "::"} {
+		if err := checkWebhookIP(net.ParseIP(ip)); err == nil {
+			t.Errorf("%s: got nil error", ip)
+		}
+	}
+}
+
+func TestDialWebhook(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	// The test server listens on a loopback address, so webhooks may not be
+	// delivered to it.
+	addr := strings.TrimPrefix(ts.URL, "http://")
+	if _, err := dialWebhook(context.Background(), "tcp", addr); err == nil {
+		t.Error("got nil error dialing a loopback address")
+	}
+	_, port, _ := net.SplitHostPort(addr)
+	if _, err := dialWebhook(context.Background(), "tcp", net.JoinHostPort("localhost", port)); err == nil {
+		t.Error("got nil error dialing a host that resolves to a loopback address")
+	}
+
+	// In insecure dev mode, it's allowed.
+	origInsecureDev := env.InsecureDev
+	env.InsecureDev = true
+	defer func() { env.InsecureDev = origInsecureDev }()
+	conn, err := dialWebhook(context.Background(), "tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+}
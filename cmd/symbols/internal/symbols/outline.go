@@ -0,0 +1,225 @@
+package symbols
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/keegancsmith/sqlf"
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	otlog "github.com/opentracing/opentracing-go/log"
+	"github.com/sourcegraph/sourcegraph/pkg/symbols/protocol"
+	"golang.org/x/net/trace"
+	log15 "gopkg.in/inconshreveable/log15.v2"
+)
+
+// maxOutlineSymbols is the maximum number of symbols in a single file's
+// outline. Files with more symbols than this are almost always generated.
+const maxOutlineSymbols = 5000
+
+func (s *Service) handleOutline(w http.ResponseWriter, r *http.Request) {
+	var args protocol.OutlineArgs
+	if err := json.NewDecoder(r.Body).Decode(&args); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := s.outline(r.Context(), args)
+	if err != nil {
+		if err == context.Canceled && r.Context().Err() == context.Canceled {
+			return // client went away
+		}
+		log15.Error("Symbol outline failed", "args", args, "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (s *Service) outline(ctx context.Context, args protocol.OutlineArgs) (result *protocol.OutlineResult, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	log15.Debug("Symbol outline", "repo", args.Repo, "path", args.Path)
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, "outline")
+	span.SetTag("repo", args.Repo)
+	span.SetTag("commitID", args.CommitID)
+	span.SetTag("path", args.Path)
+	defer func() {
+		if err != nil {
+			ext.Error.Set(span, true)
+			span.LogFields(otlog.Error(err))
+		}
+		span.Finish()
+	}()
+
+	tr := trace.New("symbols.outline", fmt.Sprintf("args:%+v", args))
+	defer func() {
+		if err != nil {
+			tr.LazyPrintf("error: %v", err)
+			tr.SetError()
+		}
+		tr.Finish()
+	}()
+
+	dbFile, err := s.getDBFile(ctx, args.Repo, args.CommitID)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sqlx.Open("sqlite3_with_pcre", dbFile)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	symbols, err := fileSymbols(ctx, db, args.Path)
+	if err != nil {
+		return nil, err
+	}
+	return &protocol.OutlineResult{Symbols: buildOutline(symbols)}, nil
+}
+
+// fileSymbols returns all symbols defined in the file at path, ordered by the
+// line on which they are defined.
+func fileSymbols(ctx context.Context, db *sqlx.DB, path string) (res []protocol.Symbol, err error) {
+	span, _ := opentracing.StartSpanFromContext(ctx, "fileSymbols")
+	defer func() {
+		if err != nil {
+			ext.Error.Set(span, true)
+			span.LogFields(otlog.Error(err))
+		}
+		span.Finish()
+	}()
+
+	// Uses path_index. The rowid tiebreaker preserves ctags' output order for
+	// symbols defined on the same line.
+	sqlQuery := sqlf.Sprintf("SELECT * FROM symbols WHERE path = %s ORDER BY line, rowid LIMIT %s", path, maxOutlineSymbols)
+
+	var symbolsInDB []symbolInDB
+	err = db.Select(&symbolsInDB, sqlQuery.Query(sqlf.PostgresBindVar), sqlQuery.Args()...)
+	if err != nil {
+		return nil, err
+	}
+
+	res = make([]protocol.Symbol, 0, len(symbolsInDB))
+	for _, symbolInDB := range symbolsInDB {
+		res = append(res, symbolInDBToSymbol(symbolInDB))
+	}
+	span.SetTag("symbols", len(res))
+	return res, nil
+}
+
+// buildOutline arranges the symbols of a single file (ordered by line) into a
+// tree using each symbol's Parent and ParentKind.
+//
+// ctags reports a symbol's parent as a (possibly qualified) name, not as a
+// reference to another entry, so the parent is resolved by name. When several
+// symbols share the parent's name (e.g., overloads or a type and its
+// constructor), the one whose kind matches ParentKind and that is defined
+// closest before the child wins. Symbols whose parent can't be found in the
+// file (such as Go methods on a type declared in another file) are top-level.
+func buildOutline(symbols []protocol.Symbol) []*protocol.OutlineSymbol {
+	nodes := make([]*protocol.OutlineSymbol, len(symbols))
+	byName := map[string][]int{} // symbol name (qualified and unqualified) -> indexes into nodes
+	for i, symbol := range symbols {
+		nodes[i] = &protocol.OutlineSymbol{Symbol: symbol}
+		byName[symbol.Name] = append(byName[symbol.Name], i)
+		if symbol.Parent != "" {
+			qualified := symbol.Parent + "." + symbol.Name
+			byName[qualified] = append(byName[qualified], i)
+		}
+	}
+
+	parents := make([]int, len(symbols)) // index of each node's parent, or -1 if top-level
+	for i := range parents {
+		parents[i] = -1
+	}
+	for i, symbol := range symbols {
+		if symbol.Parent == "" {
+			continue
+		}
+		candidates := byName[symbol.Parent]
+		if len(candidates) == 0 {
+			candidates = byName[unqualifiedName(symbol.Parent)]
+		}
+		parent := closestParentCandidate(symbols, candidates, i)
+		if parent == -1 || isAncestor(parents, i, parent) {
+			continue
+		}
+		parents[i] = parent
+	}
+
+	var roots []*protocol.OutlineSymbol
+	for i, node := range nodes {
+		if parents[i] == -1 {
+			roots = append(roots, node)
+		} else {
+			parent := nodes[parents[i]]
+			parent.Children = append(parent.Children, node)
+		}
+	}
+	return roots
+}
+
+// closestParentCandidate returns the index of the best parent for
+// symbols[child] among candidates, or -1 if there is none.
+func closestParentCandidate(symbols []protocol.Symbol, candidates []int, child int) int {
+	best := -1
+	better := func(c int) bool {
+		if best == -1 {
+			return true
+		}
+		kindMatches := func(i int) bool { return symbols[i].Kind == symbols[child].ParentKind }
+		if kindMatches(c) != kindMatches(best) {
+			return kindMatches(c)
+		}
+		before := func(i int) bool { return symbols[i].Line <= symbols[child].Line }
+		if before(c) != before(best) {
+			return before(c)
+		}
+		if before(c) {
+			return symbols[c].Line > symbols[best].Line
+		}
+		return symbols[c].Line < symbols[best].Line
+	}
+	for _, c := range candidates {
+		if c != child && better(c) {
+			best = c
+		}
+	}
+	return best
+}
+
+// isAncestor reports whether node is an ancestor of (or the same as) other,
+// according to the parent indexes assigned so far. It prevents cycles when
+// ctags output is ambiguous.
+func isAncestor(parents []int, node, other int) bool {
+	for i := other; i != -1; i = parents[i] {
+		if i == node {
+			return true
+		}
+	}
+	return false
+}
+
+// unqualifiedName returns the last component of a qualified ctags scope name
+// such as "a.b.C" or "a::b::C".
+func unqualifiedName(name string) string {
+	if i := strings.LastIndex(name, "::"); i != -1 {
+		name = name[i+len("::"):]
+	}
+	if i := strings.LastIndexAny(name, "./"); i != -1 {
+		name = name[i+1:]
+	}
+	return name
+}
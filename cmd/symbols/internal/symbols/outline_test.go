@@ -0,0 +1,88 @@
+package symbols
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/pkg/symbols/protocol"
+)
+
+func TestBuildOutline(t *testing.T) {
+	// outlineNames returns a compact representation of the outline, for easier comparison.
+	var outlineNames func(nodes []*protocol.OutlineSymbol) []interface{}
+	outlineNames = func(nodes []*protocol.OutlineSymbol) []interface{} {
+		var names []interface{}
+		for _, node := range nodes {
+			names = append(names, node.Name)
+			if len(node.Children) > 0 {
+				names = append(names, outlineNames(node.Children))
+			}
+		}
+		return names
+	}
+
+	tests := map[string]struct {
+		symbols []protocol.Symbol
+		want    []interface{}
+	}{
+		"empty": {
+			symbols: nil,
+			want:    nil,
+		},
+		"nested": {
+			symbols: []protocol.Symbol{
+				{Name: "A", Kind: "class", Line: 4},
+				{Name: "D", Kind: "field", Line: 5, Parent: "A", ParentKind: "class"},
+				{Name: "Inner", Kind: "class", Line: 6, Parent: "A", ParentKind: "class"},
+				{Name: "g", Kind: "method", Line: 7, Parent: "A.Inner", ParentKind: "class"},
+				{Name: "F", Kind: "method", Line: 10, Parent: "A", ParentKind: "class"},
+				{Name: "B", Kind: "class", Line: 20},
+			},
+			want: []interface{}{"A", []interface{}{"D", "Inner", []interface{}{"g"}, "F"}, "B"},
+		},
+		"parent kind preferred over name collision": {
+			symbols: []protocol.Symbol{
+				{Name: "T", Kind: "class", Line: 1},
+				{Name: "T", Kind: "method", Line: 2, Parent: "T", ParentKind: "class"},
+				{Name: "x", Kind: "field", Line: 3, Parent: "T", ParentKind: "class"},
+			},
+			want: []interface{}{"T", []interface{}{"T", "x"}},
+		},
+		"parent declared after child": {
+			symbols: []protocol.Symbol{
+				{Name: "m", Kind: "func", Line: 1, Parent: "T", ParentKind: "struct"},
+				{Name: "T", Kind: "struct", Line: 5},
+			},
+			want: []interface{}{"T", []interface{}{"m"}},
+		},
+		"parent not in file": {
+			symbols: []protocol.Symbol{
+				{Name: "m", Kind: "func", Line: 1, Parent: "T", ParentKind: "struct"},
+			},
+			want: []interface{}{"m"},
+		},
+		"qualified scope with ::": {
+			symbols: []protocol.Symbol{
+				{Name: "ns", Kind: "namespace", Line: 1},
+				{Name: "C", Kind: "class", Line: 2, Parent: "ns", ParentKind: "namespace"},
+				{Name: "f", Kind: "function", Line: 3, Parent: "outer::ns::C", ParentKind: "class"},
+			},
+			want: []interface{}{"ns", []interface{}{"C", []interface{}{"f"}}},
+		},
+		"cycle": {
+			symbols: []protocol.Symbol{
+				{Name: "a", Kind: "x", Line: 1, Parent: "b", ParentKind: "x"},
+				{Name: "b", Kind: "x", Line: 2, Parent: "a", ParentKind: "x"},
+			},
+			want: []interface{}{"b", []interface{}{"a"}},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := outlineNames(buildOutline(test.symbols))
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -93,7 +93,7 @@ func (s *Service) search(ctx context.Context, args protocol.SearchArgs) (result
 		tr.Finish()
 	}()
 
-	dbFile, err := s.getDBFile(ctx, args)
+	dbFile, err := s.getDBFile(ctx, args.Repo, args.CommitID)
 	if err != nil {
 		return nil, err
 	}
@@ -112,15 +112,15 @@ func (s *Service) search(ctx context.Context, args protocol.SearchArgs) (result
 	return result, nil
 }
 
-// getDBFile returns the path to the sqlite3 database for the repo@commit
-// specified in `args`. If the database doesn't already exist in the disk cache,
-// it will create a new one and write all the symbols into it.
-func (s *Service) getDBFile(ctx context.Context, args protocol.SearchArgs) (string, error) {
-	diskcacheFile, err := s.cache.OpenWithPath(ctx, fmt.Sprintf("%d-%s@%s", symbolsDBVersion, args.Repo, args.CommitID), func(fetcherCtx context.Context, tempDBFile string) error {
-		err := s.writeAllSymbolsToNewDB(fetcherCtx, tempDBFile, args.Repo, args.CommitID)
+// getDBFile returns the path to the sqlite3 database for the repo@commit. If
+// the database doesn't already exist in the disk cache, it will create a new
+// one and write all the symbols into it.
+func (s *Service) getDBFile(ctx context.Context, repo api.RepoName, commitID api.CommitID) (string, error) {
+	diskcacheFile, err := s.cache.OpenWithPath(ctx, fmt.Sprintf("%d-%s@%s", symbolsDBVersion, repo, commitID), func(fetcherCtx context.Context, tempDBFile string) error {
+		err := s.writeAllSymbolsToNewDB(fetcherCtx, tempDBFile, repo, commitID)
 		if err != nil {
 			if err == context.Canceled {
-				log15.Error("Unable to parse repository symbols within the context", "repo", args.Repo, "commit", args.CommitID, "query", args.Query)
+				log15.Error("Unable to parse repository symbols within the context", "repo", repo, "commit", commitID)
 			}
 			return err
 		}
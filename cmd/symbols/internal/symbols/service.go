@@ -81,6 +81,7 @@ func (s *Service) Handler() http.Handler {
 	mux := http.NewServeMux()
 
 	mux.HandleFunc("/search", s.handleSearch)
+	mux.HandleFunc("/outline", s.handleOutline)
 	mux.HandleFunc("/healthz", s.handleHealthCheck)
 
 	return mux
@@ -0,0 +1,150 @@
+package ldap
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc/ldap"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+// authzProvider is an authz.Provider that grants read access to the repositories matched by the
+// repositoryPermissions rules of an LDAP auth provider to the members of the rules' LDAP groups.
+//
+// Users are identified by the external accounts created when they sign in with the LDAP auth
+// provider. Their group memberships are looked up in the directory whenever their permissions are
+// computed, so that users who are removed from a group lose access at the next permissions sync.
+type authzProvider struct {
+	serviceID string
+	client    *ldap.Client
+	rules     []authzRule
+}
+
+type authzRule struct {
+	pattern *regexp.Regexp
+	groups  map[string]struct{} // lowercase group names
+}
+
+var _ authz.Provider = (*authzProvider)(nil)
+
+// NewAuthzProvider returns the authz provider for the repositoryPermissions of the LDAP auth
+// provider, or nil if it has none.
+func NewAuthzProvider(pc *schema.LDAPAuthProvider) (authz.Provider, error) {
+	if pc.RepositoryPermissions == nil {
+		return nil, nil
+	}
+
+	client, err := newClient(pc)
+	if err != nil {
+		return nil, err
+	}
+	p := &authzProvider{serviceID: pc.Url, client: client}
+	for _, r := range pc.RepositoryPermissions.Rules {
+		pattern, err := regexp.Compile(r.RepositoryPattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid repositoryPattern %q for LDAP server %q: %s", r.RepositoryPattern, pc.Url, err)
+		}
+		rule := authzRule{pattern: pattern, groups: make(map[string]struct{}, len(r.Groups))}
+		for _, g := range r.Groups {
+			rule.groups[strings.ToLower(g)] = struct{}{}
+		}
+		p.rules = append(p.rules, rule)
+	}
+	return p, nil
+}
+
+// Repos implements authz.Provider. It claims the repositories that match any rule.
+func (p *authzProvider) Repos(ctx context.Context, repos map[authz.Repo]struct{}) (mine map[authz.Repo]struct{}, others map[authz.Repo]struct{}) {
+	mine, others = make(map[authz.Repo]struct{}), make(map[authz.Repo]struct{})
+	for repo := range repos {
+		if p.matchingRules(repo.RepoName) != nil {
+			mine[repo] = struct{}{}
+		} else {
+			others[repo] = struct{}{}
+		}
+	}
+	return mine, others
+}
+
+func (p *authzProvider) matchingRules(repo api.RepoName) (rules []authzRule) {
+	for _, r := range p.rules {
+		if r.pattern.MatchString(string(repo)) {
+			rules = append(rules, r)
+		}
+	}
+	return rules
+}
+
+// RepoPerms implements authz.Provider. The user may read a repository if they are a member of any
+// group of any rule that matches the repository. Anonymous users (and users who never signed in
+// with the LDAP auth provider) may not read any.
+func (p *authzProvider) RepoPerms(ctx context.Context, account *extsvc.ExternalAccount, repos map[authz.Repo]struct{}) (map[api.RepoName]map[authz.Perm]bool, error) {
+	perms := make(map[api.RepoName]map[authz.Perm]bool)
+	if account == nil || len(repos) == 0 {
+		return perms, nil
+	}
+	if account.ServiceType != p.ServiceType() || account.ServiceID != p.ServiceID() {
+		return nil, fmt.Errorf("LDAP authz provider %q can't compute permissions of external account of service %s %q", p.serviceID, account.ServiceType, account.ServiceID)
+	}
+
+	var data accountData
+	if err := account.GetAccountData(&data); err != nil {
+		return nil, err
+	}
+	if data.DN == "" {
+		return nil, fmt.Errorf("LDAP external account %q has no DN", account.AccountID)
+	}
+	groups, err := p.client.Groups(data.DN)
+	if err != nil {
+		return nil, err
+	}
+	inGroup := make(map[string]struct{}, len(groups))
+	for _, g := range groups {
+		inGroup[strings.ToLower(g)] = struct{}{}
+	}
+
+	for repo := range repos {
+		for _, r := range p.matchingRules(repo.RepoName) {
+			if r.allows(inGroup) {
+				perms[repo.RepoName] = map[authz.Perm]bool{authz.Read: true}
+				break
+			}
+		}
+	}
+	return perms, nil
+}
+
+func (r authzRule) allows(inGroup map[string]struct{}) bool {
+	for g := range r.groups {
+		if _, ok := inGroup[g]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// FetchAccount implements authz.Provider. LDAP external accounts are only created when users sign
+// in with the LDAP auth provider (which is the only way to verify their identity), so it always
+// returns nil.
+func (p *authzProvider) FetchAccount(ctx context.Context, user *types.User, current []*extsvc.ExternalAccount) (mine *extsvc.ExternalAccount, err error) {
+	return nil, nil
+}
+
+// ServiceType implements authz.Provider.
+func (p *authzProvider) ServiceType() string { return providerType }
+
+// ServiceID implements authz.Provider. It is the same as the ServiceID of the external accounts
+// created by the LDAP auth provider.
+func (p *authzProvider) ServiceID() string { return p.serviceID }
+
+// Validate implements authz.Provider. The rules are validated when the provider is created, and
+// problems with the directory are reported as permissions sync errors.
+func (p *authzProvider) Validate() (problems []string) {
+	return nil
+}
@@ -0,0 +1,125 @@
+package ldap
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestAuthzProvider(t *testing.T) {
+	defer newTestDirectory()()
+
+	cfg := newTestConfig()
+	if p, err := NewAuthzProvider(&cfg); p != nil || err != nil {
+		t.Fatalf("got provider %v (error %v), want none without repositoryPermissions", p, err)
+	}
+
+	cfg.RepositoryPermissions = &schema.LDAPRepositoryPermissions{
+		Rules: []*schema.LDAPRepositoryPermissionRule{
+			{RepositoryPattern: "^gitolite.example.com/", Groups: []string{"engineering"}},
+			{RepositoryPattern: "^gitolite.example.com/secret/", Groups: []string{"secret"}},
+			{RepositoryPattern: "^gitolite.example.com/nobody/", Groups: []string{}},
+		},
+	}
+	p, err := NewAuthzProvider(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		public = authz.Repo{RepoName: "github.com/foo/bar"}
+		eng    = authz.Repo{RepoName: "gitolite.example.com/eng"}
+		secret = authz.Repo{RepoName: "gitolite.example.com/secret/plans"}
+		nobody = authz.Repo{RepoName: "gitolite.example.com/nobody/x"}
+	)
+	mine, others := p.Repos(context.Background(), map[authz.Repo]struct{}{public: {}, eng: {}, secret: {}, nobody: {}})
+	if want := map[authz.Repo]struct{}{eng: {}, secret: {}, nobody: {}}; !reflect.DeepEqual(mine, want) {
+		t.Errorf("got mine %v, want %v", mine, want)
+	}
+	if want := map[authz.Repo]struct{}{public: {}}; !reflect.DeepEqual(others, want) {
+		t.Errorf("got others %v, want %v", others, want)
+	}
+
+	account := func(dn string) *extsvc.ExternalAccount {
+		acct := &extsvc.ExternalAccount{ExternalAccountSpec: extsvc.ExternalAccountSpec{ServiceType: "ldap", ServiceID: testServiceID, AccountID: dn}}
+		acct.SetAccountData(&accountData{DN: dn})
+		return acct
+	}
+	read := map[authz.Perm]bool{authz.Read: true}
+	tests := map[string]struct {
+		account   *extsvc.ExternalAccount
+		wantPerms map[api.RepoName]map[authz.Perm]bool
+	}{
+		"anonymous": {
+			account:   nil,
+			wantPerms: map[api.RepoName]map[authz.Perm]bool{},
+		},
+		"member of all groups": {
+			account:   account("uid=alice,ou=people,dc=example,dc=com"),
+			wantPerms: map[api.RepoName]map[authz.Perm]bool{eng.RepoName: read, secret.RepoName: read, nobody.RepoName: read},
+		},
+		"member of the broad rule's group only": {
+			account:   account("uid=bob,ou=people,dc=example,dc=com"),
+			wantPerms: map[api.RepoName]map[authz.Perm]bool{eng.RepoName: read, secret.RepoName: read, nobody.RepoName: read},
+		},
+		"not in the directory": {
+			account:   account("uid=carol,ou=people,dc=example,dc=com"),
+			wantPerms: map[api.RepoName]map[authz.Perm]bool{},
+		},
+	}
+	// Any matching rule grants access, so members of the broad "engineering" rule may read all of
+	// these repositories. Check the narrower rules separately below.
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			perms, err := p.RepoPerms(context.Background(), test.account, mine)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(perms, test.wantPerms) {
+				t.Errorf("got perms %v, want %v", perms, test.wantPerms)
+			}
+		})
+	}
+
+	t.Run("narrow rules", func(t *testing.T) {
+		cfg.RepositoryPermissions.Rules[0].RepositoryPattern = "^gitolite.example.com/eng$"
+		p, err := NewAuthzProvider(&cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		perms, err := p.RepoPerms(context.Background(), account("uid=bob,ou=people,dc=example,dc=com"), mine)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := map[api.RepoName]map[authz.Perm]bool{eng.RepoName: read}; !reflect.DeepEqual(perms, want) {
+			t.Errorf("got perms %v, want %v", perms, want)
+		}
+		perms, err = p.RepoPerms(context.Background(), account("uid=alice,ou=people,dc=example,dc=com"), mine)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := map[api.RepoName]map[authz.Perm]bool{eng.RepoName: read, secret.RepoName: read}; !reflect.DeepEqual(perms, want) {
+			t.Errorf("got perms %v, want %v", perms, want)
+		}
+	})
+
+	t.Run("external account of another service", func(t *testing.T) {
+		acct := account("uid=alice,ou=people,dc=example,dc=com")
+		acct.ServiceID = "ldap://other.example.com"
+		if _, err := p.RepoPerms(context.Background(), acct, mine); err == nil {
+			t.Error("got nil error, want error")
+		}
+	})
+
+	t.Run("invalid pattern", func(t *testing.T) {
+		cfg.RepositoryPermissions.Rules[0].RepositoryPattern = "("
+		if _, err := NewAuthzProvider(&cfg); err == nil {
+			t.Error("got nil error, want error")
+		}
+	})
+}
@@ -0,0 +1,139 @@
+package ldap
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
+	"github.com/sourcegraph/sourcegraph/pkg/conf"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc/ldap"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func getProvider(pcID string) *provider {
+	p, _ := auth.GetProviderByConfigID(auth.ProviderConfigID{Type: providerType, ID: pcID}).(*provider)
+	if p != nil {
+		return p
+	}
+
+	// Special case: if there is only a single LDAP auth provider, return it regardless of the pcID.
+	for _, ap := range auth.Providers() {
+		if ap.Config().Ldap != nil {
+			if p != nil {
+				return nil // multiple LDAP providers, can't use this special case
+			}
+			p = ap.(*provider)
+		}
+	}
+	return p
+}
+
+// providerConfigID produces a semi-stable identifier for an LDAP auth provider config object. It is
+// used to distinguish between multiple auth providers of the same type when signing in. Its value
+// is never persisted, and it must be deterministic.
+func providerConfigID(pc *schema.LDAPAuthProvider) string {
+	data, err := json.Marshal(pc)
+	if err != nil {
+		panic(err)
+	}
+	b := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(b[:16])
+}
+
+// withDefaults returns the config with the default values of unset optional properties filled in.
+func withDefaults(c schema.LDAPAuthProvider) schema.LDAPAuthProvider {
+	setDefault := func(v *string, defaultValue string) {
+		if *v == "" {
+			*v = defaultValue
+		}
+	}
+	setDefault(&c.UserFilter, "(objectClass=person)")
+	setDefault(&c.UsernameAttribute, "uid")
+	setDefault(&c.EmailAttribute, "mail")
+	setDefault(&c.DisplayNameAttribute, "cn")
+	setDefault(&c.GroupBaseDN, c.BaseDN)
+	setDefault(&c.GroupFilter, "(objectClass=groupOfNames)")
+	setDefault(&c.GroupMemberAttribute, "member")
+	setDefault(&c.GroupNameAttribute, "cn")
+	return c
+}
+
+// mockDial, if non-nil, is called instead of ldap.Dial. It should only be set in tests.
+var mockDial func() (ldap.Conn, error)
+
+// newClient returns a client for the directory of the LDAP auth provider.
+func newClient(pc *schema.LDAPAuthProvider) (*ldap.Client, error) {
+	c := withDefaults(*pc)
+
+	var tlsConfig *tls.Config
+	if c.Certificate != "" {
+		certPool := x509.NewCertPool()
+		if ok := certPool.AppendCertsFromPEM([]byte(c.Certificate)); !ok {
+			return nil, fmt.Errorf("invalid certificate for LDAP server %q", c.Url)
+		}
+		tlsConfig = &tls.Config{RootCAs: certPool}
+	}
+
+	dial := func() (ldap.Conn, error) { return ldap.Dial(c.Url, c.StartTLS, tlsConfig) }
+	if mockDial != nil {
+		dial = mockDial
+	}
+	return &ldap.Client{
+		Dial:                 dial,
+		BindDN:               c.BindDN,
+		BindPassword:         c.BindPassword,
+		UserBaseDN:           c.BaseDN,
+		UserFilter:           c.UserFilter,
+		UsernameAttribute:    c.UsernameAttribute,
+		UserAttributes:       []string{c.EmailAttribute, c.DisplayNameAttribute},
+		GroupBaseDN:          c.GroupBaseDN,
+		GroupFilter:          c.GroupFilter,
+		GroupMemberAttribute: c.GroupMemberAttribute,
+		GroupNameAttribute:   c.GroupNameAttribute,
+	}, nil
+}
+
+func init() {
+	conf.ContributeValidator(validateConfig)
+}
+
+func validateConfig(c conf.Unified) (problems []string) {
+	seen := map[string]struct{}{}
+	for _, p := range c.Critical.AuthProviders {
+		if p.Ldap == nil {
+			continue
+		}
+		pc := p.Ldap
+
+		if _, ok := seen[pc.Url]; ok {
+			problems = append(problems, fmt.Sprintf("at most 1 ldap auth provider may be used for the LDAP server %q", pc.Url))
+		}
+		seen[pc.Url] = struct{}{}
+
+		if u, err := url.Parse(pc.Url); err != nil {
+			problems = append(problems, fmt.Sprintf("invalid ldap auth provider url %q: %s", pc.Url, err))
+		} else if u.Scheme == "ldaps" && pc.StartTLS {
+			problems = append(problems, fmt.Sprintf("ldap auth provider %q: startTLS can't be used with the ldaps URL scheme", pc.Url))
+		}
+		if pc.BindDN != "" && pc.BindPassword == "" {
+			problems = append(problems, fmt.Sprintf("ldap auth provider %q: bindPassword is required when bindDN is set", pc.Url))
+		}
+		if _, err := newClient(pc); err != nil {
+			problems = append(problems, fmt.Sprintf("ldap auth provider %q: %s", pc.Url, err))
+		}
+		if pc.RepositoryPermissions != nil {
+			for _, rule := range pc.RepositoryPermissions.Rules {
+				if _, err := regexp.Compile(rule.RepositoryPattern); err != nil {
+					problems = append(problems, fmt.Sprintf("ldap auth provider %q: invalid repositoryPattern %q: %s", pc.Url, rule.RepositoryPattern, err))
+				}
+			}
+		}
+	}
+	return problems
+}
@@ -0,0 +1,25 @@
+package ldap
+
+import (
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
+	"github.com/sourcegraph/sourcegraph/pkg/conf"
+)
+
+func getProviders() []auth.Provider {
+	var providers []auth.Provider
+	for _, p := range conf.Get().Critical.AuthProviders {
+		if p.Ldap == nil {
+			continue
+		}
+		providers = append(providers, &provider{config: *p.Ldap})
+	}
+	return providers
+}
+
+func init() {
+	go func() {
+		conf.Watch(func() {
+			auth.UpdateProviders(providerType, getProviders())
+		})
+	}()
+}
@@ -0,0 +1,91 @@
+// Package ldap implements auth via an LDAP directory, and repository permissions based on the
+// user's LDAP groups.
+package ldap
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/external/session"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc/ldap"
+	log15 "gopkg.in/inconshreveable/log15.v2"
+)
+
+// All LDAP endpoints are under this path prefix.
+const authPrefix = auth.AuthURLPrefix + "/" + providerType
+
+// Middleware is middleware for LDAP authentication, adding the sign-in endpoint under the auth path
+// prefix ("/.auth").
+//
+// Unlike with SSO providers, there is no redirect flow: the web app's sign-in form posts the
+// username and password to the endpoint, which checks them by binding to the directory as the
+// user. Upon success, it creates a new session and session cookie.
+//
+// 🚨 SECURITY
+var Middleware = &auth.Middleware{
+	API: func(next http.Handler) http.Handler { return next },
+	App: func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == authPrefix+"/login" {
+				loginHandler(w, r)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	},
+}
+
+// credentials is the request body of the sign-in endpoint. It is the same as that of builtin
+// password authentication, so that the web app can use the same sign-in form (whose "email" field
+// holds the username).
+type credentials struct {
+	Username string `json:"email"`
+	Password string `json:"password"`
+}
+
+// loginHandler signs in the user with the username and password in the request body.
+//
+// 🚨 SECURITY
+func loginHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, fmt.Sprintf("Unsupported method %s", r.Method), http.StatusMethodNotAllowed)
+		return
+	}
+	// 🚨 SECURITY: The auth middleware runs before the CSRF middleware, so require a header that
+	// cross-origin HTML forms can't send to prevent login CSRF (like the API does for cookie auth).
+	if r.Header.Get("X-Requested-With") == "" {
+		http.Error(w, "Missing X-Requested-With header.", http.StatusForbidden)
+		return
+	}
+
+	p := getProvider(r.URL.Query().Get("pc"))
+	if p == nil {
+		log15.Error("No LDAP auth provider found with ID.", "id", r.URL.Query().Get("pc"))
+		http.Error(w, "Misconfigured LDAP auth provider.", http.StatusInternalServerError)
+		return
+	}
+
+	var creds credentials
+	if err := json.NewDecoder(r.Body).Decode(&creds); err != nil {
+		http.Error(w, "Could not decode request body", http.StatusBadRequest)
+		return
+	}
+
+	actr, safeErrMsg, err := getOrCreateUser(r.Context(), p, creds.Username, creds.Password)
+	if err == ldap.ErrInvalidCredentials {
+		http.Error(w, "Authentication failed", http.StatusUnauthorized)
+		return
+	} else if err != nil {
+		log15.Error("LDAP auth failed: error looking up LDAP-authenticated user.", "error", err, "userErr", safeErrMsg)
+		http.Error(w, safeErrMsg, http.StatusInternalServerError)
+		return
+	}
+
+	if err := session.SetActor(w, r, actr, 0); err != nil {
+		log15.Error("LDAP auth failed: could not initiate session.", "error", err)
+		http.Error(w, "Authentication failed. Try signing in again (and clearing cookies for the current site). The error was: could not initiate session.", http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,143 @@
+package ldap
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/external/session"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc/ldap"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+const testServiceID = "ldap://ldap.example.com"
+
+// newTestDirectory returns an in-process LDAP directory for tests and sets mockDial to connect to
+// it. The caller must call the returned cleanup func.
+func newTestDirectory() (cleanup func()) {
+	d := &ldap.MockDirectory{
+		Entries: []*ldap.Entry{
+			{DN: "cn=sourcegraph,dc=example,dc=com", Attributes: map[string][]string{"objectClass": {"applicationProcess"}}},
+			{DN: "uid=alice,ou=people,dc=example,dc=com", Attributes: map[string][]string{"objectClass": {"person"}, "uid": {"Alice"}, "mail": {"alice@example.com"}, "cn": {"Alice Smith"}}},
+			{DN: "uid=bob,ou=people,dc=example,dc=com", Attributes: map[string][]string{"objectClass": {"person"}, "uid": {"bob"}}},
+			{DN: "cn=engineering,ou=groups,dc=example,dc=com", Attributes: map[string][]string{"objectClass": {"groupOfNames"}, "cn": {"engineering"}, "member": {"uid=alice,ou=people,dc=example,dc=com", "uid=bob,ou=people,dc=example,dc=com"}}},
+			{DN: "cn=secret,ou=groups,dc=example,dc=com", Attributes: map[string][]string{"objectClass": {"groupOfNames"}, "cn": {"Secret"}, "member": {"uid=alice,ou=people,dc=example,dc=com"}}},
+		},
+		Passwords: map[string]string{
+			"cn=sourcegraph,dc=example,dc=com":      "s3cret",
+			"uid=alice,ou=people,dc=example,dc=com": "alicepw",
+			"uid=bob,ou=people,dc=example,dc=com":   "bobpw",
+		},
+	}
+	mockDial = d.Dial
+	return func() { mockDial = nil }
+}
+
+func newTestConfig() schema.LDAPAuthProvider {
+	return schema.LDAPAuthProvider{
+		Type:         providerType,
+		Url:          testServiceID,
+		BindDN:       "cn=sourcegraph,dc=example,dc=com",
+		BindPassword: "s3cret",
+		BaseDN:       "dc=example,dc=com",
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	cleanup := session.ResetMockSessionStore(t)
+	defer cleanup()
+	defer newTestDirectory()()
+
+	p := &provider{config: newTestConfig()}
+	auth.MockProviders = []auth.Provider{p}
+	defer func() { auth.MockProviders = nil }()
+
+	const mockUserID = 123
+	var gotOp *auth.GetAndSaveUserOp
+	auth.MockGetAndSaveUser = func(ctx context.Context, op auth.GetAndSaveUserOp) (int32, string, error) {
+		gotOp = &op
+		return mockUserID, "", nil
+	}
+	defer func() { auth.MockGetAndSaveUser = nil }()
+
+	loginURL := "http://example.com" + p.CachedInfo().AuthenticationURL
+	h := Middleware.App(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	doRequest := func(method, urlStr, body string, xhr bool) *http.Response {
+		req := httptest.NewRequest(method, urlStr, bytes.NewBufferString(body))
+		if xhr {
+			req.Header.Set("X-Requested-With", "Sourcegraph")
+		}
+		respRecorder := httptest.NewRecorder()
+		h.ServeHTTP(respRecorder, req)
+		return respRecorder.Result()
+	}
+
+	t.Run("other paths are passed through", func(t *testing.T) {
+		if resp := doRequest("GET", "http://example.com/search", "", false); resp.StatusCode != http.StatusTeapot {
+			t.Errorf("got status %d, want %d", resp.StatusCode, http.StatusTeapot)
+		}
+	})
+
+	tests := map[string]struct {
+		method, body string
+		xhr          bool
+		wantStatus   int
+	}{
+		"GET":                      {"GET", "", true, http.StatusMethodNotAllowed},
+		"missing X-Requested-With": {"POST", `{"email":"alice","password":"alicepw"}`, false, http.StatusForbidden},
+		"wrong password":           {"POST", `{"email":"alice","password":"wrong"}`, true, http.StatusUnauthorized},
+		"empty password":           {"POST", `{"email":"alice","password":""}`, true, http.StatusUnauthorized},
+		"no such user":             {"POST", `{"email":"carol","password":"x"}`, true, http.StatusUnauthorized},
+		"malformed body":           {"POST", `{`, true, http.StatusBadRequest},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			gotOp = nil
+			resp := doRequest(test.method, loginURL, test.body, test.xhr)
+			if resp.StatusCode != test.wantStatus {
+				t.Errorf("got status %d, want %d", resp.StatusCode, test.wantStatus)
+			}
+			if gotOp != nil {
+				t.Error("user was unexpectedly signed in")
+			}
+		})
+	}
+
+	t.Run("sign in", func(t *testing.T) {
+		resp := doRequest("POST", loginURL, `{"email":"alice","password":"alicepw"}`, true)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("got status %d, want %d", resp.StatusCode, http.StatusOK)
+		}
+		if len(resp.Cookies()) == 0 {
+			t.Error("got no session cookie")
+		}
+		if gotOp == nil {
+			t.Fatal("user was not signed in")
+		}
+
+		wantUser := db.NewUser{Username: "Alice", Email: "alice@example.com", EmailIsVerified: true, DisplayName: "Alice Smith"}
+		if !reflect.DeepEqual(gotOp.UserProps, wantUser) {
+			t.Errorf("got user %+v, want %+v", gotOp.UserProps, wantUser)
+		}
+		wantAccount := extsvc.ExternalAccountSpec{ServiceType: "ldap", ServiceID: testServiceID, AccountID: "Alice"}
+		if gotOp.ExternalAccount != wantAccount {
+			t.Errorf("got external account %+v, want %+v", gotOp.ExternalAccount, wantAccount)
+		}
+		var data accountData
+		if err := gotOp.ExternalAccountData.GetAccountData(&data); err != nil {
+			t.Fatal(err)
+		}
+		wantData := accountData{DN: "uid=alice,ou=people,dc=example,dc=com", Username: "Alice", Email: "alice@example.com", DisplayName: "Alice Smith", Groups: []string{"Secret", "engineering"}}
+		if !reflect.DeepEqual(data, wantData) {
+			t.Errorf("got account data %+v, want %+v", data, wantData)
+		}
+	})
+}
@@ -0,0 +1,155 @@
+package ldap
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/pkg/actor"
+	"github.com/sourcegraph/sourcegraph/pkg/errcode"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc"
+	log15 "gopkg.in/inconshreveable/log15.v2"
+)
+
+// accountData is the data of an LDAP external account.
+type accountData struct {
+	DN          string   `json:"dn"`
+	Username    string   `json:"username"`
+	Email       string   `json:"email,omitempty"`
+	DisplayName string   `json:"displayName,omitempty"`
+	Groups      []string `json:"groups"`
+}
+
+// getOrCreateUser checks the username and password against the directory, and gets or creates the
+// user account for the directory entry. It returns the authenticated actor if successful; otherwise
+// it returns an friendly error message (safeErrMsg) that is safe to display to users, and a non-nil
+// err with lower-level error details. If the username or password is incorrect, err is
+// ldap.ErrInvalidCredentials.
+func getOrCreateUser(ctx context.Context, p *provider, username, password string) (_ *actor.Actor, safeErrMsg string, err error) {
+	cfg := withDefaults(p.config)
+	client, err := newClient(&p.config)
+	if err != nil {
+		return nil, "Misconfigured LDAP auth provider.", err
+	}
+
+	entry, err := client.Authenticate(username, password)
+	if err != nil {
+		return nil, "Unexpected error authenticating with the LDAP server. Ask a site admin for help.", err
+	}
+	groups, err := client.Groups(entry.DN)
+	if err != nil {
+		return nil, "Unexpected error looking up your LDAP groups. Ask a site admin for help.", err
+	}
+
+	// Use the username as stored in the directory, not as typed by the user (LDAP attribute values
+	// are usually compared case-insensitively).
+	rawUsername := entry.Attribute(cfg.UsernameAttribute)
+	login, err := auth.NormalizeUsername(rawUsername)
+	if err != nil {
+		return nil, fmt.Sprintf("Error normalizing the username %q. See https://docs.sourcegraph.com/admin/auth/#username-normalization.", rawUsername), err
+	}
+	email := entry.Attribute(cfg.EmailAttribute)
+	displayName := entry.Attribute(cfg.DisplayNameAttribute)
+
+	var data extsvc.ExternalAccountData
+	data.SetAccountData(&accountData{
+		DN:          entry.DN,
+		Username:    rawUsername,
+		Email:       email,
+		DisplayName: displayName,
+		Groups:      groups,
+	})
+
+	userID, safeErrMsg, err := auth.GetAndSaveUser(ctx, auth.GetAndSaveUserOp{
+		UserProps: db.NewUser{
+			Username: login,
+			Email:    email,
+			// Email addresses in the directory are managed by the directory's administrators.
+			EmailIsVerified: email != "",
+			DisplayName:     displayName,
+		},
+		ExternalAccount: extsvc.ExternalAccountSpec{
+			ServiceType: providerType,
+			ServiceID:   p.config.Url,
+			// Store rawUsername, not the normalized username, to prevent two users with distinct
+			// pre-normalization usernames from being merged into the same normalized username.
+			AccountID: rawUsername,
+		},
+		ExternalAccountData: data,
+		CreateIfNotExist:    true,
+	})
+	if err != nil {
+		return nil, safeErrMsg, err
+	}
+
+	if err := syncOrgMemberships(ctx, userID, cfg.GroupOrgMap, groups); err != nil {
+		return nil, "Unexpected error updating your organization memberships. Ask a site admin for help.", err
+	}
+	return actor.FromUser(userID), "", nil
+}
+
+// syncOrgMemberships adds the user to the orgs of their LDAP groups, and removes them from the other
+// orgs in groupOrgMap.
+func syncOrgMemberships(ctx context.Context, userID int32, groupOrgMap map[string][]string, groups []string) error {
+	join, leave := orgMembershipChanges(groupOrgMap, groups)
+	for _, name := range join {
+		org, err := db.Orgs.GetByName(ctx, name)
+		if _, ok := err.(*db.OrgNotFoundError); ok {
+			log15.Warn("Organization in LDAP groupOrgMap does not exist.", "org", name)
+			continue
+		} else if err != nil {
+			return err
+		}
+		if _, err := db.OrgMembers.GetByOrgIDAndUserID(ctx, org.ID, userID); err == nil {
+			continue // already a member
+		} else if !errcode.IsNotFound(err) {
+			return err
+		}
+		if _, err := db.OrgMembers.Create(ctx, org.ID, userID); err != nil {
+			return err
+		}
+	}
+	for _, name := range leave {
+		org, err := db.Orgs.GetByName(ctx, name)
+		if _, ok := err.(*db.OrgNotFoundError); ok {
+			continue
+		} else if err != nil {
+			return err
+		}
+		if err := db.OrgMembers.Remove(ctx, org.ID, userID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// orgMembershipChanges returns the sorted names of the orgs in groupOrgMap that a member of the
+// given LDAP groups should be a member of (join), and of those they should not be a member of
+// (leave). Group names are compared case-insensitively.
+func orgMembershipChanges(groupOrgMap map[string][]string, groups []string) (join, leave []string) {
+	inGroup := make(map[string]struct{}, len(groups))
+	for _, g := range groups {
+		inGroup[strings.ToLower(g)] = struct{}{}
+	}
+
+	member := map[string]bool{}
+	for group, orgs := range groupOrgMap {
+		_, ok := inGroup[strings.ToLower(group)]
+		for _, org := range orgs {
+			member[org] = member[org] || ok
+		}
+	}
+	for org, ok := range member {
+		if ok {
+			join = append(join, org)
+		} else {
+			leave = append(leave, org)
+		}
+	}
+	sort.Strings(join)
+	sort.Strings(leave)
+	return join, leave
+}
@@ -0,0 +1,48 @@
+package ldap
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_orgMembershipChanges(t *testing.T) {
+	groupOrgMap := map[string][]string{
+		"Engineering": {"eng"},
+		"sre":         {"eng", "ops"},
+		"sales":       {"sales"},
+	}
+	tests := map[string]struct {
+		groups              []string
+		wantJoin, wantLeave []string
+	}{
+		"no groups": {
+			groups:    nil,
+			wantLeave: []string{"eng", "ops", "sales"},
+		},
+		"group names are case-insensitive": {
+			groups:    []string{"engineering"},
+			wantJoin:  []string{"eng"},
+			wantLeave: []string{"ops", "sales"},
+		},
+		"org of any group is joined": {
+			groups:    []string{"sre", "unmapped"},
+			wantJoin:  []string{"eng", "ops"},
+			wantLeave: []string{"sales"},
+		},
+		"all groups": {
+			groups:   []string{"engineering", "sre", "sales"},
+			wantJoin: []string{"eng", "ops", "sales"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			join, leave := orgMembershipChanges(groupOrgMap, test.groups)
+			if !reflect.DeepEqual(join, test.wantJoin) {
+				t.Errorf("got join %v, want %v", join, test.wantJoin)
+			}
+			if !reflect.DeepEqual(leave, test.wantLeave) {
+				t.Errorf("got leave %v, want %v", leave, test.wantLeave)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package authz
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	permbbs "github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/authz/bitbucketserver"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc/bitbucketserver"
+	"github.com/sourcegraph/sourcegraph/schema"
+	"golang.org/x/time/rate"
+)
+
+func bitbucketServerProviders(ctx context.Context) (
+	authzProviders []authz.Provider,
+	seriousProblems []string,
+	warnings []string,
+) {
+	bbss, err := db.ExternalServices.ListBitbucketServerConnections(ctx)
+	if err != nil {
+		seriousProblems = append(seriousProblems, fmt.Sprintf("Could not load Bitbucket Server external service configs: %s", err))
+		return
+	}
+
+	for _, b := range bbss {
+		p, err := bitbucketServerProvider(b)
+		if err != nil {
+			seriousProblems = append(seriousProblems, err.Error())
+			continue
+		}
+		if p != nil {
+			authzProviders = append(authzProviders, p)
+		}
+	}
+	return authzProviders, seriousProblems, warnings
+}
+
+func bitbucketServerProvider(b *schema.BitbucketServerConnection) (authz.Provider, error) {
+	if b.Authorization == nil {
+		return nil, nil
+	}
+
+	bbsURL, err := url.Parse(b.Url)
+	if err != nil {
+		return nil, fmt.Errorf("Could not parse URL for Bitbucket Server instance %q: %s", b.Url, err)
+	}
+
+	ttl, err := parseTTL(b.Authorization.Ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport
+	if b.Certificate != "" {
+		certPool := x509.NewCertPool()
+		if ok := certPool.AppendCertsFromPEM([]byte(b.Certificate)); !ok {
+			return nil, fmt.Errorf("Invalid certificate for Bitbucket Server instance %q.", b.Url)
+		}
+		transport = &http.Transport{TLSClientConfig: &tls.Config{RootCAs: certPool}}
+	}
+
+	client := &bitbucketserver.Client{
+		URL:        bbsURL,
+		Token:      b.Token,
+		Username:   b.Username,
+		Password:   b.Password,
+		HTTPClient: &http.Client{Transport: bitbucketserver.WithRequestCounter(transport)},
+		RateLimit:  bitbucketServerRateLimiter(b.Url),
+	}
+	return permbbs.NewProvider(client, ttl, nil), nil
+}
+
+// Self-imposed rate limit of the Bitbucket Server API requests made by the authz providers (the
+// same as the one used by repo-updater).
+const (
+	bitbucketServerRateLimitRequestsPerSecond = 2
+	bitbucketServerRateLimitMaxBurstRequests  = 500
+)
+
+var (
+	bitbucketServerRateLimitersMu sync.Mutex
+	bitbucketServerRateLimiters   = map[string]*rate.Limiter{}
+)
+
+// bitbucketServerRateLimiter returns the rate limiter for the Bitbucket Server instance. It is
+// shared by all providers for the instance, because providers are recreated whenever the config
+// is reloaded.
+func bitbucketServerRateLimiter(bbsURL string) *rate.Limiter {
+	bitbucketServerRateLimitersMu.Lock()
+	defer bitbucketServerRateLimitersMu.Unlock()
+	l, ok := bitbucketServerRateLimiters[bbsURL]
+	if !ok {
+		l = rate.NewLimiter(bitbucketServerRateLimitRequestsPerSecond, bitbucketServerRateLimitMaxBurstRequests)
+		bitbucketServerRateLimiters[bbsURL] = l
+	}
+	return l
+}
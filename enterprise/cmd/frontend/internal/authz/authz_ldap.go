@@ -0,0 +1,30 @@
+package authz
+
+import (
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/auth/ldap"
+	"github.com/sourcegraph/sourcegraph/pkg/conf"
+)
+
+// ldapProviders returns the authz providers of the LDAP auth providers that have
+// repositoryPermissions.
+func ldapProviders(cfg *conf.Unified) (
+	authzProviders []authz.Provider,
+	seriousProblems []string,
+	warnings []string,
+) {
+	for _, p := range cfg.Critical.AuthProviders {
+		if p.Ldap == nil {
+			continue
+		}
+		ap, err := ldap.NewAuthzProvider(p.Ldap)
+		if err != nil {
+			seriousProblems = append(seriousProblems, err.Error())
+			continue
+		}
+		if ap != nil {
+			authzProviders = append(authzProviders, ap)
+		}
+	}
+	return authzProviders, seriousProblems, warnings
+}
@@ -3,6 +3,7 @@ package authz
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/url"
 	"reflect"
 	"testing"
@@ -12,6 +13,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/authz/explicit"
 	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/authz/gitlab"
 	"github.com/sourcegraph/sourcegraph/pkg/api"
 	"github.com/sourcegraph/sourcegraph/pkg/conf"
@@ -53,6 +55,9 @@ func Test_providersFromConfig(t *testing.T) {
 
 	db.Mocks = db.MockStores{}
 	defer func() { db.Mocks = db.MockStores{} }()
+	db.Mocks.ExplicitRepoPermissions.List = func(ctx context.Context, opt db.ExplicitRepoPermissionsListOptions) ([]*types.ExplicitRepoPermission, error) {
+		return nil, nil
+	}
 
 	tests := []struct {
 		description                  string
@@ -370,6 +375,69 @@ func Test_providersFromConfig(t *testing.T) {
 	}
 }
 
+func Test_providersFromConfig_explicit(t *testing.T) {
+	db.Mocks.ExternalServices.List = func(opt db.ExternalServicesListOptions) ([]*types.ExternalService, error) {
+		return nil, nil
+	}
+	defer func() { db.Mocks = db.MockStores{} }()
+
+	db.Mocks.ExplicitRepoPermissions.List = func(ctx context.Context, opt db.ExplicitRepoPermissionsListOptions) ([]*types.ExplicitRepoPermission, error) {
+		return []*types.ExplicitRepoPermission{{ID: 1, RepoName: "a", UserID: 1}}, nil
+	}
+	allowAccessByDefault, authzProviders, seriousProblems, _ := providersFromConfig(context.Background(), &conf.Unified{})
+	if len(authzProviders) != 1 || !allowAccessByDefault || len(seriousProblems) != 0 {
+		t.Fatalf("got providers %v (allowAccessByDefault %v, problems %v), want only the explicit permissions provider", authzProviders, allowAccessByDefault, seriousProblems)
+	}
+	if _, ok := authzProviders[0].(*explicit.Provider); !ok {
+		t.Errorf("got provider %T, want the explicit permissions provider", authzProviders[0])
+	}
+
+	// 🚨 SECURITY: Test that access is restricted by default if the permissions can't be loaded.
+	db.Mocks.ExplicitRepoPermissions.List = func(ctx context.Context, opt db.ExplicitRepoPermissionsListOptions) ([]*types.ExplicitRepoPermission, error) {
+		return nil, errors.New("x")
+	}
+	allowAccessByDefault, authzProviders, seriousProblems, _ = providersFromConfig(context.Background(), &conf.Unified{})
+	if len(authzProviders) != 0 || allowAccessByDefault || len(seriousProblems) != 1 {
+		t.Errorf("got providers %v (allowAccessByDefault %v, problems %v), want no providers and access restricted by default", authzProviders, allowAccessByDefault, seriousProblems)
+	}
+}
+
+func Test_providersConfig(t *testing.T) {
+	db.Mocks.ExternalServices.List = func(opt db.ExternalServicesListOptions) ([]*types.ExternalService, error) {
+		return nil, nil
+	}
+	db.Mocks.ExplicitRepoPermissions.List = func(ctx context.Context, opt db.ExplicitRepoPermissionsListOptions) ([]*types.ExplicitRepoPermission, error) {
+		return nil, nil
+	}
+	defer func() { db.Mocks = db.MockStores{} }()
+
+	ldapConfig := func(pattern string) *conf.Unified {
+		return &conf.Unified{Critical: schema.CriticalConfiguration{
+			AuthProviders: []schema.AuthProviders{{Ldap: &schema.LDAPAuthProvider{
+				Type: "ldap",
+				Url:  "ldap://ldap.example.com",
+				RepositoryPermissions: &schema.LDAPRepositoryPermissions{
+					Rules: []*schema.LDAPRepositoryPermissionRule{{RepositoryPattern: pattern, Groups: []string{"g"}}},
+				},
+			}}},
+		}}
+	}
+	config := func(cfg *conf.Unified) string {
+		config, err := providersConfig(context.Background(), cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return config
+	}
+
+	if a, b := config(ldapConfig("^a/")), config(ldapConfig("^a/")); a != b {
+		t.Errorf("got different configs %q and %q for the same configuration", a, b)
+	}
+	if a, b := config(ldapConfig("^a/")), config(ldapConfig("^b/")); a == b {
+		t.Errorf("got the same config %q for different LDAP repository permissions", a)
+	}
+}
+
 func mustURLParse(t *testing.T, u string) *url.URL {
 	parsed, err := url.Parse(u)
 	if err != nil {
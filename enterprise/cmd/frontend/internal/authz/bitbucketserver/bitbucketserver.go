@@ -0,0 +1,437 @@
+// Package bitbucketserver implements authz.Provider for Bitbucket Server
+// repository permissions.
+package bitbucketserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/errcode"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc/bitbucketserver"
+	"github.com/sourcegraph/sourcegraph/pkg/rcache"
+)
+
+// pageSize is the number of results to request per page from paged API methods.
+const pageSize = 1000
+
+// Provider implements authz.Provider for Bitbucket Server repository permissions.
+//
+// Bitbucket Server does not let an admin act on behalf of a user, so Provider
+// uses the admin credentials of the client to list the users and groups that
+// have been granted permissions on each project and repository, and the groups
+// that each user is a member of. These are cached separately, so that the
+// permissions of a project or repository are shared by all users.
+type Provider struct {
+	client   *bitbucketserver.Client
+	codeHost *bitbucketserver.CodeHost
+	cacheTTL time.Duration
+	cache    cache
+}
+
+// NewProvider returns a Provider for the Bitbucket Server instance of the
+// client, whose credentials must belong to a Bitbucket Server admin.
+func NewProvider(client *bitbucketserver.Client, cacheTTL time.Duration, mockCache cache) *Provider {
+	p := &Provider{
+		client:   client,
+		codeHost: bitbucketserver.NewCodeHost(client.URL),
+		cacheTTL: cacheTTL,
+		cache:    mockCache,
+	}
+	// Note: this will use the same underlying Redis instance and key namespace for every instance
+	// of Provider.  This is by design, so that different instances, even in different processes,
+	// will share cache entries.
+	if p.cache == nil {
+		p.cache = rcache.NewWithTTL(fmt.Sprintf("bitbucketServerAuthz:%s", p.codeHost.ServiceID()), int(math.Ceil(cacheTTL.Seconds())))
+	}
+	return p
+}
+
+var _ authz.Provider = ((*Provider)(nil))
+
+// Repos implements the authz.Provider interface.
+func (p *Provider) Repos(ctx context.Context, repos map[authz.Repo]struct{}) (mine map[authz.Repo]struct{}, others map[authz.Repo]struct{}) {
+	return authz.GetCodeHostRepos(p.codeHost, repos)
+}
+
+// RepoPerms implements the authz.Provider interface.
+//
+// A user can read a repository if it (or its project) is public, if the user is a global admin, if
+// it is in the user's personal project, or if the user or one of their groups has been granted any
+// permission on it or its project (or the project grants read access to all signed-in users).
+func (p *Provider) RepoPerms(ctx context.Context, userAccount *extsvc.ExternalAccount, repos map[authz.Repo]struct{}) (map[api.RepoName]map[authz.Perm]bool, error) {
+	mine, _ := p.Repos(ctx, repos)
+	if len(mine) == 0 {
+		return nil, nil
+	}
+
+	var (
+		username string
+		groups   map[string]bool
+	)
+	if userAccount != nil {
+		username = strings.ToLower(userAccount.AccountID)
+		groupList, err := p.userGroups(ctx, username)
+		if err != nil {
+			return nil, err
+		}
+		groups = toSet(groupList)
+
+		global, err := p.global(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if isMember(username, groups, global.AdminUsers, global.AdminGroups) {
+			perms := make(map[api.RepoName]map[authz.Perm]bool, len(mine))
+			for repo := range mine {
+				perms[repo.RepoName] = map[authz.Perm]bool{authz.Read: true}
+			}
+			return perms, nil
+		}
+	}
+
+	// The external ID of a Bitbucket Server repository is "{projectKey}/{repoSlug}".
+	var repoIDs, projectKeys []string
+	seenProjects := map[string]bool{}
+	for repo := range mine {
+		repoIDs = append(repoIDs, repo.ExternalRepoSpec.ID)
+		if projectKey, _, ok := splitRepoID(repo.ExternalRepoSpec.ID); ok && !seenProjects[projectKey] {
+			seenProjects[projectKey] = true
+			projectKeys = append(projectKeys, projectKey)
+		}
+	}
+	repoACLs, err := p.repoACLs(ctx, repoIDs)
+	if err != nil {
+		return nil, err
+	}
+	var projectACLs map[string]*aclCacheVal
+	if userAccount != nil {
+		if projectACLs, err = p.projectACLs(ctx, projectKeys); err != nil {
+			return nil, err
+		}
+	}
+
+	perms := make(map[api.RepoName]map[authz.Perm]bool, len(mine))
+	for repo := range mine {
+		repoACL, ok := repoACLs[repo.ExternalRepoSpec.ID]
+		if !ok {
+			continue // the repository no longer exists
+		}
+		canRead := repoACL.Public
+		if !canRead && userAccount != nil {
+			projectKey, _, _ := splitRepoID(repo.ExternalRepoSpec.ID)
+			projectACL := projectACLs[projectKey]
+			canRead = strings.EqualFold(projectKey, "~"+username) || // personal repository
+				projectACL.AllUsers ||
+				isMember(username, groups, projectACL.Users, projectACL.Groups) ||
+				isMember(username, groups, repoACL.Users, repoACL.Groups)
+		}
+		perms[repo.RepoName] = map[authz.Perm]bool{authz.Read: canRead}
+	}
+	return perms, nil
+}
+
+// global returns the users and groups with a global admin permission.
+func (p *Provider) global(ctx context.Context) (*globalCacheVal, error) {
+	if b, ok := p.cache.Get(globalCacheKey); ok {
+		var val globalCacheVal
+		if err := json.Unmarshal(b, &val); err != nil {
+			return nil, err
+		}
+		if val.TTL <= p.cacheTTL {
+			return &val, nil
+		}
+	}
+
+	val := globalCacheVal{TTL: p.cacheTTL}
+	var err error
+	val.AdminUsers, val.AdminGroups, err = p.grantees(
+		ctx,
+		p.client.GlobalUserPermissions,
+		p.client.GlobalGroupPermissions,
+		bitbucketserver.PermAdmin, bitbucketserver.PermSysAdmin,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.set(globalCacheKey, val); err != nil {
+		return nil, err
+	}
+	return &val, nil
+}
+
+// userGroups returns the names of the groups that the user is a member of.
+func (p *Provider) userGroups(ctx context.Context, username string) ([]string, error) {
+	key := userCacheKey(username)
+	if b, ok := p.cache.Get(key); ok {
+		var val userCacheVal
+		if err := json.Unmarshal(b, &val); err != nil {
+			return nil, err
+		}
+		if val.TTL <= p.cacheTTL {
+			return val.Groups, nil
+		}
+	}
+
+	val := userCacheVal{Groups: []string{}, TTL: p.cacheTTL}
+	pageToken := &bitbucketserver.PageToken{Limit: pageSize}
+	for pageToken.HasMore() {
+		groups, next, err := p.client.UserGroups(ctx, username, pageToken)
+		if err != nil {
+			return nil, err
+		}
+		for _, g := range groups {
+			val.Groups = append(val.Groups, strings.ToLower(g.Name))
+		}
+		pageToken = next
+	}
+	if err := p.set(key, val); err != nil {
+		return nil, err
+	}
+	return val.Groups, nil
+}
+
+// projectACLs returns the users and groups that have been granted permissions on each project.
+func (p *Provider) projectACLs(ctx context.Context, projectKeys []string) (map[string]*aclCacheVal, error) {
+	return p.acls(projectKeys, projectCacheKey, func(projectKey string) (*aclCacheVal, error) {
+		val := &aclCacheVal{TTL: p.cacheTTL}
+		var err error
+		val.AllUsers, err = p.client.ProjectDefaultPermission(ctx, projectKey, bitbucketserver.PermProjectRead)
+		if err != nil {
+			return nil, err
+		}
+		val.Users, val.Groups, err = p.grantees(
+			ctx,
+			func(ctx context.Context, t *bitbucketserver.PageToken) ([]*bitbucketserver.UserPermission, *bitbucketserver.PageToken, error) {
+				return p.client.ProjectUserPermissions(ctx, projectKey, t)
+			},
+			func(ctx context.Context, t *bitbucketserver.PageToken) ([]*bitbucketserver.GroupPermission, *bitbucketserver.PageToken, error) {
+				return p.client.ProjectGroupPermissions(ctx, projectKey, t)
+			},
+			bitbucketserver.PermProjectRead, bitbucketserver.PermProjectWrite, bitbucketserver.PermProjectAdmin,
+		)
+		return val, err
+	})
+}
+
+// repoACLs returns whether each repository is public and the users and groups that have been
+// granted permissions on it. Repositories that don't exist are omitted.
+func (p *Provider) repoACLs(ctx context.Context, repoIDs []string) (map[string]*aclCacheVal, error) {
+	return p.acls(repoIDs, repoCacheKey, func(id string) (*aclCacheVal, error) {
+		projectKey, repoSlug, ok := splitRepoID(id)
+		if !ok {
+			return nil, nil
+		}
+		repo, err := p.client.Repo(ctx, projectKey, repoSlug)
+		if errcode.IsNotFound(err) {
+			// Purposefully don't cache that the repository doesn't exist, in case it is
+			// created later.
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+		val := &aclCacheVal{
+			Public: repo.Public || (repo.Project != nil && repo.Project.Public),
+			TTL:    p.cacheTTL,
+		}
+		val.Users, val.Groups, err = p.grantees(
+			ctx,
+			func(ctx context.Context, t *bitbucketserver.PageToken) ([]*bitbucketserver.UserPermission, *bitbucketserver.PageToken, error) {
+				return p.client.RepoUserPermissions(ctx, projectKey, repoSlug, t)
+			},
+			func(ctx context.Context, t *bitbucketserver.PageToken) ([]*bitbucketserver.GroupPermission, *bitbucketserver.PageToken, error) {
+				return p.client.RepoGroupPermissions(ctx, projectKey, repoSlug, t)
+			},
+			bitbucketserver.PermRepoRead, bitbucketserver.PermRepoWrite, bitbucketserver.PermRepoAdmin,
+		)
+		return val, err
+	})
+}
+
+// acls returns the cached ACL for each ID, fetching (and caching) those that are not cached. IDs
+// for which fetch returns nil are omitted.
+func (p *Provider) acls(ids []string, cacheKey func(string) string, fetch func(string) (*aclCacheVal, error)) (map[string]*aclCacheVal, error) {
+	acls := make(map[string]*aclCacheVal, len(ids))
+	if len(ids) == 0 {
+		return acls, nil
+	}
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = cacheKey(id)
+	}
+	for i, b := range p.cache.GetMulti(keys...) {
+		if len(b) == 0 {
+			continue
+		}
+		var val aclCacheVal
+		if err := json.Unmarshal(b, &val); err != nil {
+			return nil, err
+		}
+		if p.cacheTTL < val.TTL {
+			// if the cache TTL is now less than the cache entry TTL, invalidate that entry
+			continue
+		}
+		acls[ids[i]] = &val
+	}
+
+	var setArgs [][2]string
+	for i, id := range ids {
+		if _, ok := acls[id]; ok {
+			continue
+		}
+		val, err := fetch(id)
+		if err != nil {
+			return nil, err
+		}
+		if val == nil {
+			continue
+		}
+		b, err := json.Marshal(val)
+		if err != nil {
+			return nil, err
+		}
+		setArgs = append(setArgs, [2]string{keys[i], string(b)})
+		acls[id] = val
+	}
+	if len(setArgs) > 0 {
+		p.cache.SetMulti(setArgs...)
+	}
+	return acls, nil
+}
+
+// grantees returns the (lowercased) names of the users and groups that have been granted any of
+// the permissions, according to the paged listUsers and listGroups API methods.
+func (p *Provider) grantees(
+	ctx context.Context,
+	listUsers func(context.Context, *bitbucketserver.PageToken) ([]*bitbucketserver.UserPermission, *bitbucketserver.PageToken, error),
+	listGroups func(context.Context, *bitbucketserver.PageToken) ([]*bitbucketserver.GroupPermission, *bitbucketserver.PageToken, error),
+	perms ...bitbucketserver.Perm,
+) (users, groups []string, err error) {
+	isGranted := func(perm bitbucketserver.Perm) bool {
+		for _, p := range perms {
+			if perm == p {
+				return true
+			}
+		}
+		return false
+	}
+
+	users, groups = []string{}, []string{}
+	pageToken := &bitbucketserver.PageToken{Limit: pageSize}
+	for pageToken.HasMore() {
+		userPerms, next, err := listUsers(ctx, pageToken)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, up := range userPerms {
+			if up.User != nil && isGranted(up.Permission) {
+				users = append(users, strings.ToLower(up.User.Name))
+			}
+		}
+		pageToken = next
+	}
+	pageToken = &bitbucketserver.PageToken{Limit: pageSize}
+	for pageToken.HasMore() {
+		groupPerms, next, err := listGroups(ctx, pageToken)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, gp := range groupPerms {
+			if gp.Group != nil && isGranted(gp.Permission) {
+				groups = append(groups, strings.ToLower(gp.Group.Name))
+			}
+		}
+		pageToken = next
+	}
+	return users, groups, nil
+}
+
+func (p *Provider) set(key string, val interface{}) error {
+	b, err := json.Marshal(val)
+	if err != nil {
+		return err
+	}
+	p.cache.Set(key, b)
+	return nil
+}
+
+// FetchAccount implements the authz.Provider interface. It returns the Bitbucket Server user whose
+// username is the same as the Sourcegraph user's username, if any.
+func (p *Provider) FetchAccount(ctx context.Context, user *types.User, current []*extsvc.ExternalAccount) (mine *extsvc.ExternalAccount, err error) {
+	if user == nil {
+		return nil, nil
+	}
+
+	pageToken := &bitbucketserver.PageToken{Limit: pageSize}
+	for pageToken.HasMore() {
+		bbUsers, next, err := p.client.Users(ctx, user.Username, pageToken)
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range bbUsers {
+			if strings.EqualFold(u.Name, user.Username) {
+				return &extsvc.ExternalAccount{
+					UserID: user.ID,
+					ExternalAccountSpec: extsvc.ExternalAccountSpec{
+						ServiceType: p.codeHost.ServiceType(),
+						ServiceID:   p.codeHost.ServiceID(),
+						AccountID:   u.Name,
+					},
+				}, nil
+			}
+		}
+		pageToken = next
+	}
+	return nil, nil
+}
+
+func (p *Provider) ServiceID() string {
+	return p.codeHost.ServiceID()
+}
+
+func (p *Provider) ServiceType() string {
+	return p.codeHost.ServiceType()
+}
+
+func (p *Provider) Validate() (problems []string) {
+	return nil
+}
+
+// splitRepoID splits the external ID of a Bitbucket Server repository ("{projectKey}/{repoSlug}").
+func splitRepoID(id string) (projectKey, repoSlug string, ok bool) {
+	i := strings.Index(id, "/")
+	if i <= 0 || i == len(id)-1 {
+		return "", "", false
+	}
+	return id[:i], id[i+1:], true
+}
+
+// isMember reports whether the user or one of their groups is among the users or groups.
+func isMember(username string, userGroups map[string]bool, users, groups []string) bool {
+	for _, u := range users {
+		if u == username {
+			return true
+		}
+	}
+	for _, g := range groups {
+		if userGroups[g] {
+			return true
+		}
+	}
+	return false
+}
+
+func toSet(strs []string) map[string]bool {
+	set := make(map[string]bool, len(strs))
+	for _, s := range strs {
+		set[s] = true
+	}
+	return set
+}
@@ -0,0 +1,258 @@
+package bitbucketserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc/bitbucketserver"
+	"golang.org/x/time/rate"
+)
+
+// newMockBitbucketServer returns a Bitbucket Server API stand-in and a counter of the requests made
+// to it.
+func newMockBitbucketServer(t *testing.T) (*httptest.Server, *int) {
+	page := func(values ...interface{}) interface{} {
+		if values == nil {
+			values = []interface{}{}
+		}
+		return map[string]interface{}{"size": len(values), "isLastPage": true, "values": values}
+	}
+	user := func(name string, perm bitbucketserver.Perm) interface{} {
+		return bitbucketserver.UserPermission{User: &bitbucketserver.User{Name: name}, Permission: perm}
+	}
+	group := func(name string, perm bitbucketserver.Perm) interface{} {
+		return bitbucketserver.GroupPermission{Group: &bitbucketserver.Group{Name: name}, Permission: perm}
+	}
+	repo := func(public bool) interface{} {
+		return bitbucketserver.Repo{Public: public, Project: &bitbucketserver.Project{}}
+	}
+	responses := map[string]interface{}{
+		"/rest/api/1.0/users?filter=alice":                                page(bitbucketserver.User{Name: "alice2"}, bitbucketserver.User{Name: "Alice"}),
+		"/rest/api/1.0/users?filter=nobody":                               page(),
+		"/rest/api/1.0/admin/permissions/users":                           page(user("admin", bitbucketserver.PermAdmin), user("alice", "LICENSED_USER")),
+		"/rest/api/1.0/admin/permissions/groups":                          page(),
+		"/rest/api/1.0/admin/users/more-members?context=alice":            page(bitbucketserver.Group{Name: "Devs"}),
+		"/rest/api/1.0/admin/users/more-members?context=bob":              page(),
+		"/rest/api/1.0/admin/users/more-members?context=admin":            page(),
+		"/rest/api/1.0/projects/PUB/repos/public":                         repo(true),
+		"/rest/api/1.0/projects/PUB/repos/public/permissions/users":       page(),
+		"/rest/api/1.0/projects/PUB/repos/public/permissions/groups":      page(),
+		"/rest/api/1.0/projects/PUB/permissions/PROJECT_READ/all":         map[string]bool{"permitted": false},
+		"/rest/api/1.0/projects/PUB/permissions/users":                    page(),
+		"/rest/api/1.0/projects/PUB/permissions/groups":                   page(),
+		"/rest/api/1.0/projects/PRJ/repos/private":                        repo(false),
+		"/rest/api/1.0/projects/PRJ/repos/private/permissions/users":      page(),
+		"/rest/api/1.0/projects/PRJ/repos/private/permissions/groups":     page(),
+		"/rest/api/1.0/projects/PRJ/repos/granted":                        repo(false),
+		"/rest/api/1.0/projects/PRJ/repos/granted/permissions/users":      page(user("bob", bitbucketserver.PermRepoWrite)),
+		"/rest/api/1.0/projects/PRJ/repos/granted/permissions/groups":     page(),
+		"/rest/api/1.0/projects/PRJ/permissions/PROJECT_READ/all":         map[string]bool{"permitted": false},
+		"/rest/api/1.0/projects/PRJ/permissions/users":                    page(),
+		"/rest/api/1.0/projects/PRJ/permissions/groups":                   page(group("devs", bitbucketserver.PermProjectRead)),
+		"/rest/api/1.0/projects/OPEN/repos/repo":                          repo(false),
+		"/rest/api/1.0/projects/OPEN/repos/repo/permissions/users":        page(),
+		"/rest/api/1.0/projects/OPEN/repos/repo/permissions/groups":       page(),
+		"/rest/api/1.0/projects/OPEN/permissions/PROJECT_READ/all":        map[string]bool{"permitted": true},
+		"/rest/api/1.0/projects/OPEN/permissions/users":                   page(),
+		"/rest/api/1.0/projects/OPEN/permissions/groups":                  page(),
+		"/rest/api/1.0/projects/~ALICE/repos/personal":                    repo(false),
+		"/rest/api/1.0/projects/~ALICE/repos/personal/permissions/users":  page(),
+		"/rest/api/1.0/projects/~ALICE/repos/personal/permissions/groups": page(),
+		"/rest/api/1.0/projects/~ALICE/permissions/PROJECT_READ/all":      map[string]bool{"permitted": false},
+		"/rest/api/1.0/projects/~ALICE/permissions/users":                 page(),
+		"/rest/api/1.0/projects/~ALICE/permissions/groups":                page(),
+	}
+
+	var count int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		if r.Header.Get("Authorization") != "Bearer admin-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		// Ignore paging parameters.
+		q := r.URL.Query()
+		q.Del("limit")
+		q.Del("start")
+		key := r.URL.Path
+		if len(q) > 0 {
+			key += "?" + q.Encode()
+		}
+		resp, ok := responses[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			t.Error(err)
+		}
+	}))
+	return srv, &count
+}
+
+func newTestProvider(t *testing.T, srvURL string, cacheTTL time.Duration, cache cache) *Provider {
+	u, err := url.Parse(srvURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewProvider(&bitbucketserver.Client{
+		URL:        u,
+		Token:      "admin-token",
+		HTTPClient: http.DefaultClient,
+		RateLimit:  rate.NewLimiter(rate.Inf, 0),
+	}, cacheTTL, cache)
+}
+
+func TestProvider_RepoPerms(t *testing.T) {
+	srv, count := newMockBitbucketServer(t)
+	defer srv.Close()
+	p := newTestProvider(t, srv.URL, 3*time.Hour, make(authz.MockCache))
+
+	serviceID := p.ServiceID()
+	rp := func(id string) authz.Repo {
+		return authz.Repo{
+			RepoName:         api.RepoName(id),
+			ExternalRepoSpec: api.ExternalRepoSpec{ID: id, ServiceType: bitbucketserver.ServiceType, ServiceID: serviceID},
+		}
+	}
+	repos := map[authz.Repo]struct{}{
+		rp("PUB/public"):         {},
+		rp("PRJ/private"):        {},
+		rp("PRJ/granted"):        {},
+		rp("PRJ/missing"):        {},
+		rp("OPEN/repo"):          {},
+		rp("~ALICE/personal"):    {},
+		{RepoName: "other/repo"}: {},
+	}
+	ua := func(name string) *extsvc.ExternalAccount {
+		return &extsvc.ExternalAccount{ExternalAccountSpec: extsvc.ExternalAccountSpec{
+			ServiceType: bitbucketserver.ServiceType,
+			ServiceID:   serviceID,
+			AccountID:   name,
+		}}
+	}
+	perms := func(readable ...string) map[api.RepoName]map[authz.Perm]bool {
+		m := map[api.RepoName]map[authz.Perm]bool{}
+		for _, name := range []string{"PUB/public", "PRJ/private", "PRJ/granted", "OPEN/repo", "~ALICE/personal"} {
+			m[api.RepoName(name)] = map[authz.Perm]bool{authz.Read: false}
+		}
+		for _, name := range readable {
+			m[api.RepoName(name)] = map[authz.Perm]bool{authz.Read: true}
+		}
+		return m
+	}
+
+	tests := []struct {
+		description string
+		userAccount *extsvc.ExternalAccount
+		wantPerms   map[api.RepoName]map[authz.Perm]bool
+	}{
+		{
+			description: "anonymous",
+			wantPerms:   perms("PUB/public"),
+		},
+		{
+			description: "group member and personal project",
+			userAccount: ua("Alice"),
+			wantPerms:   perms("PUB/public", "PRJ/private", "PRJ/granted", "OPEN/repo", "~ALICE/personal"),
+		},
+		{
+			description: "repository permission",
+			userAccount: ua("bob"),
+			wantPerms:   perms("PUB/public", "PRJ/granted", "OPEN/repo"),
+		},
+		{
+			description: "global admin",
+			userAccount: ua("admin"),
+			wantPerms:   perms("PUB/public", "PRJ/private", "PRJ/granted", "PRJ/missing", "OPEN/repo", "~ALICE/personal"),
+		},
+	}
+	for i := 0; i < 2; i++ { // run twice for cache coherency
+		for _, test := range tests {
+			*count = 0
+			gotPerms, err := p.RepoPerms(context.Background(), test.userAccount, repos)
+			if err != nil {
+				t.Fatalf("%s: %s", test.description, err)
+			}
+			if !reflect.DeepEqual(gotPerms, test.wantPerms) {
+				t.Errorf("%s: got perms %v, want %v", test.description, gotPerms, test.wantPerms)
+			}
+			// Only the missing repository is not cached.
+			if i == 1 && *count > 1 {
+				t.Errorf("%s: got %d requests, expected entries to be cached", test.description, *count)
+			}
+		}
+	}
+}
+
+func TestProvider_RepoPerms_cacheTTL(t *testing.T) {
+	srv, count := newMockBitbucketServer(t)
+	defer srv.Close()
+	cache := make(authz.MockCache)
+	p := newTestProvider(t, srv.URL, 3*time.Hour, cache)
+	repos := map[authz.Repo]struct{}{
+		{RepoName: "r", ExternalRepoSpec: api.ExternalRepoSpec{ID: "PRJ/granted", ServiceType: bitbucketserver.ServiceType, ServiceID: p.ServiceID()}}: {},
+	}
+	account := &extsvc.ExternalAccount{ExternalAccountSpec: extsvc.ExternalAccountSpec{AccountID: "bob"}}
+
+	if _, err := p.RepoPerms(context.Background(), account, repos); err != nil {
+		t.Fatal(err)
+	}
+	*count = 0
+	if _, err := p.RepoPerms(context.Background(), account, repos); err != nil {
+		t.Fatal(err)
+	}
+	if *count != 0 {
+		t.Errorf("got %d requests, expected entries to be cached", *count)
+	}
+
+	// Entries cached with a longer TTL than the current one are refetched.
+	p = newTestProvider(t, srv.URL, time.Hour, cache)
+	if _, err := p.RepoPerms(context.Background(), account, repos); err != nil {
+		t.Fatal(err)
+	}
+	if *count == 0 {
+		t.Error("expected entries to be refetched")
+	}
+}
+
+func TestProvider_FetchAccount(t *testing.T) {
+	srv, _ := newMockBitbucketServer(t)
+	defer srv.Close()
+	p := newTestProvider(t, srv.URL, 3*time.Hour, make(authz.MockCache))
+
+	acct, err := p.FetchAccount(context.Background(), &types.User{ID: 1, Username: "alice"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &extsvc.ExternalAccount{
+		UserID: 1,
+		ExternalAccountSpec: extsvc.ExternalAccountSpec{
+			ServiceType: bitbucketserver.ServiceType,
+			ServiceID:   strings.TrimSuffix(srv.URL, "/") + "/",
+			AccountID:   "Alice",
+		},
+	}
+	if !reflect.DeepEqual(acct, want) {
+		t.Errorf("got account %+v, want %+v", acct, want)
+	}
+
+	acct, err = p.FetchAccount(context.Background(), &types.User{ID: 2, Username: "nobody"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if acct != nil {
+		t.Errorf("got account %+v, want nil", acct)
+	}
+}
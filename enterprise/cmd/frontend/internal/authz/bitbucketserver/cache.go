@@ -0,0 +1,59 @@
+package bitbucketserver
+
+import (
+	"time"
+)
+
+// cache describes the shape of the permissions cache that Provider uses internally.
+type cache interface {
+	GetMulti(keys ...string) [][]byte
+	SetMulti(keyvals ...[2]string)
+	Get(key string) ([]byte, bool)
+	Set(key string, b []byte)
+	Delete(key string)
+}
+
+// globalCacheKey is the cache key of the users and groups that have a global
+// admin permission, which grants read access to all repositories.
+const globalCacheKey = "g"
+
+type globalCacheVal struct {
+	AdminUsers  []string
+	AdminGroups []string
+	TTL         time.Duration
+}
+
+func userCacheKey(username string) string {
+	return "u:" + username
+}
+
+// userCacheVal is a Bitbucket Server user's group memberships.
+type userCacheVal struct {
+	Groups []string
+	TTL    time.Duration
+}
+
+func projectCacheKey(projectKey string) string {
+	return "p:" + projectKey
+}
+
+// repoCacheKey returns the cache key of a repository, given its external ID.
+func repoCacheKey(repoID string) string {
+	return "r:" + repoID
+}
+
+// aclCacheVal lists the users and groups that have been granted any permission
+// on a project or repository.
+type aclCacheVal struct {
+	// Public is whether anyone (even anonymous users) may read the repository
+	// (because it or its project is public). It is only set for repositories.
+	Public bool
+
+	// AllUsers is whether all signed-in users may read the project. It is only
+	// set for projects.
+	AllUsers bool
+
+	Users  []string
+	Groups []string
+	TTL    time.Duration
+}
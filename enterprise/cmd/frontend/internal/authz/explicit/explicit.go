@@ -0,0 +1,168 @@
+// Package explicit implements the authz provider for the explicit repository permissions that
+// site admins grant to users and organizations (see db.ExplicitRepoPermissions).
+package explicit
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc"
+)
+
+// serviceID is the ServiceID of the provider and its external accounts.
+const serviceID = "explicit"
+
+// Provider is an authz.Provider that claims the repositories that any explicit permission applies
+// to, and grants read access to them to the users (and members of the organizations) that the
+// permissions are for.
+//
+// The permissions are loaded from the database when the provider is created, which happens
+// whenever the authz providers are updated (every few seconds).
+//
+// Users are identified by external accounts whose AccountID is their Sourcegraph user ID. Such
+// accounts are computed by FetchAccount and never saved.
+type Provider struct {
+	perms *permissions
+}
+
+var _ authz.Provider = (*Provider)(nil)
+
+// NewProvider returns the authz provider for the current explicit repository permissions, or nil
+// if there are none.
+func NewProvider(ctx context.Context) (*Provider, error) {
+	perms, err := loadPermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(perms.repos) == 0 && len(perms.patterns) == 0 {
+		return nil, nil
+	}
+	return &Provider{perms: perms}, nil
+}
+
+// permissions is the set of explicit permissions.
+type permissions struct {
+	repos    map[api.RepoName][]grantee // the grantees of permissions on specific repositories
+	patterns []patternPermission
+}
+
+// grantee is a user or an organization (exactly one of the fields is set).
+type grantee struct {
+	userID int32
+	orgID  int32
+}
+
+type patternPermission struct {
+	pattern *regexp.Regexp
+	grantee
+}
+
+func loadPermissions(ctx context.Context) (*permissions, error) {
+	perms, err := db.ExplicitRepoPermissions.List(ctx, db.ExplicitRepoPermissionsListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	p := &permissions{repos: make(map[api.RepoName][]grantee)}
+	for _, perm := range perms {
+		g := grantee{userID: perm.UserID, orgID: perm.OrgID}
+		if perm.RepoPattern == "" {
+			p.repos[perm.RepoName] = append(p.repos[perm.RepoName], g)
+			continue
+		}
+		pattern, err := regexp.Compile(perm.RepoPattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid repository pattern %q of explicit repository permission %d: %s", perm.RepoPattern, perm.ID, err)
+		}
+		p.patterns = append(p.patterns, patternPermission{pattern: pattern, grantee: g})
+	}
+	return p, nil
+}
+
+func (p *permissions) grantees(repo api.RepoName) []grantee {
+	grantees := p.repos[repo]
+	for _, pp := range p.patterns {
+		if pp.pattern.MatchString(string(repo)) {
+			grantees = append(grantees, pp.grantee)
+		}
+	}
+	return grantees
+}
+
+// Repos implements authz.Provider. It claims the repositories that any permission applies to.
+func (p *Provider) Repos(ctx context.Context, repos map[authz.Repo]struct{}) (mine map[authz.Repo]struct{}, others map[authz.Repo]struct{}) {
+	mine, others = make(map[authz.Repo]struct{}), make(map[authz.Repo]struct{})
+	for repo := range repos {
+		if len(p.perms.grantees(repo.RepoName)) > 0 {
+			mine[repo] = struct{}{}
+		} else {
+			others[repo] = struct{}{}
+		}
+	}
+	return mine, others
+}
+
+// RepoPerms implements authz.Provider. The user may read a repository if a permission on it was
+// granted to them or to an organization they are a member of. Anonymous users may not read any.
+func (p *Provider) RepoPerms(ctx context.Context, account *extsvc.ExternalAccount, repos map[authz.Repo]struct{}) (map[api.RepoName]map[authz.Perm]bool, error) {
+	perms := make(map[api.RepoName]map[authz.Perm]bool)
+	if account == nil || len(repos) == 0 {
+		return perms, nil
+	}
+	if account.ServiceType != p.ServiceType() || account.ServiceID != p.ServiceID() {
+		return nil, fmt.Errorf("explicit permissions authz provider can't compute permissions of external account of service %s %q", account.ServiceType, account.ServiceID)
+	}
+	userID, err := strconv.ParseInt(account.AccountID, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID %q of external account: %s", account.AccountID, err)
+	}
+
+	orgs, err := db.Orgs.GetByUserID(ctx, int32(userID))
+	if err != nil {
+		return nil, err
+	}
+	member := make(map[int32]struct{}, len(orgs))
+	for _, org := range orgs {
+		member[org.ID] = struct{}{}
+	}
+
+	for repo := range repos {
+		for _, g := range p.perms.grantees(repo.RepoName) {
+			_, isMember := member[g.orgID]
+			if (g.userID != 0 && g.userID == int32(userID)) || (g.orgID != 0 && isMember) {
+				perms[repo.RepoName] = map[authz.Perm]bool{authz.Read: true}
+				break
+			}
+		}
+	}
+	return perms, nil
+}
+
+// FetchAccount implements authz.Provider. It returns an account that identifies the user by their
+// Sourcegraph user ID.
+func (p *Provider) FetchAccount(ctx context.Context, user *types.User, current []*extsvc.ExternalAccount) (mine *extsvc.ExternalAccount, err error) {
+	return &extsvc.ExternalAccount{
+		UserID: user.ID,
+		ExternalAccountSpec: extsvc.ExternalAccountSpec{
+			ServiceType: p.ServiceType(),
+			ServiceID:   p.ServiceID(),
+			AccountID:   strconv.Itoa(int(user.ID)),
+		},
+	}, nil
+}
+
+// ServiceType implements authz.Provider.
+func (p *Provider) ServiceType() string { return authz.SourcegraphServiceType }
+
+// ServiceID implements authz.Provider.
+func (p *Provider) ServiceID() string { return serviceID }
+
+// Validate implements authz.Provider. The permissions are validated when they are created.
+func (p *Provider) Validate() (problems []string) {
+	return nil
+}
@@ -0,0 +1,113 @@
+package explicit
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc"
+)
+
+func TestProvider(t *testing.T) {
+	const (
+		alice int32 = 1
+		bob   int32 = 2
+		eng   int32 = 10
+	)
+	db.Mocks.ExplicitRepoPermissions.List = func(ctx context.Context, opt db.ExplicitRepoPermissionsListOptions) ([]*types.ExplicitRepoPermission, error) {
+		return []*types.ExplicitRepoPermission{
+			{ID: 1, RepoID: 100, RepoName: "gitolite.example.com/alice", UserID: alice},
+			{ID: 2, RepoPattern: "^gitolite\\.example\\.com/eng/", OrgID: eng},
+		}, nil
+	}
+	db.Mocks.Orgs.GetByUserID = func(ctx context.Context, userID int32) ([]*types.Org, error) {
+		if userID == bob {
+			return []*types.Org{{ID: eng}}, nil
+		}
+		return nil, nil
+	}
+	defer func() { db.Mocks = db.MockStores{} }()
+
+	var (
+		public  = authz.Repo{RepoName: "github.com/foo/bar"}
+		aliceR  = authz.Repo{RepoName: "gitolite.example.com/alice"}
+		engRepo = authz.Repo{RepoName: "gitolite.example.com/eng/web"}
+	)
+	p, err := NewProvider(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	mine, others := p.Repos(context.Background(), map[authz.Repo]struct{}{public: {}, aliceR: {}, engRepo: {}})
+	if want := map[authz.Repo]struct{}{aliceR: {}, engRepo: {}}; !reflect.DeepEqual(mine, want) {
+		t.Errorf("got mine %v, want %v", mine, want)
+	}
+	if want := map[authz.Repo]struct{}{public: {}}; !reflect.DeepEqual(others, want) {
+		t.Errorf("got others %v, want %v", others, want)
+	}
+
+	account := func(userID int32) *extsvc.ExternalAccount {
+		acct, err := p.FetchAccount(context.Background(), &types.User{ID: userID}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return acct
+	}
+	read := map[authz.Perm]bool{authz.Read: true}
+	tests := map[string]struct {
+		account   *extsvc.ExternalAccount
+		wantPerms map[api.RepoName]map[authz.Perm]bool
+	}{
+		"anonymous": {
+			account:   nil,
+			wantPerms: map[api.RepoName]map[authz.Perm]bool{},
+		},
+		"user permission": {
+			account:   account(alice),
+			wantPerms: map[api.RepoName]map[authz.Perm]bool{aliceR.RepoName: read},
+		},
+		"organization permission": {
+			account:   account(bob),
+			wantPerms: map[api.RepoName]map[authz.Perm]bool{engRepo.RepoName: read},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			perms, err := p.RepoPerms(context.Background(), test.account, mine)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(perms, test.wantPerms) {
+				t.Errorf("got perms %v, want %v", perms, test.wantPerms)
+			}
+		})
+	}
+
+	other := &extsvc.ExternalAccount{ExternalAccountSpec: extsvc.ExternalAccountSpec{ServiceType: "gitlab", ServiceID: "https://gitlab.example.com/", AccountID: "1"}}
+	if _, err := p.RepoPerms(context.Background(), other, mine); err == nil {
+		t.Error("got nil error for account of other service, want error")
+	}
+}
+
+func TestNewProvider(t *testing.T) {
+	defer func() { db.Mocks = db.MockStores{} }()
+
+	// No provider is needed (and repositories are not claimed) if there are no permissions.
+	db.Mocks.ExplicitRepoPermissions.List = func(ctx context.Context, opt db.ExplicitRepoPermissionsListOptions) ([]*types.ExplicitRepoPermission, error) {
+		return nil, nil
+	}
+	if p, err := NewProvider(context.Background()); p != nil || err != nil {
+		t.Errorf("got provider %v (error %v), want none", p, err)
+	}
+
+	db.Mocks.ExplicitRepoPermissions.List = func(ctx context.Context, opt db.ExplicitRepoPermissionsListOptions) ([]*types.ExplicitRepoPermission, error) {
+		return nil, errors.New("x")
+	}
+	if _, err := NewProvider(context.Background()); err == nil {
+		t.Error("got nil error, want error")
+	}
+}
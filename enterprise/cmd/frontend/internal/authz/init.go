@@ -2,6 +2,7 @@ package authz
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -11,6 +12,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
+	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/authz/explicit"
 	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/licensing"
 	"github.com/sourcegraph/sourcegraph/pkg/conf"
 	log15 "gopkg.in/inconshreveable/log15.v2"
@@ -59,10 +61,42 @@ func init() {
 			}
 		}
 
+		bbss, err := db.ExternalServices.ListBitbucketServerConnections(ctx)
+		if err != nil {
+			return []*graphqlbackend.Alert{{
+				TypeValue:    graphqlbackend.AlertTypeError,
+				MessageValue: fmt.Sprintf("Unable to fetch Bitbucket Server external services: %s", err),
+			}}
+		}
+		for _, b := range bbss {
+			if b.Authorization != nil {
+				authzTypes = append(authzTypes, "Bitbucket Server")
+				break
+			}
+		}
+
+		for _, p := range conf.Get().Critical.AuthProviders {
+			if p.Ldap != nil && p.Ldap.RepositoryPermissions != nil {
+				authzTypes = append(authzTypes, "LDAP")
+				break
+			}
+		}
+
+		explicitPerms, err := db.ExplicitRepoPermissions.Count(ctx, db.ExplicitRepoPermissionsListOptions{})
+		if err != nil {
+			return []*graphqlbackend.Alert{{
+				TypeValue:    graphqlbackend.AlertTypeError,
+				MessageValue: fmt.Sprintf("Unable to count explicit repository permissions: %s", err),
+			}}
+		}
+		if explicitPerms > 0 {
+			authzTypes = append(authzTypes, "explicit repository permissions")
+		}
+
 		if len(authzTypes) > 0 {
 			return []*graphqlbackend.Alert{{
 				TypeValue:    graphqlbackend.AlertTypeError,
-				MessageValue: fmt.Sprintf("A Sourcegraph license is required to enable repository permissions for the following code hosts: %s. [**Get a license.**](/site-admin/license)", strings.Join(authzTypes, ", ")),
+				MessageValue: fmt.Sprintf("A Sourcegraph license is required to enable repository permissions from the following sources: %s. [**Get a license.**](/site-admin/license)", strings.Join(authzTypes, ", ")),
 			}}
 		}
 		return nil
@@ -79,13 +113,62 @@ func init() {
 	ctx := context.Background()
 	go func() {
 		t := time.NewTicker(5 * time.Second)
+		var lastConfig string
 		for range t.C {
-			allowAccessByDefault, authzProviders, _, _ := providersFromConfig(ctx, conf.Get())
+			// Read the configuration before deriving the providers from it, so that the providers
+			// reflect at least this configuration.
+			cfg := conf.Get()
+			config, err := providersConfig(ctx, cfg)
+			if err != nil {
+				log15.Error("Unable to read repository authz config.", "error", err)
+			}
+
+			allowAccessByDefault, authzProviders, _, _ := providersFromConfig(ctx, cfg)
 			authz.SetProviders(allowAccessByDefault, authzProviders)
+
+			// Repositories that a provider newly became the source of permissions for are
+			// inaccessible to users until their permissions are synced again, so resync all users
+			// when the configuration changes. Every frontend requests the resync after it set the
+			// new providers, so the resync also happens after the permissions sync worker (which
+			// runs on any one of them) uses the new providers.
+			if err != nil || config == lastConfig {
+				continue
+			}
+			if lastConfig != "" {
+				log15.Info("Repository authz config changed. Syncing the repository permissions of all users.")
+				if err := db.UserPermissions.RequestSyncAll(ctx); err != nil {
+					log15.Error("Unable to request repository permissions sync of all users.", "error", err)
+					continue
+				}
+			}
+			lastConfig = config
 		}
 	}()
 }
 
+// providersConfig returns the configuration that the authz providers are derived from, in a form
+// that is only useful to check whether it changed.
+func providersConfig(ctx context.Context, cfg *conf.Unified) (string, error) {
+	githubs, err := db.ExternalServices.ListGitHubConnections(ctx)
+	if err != nil {
+		return "", err
+	}
+	gitlabs, err := db.ExternalServices.ListGitLabConnections(ctx)
+	if err != nil {
+		return "", err
+	}
+	bbss, err := db.ExternalServices.ListBitbucketServerConnections(ctx)
+	if err != nil {
+		return "", err
+	}
+	explicitPerms, err := db.ExplicitRepoPermissions.List(ctx, db.ExplicitRepoPermissionsListOptions{})
+	if err != nil {
+		return "", err
+	}
+	b, err := json.Marshal([]interface{}{cfg.Critical.AuthProviders, githubs, gitlabs, bbss, explicitPerms})
+	return string(b), err
+}
+
 // providersFromConfig returns the set of permission-related providers derived from the site config.
 // It also returns any validation problems with the config, separating these into "serious problems"
 // and "warnings".  "Serious problems" are those that should make Sourcegraph set
@@ -114,5 +197,29 @@ func providersFromConfig(ctx context.Context, cfg *conf.Unified) (
 	seriousProblems = append(seriousProblems, ghproblems...)
 	warnings = append(warnings, ghwarnings...)
 
+	bbsp, bbsproblems, bbswarnings := bitbucketServerProviders(ctx)
+	authzProviders = append(authzProviders, bbsp...)
+	seriousProblems = append(seriousProblems, bbsproblems...)
+	warnings = append(warnings, bbswarnings...)
+
+	// LDAP providers come last, so that repositories whose permissions come from their code host
+	// are not affected by LDAP rules.
+	ldapp, ldapproblems, ldapwarnings := ldapProviders(cfg)
+	authzProviders = append(authzProviders, ldapp...)
+	seriousProblems = append(seriousProblems, ldapproblems...)
+	warnings = append(warnings, ldapwarnings...)
+
+	// Explicit permissions come last, so that they only apply to repositories that no other authz
+	// provider is the source of permissions for (such as those of Gitolite or "Other" external
+	// services). There is no provider if there are no explicit permissions.
+	explicitp, err := explicit.NewProvider(ctx)
+	if err != nil {
+		// 🚨 SECURITY: Restrict access by default, so that the repositories that the permissions
+		// apply to don't become accessible to all users.
+		seriousProblems = append(seriousProblems, fmt.Sprintf("Could not load explicit repository permissions: %s", err))
+	} else if explicitp != nil {
+		authzProviders = append(authzProviders, explicitp)
+	}
+
 	return allowAccessByDefault, authzProviders, seriousProblems, warnings
 }
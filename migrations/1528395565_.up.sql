@@ -0,0 +1,10 @@
+CREATE TABLE repo_language_stats (
+	"id" bigserial NOT NULL PRIMARY KEY,
+	"repo_id" integer NOT NULL REFERENCES repo(id) ON DELETE CASCADE,
+	"commit_id" text NOT NULL,
+	"language" text NOT NULL,
+	"total_bytes" bigint NOT NULL,
+	"recorded_at" TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+CREATE INDEX repo_language_stats_repo_id_recorded_at ON repo_language_stats(repo_id, recorded_at);
+CREATE INDEX repo_language_stats_recorded_at ON repo_language_stats(recorded_at);
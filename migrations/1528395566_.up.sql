@@ -0,0 +1,5 @@
+CREATE TABLE saved_query_snapshots (
+	"query" text NOT NULL PRIMARY KEY,
+	"matches" jsonb NOT NULL,
+	"updated_at" TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS saved_query_webhook_deliveries;
@@ -0,0 +1,15 @@
+CREATE TABLE saved_query_webhook_deliveries (
+	"id" bigserial NOT NULL PRIMARY KEY,
+	"subject" text NOT NULL,
+	"saved_query_key" text NOT NULL,
+	"delivery_id" text NOT NULL,
+	"event" text NOT NULL,
+	"url" text NOT NULL,
+	"attempt" integer NOT NULL,
+	"status_code" integer,
+	"error" text,
+	"duration_ms" integer NOT NULL,
+	"created_at" TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+CREATE INDEX saved_query_webhook_deliveries_subject_saved_query_key ON saved_query_webhook_deliveries(subject, saved_query_key, created_at);
+CREATE INDEX saved_query_webhook_deliveries_created_at ON saved_query_webhook_deliveries(created_at);
@@ -0,0 +1,12 @@
+CREATE TABLE saved_query_runs (
+	"id" bigserial NOT NULL PRIMARY KEY,
+	"query" text NOT NULL,
+	"executed_at" TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+	"exec_duration_ns" bigint NOT NULL,
+	"result_count" integer NOT NULL,
+	"limit_hit" boolean NOT NULL,
+	"error" text,
+	"new_results" jsonb NOT NULL
+);
+CREATE INDEX saved_query_runs_query_executed_at ON saved_query_runs(query, executed_at);
+CREATE INDEX saved_query_runs_executed_at ON saved_query_runs(executed_at);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS insight_series_points;
@@ -0,0 +1,10 @@
+CREATE TABLE insight_series_points (
+	"id" bigserial NOT NULL PRIMARY KEY,
+	"query" text NOT NULL,
+	"repo_id" integer NOT NULL REFERENCES repo(id) ON DELETE CASCADE,
+	"time" TIMESTAMP WITH TIME ZONE NOT NULL,
+	"commit_id" text,
+	"value" integer NOT NULL,
+	"recorded_at" TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+CREATE UNIQUE INDEX insight_series_points_query_repo_id_time ON insight_series_points(query, repo_id, time);
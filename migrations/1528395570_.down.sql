@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS saved_query_digest_items;
+DROP TABLE IF EXISTS saved_query_notification_preferences;
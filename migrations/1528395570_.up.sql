@@ -0,0 +1,27 @@
+CREATE TABLE saved_query_notification_preferences (
+	"id" bigserial NOT NULL PRIMARY KEY,
+	"user_id" integer NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	"subject" text NOT NULL,
+	"saved_query_key" text NOT NULL,
+	"frequency" text,
+	"unsubscribed" boolean NOT NULL DEFAULT false,
+	"updated_at" TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+CREATE UNIQUE INDEX saved_query_notification_preferences_user_id_subject_saved_query_key ON saved_query_notification_preferences(user_id, subject, saved_query_key);
+CREATE INDEX saved_query_notification_preferences_subject_saved_query_key ON saved_query_notification_preferences(subject, saved_query_key);
+
+CREATE TABLE saved_query_digest_items (
+	"id" bigserial NOT NULL PRIMARY KEY,
+	"subject" text NOT NULL,
+	"saved_query_key" text NOT NULL,
+	"recipient_user_id" integer REFERENCES users(id) ON DELETE CASCADE,
+	"recipient_org_id" integer REFERENCES orgs(id) ON DELETE CASCADE,
+	"channel" text NOT NULL,
+	"frequency" text NOT NULL,
+	"description" text NOT NULL,
+	"query" text NOT NULL,
+	"added_count" integer NOT NULL,
+	"removed_count" integer NOT NULL,
+	"created_at" TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+CREATE INDEX saved_query_digest_items_created_at ON saved_query_digest_items(created_at);
@@ -0,0 +1,5 @@
+BEGIN;
+ALTER TABLE saved_query_notification_preferences DROP COLUMN subscribed;
+ALTER TABLE saved_query_notification_preferences DROP COLUMN notify_email;
+ALTER TABLE saved_query_notification_preferences DROP COLUMN notify_slack;
+END;
@@ -0,0 +1,5 @@
+BEGIN;
+ALTER TABLE saved_query_notification_preferences ADD COLUMN subscribed boolean NOT NULL DEFAULT false;
+ALTER TABLE saved_query_notification_preferences ADD COLUMN notify_email boolean;
+ALTER TABLE saved_query_notification_preferences ADD COLUMN notify_slack boolean;
+END;
@@ -0,0 +1,15 @@
+CREATE TABLE discussion_code_host_comments (
+	"id" bigserial NOT NULL PRIMARY KEY,
+	"thread_id" bigint NOT NULL REFERENCES discussion_threads(id) ON DELETE CASCADE,
+	"comment_id" bigint NOT NULL REFERENCES discussion_comments(id) ON DELETE CASCADE,
+	"service_type" text NOT NULL,
+	"service_id" text NOT NULL,
+	"external_repo_id" text NOT NULL,
+	"pull_request_number" integer NOT NULL,
+	"external_thread_id" text NOT NULL,
+	"external_comment_id" text NOT NULL,
+	"created_at" TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+CREATE UNIQUE INDEX discussion_code_host_comments_comment_id ON discussion_code_host_comments(comment_id);
+CREATE UNIQUE INDEX discussion_code_host_comments_external_comment_id ON discussion_code_host_comments(service_type, service_id, external_comment_id);
+CREATE INDEX discussion_code_host_comments_thread_id ON discussion_code_host_comments(thread_id);
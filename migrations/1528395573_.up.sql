@@ -0,0 +1,11 @@
+ALTER TABLE discussion_threads ADD COLUMN resolved_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE discussion_threads ADD COLUMN labels text[] NOT NULL DEFAULT '{}';
+CREATE INDEX discussion_threads_labels_idx ON discussion_threads USING GIN (labels);
+
+CREATE TABLE discussion_thread_assignees (
+	"thread_id" bigint NOT NULL REFERENCES discussion_threads(id) ON DELETE CASCADE,
+	"user_id" integer NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	"created_at" TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+	PRIMARY KEY (thread_id, user_id)
+);
+CREATE INDEX discussion_thread_assignees_user_id_idx ON discussion_thread_assignees(user_id);
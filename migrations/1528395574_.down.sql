@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS discussion_comment_reactions;
+DROP INDEX IF EXISTS discussion_comments_parent_comment_id_idx;
+ALTER TABLE discussion_comments DROP COLUMN IF EXISTS resolved_at;
+ALTER TABLE discussion_comments DROP COLUMN IF EXISTS parent_comment_id;
@@ -0,0 +1,12 @@
+ALTER TABLE discussion_comments ADD COLUMN parent_comment_id bigint REFERENCES discussion_comments(id) ON DELETE CASCADE;
+ALTER TABLE discussion_comments ADD COLUMN resolved_at TIMESTAMP WITH TIME ZONE;
+CREATE INDEX discussion_comments_parent_comment_id_idx ON discussion_comments(parent_comment_id);
+
+CREATE TABLE discussion_comment_reactions (
+	"comment_id" bigint NOT NULL REFERENCES discussion_comments(id) ON DELETE CASCADE,
+	"user_id" integer NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	"emoji" text NOT NULL,
+	"created_at" TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+	PRIMARY KEY (comment_id, user_id, emoji)
+);
+CREATE INDEX discussion_comment_reactions_user_id_idx ON discussion_comment_reactions(user_id);
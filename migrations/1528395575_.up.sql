@@ -0,0 +1,8 @@
+CREATE TABLE user_permissions (
+	"user_id" integer PRIMARY KEY,
+	"read_repo_ids" integer[] NOT NULL DEFAULT '{}',
+	"synced_at" TIMESTAMP WITH TIME ZONE,
+	"sync_attempted_at" TIMESTAMP WITH TIME ZONE,
+	"sync_requested_at" TIMESTAMP WITH TIME ZONE,
+	"sync_error" text
+);
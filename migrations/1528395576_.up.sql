@@ -0,0 +1,2 @@
+ALTER TABLE users ADD COLUMN deactivated_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE users ADD COLUMN invalidated_sessions_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS explicit_repo_permissions;
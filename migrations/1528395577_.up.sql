@@ -0,0 +1,12 @@
+CREATE TABLE explicit_repo_permissions (
+	"id" bigserial PRIMARY KEY,
+	"repo_id" integer REFERENCES repo(id) ON DELETE CASCADE,
+	"repo_pattern" text,
+	"user_id" integer REFERENCES users(id) ON DELETE CASCADE,
+	"org_id" integer REFERENCES orgs(id) ON DELETE CASCADE,
+	"created_at" TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+	CONSTRAINT explicit_repo_permissions_repo CHECK ((repo_id IS NULL) <> (repo_pattern IS NULL)),
+	CONSTRAINT explicit_repo_permissions_subject CHECK ((user_id IS NULL) <> (org_id IS NULL))
+);
+CREATE INDEX explicit_repo_permissions_user_id ON explicit_repo_permissions(user_id);
+CREATE INDEX explicit_repo_permissions_org_id ON explicit_repo_permissions(org_id);
@@ -0,0 +1,3 @@
+DELETE FROM discussion_code_host_comments WHERE external_thread_id IS NULL OR external_comment_id IS NULL;
+ALTER TABLE discussion_code_host_comments ALTER COLUMN external_thread_id SET NOT NULL;
+ALTER TABLE discussion_code_host_comments ALTER COLUMN external_comment_id SET NOT NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE discussion_code_host_comments ALTER COLUMN external_thread_id DROP NOT NULL;
+ALTER TABLE discussion_code_host_comments ALTER COLUMN external_comment_id DROP NOT NULL;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS discussion_threads_import_id_idx;
+ALTER TABLE discussion_threads DROP COLUMN IF EXISTS import_id;
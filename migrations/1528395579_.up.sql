@@ -0,0 +1,2 @@
+ALTER TABLE discussion_threads ADD COLUMN import_id text;
+CREATE UNIQUE INDEX discussion_threads_import_id_idx ON discussion_threads(import_id) WHERE deleted_at IS NULL;
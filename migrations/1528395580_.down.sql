@@ -0,0 +1,3 @@
+UPDATE saved_query_runs SET result_count=new_result_count WHERE result_count IS NULL;
+ALTER TABLE saved_query_runs ALTER COLUMN result_count SET NOT NULL;
+ALTER TABLE saved_query_runs DROP COLUMN new_result_count;
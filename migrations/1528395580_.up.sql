@@ -0,0 +1,5 @@
+ALTER TABLE saved_query_runs ADD COLUMN new_result_count integer NOT NULL DEFAULT 0;
+ALTER TABLE saved_query_runs ALTER COLUMN result_count DROP NOT NULL;
+-- Diff and commit searches only search for new results, so their result
+-- counts were new result counts.
+UPDATE saved_query_runs SET new_result_count=result_count, result_count=NULL WHERE query LIKE '%type:diff%' OR query LIKE '%type:commit%';
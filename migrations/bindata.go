@@ -192,6 +192,38 @@
 // 1528395563_.up.sql (181B)
 // 1528395564_.down.sql (0)
 // 1528395564_.up.sql (0)
+// 1528395565_.down.sql (42B)
+// 1528395565_.up.sql (474B)
+// 1528395566_.down.sql (44B)
+// 1528395566_.up.sql (165B)
+// 1528395567_.down.sql (53B)
+// 1528395567_.up.sql (629B)
+// 1528395568_.down.sql (39B)
+// 1528395568_.up.sql (473B)
+// 1528395569_.down.sql (44B)
+// 1528395569_.up.sql (430B)
+// 1528395570_.down.sql (106B)
+// 1528395570_.up.sql (1.262kB)
+// 1528395571_.down.sql (235B)
+// 1528395571_.up.sql (279B)
+// 1528395572_.down.sql (52B)
+// 1528395572_.up.sql (881B)
+// 1528395573_.down.sql (229B)
+// 1528395573_.up.sql (632B)
+// 1528395574_.down.sql (255B)
+// 1528395574_.up.sql (726B)
+// 1528395575_.down.sql (39B)
+// 1528395575_.up.sql (269B)
+// 1528395576_.down.sql (121B)
+// 1528395576_.up.sql (149B)
+// 1528395577_.down.sql (48B)
+// 1528395577_.up.sql (690B)
+// 1528395578_.down.sql (284B)
+// 1528395578_.up.sql (179B)
+// 1528395579_.down.sql (119B)
+// 1528395579_.up.sql (170B)
+// 1528395580_.down.sql (214B)
+// 1528395580_.up.sql (401B)
 
 package migrations
 
@@ -4100,6 +4132,646 @@ func _1528395564_UpSql() (*asset, error) {
 	return a, nil
 }
 
+var __1528395565_DownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x72\x09\xf2\x0f\x50\x08\x71\x74\xf2\x71\x55\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\x28\x4a\x2d\xc8\x8f\xcf\x49\xcc\x4b\x2f\x4d\x4c\x4f\x8d\x2f\x2e\x49\x2c\x29\xb6\xe6\x02\x00\x00\x00\xff\xff\x01\x00\x00\xff\xff\x57\x1e\xf5\xdc\x2a\x00\x00\x00")
+
+func _1528395565_DownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395565_DownSql,
+		"1528395565_.down.sql",
+	)
+}
+
+func _1528395565_DownSql() (*asset, error) {
+	bytes, err := _1528395565_DownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395565_.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x13, 0x91, 0x8f, 0x3d, 0xcb, 0xf5, 0xa9, 0xa4, 0xdd, 0x33, 0x3e, 0xd8, 0x1c, 0xa5, 0x6e, 0x5f, 0x9e, 0x21, 0xd3, 0x9e, 0xb2, 0xfe, 0x7, 0x22, 0xcb, 0xbb, 0xf8, 0x7d, 0x7b, 0xa, 0x89, 0x39}}
+	return a, nil
+}
+
+var __1528395565_UpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x8c\x90\xc1\x4e\xc3\x30\x10\x44\xcf\xe4\x2b\x56\x39\x25\x52\xff\x80\x93\x71\xb6\x22\xc2\x71\x2a\xc7\x15\x94\x8b\xe5\x36\x56\x64\x29\x8d\x91\x63\x04\xfc\x3d\x89\x29\x34\x48\x39\xf4\xb8\x9a\xb7\x33\xb3\x4b\x05\x12\x89\x20\xc9\x03\x43\xf0\xe6\xcd\xa9\x5e\x0f\xdd\xbb\xee\x8c\x1a\x83\x0e\x23\x64\xc9\x5d\x6a\xdb\x14\x8e\xb6\x1b\x8d\xb7\xba\x07\x5e\x4b\xe0\x7b\xc6\x60\x27\xca\x8a\x88\x03\x3c\xe1\x61\x33\x51\x71\x7b\x46\xed\x10\x4c\x67\xfc\x15\x14\xb8\x45\x81\x9c\x62\x13\x23\x32\xdb\xe6\x50\x73\x28\x90\xe1\x94\x4d\x49\x43\x49\x81\xb3\xc5\xc9\x9d\xcf\x36\x44\x93\x60\x3e\xc3\x9f\xc3\xac\xfd\xf6\x5a\x91\x82\x0b\xba\x57\xc7\xaf\x60\xc6\x58\x74\x2a\xf0\x4f\xf7\xe6\xe4\x7c\x6b\x5a\xa5\x43\x0a\xb2\xac\xb0\x91\xa4\xda\xc1\x73\x29\x1f\xe3\x08\xaf\x35\xc7\x6b\xdd\x02\xb7\x64\xcf\x24\x0c\xee\x23\xcb\x93\xfc\x3e\xa1\x3f\x4f\x2a\x79\x81\x2f\x6b\x4f\x52\x97\xd3\xd5\x22\x68\x3e\x70\x05\xcd\x2e\xe8\x06\x16\xec\x6d\x11\x37\x58\x2f\x1d\xbf\x01\x00\x00\xff\xff\x01\x00\x00\xff\xff\x9d\xdc\x05\x34\xda\x01\x00\x00")
+
+func _1528395565_UpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395565_UpSql,
+		"1528395565_.up.sql",
+	)
+}
+
+func _1528395565_UpSql() (*asset, error) {
+	bytes, err := _1528395565_UpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395565_.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x10, 0x33, 0x19, 0xb8, 0x89, 0x34, 0x3a, 0x42, 0xb9, 0xbe, 0x32, 0x72, 0xb1, 0x14, 0x7a, 0x8f, 0xae, 0xb6, 0xfc, 0x46, 0xc, 0xf2, 0x3f, 0x2f, 0x86, 0xe7, 0xd9, 0xc, 0xab, 0xa6, 0x69, 0xf2}}
+	return a, nil
+}
+
+var __1528395566_DownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x72\x09\xf2\x0f\x50\x08\x71\x74\xf2\x71\x55\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\x28\x4e\x2c\x4b\x4d\x89\x2f\x2c\x4d\x2d\xaa\x8c\x2f\xce\x4b\x2c\x28\xce\xc8\x2f\x29\xb6\xe6\x02\x00\x00\x00\xff\xff\x01\x00\x00\xff\xff\x79\x02\x38\x16\x2c\x00\x00\x00")
+
+func _1528395566_DownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395566_DownSql,
+		"1528395566_.down.sql",
+	)
+}
+
+func _1528395566_DownSql() (*asset, error) {
+	bytes, err := _1528395566_DownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395566_.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xd0, 0x69, 0x57, 0xfb, 0x6d, 0x76, 0xe8, 0xc2, 0x8, 0x34, 0xb9, 0xa0, 0x3d, 0x64, 0x61, 0xa, 0x58, 0x46, 0x6d, 0x1c, 0x23, 0xf0, 0xee, 0x71, 0xf3, 0xc4, 0x3a, 0x51, 0x3b, 0xca, 0x17, 0x6a}}
+	return a, nil
+}
+
+var __1528395566_UpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x44\x8c\xcb\x0e\x82\x30\x14\x05\xd7\xf6\x2b\x4e\xba\x82\xc4\x3f\x70\x55\xf5\x1a\x89\xe5\x11\xbc\xc4\xe0\x86\x54\x69\x42\x4c\x04\xb4\xc5\xc7\xdf\x8b\x2c\x74\x79\xe6\x4c\x66\x95\x93\x62\x02\xab\xa5\x26\x38\xf3\xb0\x75\x75\x1b\xec\xfd\x5d\xb9\xd6\xf4\xae\xe9\xbc\x43\x20\x66\x72\x62\x12\xde\xbe\x3c\x92\x94\x91\x14\x5a\x23\xcb\xa3\x58\xe5\x25\x76\x54\xce\x47\xe7\x6a\xfc\xb9\xb1\x4e\xe2\xe2\xba\xf6\xf4\xd3\xbe\xd7\xd0\xd7\xc6\x8f\x69\xe3\x25\x38\x8a\x69\xcf\x2a\xce\x70\x88\x78\x3b\x4d\x1c\xd3\x84\xfe\xdd\x35\x6d\x54\xa1\x19\x6d\xf7\x0c\x42\x11\x2e\xc4\x07\x00\x00\xff\xff\x01\x00\x00\xff\xff\x3f\x46\x0c\xeb\xa5\x00\x00\x00")
+
+func _1528395566_UpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395566_UpSql,
+		"1528395566_.up.sql",
+	)
+}
+
+func _1528395566_UpSql() (*asset, error) {
+	bytes, err := _1528395566_UpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395566_.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x1a, 0x22, 0x6e, 0xcd, 0xe3, 0xf0, 0xfe, 0xf, 0xf7, 0xd5, 0xe5, 0xab, 0x9d, 0x3a, 0xa1, 0x7f, 0x9f, 0xc2, 0x9a, 0x2, 0xd6, 0x84, 0xfc, 0x36, 0x67, 0x89, 0x3c, 0x37, 0xc5, 0x81, 0xe4, 0x62}}
+	return a, nil
+}
+
+var __1528395567_DownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x72\x09\xf2\x0f\x50\x08\x71\x74\xf2\x71\x55\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\x28\x4e\x2c\x4b\x4d\x89\x2f\x2c\x4d\x2d\xaa\x8c\x2f\x4f\x4d\xca\xc8\xcf\xcf\x8e\x4f\x49\xcd\xc9\x2c\x4b\x2d\xca\x4c\x2d\xb6\xe6\x02\x00\x00\x00\xff\xff\x01\x00\x00\xff\xff\x3f\xaa\xcd\x47\x35\x00\x00\x00")
+
+func _1528395567_DownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395567_DownSql,
+		"1528395567_.down.sql",
+	)
+}
+
+func _1528395567_DownSql() (*asset, error) {
+	bytes, err := _1528395567_DownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395567_.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xe7, 0x1c, 0x25, 0x0, 0x1f, 0x5a, 0x90, 0x73, 0xfb, 0xcf, 0xba, 0xe, 0xb6, 0x91, 0xa1, 0xa0, 0x3a, 0x1b, 0x31, 0x50, 0xfc, 0x59, 0x6, 0x50, 0xe6, 0x77, 0x2, 0x6f, 0x7f, 0xce, 0x16, 0xe9}}
+	return a, nil
+}
+
+var __1528395567_UpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x94\x91\x4d\x4e\xc3\x30\x10\x85\xd7\xe4\x14\xa3\xac\x52\x29\x37\x60\x65\x5a\x23\x22\xf2\x53\x05\x57\x50\x36\x96\x93\x8c\x8a\x69\x1a\x83\xed\xa4\xe4\xf6\x84\x34\x6a\xda\x12\xa9\xb0\xf4\xcc\x7b\xf3\x3d\xcf\xcc\x53\x4a\x18\x05\x46\xee\x42\x0a\x46\x34\x58\xf0\xcf\x1a\x75\xcb\xf7\x98\xbd\x29\xb5\xe5\x05\x96\xb2\x41\x2d\xd1\x80\xe7\xdc\xb8\xb2\x70\x21\x93\x1b\xd3\x55\x44\x09\x71\xc2\x20\x5e\x85\x21\x2c\xd3\x20\x22\xe9\x1a\x1e\xe9\xda\xef\x54\xa6\xce\xde\x31\xb7\x2e\x58\xfc\xb2\x47\x55\xdf\x39\x41\x6c\xb1\x9d\x50\x0c\xc0\x96\xff\xa0\x7e\x75\xb1\xc1\x6a\x6a\x6e\xad\xcb\x89\xaa\xb0\x16\x77\x1f\x9d\x5e\x56\x16\x37\xa8\xcf\xa3\x58\x61\x6b\xc3\x73\x55\xe0\x51\xd0\x23\xb4\x56\xfa\x30\xac\xcf\x53\x6b\x61\xa5\xaa\xf8\xce\x4c\xcf\xc9\x35\x0a\xdb\x7d\x4a\x74\x1c\x16\x44\xf4\x89\x91\x68\x09\xcf\x01\x7b\xe8\x9f\xf0\x9a\xc4\x74\xdc\xd4\x82\xde\x93\x55\xc8\xa0\x52\x7b\x6f\xe6\xcc\x6e\x9d\xf9\xe1\x02\x41\xbc\xa0\x2f\x57\x2e\xc0\x87\xbd\xf2\x8b\x2d\x42\x12\x5f\x71\x7a\x83\xd3\x87\x0b\xab\x0f\x63\xfc\x7f\x86\x19\x8d\x7f\xe0\x9f\x51\xbe\x01\x00\x00\xff\xff\x01\x00\x00\xff\xff\xe1\xfb\xf7\xc5\x75\x02\x00\x00")
+
+func _1528395567_UpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395567_UpSql,
+		"1528395567_.up.sql",
+	)
+}
+
+func _1528395567_UpSql() (*asset, error) {
+	bytes, err := _1528395567_UpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395567_.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x81, 0x97, 0x70, 0xa8, 0x34, 0x79, 0xcc, 0x41, 0x41, 0xbc, 0xa4, 0xdf, 0x5f, 0xf8, 0xb8, 0xef, 0xd3, 0xad, 0x99, 0x27, 0x5e, 0xc2, 0x3c, 0xe6, 0x5e, 0x43, 0x36, 0x34, 0xe5, 0x9a, 0x22, 0xc3}}
+	return a, nil
+}
+
+var __1528395568_DownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x72\x09\xf2\x0f\x50\x08\x71\x74\xf2\x71\x55\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\x28\x4e\x2c\x4b\x4d\x89\x2f\x2c\x4d\x2d\xaa\x8c\x2f\x2a\xcd\x2b\xb6\xe6\x02\x00\x00\x00\xff\xff\x01\x00\x00\xff\xff\x10\x43\xef\xff\x27\x00\x00\x00")
+
+func _1528395568_DownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395568_DownSql,
+		"1528395568_.down.sql",
+	)
+}
+
+func _1528395568_DownSql() (*asset, error) {
+	bytes, err := _1528395568_DownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395568_.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x2, 0xde, 0x91, 0xe0, 0x36, 0x43, 0x84, 0x16, 0x3e, 0x1c, 0xb4, 0xc5, 0xb4, 0xdc, 0x58, 0x42, 0x72, 0x3, 0xec, 0x9e, 0x22, 0x1c, 0x7c, 0x5a, 0x7b, 0xc, 0x3, 0x24, 0x86, 0xdf, 0xa4, 0x21}}
+	return a, nil
+}
+
+var __1528395568_UpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x84\x90\x4d\x6e\xc2\x30\x10\x85\xd7\xcd\x29\x46\x59\x05\x89\x1b\x74\xe5\x82\xab\x46\x4d\x1c\x14\x8c\x28\xdd\x58\x0e\x19\x51\x57\xa9\x2d\xfc\x53\xe8\xed\x31\x49\x4b\x89\x58\xb0\x9b\xf1\xbc\xf9\xde\xf3\xcc\x6a\x4a\x38\x05\x4e\x9e\x0a\x0a\x4e\x7e\x63\x2b\xf6\x01\xed\x8f\xb0\x41\x3b\xc8\x92\x87\x54\xb5\x29\x34\x6a\xe7\xd0\x2a\xd9\x01\xab\x38\xb0\x55\x51\xc0\xa2\xce\x4b\x52\x6f\xe0\x95\x6e\xa6\x51\xd5\x2f\xa5\xe0\xf1\xe8\x2f\x9a\xf3\x3b\x1e\x71\x1b\x7c\xa4\x4a\x9f\x02\xcf\x4b\xba\xe4\xa4\x5c\xc0\x3a\xe7\x2f\x7d\x0b\xef\x15\xa3\xff\xd4\x39\x7d\x26\xab\x82\x83\x36\x87\x6c\xf2\xb7\x2f\xda\x60\xa5\x57\x46\x0b\xed\xfa\x2c\x4a\x8f\x4d\x2c\xba\xd0\x79\xb1\x35\x41\x47\x97\x38\xc5\x1d\xda\x91\xa2\x53\x5f\xca\x8b\x0f\x15\xc7\x8d\x31\x1d\x4a\x3d\x4e\x69\xad\xb1\x43\xfa\x73\xab\xf1\x20\x06\x66\xf4\xfb\x74\x46\x37\x17\x75\x32\x79\x4c\x66\xc3\xcd\x72\x36\xa7\x6f\x37\x37\xfb\x2d\xaf\xfe\x0d\x15\xbb\x51\x65\x7d\x39\x85\x2b\xd9\x5d\xf0\x3d\xe4\x98\x75\x02\x00\x00\xff\xff\x01\x00\x00\xff\xff\x06\x8e\x76\x16\xd9\x01\x00\x00")
+
+func _1528395568_UpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395568_UpSql,
+		"1528395568_.up.sql",
+	)
+}
+
+func _1528395568_UpSql() (*asset, error) {
+	bytes, err := _1528395568_UpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395568_.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x8c, 0xaa, 0x9b, 0xb8, 0xd5, 0xdd, 0xf6, 0xda, 0xce, 0x79, 0xc1, 0xee, 0xd0, 0xab, 0x67, 0x2c, 0x49, 0x10, 0xb3, 0x40, 0x8, 0x70, 0x6, 0x97, 0x54, 0x18, 0x5b, 0xde, 0x6c, 0x1c, 0x96, 0x42}}
+	return a, nil
+}
+
+var __1528395569_DownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x72\x09\xf2\x0f\x50\x08\x71\x74\xf2\x71\x55\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\xc8\xcc\x2b\xce\x4c\xcf\x28\x89\x2f\x4e\x2d\xca\x4c\x2d\x8e\x2f\xc8\xcf\xcc\x2b\x29\xb6\xe6\x02\x00\x00\x00\xff\xff\x01\x00\x00\xff\xff\x25\x11\xcd\x03\x2c\x00\x00\x00")
+
+func _1528395569_DownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395569_DownSql,
+		"1528395569_.down.sql",
+	)
+}
+
+func _1528395569_DownSql() (*asset, error) {
+	bytes, err := _1528395569_DownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395569_.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x1c, 0x36, 0xa8, 0xa, 0xb8, 0xc8, 0x13, 0x22, 0x5b, 0x87, 0x9d, 0xe6, 0x52, 0xe8, 0xc2, 0x1c, 0x32, 0x71, 0x61, 0x57, 0x5d, 0xeb, 0x32, 0x39, 0x39, 0xe5, 0x3a, 0xfc, 0x92, 0xed, 0x3d, 0x5e}}
+	return a, nil
+}
+
+var __1528395569_UpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x8c\x90\xcd\x6e\x83\x30\x10\x84\xcf\xe5\x29\x56\x9c\x40\xe2\x0d\x72\x72\xcd\x46\x45\x35\x26\x05\xa3\x36\xbd\x58\x34\x58\xc4\x52\x80\xd4\x38\xfd\x79\xfb\xb2\x24\x6a\x55\x35\x87\x1e\x77\xf7\xd3\xcc\xec\xf0\x12\x99\x42\x50\xec\x56\x20\xd8\x61\xb2\xdd\xde\xeb\xc9\x38\x6b\x26\x7d\x1c\xed\xe0\x27\x88\x82\x9b\xd0\xb6\x21\xbc\xd8\x8e\x0e\xcd\x01\x64\xa1\x40\xd6\x42\xc0\xa6\xcc\x72\x56\x6e\xe1\x1e\xb7\xc9\x4c\xbd\x9e\x8c\xfb\x0c\xc1\x9b\x0f\xff\xcd\xd0\xde\x99\xe3\xa8\x49\x62\xd6\x33\x9d\x71\x3f\x02\x25\xae\xb1\x44\xc9\xb1\x02\x82\x22\xdb\xc6\x50\x48\x48\x51\xe0\x9c\x8a\xb3\x8a\xb3\x14\x49\xc2\xdb\xde\x84\xa0\xb2\x1c\x2b\xc5\xf2\x0d\x3c\x66\xea\x6e\x19\xe1\xb9\x90\xf8\xcb\x6d\x37\xf6\xbd\xf5\x8b\x1f\x25\xa1\xd5\x5b\x73\x38\x99\xbf\xf6\xe7\x6c\xbb\xd1\xb5\xa6\xd5\x8d\xff\x87\xfe\x9c\x6c\xcd\x6a\xa1\x60\x18\xdf\xa3\x38\x88\x57\x01\x3f\x17\x58\xcb\xec\xa1\x46\xc8\x64\x8a\x4f\xd7\x7b\xd4\x4b\x3b\xfa\xd2\x85\xa6\x87\xe8\xd5\xab\x6c\xb4\xb0\x09\x5c\xe0\x04\x88\x9e\xcd\xbe\x00\x00\x00\xff\xff\x01\x00\x00\xff\xff\x1c\x34\x36\x5b\xae\x01\x00\x00")
+
+func _1528395569_UpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395569_UpSql,
+		"1528395569_.up.sql",
+	)
+}
+
+func _1528395569_UpSql() (*asset, error) {
+	bytes, err := _1528395569_UpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395569_.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x6f, 0xac, 0xba, 0x2d, 0x3, 0x4f, 0x12, 0x8f, 0x2, 0xe3, 0xe0, 0x8d, 0xfd, 0xa0, 0xa6, 0x15, 0xdc, 0x72, 0x1, 0x5f, 0x72, 0x42, 0x9, 0x27, 0xc3, 0xad, 0x7e, 0x32, 0xbd, 0xb7, 0xe4, 0x71}}
+	return a, nil
+}
+
+var __1528395570_DownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x72\x09\xf2\x0f\x50\x08\x71\x74\xf2\x71\x55\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\x28\x4e\x2c\x4b\x4d\x89\x2f\x2c\x4d\x2d\xaa\x8c\x4f\xc9\x4c\x4f\x2d\x2e\x89\xcf\x2c\x49\xcd\x2d\xb6\xe6\x72\x21\xa4\x3c\x2f\xbf\x24\x33\x2d\x33\x39\xb1\x24\x33\x3f\x2f\xbe\xa0\x28\x35\x2d\xb5\x28\x35\x2f\x39\x15\xa8\x15\x00\x00\x00\xff\xff\x01\x00\x00\xff\xff\xd9\xae\xd0\x1d\x6a\x00\x00\x00")
+
+func _1528395570_DownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395570_DownSql,
+		"1528395570_.down.sql",
+	)
+}
+
+func _1528395570_DownSql() (*asset, error) {
+	bytes, err := _1528395570_DownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395570_.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xff, 0xba, 0x52, 0xf4, 0xdb, 0x12, 0x61, 0x71, 0x3a, 0x15, 0x0, 0x8f, 0x4, 0x3f, 0x6b, 0x36, 0x58, 0x30, 0x1c, 0x87, 0xe4, 0xc9, 0x9, 0x17, 0xd6, 0xc8, 0x46, 0xe1, 0xd4, 0x4c, 0x45, 0x2f}}
+	return a, nil
+}
+
+var __1528395570_UpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xac\x53\xc1\x52\xc2\x30\x14\x3c\xcb\x57\xbc\xe1\x54\x66\xf8\x03\x4f\xb5\x0d\x63\xc7\x52\xb0\x94\x51\xbc\x64\x42\xf2\xc0\x28\xa4\x98\xa6\x28\x7f\x6f\x5a\xaa\x45\x8a\x08\x83\xc7\x76\x37\x9b\xdd\x9d\x8d\x17\x13\x37\x21\x90\xb8\x37\x21\x81\x8c\xad\x51\xd0\xb7\x1c\xf5\x86\xaa\xd4\xc8\x99\xe4\xcc\xc8\x54\xd1\x95\xc6\x19\x6a\x54\x1c\x33\x70\x5a\x57\x6d\x29\xda\x30\x95\xf3\x0c\xb5\x64\x0b\x88\x06\x09\x44\xe3\x30\x84\x61\x1c\xf4\xdd\x78\x02\x77\x64\xd2\xb5\xac\xdc\xe2\xb4\xa0\x4a\x65\x70\x8e\xba\x26\xc6\xa4\x47\x62\x12\x79\x64\x04\x05\x29\x73\xa4\xe8\xc0\x20\x02\x9f\x84\xc4\xba\xf1\xdc\x91\xe7\xfa\xa4\xd0\xc8\xf2\xe9\x0b\x72\xd3\x06\x83\x1f\xe6\x5b\xa0\x44\x76\xcc\xbe\xe2\xe6\x00\x63\xa6\xd1\xc2\x8a\x57\x58\x69\x49\x59\xc1\x8c\x6b\x39\xc5\x22\x42\x9a\x2e\x90\xa9\xda\x97\x4f\x7a\xee\x38\x4c\x60\xc6\x16\x19\x96\xfc\x95\x60\xc6\x5e\xc3\xac\x83\x24\xe8\x93\x51\xe2\xf6\x87\xf0\x10\x24\xb7\xe5\x27\x3c\x0d\x22\xd2\x3c\xae\xd2\x77\xa7\xd3\xea\x5c\xb7\xbc\x6d\xbb\xe3\x28\xb8\x1f\x13\x08\x22\x9f\x3c\x9e\x54\x32\xad\xaa\xa3\x55\x7c\xba\x17\xb6\xe8\xea\x14\x1d\xa7\xd2\xe9\x42\x25\xd4\x85\x3d\xa5\xda\xe4\x19\xee\x2e\x75\x75\xc4\xcc\x97\x9b\xe6\x20\x85\x9c\x63\x66\xa8\x34\xb8\x3c\x67\x84\x97\x0c\x48\x23\x97\x2b\x89\xca\xd0\xc6\x94\x4f\x5f\x70\x2d\x92\xea\xf9\x6f\x1a\x16\x3a\x22\xc1\x9f\x99\x52\xb8\xf8\x7b\xe2\x3f\x30\x81\xc5\xd0\x57\x45\xfb\x07\xd0\x32\xf7\x81\xff\x4c\x08\xdb\x0a\x4f\x73\x65\x9a\x0f\x77\x9b\x67\x99\xae\x8f\x53\xb8\xc6\x7f\x78\x35\xcd\x41\xee\x4e\x80\xd6\x97\xec\xef\x6e\x97\xe6\xd4\x34\xab\xfc\x09\x00\x00\xff\xff\x01\x00\x00\xff\xff\x52\xc2\x74\xe6\xee\x04\x00\x00")
+
+func _1528395570_UpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395570_UpSql,
+		"1528395570_.up.sql",
+	)
+}
+
+func _1528395570_UpSql() (*asset, error) {
+	bytes, err := _1528395570_UpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395570_.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x94, 0x1f, 0xb8, 0x39, 0xe3, 0xe5, 0xaa, 0x50, 0x78, 0x75, 0xc5, 0x39, 0xd2, 0x85, 0x7f, 0x34, 0x8a, 0x6e, 0xc4, 0xf4, 0x84, 0x91, 0x4d, 0xa1, 0xee, 0x1f, 0x8c, 0xa1, 0xaa, 0xce, 0x35, 0xeb}}
+	return a, nil
+}
+
+var __1528395571_DownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xac\xcc\x41\x0e\x45\x30\x10\x00\xd0\x7d\x4f\x31\xf7\xe8\x8a\xaf\x11\x49\x95\x08\xeb\xa6\x6a\x24\x93\x4f\xd1\x41\xe2\xf6\x12\x57\x60\xff\xf2\x52\x95\x17\x46\x8a\x44\xb7\xaa\x81\x36\x49\xb5\x02\x76\x27\x0e\x76\x3b\x30\x5e\x36\x2c\x3b\x8d\xe4\xdd\x4e\x4b\xb0\x6b\xc4\x11\x23\x06\x8f\x0c\x59\x53\xd5\xf0\xab\x74\x57\x1a\xe0\xa3\x67\x1f\xa9\xc7\xe1\x65\xf4\xa0\xcb\xe2\xec\x68\xfa\xa6\xe2\xc9\xf9\xbf\x14\xca\x64\x52\xdc\x00\x00\x00\xff\xff\x01\x00\x00\xff\xff\x1a\xbb\x87\x44\xeb\x00\x00\x00")
+
+func _1528395571_DownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395571_DownSql,
+		"1528395571_.down.sql",
+	)
+}
+
+func _1528395571_DownSql() (*asset, error) {
+	bytes, err := _1528395571_DownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395571_.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xca, 0x2d, 0x26, 0x6f, 0x4e, 0x54, 0x39, 0xf1, 0xef, 0x68, 0xb5, 0xc5, 0x1c, 0x54, 0x6d, 0x90, 0x5e, 0xe7, 0x7e, 0xdd, 0x6b, 0x45, 0x6f, 0xd5, 0x21, 0x60, 0x37, 0x48, 0x35, 0xa7, 0xc3, 0xaf}}
+	return a, nil
+}
+
+var __1528395571_UpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xac\xcd\x41\x0a\xc2\x30\x10\x40\xd1\x7d\x4f\x31\xf7\xe8\x2a\x35\x51\x84\x98\x82\xa4\xeb\x30\x49\x27\x10\x8c\x49\xcd\xb4\x42\x6f\x2f\x08\xe2\x01\x74\xff\x79\x7f\x50\xa7\xb3\xe9\x3b\xa1\xad\xba\x82\x15\x83\x56\xc0\xf8\xa4\xd9\x3d\x36\x6a\xbb\x2b\x75\x4d\x31\x05\x5c\x53\x2d\x6e\x69\x14\xa9\x51\x09\xc4\x20\xa4\x84\xc3\xa8\xa7\x8b\x01\xde\x3c\x87\x96\x3c\xcd\xe0\x6b\xcd\x84\x05\xcc\x68\xc1\x4c\x5a\x83\x54\x47\x31\x69\x0b\x11\x33\xd3\x6f\x9b\x77\xb3\x3b\xba\x63\xca\x9f\xd1\x5f\x44\xce\x18\x6e\x5f\x51\x19\xd9\x77\x2f\x00\x00\x00\xff\xff\x01\x00\x00\xff\xff\x17\x81\x79\xed\x17\x01\x00\x00")
+
+func _1528395571_UpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395571_UpSql,
+		"1528395571_.up.sql",
+	)
+}
+
+func _1528395571_UpSql() (*asset, error) {
+	bytes, err := _1528395571_UpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395571_.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x77, 0x12, 0x68, 0xf9, 0x91, 0x38, 0x4d, 0x71, 0x7c, 0xe3, 0x93, 0xed, 0x17, 0x2c, 0x15, 0xa8, 0x86, 0xc7, 0x22, 0xcc, 0x24, 0x52, 0xc7, 0xa, 0xe1, 0xc0, 0x5f, 0x26, 0x57, 0x6c, 0x53, 0x76}}
+	return a, nil
+}
+
+var __1528395572_DownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x72\x09\xf2\x0f\x50\x08\x71\x74\xf2\x71\x55\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\x48\xc9\x2c\x4e\x2e\x2d\x2e\xce\xcc\xcf\x8b\x4f\xce\x4f\x49\x8d\xcf\xc8\x2f\x2e\x01\xb2\x72\x73\x53\xf3\x4a\x8a\xad\xb9\x00\x00\x00\x00\xff\xff\x01\x00\x00\xff\xff\x84\xb8\xa9\xaf\x34\x00\x00\x00")
+
+func _1528395572_DownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395572_DownSql,
+		"1528395572_.down.sql",
+	)
+}
+
+func _1528395572_DownSql() (*asset, error) {
+	bytes, err := _1528395572_DownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395572_.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xa9, 0x28, 0xb5, 0x6b, 0xfe, 0x58, 0x6b, 0xa3, 0xba, 0xd6, 0x27, 0x3e, 0x33, 0x90, 0xad, 0x17, 0x1a, 0x60, 0xbc, 0x58, 0xfc, 0xbb, 0x82, 0x56, 0xd2, 0xb7, 0x98, 0x8a, 0xcc, 0xe8, 0xe0, 0xff}}
+	return a, nil
+}
+
+var __1528395572_UpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x9c\x92\xcd\x6e\x83\x30\x10\x84\xcf\xcd\x53\xac\x38\x81\x94\x37\xe8\xc9\x85\x8d\x8a\x0a\x4e\x0a\x46\x6d\x7a\x41\x04\x56\x89\x25\x02\x29\x36\xfd\x79\xfb\x9a\x26\x0d\x48\x21\x49\xdb\x1b\xd6\x7e\xcc\x8c\x67\xed\x46\xc8\x04\x82\x60\x77\x01\x42\x21\x55\xde\x2a\x25\xeb\x2a\xcd\xeb\x82\xd2\x4d\xad\xb4\xf9\xda\x6e\xa9\xd2\x0a\xec\xc9\x8d\x25\x0b\x0b\x56\x72\xad\xa8\x91\x59\x09\x7c\x2e\x80\x27\x41\x00\x8b\xc8\x0f\x59\xb4\x84\x07\x5c\x4e\x0d\xa5\x37\x0d\x65\x45\x7a\x80\x65\xa5\x7b\x32\xc2\x19\x46\xc8\x5d\x8c\x87\x6e\xfb\x1f\x94\x2d\x0b\x07\xe6\x1c\x3c\x0c\xd0\x84\x72\x59\xec\x32\x0f\x3b\xc5\x43\x88\x3f\x48\xfe\xc4\x3e\xaf\x69\x2e\xf1\x26\x73\x4a\xf5\xe7\x8e\x2c\xd0\xf4\xd1\x6b\x0e\xc7\x9d\xe5\xc9\xd0\x9c\xa9\xa9\xb2\x32\x6d\x68\x57\x8f\x23\xbb\xb6\xec\xc6\xaf\x2d\x99\x12\xab\x76\xbb\xa2\xc6\x02\x13\x9c\xd6\xd4\x8c\x6b\x0d\x6a\x3b\x6f\x38\x6c\xe2\x84\xca\x8d\x80\xa6\x22\xcd\xb4\x05\xc2\x0f\x31\x16\x2c\x5c\xc0\x93\x2f\xee\xbf\x8f\xf0\x32\xe7\xd8\x17\xe7\xe1\x8c\x25\x81\x80\xaa\x7e\xb7\x9d\x89\x73\x3b\x71\xf7\x8f\x21\xe1\xfe\x63\x82\xe0\x73\x0f\x9f\x2f\xbf\x89\x41\x9a\xae\xe3\x8b\xac\xdd\xb3\xff\xb2\x1a\x69\xe0\xba\xe7\x70\xc7\x53\xe8\x57\x3a\x85\x11\xb9\x3e\xd6\x6f\xf2\x1c\xb7\x75\x3d\xc5\x11\x35\x0e\x5f\x00\x00\x00\xff\xff\x01\x00\x00\xff\xff\x07\x27\x9d\x30\x71\x03\x00\x00")
+
+func _1528395572_UpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395572_UpSql,
+		"1528395572_.up.sql",
+	)
+}
+
+func _1528395572_UpSql() (*asset, error) {
+	bytes, err := _1528395572_UpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395572_.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xac, 0x9f, 0xe5, 0xb, 0x89, 0x23, 0xc1, 0x42, 0x33, 0xf5, 0x6c, 0x6a, 0xc1, 0x97, 0xfa, 0xa5, 0x9d, 0xa, 0x6d, 0xeb, 0xa9, 0x51, 0x8e, 0x8a, 0x5, 0x58, 0x5, 0xe2, 0xa, 0xa8, 0x23, 0xa8}}
+	return a, nil
+}
+
+var __1528395573_DownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x72\x09\xf2\x0f\x50\x08\x71\x74\xf2\x71\x55\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\x48\xc9\x2c\x4e\x2e\x2d\x2e\xce\xcc\xcf\x8b\x2f\xc9\x28\x4a\x4d\x4c\x89\x4f\x04\xf2\xd2\xf3\x52\x53\x8b\xad\xb9\x5c\x40\x3a\x3c\xfd\x5c\x5c\x23\xf0\xe9\x28\x8e\xcf\x49\x4c\x4a\xcd\x29\x8e\xcf\x4c\xa9\xb0\xe6\x72\xf4\x09\x71\x0d\x82\x5a\x83\xa9\x54\x01\x6c\xa4\xb3\xbf\x4f\xa8\xaf\x1f\x92\x99\x10\x03\xc8\xd4\x5c\x94\x5a\x9c\x9f\x53\x96\x0a\x74\x79\x89\x35\x17\x00\x00\x00\xff\xff\x01\x00\x00\xff\xff\x75\xb5\x9d\x77\xe5\x00\x00\x00")
+
+func _1528395573_DownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395573_DownSql,
+		"1528395573_.down.sql",
+	)
+}
+
+func _1528395573_DownSql() (*asset, error) {
+	bytes, err := _1528395573_DownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395573_.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xb6, 0x1c, 0x7e, 0xbe, 0x32, 0x2, 0xc9, 0x5f, 0xb0, 0x5f, 0xdd, 0x7d, 0xf9, 0x80, 0x9a, 0xa3, 0x82, 0xf8, 0xef, 0xca, 0x71, 0xa8, 0xd5, 0xc5, 0xf, 0xc3, 0xc5, 0x22, 0xcf, 0xdd, 0xaa, 0x5f}}
+	return a, nil
+}
+
+var __1528395573_UpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x8c\x91\x51\x4f\x83\x30\x14\x85\x9f\xed\xaf\xb8\xe1\x65\x90\xec\x1f\xf0\x54\xe1\x6e\x12\x4b\x59\xa0\x44\xa7\x31\x0d\x5b\x1b\x6c\x42\x20\xa1\x4c\x97\x18\xff\xbb\x6c\x63\xf2\x20\xa8\x8f\xb7\xb9\xf7\x3b\xa7\xe7\x50\x26\x30\x05\x41\x6f\x19\x82\x32\x76\x7f\xb0\xd6\x34\xb5\xec\x5e\x5b\x5d\x28\x0b\x34\x0c\x21\x48\x58\x1e\x73\x68\xb5\x6d\xaa\x37\xad\x64\xd1\x81\x88\x62\xcc\x04\x8d\x37\xf0\x10\x89\xbb\xf3\x08\x4f\x09\x47\x9f\xd0\x7f\xf3\xaa\x62\xa7\x2b\x0b\x9d\x3e\x76\xcf\x2f\xc0\x13\x01\x3c\x67\x0c\x42\x5c\xd1\x9c\x09\x58\x7c\x7c\x2e\x7c\x12\xa4\x48\x05\x42\xc4\x43\x7c\x9c\xe0\xc9\x0b\x44\x1a\x75\x84\x84\x4f\x09\xe6\x59\xc4\xd7\xb0\x8e\x38\xb8\x97\x5d\xcf\x27\x57\xea\x8c\x4b\x59\xf4\x53\x59\x6b\x6d\xc1\x25\x37\xce\xf0\x68\x94\x03\x3b\x53\x9a\xba\x1b\xbd\xa6\xb8\xc2\x14\x79\x80\xd9\x84\xb4\x6b\x94\x77\x32\x15\x22\xc3\x5e\x2c\xa0\x59\x40\x43\x5c\xf6\xc4\x83\xd5\xed\x99\xd7\xc3\x74\xa9\xdb\x49\xe0\x69\xe9\x17\xc6\xbe\xd7\xe8\xce\x65\x38\xb3\x6d\xfc\x0c\xb5\x6e\xde\x5d\xaf\x3f\xdf\xa4\x51\x4c\xd3\x2d\xdc\xe3\x16\xdc\xef\x0f\x2e\x61\x70\xe6\x11\xef\xaf\xe8\xc7\x90\xe4\x70\x34\x5b\xc2\xb8\xea\x5e\xf9\x3e\xf9\x02\x00\x00\xff\xff\x01\x00\x00\xff\xff\x85\x16\x59\xed\x78\x02\x00\x00")
+
+func _1528395573_UpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395573_UpSql,
+		"1528395573_.up.sql",
+	)
+}
+
+func _1528395573_UpSql() (*asset, error) {
+	bytes, err := _1528395573_UpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395573_.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xa, 0xf8, 0x88, 0xd2, 0xee, 0xbf, 0x98, 0x4e, 0x59, 0xb2, 0x69, 0x55, 0x3e, 0x1, 0xf9, 0xdf, 0x7a, 0x39, 0x49, 0xdc, 0x11, 0x6c, 0xe8, 0xa0, 0x97, 0x62, 0xc6, 0x7c, 0xbd, 0xf, 0xe9, 0x56}}
+	return a, nil
+}
+
+var __1528395574_DownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x72\x09\xf2\x0f\x50\x08\x71\x74\xf2\x71\x55\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\x48\xc9\x2c\x4e\x2e\x2d\x2e\xce\xcc\xcf\x8b\x4f\xce\xcf\xcd\x4d\xcd\x2b\x89\x2f\x4a\x4d\x4c\x2e\x01\x0a\x14\x5b\x73\xb9\x80\xb4\x78\xfa\xb9\xb8\x46\xe0\xd5\x52\x1c\x5f\x90\x58\x04\xd2\x0a\x33\x22\x33\x05\x88\x2a\xac\xb9\x1c\x7d\x42\x5c\x83\xa0\x76\x62\xd1\xa6\x00\x36\xdf\xd9\xdf\x27\xd4\xd7\x0f\xc9\x82\xa2\xd4\xe2\xfc\x9c\xb2\xd4\x94\xf8\xc4\x12\x72\x8d\xc0\x70\x8f\x35\x17\x00\x00\x00\xff\xff\x01\x00\x00\xff\xff\x6e\x62\xe7\x06\xff\x00\x00\x00")
+
+func _1528395574_DownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395574_DownSql,
+		"1528395574_.down.sql",
+	)
+}
+
+func _1528395574_DownSql() (*asset, error) {
+	bytes, err := _1528395574_DownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395574_.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xfd, 0x14, 0x72, 0xf4, 0x12, 0xa9, 0x59, 0x78, 0x8d, 0x2, 0x28, 0x67, 0x34, 0x2, 0x27, 0x7f, 0xeb, 0xf1, 0x2d, 0x28, 0xef, 0xe5, 0xc6, 0x3b, 0x43, 0xfe, 0x7f, 0xa8, 0x7b, 0x5e, 0x3b, 0x8a}}
+	return a, nil
+}
+
+var __1528395574_UpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x9c\x91\xc1\x4e\x84\x30\x10\x86\xcf\xf2\x14\x13\x4e\x90\xf0\x06\x7b\xaa\x30\x1b\x89\xa5\x6c\x4a\x89\xae\x97\x06\xa1\xd9\xd4\x08\x18\xda\xd5\x7d\x7c\x0b\x59\xc2\x61\x41\x8d\x49\x2f\x9d\xce\x7c\xff\x3f\x7f\x09\x15\xc8\x41\x90\x7b\x8a\xd0\x68\x53\x9f\x8d\xd1\x7d\x27\xeb\xbe\x6d\x55\x67\x0d\x90\x24\x81\x38\xa7\x65\xc6\xe0\xa3\x1a\x5c\x69\x7e\x92\xba\x81\x57\x7d\xd2\x9d\x05\x8e\x7b\xe4\xc8\x62\x2c\xd6\x10\x81\x6e\x42\xc8\x19\x24\x48\x51\x20\xc4\xa4\x88\x49\x82\x3b\x8f\xfc\x5d\x79\x50\xa6\x7f\xff\x54\x8d\xac\x2c\x88\x34\xc3\x42\x90\xec\x00\x4f\xa9\x78\x98\xae\xf0\x92\x33\x07\x8c\x39\x12\x27\x90\xb2\x04\x9f\xd7\x88\xf2\x66\x01\x77\x2e\xa3\xb5\x35\xd7\x37\xcd\xe1\xce\x9b\x25\xb6\x4c\xcb\x41\x55\xb5\x75\x05\x03\x81\x77\xe7\x2f\xb3\xfe\x1c\x15\xcb\x05\xb0\x92\xd2\x7f\x65\x16\x39\xe6\xd9\xa8\x61\x02\x3a\x9a\x3a\xa9\x61\x95\x38\x36\xfd\xc0\x50\x6d\xff\xa6\x7d\xb0\xea\xb2\x18\x1a\xeb\xb5\xb3\x6f\xa7\x94\xfd\xcd\x98\x17\xbd\x04\xf7\xa4\xa4\x02\xba\xfe\x2b\x08\xdd\xf8\x81\xa7\x19\xe1\x47\x78\xc4\x23\x04\xcb\xea\x11\x5c\x2d\x47\x30\xe9\x86\x5e\xf8\xeb\x57\x2d\x39\xca\xeb\xf0\xf6\x4f\x2d\xbd\xc1\xb5\xd7\xf1\xbf\x01\x00\x00\xff\xff\x01\x00\x00\xff\xff\x3b\x27\x71\x66\xd6\x02\x00\x00")
+
+func _1528395574_UpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395574_UpSql,
+		"1528395574_.up.sql",
+	)
+}
+
+func _1528395574_UpSql() (*asset, error) {
+	bytes, err := _1528395574_UpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395574_.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xd3, 0xa7, 0x1, 0x80, 0x45, 0xee, 0xaa, 0xe4, 0x89, 0xc0, 0xe2, 0xd0, 0x33, 0xcb, 0xdc, 0x1c, 0xce, 0xee, 0xc4, 0xf2, 0x84, 0x78, 0x76, 0x4a, 0x67, 0x3d, 0x6f, 0xcb, 0xb0, 0x26, 0x72, 0xce}}
+	return a, nil
+}
+
+var __1528395575_DownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x72\x09\xf2\x0f\x50\x08\x71\x74\xf2\x71\x55\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\x28\x2d\x4e\x2d\x8a\x2f\x48\x2d\xca\xcd\x2c\x2e\xce\xcc\xcf\x2b\xb6\xe6\x02\x00\x00\x00\xff\xff\x01\x00\x00\xff\xff\x8c\x60\x69\x93\x27\x00\x00\x00")
+
+func _1528395575_DownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395575_DownSql,
+		"1528395575_.down.sql",
+	)
+}
+
+func _1528395575_DownSql() (*asset, error) {
+	bytes, err := _1528395575_DownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395575_.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x78, 0x12, 0x58, 0x12, 0xcc, 0xaf, 0x2f, 0x38, 0x37, 0x11, 0x24, 0xed, 0xdd, 0xc5, 0xa, 0x3d, 0xf0, 0xd2, 0x3a, 0x6b, 0xbf, 0x38, 0xc9, 0x99, 0xf0, 0xb2, 0x54, 0xfc, 0xed, 0x23, 0xc1, 0xa4}}
+	return a, nil
+}
+
+var __1528395575_UpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x8c\xcb\xcd\x0a\xc2\x30\x10\x04\xe0\xb3\x7d\x8a\x25\x97\x2a\xf8\x06\x9e\xa2\xae\x58\x4c\x7f\xa8\x29\x52\x45\x4a\xb1\x8b\xe4\xd0\x1f\x37\x29\x28\xe2\xbb\x5b\x0b\x7a\xf6\x38\x33\xdf\xac\x52\x94\x1a\x41\xcb\xa5\x42\xe8\x2d\x71\xd1\x11\xd7\xc6\x5a\xd3\x36\x16\xa6\xde\x44\x8c\xa5\xa9\x04\x98\xc6\xd1\x95\x18\x92\x34\x08\x65\x9a\xc3\x0e\xf3\xf9\xb0\x33\x95\x55\xc1\xd4\xb5\x03\xb2\x3f\x75\x3a\x43\x14\x6b\x88\x32\xa5\x60\x8d\x1b\x99\x29\x0d\xfe\xf3\xe5\x7f\x1e\xf6\xd1\x5c\xa8\x2a\x4a\x27\x40\x07\x21\xee\xb5\x0c\x13\x38\x04\x7a\x3b\x46\x38\xc6\x11\x7e\xd9\x80\x1c\xd5\x9d\xfb\x97\x33\xdd\x7a\xb2\x7f\x73\x62\x6e\x59\x80\xa3\xbb\xf3\x66\x0b\xef\x0d\x00\x00\xff\xff\x01\x00\x00\xff\xff\xd4\x94\xd9\xef\x0d\x01\x00\x00")
+
+func _1528395575_UpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395575_UpSql,
+		"1528395575_.up.sql",
+	)
+}
+
+func _1528395575_UpSql() (*asset, error) {
+	bytes, err := _1528395575_UpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395575_.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x93, 0x44, 0xd4, 0x7b, 0x73, 0xab, 0xa, 0x33, 0xb4, 0x12, 0xdb, 0x7f, 0x9, 0xc8, 0x74, 0x4e, 0x25, 0xb9, 0xf4, 0x2f, 0x9a, 0x73, 0xdb, 0x3a, 0x16, 0x10, 0xf2, 0x86, 0xcc, 0xa4, 0xc7, 0xe3}}
+	return a, nil
+}
+
+var __1528395576_DownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x72\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x55\x28\x2d\x4e\x2d\x2a\x56\x70\x09\xf2\x0f\x50\x70\xf6\xf7\x09\xf5\xf5\x53\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\xc8\xcc\x2b\x4b\xcc\xc9\x4c\x49\x2c\x49\x4d\x89\x2f\x4e\x2d\x2e\xce\xcc\xcf\x2b\x8e\x4f\x2c\xb1\xe6\x72\x24\xd2\x80\x94\xd4\xc4\xe4\x92\xcc\x32\xb0\x01\x20\x7d\x00\x00\x00\x00\xff\xff\x01\x00\x00\xff\xff\xb7\xad\xa5\x5c\x79\x00\x00\x00")
+
+func _1528395576_DownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395576_DownSql,
+		"1528395576_.down.sql",
+	)
+}
+
+func _1528395576_DownSql() (*asset, error) {
+	bytes, err := _1528395576_DownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395576_.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x70, 0x14, 0xaa, 0x35, 0x8e, 0xda, 0xbe, 0x55, 0x51, 0xf6, 0x53, 0x2c, 0xbd, 0xe7, 0x87, 0x59, 0x8c, 0x8, 0x50, 0x1c, 0xb, 0x2, 0xba, 0x8c, 0xff, 0x8c, 0xd1, 0xda, 0x8, 0xa3, 0x61, 0x27}}
+	return a, nil
+}
+
+var __1528395576_UpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x72\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x55\x28\x2d\x4e\x2d\x2a\x56\x70\x74\x71\x51\x70\xf6\xf7\x09\xf5\xf5\x53\x48\x49\x4d\x4c\x2e\xc9\x2c\x4b\x2c\x49\x4d\x89\x4f\x2c\x51\x08\xf1\xf4\x75\x0d\x0e\x71\xf4\x0d\x50\x08\xf7\x0c\xf1\x00\x73\x15\xa2\xfc\xfd\x5c\xad\xb9\x1c\xf1\x99\x92\x99\x57\x96\x98\x93\x99\x02\x36\xa5\x38\xb5\xb8\x38\x33\x3f\xaf\x18\xbf\x71\x00\x00\x00\x00\xff\xff\x01\x00\x00\xff\xff\x2a\x10\x82\x22\x95\x00\x00\x00")
+
+func _1528395576_UpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395576_UpSql,
+		"1528395576_.up.sql",
+	)
+}
+
+func _1528395576_UpSql() (*asset, error) {
+	bytes, err := _1528395576_UpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395576_.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xf6, 0xbe, 0x90, 0xa, 0xe4, 0xaf, 0xac, 0x1d, 0x88, 0xef, 0x47, 0xeb, 0x3d, 0xaa, 0x4c, 0xe4, 0x20, 0x90, 0x6f, 0xcd, 0x32, 0xa2, 0x15, 0xfc, 0xbb, 0x74, 0xbf, 0x77, 0xc4, 0x52, 0xca, 0xf}}
+	return a, nil
+}
+
+var __1528395577_DownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x72\x09\xf2\x0f\x50\x08\x71\x74\xf2\x71\x55\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\x48\xad\x28\xc8\xc9\x4c\xce\x2c\x89\x2f\x4a\x2d\xc8\x8f\x2f\x48\x2d\xca\xcd\x2c\x2e\xce\xcc\xcf\x2b\xb6\xe6\x02\x00\x00\x00\xff\xff\x01\x00\x00\xff\xff\x1d\x13\x87\xc7\x30\x00\x00\x00")
+
+func _1528395577_DownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395577_DownSql,
+		"1528395577_.down.sql",
+	)
+}
+
+func _1528395577_DownSql() (*asset, error) {
+	bytes, err := _1528395577_DownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395577_.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xa2, 0xb3, 0x60, 0xda, 0xf3, 0xaa, 0xf8, 0xce, 0xf, 0x87, 0xdd, 0xeb, 0xf6, 0xdb, 0xda, 0xf2, 0x2e, 0xae, 0x9e, 0xcf, 0x8e, 0x9a, 0x21, 0xdc, 0xd3, 0x78, 0x55, 0xb, 0x1e, 0x8d, 0x86, 0x9}}
+	return a, nil
+}
+
+var __1528395577_UpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x8c\x92\xcd\x6e\x83\x30\x10\x84\xcf\xe5\x29\x56\x9c\x40\xea\x1b\xb4\xaa\xe4\x9a\x8d\x62\x05\x4c\x04\x8e\xda\xf4\x82\x08\x58\xc8\x55\x0a\xc8\x38\x6a\x1e\xbf\xe6\x27\xb4\x3d\x80\x72\xf4\xce\xce\x37\xab\x91\x69\x82\x44\x20\x08\xf2\x1a\x22\xc8\x6b\x7b\x56\x85\x32\x99\x96\x6d\x93\xb5\x52\x7f\xa9\xae\x53\x4d\xdd\x81\xe7\x3c\xb8\xaa\x74\xe1\xa4\xaa\x4e\x6a\x95\x9f\x61\x9f\xb0\x88\x24\x47\xd8\xe1\xf1\xd1\x8a\x83\xa3\xdf\x50\xb5\x91\x95\xd4\x90\xe0\x06\x13\xe4\x14\x53\xe8\x35\x4f\x95\x3e\xc4\x1c\x02\x0c\xd1\xe6\x51\x92\x52\x12\xe0\xec\x6c\x73\x63\xa4\xae\x5d\x30\xf2\x6a\xfa\xe9\xc5\xc6\x2c\xf1\x7a\xad\x5b\x06\x36\xba\x5a\x72\x5a\x69\xc5\x58\x68\x99\x1b\x59\x66\xb9\x71\x41\xb0\x08\x53\x41\xa2\x3d\xbc\x31\xb1\x1d\x9e\xf0\x11\x73\x04\x1e\x0b\xe0\x87\x30\xb4\xfe\x0d\x39\x84\x02\xea\xe6\xdb\xf3\xad\x9d\xc6\x3c\x15\x09\x61\x5c\x2c\xf7\x38\x0c\x80\x6e\x91\xee\xc0\xf3\xa6\xce\x80\xa5\x03\xd1\x87\xe7\x17\xf0\xfe\xd6\x31\x2b\x77\xf3\xbb\xcb\xe9\x53\x16\x66\x8e\x98\x6a\xfc\x1f\x31\x16\xf4\x0b\x77\xfc\x27\x87\x8e\xff\x80\xf1\x00\xdf\x57\xf8\x37\x9e\xed\x6f\x71\xe9\x16\x7a\x3f\x75\x3a\x68\x15\x3a\xee\x58\xe6\x0f\x00\x00\x00\xff\xff\x01\x00\x00\xff\xff\x9b\x8b\x5a\xec\xb2\x02\x00\x00")
+
+func _1528395577_UpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395577_UpSql,
+		"1528395577_.up.sql",
+	)
+}
+
+func _1528395577_UpSql() (*asset, error) {
+	bytes, err := _1528395577_UpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395577_.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x27, 0x6e, 0xf, 0x54, 0xcc, 0xe7, 0x4e, 0xdc, 0x9e, 0x48, 0xd7, 0x80, 0x41, 0x53, 0x3, 0xf4, 0x5f, 0x7b, 0x57, 0xb0, 0x35, 0x9e, 0xcc, 0x3e, 0x86, 0xc6, 0xeb, 0xed, 0xb9, 0x9e, 0xf0, 0x71}}
+	return a, nil
+}
+
+var __1528395578_DownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x72\x71\xf5\x71\x0d\x71\x55\x70\x0b\xf2\xf7\x55\x48\xc9\x2c\x4e\x2e\x2d\x2e\xce\xcc\xcf\x8b\x4f\xce\x4f\x49\x8d\xcf\xc8\x2f\x2e\x01\xb2\x72\x73\x53\xf3\x4a\x8a\x15\xc2\x3d\x5c\x83\x5c\x15\x52\x2b\x4a\x52\x8b\xf2\x12\x73\xe2\x4b\x32\x8a\x52\x13\x53\xe2\x33\x53\x14\x3c\x83\x15\xfc\x42\x7d\x7c\x14\xfc\x83\x10\xb2\x50\x5d\x48\xd2\xd6\x5c\x8e\x3e\x21\xae\x41\x0a\x21\x8e\x4e\x3e\xae\x04\xac\x82\xa8\x74\xf6\xf7\x09\xf5\xf5\xc3\x66\x63\xb0\x6b\x88\x82\x9f\x7f\x08\x95\xcc\x45\x72\x2b\xaa\xc1\x00\x00\x00\x00\xff\xff\x01\x00\x00\xff\xff\xa6\x96\x9f\xb3\x1c\x01\x00\x00")
+
+func _1528395578_DownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395578_DownSql,
+		"1528395578_.down.sql",
+	)
+}
+
+func _1528395578_DownSql() (*asset, error) {
+	bytes, err := _1528395578_DownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395578_.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xaf, 0x7d, 0xd6, 0x6d, 0x29, 0x6, 0xd, 0xdd, 0x25, 0x10, 0x5e, 0xf0, 0xb8, 0xe, 0xe5, 0x71, 0x8c, 0xbd, 0x28, 0xb4, 0xfc, 0xce, 0xea, 0xb6, 0x38, 0xb9, 0x65, 0xe8, 0x44, 0x20, 0x75, 0x48}}
+	return a, nil
+}
+
+var __1528395578_UpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x72\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x55\x48\xc9\x2c\x4e\x2e\x2d\x2e\xce\xcc\xcf\x8b\x4f\xce\x4f\x49\x8d\xcf\xc8\x2f\x2e\x01\xb2\x72\x73\x53\xf3\x4a\x8a\x15\x1c\xc1\x2a\x9d\xfd\x7d\x42\x7d\xfd\x14\x52\x2b\x4a\x52\x8b\xf2\x12\x73\xe2\x4b\x32\x8a\x52\x13\x53\xe2\x33\x53\x14\x5c\x82\xfc\x03\x14\xfc\xfc\x43\x14\xfc\x42\x7d\x7c\xac\xb9\x1c\x29\x34\x18\x2a\x8d\xc5\x64\x00\x00\x00\x00\xff\xff\x01\x00\x00\xff\xff\x70\xb1\x6f\x2e\xb3\x00\x00\x00")
+
+func _1528395578_UpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395578_UpSql,
+		"1528395578_.up.sql",
+	)
+}
+
+func _1528395578_UpSql() (*asset, error) {
+	bytes, err := _1528395578_UpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395578_.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x78, 0x50, 0xa8, 0x41, 0xf4, 0x83, 0x66, 0xb1, 0xd1, 0xcc, 0xc7, 0x41, 0xe0, 0xc1, 0xa7, 0xfc, 0x60, 0xe3, 0x92, 0xd8, 0x99, 0xe5, 0xa3, 0x25, 0xe2, 0x6b, 0x5a, 0xc7, 0xf1, 0x9a, 0x7, 0x95}}
+	return a, nil
+}
+
+var __1528395579_DownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x72\x09\xf2\x0f\x50\xf0\xf4\x73\x71\x8d\x50\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\x48\xc9\x2c\x4e\x2e\x2d\x2e\xce\xcc\xcf\x8b\x2f\xc9\x28\x4a\x4d\x4c\x29\x8e\xcf\xcc\x2d\xc8\x2f\x2a\x89\xcf\x4c\x01\xa2\x0a\x6b\x2e\x47\x9f\x10\xd7\x20\x85\x10\x47\x27\x1f\x57\x2c\xaa\x15\x5c\x40\xa6\x3a\xfb\xfb\x84\xfa\xfa\x21\x19\x0b\x37\xc3\x9a\x0b\x00\x00\x00\xff\xff\x01\x00\x00\xff\xff\x9d\xfe\x32\x4c\x77\x00\x00\x00")
+
+func _1528395579_DownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395579_DownSql,
+		"1528395579_.down.sql",
+	)
+}
+
+func _1528395579_DownSql() (*asset, error) {
+	bytes, err := _1528395579_DownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395579_.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xd2, 0x66, 0x97, 0xb3, 0xb2, 0x61, 0x9a, 0xd5, 0x9b, 0x59, 0x65, 0x80, 0xb8, 0xd5, 0x87, 0x91, 0xb, 0xfe, 0x6d, 0x72, 0x48, 0xa7, 0xc9, 0x6b, 0x80, 0x19, 0x91, 0x13, 0x27, 0x1, 0x64, 0xbe}}
+	return a, nil
+}
+
+var __1528395579_UpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x6c\x8e\xb1\x0a\xc2\x30\x14\x45\xf7\x7e\xc5\x1d\xf5\x1b\x3a\xc5\xe6\x81\x81\x98\x62\x4c\xd0\x2d\x14\xf3\xc0\x80\xb5\xd2\x3c\xa1\x9f\x6f\xa7\x4e\x85\x33\x9e\x73\xb9\xca\x06\xf2\x08\xea\x64\x09\xb9\xd4\xe7\xaf\xd6\x32\x7d\x92\xbc\x66\x1e\x72\x85\xd2\x1a\x5d\x6f\xe3\xc5\xa1\x8c\xdf\x69\x96\x54\x32\x84\x17\x69\x9b\xce\x93\x0a\x84\xe8\xcc\x35\x12\x8c\xd3\xf4\xd8\x59\x48\x5b\xb6\xb2\xa0\x77\x3b\xce\x61\x73\x8e\xb8\x9f\xc9\xaf\x4f\xf8\xcd\xc2\x39\x0d\x02\x73\x83\x8b\xd6\xb6\xcd\x1f\x00\x00\xff\xff\x01\x00\x00\xff\xff\x6a\xc3\x61\x79\xaa\x00\x00\x00")
+
+func _1528395579_UpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395579_UpSql,
+		"1528395579_.up.sql",
+	)
+}
+
+func _1528395579_UpSql() (*asset, error) {
+	bytes, err := _1528395579_UpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395579_.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x19, 0x72, 0x18, 0x48, 0x59, 0x50, 0x51, 0xfa, 0x40, 0x11, 0x56, 0xae, 0x67, 0x50, 0x41, 0x69, 0x45, 0x62, 0xbc, 0x27, 0x5e, 0xf2, 0x52, 0xb0, 0xf1, 0x57, 0xcb, 0xd2, 0xd4, 0x5f, 0x3f, 0xa9}}
+	return a, nil
+}
+
+var __1528395580_DownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x0a\x0d\x70\x71\x0c\x71\x55\x28\x4e\x2c\x4b\x4d\x89\x2f\x2c\x4d\x2d\xaa\x8c\x2f\x2a\xcd\x2b\x56\x08\x76\x0d\x51\x28\x4a\x2d\x2e\xcd\x29\x89\x4f\xce\x2f\xcd\x2b\xb1\xcd\x4b\x2d\x8f\x47\x16\x50\x08\xf7\x70\x0d\x72\x45\x51\xa3\xe0\x19\xac\xe0\x17\xea\xe3\x63\xcd\xe5\xe8\x13\xe2\x1a\xa4\x10\xe2\xe8\xe4\x83\xc5\x68\x88\xa4\xb3\xbf\x4f\xa8\xaf\x1f\xaa\x7e\x90\xa5\x7e\xfe\x21\xc4\x18\xe2\x12\xe4\x1f\x00\x33\x03\xdd\x69\xd6\x5c\x00\x00\x00\x00\xff\xff\x01\x00\x00\xff\xff\xf6\x15\xd7\x40\xd6\x00\x00\x00")
+
+func _1528395580_DownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395580_DownSql,
+		"1528395580_.down.sql",
+	)
+}
+
+func _1528395580_DownSql() (*asset, error) {
+	bytes, err := _1528395580_DownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395580_.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x7e, 0x78, 0xaf, 0xcc, 0xa1, 0x17, 0x19, 0xb2, 0xe7, 0x24, 0x86, 0x35, 0x1c, 0xe1, 0xbb, 0x1d, 0xe9, 0x35, 0x5d, 0xd4, 0xa6, 0xb9, 0x8c, 0xd3, 0xe1, 0xb2, 0xf1, 0x47, 0x43, 0x71, 0x20, 0xcb}}
+	return a, nil
+}
+
+var __1528395580_UpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x7c\x90\xc1\x6e\xc2\x30\x10\x44\xef\xf9\x8a\xb9\x44\xb9\x40\xc5\xb9\x11\x07\xb7\x76\x05\xaa\x49\x50\xea\xa8\xc7\x28\x4a\x36\x10\x09\xec\xd6\x76\x40\xf9\xfb\x96\xd4\xaa\x40\x45\x3d\xee\xcc\xea\xed\xcc\x32\xa9\x44\x01\xc5\x9e\xa4\x80\xab\x4f\xd4\x56\x9f\x03\xd9\xb1\xb2\x83\x76\x60\x9c\xe3\x39\x97\xe5\x26\x83\xa6\x73\x65\xc9\x0d\x07\x5f\x35\x66\xd0\x1e\xbd\xf6\xb4\x23\x8b\x2c\x57\xc8\x4a\x29\xc1\xc5\x0b\x2b\xa5\xc2\x22\x8d\xd8\xbf\xd0\xc9\x0c\xd8\x1b\x24\x2f\xf2\xed\x2f\x2f\x8d\xe6\x73\xf0\xbe\xeb\x50\xeb\x16\x8d\x39\x1e\x7b\x0f\x47\xb5\x6d\xf6\xe4\x60\xf4\x61\x0c\x13\x3a\x63\x2f\xf1\x02\xcb\xcd\xe0\x0c\xfc\x9e\x7a\x1b\x94\x0b\x68\x3a\xe0\x70\x26\x4b\x57\xbb\x41\x7e\x88\xca\x2d\x67\xea\x4e\xd6\x37\xa1\xfe\x34\x5f\x5e\x0f\xb3\x9b\x06\xcb\xe9\x11\xef\x2b\x51\x08\x4c\x14\xc8\xf5\xab\x40\x12\xfb\xf1\x83\x1e\xdb\xef\x32\x71\x82\xbc\xb8\xe3\xfd\xf4\x8b\x93\x34\xfa\x02\x00\x00\xff\xff\x01\x00\x00\xff\xff\x44\xb1\x76\x08\x91\x01\x00\x00")
+
+func _1528395580_UpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395580_UpSql,
+		"1528395580_.up.sql",
+	)
+}
+
+func _1528395580_UpSql() (*asset, error) {
+	bytes, err := _1528395580_UpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395580_.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x4d, 0xfe, 0x69, 0x55, 0xd5, 0x40, 0x69, 0xfb, 0x66, 0x25, 0x90, 0x23, 0xc7, 0xdb, 0x46, 0xb1, 0xa1, 0x92, 0x66, 0x26, 0x66, 0xae, 0x27, 0xdc, 0xdc, 0xa3, 0x6a, 0xcf, 0x3e, 0x70, 0xc, 0x3d}}
+	return a, nil
+}
+
 // Asset loads and returns the asset for the given name.
 // It returns an error if the asset could not be found or
 // could not be loaded.
@@ -4574,6 +5246,70 @@ var _bindata = map[string]func() (*asset, error){
 	"1528395564_.down.sql": _1528395564_DownSql,
 
 	"1528395564_.up.sql": _1528395564_UpSql,
+
+	"1528395565_.down.sql": _1528395565_DownSql,
+
+	"1528395565_.up.sql": _1528395565_UpSql,
+
+	"1528395566_.down.sql": _1528395566_DownSql,
+
+	"1528395566_.up.sql": _1528395566_UpSql,
+
+	"1528395567_.down.sql": _1528395567_DownSql,
+
+	"1528395567_.up.sql": _1528395567_UpSql,
+
+	"1528395568_.down.sql": _1528395568_DownSql,
+
+	"1528395568_.up.sql": _1528395568_UpSql,
+
+	"1528395569_.down.sql": _1528395569_DownSql,
+
+	"1528395569_.up.sql": _1528395569_UpSql,
+
+	"1528395570_.down.sql": _1528395570_DownSql,
+
+	"1528395570_.up.sql": _1528395570_UpSql,
+
+	"1528395571_.down.sql": _1528395571_DownSql,
+
+	"1528395571_.up.sql": _1528395571_UpSql,
+
+	"1528395572_.down.sql": _1528395572_DownSql,
+
+	"1528395572_.up.sql": _1528395572_UpSql,
+
+	"1528395573_.down.sql": _1528395573_DownSql,
+
+	"1528395573_.up.sql": _1528395573_UpSql,
+
+	"1528395574_.down.sql": _1528395574_DownSql,
+
+	"1528395574_.up.sql": _1528395574_UpSql,
+
+	"1528395575_.down.sql": _1528395575_DownSql,
+
+	"1528395575_.up.sql": _1528395575_UpSql,
+
+	"1528395576_.down.sql": _1528395576_DownSql,
+
+	"1528395576_.up.sql": _1528395576_UpSql,
+
+	"1528395577_.down.sql": _1528395577_DownSql,
+
+	"1528395577_.up.sql": _1528395577_UpSql,
+
+	"1528395578_.down.sql": _1528395578_DownSql,
+
+	"1528395578_.up.sql": _1528395578_UpSql,
+
+	"1528395579_.down.sql": _1528395579_DownSql,
+
+	"1528395579_.up.sql": _1528395579_UpSql,
+
+	"1528395580_.down.sql": _1528395580_DownSql,
+
+	"1528395580_.up.sql": _1528395580_UpSql,
 }
 
 // AssetDir returns the file names below a certain
@@ -4809,6 +5545,38 @@ var _bintree = &bintree{nil, map[string]*bintree{
 	"1528395563_.up.sql":                                          {_1528395563_UpSql, map[string]*bintree{}},
 	"1528395564_.down.sql":                                        {_1528395564_DownSql, map[string]*bintree{}},
 	"1528395564_.up.sql":                                          {_1528395564_UpSql, map[string]*bintree{}},
+	"1528395565_.down.sql":                                        {_1528395565_DownSql, map[string]*bintree{}},
+	"1528395565_.up.sql":                                          {_1528395565_UpSql, map[string]*bintree{}},
+	"1528395566_.down.sql":                                        {_1528395566_DownSql, map[string]*bintree{}},
+	"1528395566_.up.sql":                                          {_1528395566_UpSql, map[string]*bintree{}},
+	"1528395567_.down.sql":                                        {_1528395567_DownSql, map[string]*bintree{}},
+	"1528395567_.up.sql":                                          {_1528395567_UpSql, map[string]*bintree{}},
+	"1528395568_.down.sql":                                        {_1528395568_DownSql, map[string]*bintree{}},
+	"1528395568_.up.sql":                                          {_1528395568_UpSql, map[string]*bintree{}},
+	"1528395569_.down.sql":                                        {_1528395569_DownSql, map[string]*bintree{}},
+	"1528395569_.up.sql":                                          {_1528395569_UpSql, map[string]*bintree{}},
+	"1528395570_.down.sql":                                        {_1528395570_DownSql, map[string]*bintree{}},
+	"1528395570_.up.sql":                                          {_1528395570_UpSql, map[string]*bintree{}},
+	"1528395571_.down.sql":                                        {_1528395571_DownSql, map[string]*bintree{}},
+	"1528395571_.up.sql":                                          {_1528395571_UpSql, map[string]*bintree{}},
+	"1528395572_.down.sql":                                        {_1528395572_DownSql, map[string]*bintree{}},
+	"1528395572_.up.sql":                                          {_1528395572_UpSql, map[string]*bintree{}},
+	"1528395573_.down.sql":                                        {_1528395573_DownSql, map[string]*bintree{}},
+	"1528395573_.up.sql":                                          {_1528395573_UpSql, map[string]*bintree{}},
+	"1528395574_.down.sql":                                        {_1528395574_DownSql, map[string]*bintree{}},
+	"1528395574_.up.sql":                                          {_1528395574_UpSql, map[string]*bintree{}},
+	"1528395575_.down.sql":                                        {_1528395575_DownSql, map[string]*bintree{}},
+	"1528395575_.up.sql":                                          {_1528395575_UpSql, map[string]*bintree{}},
+	"1528395576_.down.sql":                                        {_1528395576_DownSql, map[string]*bintree{}},
+	"1528395576_.up.sql":                                          {_1528395576_UpSql, map[string]*bintree{}},
+	"1528395577_.down.sql":                                        {_1528395577_DownSql, map[string]*bintree{}},
+	"1528395577_.up.sql":                                          {_1528395577_UpSql, map[string]*bintree{}},
+	"1528395578_.down.sql":                                        {_1528395578_DownSql, map[string]*bintree{}},
+	"1528395578_.up.sql":                                          {_1528395578_UpSql, map[string]*bintree{}},
+	"1528395579_.down.sql":                                        {_1528395579_DownSql, map[string]*bintree{}},
+	"1528395579_.up.sql":                                          {_1528395579_UpSql, map[string]*bintree{}},
+	"1528395580_.down.sql":                                        {_1528395580_DownSql, map[string]*bintree{}},
+	"1528395580_.up.sql":                                          {_1528395580_UpSql, map[string]*bintree{}},
 }}
 
 // RestoreAsset restores an asset under the given directory.
@@ -110,6 +110,30 @@ func (s SettingsSubject) String() string {
 	}
 }
 
+// ParseSettingsSubject parses the string representation of a settings subject
+// (returned by its String method).
+func ParseSettingsSubject(s string) (SettingsSubject, error) {
+	switch s {
+	case "DefaultSettings":
+		return SettingsSubject{Default: true}, nil
+	case "site":
+		return SettingsSubject{Site: true}, nil
+	}
+	var (
+		kind string
+		id   int32
+	)
+	if _, err := fmt.Sscanf(s, "%s %d", &kind, &id); err == nil {
+		switch kind {
+		case "org":
+			return SettingsSubject{Org: &id}, nil
+		case "user":
+			return SettingsSubject{User: &id}, nil
+		}
+	}
+	return SettingsSubject{}, fmt.Errorf("invalid settings subject %q", s)
+}
+
 // Settings contains settings for a subject.
 type Settings struct {
 	ID           int32           // the unique ID of this settings value
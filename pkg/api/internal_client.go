@@ -91,12 +91,18 @@ type SavedQueryIDSpec struct {
 // ConfigSavedQuery is the JSON shape of a saved query entry in the JSON configuration
 // (i.e., an entry in the {"search.savedQueries": [...]} array).
 type ConfigSavedQuery struct {
-	Key            string `json:"key,omitempty"`
-	Description    string `json:"description"`
-	Query          string `json:"query"`
-	ShowOnHomepage bool   `json:"showOnHomepage"`
-	Notify         bool   `json:"notify,omitempty"`
-	NotifySlack    bool   `json:"notifySlack,omitempty"`
+	Key            string                    `json:"key,omitempty"`
+	Description    string                    `json:"description"`
+	Query          string                    `json:"query"`
+	ShowOnHomepage bool                      `json:"showOnHomepage"`
+	Notify         bool                      `json:"notify,omitempty"`
+	NotifySlack    bool                      `json:"notifySlack,omitempty"`
+	NotifyWebhook  *schema.SavedQueryWebhook `json:"notifyWebhook,omitempty"`
+	Monitor        *schema.SavedQueryMonitor `json:"monitor,omitempty"`
+
+	// NotifyFrequency is the default notification frequency of the saved
+	// query's recipients (see SavedQueryNotificationFrequency*).
+	NotifyFrequency string `json:"notifyFrequency,omitempty"`
 }
 
 func (sq ConfigSavedQuery) Equals(other ConfigSavedQuery) bool {
@@ -169,6 +175,251 @@ func (c *internalClient) SavedQueriesDeleteInfo(ctx context.Context, query strin
 	return c.postInternal(ctx, "saved-queries/delete-info", query, nil)
 }
 
+// SavedQueryMatch is a single line matched by a saved search query on file
+// contents.
+type SavedQueryMatch struct {
+	Repo    RepoName
+	Path    string
+	Line    int // 1-indexed
+	Preview string
+}
+
+// SavedQuerySnapshot is the set of lines matched by a saved search query on
+// file contents the last time that it was executed. Because file content
+// searches do not support the after:"time" operator, new and removed results
+// are found by comparing each execution's matches against the snapshot.
+type SavedQuerySnapshot struct {
+	// Query is the search query in question.
+	Query string
+
+	// Matches is the list of lines matched by the search query.
+	Matches []*SavedQueryMatch
+}
+
+// SavedQueriesGetSnapshot gets the snapshot from the DB for the given saved
+// query. nil is returned if there is no existing snapshot for the saved query.
+func (c *internalClient) SavedQueriesGetSnapshot(ctx context.Context, query string) (*SavedQuerySnapshot, error) {
+	var result *SavedQuerySnapshot
+	err := c.postInternal(ctx, "saved-queries/get-snapshot", query, &result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// SavedQueriesSetSnapshot sets the snapshot in the DB for the given query.
+func (c *internalClient) SavedQueriesSetSnapshot(ctx context.Context, snapshot *SavedQuerySnapshot) error {
+	return c.postInternal(ctx, "saved-queries/set-snapshot", snapshot, nil)
+}
+
+// SavedQueryWebhookDelivery is a single attempt to deliver a notification to a
+// saved query's webhook.
+type SavedQueryWebhookDelivery struct {
+	// Spec identifies the saved query.
+	Spec SavedQueryIDSpec
+
+	// DeliveryID identifies the notification. All attempts to deliver the
+	// same notification have the same DeliveryID.
+	DeliveryID string
+
+	// Event is the type of notification (such as "results" or "test").
+	Event string
+
+	// URL is the webhook URL.
+	URL string
+
+	// Attempt is the 1-indexed number of the attempt.
+	Attempt int
+
+	// StatusCode is the HTTP status code of the response, or 0 if no response
+	// was received.
+	StatusCode int
+
+	// Error describes why the attempt failed, or is empty if it succeeded.
+	Error string
+
+	// Duration is the amount of time the attempt took.
+	Duration time.Duration
+
+	// CreatedAt is when the attempt was logged.
+	CreatedAt time.Time
+}
+
+// SavedQueriesLogWebhookDelivery logs an attempt to deliver a notification to
+// a saved query's webhook.
+func (c *internalClient) SavedQueriesLogWebhookDelivery(ctx context.Context, delivery *SavedQueryWebhookDelivery) error {
+	return c.postInternal(ctx, "saved-queries/log-webhook-delivery", delivery, nil)
+}
+
+// SavedQueryRun is a record of a single execution of a saved search query.
+type SavedQueryRun struct {
+	// Query is the search query in question.
+	Query string
+
+	// ExecutedAt is when the search query was executed.
+	ExecutedAt time.Time
+
+	// ExecDuration is the amount of time it took for the query to execute.
+	ExecDuration time.Duration
+
+	// ResultCount is the total number of matches, or nil if it is not known.
+	// It is only known for runs of searches on file contents on all
+	// repositories: diff and commit searches only search for results since
+	// the previous execution, and runs on repository updates only search the
+	// updated repository.
+	ResultCount *int
+
+	// NewResultCount is the number of results that the previous execution
+	// did not find.
+	NewResultCount int
+
+	// LimitHit is whether the search stopped before finding all results.
+	LimitHit bool
+
+	// Error describes why the execution failed, or is empty if it succeeded.
+	Error string
+
+	// NewResults is a sample of the new results found by the execution.
+	NewResults []*SavedQueryRunResult
+}
+
+// SavedQueryRunResult is a new search result found by an execution of a saved
+// search query. A commit result has Commit set (and Preview is the commit
+// message); a file content result has Path (and Line and Preview, unless it's
+// a match on the path only) set.
+type SavedQueryRunResult struct {
+	Repo    RepoName
+	Commit  CommitID `json:",omitempty"`
+	Path    string   `json:",omitempty"`
+	Line    int      `json:",omitempty"` // 1-indexed
+	Preview string   `json:",omitempty"`
+}
+
+// SavedQueriesLogRun records an execution of a saved search query in its
+// history.
+func (c *internalClient) SavedQueriesLogRun(ctx context.Context, run *SavedQueryRun) error {
+	return c.postInternal(ctx, "saved-queries/log-run", run, nil)
+}
+
+// SavedQueriesListRunsArgs are the arguments to SavedQueriesListRuns.
+type SavedQueriesListRunsArgs struct {
+	Query string
+	Limit int
+}
+
+// SavedQueriesListRuns returns the most recent runs of the saved search query,
+// newest first.
+func (c *internalClient) SavedQueriesListRuns(ctx context.Context, query string, limit int) ([]*SavedQueryRun, error) {
+	var runs []*SavedQueryRun
+	err := c.postInternal(ctx, "saved-queries/list-runs", &SavedQueriesListRunsArgs{Query: query, Limit: limit}, &runs)
+	if err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+// Saved query notification frequencies.
+const (
+	SavedQueryNotificationFrequencyImmediate = "immediate"
+	SavedQueryNotificationFrequencyHourly    = "hourly"
+	SavedQueryNotificationFrequencyDaily     = "daily"
+)
+
+// SavedQueryNotificationPreference is a user's preference for the
+// notifications they receive for a saved query.
+type SavedQueryNotificationPreference struct {
+	UserID int32
+
+	// Frequency is how often the user is notified (see
+	// SavedQueryNotificationFrequency*), or empty to use the saved query's
+	// default.
+	Frequency string
+
+	// Unsubscribed is whether the user opted out of (muted) all email and
+	// Slack notifications for the saved query.
+	Unsubscribed bool
+
+	// Subscribed is whether the user opted in to notifications for an
+	// organization's saved query, even if the saved query doesn't notify all
+	// organization members.
+	Subscribed bool
+
+	// Email and Slack are whether the user receives notifications for an
+	// organization's saved query by email and on Slack (to the Slack webhook
+	// in their user settings), or nil to use the default (email only).
+	Email, Slack *bool
+}
+
+var MockSavedQueriesGetNotificationPreferences func(spec SavedQueryIDSpec) ([]*SavedQueryNotificationPreference, error)
+
+// SavedQueriesGetNotificationPreferences returns the notification preferences
+// of all users who set them for the saved query.
+func (c *internalClient) SavedQueriesGetNotificationPreferences(ctx context.Context, spec SavedQueryIDSpec) ([]*SavedQueryNotificationPreference, error) {
+	if MockSavedQueriesGetNotificationPreferences != nil {
+		return MockSavedQueriesGetNotificationPreferences(spec)
+	}
+	var prefs []*SavedQueryNotificationPreference
+	err := c.postInternal(ctx, "saved-queries/get-notification-preferences", spec, &prefs)
+	if err != nil {
+		return nil, err
+	}
+	return prefs, nil
+}
+
+// SavedQueryDigestItem is a notification about new results of a saved query
+// that is held back to be sent to a recipient in an hourly or daily digest.
+type SavedQueryDigestItem struct {
+	ID int64
+
+	// Spec identifies the saved query.
+	Spec SavedQueryIDSpec
+
+	// RecipientUserID or RecipientOrgID (exactly one of which is nonzero)
+	// identifies the recipient.
+	RecipientUserID, RecipientOrgID int32
+
+	// Channel is how the digest is sent ("email" or "slack").
+	Channel string
+
+	// Frequency is SavedQueryNotificationFrequencyHourly or
+	// SavedQueryNotificationFrequencyDaily.
+	Frequency string
+
+	// Description is the saved query's description.
+	Description string
+
+	// Query is the search query that finds the new results.
+	Query string
+
+	// AddedCount and RemovedCount are the number of new and removed results.
+	AddedCount, RemovedCount int
+
+	// CreatedAt is when the notification was queued.
+	CreatedAt time.Time
+}
+
+// SavedQueriesAddDigestItem queues a notification to be sent in a digest.
+func (c *internalClient) SavedQueriesAddDigestItem(ctx context.Context, item *SavedQueryDigestItem) error {
+	return c.postInternal(ctx, "saved-queries/add-digest-item", item, nil)
+}
+
+// SavedQueriesListDigestItems returns all notifications queued to be sent in
+// digests, oldest first.
+func (c *internalClient) SavedQueriesListDigestItems(ctx context.Context) ([]*SavedQueryDigestItem, error) {
+	var items []*SavedQueryDigestItem
+	err := c.postInternal(ctx, "saved-queries/list-digest-items", nil, &items)
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// SavedQueriesDeleteDigestItems removes notifications (that were sent in a
+// digest) from the queue.
+func (c *internalClient) SavedQueriesDeleteDigestItems(ctx context.Context, ids []int64) error {
+	return c.postInternal(ctx, "saved-queries/delete-digest-items", ids, nil)
+}
+
 func (c *internalClient) SettingsGetForSubject(ctx context.Context, subject SettingsSubject) (parsed *schema.Settings, settings *Settings, err error) {
 	err = c.postInternal(ctx, "settings/get-for-subject", subject, &settings)
 	if err == nil {
@@ -179,6 +430,28 @@ func (c *internalClient) SettingsGetForSubject(ctx context.Context, subject Sett
 
 var MockOrgsListUsers func(orgID int32) (users []int32, err error)
 
+// DiscussionsCreateThreadArgs describes a discussion thread to create on
+// behalf of a user.
+type DiscussionsCreateThreadArgs struct {
+	AuthorUserID int32
+	Title        string
+	Contents     string
+
+	// Repo is the repository that the thread is about. If Path is set, the
+	// thread is about that file, and if Line is also set, about that
+	// (1-indexed) line, whose contents are Preview.
+	Repo    RepoName
+	Path    string
+	Line    int
+	Preview string
+}
+
+// DiscussionsCreateThread creates a discussion thread and returns its ID.
+func (c *internalClient) DiscussionsCreateThread(ctx context.Context, args *DiscussionsCreateThreadArgs) (threadID int64, err error) {
+	err = c.postInternal(ctx, "discussions/create-thread", args, &threadID)
+	return threadID, err
+}
+
 func (c *internalClient) OrgsListUsers(ctx context.Context, orgID int32) (users []int32, err error) {
 	if MockOrgsListUsers != nil {
 		return MockOrgsListUsers(orgID)
@@ -190,9 +190,25 @@ func CanSendEmail() bool {
 	return Get().EmailSmtp != nil
 }
 
-// CanReadEmail tells if an IMAP server is configured and reading email is possible.
+// CanReadEmail tells if an IMAP server or an inbound email provider is configured and reading
+// email is possible.
 func CanReadEmail() bool {
-	return Get().EmailImap != nil
+	c := Get()
+	return c.EmailImap != nil || c.EmailInbound != nil
+}
+
+// InboundEmailAddress returns the email address that receives email (such as code discussion
+// reply emails). It is the address of the configured inbound email provider, if any, and otherwise
+// the IMAP username.
+func InboundEmailAddress() string {
+	c := Get()
+	if c.EmailInbound != nil {
+		return c.EmailInbound.Address
+	}
+	if c.EmailImap != nil {
+		return c.EmailImap.Username
+	}
+	return ""
 }
 
 const (
@@ -247,6 +263,16 @@ func UpdateChannel() string {
 	return channel
 }
 
+// HighlightEngine tells which syntax highlighting engine to use, either
+// "syntect" or "builtin". Default is "syntect".
+func HighlightEngine() string {
+	engine := Get().HighlightEngine
+	if engine == "" {
+		return "syntect"
+	}
+	return engine
+}
+
 // SearchIndexEnabled returns true if sourcegraph should index all
 // repositories for text search. If the configuration is unset, it returns
 // false for the docker server image (due to resource usage) but true
@@ -117,6 +117,117 @@ func (c *Client) RecentRepos(ctx context.Context, pageToken *PageToken) ([]*Repo
 	return resp.Values, resp.PageToken, nil
 }
 
+// Users lists the users whose username, name or email address match the
+// filter. It requires the LICENSED_USER permission.
+func (c *Client) Users(ctx context.Context, filter string, pageToken *PageToken) ([]*User, *PageToken, error) {
+	var users []*User
+	next, err := c.page(ctx, "rest/api/1.0/users", url.Values{"filter": {filter}}, pageToken, &users)
+	return users, next, err
+}
+
+// UserGroups lists the groups that the user is a member of. It requires the
+// ADMIN permission.
+func (c *Client) UserGroups(ctx context.Context, username string, pageToken *PageToken) ([]*Group, *PageToken, error) {
+	var groups []*Group
+	next, err := c.page(ctx, "rest/api/1.0/admin/users/more-members", url.Values{"context": {username}}, pageToken, &groups)
+	return groups, next, err
+}
+
+// GlobalUserPermissions lists the users who have a global permission (such as
+// ADMIN). It requires the ADMIN permission.
+func (c *Client) GlobalUserPermissions(ctx context.Context, pageToken *PageToken) ([]*UserPermission, *PageToken, error) {
+	var perms []*UserPermission
+	next, err := c.page(ctx, "rest/api/1.0/admin/permissions/users", nil, pageToken, &perms)
+	return perms, next, err
+}
+
+// GlobalGroupPermissions lists the groups that have a global permission (such
+// as ADMIN). It requires the ADMIN permission.
+func (c *Client) GlobalGroupPermissions(ctx context.Context, pageToken *PageToken) ([]*GroupPermission, *PageToken, error) {
+	var perms []*GroupPermission
+	next, err := c.page(ctx, "rest/api/1.0/admin/permissions/groups", nil, pageToken, &perms)
+	return perms, next, err
+}
+
+// ProjectUserPermissions lists the users who have been granted a permission on
+// the project. It requires the PROJECT_ADMIN permission.
+func (c *Client) ProjectUserPermissions(ctx context.Context, projectKey string, pageToken *PageToken) ([]*UserPermission, *PageToken, error) {
+	var perms []*UserPermission
+	next, err := c.page(ctx, fmt.Sprintf("rest/api/1.0/projects/%s/permissions/users", projectKey), nil, pageToken, &perms)
+	return perms, next, err
+}
+
+// ProjectGroupPermissions lists the groups that have been granted a permission
+// on the project. It requires the PROJECT_ADMIN permission.
+func (c *Client) ProjectGroupPermissions(ctx context.Context, projectKey string, pageToken *PageToken) ([]*GroupPermission, *PageToken, error) {
+	var perms []*GroupPermission
+	next, err := c.page(ctx, fmt.Sprintf("rest/api/1.0/projects/%s/permissions/groups", projectKey), nil, pageToken, &perms)
+	return perms, next, err
+}
+
+// ProjectDefaultPermission tells whether all users who are signed in to
+// Bitbucket Server have the permission (such as PROJECT_READ) on the project.
+func (c *Client) ProjectDefaultPermission(ctx context.Context, projectKey string, perm Perm) (bool, error) {
+	u := fmt.Sprintf("rest/api/1.0/projects/%s/permissions/%s/all", projectKey, perm)
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return false, err
+	}
+	var resp struct {
+		Permitted bool `json:"permitted"`
+	}
+	err = c.do(ctx, req, &resp)
+	return resp.Permitted, err
+}
+
+// RepoUserPermissions lists the users who have been granted a permission on
+// the repository. It requires the REPO_ADMIN permission.
+func (c *Client) RepoUserPermissions(ctx context.Context, projectKey, repoSlug string, pageToken *PageToken) ([]*UserPermission, *PageToken, error) {
+	var perms []*UserPermission
+	next, err := c.page(ctx, fmt.Sprintf("rest/api/1.0/projects/%s/repos/%s/permissions/users", projectKey, repoSlug), nil, pageToken, &perms)
+	return perms, next, err
+}
+
+// RepoGroupPermissions lists the groups that have been granted a permission on
+// the repository. It requires the REPO_ADMIN permission.
+func (c *Client) RepoGroupPermissions(ctx context.Context, projectKey, repoSlug string, pageToken *PageToken) ([]*GroupPermission, *PageToken, error) {
+	var perms []*GroupPermission
+	next, err := c.page(ctx, fmt.Sprintf("rest/api/1.0/projects/%s/repos/%s/permissions/groups", projectKey, repoSlug), nil, pageToken, &perms)
+	return perms, next, err
+}
+
+// page gets a page of results from a paged API endpoint, decoding the page's
+// values into values (which must be a pointer to a slice).
+func (c *Client) page(ctx context.Context, path string, qry url.Values, pageToken *PageToken, values interface{}) (*PageToken, error) {
+	if qry == nil {
+		qry = url.Values{}
+	}
+	for k, vs := range pageToken.Values() {
+		qry[k] = vs
+	}
+	u := path
+	if len(qry) > 0 {
+		u += "?" + qry.Encode()
+	}
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		*PageToken
+		Values interface{} `json:"values"`
+	}
+	resp.Values = values
+	if err := c.do(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	if resp.PageToken == nil {
+		// Don't let callers page forever if the response is missing paging info.
+		return &PageToken{IsLastPage: true}, nil
+	}
+	return resp.PageToken, nil
+}
+
 func (c *Client) do(ctx context.Context, req *http.Request, result interface{}) error {
 	req.URL = c.URL.ResolveReference(req.URL)
 	req.Header.Set("Content-Type", "application/json; charset=utf-8")
@@ -165,20 +276,26 @@ func (t *PageToken) HasMore() bool {
 }
 
 func (t *PageToken) Query() string {
-	if t == nil {
+	v := t.Values()
+	if len(v) == 0 {
 		return ""
 	}
+	return "?" + v.Encode()
+}
+
+// Values returns the query parameters that request the next page.
+func (t *PageToken) Values() url.Values {
 	v := url.Values{}
+	if t == nil {
+		return v
+	}
 	if t.NextPageStart != 0 {
 		v.Set("start", strconv.Itoa(t.NextPageStart))
 	}
 	if t.Limit != 0 {
 		v.Set("limit", strconv.Itoa(t.Limit))
 	}
-	if len(v) == 0 {
-		return ""
-	}
-	return "?" + v.Encode()
+	return v
 }
 
 type Repo struct {
@@ -221,6 +338,50 @@ type Project struct {
 	} `json:"links"`
 }
 
+// User is a Bitbucket Server user.
+type User struct {
+	Name         string `json:"name"`
+	EmailAddress string `json:"emailAddress"`
+	ID           int    `json:"id"`
+	DisplayName  string `json:"displayName"`
+	Active       bool   `json:"active"`
+	Slug         string `json:"slug"`
+	Type         string `json:"type"`
+}
+
+// Group is a Bitbucket Server group of users.
+type Group struct {
+	Name string `json:"name"`
+}
+
+// Perm is a Bitbucket Server permission.
+type Perm string
+
+// Permissions that are relevant to reading repositories. See
+// https://confluence.atlassian.com/bitbucketserver/users-and-groups-776640439.html.
+const (
+	PermSysAdmin     Perm = "SYS_ADMIN"
+	PermAdmin        Perm = "ADMIN"
+	PermProjectRead  Perm = "PROJECT_READ"
+	PermProjectWrite Perm = "PROJECT_WRITE"
+	PermProjectAdmin Perm = "PROJECT_ADMIN"
+	PermRepoRead     Perm = "REPO_READ"
+	PermRepoWrite    Perm = "REPO_WRITE"
+	PermRepoAdmin    Perm = "REPO_ADMIN"
+)
+
+// UserPermission is a permission granted to a user.
+type UserPermission struct {
+	User       *User `json:"user"`
+	Permission Perm  `json:"permission"`
+}
+
+// GroupPermission is a permission granted to a group.
+type GroupPermission struct {
+	Group      *Group `json:"group"`
+	Permission Perm   `json:"permission"`
+}
+
 type httpError struct {
 	StatusCode int
 	URL        *url.URL
@@ -0,0 +1,88 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// PullRequest is a GitHub pull request.
+type PullRequest struct {
+	Number  int32  // number of the pull request in its repository
+	State   string // "open" or "closed"
+	HTMLURL string `json:"html_url"` // web URL
+	Head    struct {
+		Ref string // name of the branch
+		SHA string // commit ID
+	}
+}
+
+// ListOpenPullRequestsForBranch lists the open pull requests in the repository whose head is the
+// given branch of the same repository.
+//
+// https://developer.github.com/v3/pulls/#list-pull-requests
+func (c *Client) ListOpenPullRequestsForBranch(ctx context.Context, token, owner, name, branch string) ([]*PullRequest, error) {
+	q := url.Values{}
+	q.Set("state", "open")
+	q.Set("head", owner+":"+branch)
+	var prs []*PullRequest
+	if err := c.requestGet(ctx, token, fmt.Sprintf("/repos/%s/%s/pulls?%s", owner, name, q.Encode()), &prs); err != nil {
+		return nil, err
+	}
+	return prs, nil
+}
+
+// ReviewComment is a comment on the diff of a GitHub pull request.
+type ReviewComment struct {
+	ID          int64
+	Body        string
+	Path        string
+	InReplyToID int64 `json:"in_reply_to_id"` // ID of the first comment of the thread (0 if this is the first)
+	User        struct {
+		ID    int64
+		Login string
+	}
+	HTMLURL   string    `json:"html_url"` // web URL
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewReviewComment describes a new comment on the diff of a GitHub pull request. Either InReplyTo
+// or CommitID, Path and Line must be set.
+type NewReviewComment struct {
+	Body      string `json:"body"`
+	InReplyTo int64  `json:"in_reply_to,omitempty"`
+
+	CommitID  string `json:"commit_id,omitempty"`
+	Path      string `json:"path,omitempty"`
+	Line      int32  `json:"line,omitempty"`       // 1-based line number in the head commit
+	Side      string `json:"side,omitempty"`       // always "RIGHT" (the head commit)
+	StartLine int32  `json:"start_line,omitempty"` // for multi-line comments, the first line
+	StartSide string `json:"start_side,omitempty"`
+}
+
+// CreateReviewComment creates a comment on the diff of a pull request (or a reply to one).
+//
+// https://developer.github.com/v3/pulls/comments/#create-a-comment
+func (c *Client) CreateReviewComment(ctx context.Context, token, owner, name string, number int32, comment *NewReviewComment) (*ReviewComment, error) {
+	var result ReviewComment
+	if err := c.requestPost(ctx, token, fmt.Sprintf("/repos/%s/%s/pulls/%d/comments", owner, name, number), comment, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// reviewCommentsPerPage is the maximum page size of the review comments API.
+const reviewCommentsPerPage = 100
+
+// ListReviewComments lists the comments on the diff of a pull request, oldest first. page is the
+// page of results to return. Pages are 1-indexed (so the first call should be for page 1).
+//
+// https://developer.github.com/v3/pulls/comments/#list-comments-on-a-pull-request
+func (c *Client) ListReviewComments(ctx context.Context, token, owner, name string, number int32, page int) (comments []*ReviewComment, hasNextPage bool, err error) {
+	path := fmt.Sprintf("/repos/%s/%s/pulls/%d/comments?per_page=%d&page=%d", owner, name, number, reviewCommentsPerPage, page)
+	if err := c.requestGet(ctx, token, path, &comments); err != nil {
+		return nil, false, err
+	}
+	return comments, len(comments) == reviewCommentsPerPage, nil
+}
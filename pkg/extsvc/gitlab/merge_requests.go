@@ -0,0 +1,144 @@
+package gitlab
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// MergeRequest is a GitLab merge request (equivalent to a GitHub pull request).
+type MergeRequest struct {
+	ID           int    `json:"id"`
+	IID          int32  `json:"iid"`           // number of the merge request in its project
+	State        string `json:"state"`         // "opened", "closed", "locked", or "merged"
+	SourceBranch string `json:"source_branch"` // name of the branch
+	SHA          string `json:"sha"`           // commit ID of the head of the source branch
+	WebURL       string `json:"web_url"`
+
+	// DiffRefs is only set when getting a single merge request (not when listing them).
+	DiffRefs *DiffRefs `json:"diff_refs,omitempty"`
+}
+
+// DiffRefs are the commits that a merge request's diff is between.
+type DiffRefs struct {
+	BaseSHA  string `json:"base_sha"`
+	HeadSHA  string `json:"head_sha"`
+	StartSHA string `json:"start_sha"`
+}
+
+// ListOpenMergeRequestsForBranch lists the open merge requests in the project whose source is the
+// given branch.
+//
+// https://docs.gitlab.com/ee/api/merge_requests.html#list-project-merge-requests
+func (c *Client) ListOpenMergeRequestsForBranch(ctx context.Context, projID int, branch string) (mrs []*MergeRequest, err error) {
+	q := url.Values{}
+	q.Set("state", "opened")
+	q.Set("source_branch", branch)
+	req, err := http.NewRequest("GET", fmt.Sprintf("projects/%d/merge_requests?%s", projID, q.Encode()), nil)
+	if err != nil {
+		return nil, err
+	}
+	_, err = c.do(ctx, req, &mrs)
+	return mrs, err
+}
+
+// GetMergeRequest gets a merge request (including its diff refs).
+//
+// https://docs.gitlab.com/ee/api/merge_requests.html#get-single-mr
+func (c *Client) GetMergeRequest(ctx context.Context, projID int, iid int32) (*MergeRequest, error) {
+	req, err := http.NewRequest("GET", fmt.Sprintf("projects/%d/merge_requests/%d", projID, iid), nil)
+	if err != nil {
+		return nil, err
+	}
+	var mr MergeRequest
+	if _, err := c.do(ctx, req, &mr); err != nil {
+		return nil, err
+	}
+	return &mr, nil
+}
+
+// Discussion is a thread of notes (comments) on a GitLab merge request.
+type Discussion struct {
+	ID    string  `json:"id"`
+	Notes []*Note `json:"notes"`
+}
+
+// Note is a comment in a discussion on a GitLab merge request.
+type Note struct {
+	ID        int64     `json:"id"`
+	Body      string    `json:"body"`
+	Author    User      `json:"author"`
+	System    bool      `json:"system"` // whether the note was created by GitLab (e.g. "changed this line")
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DiffPosition is the position of a new discussion on the diff of a merge request.
+type DiffPosition struct {
+	DiffRefs
+	PositionType string `json:"position_type"` // always "text"
+	OldPath      string `json:"old_path"`
+	NewPath      string `json:"new_path"`
+	NewLine      int32  `json:"new_line"` // 1-based line number in the head commit
+}
+
+// CreateMergeRequestDiscussion creates a discussion on the diff of a merge request.
+//
+// https://docs.gitlab.com/ee/api/discussions.html#create-new-merge-request-thread
+func (c *Client) CreateMergeRequestDiscussion(ctx context.Context, projID int, iid int32, body string, position *DiffPosition) (*Discussion, error) {
+	reqBody, err := json.Marshal(struct {
+		Body     string        `json:"body"`
+		Position *DiffPosition `json:"position"`
+	}{Body: body, Position: position})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("POST", fmt.Sprintf("projects/%d/merge_requests/%d/discussions", projID, iid), bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, err
+	}
+	var discussion Discussion
+	if _, err := c.do(ctx, req, &discussion); err != nil {
+		return nil, err
+	}
+	return &discussion, nil
+}
+
+// GetMergeRequestDiscussion gets a discussion on a merge request, including all of its notes.
+//
+// https://docs.gitlab.com/ee/api/discussions.html#get-single-merge-request-discussion-item
+func (c *Client) GetMergeRequestDiscussion(ctx context.Context, projID int, iid int32, discussionID string) (*Discussion, error) {
+	req, err := http.NewRequest("GET", fmt.Sprintf("projects/%d/merge_requests/%d/discussions/%s", projID, iid, url.PathEscape(discussionID)), nil)
+	if err != nil {
+		return nil, err
+	}
+	var discussion Discussion
+	if _, err := c.do(ctx, req, &discussion); err != nil {
+		return nil, err
+	}
+	return &discussion, nil
+}
+
+// AddMergeRequestDiscussionNote adds a note (a reply) to a discussion on a merge request.
+//
+// https://docs.gitlab.com/ee/api/discussions.html#add-note-to-existing-merge-request-thread
+func (c *Client) AddMergeRequestDiscussionNote(ctx context.Context, projID int, iid int32, discussionID, body string) (*Note, error) {
+	reqBody, err := json.Marshal(struct {
+		Body string `json:"body"`
+	}{Body: body})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("POST", fmt.Sprintf("projects/%d/merge_requests/%d/discussions/%s/notes", projID, iid, url.PathEscape(discussionID)), bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, err
+	}
+	var note Note
+	if _, err := c.do(ctx, req, &note); err != nil {
+		return nil, err
+	}
+	return &note, nil
+}
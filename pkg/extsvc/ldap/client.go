@@ -0,0 +1,186 @@
+package ldap
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ErrInvalidCredentials is when a bind fails because the DN or password is incorrect, or when the
+// user to authenticate does not exist (which is deliberately indistinguishable).
+var ErrInvalidCredentials = errors.New("invalid LDAP credentials")
+
+// Conn is a connection to an LDAP directory.
+type Conn interface {
+	// Bind authenticates the connection as the entry with the given DN. It returns
+	// ErrInvalidCredentials if the DN or password is incorrect.
+	Bind(dn, password string) error
+
+	// Search returns the entries in the subtree of baseDN that match the filter, with only the given
+	// attributes.
+	Search(baseDN, filter string, attributes []string) ([]*Entry, error)
+
+	Close() error
+}
+
+// Entry is an entry in an LDAP directory.
+type Entry struct {
+	DN         string
+	Attributes map[string][]string
+}
+
+// Attribute returns the first value of the attribute, or "" if the entry has no such attribute.
+// Attribute names are case-insensitive.
+func (e *Entry) Attribute(name string) string {
+	for k, v := range e.Attributes {
+		if strings.EqualFold(k, name) && len(v) > 0 {
+			return v[0]
+		}
+	}
+	return ""
+}
+
+// Client searches an LDAP directory for users and groups, and authenticates users.
+type Client struct {
+	// Dial opens a new connection to the directory (usually by calling the Dial func in this
+	// package).
+	Dial func() (Conn, error)
+
+	// BindDN and BindPassword are the credentials of the service account used to search the
+	// directory. If BindDN is empty, the directory is searched anonymously.
+	BindDN, BindPassword string
+
+	// UserBaseDN and UserFilter select the entries of the users who may authenticate, and
+	// UsernameAttribute is the attribute that users authenticate with.
+	UserBaseDN, UserFilter, UsernameAttribute string
+
+	// UserAttributes are the attributes of user entries to return (in addition to
+	// UsernameAttribute).
+	UserAttributes []string
+
+	// GroupBaseDN and GroupFilter select group entries, GroupMemberAttribute is the attribute of
+	// group entries that contains the DNs of their members, and GroupNameAttribute is the attribute
+	// of group entries that contains their name.
+	GroupBaseDN, GroupFilter, GroupMemberAttribute, GroupNameAttribute string
+}
+
+// dial opens a new connection to the directory, bound as the service account (if any).
+func (c *Client) dial() (Conn, error) {
+	conn, err := c.Dial()
+	if err != nil {
+		return nil, errors.Wrap(err, "connecting to LDAP server")
+	}
+	if c.BindDN != "" {
+		if err := conn.Bind(c.BindDN, c.BindPassword); err != nil {
+			conn.Close()
+			return nil, errors.Wrap(err, "binding as LDAP service account")
+		}
+	}
+	return conn, nil
+}
+
+// Authenticate checks the password of the user with the given username, and returns the user's
+// entry. It returns ErrInvalidCredentials if there is no such user or if the password is
+// incorrect.
+func (c *Client) Authenticate(username, password string) (*Entry, error) {
+	// 🚨 SECURITY: Most LDAP servers treat a bind with an empty password as an unauthenticated bind
+	// (RFC 4513 section 5.1.2), which succeeds for any DN.
+	if username == "" || password == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	conn, err := c.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	user, err := c.lookUpUser(conn, username)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrInvalidCredentials
+	}
+	if err := conn.Bind(user.DN, password); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// lookUpUser returns the entry of the user with the given username, or nil if there is none.
+func (c *Client) lookUpUser(conn Conn, username string) (*Entry, error) {
+	// 🚨 SECURITY: The username must be escaped to prevent LDAP filter injection.
+	filter := fmt.Sprintf("(&%s(%s=%s))", wrapFilter(c.UserFilter), c.UsernameAttribute, EscapeFilter(username))
+	attributes := append([]string{c.UsernameAttribute}, c.UserAttributes...)
+	entries, err := conn.Search(c.UserBaseDN, filter, attributes)
+	if err != nil {
+		return nil, errors.Wrap(err, "searching for LDAP user")
+	}
+	switch len(entries) {
+	case 0:
+		return nil, nil
+	case 1:
+		return entries[0], nil
+	default:
+		// 🚨 SECURITY: Refuse to guess which entry the user meant, because each has its own password.
+		return nil, fmt.Errorf("%d LDAP entries match username %q (the username attribute must be unique)", len(entries), username)
+	}
+}
+
+// Groups returns the sorted names of the groups that the entry with the given DN is a member of.
+func (c *Client) Groups(dn string) ([]string, error) {
+	conn, err := c.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	filter := fmt.Sprintf("(&%s(%s=%s))", wrapFilter(c.GroupFilter), c.GroupMemberAttribute, EscapeFilter(dn))
+	entries, err := conn.Search(c.GroupBaseDN, filter, []string{c.GroupNameAttribute})
+	if err != nil {
+		return nil, errors.Wrap(err, "searching for LDAP groups")
+	}
+	groups := make([]string, 0, len(entries))
+	seen := make(map[string]struct{}, len(entries))
+	for _, e := range entries {
+		name := e.Attribute(c.GroupNameAttribute)
+		if _, ok := seen[name]; ok || name == "" {
+			continue
+		}
+		seen[name] = struct{}{}
+		groups = append(groups, name)
+	}
+	sort.Strings(groups)
+	return groups, nil
+}
+
+// wrapFilter wraps the filter in parentheses if it isn't already (as is commonly omitted in
+// configuration), so that it can be combined with other filters.
+func wrapFilter(filter string) string {
+	if filter == "" {
+		return "(objectClass=*)"
+	}
+	if !strings.HasPrefix(filter, "(") {
+		return "(" + filter + ")"
+	}
+	return filter
+}
+
+// EscapeFilter escapes a value for use in an LDAP search filter (as specified in RFC 4515 section
+// 3), so that it can only be matched literally.
+func EscapeFilter(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case c == '*' || c == '(' || c == ')' || c == '\\' || c == 0 || c > 0x7f:
+			fmt.Fprintf(&b, `\%02x`, c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,129 @@
+package ldap
+
+import (
+	"reflect"
+	"testing"
+)
+
+func newTestClient() (*Client, *MockDirectory) {
+	d := &MockDirectory{
+		Entries: []*Entry{
+			{DN: "cn=sourcegraph,ou=services,dc=example,dc=com", Attributes: map[string][]string{"objectClass": {"applicationProcess"}, "cn": {"sourcegraph"}}},
+			{DN: "uid=alice,ou=people,dc=example,dc=com", Attributes: map[string][]string{"objectClass": {"person"}, "uid": {"alice"}, "mail": {"alice@example.com"}, "cn": {"Alice"}}},
+			{DN: "uid=bob,ou=people,dc=example,dc=com", Attributes: map[string][]string{"objectClass": {"person"}, "uid": {"bob"}}},
+			{DN: "uid=dup,ou=people,dc=example,dc=com", Attributes: map[string][]string{"objectClass": {"person"}, "uid": {"dup"}}},
+			{DN: "uid=dup,ou=contractors,ou=people,dc=example,dc=com", Attributes: map[string][]string{"objectClass": {"person"}, "uid": {"dup"}}},
+			{DN: "cn=engineering,ou=groups,dc=example,dc=com", Attributes: map[string][]string{"objectClass": {"groupOfNames"}, "cn": {"engineering"}, "member": {"uid=alice,ou=people,dc=example,dc=com", "uid=bob,ou=people,dc=example,dc=com"}}},
+			{DN: "cn=admins,ou=groups,dc=example,dc=com", Attributes: map[string][]string{"objectClass": {"groupOfNames"}, "cn": {"admins"}, "member": {"UID=Alice,ou=people,dc=example,dc=com"}}},
+			{DN: "cn=other,ou=groups,dc=example,dc=com", Attributes: map[string][]string{"objectClass": {"posixGroup"}, "cn": {"other"}, "member": {"uid=alice,ou=people,dc=example,dc=com"}}},
+		},
+		Passwords: map[string]string{
+			"cn=sourcegraph,ou=services,dc=example,dc=com":       "s3cret",
+			"uid=alice,ou=people,dc=example,dc=com":              "alicepw",
+			"uid=dup,ou=people,dc=example,dc=com":                "duppw",
+			"uid=dup,ou=contractors,ou=people,dc=example,dc=com": "duppw",
+		},
+	}
+	return &Client{
+		Dial:                 d.Dial,
+		BindDN:               "cn=sourcegraph,ou=services,dc=example,dc=com",
+		BindPassword:         "s3cret",
+		UserBaseDN:           "ou=people,dc=example,dc=com",
+		UserFilter:           "objectClass=person",
+		UsernameAttribute:    "uid",
+		UserAttributes:       []string{"mail", "cn"},
+		GroupBaseDN:          "ou=groups,dc=example,dc=com",
+		GroupFilter:          "(objectClass=groupOfNames)",
+		GroupMemberAttribute: "member",
+		GroupNameAttribute:   "cn",
+	}, d
+}
+
+func TestClient_Authenticate(t *testing.T) {
+	c, _ := newTestClient()
+
+	user, err := c.Authenticate("alice", "alicepw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "uid=alice,ou=people,dc=example,dc=com"; user.DN != want {
+		t.Errorf("got DN %q, want %q", user.DN, want)
+	}
+	if got, want := user.Attribute("MAIL"), "alice@example.com"; got != want {
+		t.Errorf("got email %q, want %q", got, want)
+	}
+
+	tests := map[string]struct {
+		username, password string
+	}{
+		"wrong password":       {"alice", "wrong"},
+		"empty password":       {"alice", ""},
+		"no password set":      {"bob", "x"},
+		"no such user":         {"carol", "x"},
+		"empty username":       {"", "x"},
+		"filter injection":     {"*", "alicepw"},
+		"not matching filter":  {"sourcegraph", "s3cret"},
+		"injection in value":   {"alice)(uid=*", "alicepw"},
+		"non-ASCII is escaped": {"alicé", "alicepw"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := c.Authenticate(test.username, test.password); err != ErrInvalidCredentials {
+				t.Errorf("got error %v, want %v", err, ErrInvalidCredentials)
+			}
+		})
+	}
+
+	t.Run("ambiguous username", func(t *testing.T) {
+		if _, err := c.Authenticate("dup", "duppw"); err == nil || err == ErrInvalidCredentials {
+			t.Errorf("got error %v, want ambiguity error", err)
+		}
+	})
+
+	t.Run("wrong service account password", func(t *testing.T) {
+		c, _ := newTestClient()
+		c.BindPassword = "wrong"
+		if _, err := c.Authenticate("alice", "alicepw"); err == nil || err == ErrInvalidCredentials {
+			t.Errorf("got error %v, want service account error", err)
+		}
+	})
+}
+
+func TestClient_Groups(t *testing.T) {
+	c, _ := newTestClient()
+	groups, err := c.Groups("uid=alice,ou=people,dc=example,dc=com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"admins", "engineering"}; !reflect.DeepEqual(groups, want) {
+		t.Errorf("got groups %v, want %v", groups, want)
+	}
+
+	groups, err = c.Groups("uid=carol,ou=people,dc=example,dc=com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 0 {
+		t.Errorf("got groups %v, want none", groups)
+	}
+}
+
+func TestEscapeFilter(t *testing.T) {
+	tests := map[string]string{
+		"alice":                     "alice",
+		"*":                         `\2a`,
+		"a(b)c\\d":                  `a\28b\29c\5cd`,
+		"nul\x00":                   `nul\00`,
+		"é":                         `\c3\a9`,
+		"cn=x,ou=people,dc=example": "cn=x,ou=people,dc=example",
+	}
+	for value, want := range tests {
+		got := EscapeFilter(value)
+		if got != want {
+			t.Errorf("EscapeFilter(%q): got %q, want %q", value, got, want)
+		}
+		if unescaped, err := unescapeFilter(got); err != nil || unescaped != value {
+			t.Errorf("unescapeFilter(%q): got %q (error %v), want %q", got, unescaped, err, value)
+		}
+	}
+}
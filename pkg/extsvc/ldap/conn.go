@@ -0,0 +1,96 @@
+package ldap
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+
+	ldap "gopkg.in/ldap.v2"
+)
+
+// Dial connects to the LDAP server at the URL, which must have the ldap or ldaps scheme. If
+// startTLS is true, the connection to an ldap URL is upgraded to TLS. The tlsConfig (which may be
+// nil) is used for TLS connections.
+func Dial(rawURL string, startTLS bool, tlsConfig *tls.Config) (Conn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{}
+	} else {
+		tlsConfig = tlsConfig.Clone()
+	}
+	if tlsConfig.ServerName == "" {
+		tlsConfig.ServerName = u.Hostname()
+	}
+
+	var l *ldap.Conn
+	switch u.Scheme {
+	case "ldap":
+		l, err = ldap.Dial("tcp", hostPort(u, "389"))
+		if err != nil {
+			return nil, err
+		}
+		if startTLS {
+			if err := l.StartTLS(tlsConfig); err != nil {
+				l.Close()
+				return nil, err
+			}
+		}
+	case "ldaps":
+		if startTLS {
+			return nil, fmt.Errorf("StartTLS can't be used with LDAP URL %q, which is already TLS", rawURL)
+		}
+		l, err = ldap.DialTLS("tcp", hostPort(u, "636"), tlsConfig)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported LDAP URL scheme %q (must be ldap or ldaps)", u.Scheme)
+	}
+	return &conn{l: l}, nil
+}
+
+func hostPort(u *url.URL, defaultPort string) string {
+	port := u.Port()
+	if port == "" {
+		port = defaultPort
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
+
+// conn is a Conn to an LDAP server.
+type conn struct {
+	l *ldap.Conn
+}
+
+func (c *conn) Bind(dn, password string) error {
+	err := c.l.Bind(dn, password)
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+		return ErrInvalidCredentials
+	}
+	return err
+}
+
+func (c *conn) Search(baseDN, filter string, attributes []string) ([]*Entry, error) {
+	req := ldap.NewSearchRequest(baseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false, filter, attributes, nil)
+	res, err := c.l.Search(req)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]*Entry, len(res.Entries))
+	for i, e := range res.Entries {
+		entries[i] = &Entry{DN: e.DN, Attributes: make(map[string][]string, len(e.Attributes))}
+		for _, a := range e.Attributes {
+			entries[i].Attributes[a.Name] = a.Values
+		}
+	}
+	return entries, nil
+}
+
+func (c *conn) Close() error {
+	c.l.Close()
+	return nil
+}
@@ -0,0 +1,2 @@
+// Package ldap implements an LDAP directory client.
+package ldap
@@ -0,0 +1,178 @@
+package ldap
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// MockDirectory is an in-process stand-in for an LDAP directory, for use in tests. Set a Client's
+// Dial field to its Dial method.
+//
+// It supports the subset of LDAP search filters used in practice for users and groups: the &, |
+// and ! operators, equality matches and presence matches. Matching is case-insensitive.
+type MockDirectory struct {
+	Entries []*Entry
+
+	// Passwords are the passwords of the entries that may bind, by DN.
+	Passwords map[string]string
+}
+
+// Dial implements the Client's Dial func.
+func (d *MockDirectory) Dial() (Conn, error) { return &mockConn{d: d}, nil }
+
+type mockConn struct {
+	d      *MockDirectory
+	closed bool
+}
+
+func (c *mockConn) Bind(dn, password string) error {
+	if c.closed {
+		return fmt.Errorf("connection closed")
+	}
+	if dn == "" && password == "" {
+		return nil // anonymous bind
+	}
+	// An empty password is an unauthenticated bind, which (like most servers) we allow for any DN.
+	if want, ok := c.d.Passwords[dn]; password != "" && (!ok || want != password) {
+		return ErrInvalidCredentials
+	}
+	return nil
+}
+
+func (c *mockConn) Search(baseDN, filter string, attributes []string) ([]*Entry, error) {
+	if c.closed {
+		return nil, fmt.Errorf("connection closed")
+	}
+	match, rest, err := parseMockFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	if rest != "" {
+		return nil, fmt.Errorf("invalid filter %q: trailing %q", filter, rest)
+	}
+
+	var entries []*Entry
+	for _, e := range c.d.Entries {
+		if !inSubtree(e.DN, baseDN) || !match(e) {
+			continue
+		}
+		result := &Entry{DN: e.DN, Attributes: map[string][]string{}}
+		for _, a := range attributes {
+			for k, v := range e.Attributes {
+				if strings.EqualFold(k, a) {
+					result.Attributes[k] = v
+				}
+			}
+		}
+		entries = append(entries, result)
+	}
+	return entries, nil
+}
+
+func (c *mockConn) Close() error {
+	c.closed = true
+	return nil
+}
+
+func inSubtree(dn, baseDN string) bool {
+	dn, baseDN = strings.ToLower(dn), strings.ToLower(baseDN)
+	return baseDN == "" || dn == baseDN || strings.HasSuffix(dn, ","+baseDN)
+}
+
+type mockFilter func(*Entry) bool
+
+// parseMockFilter parses the filter at the start of s, returning the rest of s.
+func parseMockFilter(s string) (match mockFilter, rest string, err error) {
+	if !strings.HasPrefix(s, "(") {
+		return nil, "", fmt.Errorf("invalid filter %q: expected (", s)
+	}
+	s = s[1:]
+	switch {
+	case strings.HasPrefix(s, "&"), strings.HasPrefix(s, "|"):
+		and := s[0] == '&'
+		s = s[1:]
+		var subs []mockFilter
+		for !strings.HasPrefix(s, ")") {
+			sub, rest, err := parseMockFilter(s)
+			if err != nil {
+				return nil, "", err
+			}
+			subs = append(subs, sub)
+			s = rest
+		}
+		return func(e *Entry) bool {
+			for _, sub := range subs {
+				if sub(e) != and {
+					return !and
+				}
+			}
+			return and
+		}, s[1:], nil
+
+	case strings.HasPrefix(s, "!"):
+		sub, rest, err := parseMockFilter(s[1:])
+		if err != nil {
+			return nil, "", err
+		}
+		if !strings.HasPrefix(rest, ")") {
+			return nil, "", fmt.Errorf("invalid filter %q: expected )", rest)
+		}
+		return func(e *Entry) bool { return !sub(e) }, rest[1:], nil
+	}
+
+	end := strings.Index(s, ")")
+	if end == -1 {
+		return nil, "", fmt.Errorf("invalid filter %q: expected )", s)
+	}
+	item := s[:end]
+	eq := strings.Index(item, "=")
+	if eq <= 0 {
+		return nil, "", fmt.Errorf("invalid filter item %q", item)
+	}
+	attr, rawValue := item[:eq], item[eq+1:]
+	if rawValue == "*" {
+		return func(e *Entry) bool { return e.Attribute(attr) != "" }, s[end+1:], nil
+	}
+	if strings.ContainsAny(rawValue, "*<>~:") || strings.ContainsAny(attr, "<>~:") {
+		return nil, "", fmt.Errorf("unsupported filter item %q (only equality and presence are supported)", item)
+	}
+	value, err := unescapeFilter(rawValue)
+	if err != nil {
+		return nil, "", err
+	}
+	return func(e *Entry) bool {
+		for k, vs := range e.Attributes {
+			if !strings.EqualFold(k, attr) {
+				continue
+			}
+			for _, v := range vs {
+				if strings.EqualFold(v, value) {
+					return true
+				}
+			}
+		}
+		return false
+	}, s[end+1:], nil
+}
+
+// unescapeFilter is the inverse of EscapeFilter.
+func unescapeFilter(s string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			b.WriteByte(s[i])
+			continue
+		}
+		if i+2 >= len(s) {
+			return "", fmt.Errorf("invalid escape in filter value %q", s)
+		}
+		c, err := hex.DecodeString(s[i+1 : i+3])
+		if err != nil {
+			return "", fmt.Errorf("invalid escape in filter value %q", s)
+		}
+		b.Write(c)
+		i += 2
+	}
+	return b.String(), nil
+}
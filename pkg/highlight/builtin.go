@@ -0,0 +1,383 @@
+package highlight
+
+import (
+	"bytes"
+	"html/template"
+	"path"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// The builtin highlighter is a small in-process lexer that is used when
+// syntect-server is not deployed or is unavailable. It only distinguishes
+// comments, strings, numbers and keywords, which is far less precise than
+// syntect, but it needs no external service and runs in linear time.
+
+// tokenKind is the kind of a token produced by the builtin lexer.
+type tokenKind int
+
+const (
+	tokenText tokenKind = iota
+	tokenComment
+	tokenString
+	tokenNumber
+	tokenKeyword
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+// builtinLanguage describes the lexical syntax of a language, as far as the
+// builtin highlighter cares.
+type builtinLanguage struct {
+	lineComments []string  // line comment prefixes, e.g. "//"
+	blockComment [2]string // block comment delimiters, e.g. "/*" and "*/" (empty if none)
+	quotes       string    // string delimiter characters
+	multiline    string    // subset of quotes whose strings may span multiple lines
+	tripleQuotes bool      // whether """ and ''' delimit (multiline) strings
+	ignoreCase   bool      // whether keywords are case insensitive
+	keywords     map[string]bool
+}
+
+func keywords(s string) map[string]bool {
+	m := map[string]bool{}
+	for _, kw := range strings.Fields(s) {
+		m[kw] = true
+	}
+	return m
+}
+
+var (
+	cStyleComments = builtinLanguage{lineComments: []string{"//"}, blockComment: [2]string{"/*", "*/"}}
+	hashComments   = builtinLanguage{lineComments: []string{"#"}}
+)
+
+func withSyntax(base builtinLanguage, quotes, multiline string, kws string) *builtinLanguage {
+	base.quotes = quotes
+	base.multiline = multiline
+	base.keywords = keywords(kws)
+	return &base
+}
+
+var (
+	builtinGo = withSyntax(cStyleComments, "\"'`", "`",
+		"break case chan const continue default defer else fallthrough for func go goto if import interface map package range return select struct switch type var nil true false iota")
+	builtinC = withSyntax(cStyleComments, `"'`, "",
+		"auto break case char const continue default do double else enum extern float for goto if inline int long register return short signed sizeof static struct switch typedef union unsigned void volatile while bool class namespace template typename public private protected virtual new delete this nullptr true false using try catch throw operator friend NULL")
+	builtinJava = withSyntax(cStyleComments, `"'`, "",
+		"abstract assert boolean break byte case catch char class const continue default do double else enum extends final finally float for if implements import instanceof int interface long native new package private protected public return short static super switch synchronized this throw throws transient try void volatile while true false null var val fun object when is in override data sealed companion internal")
+	builtinCSharp = withSyntax(cStyleComments, `"'`, "",
+		"abstract as base bool break byte case catch char class const continue decimal default delegate do double else enum event explicit extern false finally float for foreach if implicit in int interface internal is lock long namespace new null object operator out override params private protected public readonly ref return sealed short static string struct switch this throw true try typeof uint ulong using var virtual void while async await")
+	builtinJavaScript = withSyntax(cStyleComments, "\"'`", "`",
+		"async await break case catch class const continue debugger default delete do else export extends false finally for from function if import in instanceof let new null of return static super switch this throw true try typeof undefined var void while yield interface type enum implements private protected public readonly abstract as declare namespace")
+	builtinRust = withSyntax(cStyleComments, `"`, `"`,
+		"as async await break const continue crate dyn else enum extern false fn for if impl in let loop match mod move mut pub ref return self Self static struct super trait true type unsafe use where while")
+	builtinSwift = withSyntax(cStyleComments, `"`, "",
+		"associatedtype class deinit enum extension fileprivate func import init inout internal let open operator private protocol public static struct subscript typealias var break case continue default defer do else fallthrough for guard if in repeat return switch where while as catch false is nil rethrows super self Self throw throws true try")
+	builtinPHP = &builtinLanguage{
+		lineComments: []string{"//", "#"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       `"'`,
+		multiline:    `"'`,
+		keywords:     keywords("abstract and array as break case catch class const continue declare default do echo else elseif empty extends final finally for foreach function global if implements include interface isset namespace new null or private protected public require return static switch this throw trait true false try unset use var while yield"),
+	}
+	builtinPython = &builtinLanguage{
+		lineComments: []string{"#"},
+		quotes:       `"'`,
+		tripleQuotes: true,
+		keywords:     keywords("and as assert async await break class continue def del elif else except False finally for from global if import in is lambda None nonlocal not or pass raise return True try while with yield self"),
+	}
+	builtinRuby = withSyntax(hashComments, `"'`, `"'`,
+		"alias and begin break case class def defined do else elsif end ensure false for if in module next nil not or redo rescue retry return self super then true undef unless until when while yield require")
+	builtinShell = withSyntax(hashComments, `"'`, `"'`,
+		"if then else elif fi case esac for select while until do done in function return local export readonly declare set unset shift exit")
+	builtinYAML = withSyntax(hashComments, `"'`, "", "true false null yes no on off")
+	builtinSQL  = &builtinLanguage{
+		lineComments: []string{"--"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       `'"`,
+		multiline:    `'`,
+		ignoreCase:   true,
+		keywords:     keywords("select from where and or not insert into values update set delete create table index view drop alter add column primary key foreign references join inner left right outer on as order by group having limit offset distinct union all null is in exists case when then else end begin commit rollback default unique"),
+	}
+	builtinCSS = &builtinLanguage{
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       `"'`,
+	}
+)
+
+// builtinLanguagesByExtension maps lowercase file extensions (and, for files
+// without an extension, lowercase base names) to languages.
+var builtinLanguagesByExtension = map[string]*builtinLanguage{
+	".go":    builtinGo,
+	".c":     builtinC,
+	".h":     builtinC,
+	".cc":    builtinC,
+	".cpp":   builtinC,
+	".cxx":   builtinC,
+	".hh":    builtinC,
+	".hpp":   builtinC,
+	".m":     builtinC,
+	".java":  builtinJava,
+	".kt":    builtinJava,
+	".kts":   builtinJava,
+	".scala": builtinJava,
+	".cs":    builtinCSharp,
+	".js":    builtinJavaScript,
+	".jsx":   builtinJavaScript,
+	".mjs":   builtinJavaScript,
+	".ts":    builtinJavaScript,
+	".tsx":   builtinJavaScript,
+	".json":  builtinJavaScript,
+	".rs":    builtinRust,
+	".swift": builtinSwift,
+	".php":   builtinPHP,
+	".py":    builtinPython,
+	".rb":    builtinRuby,
+	".sh":    builtinShell,
+	".bash":  builtinShell,
+	".zsh":   builtinShell,
+	".yml":   builtinYAML,
+	".yaml":  builtinYAML,
+	".toml":  builtinYAML,
+	".sql":   builtinSQL,
+	".css":   builtinCSS,
+	".scss":  builtinCSS,
+	".less":  builtinCSS,
+
+	"dockerfile": builtinShell,
+	"makefile":   builtinShell,
+}
+
+// builtinLanguageForPath returns the language of the file at the given path,
+// or nil if the builtin highlighter doesn't support it.
+func builtinLanguageForPath(filepath string) *builtinLanguage {
+	name := strings.ToLower(path.Base(filepath))
+	if lang, ok := builtinLanguagesByExtension[path.Ext(name)]; ok {
+		return lang
+	}
+	return builtinLanguagesByExtension[name]
+}
+
+// tokenize splits code into tokens. Concatenating the text of all tokens
+// yields code.
+func tokenize(code string, lang *builtinLanguage) []token {
+	var tokens []token
+	i := 0 // the offset in code of the next token
+
+	// emit adds the next n bytes of code as a token of the given kind (or
+	// extends the last token, if it is of the same kind) and advances i.
+	// Token texts are slices of code, so extending a token doesn't copy.
+	emit := func(kind tokenKind, n int) {
+		if n == 0 {
+			return
+		}
+		if last := len(tokens) - 1; last >= 0 && tokens[last].kind == kind {
+			start := i - len(tokens[last].text)
+			tokens[last].text = code[start : i+n]
+		} else {
+			tokens = append(tokens, token{kind: kind, text: code[i : i+n]})
+		}
+		i += n
+	}
+
+	// untilLineEnd returns the length of s up to (not including) the next newline.
+	untilLineEnd := func(s string) int {
+		if i := strings.IndexByte(s, '\n'); i >= 0 {
+			return i
+		}
+		return len(s)
+	}
+
+	prevIdent := false // whether the previous rune was part of an identifier
+	for i < len(code) {
+		rest := code[i:]
+
+		if lang.blockComment[0] != "" && strings.HasPrefix(rest, lang.blockComment[0]) {
+			n := len(rest)
+			if end := strings.Index(rest[len(lang.blockComment[0]):], lang.blockComment[1]); end >= 0 {
+				n = len(lang.blockComment[0]) + end + len(lang.blockComment[1])
+			}
+			emit(tokenComment, n)
+			prevIdent = false
+			continue
+		}
+
+		isLineComment := false
+		for _, prefix := range lang.lineComments {
+			if strings.HasPrefix(rest, prefix) {
+				isLineComment = true
+				break
+			}
+		}
+		if isLineComment {
+			n := untilLineEnd(rest)
+			emit(tokenComment, n)
+			prevIdent = false
+			continue
+		}
+
+		if lang.tripleQuotes && (strings.HasPrefix(rest, `"""`) || strings.HasPrefix(rest, `'''`)) {
+			n := len(rest)
+			if end := strings.Index(rest[3:], rest[:3]); end >= 0 {
+				n = 3 + end + 3
+			}
+			emit(tokenString, n)
+			prevIdent = false
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(rest)
+		switch {
+		case strings.ContainsRune(lang.quotes, r):
+			n := scanString(rest, r, strings.ContainsRune(lang.multiline, r))
+			emit(tokenString, n)
+			prevIdent = false
+		case !prevIdent && r >= '0' && r <= '9':
+			n := size
+			for n < len(rest) && isNumberByte(rest[n]) {
+				n++
+			}
+			emit(tokenNumber, n)
+		case isIdentRune(r):
+			n := size
+			for n < len(rest) {
+				r, size := utf8.DecodeRuneInString(rest[n:])
+				if !isIdentRune(r) && !unicode.IsDigit(r) {
+					break
+				}
+				n += size
+			}
+			ident := rest[:n]
+			if lang.ignoreCase {
+				ident = strings.ToLower(ident)
+			}
+			if lang.keywords[ident] {
+				emit(tokenKeyword, n)
+			} else {
+				emit(tokenText, n)
+			}
+			prevIdent = true
+		default:
+			emit(tokenText, size)
+			prevIdent = false
+		}
+	}
+	return tokens
+}
+
+// scanString returns the length of the string literal at the start of s,
+// which begins with the quote character. Backslash escapes are honored.
+// Unterminated strings end at the end of the line (or of s, if multiline).
+func scanString(s string, quote rune, multiline bool) int {
+	n := utf8.RuneLen(quote)
+	for n < len(s) {
+		r, size := utf8.DecodeRuneInString(s[n:])
+		switch {
+		case r == '\\' && quote != '`':
+			n += size
+			if n < len(s) && (multiline || s[n] != '\n') {
+				_, size = utf8.DecodeRuneInString(s[n:])
+				n += size
+			}
+			continue
+		case r == quote:
+			return n + size
+		case r == '\n' && !multiline:
+			return n
+		}
+		n += size
+	}
+	return n
+}
+
+func isIdentRune(r rune) bool {
+	return r == '_' || r == '$' || unicode.IsLetter(r)
+}
+
+func isNumberByte(b byte) bool {
+	return b == '.' || b == '_' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
+
+// builtinThemes are the colors of each token kind (tokenText is never
+// colored). They approximate the colors of the syntect themes.
+var builtinThemes = map[bool]map[tokenKind]string{
+	false: { // dark theme
+		tokenComment: "#748294",
+		tokenString:  "#c2e4ff",
+		tokenNumber:  "#a6e22e",
+		tokenKeyword: "#f4a3f0",
+	},
+	true: { // light theme
+		tokenComment: "#6a737d",
+		tokenString:  "#032f62",
+		tokenNumber:  "#005cc5",
+		tokenKeyword: "#d73a49",
+	},
+}
+
+// generateBuiltinTable highlights code with the builtin highlighter and
+// returns an HTML table of the same shape as the one generated from
+// syntect's output. If the language of the file is not supported, the table
+// is not highlighted.
+func generateBuiltinTable(code, filepath string, isLightTheme bool) (template.HTML, error) {
+	lang := builtinLanguageForPath(filepath)
+	if lang == nil {
+		return generatePlainTable(code)
+	}
+
+	code = strings.Replace(code, "\r\n", "\n", -1) // CRLF files
+	colors := builtinThemes[isLightTheme]
+
+	var (
+		table    = &html.Node{Type: html.ElementNode, DataAtom: atom.Table, Data: atom.Table.String()}
+		rows     int
+		codeCell *html.Node
+	)
+	newRow := func() {
+		// Blank lines need a span with a newline character for proper
+		// whitespace copy+paste support.
+		if codeCell != nil && codeCell.FirstChild == nil {
+			appendSpan(codeCell, "", "\n")
+		}
+		rows++
+		codeCell = newTableRow(table, rows)
+	}
+	newRow()
+	for _, tok := range tokenize(code, lang) {
+		for i, text := range strings.Split(tok.text, "\n") {
+			if i > 0 {
+				newRow()
+			}
+			if text != "" {
+				appendSpan(codeCell, colors[tok.kind], text)
+			}
+		}
+	}
+	if codeCell.FirstChild == nil {
+		appendSpan(codeCell, "", "\n")
+	}
+
+	var buf bytes.Buffer
+	if err := html.Render(&buf, table); err != nil {
+		return "", err
+	}
+	return template.HTML(buf.String()), nil
+}
+
+// appendSpan appends a <span> containing text to parent. The span is colored
+// if color is not empty.
+func appendSpan(parent *html.Node, color, text string) {
+	span := &html.Node{Type: html.ElementNode, DataAtom: atom.Span, Data: atom.Span.String()}
+	if color != "" {
+		span.Attr = append(span.Attr, html.Attribute{Key: "style", Val: "color:" + color + ";"})
+	}
+	parent.AppendChild(span)
+	span.AppendChild(&html.Node{Type: html.TextNode, Data: text})
+}
@@ -0,0 +1,105 @@
+package highlight
+
+import (
+	"html/template"
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		path string
+		code string
+		want []token
+	}{
+		{
+			path: "a.go",
+			code: "func f() string { return `a\nb` } // x\n/* y */ 0x1f",
+			want: []token{
+				{tokenKeyword, "func"},
+				{tokenText, " f() string { "},
+				{tokenKeyword, "return"},
+				{tokenText, " "},
+				{tokenString, "`a\nb`"},
+				{tokenText, " } "},
+				{tokenComment, "// x"},
+				{tokenText, "\n"},
+				{tokenComment, "/* y */"},
+				{tokenText, " "},
+				{tokenNumber, "0x1f"},
+			},
+		},
+		{
+			path: "a.js",
+			code: `x = "a\"b" + 'c` + "\n" + `if2 + 1`,
+			want: []token{
+				{tokenText, "x = "},
+				{tokenString, `"a\"b"`},
+				{tokenText, " + "},
+				{tokenString, `'c`},
+				{tokenText, "\nif2 + "},
+				{tokenNumber, "1"},
+			},
+		},
+		{
+			path: "a.py",
+			code: "def f():\n    '''doc\n    '''  # c",
+			want: []token{
+				{tokenKeyword, "def"},
+				{tokenText, " f():\n    "},
+				{tokenString, "'''doc\n    '''"},
+				{tokenText, "  "},
+				{tokenComment, "# c"},
+			},
+		},
+		{
+			path: "q.SQL",
+			code: "SELECT 1 -- one",
+			want: []token{
+				{tokenKeyword, "SELECT"},
+				{tokenText, " "},
+				{tokenNumber, "1"},
+				{tokenText, " "},
+				{tokenComment, "-- one"},
+			},
+		},
+	}
+	for _, test := range tests {
+		lang := builtinLanguageForPath(test.path)
+		if lang == nil {
+			t.Fatalf("%s: no builtin language", test.path)
+		}
+		got := tokenize(test.code, lang)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got tokens\n%+v\nwant\n%+v", test.path, got, test.want)
+		}
+	}
+}
+
+func TestGenerateBuiltinTable(t *testing.T) {
+	input := "package main\n\n/* <a>\nb */"
+	want := template.HTML(`<table><tr><td class="line" data-line="1"></td><td class="code"><span style="color:#d73a49;">package</span><span> main</span></td></tr><tr><td class="line" data-line="2"></td><td class="code"><span>
+</span></td></tr><tr><td class="line" data-line="3"></td><td class="code"><span style="color:#6a737d;">/* &lt;a&gt;</span></td></tr><tr><td class="line" data-line="4"></td><td class="code"><span style="color:#6a737d;">b */</span></td></tr></table>`)
+	got, err := generateBuiltinTable(input, "a/b.go", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Fatalf("\ngot:\n%s\nwant:\n%s\n", got, want)
+	}
+}
+
+func TestGenerateBuiltinTable_UnsupportedLanguage(t *testing.T) {
+	input := "a\nb"
+	got, err := generateBuiltinTable(input, "README", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := generatePlainTable(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Fatalf("\ngot:\n%s\nwant:\n%s\n", got, want)
+	}
+}
@@ -0,0 +1,176 @@
+package highlight
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"log"
+	"path"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sourcegraph/sourcegraph/pkg/conf"
+	"github.com/sourcegraph/sourcegraph/pkg/diskcache"
+	"github.com/sourcegraph/sourcegraph/pkg/env"
+)
+
+// CacheDir is the location on disk where highlighted code is cached. It is
+// configurable so that in production we can point it into CACHE_DIR.
+var CacheDir = "/tmp/highlight-cache"
+
+var cacheSizeMB, _ = strconv.Atoi(env.Get("HIGHLIGHT_CACHE_SIZE_MB", "500", "maximum size of the on disk cache of highlighted code (0 disables the cache)"))
+
+// cacheVersion is included in cache keys. Increment it when the HTML
+// generated for the same input changes (e.g., when the table structure or the
+// builtin highlighter's colors change).
+const cacheVersion = 1
+
+var (
+	cacheOnce  sync.Once
+	cacheStore *diskcache.Store
+)
+
+func getCacheStore() *diskcache.Store {
+	cacheOnce.Do(func() {
+		cacheStore = &diskcache.Store{
+			Dir:       CacheDir,
+			Component: "highlight",
+		}
+		go watchAndEvict(cacheStore, int64(cacheSizeMB)*1024*1024)
+	})
+	return cacheStore
+}
+
+// errNotCacheable is returned by the cache fetcher to prevent a result from
+// being stored.
+var errNotCacheable = errors.New("highlighted code is not cacheable")
+
+// mockHighlightCode, if set, is called by CachedCode instead of highlightCode.
+// It is used in tests.
+var mockHighlightCode func(ctx context.Context, content []byte, filepath string, disableTimeout bool, isLightTheme bool) (template.HTML, bool, bool, error)
+
+// CachedCode is like Code, but caches the highlighted HTML on disk.
+//
+// The cache is content-addressed: entries are keyed by the Git blob OID of
+// the content (not by repository, revision or path), so the same file
+// content at different commits or in forks shares one entry. Results of
+// falling back to the builtin highlighter because syntect-server failed or
+// timed out are not cached.
+func CachedCode(ctx context.Context, content []byte, filepath string, disableTimeout bool, isLightTheme bool) (template.HTML, bool, error) {
+	if cacheSizeMB <= 0 || IsBinary(content) {
+		return Code(ctx, content, filepath, disableTimeout, isLightTheme)
+	}
+
+	type result struct {
+		html    template.HTML
+		aborted bool
+		err     error
+	}
+	computed := make(chan result, 1)
+
+	f, err := getCacheStore().OpenWithPath(ctx, cacheKey(content, filepath, isLightTheme), func(ctx context.Context, tempPath string) error {
+		highlight := highlightCode
+		if mockHighlightCode != nil {
+			highlight = mockHighlightCode
+		}
+		html, aborted, cacheable, err := highlight(ctx, content, filepath, disableTimeout, isLightTheme)
+		computed <- result{html: html, aborted: aborted, err: err}
+		if err != nil {
+			return err
+		}
+		if !cacheable {
+			return errNotCacheable
+		}
+		return ioutil.WriteFile(tempPath, []byte(html), 0600)
+	})
+	select {
+	case r := <-computed:
+		cacheMisses.Inc()
+		if f != nil {
+			f.Close()
+		}
+		return r.html, r.aborted, r.err
+	default:
+	}
+	if err != nil {
+		return "", false, err
+	}
+	defer f.Close()
+
+	html, err := ioutil.ReadAll(f)
+	if err != nil {
+		return "", false, err
+	}
+	cacheHits.Inc()
+	return template.HTML(html), false, nil
+}
+
+// cacheKey returns the cache key for highlighting content with the given
+// options. The file name (but not its directory) is part of the key because
+// the highlighter uses it to detect the language.
+func cacheKey(content []byte, filepath string, isLightTheme bool) string {
+	return fmt.Sprintf("%d:%s:%t:%s:%s", cacheVersion, conf.HighlightEngine(), isLightTheme, blobOID(content), path.Base(filepath))
+}
+
+// blobOID returns the Git object ID of a blob with the given content.
+func blobOID(content []byte) string {
+	h := sha1.New()
+	fmt.Fprintf(h, "blob %d\x00", len(content))
+	h.Write(content)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// watchAndEvict is a loop which periodically checks the size of the cache and
+// evicts/deletes items if the store gets too large.
+func watchAndEvict(store *diskcache.Store, maxCacheSizeBytes int64) {
+	for {
+		time.Sleep(10 * time.Second)
+		stats, err := store.Evict(maxCacheSizeBytes)
+		if err != nil {
+			log.Printf("failed to Evict: %s", err)
+			continue
+		}
+		cacheSizeBytes.Set(float64(stats.CacheSize))
+		cacheEvictions.Add(float64(stats.Evicted))
+	}
+}
+
+var (
+	cacheHits = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "src",
+		Subsystem: "highlight",
+		Name:      "cache_hits_total",
+		Help:      "Total number of highlighted code cache hits.",
+	})
+	cacheMisses = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "src",
+		Subsystem: "highlight",
+		Name:      "cache_misses_total",
+		Help:      "Total number of highlighted code cache misses.",
+	})
+	cacheSizeBytes = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "src",
+		Subsystem: "highlight",
+		Name:      "cache_size_bytes",
+		Help:      "The total size of highlighted code in the on disk cache.",
+	})
+	cacheEvictions = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "src",
+		Subsystem: "highlight",
+		Name:      "cache_evictions_total",
+		Help:      "The total number of highlighted code items evicted from the cache.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(cacheHits)
+	prometheus.MustRegister(cacheMisses)
+	prometheus.MustRegister(cacheSizeBytes)
+	prometheus.MustRegister(cacheEvictions)
+}
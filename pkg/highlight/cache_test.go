@@ -0,0 +1,100 @@
+package highlight
+
+import (
+	"context"
+	"html/template"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/pkg/conf"
+	"github.com/sourcegraph/sourcegraph/pkg/diskcache"
+)
+
+func TestBlobOID(t *testing.T) {
+	// Computed with `printf 'hello\n' | git hash-object --stdin`.
+	if got, want := blobOID([]byte("hello\n")), "ce013625030ba8dba906f756967f9e9ca394464a"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	// The empty blob.
+	if got, want := blobOID(nil), "e69de29bb2d1d6434b8b29ae775ad8c2e48c5391"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+// setupCachedCode points CachedCode at an empty cache directory and makes it
+// highlight with fn. The returned func undoes this.
+func setupCachedCode(t *testing.T, fn func(ctx context.Context, content []byte, filepath string, disableTimeout bool, isLightTheme bool) (template.HTML, bool, bool, error)) func() {
+	dir, err := ioutil.TempDir("", "highlight-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf.Mock(&conf.Unified{})
+	getCacheStore() // so that the sync.Once does not replace the store below
+	origStore, origSizeMB := cacheStore, cacheSizeMB
+	cacheStore = &diskcache.Store{Dir: dir, Component: "highlight"}
+	cacheSizeMB = 1
+	mockHighlightCode = fn
+	return func() {
+		cacheStore, cacheSizeMB = origStore, origSizeMB
+		mockHighlightCode = nil
+		conf.Mock(nil)
+		os.RemoveAll(dir)
+	}
+}
+
+func TestCachedCode(t *testing.T) {
+	calls := 0
+	defer setupCachedCode(t, func(ctx context.Context, content []byte, filepath string, disableTimeout bool, isLightTheme bool) (template.HTML, bool, bool, error) {
+		calls++
+		return template.HTML("<table>" + string(content) + "</table>"), false, true, nil
+	})()
+
+	ctx := context.Background()
+	for i, test := range []struct {
+		content   string
+		filepath  string
+		wantCalls int
+	}{
+		{content: "a", filepath: "a/x.go", wantCalls: 1}, // miss
+		{content: "a", filepath: "a/x.go", wantCalls: 1}, // hit
+		{content: "a", filepath: "b/x.go", wantCalls: 1}, // hit: only the file name is part of the key
+		{content: "a", filepath: "a/y.go", wantCalls: 2}, // miss: different file name
+		{content: "b", filepath: "a/x.go", wantCalls: 3}, // miss: different content
+	} {
+		html, aborted, err := CachedCode(ctx, []byte(test.content), test.filepath, false, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := template.HTML("<table>" + test.content + "</table>"); html != want || aborted {
+			t.Errorf("%d: got (%q, %v), want (%q, false)", i, html, aborted, want)
+		}
+		if calls != test.wantCalls {
+			t.Errorf("%d: got %d highlightCode calls, want %d", i, calls, test.wantCalls)
+		}
+	}
+}
+
+func TestCachedCode_notCacheable(t *testing.T) {
+	calls := 0
+	defer setupCachedCode(t, func(ctx context.Context, content []byte, filepath string, disableTimeout bool, isLightTheme bool) (template.HTML, bool, bool, error) {
+		calls++
+		// Simulate a timeout of syntect-server, i.e. falling back to the
+		// builtin highlighter.
+		return "<table>builtin</table>", true, false, nil
+	})()
+
+	for i := 1; i <= 2; i++ {
+		html, aborted, err := CachedCode(context.Background(), []byte("a"), "x.go", false, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if html != "<table>builtin</table>" || !aborted {
+			t.Errorf("got (%q, %v), want the builtin result and aborted", html, aborted)
+		}
+		// The result must not have been cached, so each call highlights again.
+		if calls != i {
+			t.Errorf("got %d highlightCode calls, want %d", calls, i)
+		}
+	}
+}
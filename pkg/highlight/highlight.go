@@ -11,10 +11,13 @@ import (
 	"time"
 	"unicode/utf8"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sourcegraph/gosyntect"
+	"github.com/sourcegraph/sourcegraph/pkg/conf"
 	"github.com/sourcegraph/sourcegraph/pkg/env"
 	"golang.org/x/net/html"
 	"golang.org/x/net/html/atom"
+	log15 "gopkg.in/inconshreveable/log15.v2"
 )
 
 var (
@@ -22,8 +25,16 @@ var (
 	client        *gosyntect.Client
 )
 
+var builtinFallbacks = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "src",
+	Subsystem: "highlight",
+	Name:      "builtin_fallbacks_total",
+	Help:      "Total number of times code was highlighted with the builtin highlighter because syntect-server failed.",
+}, []string{"reason"})
+
 func init() {
 	client = gosyntect.New(syntectServer)
+	prometheus.MustRegister(builtinFallbacks)
 }
 
 // IsBinary is a helper to tell if the content of a file is binary or not.
@@ -41,9 +52,22 @@ func IsBinary(content []byte) bool {
 // at least the file name + extension) and returns the properly escaped HTML
 // table representing the highlighted code.
 //
+// Depending on the "highlight.engine" site configuration, the code is
+// highlighted by syntect-server or by the builtin in-process highlighter. If
+// syntect-server fails or times out, the builtin highlighter is used.
+//
 // The returned boolean represents whether or not highlighting was aborted due
-// to timeout. In this scenario, a plain text table is returned.
+// to timeout. In this scenario, a table highlighted by the builtin highlighter
+// is returned.
 func Code(ctx context.Context, content []byte, filepath string, disableTimeout bool, isLightTheme bool) (template.HTML, bool, error) {
+	table, aborted, _, err := highlightCode(ctx, content, filepath, disableTimeout, isLightTheme)
+	return table, aborted, err
+}
+
+// highlightCode is like Code, but additionally returns (as the second boolean)
+// whether the result may be cached, i.e. it is not the result of falling back
+// to the builtin highlighter because syntect-server failed or timed out.
+func highlightCode(ctx context.Context, content []byte, filepath string, disableTimeout bool, isLightTheme bool) (template.HTML, bool, bool, error) {
 	if !disableTimeout {
 		var cancel func()
 		ctx, cancel = context.WithTimeout(ctx, 3*time.Second)
@@ -52,7 +76,7 @@ func Code(ctx context.Context, content []byte, filepath string, disableTimeout b
 
 	// Never pass binary files to the syntax highlighter.
 	if IsBinary(content) {
-		return "", false, errors.New("cannot render binary file")
+		return "", false, false, errors.New("cannot render binary file")
 	}
 	code := string(content)
 
@@ -71,6 +95,11 @@ func Code(ctx context.Context, content []byte, filepath string, disableTimeout b
 	// background.
 	code = strings.TrimSuffix(code, "\n")
 
+	if conf.HighlightEngine() == "builtin" {
+		table, err := generateBuiltinTable(code, filepath, isLightTheme)
+		return table, false, true, err
+	}
+
 	resp, err := client.Highlight(ctx, &gosyntect.Query{
 		Code:     code,
 		Filepath: filepath,
@@ -78,25 +107,34 @@ func Code(ctx context.Context, content []byte, filepath string, disableTimeout b
 	})
 
 	if ctx.Err() == context.DeadlineExceeded {
-		// Timeout, so render plain table.
-		table, err2 := generatePlainTable(code)
-		return table, true, err2
+		// Timeout, so render with the builtin highlighter instead. The caller
+		// may retry with the timeout disabled to get syntect's highlighting.
+		builtinFallbacks.WithLabelValues("timeout").Inc()
+		table, err2 := generateBuiltinTable(code, filepath, isLightTheme)
+		return table, true, false, err2
+	} else if ctx.Err() != nil {
+		return "", false, false, ctx.Err()
 	} else if err != nil {
 		postTooLarge := strings.HasSuffix(err.Error(), "EOF")
 		if postTooLarge {
 			// Failed to highlight code, e.g. for a text file. We still need to
 			// generate the table.
-			table, err2 := generatePlainTable(code)
-			return table, false, err2
+			builtinFallbacks.WithLabelValues("syntect_eof").Inc()
+		} else {
+			// syntect-server is down or misbehaving. Keep highlighting working
+			// (although less precisely) until it recovers.
+			log15.Warn("Syntax highlighting with syntect-server failed, using the builtin highlighter.", "filepath", filepath, "error", err)
+			builtinFallbacks.WithLabelValues("error").Inc()
 		}
-		return "", false, err
+		table, err2 := generateBuiltinTable(code, filepath, isLightTheme)
+		return table, false, postTooLarge, err2
 	}
 	// Note: resp.Data is properly HTML escaped by syntect_server
 	table, err := preSpansToTable(resp.Data)
 	if err != nil {
-		return "", false, err
+		return "", false, false, err
 	}
-	return template.HTML(table), false, nil
+	return template.HTML(table), false, true, nil
 }
 
 // preSpansToTable takes the syntect data structure, which looks like:
@@ -219,23 +257,10 @@ func generatePlainTable(code string) (template.HTML, error) {
 		if line == "" {
 			line = "\n" // important for e.g. selecting whitespace in the produced table
 		}
-		tr := &html.Node{Type: html.ElementNode, DataAtom: atom.Tr, Data: atom.Tr.String()}
-		table.AppendChild(tr)
-
-		tdLineNumber := &html.Node{Type: html.ElementNode, DataAtom: atom.Td, Data: atom.Td.String()}
-		tdLineNumber.Attr = append(tdLineNumber.Attr, html.Attribute{Key: "class", Val: "line"})
-		tdLineNumber.Attr = append(tdLineNumber.Attr, html.Attribute{Key: "data-line", Val: fmt.Sprint(row + 1)})
-		tr.AppendChild(tdLineNumber)
-
-		codeCell := &html.Node{Type: html.ElementNode, DataAtom: atom.Td, Data: atom.Td.String()}
-		codeCell.Attr = append(codeCell.Attr, html.Attribute{Key: "class", Val: "code"})
-		tr.AppendChild(codeCell)
+		codeCell := newTableRow(table, row+1)
 
 		// Span to match same structure as what highlighting would usually generate.
-		span := &html.Node{Type: html.ElementNode, DataAtom: atom.Span, Data: atom.Span.String()}
-		codeCell.AppendChild(span)
-		spanText := &html.Node{Type: html.TextNode, Data: line}
-		span.AppendChild(spanText)
+		appendSpan(codeCell, "", line)
 	}
 
 	var buf bytes.Buffer
@@ -244,3 +269,20 @@ func generatePlainTable(code string) (template.HTML, error) {
 	}
 	return template.HTML(buf.String()), nil
 }
+
+// newTableRow appends a row for the given (1-indexed) line number to table
+// and returns the row's (empty) code cell.
+func newTableRow(table *html.Node, line int) *html.Node {
+	tr := &html.Node{Type: html.ElementNode, DataAtom: atom.Tr, Data: atom.Tr.String()}
+	table.AppendChild(tr)
+
+	tdLineNumber := &html.Node{Type: html.ElementNode, DataAtom: atom.Td, Data: atom.Td.String()}
+	tdLineNumber.Attr = append(tdLineNumber.Attr, html.Attribute{Key: "class", Val: "line"})
+	tdLineNumber.Attr = append(tdLineNumber.Attr, html.Attribute{Key: "data-line", Val: fmt.Sprint(line)})
+	tr.AppendChild(tdLineNumber)
+
+	codeCell := &html.Node{Type: html.ElementNode, DataAtom: atom.Td, Data: atom.Td.String()}
+	codeCell.Attr = append(codeCell.Attr, html.Attribute{Key: "class", Val: "code"})
+	tr.AppendChild(codeCell)
+	return codeCell
+}
@@ -45,11 +45,11 @@ func Get(ctx context.Context, files []os.FileInfo) (*Inventory, error) {
 	for _, file := range files {
 		// NOTE: We used to skip vendored files, but the
 		// filelang.IsVendored function is slow (benchmark goes from
-		// 160ms to 0.5ms without the check). Currently Inventory is
-		// just used to determine which languages are in a repo, the
-		// relative usage (TotalBytes) is not exposed or used. So
-		// including vendored files should be fine for the aggregate
-		// stats.
+		// 160ms to 0.5ms without the check). Inventory is mostly used
+		// to determine which languages are in a repo. The relative usage
+		// (TotalBytes) is only exposed to site admins as language
+		// statistics over time, where including vendored files should be
+		// fine for tracking trends.
 		matchedLangs := byFilename(file.Name())
 		if len(matchedLangs) > 0 {
 			langs[matchedLangs[0].Name] += uint64(file.Size())
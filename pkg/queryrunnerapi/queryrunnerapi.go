@@ -39,6 +39,7 @@ const (
 	PathSavedQueryWasCreatedOrUpdated = "/saved-query-was-created-or-updated"
 	PathSavedQueryWasDeleted          = "/saved-query-was-deleted"
 	PathTestNotification              = "/test-notification"
+	PathRepoUpdated                   = "/repo-updated"
 )
 
 type client struct {
@@ -86,6 +87,23 @@ func (c *client) TestNotification(ctx context.Context, spec api.SavedQueryIDSpec
 	return c.post(PathTestNotification, &TestNotificationArgs{Spec: spec})
 }
 
+type RepoUpdatedArgs struct {
+	Repo      api.RepoName
+	OldCommit api.CommitID
+	NewCommit api.CommitID
+}
+
+// RepoUpdated should be called whenever a repository's default branch
+// advanced from oldCommit to newCommit, so that the saved searches that apply
+// to the repository are run on the new commits.
+func (c *client) RepoUpdated(ctx context.Context, repo api.RepoName, oldCommit, newCommit api.CommitID) error {
+	return c.post(PathRepoUpdated, &RepoUpdatedArgs{
+		Repo:      repo,
+		OldCommit: oldCommit,
+		NewCommit: newCommit,
+	})
+}
+
 func (c *client) post(path string, data interface{}) error {
 	var buf bytes.Buffer
 	if err := json.NewEncoder(&buf).Encode(data); err != nil {
@@ -58,3 +58,31 @@ type Symbol struct {
 
 	FileLimited bool
 }
+
+// OutlineArgs are the arguments to compute the symbol outline of a single file
+// on the symbols service.
+type OutlineArgs struct {
+	// Repo is the name of the repository containing the file.
+	Repo api.RepoName `json:"repo"`
+
+	// CommitID is the commit containing the file.
+	CommitID api.CommitID `json:"commitID"`
+
+	// Path is the path of the file (relative to the repository root).
+	Path string `json:"path"`
+}
+
+// OutlineResult is the result of computing a file's outline on the symbols
+// service.
+type OutlineResult struct {
+	Symbols []*OutlineSymbol // top-level symbols, in the order they appear in the file
+}
+
+// OutlineSymbol is a node in a file's symbol outline.
+type OutlineSymbol struct {
+	Symbol
+
+	// Children are the symbols whose parent is this symbol, in the order they
+	// appear in the file.
+	Children []*OutlineSymbol `json:",omitempty"`
+}
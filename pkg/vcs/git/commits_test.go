@@ -159,6 +159,15 @@ func TestRepository_Commits_options(t *testing.T) {
 			Parents:   []api.CommitID{"b266c7e3ca00b1a17ad0b1449825d0854225c007"},
 		},
 	}
+	wantGitCommits3 := []*git.Commit{
+		{
+			ID:        "ea167fe3d76b1e5fd3ed8ca44cbd2fe3897684f8",
+			Author:    git.Signature{Name: "a", Email: "a@a.com", Date: mustParseTime(time.RFC3339, "2006-01-02T15:04:05Z")},
+			Committer: &git.Signature{Name: "a", Email: "a@a.com", Date: mustParseTime(time.RFC3339, "2006-01-02T15:04:05Z")},
+			Message:   "foo",
+			Parents:   nil,
+		},
+	}
 	tests := map[string]struct {
 		repo        gitserver.Repo
 		opt         git.CommitsOptions
@@ -179,6 +188,16 @@ func TestRepository_Commits_options(t *testing.T) {
 			wantCommits: wantGitCommits2,
 			wantTotal:   1,
 		},
+		"git cmd Before": {
+			repo: makeGitRepository(t, gitCommands...),
+			opt: git.CommitsOptions{
+				Range:  "ade564eba4cf904492fb56dcd287ac633e6e082c",
+				N:      1,
+				Before: "2006-01-02T15:04:06Z",
+			},
+			wantCommits: wantGitCommits3,
+			wantTotal:   1,
+		},
 	}
 
 	for label, test := range tests {
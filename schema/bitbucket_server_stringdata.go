@@ -79,6 +79,19 @@ const BitbucketServerSchemaJSON = `{
       "description": "Defines whether repositories from this Bitbucket Server instance should be enabled and cloned when they are first seen by Sourcegraph. If false, the site admin must explicitly enable Bitbucket Server repositories (in the site admin area) to clone them and make them searchable on Sourcegraph. If true, they will be enabled and cloned immediately (subject to rate limiting by Bitbucket Server); site admins can still disable them explicitly, and they'll remain disabled.",
       "type": "boolean",
       "default": false
+    },
+    "authorization": {
+      "title": "BitbucketServerAuthorization",
+      "description": "If non-null, enforces Bitbucket Server repository permissions. Sourcegraph users are matched to Bitbucket Server users by username, so this should only be used if Sourcegraph usernames cannot be changed by users (such as with ` + "`" + `http-header` + "`" + ` authentication). The token (or username and password) must belong to a Bitbucket Server admin, so that Sourcegraph can read the permissions of every project and repository.",
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {
+        "ttl": {
+          "description": "The TTL of how long to cache permissions data. This is 3 hours by default.\n\nPermissions of projects and repositories are shared by all users, and each user's group memberships are cached separately. Decreasing the TTL will increase the load on the Bitbucket Server API.",
+          "type": "string",
+          "default": "3h"
+        }
+      }
     }
   }
 }
@@ -98,7 +98,7 @@ const CriticalSchemaJSON = `{
         "properties": {
           "type": {
             "type": "string",
-            "enum": ["builtin", "saml", "openidconnect", "http-header", "github", "gitlab"]
+            "enum": ["builtin", "saml", "openidconnect", "http-header", "github", "gitlab", "ldap"]
           }
         },
         "oneOf": [
@@ -107,7 +107,8 @@ const CriticalSchemaJSON = `{
           { "$ref": "#/definitions/OpenIDConnectAuthProvider" },
           { "$ref": "#/definitions/HTTPHeaderAuthProvider" },
           { "$ref": "#/definitions/GitHubAuthProvider" },
-          { "$ref": "#/definitions/GitLabAuthProvider" }
+          { "$ref": "#/definitions/GitLabAuthProvider" },
+          { "$ref": "#/definitions/LDAPAuthProvider" }
         ],
         "!go": {
           "taggedUnionType": true
@@ -353,6 +354,137 @@ const CriticalSchemaJSON = `{
         "displayName": { "$ref": "#/definitions/AuthProviderCommon/properties/displayName" }
       }
     },
+    "LDAPAuthProvider": {
+      "description": "Configures the LDAP authentication provider, which authenticates users with the username and password of their entry in an LDAP directory (such as OpenLDAP or Active Directory).",
+      "type": "object",
+      "additionalProperties": false,
+      "required": ["type", "url", "baseDN"],
+      "properties": {
+        "type": {
+          "type": "string",
+          "const": "ldap"
+        },
+        "displayName": { "$ref": "#/definitions/AuthProviderCommon/properties/displayName" },
+        "url": {
+          "description": "URL of the LDAP server. Use the ldaps scheme to connect over TLS.",
+          "type": "string",
+          "pattern": "^ldaps?://",
+          "examples": ["ldap://ldap.example.com", "ldaps://ldap.example.com:636"]
+        },
+        "startTLS": {
+          "description": "Upgrade the connection to TLS with the StartTLS operation. Only valid with the ldap URL scheme.",
+          "type": "boolean",
+          "default": false
+        },
+        "certificate": {
+          "description": "TLS certificate of the LDAP server. Only necessary if the certificate is self-signed or signed by an internal CA.",
+          "type": "string",
+          "pattern": "^-----BEGIN CERTIFICATE-----\n",
+          "examples": ["-----BEGIN CERTIFICATE-----\n..."]
+        },
+        "bindDN": {
+          "description": "The DN of the service account used to search the directory for users and groups. If empty, the directory is searched anonymously.",
+          "type": "string",
+          "examples": ["cn=sourcegraph,ou=services,dc=example,dc=com"]
+        },
+        "bindPassword": {
+          "description": "The password of the service account.",
+          "type": "string"
+        },
+        "baseDN": {
+          "description": "The DN under which users are searched for.",
+          "type": "string",
+          "examples": ["ou=people,dc=example,dc=com"]
+        },
+        "userFilter": {
+          "description": "The LDAP filter that entries must match to be users who may sign in.",
+          "type": "string",
+          "default": "(objectClass=person)",
+          "examples": ["(&(objectClass=person)(memberOf=cn=engineering,ou=groups,dc=example,dc=com))"]
+        },
+        "usernameAttribute": {
+          "description": "The attribute of user entries that users sign in with. Its value is also used (after normalization) as the Sourcegraph username.",
+          "type": "string",
+          "default": "uid",
+          "examples": ["sAMAccountName"]
+        },
+        "emailAttribute": {
+          "description": "The attribute of user entries that contains their email address. Email addresses from the directory are considered verified.",
+          "type": "string",
+          "default": "mail"
+        },
+        "displayNameAttribute": {
+          "description": "The attribute of user entries that contains their display name.",
+          "type": "string",
+          "default": "cn",
+          "examples": ["displayName"]
+        },
+        "groupBaseDN": {
+          "description": "The DN under which groups are searched for. Defaults to baseDN.",
+          "type": "string",
+          "examples": ["ou=groups,dc=example,dc=com"]
+        },
+        "groupFilter": {
+          "description": "The LDAP filter that entries must match to be groups.",
+          "type": "string",
+          "default": "(objectClass=groupOfNames)",
+          "examples": ["(objectClass=group)"]
+        },
+        "groupMemberAttribute": {
+          "description": "The attribute of group entries that contains the DNs of their members.",
+          "type": "string",
+          "default": "member",
+          "examples": ["uniqueMember"]
+        },
+        "groupNameAttribute": {
+          "description": "The attribute of group entries that contains their name. Group names are used in groupOrgMap and repositoryPermissions.",
+          "type": "string",
+          "default": "cn"
+        },
+        "groupOrgMap": {
+          "description": "Maps LDAP groups to the Sourcegraph organizations that their members are members of. When a user signs in, they are added to the organizations of their groups, and removed from the other organizations in this map. Organizations must already exist.",
+          "type": "object",
+          "additionalProperties": {
+            "type": "array",
+            "items": {
+              "type": "string"
+            }
+          },
+          "examples": [{ "engineering": ["eng"], "sre": ["eng", "ops"] }]
+        },
+        "repositoryPermissions": {
+          "title": "LDAPRepositoryPermissions",
+          "description": "If non-null, enforces repository permissions based on LDAP group membership. Repositories that match a rule may only be read by the members of the rule's groups (and site admins). Repositories whose permissions come from their code host are not affected.",
+          "type": "object",
+          "additionalProperties": false,
+          "required": ["rules"],
+          "properties": {
+            "rules": {
+              "type": "array",
+              "items": {
+                "title": "LDAPRepositoryPermissionRule",
+                "type": "object",
+                "additionalProperties": false,
+                "required": ["repositoryPattern", "groups"],
+                "properties": {
+                  "repositoryPattern": {
+                    "description": "Regular expression that the names of the repositories this rule applies to match.",
+                    "type": "string",
+                    "examples": ["^gitolite\\.example\\.com/secret/"]
+                  },
+                  "groups": {
+                    "description": "The LDAP groups whose members may read the repositories.",
+                    "type": "array",
+                    "items": { "type": "string" }
+                  }
+                }
+              }
+            }
+          },
+          "examples": [{ "rules": [{ "repositoryPattern": "^gitolite\\.example\\.com/secret/", "groups": ["engineering"] }] }]
+        }
+      }
+    },
     "AuthProviderCommon": {
       "$comment": "This schema is not used directly. The *AuthProvider schemas refer to its properties directly.",
       "description": "Common properties for authentication providers.",
@@ -33,6 +33,7 @@ type AuthProviders struct {
 	HttpHeader    *HTTPHeaderAuthProvider
 	Github        *GitHubAuthProvider
 	Gitlab        *GitLabAuthProvider
+	Ldap          *LDAPAuthProvider
 }
 
 func (v AuthProviders) MarshalJSON() ([]byte, error) {
@@ -54,6 +55,9 @@ func (v AuthProviders) MarshalJSON() ([]byte, error) {
 	if v.Gitlab != nil {
 		return json.Marshal(v.Gitlab)
 	}
+	if v.Ldap != nil {
+		return json.Marshal(v.Ldap)
+	}
 	return nil, errors.New("tagged union type must have exactly 1 non-nil field value")
 }
 func (v *AuthProviders) UnmarshalJSON(data []byte) error {
@@ -72,25 +76,33 @@ func (v *AuthProviders) UnmarshalJSON(data []byte) error {
 		return json.Unmarshal(data, &v.Gitlab)
 	case "http-header":
 		return json.Unmarshal(data, &v.HttpHeader)
+	case "ldap":
+		return json.Unmarshal(data, &v.Ldap)
 	case "openidconnect":
 		return json.Unmarshal(data, &v.Openidconnect)
 	case "saml":
 		return json.Unmarshal(data, &v.Saml)
 	}
-	return fmt.Errorf("tagged union type must have a %q property whose value is one of %s", "type", []string{"builtin", "saml", "openidconnect", "http-header", "github", "gitlab"})
+	return fmt.Errorf("tagged union type must have a %q property whose value is one of %s", "type", []string{"builtin", "saml", "openidconnect", "http-header", "github", "gitlab", "ldap"})
+}
+
+// BitbucketServerAuthorization description: If non-null, enforces Bitbucket Server repository permissions. Sourcegraph users are matched to Bitbucket Server users by username, so this should only be used if Sourcegraph usernames cannot be changed by users (such as with `http-header` authentication). The token (or username and password) must belong to a Bitbucket Server admin, so that Sourcegraph can read the permissions of every project and repository.
+type BitbucketServerAuthorization struct {
+	Ttl string `json:"ttl,omitempty"`
 }
 
 // BitbucketServerConnection description: Configuration for a connection to Bitbucket Server.
 type BitbucketServerConnection struct {
-	Certificate                 string `json:"certificate,omitempty"`
-	ExcludePersonalRepositories bool   `json:"excludePersonalRepositories,omitempty"`
-	GitURLType                  string `json:"gitURLType,omitempty"`
-	InitialRepositoryEnablement bool   `json:"initialRepositoryEnablement,omitempty"`
-	Password                    string `json:"password,omitempty"`
-	RepositoryPathPattern       string `json:"repositoryPathPattern,omitempty"`
-	Token                       string `json:"token,omitempty"`
-	Url                         string `json:"url"`
-	Username                    string `json:"username,omitempty"`
+	Authorization               *BitbucketServerAuthorization `json:"authorization,omitempty"`
+	Certificate                 string                        `json:"certificate,omitempty"`
+	ExcludePersonalRepositories bool                          `json:"excludePersonalRepositories,omitempty"`
+	GitURLType                  string                        `json:"gitURLType,omitempty"`
+	InitialRepositoryEnablement bool                          `json:"initialRepositoryEnablement,omitempty"`
+	Password                    string                        `json:"password,omitempty"`
+	RepositoryPathPattern       string                        `json:"repositoryPathPattern,omitempty"`
+	Token                       string                        `json:"token,omitempty"`
+	Url                         string                        `json:"url"`
+	Username                    string                        `json:"username,omitempty"`
 }
 
 // BuiltinAuthProvider description: Configures the builtin username-password authentication provider.
@@ -127,8 +139,9 @@ type CriticalConfiguration struct {
 
 // Discussions description: Configures Sourcegraph code discussions.
 type Discussions struct {
-	AbuseEmails     []string `json:"abuseEmails,omitempty"`
-	AbuseProtection bool     `json:"abuseProtection,omitempty"`
+	AbuseEmails          []string `json:"abuseEmails,omitempty"`
+	AbuseProtection      bool     `json:"abuseProtection,omitempty"`
+	SyncCodeHostComments bool     `json:"syncCodeHostComments,omitempty"`
 }
 
 // ExperimentalFeatures description: Experimental features to enable or disable. Features that are now enabled by default are marked as deprecated.
@@ -266,6 +279,55 @@ func (v *IdentityProvider) UnmarshalJSON(data []byte) error {
 	return fmt.Errorf("tagged union type must have a %q property whose value is one of %s", "type", []string{"oauth", "username", "external"})
 }
 
+// InboundEmailConfig description: Optional. Receives emails (such as code discussion reply emails) from an inbound email provider's webhook or with a built-in SMTP server. This can be used instead of `email.imap`. Webhooks must be sent to `https://sourcegraph.example.com/.api/inbound-email`.
+type InboundEmailConfig struct {
+	Address           string `json:"address"`
+	Provider          string `json:"provider"`
+	SmtpListenAddress string `json:"smtpListenAddress,omitempty"`
+	WebhookSecret     string `json:"webhookSecret,omitempty"`
+}
+
+// InsightSeries description: A code insight series: the number of files matching a search query at regularly sampled points in time in the history of each repository's default branch.
+type InsightSeries struct {
+	Interval     string   `json:"interval,omitempty"`
+	Key          string   `json:"key"`
+	Query        string   `json:"query"`
+	Repositories []string `json:"repositories,omitempty"`
+	Samples      int      `json:"samples,omitempty"`
+	Title        string   `json:"title"`
+}
+
+// LDAPAuthProvider description: Configures the LDAP authentication provider, which authenticates users with the username and password of their entry in an LDAP directory (such as OpenLDAP or Active Directory).
+type LDAPAuthProvider struct {
+	BaseDN                string                     `json:"baseDN"`
+	BindDN                string                     `json:"bindDN,omitempty"`
+	BindPassword          string                     `json:"bindPassword,omitempty"`
+	Certificate           string                     `json:"certificate,omitempty"`
+	DisplayName           string                     `json:"displayName,omitempty"`
+	DisplayNameAttribute  string                     `json:"displayNameAttribute,omitempty"`
+	EmailAttribute        string                     `json:"emailAttribute,omitempty"`
+	GroupBaseDN           string                     `json:"groupBaseDN,omitempty"`
+	GroupFilter           string                     `json:"groupFilter,omitempty"`
+	GroupMemberAttribute  string                     `json:"groupMemberAttribute,omitempty"`
+	GroupNameAttribute    string                     `json:"groupNameAttribute,omitempty"`
+	GroupOrgMap           map[string][]string        `json:"groupOrgMap,omitempty"`
+	RepositoryPermissions *LDAPRepositoryPermissions `json:"repositoryPermissions,omitempty"`
+	StartTLS              bool                       `json:"startTLS,omitempty"`
+	Type                  string                     `json:"type"`
+	Url                   string                     `json:"url"`
+	UserFilter            string                     `json:"userFilter,omitempty"`
+	UsernameAttribute     string                     `json:"usernameAttribute,omitempty"`
+}
+type LDAPRepositoryPermissionRule struct {
+	Groups            []string `json:"groups"`
+	RepositoryPattern string   `json:"repositoryPattern"`
+}
+
+// LDAPRepositoryPermissions description: If non-null, enforces repository permissions based on LDAP group membership. Repositories that match a rule may only be read by the members of the rule's groups (and site admins). Repositories whose permissions come from their code host are not affected.
+type LDAPRepositoryPermissions struct {
+	Rules []*LDAPRepositoryPermissionRule `json:"rules"`
+}
+
 // Log description: Configuration for logging and alerting, including to external services.
 type Log struct {
 	Sentry *Sentry `json:"sentry,omitempty"`
@@ -339,13 +401,35 @@ type SMTPServerConfig struct {
 	Port           int    `json:"port"`
 	Username       string `json:"username,omitempty"`
 }
+
+// SavedQueryMonitor description: Turns the saved query into a monitor. Notifications are only sent when the trigger condition is met, using the monitor's actions instead of the notify, notifySlack and notifyWebhook properties.
+type SavedQueryMonitor struct {
+	Actions []string                  `json:"actions"`
+	Trigger *SavedQueryMonitorTrigger `json:"trigger"`
+}
+
+// SavedQueryMonitorTrigger description: The conditions under which a saved query monitor is triggered. The monitor is triggered when a run of the saved query finds new results and any of the conditions is met.
+type SavedQueryMonitorTrigger struct {
+	ResultCountAbove         *int    `json:"resultCountAbove,omitempty"`
+	ResultCountChangePercent float64 `json:"resultCountChangePercent,omitempty"`
+	ResultInRepo             string  `json:"resultInRepo,omitempty"`
+}
+
+// SavedQueryWebhook description: An HTTPS webhook that receives a signed JSON payload when new results are available for the saved query.
+type SavedQueryWebhook struct {
+	Secret string `json:"secret,omitempty"`
+	Url    string `json:"url"`
+}
 type SearchSavedQueries struct {
-	Description    string `json:"description"`
-	Key            string `json:"key"`
-	Notify         bool   `json:"notify,omitempty"`
-	NotifySlack    bool   `json:"notifySlack,omitempty"`
-	Query          string `json:"query"`
-	ShowOnHomepage bool   `json:"showOnHomepage,omitempty"`
+	Description     string             `json:"description"`
+	Key             string             `json:"key"`
+	Monitor         *SavedQueryMonitor `json:"monitor,omitempty"`
+	Notify          bool               `json:"notify,omitempty"`
+	NotifyFrequency string             `json:"notifyFrequency,omitempty"`
+	NotifySlack     bool               `json:"notifySlack,omitempty"`
+	NotifyWebhook   *SavedQueryWebhook `json:"notifyWebhook,omitempty"`
+	Query           string             `json:"query"`
+	ShowOnHomepage  bool               `json:"showOnHomepage,omitempty"`
 }
 type SearchScope struct {
 	Description string `json:"description,omitempty"`
@@ -362,6 +446,7 @@ type Sentry struct {
 // Settings description: Configuration settings for users and organizations on Sourcegraph.
 type Settings struct {
 	Extensions             map[string]bool           `json:"extensions,omitempty"`
+	Insights               []*InsightSeries          `json:"insights,omitempty"`
 	Motd                   []string                  `json:"motd,omitempty"`
 	NotificationsSlack     *SlackNotificationsConfig `json:"notifications.slack,omitempty"`
 	SearchRepositoryGroups map[string][]string       `json:"search.repositoryGroups,omitempty"`
@@ -380,6 +465,7 @@ type SiteConfiguration struct {
 	DontIncludeSymbolResultsByDefault bool                        `json:"dontIncludeSymbolResultsByDefault,omitempty"`
 	EmailAddress                      string                      `json:"email.address,omitempty"`
 	EmailImap                         *IMAPServerConfig           `json:"email.imap,omitempty"`
+	EmailInbound                      *InboundEmailConfig         `json:"email.inbound,omitempty"`
 	EmailSmtp                         *SMTPServerConfig           `json:"email.smtp,omitempty"`
 	ExperimentalFeatures              *ExperimentalFeatures       `json:"experimentalFeatures,omitempty"`
 	Extensions                        *Extensions                 `json:"extensions,omitempty"`
@@ -387,8 +473,10 @@ type SiteConfiguration struct {
 	GitMaxConcurrentClones            int                         `json:"gitMaxConcurrentClones,omitempty"`
 	GithubClientID                    string                      `json:"githubClientID,omitempty"`
 	GithubClientSecret                string                      `json:"githubClientSecret,omitempty"`
+	HighlightEngine                   string                      `json:"highlight.engine,omitempty"`
 	MaxReposToSearch                  int                         `json:"maxReposToSearch,omitempty"`
 	ParentSourcegraph                 *ParentSourcegraph          `json:"parentSourcegraph,omitempty"`
+	PermissionsSyncInterval           int                         `json:"permissions.syncInterval,omitempty"`
 	RepoListUpdateInterval            int                         `json:"repoListUpdateInterval,omitempty"`
 	SearchIndexEnabled                *bool                       `json:"search.index.enabled,omitempty"`
 }
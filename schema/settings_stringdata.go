@@ -39,6 +39,18 @@ const SettingsSchemaJSON = `{
           "notifySlack": {
             "type": "boolean",
             "description": "Notify Slack via the organization's Slack webhook URL when new results are available"
+          },
+          "notifyWebhook": {
+            "$ref": "#/definitions/SavedQueryWebhook"
+          },
+          "notifyFrequency": {
+            "type": "string",
+            "description": "How often to send email and Slack notifications: immediately after each run that finds new results, or batched into an hourly or daily digest. Each user can override this for their own notifications.",
+            "enum": ["immediate", "hourly", "daily"],
+            "default": "immediate"
+          },
+          "monitor": {
+            "$ref": "#/definitions/SavedQueryMonitor"
           }
         },
         "additionalProperties": false,
@@ -63,6 +75,13 @@ const SettingsSchemaJSON = `{
     "notifications.slack": {
       "$ref": "#/definitions/SlackNotificationsConfig"
     },
+    "insights": {
+      "description": "Code insight series, each of which counts the files matching a search query at points in the history of each repository's default branch. The counts are computed by a background job, so a new series's data points appear gradually.",
+      "type": "array",
+      "items": {
+        "$ref": "#/definitions/InsightSeries"
+      }
+    },
     "motd": {
       "description": "An array (often with just one element) of messages to display at the top of all pages, including for unauthenticated users. Users may dismiss a message (and any message with the same string value will remain dismissed for the user).\n\nMarkdown formatting is supported.\n\nUsually this setting is used in global and organization settings. If set in user settings, the message will only be displayed to that user. (This is useful for testing the correctness of the message's Markdown formatting.)\n\nMOTD stands for \"message of the day\" (which is the conventional Unix name for this type of message).",
       "type": "array",
@@ -106,6 +125,107 @@ const SettingsSchemaJSON = `{
         }
       }
     },
+    "InsightSeries": {
+      "type": "object",
+      "description": "A code insight series: the number of files matching a search query at regularly sampled points in time in the history of each repository's default branch.",
+      "additionalProperties": false,
+      "required": ["key", "title", "query"],
+      "properties": {
+        "key": {
+          "type": "string",
+          "description": "Unique key for this series in this file"
+        },
+        "title": {
+          "type": "string",
+          "description": "Title of this series"
+        },
+        "query": {
+          "type": "string",
+          "description": "Search query on file contents whose matching files are counted (such as \"lang:go log15.\"). It must not contain repo: filters with revisions."
+        },
+        "interval": {
+          "type": "string",
+          "description": "The interval between data points. Data points are at the start of each interval (in UTC).",
+          "enum": ["day", "week", "month"],
+          "default": "month"
+        },
+        "samples": {
+          "type": "integer",
+          "description": "The number of data points, counting back from the most recent interval boundary.",
+          "minimum": 1,
+          "maximum": 120,
+          "default": 24
+        },
+        "repositories": {
+          "type": "array",
+          "description": "Names of the repositories to count matching files in (such as \"github.com/gorilla/mux\"). If empty, all enabled repositories are included.",
+          "items": { "type": "string" }
+        }
+      }
+    },
+    "SavedQueryMonitor": {
+      "type": "object",
+      "description": "Turns the saved query into a monitor. Notifications are only sent when the trigger condition is met, using the monitor's actions instead of the notify, notifySlack and notifyWebhook properties.",
+      "additionalProperties": false,
+      "required": ["trigger", "actions"],
+      "properties": {
+        "trigger": {
+          "$ref": "#/definitions/SavedQueryMonitorTrigger"
+        },
+        "actions": {
+          "type": "array",
+          "description": "The actions to take when the monitor is triggered. \"email\" and \"slack\" notify the saved query's owner (like the notify and notifySlack properties), \"webhook\" sends a notification to the saved query's notifyWebhook, and \"discussion\" opens a discussion thread on each new matching file (only for saved queries in user settings, authored by the user).",
+          "minItems": 1,
+          "uniqueItems": true,
+          "items": {
+            "type": "string",
+            "enum": ["email", "slack", "webhook", "discussion"]
+          }
+        }
+      }
+    },
+    "SavedQueryMonitorTrigger": {
+      "type": "object",
+      "description": "The conditions under which a saved query monitor is triggered. The monitor is triggered when a run of the saved query finds new results and any of the conditions is met.",
+      "additionalProperties": false,
+      "minProperties": 1,
+      "properties": {
+        "resultCountAbove": {
+          "type": "integer",
+          "description": "Trigger when the number of results rises above this number (i.e., it is above this number and was not on the previous run). For diff and commit searches, the number of results is the number of new commits; for all other searches, it is the total number of matching lines.",
+          "minimum": 0,
+          "!go": { "pointer": true }
+        },
+        "resultInRepo": {
+          "type": "string",
+          "description": "Trigger when a new result is in a repository whose name matches this regular expression (such as \"^github\\\\.com/critical/\").",
+          "format": "regex"
+        },
+        "resultCountChangePercent": {
+          "type": "number",
+          "description": "Trigger when the number of results changed by more than this percentage since the previous run.",
+          "exclusiveMinimum": 0
+        }
+      }
+    },
+    "SavedQueryWebhook": {
+      "type": "object",
+      "description": "An HTTPS webhook that receives a signed JSON payload when new results are available for the saved query.",
+      "additionalProperties": false,
+      "required": ["url"],
+      "properties": {
+        "url": {
+          "type": "string",
+          "description": "The HTTPS URL that the JSON payload is POSTed to.",
+          "format": "uri",
+          "pattern": "^https://"
+        },
+        "secret": {
+          "type": "string",
+          "description": "The secret used to sign the payload. The hex-encoded HMAC-SHA256 of the request body, keyed with this secret, is sent in the X-Sourcegraph-Signature header as \"sha256=<signature>\"."
+        }
+      }
+    },
     "SlackNotificationsConfig": {
       "type": "object",
       "description": "Configuration for sending notifications to Slack.",
@@ -10,6 +10,16 @@ import { getReturnTo, PasswordInput } from './SignInSignUpCommon'
 interface Props {
     location: H.Location
     history: H.History
+
+    /**
+     * The URL to post the credentials to, if not that of builtin password authentication. The accounts
+     * of such auth providers (e.g., LDAP) are managed externally, so the sign-up and password reset links
+     * are not shown.
+     */
+    signInURL?: string
+
+    /** The display name of the auth provider, shown on the sign-in button if signInURL is set. */
+    displayName?: string
 }
 
 interface State {
@@ -36,7 +46,7 @@ export class UsernamePasswordSignInForm extends React.Component<Props, State> {
     public render(): JSX.Element | null {
         return (
             <Form className="signin-signup-form signin-form" onSubmit={this.handleSubmit}>
-                {window.context.allowSignup ? (
+                {this.props.signInURL ? null : window.context.allowSignup ? (
                     <Link className="signin-signup-form__mode" to={`/sign-up${this.props.location.search}`}>
                         Don't have an account? Sign up.
                     </Link>
@@ -50,7 +60,7 @@ export class UsernamePasswordSignInForm extends React.Component<Props, State> {
                     <input
                         className={`form-control signin-signup-form__input`}
                         type="text"
-                        placeholder="Username or email"
+                        placeholder={this.props.signInURL ? 'Username' : 'Username or email'}
                         onChange={this.onEmailFieldChange}
                         required={true}
                         value={this.state.email}
@@ -72,9 +82,11 @@ export class UsernamePasswordSignInForm extends React.Component<Props, State> {
                 </div>
                 <div className="form-group">
                     <button className="btn btn-primary btn-block" type="submit" disabled={this.state.loading}>
-                        Sign in
+                        {this.props.signInURL && this.props.displayName
+                            ? `Sign in with ${this.props.displayName}`
+                            : 'Sign in'}
                     </button>
-                    {window.context.resetPasswordEnabled && (
+                    {window.context.resetPasswordEnabled && !this.props.signInURL && (
                         <small className="form-text text-muted">
                             <Link to="/password-reset">Forgot password?</Link>
                         </small>
@@ -105,7 +117,7 @@ export class UsernamePasswordSignInForm extends React.Component<Props, State> {
 
         this.setState({ loading: true })
         eventLogger.log('InitiateSignIn')
-        fetch('/-/sign-in', {
+        fetch(this.props.signInURL || '/-/sign-in', {
             credentials: 'same-origin',
             method: 'POST',
             headers: {
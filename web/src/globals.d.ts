@@ -107,6 +107,7 @@ interface SourcegraphContext {
     authProviders?: {
         displayName: string
         isBuiltin: boolean
+        serviceType: string
         authenticationURL?: string
     }[]
 